@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"strconv"
 	"sync"
 	"time"
@@ -32,6 +33,18 @@ type ExecutionEngine struct {
 	onFill        func(order *Order, fill *Fill)
 	onReject      func(order *Order, reason string)
 	onUpdate      func(order *Order)
+	onSlippage    func(record SlippageRecord)
+	onReport      func(report fix.ExecutionReport) // Every FIX report, including other components' orders
+
+	// Sweep execution
+	depthProvider   DepthProvider    // nil = the SOR's LP top of book
+	slippageRecords []SlippageRecord // Expected vs actual price of filled orders
+
+	// Callers waiting for an order's first outcome from the LP, by order ID
+	waiters map[string][]chan struct{}
+
+	// Sent ClOrdIDs matched against the LP's execution reports
+	reconciler *reconciler
 }
 
 // Order represents an A-Book order
@@ -49,14 +62,20 @@ type Order struct {
 	Status        string  // PENDING, SENT, PARTIAL, FILLED, REJECTED, CANCELED
 	SelectedLP    string
 	LPOrderID     string
-	FilledQty     float64
-	AvgFillPrice  float64
+	FilledQty     float64 // Cumulative filled quantity
+	LeavesQty     float64 // Quantity still open at the LP
+	AvgFillPrice  float64 // Volume-weighted average of the fills
 	Slippage      float64
 	CreatedAt     time.Time
 	SentAt        *time.Time
 	FilledAt      *time.Time
 	Fills         []*Fill
 	RejectReason  string
+	RejectCode    string // FIX OrdRejReason of an LP reject
+
+	ExpectedPrice    float64    // Quoted price, or the sweep's expected VWAP
+	ExpectedSlippage float64    // Expected VWAP worse than top of book
+	Sweep            *SweepPlan // Set on sweep orders
 }
 
 // Fill represents a partial or full execution
@@ -103,12 +122,14 @@ type ExecutionReport struct {
 	OrderQty      float64
 	LastQty       float64
 	LastPx        float64
-	CumQty        float64
-	AvgPx         float64
+	CumQty        float64 // 0 = not reported, accumulated from LastQty
+	LeavesQty     float64
+	AvgPx         float64 // 0 = not reported, weighted from LastPx
 	OrdStatus     string
 	LP            string
 	LPOrderID     string
 	Text          string
+	RejectCode    string // FIX OrdRejReason (103)
 	Timestamp     time.Time
 }
 
@@ -145,10 +166,12 @@ func NewExecutionEngine(
 			SlippageByLP:   make(map[string]float64),
 			AvgLatencyByLP: make(map[string]time.Duration),
 		},
+		reconciler: newReconciler(),
 	}
 
 	// Start execution report processor
 	go engine.processExecutionReports()
+	go engine.reconciler.run()
 
 	return engine
 }
@@ -190,7 +213,12 @@ func (e *ExecutionEngine) PlaceOrder(req *OrderRequest) (*Order, error) {
 		order.ClientOrderID = order.ID
 	}
 
-	// 4. Smart Order Routing - select best LP
+	// 4. Sweep mode: walk LP depth for the expected average price, optionally across LPs
+	if req.Sweep && req.Type == "MARKET" {
+		return e.placeSweepOrder(order, req.SplitLPs)
+	}
+
+	// Smart Order Routing - select best LP
 	lpSelection, err := e.sor.SelectLP(req.Symbol, req.Side, req.Volume)
 	if err != nil {
 		order.Status = "REJECTED"
@@ -202,6 +230,7 @@ func (e *ExecutionEngine) PlaceOrder(req *OrderRequest) (*Order, error) {
 	}
 
 	order.SelectedLP = lpSelection.LPID
+	order.ExpectedPrice = lpSelection.Price
 
 	// 5. Route to LP via FIX
 	if err := e.routeToLP(order, lpSelection); err != nil {
@@ -232,6 +261,58 @@ func (e *ExecutionEngine) PlaceOrder(req *OrderRequest) (*Order, error) {
 	return order, nil
 }
 
+// placeSweepOrder plans a market order over LP depth and routes it, as one
+// order to the best LP or, with split, one order per LP the sweep takes
+func (e *ExecutionEngine) placeSweepOrder(order *Order, split bool) (*Order, error) {
+	plan, err := e.PlanSweep(order.Symbol, order.Side, order.Volume, split)
+	if err != nil {
+		order.Status = "REJECTED"
+		order.RejectReason = fmt.Sprintf("Sweep failed: %v", err)
+		e.mu.Lock()
+		e.orders[order.ID] = order
+		e.mu.Unlock()
+		return order, err
+	}
+
+	order.Sweep = plan
+	order.ExpectedPrice = plan.ExpectedVWAP
+	order.ExpectedSlippage = plan.ExpectedSlippage
+	order.SelectedLP = plan.Legs[0].LP
+
+	if len(plan.Legs) > 1 {
+		err = e.routeSweep(order)
+	} else {
+		plan.Legs[0].SessionID = e.sor.mapLPToSession(order.SelectedLP)
+		err = e.routeToLP(order, &LPSelection{LPID: order.SelectedLP, SessionID: plan.Legs[0].SessionID, Price: plan.ExpectedVWAP})
+		plan.Legs[0].LPOrderID = order.LPOrderID
+	}
+	if err != nil {
+		order.Status = "REJECTED"
+		order.RejectReason = fmt.Sprintf("Routing failed: %v", err)
+		e.mu.Lock()
+		e.orders[order.ID] = order
+		e.mu.Unlock()
+		return order, err
+	}
+
+	order.Status = "SENT"
+	now := time.Now()
+	order.SentAt = &now
+
+	e.mu.Lock()
+	e.orders[order.ID] = order
+	e.mu.Unlock()
+
+	log.Printf("[A-Book] Sweep order %s sent to %d LP(s) for %s %.2f: expected VWAP %.5f (slippage %.5f)",
+		order.ClientOrderID, len(plan.Legs), order.Symbol, order.Volume, plan.ExpectedVWAP, plan.ExpectedSlippage)
+
+	e.metrics.mu.Lock()
+	e.metrics.TotalOrders++
+	e.metrics.mu.Unlock()
+
+	return order, nil
+}
+
 // CancelOrder cancels an order at the LP
 func (e *ExecutionEngine) CancelOrder(orderID string) error {
 	e.mu.RLock()
@@ -247,7 +328,7 @@ func (e *ExecutionEngine) CancelOrder(orderID string) error {
 	}
 
 	// Send cancel request via FIX
-	_, cancelErr := e.fixGateway.CancelOrder(order.SelectedLP, order.ClientOrderID, order.Symbol, order.Side)
+	_, cancelErr := e.fixGateway.CancelOrder(order.SelectedLP, order.ClientOrderID, order.Symbol, order.Side, order.Volume)
 	if cancelErr != nil {
 		return fmt.Errorf("cancel request failed: %w", cancelErr)
 	}
@@ -364,6 +445,15 @@ func (e *ExecutionEngine) SetOnUpdateCallback(callback func(*Order)) {
 	e.onUpdate = callback
 }
 
+// SetOnExecutionReportCallback sets a callback receiving every FIX execution
+// report, e.g. for orders sent to the LP outside the engine such as hedges.
+// It runs on the report processor, before the engine handles the report.
+func (e *ExecutionEngine) SetOnExecutionReportCallback(callback func(fix.ExecutionReport)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onReport = callback
+}
+
 // processExecutionReports processes incoming execution reports from LPs
 func (e *ExecutionEngine) processExecutionReports() {
 	log.Println("[A-Book] Execution report processor started")
@@ -374,6 +464,13 @@ func (e *ExecutionEngine) processExecutionReports() {
 	for {
 		select {
 		case fixReport := <-fixExecReports:
+			e.mu.RLock()
+			onReport := e.onReport
+			e.mu.RUnlock()
+			if onReport != nil {
+				onReport(fixReport)
+			}
+			e.reconciler.observe(&fixReport)
 			e.handleFIXExecutionReport(&fixReport)
 		case report := <-e.execReports:
 			e.handleExecutionReport(report)
@@ -383,19 +480,26 @@ func (e *ExecutionEngine) processExecutionReports() {
 
 // handleFIXExecutionReport converts FIX report to internal format
 func (e *ExecutionEngine) handleFIXExecutionReport(fixReport *fix.ExecutionReport) {
+	clOrdID := fixReport.ClOrdID
+	if clOrdID == "" {
+		clOrdID = fixReport.OrderID
+	}
 	report := &ExecutionReport{
-		ClientOrderID: fixReport.OrderID,
+		OrderID:       fixReport.OrderID,
+		ClientOrderID: clOrdID,
 		ExecType:      fixReport.ExecType,
 		Symbol:        fixReport.Symbol,
 		Side:          fixReport.Side,
 		OrderQty:      fixReport.Volume,
 		LastQty:       fixReport.Volume,
 		LastPx:        fixReport.Price,
-		CumQty:        fixReport.Volume,
-		AvgPx:         fixReport.Price,
+		CumQty:        fixReport.CumQty,
+		LeavesQty:     fixReport.LeavesQty,
+		AvgPx:         fixReport.AvgPx,
 		LP:            "FIX",
 		LPOrderID:     fixReport.LPOrderID,
 		Text:          fixReport.Text,
+		RejectCode:    fixReport.RejReason,
 		Timestamp:     fixReport.Timestamp,
 	}
 
@@ -403,7 +507,11 @@ func (e *ExecutionEngine) handleFIXExecutionReport(fixReport *fix.ExecutionRepor
 	switch fixReport.ExecType {
 	case "NEW":
 		report.OrdStatus = "NEW"
+	case "PARTIAL":
+		report.ExecType = "PARTIAL_FILL"
+		report.OrdStatus = "PARTIALLY_FILLED"
 	case "FILLED":
+		report.ExecType = "FILL"
 		report.OrdStatus = "FILLED"
 	case "REJECTED":
 		report.OrdStatus = "REJECTED"
@@ -414,18 +522,46 @@ func (e *ExecutionEngine) handleFIXExecutionReport(fixReport *fix.ExecutionRepor
 	e.handleExecutionReport(report)
 }
 
+// cumulativeFill returns the filled quantity and average price after a fill,
+// taken from the LP's CumQty/AvgPx when reported and otherwise accumulated
+// from the previous state and the fill's LastQty/LastPx
+func cumulativeFill(filledQty, avgPrice float64, report *ExecutionReport) (float64, float64) {
+	cumQty := report.CumQty
+	if cumQty <= 0 {
+		cumQty = filledQty + report.LastQty
+	}
+	avgPx := report.AvgPx
+	if avgPx <= 0 && cumQty > 0 {
+		avgPx = (filledQty*avgPrice + report.LastQty*report.LastPx) / cumQty
+	}
+	return cumQty, avgPx
+}
+
 // handleExecutionReport processes an execution report
 func (e *ExecutionEngine) handleExecutionReport(report *ExecutionReport) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	// Find order by client order ID
+	// Find order by client order ID, or by the LP order ID of a sweep leg
 	var order *Order
+	var leg *SweepLeg
 	for _, o := range e.orders {
-		if o.ClientOrderID == report.ClientOrderID {
+		if o.ClientOrderID == report.ClientOrderID ||
+			(o.LPOrderID != "" && o.LPOrderID == report.ClientOrderID && (o.Sweep == nil || len(o.Sweep.Legs) <= 1)) {
 			order = o
 			break
 		}
+		if o.Sweep != nil && len(o.Sweep.Legs) > 1 {
+			for i := range o.Sweep.Legs {
+				if o.Sweep.Legs[i].LPOrderID == report.ClientOrderID {
+					order, leg = o, &o.Sweep.Legs[i]
+					break
+				}
+			}
+		}
+		if order != nil {
+			break
+		}
 	}
 
 	if order == nil {
@@ -439,7 +575,10 @@ func (e *ExecutionEngine) handleExecutionReport(report *ExecutionReport) {
 	switch report.ExecType {
 	case "NEW":
 		order.Status = "SENT"
-		order.LPOrderID = report.LPOrderID
+		if order.LPOrderID == "" {
+			order.LPOrderID = report.LPOrderID
+		}
+		order.LeavesQty = order.Volume
 
 	case "PARTIAL_FILL", "FILL":
 		// Create fill record
@@ -457,34 +596,40 @@ func (e *ExecutionEngine) handleExecutionReport(report *ExecutionReport) {
 		}
 
 		order.Fills = append(order.Fills, fill)
-		order.FilledQty = report.CumQty
-		order.AvgFillPrice = report.AvgPx
+
+		// A split sweep is done once the LPs are done with every leg
+		var complete bool
+		if leg != nil {
+			fill.LP = leg.LP
+			leg.FilledQty, leg.AvgFillPrice = cumulativeFill(leg.FilledQty, leg.AvgFillPrice, report)
+			leg.Status = "PARTIAL"
+			if report.ExecType == "FILL" || leg.FilledQty >= leg.Volume-1e-9 {
+				leg.Status = "FILLED"
+			}
+			complete = sweepLegsSettled(order)
+		} else {
+			order.FilledQty, order.AvgFillPrice = cumulativeFill(order.FilledQty, order.AvgFillPrice, report)
+			complete = report.ExecType == "FILL" || order.FilledQty >= order.Volume-1e-9
+		}
+		order.LeavesQty = math.Max(order.Volume-order.FilledQty, 0)
+		if report.LeavesQty > 0 && !complete {
+			order.LeavesQty = report.LeavesQty
+		}
+		if complete {
+			order.LeavesQty = 0
+		}
 
 		// Calculate slippage
 		if order.Price > 0 {
 			if order.Side == "BUY" {
-				order.Slippage = report.AvgPx - order.Price
+				order.Slippage = order.AvgFillPrice - order.Price
 			} else {
-				order.Slippage = order.Price - report.AvgPx
+				order.Slippage = order.Price - order.AvgFillPrice
 			}
 		}
 
-		if report.ExecType == "FILL" {
-			order.Status = "FILLED"
-			now := time.Now()
-			order.FilledAt = &now
-
-			// Create position
-			e.createPosition(order, fill)
-
-			// Update metrics
-			e.metrics.mu.Lock()
-			e.metrics.FilledOrders++
-			e.updateMetrics(order)
-			e.metrics.mu.Unlock()
-
-			log.Printf("[A-Book] Order %s FILLED: %.2f @ %.5f (slippage: %.5f)",
-				order.ClientOrderID, order.FilledQty, order.AvgFillPrice, order.Slippage)
+		if complete {
+			e.fillOrderLocked(order, fill)
 		} else {
 			order.Status = "PARTIAL"
 			e.metrics.mu.Lock()
@@ -498,31 +643,91 @@ func (e *ExecutionEngine) handleExecutionReport(report *ExecutionReport) {
 		}
 
 	case "REJECTED":
+		// A rejected sweep leg only settles the sweep once the others are
+		// done: with what the other legs filled booked, rejected with none
+		if leg != nil && !e.settleSweepLegLocked(order, leg, "REJECTED", report) {
+			break
+		}
 		order.Status = "REJECTED"
+		order.RejectCode = report.RejectCode
 		order.RejectReason = report.Text
+		if order.RejectReason == "" {
+			order.RejectReason = rejectReasonText(report.RejectCode)
+		}
 
 		e.metrics.mu.Lock()
 		e.metrics.RejectedOrders++
 		e.metrics.mu.Unlock()
 
-		log.Printf("[A-Book] Order %s REJECTED: %s", order.ClientOrderID, report.Text)
+		log.Printf("[A-Book] Order %s REJECTED: %s", order.ClientOrderID, order.RejectReason)
 
 		// Callback
 		if e.onReject != nil {
-			e.onReject(order, report.Text)
+			e.onReject(order, order.RejectReason)
 		}
 
 	case "CANCELED":
+		if leg != nil && !e.settleSweepLegLocked(order, leg, "CANCELED", report) {
+			break
+		}
 		order.Status = "CANCELED"
 		log.Printf("[A-Book] Order %s CANCELED", order.ClientOrderID)
 	}
 
+	e.notifyWaitersLocked(order)
+
 	// Update callback
 	if e.onUpdate != nil {
 		e.onUpdate(order)
 	}
 }
 
+// fillOrderLocked completes a filled order: books its position at the filled
+// quantity and records its slippage (caller must hold lock)
+func (e *ExecutionEngine) fillOrderLocked(order *Order, fill *Fill) {
+	order.Status = "FILLED"
+	order.LeavesQty = 0
+	now := time.Now()
+	order.FilledAt = &now
+	e.recordSlippageLocked(order)
+
+	// Create position
+	e.createPosition(order, fill)
+
+	// Update metrics
+	e.metrics.mu.Lock()
+	e.metrics.FilledOrders++
+	e.updateMetrics(order)
+	e.metrics.mu.Unlock()
+
+	log.Printf("[A-Book] Order %s FILLED: %.2f @ %.5f (slippage: %.5f)",
+		order.ClientOrderID, order.FilledQty, order.AvgFillPrice, order.Slippage)
+}
+
+// settleSweepLegLocked marks a split sweep's leg rejected or canceled by its
+// LP. Once every leg is settled, a sweep something filled is completed at the
+// filled quantity. Returns true when nothing filled and the whole order is to
+// be rejected or canceled (caller must hold lock).
+func (e *ExecutionEngine) settleSweepLegLocked(order *Order, leg *SweepLeg, status string, report *ExecutionReport) bool {
+	leg.Status = status
+	leg.RejectReason = report.Text
+	if leg.RejectReason == "" && status == "REJECTED" {
+		leg.RejectReason = rejectReasonText(report.RejectCode)
+	}
+	log.Printf("[A-Book] Sweep order %s leg %s %s after %.2f of %.2f lots: %s",
+		order.ClientOrderID, leg.LP, status, leg.FilledQty, leg.Volume, leg.RejectReason)
+
+	if !sweepLegsSettled(order) {
+		return false
+	}
+	if order.FilledQty <= 0 {
+		return true
+	}
+	order.RejectReason = fmt.Sprintf("Filled %.2f of %.2f lots, the other sweep legs were rejected or canceled", order.FilledQty, order.Volume)
+	e.fillOrderLocked(order, nil)
+	return false
+}
+
 // createPosition creates a new position from a filled order
 func (e *ExecutionEngine) createPosition(order *Order, fill *Fill) {
 	position := &Position{
@@ -606,6 +811,12 @@ func (e *ExecutionEngine) routeToLP(order *Order, lpSelection *LPSelection) erro
 	// Store the LP order ID returned by FIX gateway
 	order.LPOrderID = clOrdID
 
+	requested := order.Price
+	if requested == 0 {
+		requested = lpSelection.Price
+	}
+	e.reconciler.track(clOrdID, order, lpSelection.LPID, order.Volume, requested)
+
 	return nil
 }
 
@@ -645,4 +856,6 @@ type OrderRequest struct {
 	Price         float64
 	SL            float64
 	TP            float64
+	Sweep         bool // Market orders: walk LP depth for the expected VWAP
+	SplitLPs      bool // Sweep across LPs with one order per LP
 }
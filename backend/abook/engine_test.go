@@ -0,0 +1,74 @@
+package abook
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/fix"
+)
+
+// newPartialFillTestEngine returns an engine holding one sent 10-lot buy
+func newPartialFillTestEngine() (*ExecutionEngine, *Order) {
+	sent := time.Now()
+	order := &Order{ID: "o-1", ClientOrderID: "client-1", Symbol: "EURUSD", Side: "BUY", Type: "MARKET",
+		Volume: 10, Status: "SENT", SelectedLP: "lp-a", LPOrderID: "YOFX1_1", SentAt: &sent}
+	e := &ExecutionEngine{
+		orders:     map[string]*Order{order.ID: order},
+		positions:  make(map[string]*Position),
+		metrics:    &ExecutionMetrics{FillRateByLP: map[string]float64{}, SlippageByLP: map[string]float64{}, AvgLatencyByLP: map[string]time.Duration{}},
+		reconciler: newReconciler(),
+	}
+	return e, order
+}
+
+// TestPartialFillsThenFinalFill tests that two partial fills and a final fill
+// reported by ClOrdID accumulate into one filled position at the volume
+// weighted average price
+func TestPartialFillsThenFinalFill(t *testing.T) {
+	e, order := newPartialFillTestEngine()
+
+	// LastQty/LastPx only: quantities and price are accumulated
+	e.handleFIXExecutionReport(&fix.ExecutionReport{ClOrdID: "YOFX1_1", ExecType: "PARTIAL", Volume: 3, Price: 1.10000})
+	if order.Status != "PARTIAL" || order.FilledQty != 3 || order.LeavesQty != 7 || len(e.positions) != 0 {
+		t.Fatalf("after 3 lots: %s filled %.0f leaves %.0f, %d positions; want PARTIAL 3/7 without a position",
+			order.Status, order.FilledQty, order.LeavesQty, len(e.positions))
+	}
+	e.handleFIXExecutionReport(&fix.ExecutionReport{ClOrdID: "YOFX1_1", ExecType: "PARTIAL", Volume: 4, Price: 1.10010})
+	if order.Status != "PARTIAL" || order.FilledQty != 7 || order.LeavesQty != 3 {
+		t.Fatalf("after 7 lots: %s filled %.0f leaves %.0f; want PARTIAL 7/3", order.Status, order.FilledQty, order.LeavesQty)
+	}
+	e.handleFIXExecutionReport(&fix.ExecutionReport{ClOrdID: "YOFX1_1", ExecType: "FILLED", Volume: 3, Price: 1.10020})
+
+	wantAvg := (3*1.10000 + 4*1.10010 + 3*1.10020) / 10
+	if order.Status != "FILLED" || order.FilledQty != 10 || order.LeavesQty != 0 || math.Abs(order.AvgFillPrice-wantAvg) > 1e-9 {
+		t.Fatalf("order = %s %.0f (leaves %.0f) @ %.6f, want FILLED 10 @ %.6f",
+			order.Status, order.FilledQty, order.LeavesQty, order.AvgFillPrice, wantAvg)
+	}
+	if len(order.Fills) != 3 {
+		t.Errorf("fills = %d, want 3", len(order.Fills))
+	}
+	if len(e.positions) != 1 {
+		t.Fatalf("positions = %d, want one", len(e.positions))
+	}
+	for _, pos := range e.positions {
+		if pos.Volume != 10 || math.Abs(pos.OpenPrice-wantAvg) > 1e-9 {
+			t.Errorf("position = %.0f @ %.6f, want 10 @ %.6f", pos.Volume, pos.OpenPrice, wantAvg)
+		}
+	}
+}
+
+// TestPartialFillUsesReportedCumQty tests that the LP's CumQty, LeavesQty
+// and AvgPx are taken over the engine's own accumulation
+func TestPartialFillUsesReportedCumQty(t *testing.T) {
+	e, order := newPartialFillTestEngine()
+
+	e.handleFIXExecutionReport(&fix.ExecutionReport{ClOrdID: "YOFX1_1", ExecType: "PARTIAL",
+		Volume: 3, Price: 1.1, CumQty: 3, LeavesQty: 7, AvgPx: 1.1})
+	e.handleFIXExecutionReport(&fix.ExecutionReport{ClOrdID: "YOFX1_1", ExecType: "FILLED",
+		Volume: 7, Price: 1.2, CumQty: 10, AvgPx: 1.17})
+
+	if order.Status != "FILLED" || order.FilledQty != 10 || order.AvgFillPrice != 1.17 {
+		t.Errorf("order = %s %.0f @ %.5f, want FILLED 10 @ the reported 1.17", order.Status, order.FilledQty, order.AvgFillPrice)
+	}
+}
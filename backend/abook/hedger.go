@@ -0,0 +1,446 @@
+package abook
+
+import (
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/epic1st/rtx/backend/fix"
+)
+
+// hedgeFillTimeout is how long a hedge order may go without an execution
+// report before it stops blocking new adjustments of its symbol. Fills the
+// LP reports after that still move the hedge.
+const hedgeFillTimeout = 30 * time.Second
+
+// HedgeConfig controls when residual B-Book exposure is offset at the LP.
+// Bands are expressed in lots of net client exposure per symbol.
+type HedgeConfig struct {
+	Enabled       bool
+	UpperBand     float64       // Open/increase the hedge when |net exposure| reaches this level
+	LowerBand     float64       // Unwind the hedge when |net exposure| falls below this level
+	HedgeRatio    float64       // Fraction of net exposure to hedge (1.0 = fully offset)
+	MinTradeSize  float64       // Hedge adjustments smaller than this are skipped
+	CheckInterval time.Duration // How often exposure is re-evaluated
+}
+
+// DefaultHedgeConfig returns conservative hedging defaults (disabled)
+func DefaultHedgeConfig() HedgeConfig {
+	return HedgeConfig{
+		Enabled:       false,
+		UpperBand:     10.0,
+		LowerBand:     2.0,
+		HedgeRatio:    1.0,
+		MinTradeSize:  0.01,
+		CheckInterval: 5 * time.Second,
+	}
+}
+
+// HedgeAction records a single hedge adjustment sent to the LP
+type HedgeAction struct {
+	Symbol      string    `json:"symbol"`
+	Side        string    `json:"side"` // BUY or SELL at the LP
+	Volume      float64   `json:"volume"`
+	NetExposure float64   `json:"netExposure"` // Net client exposure at decision time (+ = clients long)
+	HedgeBefore float64   `json:"hedgeBefore"`
+	HedgeAfter  float64   `json:"hedgeAfter"`
+	Reason      string    `json:"reason"` // OPEN, INCREASE, REDUCE, UNWIND
+	Status      string    `json:"status"` // SENT, PARTIAL, FILLED, REJECTED, FAILED, EXPIRED
+	FilledQty   float64   `json:"filledQty"`
+	LPOrderID   string    `json:"lpOrderId,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// pendingHedge is a hedge order sent to the LP and not yet fully reported
+type pendingHedge struct {
+	action    *HedgeAction
+	direction float64 // +1 for BUY, -1 for SELL
+	remaining float64
+	sentAt    time.Time
+}
+
+// AutoHedger keeps an offsetting A-Book position at the LP for symbols whose
+// net B-Book exposure exceeds the configured bands. The hedge only moves by
+// what the LP's execution reports fill.
+type AutoHedger struct {
+	mu         sync.RWMutex
+	config     HedgeConfig
+	exposureFn func() map[string]float64
+	executeFn  func(symbol, side string, volume float64) (string, error)
+	hedges     map[string]float64       // Symbol -> net filled hedge at LP (+ = broker long)
+	pending    map[string]*pendingHedge // LP order ID -> order awaiting fills
+	expired    map[string]*pendingHedge // LP order ID -> expired order whose late fills still count
+	inFlight   map[string]bool          // Symbols with an order being sent or awaiting fills
+	sending    int                      // Orders inside executeFn
+	early      []fix.ExecutionReport    // Reports that arrived while their order was being sent
+	actions    []*HedgeAction
+	maxActions int
+	stopChan   chan struct{}
+	running    bool
+}
+
+// NewAutoHedger creates a hedger. exposureFn returns net client exposure per
+// symbol in lots (positive when clients are net long); executeFn sends a
+// market order to the LP and returns its order ID.
+func NewAutoHedger(
+	config HedgeConfig,
+	exposureFn func() map[string]float64,
+	executeFn func(symbol, side string, volume float64) (string, error),
+) *AutoHedger {
+	return &AutoHedger{
+		config:     config,
+		exposureFn: exposureFn,
+		executeFn:  executeFn,
+		hedges:     make(map[string]float64),
+		pending:    make(map[string]*pendingHedge),
+		expired:    make(map[string]*pendingHedge),
+		inFlight:   make(map[string]bool),
+		actions:    make([]*HedgeAction, 0),
+		maxActions: 1000,
+	}
+}
+
+// Start begins periodic exposure evaluation
+func (h *AutoHedger) Start() {
+	h.mu.Lock()
+	if h.running || !h.config.Enabled {
+		h.mu.Unlock()
+		return
+	}
+	h.running = true
+	h.stopChan = make(chan struct{})
+	stop := h.stopChan
+	config := h.config
+	h.mu.Unlock()
+
+	interval := config.CheckInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				h.Evaluate()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	log.Printf("[AutoHedge] Started (upper=%.2f lots, lower=%.2f lots, ratio=%.2f)",
+		config.UpperBand, config.LowerBand, config.HedgeRatio)
+}
+
+// Stop halts periodic evaluation
+func (h *AutoHedger) Stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.running {
+		return
+	}
+	close(h.stopChan)
+	h.running = false
+}
+
+// SetConfig replaces the hedging configuration
+func (h *AutoHedger) SetConfig(config HedgeConfig) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.config = config
+}
+
+// GetConfig returns the current hedging configuration
+func (h *AutoHedger) GetConfig() HedgeConfig {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.config
+}
+
+// Evaluate compares current net exposure with the bands and sends the hedge
+// adjustments. Orders go to the LP without holding the lock; a symbol is not
+// adjusted again until its last order is reported filled, rejected or expired.
+func (h *AutoHedger) Evaluate() []HedgeAction {
+	if h.exposureFn == nil || h.executeFn == nil {
+		return nil
+	}
+
+	exposure := h.exposureFn()
+
+	h.mu.Lock()
+	h.expirePendingLocked(time.Now())
+
+	// Symbols with an existing hedge but no remaining exposure must be unwound too
+	for symbol := range h.hedges {
+		if _, ok := exposure[symbol]; !ok {
+			exposure[symbol] = 0
+		}
+	}
+
+	var planned []*HedgeAction
+	for symbol, net := range exposure {
+		if h.inFlight[symbol] {
+			continue
+		}
+		current := h.hedges[symbol]
+		target := h.targetHedge(net, current)
+		delta := target - current
+
+		if math.Abs(delta) < h.config.MinTradeSize || delta == 0 {
+			continue
+		}
+
+		side := "BUY"
+		if delta < 0 {
+			side = "SELL"
+		}
+		planned = append(planned, &HedgeAction{
+			Symbol:      symbol,
+			Side:        side,
+			Volume:      math.Round(math.Abs(delta)*100) / 100,
+			NetExposure: net,
+			HedgeBefore: current,
+			HedgeAfter:  current,
+			Reason:      hedgeReason(current, target),
+			Timestamp:   time.Now(),
+		})
+		h.inFlight[symbol] = true
+	}
+	h.sending += len(planned)
+	h.mu.Unlock()
+
+	actions := make([]HedgeAction, 0, len(planned))
+	for _, action := range planned {
+		lpOrderID, err := h.executeFn(action.Symbol, action.Side, action.Volume)
+
+		h.mu.Lock()
+		h.sending--
+		if err != nil {
+			action.Status = "FAILED"
+			action.Error = err.Error()
+			delete(h.inFlight, action.Symbol)
+			log.Printf("[AutoHedge] %s %s %.2f lots failed: %v", action.Side, action.Symbol, action.Volume, err)
+		} else {
+			action.Status = "SENT"
+			action.LPOrderID = lpOrderID
+			direction := 1.0
+			if action.Side == "SELL" {
+				direction = -1
+			}
+			h.pending[lpOrderID] = &pendingHedge{action: action, direction: direction, remaining: action.Volume, sentAt: time.Now()}
+			log.Printf("[AutoHedge] %s: %s %.2f lots sent to LP as %s (net exposure %.2f, hedge %.2f)",
+				action.Reason, action.Side, action.Volume, lpOrderID, action.NetExposure, action.HedgeBefore)
+			h.replayEarlyLocked(lpOrderID)
+		}
+		if h.sending == 0 {
+			h.early = nil
+		}
+		h.recordAction(action)
+		actions = append(actions, *action)
+		h.mu.Unlock()
+	}
+
+	return actions
+}
+
+// OnExecutionReport applies an LP execution report to the hedge order it
+// belongs to, including orders that expired before the LP answered. Reports
+// of other orders are ignored.
+func (h *AutoHedger) OnExecutionReport(report fix.ExecutionReport) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	id := hedgeReportID(report)
+	_, live := h.pending[id]
+	_, expired := h.expired[id]
+	if !live && !expired {
+		// The LP can answer before executeFn has returned the order ID
+		if h.sending > 0 {
+			h.early = append(h.early, report)
+		}
+		return
+	}
+	h.applyReportLocked(id, report)
+}
+
+// hedgeReportID returns the order ID executeFn returned for a report's order
+func hedgeReportID(report fix.ExecutionReport) string {
+	if report.ClOrdID != "" {
+		return report.ClOrdID
+	}
+	return report.OrderID
+}
+
+// replayEarlyLocked applies reports of lpOrderID that arrived before it was
+// registered (caller must hold lock)
+func (h *AutoHedger) replayEarlyLocked(lpOrderID string) {
+	kept := h.early[:0]
+	for _, report := range h.early {
+		if hedgeReportID(report) == lpOrderID {
+			h.applyReportLocked(lpOrderID, report)
+		} else {
+			kept = append(kept, report)
+		}
+	}
+	h.early = kept
+}
+
+// applyReportLocked moves the hedge by a report's fill and settles the order
+// once it is done (caller must hold lock)
+func (h *AutoHedger) applyReportLocked(lpOrderID string, report fix.ExecutionReport) {
+	p, live := h.pending[lpOrderID]
+	if !live {
+		p = h.expired[lpOrderID]
+	}
+	action := p.action
+
+	switch report.ExecType {
+	case "PARTIAL", "FILLED":
+		qty := math.Min(report.Volume, p.remaining)
+		if report.CumQty > 0 {
+			qty = math.Min(report.CumQty-action.FilledQty, p.remaining)
+		}
+		if qty > 0 {
+			hedge := h.hedges[action.Symbol] + p.direction*qty
+			if math.Abs(hedge) < 1e-9 {
+				delete(h.hedges, action.Symbol)
+				hedge = 0
+			} else {
+				h.hedges[action.Symbol] = hedge
+			}
+			p.remaining -= qty
+			action.FilledQty += qty
+			action.HedgeAfter = hedge
+		}
+		if live {
+			action.Status = "PARTIAL"
+		} else if qty > 0 {
+			log.Printf("[AutoHedge] %s: late fill of %.2f lots after expiry (hedge %.2f)",
+				lpOrderID, qty, action.HedgeAfter)
+		}
+		if report.ExecType == "FILLED" || p.remaining <= 1e-9 {
+			action.Status = "FILLED"
+			action.Error = ""
+			h.settleLocked(lpOrderID)
+			log.Printf("[AutoHedge] %s filled %.2f lots of %s (hedge %.2f -> %.2f)",
+				lpOrderID, action.FilledQty, action.Symbol, action.HedgeBefore, action.HedgeAfter)
+		}
+
+	case "REJECTED", "CANCELED":
+		action.Status = report.ExecType
+		action.Error = report.Text
+		h.settleLocked(lpOrderID)
+		log.Printf("[AutoHedge] %s %s after %.2f of %.2f lots: %s",
+			lpOrderID, report.ExecType, action.FilledQty, action.Volume, report.Text)
+	}
+}
+
+// settleLocked stops tracking a hedge order once it is done (caller must hold lock)
+func (h *AutoHedger) settleLocked(lpOrderID string) {
+	if p, ok := h.pending[lpOrderID]; ok {
+		delete(h.inFlight, p.action.Symbol)
+		delete(h.pending, lpOrderID)
+	}
+	delete(h.expired, lpOrderID)
+}
+
+// expirePendingLocked stops orders without a final report for
+// hedgeFillTimeout from blocking their symbol, so it can be re-evaluated
+// against the hedge filled so far. The orders are kept until the LP reports
+// them done, so fills arriving late still move the hedge (caller must hold lock).
+func (h *AutoHedger) expirePendingLocked(now time.Time) {
+	for id, p := range h.pending {
+		if now.Sub(p.sentAt) < hedgeFillTimeout {
+			continue
+		}
+		p.action.Status = "EXPIRED"
+		p.action.Error = "no execution report from LP"
+		delete(h.inFlight, p.action.Symbol)
+		delete(h.pending, id)
+		h.expired[id] = p
+		log.Printf("[AutoHedge] %s: no execution report after %v, %.2f of %.2f lots filled",
+			id, hedgeFillTimeout, p.action.FilledQty, p.action.Volume)
+	}
+}
+
+// targetHedge returns the desired LP hedge for a symbol (caller must hold lock).
+// Clients net long means the broker is short, so the hedge has the same sign
+// as client exposure.
+func (h *AutoHedger) targetHedge(net, current float64) float64 {
+	absNet := math.Abs(net)
+	full := net * h.config.HedgeRatio
+
+	if absNet < h.config.LowerBand {
+		return 0
+	}
+	if absNet >= h.config.UpperBand {
+		return full
+	}
+
+	// Inside the band: hold the hedge, but never keep more than the exposure
+	// justifies and never keep a hedge pointing the wrong way
+	if current == 0 {
+		return 0
+	}
+	if (current > 0) != (net > 0) {
+		return 0
+	}
+	if math.Abs(current) > math.Abs(full) {
+		return full
+	}
+	return current
+}
+
+// recordAction appends to the bounded action history (caller must hold lock)
+func (h *AutoHedger) recordAction(action *HedgeAction) {
+	h.actions = append(h.actions, action)
+	if len(h.actions) > h.maxActions {
+		h.actions = h.actions[len(h.actions)-h.maxActions:]
+	}
+}
+
+func hedgeReason(current, target float64) string {
+	switch {
+	case current == 0:
+		return "OPEN"
+	case target == 0:
+		return "UNWIND"
+	case math.Abs(target) > math.Abs(current):
+		return "INCREASE"
+	default:
+		return "REDUCE"
+	}
+}
+
+// GetHedges returns the current net hedge per symbol
+func (h *AutoHedger) GetHedges() map[string]float64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	hedges := make(map[string]float64, len(h.hedges))
+	for k, v := range h.hedges {
+		hedges[k] = v
+	}
+	return hedges
+}
+
+// GetActions returns recorded hedge actions, most recent last
+func (h *AutoHedger) GetActions(limit int) []HedgeAction {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	start := 0
+	if limit > 0 && len(h.actions) > limit {
+		start = len(h.actions) - limit
+	}
+	actions := make([]HedgeAction, 0, len(h.actions)-start)
+	for _, action := range h.actions[start:] {
+		actions = append(actions, *action)
+	}
+	return actions
+}
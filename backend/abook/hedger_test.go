@@ -0,0 +1,263 @@
+package abook
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/fix"
+)
+
+type hedgeOrder struct {
+	symbol string
+	side   string
+	volume float64
+}
+
+// newTestHedger returns a hedger whose LP fills each order in full, reporting
+// the fill before executeFn returns as a fast LP can
+func newTestHedger(exposure map[string]float64, orders *[]hedgeOrder, fail bool) *AutoHedger {
+	config := DefaultHedgeConfig()
+	config.Enabled = true
+	config.UpperBand = 5.0
+	config.LowerBand = 2.0
+
+	var hedger *AutoHedger
+	hedger = NewAutoHedger(config,
+		func() map[string]float64 {
+			copied := make(map[string]float64, len(exposure))
+			for k, v := range exposure {
+				copied[k] = v
+			}
+			return copied
+		},
+		func(symbol, side string, volume float64) (string, error) {
+			if fail {
+				return "", errors.New("session not logged in")
+			}
+			*orders = append(*orders, hedgeOrder{symbol, side, volume})
+			id := fmt.Sprintf("HEDGE-%d", len(*orders))
+			hedger.OnExecutionReport(fix.ExecutionReport{ClOrdID: id, ExecType: "FILLED", Symbol: symbol, Side: side, Volume: volume})
+			return id, nil
+		},
+	)
+	return hedger
+}
+
+// TestAutoHedgerOpensHedgeAboveUpperBand tests that crossing the upper band opens a hedge
+func TestAutoHedgerOpensHedgeAboveUpperBand(t *testing.T) {
+	exposure := map[string]float64{"EURUSD": 4.0}
+	var orders []hedgeOrder
+	hedger := newTestHedger(exposure, &orders, false)
+
+	// Below upper band - no hedge
+	hedger.Evaluate()
+	if len(orders) != 0 {
+		t.Fatalf("expected no hedge below upper band, got %d orders", len(orders))
+	}
+
+	// Clients go net long 6 lots -> broker buys 6 lots at LP
+	exposure["EURUSD"] = 6.0
+	actions := hedger.Evaluate()
+	if len(orders) != 1 {
+		t.Fatalf("expected 1 hedge order, got %d", len(orders))
+	}
+	if orders[0].side != "BUY" || orders[0].volume != 6.0 {
+		t.Errorf("hedge order = %s %.2f, want BUY 6.00", orders[0].side, orders[0].volume)
+	}
+	if len(actions) != 1 || actions[0].Reason != "OPEN" {
+		t.Errorf("expected OPEN action, got %+v", actions)
+	}
+	if got := hedger.GetHedges()["EURUSD"]; got != 6.0 {
+		t.Errorf("hedge = %.2f, want 6.00", got)
+	}
+
+	// Net short exposure opens a SELL hedge
+	exposure["GBPUSD"] = -8.0
+	hedger.Evaluate()
+	if got := hedger.GetHedges()["GBPUSD"]; got != -8.0 {
+		t.Errorf("GBPUSD hedge = %.2f, want -8.00", got)
+	}
+}
+
+// TestAutoHedgerReducesHedgeBelowLowerBand tests that falling below the lower band unwinds the hedge
+func TestAutoHedgerReducesHedgeBelowLowerBand(t *testing.T) {
+	exposure := map[string]float64{"EURUSD": 10.0}
+	var orders []hedgeOrder
+	hedger := newTestHedger(exposure, &orders, false)
+
+	hedger.Evaluate()
+	if got := hedger.GetHedges()["EURUSD"]; got != 10.0 {
+		t.Fatalf("hedge = %.2f, want 10.00", got)
+	}
+
+	// Partial closes inside the band cap the hedge at the remaining exposure
+	exposure["EURUSD"] = 3.0
+	hedger.Evaluate()
+	if got := hedger.GetHedges()["EURUSD"]; got != 3.0 {
+		t.Errorf("hedge after partial close = %.2f, want 3.00", got)
+	}
+	last := orders[len(orders)-1]
+	if last.side != "SELL" || last.volume != 7.0 {
+		t.Errorf("reduce order = %s %.2f, want SELL 7.00", last.side, last.volume)
+	}
+
+	// Holding inside the band does not trade again
+	count := len(orders)
+	hedger.Evaluate()
+	if len(orders) != count {
+		t.Errorf("expected no trade inside band, got %d new orders", len(orders)-count)
+	}
+
+	// Below lower band - hedge fully unwound
+	exposure["EURUSD"] = 1.0
+	actions := hedger.Evaluate()
+	if len(actions) != 1 || actions[0].Reason != "UNWIND" {
+		t.Fatalf("expected UNWIND action, got %+v", actions)
+	}
+	if _, ok := hedger.GetHedges()["EURUSD"]; ok {
+		t.Error("hedge should be removed after unwind")
+	}
+
+	if len(hedger.GetActions(0)) != 3 {
+		t.Errorf("recorded actions = %d, want 3", len(hedger.GetActions(0)))
+	}
+}
+
+// TestAutoHedgerFailedOrderKeepsHedge tests that LP failures are recorded without changing state
+func TestAutoHedgerFailedOrderKeepsHedge(t *testing.T) {
+	exposure := map[string]float64{"EURUSD": 6.0}
+	var orders []hedgeOrder
+	hedger := newTestHedger(exposure, &orders, true)
+
+	actions := hedger.Evaluate()
+	if len(actions) != 1 || actions[0].Error == "" {
+		t.Fatalf("expected failed action, got %+v", actions)
+	}
+	if len(hedger.GetHedges()) != 0 {
+		t.Error("hedge should not be recorded when LP order fails")
+	}
+}
+
+// TestAutoHedgerFollowsExecutionReports tests that orders are sent without
+// the hedger's lock held, the hedge moves only by reported fills, and a
+// symbol is not re-hedged while its order is outstanding
+func TestAutoHedgerFollowsExecutionReports(t *testing.T) {
+	config := DefaultHedgeConfig()
+	config.Enabled = true
+	config.UpperBand = 5.0
+	config.LowerBand = 2.0
+
+	var hedger *AutoHedger
+	sent := 0
+	hedger = NewAutoHedger(config,
+		func() map[string]float64 { return map[string]float64{"EURUSD": 6.0} },
+		func(symbol, side string, volume float64) (string, error) {
+			hedger.GetHedges() // Deadlocks if Evaluate still holds the lock
+			sent++
+			return fmt.Sprintf("HEDGE-%d", sent), nil
+		},
+	)
+
+	done := make(chan []HedgeAction)
+	go func() { done <- hedger.Evaluate() }()
+	select {
+	case actions := <-done:
+		if len(actions) != 1 || actions[0].Status != "SENT" {
+			t.Fatalf("actions = %+v, want one SENT", actions)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Evaluate held the lock while sending to the LP")
+	}
+	if got := hedger.GetHedges()["EURUSD"]; got != 0 {
+		t.Errorf("hedge before any fill = %.2f, want 0", got)
+	}
+
+	// Still outstanding: no second order for the same exposure
+	hedger.Evaluate()
+	if sent != 1 {
+		t.Fatalf("orders sent = %d while the first is outstanding, want 1", sent)
+	}
+
+	hedger.OnExecutionReport(fix.ExecutionReport{ClOrdID: "HEDGE-1", ExecType: "PARTIAL", Volume: 2.5, CumQty: 2.5})
+	if got := hedger.GetHedges()["EURUSD"]; got != 2.5 {
+		t.Errorf("hedge after partial fill = %.2f, want 2.50", got)
+	}
+	hedger.OnExecutionReport(fix.ExecutionReport{ClOrdID: "HEDGE-1", ExecType: "CANCELED", Text: "IOC remainder"})
+	if action := hedger.GetActions(1)[0]; action.Status != "CANCELED" || action.FilledQty != 2.5 {
+		t.Errorf("action = %+v, want CANCELED with 2.50 filled", action)
+	}
+
+	// Settled: the unfilled 3.5 lots are hedged by a new order
+	actions := hedger.Evaluate()
+	if sent != 2 || len(actions) != 1 || actions[0].Volume != 3.5 {
+		t.Fatalf("after cancel sent = %d, actions = %+v, want a 3.50 lot top-up", sent, actions)
+	}
+	hedger.OnExecutionReport(fix.ExecutionReport{ClOrdID: "HEDGE-2", ExecType: "REJECTED", Text: "no liquidity"})
+	if got := hedger.GetHedges()["EURUSD"]; got != 2.5 {
+		t.Errorf("hedge after reject = %.2f, want 2.50", got)
+	}
+}
+
+// TestAutoHedgerRestart tests that stopping and restarting the hedger stops
+// each evaluation loop on its own stop channel; run with -race
+func TestAutoHedgerRestart(t *testing.T) {
+	var orders []hedgeOrder
+	hedger := newTestHedger(map[string]float64{}, &orders, false)
+	config := hedger.GetConfig()
+	config.CheckInterval = time.Millisecond
+	hedger.SetConfig(config)
+
+	for i := 0; i < 20; i++ {
+		hedger.Start()
+		time.Sleep(2 * time.Millisecond)
+		hedger.Stop()
+	}
+}
+
+// TestAutoHedgerAppliesFillsAfterExpiry tests that a fill the LP reports
+// after the order expired still moves the hedge, so the symbol is not
+// hedged twice
+func TestAutoHedgerAppliesFillsAfterExpiry(t *testing.T) {
+	config := DefaultHedgeConfig()
+	config.Enabled = true
+	config.UpperBand = 5.0
+	config.LowerBand = 2.0
+
+	sent := 0
+	hedger := NewAutoHedger(config,
+		func() map[string]float64 { return map[string]float64{"EURUSD": 6.0} },
+		func(symbol, side string, volume float64) (string, error) {
+			sent++
+			return fmt.Sprintf("HEDGE-%d", sent), nil
+		},
+	)
+
+	hedger.Evaluate()
+	hedger.mu.Lock()
+	hedger.expirePendingLocked(time.Now().Add(hedgeFillTimeout))
+	hedger.mu.Unlock()
+	if action := hedger.GetActions(1)[0]; action.Status != "EXPIRED" {
+		t.Fatalf("action = %+v, want EXPIRED", action)
+	}
+
+	hedger.OnExecutionReport(fix.ExecutionReport{ClOrdID: "HEDGE-1", ExecType: "FILLED", Volume: 6.0, CumQty: 6.0})
+	if got := hedger.GetHedges()["EURUSD"]; got != 6.0 {
+		t.Errorf("hedge after late fill = %.2f, want 6.00", got)
+	}
+	if action := hedger.GetActions(1)[0]; action.Status != "FILLED" || action.FilledQty != 6.0 {
+		t.Errorf("action = %+v, want FILLED with 6.00 filled", action)
+	}
+
+	// The late fill already covers the exposure: nothing is re-hedged
+	if actions := hedger.Evaluate(); len(actions) != 0 || sent != 1 {
+		t.Errorf("after late fill sent = %d, actions = %+v, want no new order", sent, actions)
+	}
+
+	// The order is done: a duplicate report no longer moves the hedge
+	hedger.OnExecutionReport(fix.ExecutionReport{ClOrdID: "HEDGE-1", ExecType: "FILLED", Volume: 6.0, CumQty: 6.0})
+	if got := hedger.GetHedges()["EURUSD"]; got != 6.0 {
+		t.Errorf("hedge after duplicate report = %.2f, want 6.00", got)
+	}
+}
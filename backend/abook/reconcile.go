@@ -0,0 +1,288 @@
+package abook
+
+import (
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/epic1st/rtx/backend/fix"
+)
+
+// Reconciliation defaults
+const (
+	defaultReconcileTimeout        = 30 * time.Second
+	defaultReconcilePriceTolerance = 0.001 // Fill vs requested price, as a fraction of the requested price
+	reconcileCheckInterval         = 5 * time.Second
+	maxReconciledOrders            = 5000
+)
+
+// Reconciliation statuses of an order sent to an LP
+const (
+	ReconPending   = "PENDING"   // Sent, no outcome reported yet
+	ReconPartial   = "PARTIAL"   // Partly filled
+	ReconFilled    = "FILLED"    // Fully filled
+	ReconRejected  = "REJECTED"  // Rejected by the LP
+	ReconCanceled  = "CANCELED"  // Canceled at the LP
+	ReconTimedOut  = "TIMED_OUT" // No fill reported within the timeout
+	ReconUnmatched = "UNMATCHED" // Report for a ClOrdID the engine never sent
+)
+
+// Reconciliation issue kinds
+const (
+	IssueTimeout          = "TIMEOUT"
+	IssuePriceDiscrepancy = "PRICE_DISCREPANCY"
+	IssueUnmatchedReport  = "UNMATCHED_REPORT"
+)
+
+// ReconciledOrder is what the engine sent under one ClOrdID next to what the
+// LP reported for it
+type ReconciledOrder struct {
+	ClOrdID          string     `json:"clOrdId"`
+	OrderID          string     `json:"orderId,omitempty"`
+	Symbol           string     `json:"symbol"`
+	Side             string     `json:"side"`
+	LP               string     `json:"lp"`
+	Quantity         float64    `json:"quantity"`
+	RequestedPrice   float64    `json:"requestedPrice"` // Limit or quoted price
+	Status           string     `json:"status"`
+	FilledQty        float64    `json:"filledQty"`
+	AvgFillPrice     float64    `json:"avgFillPrice"`
+	PriceDiff        float64    `json:"priceDiff"` // Avg fill minus requested price
+	PriceDiscrepancy bool       `json:"priceDiscrepancy"`
+	LPOrderID        string     `json:"lpOrderId,omitempty"`
+	Text             string     `json:"text,omitempty"`
+	SentAt           time.Time  `json:"sentAt"`
+	LastReportAt     *time.Time `json:"lastReportAt,omitempty"`
+}
+
+// ReconciliationIssue is an order the LP's reports don't account for
+type ReconciliationIssue struct {
+	Kind       string          `json:"kind"`
+	Order      ReconciledOrder `json:"order"`
+	DetectedAt time.Time       `json:"detectedAt"`
+}
+
+// ReconciliationReport summarises reconciliation of recent LP orders
+type ReconciliationReport struct {
+	GeneratedAt    time.Time             `json:"generatedAt"`
+	Timeout        string                `json:"timeout"`
+	PriceTolerance float64               `json:"priceTolerance"`
+	Counts         map[string]int        `json:"counts"` // By status
+	Issues         []ReconciliationIssue `json:"issues"`
+	Orders         []ReconciledOrder     `json:"orders"` // Newest first
+}
+
+// reconciler matches ClOrdIDs sent via FIX to the ExecutionReports coming back
+type reconciler struct {
+	mu        sync.Mutex
+	orders    map[string]*ReconciledOrder // ClOrdID -> order
+	sequence  []string                    // ClOrdIDs in the order they were seen
+	issues    []ReconciliationIssue
+	timeout   time.Duration
+	tolerance float64
+	onIssue   func(ReconciliationIssue)
+	now       func() time.Time
+}
+
+func newReconciler() *reconciler {
+	return &reconciler{
+		orders:    make(map[string]*ReconciledOrder),
+		timeout:   defaultReconcileTimeout,
+		tolerance: defaultReconcilePriceTolerance,
+		now:       time.Now,
+	}
+}
+
+// track records an order sent under clOrdID
+func (r *reconciler) track(clOrdID string, order *Order, lp string, volume, requestedPrice float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.addLocked(&ReconciledOrder{
+		ClOrdID:        clOrdID,
+		OrderID:        order.ID,
+		Symbol:         order.Symbol,
+		Side:           order.Side,
+		LP:             lp,
+		Quantity:       volume,
+		RequestedPrice: requestedPrice,
+		Status:         ReconPending,
+		SentAt:         r.now(),
+	})
+}
+
+// observe matches an ExecutionReport to the order sent under its ClOrdID
+func (r *reconciler) observe(report *fix.ExecutionReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	entry, ok := r.orders[report.ClOrdID]
+	if !ok {
+		entry = &ReconciledOrder{
+			ClOrdID: report.ClOrdID,
+			Symbol:  report.Symbol,
+			Side:    report.Side,
+			Status:  ReconUnmatched,
+			SentAt:  now,
+		}
+		r.addLocked(entry)
+		r.raiseLocked(IssueUnmatchedReport, entry, now)
+	}
+	entry.LastReportAt = &now
+	if report.OrderID != "" {
+		entry.LPOrderID = report.OrderID
+	}
+	if report.Text != "" {
+		entry.Text = report.Text
+	}
+
+	switch report.ExecType {
+	case "PARTIAL", "FILLED":
+		switch {
+		case report.CumQty > 0:
+			entry.FilledQty = report.CumQty
+		case report.Volume > 0:
+			entry.FilledQty += report.Volume
+		}
+		switch {
+		case report.AvgPx > 0:
+			entry.AvgFillPrice = report.AvgPx
+		case report.Volume > 0 && entry.FilledQty > 0:
+			entry.AvgFillPrice += (report.Price - entry.AvgFillPrice) * report.Volume / entry.FilledQty
+		case entry.AvgFillPrice == 0:
+			entry.AvgFillPrice = report.Price
+		}
+		if entry.Status != ReconUnmatched {
+			entry.Status = ReconPartial
+			if report.ExecType == "FILLED" || entry.FilledQty >= entry.Quantity-1e-9 {
+				entry.Status = ReconFilled
+			}
+		}
+		r.checkPriceLocked(entry, now)
+	case "REJECTED":
+		if entry.Status != ReconUnmatched {
+			entry.Status = ReconRejected
+		}
+	case "CANCELED":
+		if entry.Status != ReconUnmatched {
+			entry.Status = ReconCanceled
+		}
+	}
+}
+
+// checkPriceLocked flags a fill away from the requested price by more than the
+// tolerance, once per order (caller must hold lock)
+func (r *reconciler) checkPriceLocked(entry *ReconciledOrder, now time.Time) {
+	if entry.RequestedPrice <= 0 || entry.AvgFillPrice <= 0 {
+		return
+	}
+	entry.PriceDiff = entry.AvgFillPrice - entry.RequestedPrice
+	if math.Abs(entry.PriceDiff)/entry.RequestedPrice <= r.tolerance || entry.PriceDiscrepancy {
+		return
+	}
+	entry.PriceDiscrepancy = true
+	r.raiseLocked(IssuePriceDiscrepancy, entry, now)
+}
+
+// checkTimeouts flags pending orders sent longer ago than the timeout
+func (r *reconciler) checkTimeouts() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	for _, clOrdID := range r.sequence {
+		entry := r.orders[clOrdID]
+		if entry.Status == ReconPending && now.Sub(entry.SentAt) > r.timeout {
+			entry.Status = ReconTimedOut
+			r.raiseLocked(IssueTimeout, entry, now)
+		}
+	}
+}
+
+// raiseLocked records an issue and notifies the callback (caller must hold lock)
+func (r *reconciler) raiseLocked(kind string, entry *ReconciledOrder, now time.Time) {
+	issue := ReconciliationIssue{Kind: kind, Order: *entry, DetectedAt: now}
+	r.issues = append(r.issues, issue)
+	if len(r.issues) > maxReconciledOrders {
+		r.issues = r.issues[len(r.issues)-maxReconciledOrders:]
+	}
+	log.Printf("[A-Book] Reconciliation %s: %s %s %s %.2f via %s", kind, entry.ClOrdID, entry.Side, entry.Symbol, entry.Quantity, entry.LP)
+	if r.onIssue != nil {
+		go r.onIssue(issue)
+	}
+}
+
+// addLocked stores an order, dropping the oldest beyond the cap (caller must hold lock)
+func (r *reconciler) addLocked(entry *ReconciledOrder) {
+	if _, exists := r.orders[entry.ClOrdID]; !exists {
+		r.sequence = append(r.sequence, entry.ClOrdID)
+	}
+	r.orders[entry.ClOrdID] = entry
+	for len(r.sequence) > maxReconciledOrders {
+		delete(r.orders, r.sequence[0])
+		r.sequence = r.sequence[1:]
+	}
+}
+
+// report returns the reconciliation state, newest orders first
+func (r *reconciler) report() ReconciliationReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := ReconciliationReport{
+		GeneratedAt:    r.now(),
+		Timeout:        r.timeout.String(),
+		PriceTolerance: r.tolerance,
+		Counts:         make(map[string]int),
+		Issues:         append([]ReconciliationIssue(nil), r.issues...),
+		Orders:         make([]ReconciledOrder, 0, len(r.sequence)),
+	}
+	for _, clOrdID := range r.sequence {
+		entry := r.orders[clOrdID]
+		report.Counts[entry.Status]++
+		report.Orders = append(report.Orders, *entry)
+	}
+	sort.SliceStable(report.Orders, func(i, j int) bool {
+		return report.Orders[i].SentAt.After(report.Orders[j].SentAt)
+	})
+	return report
+}
+
+// run checks for timed out orders until the process exits
+func (r *reconciler) run() {
+	ticker := time.NewTicker(reconcileCheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		r.checkTimeouts()
+	}
+}
+
+// GetReconciliationReport returns sent orders matched against the LP's
+// execution reports, with unreconciled orders listed as issues
+func (e *ExecutionEngine) GetReconciliationReport() ReconciliationReport {
+	return e.reconciler.report()
+}
+
+// SetReconciliationPolicy sets how long an order may go without a fill and how
+// far (as a fraction of the requested price) a fill may be from the requested
+// price before either is flagged. Zero keeps the current value.
+func (e *ExecutionEngine) SetReconciliationPolicy(timeout time.Duration, priceTolerance float64) {
+	e.reconciler.mu.Lock()
+	defer e.reconciler.mu.Unlock()
+	if timeout > 0 {
+		e.reconciler.timeout = timeout
+	}
+	if priceTolerance > 0 {
+		e.reconciler.tolerance = priceTolerance
+	}
+}
+
+// SetOnReconciliationIssue sets the callback for unreconciled orders
+func (e *ExecutionEngine) SetOnReconciliationIssue(callback func(ReconciliationIssue)) {
+	e.reconciler.mu.Lock()
+	defer e.reconciler.mu.Unlock()
+	e.reconciler.onIssue = callback
+}
@@ -0,0 +1,78 @@
+package abook
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/fix"
+)
+
+// TestReconcileFilledRejectedTimedOut tests that a filled and a rejected
+// order reconcile, an order without a report times out, and a fill away from
+// the requested price or for an unknown ClOrdID is flagged
+func TestReconcileFilledRejectedTimedOut(t *testing.T) {
+	r := newReconciler()
+	clock := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return clock }
+	issues := make(chan ReconciliationIssue, 8)
+	r.onIssue = func(issue ReconciliationIssue) { issues <- issue }
+
+	order := func(id string) *Order { return &Order{ID: id, Symbol: "EURUSD", Side: "BUY", Volume: 1} }
+	r.track("CL-FILL", order("o-1"), "lp-a", 1, 1.10000)
+	r.track("CL-REJECT", order("o-2"), "lp-a", 1, 1.10000)
+	r.track("CL-SLOW", order("o-3"), "lp-b", 1, 1.10000)
+	r.track("CL-SLIP", order("o-4"), "lp-b", 2, 1.10000)
+
+	r.observe(&fix.ExecutionReport{ClOrdID: "CL-FILL", ExecType: "NEW", OrderID: "LP-1"})
+	r.observe(&fix.ExecutionReport{ClOrdID: "CL-FILL", ExecType: "FILLED", Volume: 1, Price: 1.10002})
+	r.observe(&fix.ExecutionReport{ClOrdID: "CL-REJECT", ExecType: "REJECTED", Text: "No liquidity"})
+	r.observe(&fix.ExecutionReport{ClOrdID: "CL-SLIP", ExecType: "FILLED", Volume: 1, Price: 1.10100})
+	r.observe(&fix.ExecutionReport{ClOrdID: "CL-SLIP", ExecType: "FILLED", Volume: 1, Price: 1.10300})
+	r.observe(&fix.ExecutionReport{ClOrdID: "CL-GHOST", ExecType: "FILLED", Symbol: "GBPUSD", Volume: 1, Price: 1.27})
+
+	// Nothing times out before the timeout
+	r.checkTimeouts()
+	clock = clock.Add(defaultReconcileTimeout + time.Second)
+	r.checkTimeouts()
+
+	byID := make(map[string]ReconciledOrder)
+	report := r.report()
+	for _, o := range report.Orders {
+		byID[o.ClOrdID] = o
+	}
+	if o := byID["CL-FILL"]; o.Status != ReconFilled || o.AvgFillPrice != 1.10002 || o.PriceDiscrepancy || o.LPOrderID != "LP-1" {
+		t.Errorf("filled order = %+v, want FILLED at 1.10002 without discrepancy", o)
+	}
+	if o := byID["CL-REJECT"]; o.Status != ReconRejected || o.Text != "No liquidity" {
+		t.Errorf("rejected order = %+v, want REJECTED with the LP's text", o)
+	}
+	if o := byID["CL-SLOW"]; o.Status != ReconTimedOut {
+		t.Errorf("unreported order = %+v, want TIMED_OUT", o)
+	}
+	if o := byID["CL-SLIP"]; o.Status != ReconFilled || !o.PriceDiscrepancy || math.Abs(o.AvgFillPrice-1.10200) > 1e-9 {
+		t.Errorf("slipped order = %+v, want FILLED at avg 1.10200 with a discrepancy", o)
+	}
+	if o := byID["CL-GHOST"]; o.Status != ReconUnmatched {
+		t.Errorf("unknown ClOrdID = %+v, want UNMATCHED", o)
+	}
+	if report.Counts[ReconFilled] != 2 || report.Counts[ReconTimedOut] != 1 {
+		t.Errorf("counts = %v, want 2 filled and 1 timed out", report.Counts)
+	}
+
+	kinds := make(map[string]string)
+	for range 3 {
+		select {
+		case issue := <-issues:
+			kinds[issue.Order.ClOrdID] = issue.Kind
+		case <-time.After(time.Second):
+			t.Fatalf("issues = %v, want three", kinds)
+		}
+	}
+	if kinds["CL-SLOW"] != IssueTimeout || kinds["CL-SLIP"] != IssuePriceDiscrepancy || kinds["CL-GHOST"] != IssueUnmatchedReport {
+		t.Errorf("issues = %v, want timeout, price discrepancy and unmatched report", kinds)
+	}
+	if len(report.Issues) != 3 {
+		t.Errorf("report issues = %+v, want 3", report.Issues)
+	}
+}
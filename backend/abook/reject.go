@@ -0,0 +1,120 @@
+package abook
+
+import (
+	"errors"
+	"time"
+)
+
+// FIX OrdRejReason (tag 103) codes and their client-facing reasons
+var fixRejectReasons = map[string]string{
+	"0":  "broker option",
+	"1":  "unknown symbol",
+	"2":  "market closed",
+	"3":  "order exceeds limit",
+	"4":  "too late to enter",
+	"5":  "unknown order",
+	"6":  "duplicate order",
+	"11": "unsupported order characteristic",
+	"13": "incorrect quantity",
+	"99": "other",
+}
+
+// rejectReasonText returns the reason for a FIX OrdRejReason code
+func rejectReasonText(code string) string {
+	if reason, ok := fixRejectReasons[code]; ok {
+		return reason
+	}
+	return "rejected by liquidity provider"
+}
+
+// RejectError is an order rejected by the LP, carrying the reject text (tag
+// 58) and reason code (tag 103) from its execution report
+type RejectError struct {
+	OrderID       string
+	ClientOrderID string
+	LP            string
+	Code          string // FIX OrdRejReason, "" if not reported
+	Text          string // LP's reject text, or the reason for Code
+}
+
+func (e *RejectError) Error() string {
+	return "Rejected: " + e.Text
+}
+
+// WaitForOrder waits up to timeout for the LP's first answer to a sent order:
+// an ack, fill, reject or cancel. It returns a *RejectError if the LP rejected
+// the order, and the order as it stands otherwise, still SENT if the LP only
+// acknowledged it or did not answer in time.
+func (e *ExecutionEngine) WaitForOrder(orderID string, timeout time.Duration) (*Order, error) {
+	e.mu.Lock()
+	order, exists := e.orders[orderID]
+	if !exists {
+		e.mu.Unlock()
+		return nil, errors.New("order not found")
+	}
+	if order.Status != "SENT" && order.Status != "PENDING" {
+		err := rejectErrorLocked(order)
+		e.mu.Unlock()
+		return order, err
+	}
+	ch := make(chan struct{})
+	if e.waiters == nil {
+		e.waiters = make(map[string][]chan struct{})
+	}
+	e.waiters[orderID] = append(e.waiters[orderID], ch)
+	e.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-ch:
+	case <-timer.C:
+		e.mu.Lock()
+		e.removeWaiterLocked(orderID, ch)
+		e.mu.Unlock()
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return order, rejectErrorLocked(order)
+}
+
+// notifyWaitersLocked wakes WaitForOrder callers once the LP has reported on
+// an order, including a NEW ack that leaves it SENT (caller must hold lock)
+func (e *ExecutionEngine) notifyWaitersLocked(order *Order) {
+	for _, ch := range e.waiters[order.ID] {
+		close(ch)
+	}
+	delete(e.waiters, order.ID)
+}
+
+// removeWaiterLocked drops a timed out waiter (caller must hold lock)
+func (e *ExecutionEngine) removeWaiterLocked(orderID string, ch chan struct{}) {
+	waiting := e.waiters[orderID]
+	for i, c := range waiting {
+		if c == ch {
+			waiting = append(waiting[:i], waiting[i+1:]...)
+			break
+		}
+	}
+	if len(waiting) == 0 {
+		delete(e.waiters, orderID)
+	} else {
+		e.waiters[orderID] = waiting
+	}
+}
+
+// rejectErrorLocked returns a *RejectError for a rejected order, nil otherwise
+// (caller must hold lock)
+func rejectErrorLocked(order *Order) error {
+	if order.Status != "REJECTED" {
+		return nil
+	}
+	return &RejectError{
+		OrderID:       order.ID,
+		ClientOrderID: order.ClientOrderID,
+		LP:            order.SelectedLP,
+		Code:          order.RejectCode,
+		Text:          order.RejectReason,
+	}
+}
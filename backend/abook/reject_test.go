@@ -0,0 +1,72 @@
+package abook
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/fix"
+)
+
+// TestWaitForOrderReturnsLPReject tests that a reject report arriving while
+// the placement waits comes back as a RejectError with the LP's reason
+func TestWaitForOrderReturnsLPReject(t *testing.T) {
+	e, order := newPartialFillTestEngine()
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		e.handleFIXExecutionReport(&fix.ExecutionReport{ClOrdID: "YOFX1_1", ExecType: "REJECTED",
+			Text: "market closed", RejReason: "2"})
+	}()
+
+	_, err := e.WaitForOrder(order.ID, 2*time.Second)
+	var rejectErr *RejectError
+	if !errors.As(err, &rejectErr) {
+		t.Fatalf("WaitForOrder() error = %v, want a RejectError", err)
+	}
+	if err.Error() != "Rejected: market closed" || rejectErr.Code != "2" {
+		t.Errorf("error = %q (code %q), want \"Rejected: market closed\" (code 2)", err, rejectErr.Code)
+	}
+	if len(e.waiters) != 0 {
+		t.Errorf("%d waiters left, want none", len(e.waiters))
+	}
+}
+
+// TestWaitForOrderRejectWithoutText tests that a reject carrying only
+// OrdRejReason is described by the code, and that an unanswered order times
+// out without error
+func TestWaitForOrderRejectWithoutText(t *testing.T) {
+	e, order := newPartialFillTestEngine()
+
+	if got, err := e.WaitForOrder(order.ID, 10*time.Millisecond); err != nil || got.Status != "SENT" {
+		t.Fatalf("WaitForOrder() = %v, %v; want the SENT order", got.Status, err)
+	}
+	if len(e.waiters) != 0 {
+		t.Errorf("%d waiters left after timeout, want none", len(e.waiters))
+	}
+
+	e.handleFIXExecutionReport(&fix.ExecutionReport{ClOrdID: "YOFX1_1", ExecType: "REJECTED", RejReason: "1"})
+	if _, err := e.WaitForOrder(order.ID, time.Second); err == nil || err.Error() != "Rejected: unknown symbol" {
+		t.Errorf("WaitForOrder() error = %v, want \"Rejected: unknown symbol\"", err)
+	}
+}
+
+// TestWaitForOrderWakesOnAck tests that a NEW ack ends the wait with the
+// order still SENT instead of blocking until the timeout
+func TestWaitForOrderWakesOnAck(t *testing.T) {
+	e, order := newPartialFillTestEngine()
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		e.handleFIXExecutionReport(&fix.ExecutionReport{ClOrdID: "YOFX1_1", ExecType: "NEW", OrderID: "LP-1"})
+	}()
+
+	start := time.Now()
+	got, err := e.WaitForOrder(order.ID, 2*time.Second)
+	if err != nil || got.Status != "SENT" {
+		t.Fatalf("WaitForOrder() = %v, %v; want the SENT order", got.Status, err)
+	}
+	if waited := time.Since(start); waited > time.Second {
+		t.Errorf("WaitForOrder() returned after %v, want right after the ack", waited)
+	}
+}
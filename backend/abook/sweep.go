@@ -0,0 +1,360 @@
+package abook
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/epic1st/rtx/backend/fix"
+)
+
+// standardLotSize converts LP quote sizes (units) to lots
+const standardLotSize = 100000.0
+
+// maxSlippageRecords bounds the slippage records kept, dropping the oldest
+const maxSlippageRecords = 10000
+
+// ErrInsufficientLiquidity is returned when the available depth cannot fill a sweep
+var ErrInsufficientLiquidity = errors.New("insufficient liquidity")
+
+// DepthLevel is one price level of an LP's book on the side an order takes
+type DepthLevel struct {
+	LP        string  `json:"lp"`
+	SessionID string  `json:"sessionId,omitempty"` // FIX session quoting the level, "" = mapped from the LP
+	Price     float64 `json:"price"`
+	Size      float64 `json:"size"` // Lots available at this price
+}
+
+// DepthProvider returns the levels an order on side would take, in any order
+type DepthProvider func(symbol, side string) []DepthLevel
+
+// SweepLeg is the part of a sweep executed at one LP
+type SweepLeg struct {
+	LP            string  `json:"lp"`
+	SessionID     string  `json:"sessionId,omitempty"`
+	Volume        float64 `json:"volume"`
+	ExpectedPrice float64 `json:"expectedPrice"` // VWAP of the levels this leg takes
+	LPOrderID     string  `json:"lpOrderId,omitempty"`
+	FilledQty     float64 `json:"filledQty"`
+	AvgFillPrice  float64 `json:"avgFillPrice,omitempty"`
+	Status        string  `json:"status,omitempty"` // SENT, PARTIAL, FILLED, REJECTED or CANCELED
+	RejectReason  string  `json:"rejectReason,omitempty"`
+}
+
+// settled reports whether the LP is done with the leg
+func (leg *SweepLeg) settled() bool {
+	return leg.Status == "FILLED" || leg.Status == "REJECTED" || leg.Status == "CANCELED"
+}
+
+// SweepPlan is the expected execution of an order walking LP depth
+type SweepPlan struct {
+	Symbol           string       `json:"symbol"`
+	Side             string       `json:"side"`
+	Volume           float64      `json:"volume"`
+	TopOfBook        float64      `json:"topOfBook"`        // Best price before the sweep
+	ExpectedVWAP     float64      `json:"expectedVwap"`     // Volume-weighted average of the levels taken
+	ExpectedSlippage float64      `json:"expectedSlippage"` // VWAP worse than top of book, in price
+	Levels           []DepthLevel `json:"levels"`           // Levels taken, the last possibly in part
+	Legs             []SweepLeg   `json:"legs"`             // One per LP
+}
+
+// SlippageRecord compares a filled order's expected and actual price, for best-execution reporting
+type SlippageRecord struct {
+	OrderID          string        `json:"orderId"`
+	Symbol           string        `json:"symbol"`
+	Side             string        `json:"side"`
+	LP               string        `json:"lp"`
+	Volume           float64       `json:"volume"`
+	TopOfBook        float64       `json:"topOfBook"`
+	ExpectedPrice    float64       `json:"expectedPrice"`
+	ActualPrice      float64       `json:"actualPrice"`
+	ExpectedSlippage float64       `json:"expectedSlippage"` // Against top of book, positive = worse
+	ActualSlippage   float64       `json:"actualSlippage"`
+	Latency          time.Duration `json:"latency"`
+	Timestamp        time.Time     `json:"timestamp"`
+}
+
+// adverse returns how much worse price is than reference for side (positive = worse)
+func adverse(side string, price, reference float64) float64 {
+	if side == "BUY" {
+		return price - reference
+	}
+	return reference - price
+}
+
+// planSweep walks levels best price first until volume is covered. With
+// split, the sweep takes liquidity across LPs; otherwise the whole order goes
+// to the single LP that can fill it at the best average price.
+func planSweep(symbol, side string, volume float64, levels []DepthLevel, split bool) (*SweepPlan, error) {
+	sorted := make([]DepthLevel, 0, len(levels))
+	for _, level := range levels {
+		if level.Price > 0 && level.Size > 0 {
+			sorted = append(sorted, level)
+		}
+	}
+	if len(sorted) == 0 {
+		return nil, fmt.Errorf("%w: no depth for %s %s", ErrInsufficientLiquidity, side, symbol)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Price == sorted[j].Price {
+			return sorted[i].LP < sorted[j].LP
+		}
+		if side == "BUY" {
+			return sorted[i].Price < sorted[j].Price
+		}
+		return sorted[i].Price > sorted[j].Price
+	})
+	topOfBook := sorted[0].Price
+
+	if split {
+		plan, err := walkLevels(symbol, side, volume, sorted)
+		if err != nil {
+			return nil, err
+		}
+		plan.TopOfBook = topOfBook
+		plan.ExpectedSlippage = adverse(side, plan.ExpectedVWAP, topOfBook)
+		return plan, nil
+	}
+
+	byLP := make(map[string][]DepthLevel)
+	for _, level := range sorted {
+		byLP[level.LP] = append(byLP[level.LP], level)
+	}
+	var best *SweepPlan
+	for _, lpLevels := range byLP {
+		plan, err := walkLevels(symbol, side, volume, lpLevels)
+		if err != nil {
+			continue
+		}
+		if best == nil || adverse(side, plan.ExpectedVWAP, best.ExpectedVWAP) < 0 ||
+			(plan.ExpectedVWAP == best.ExpectedVWAP && plan.Legs[0].LP < best.Legs[0].LP) {
+			best = plan
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("%w: no single LP can fill %.2f lots of %s", ErrInsufficientLiquidity, volume, symbol)
+	}
+	best.TopOfBook = topOfBook
+	best.ExpectedSlippage = adverse(side, best.ExpectedVWAP, topOfBook)
+	return best, nil
+}
+
+// walkLevels takes sorted levels in turn until volume is covered
+func walkLevels(symbol, side string, volume float64, levels []DepthLevel) (*SweepPlan, error) {
+	plan := &SweepPlan{Symbol: symbol, Side: side, Volume: volume}
+	legs := make(map[string]*SweepLeg)
+	var order []string
+	remaining := volume
+	var notional float64
+
+	for _, level := range levels {
+		if remaining <= 1e-9 {
+			break
+		}
+		take := math.Min(remaining, level.Size)
+		remaining -= take
+		notional += take * level.Price
+		plan.Levels = append(plan.Levels, DepthLevel{LP: level.LP, Price: level.Price, Size: take})
+
+		leg, ok := legs[level.LP]
+		if !ok {
+			leg = &SweepLeg{LP: level.LP, SessionID: level.SessionID}
+			legs[level.LP] = leg
+			order = append(order, level.LP)
+		}
+		leg.ExpectedPrice = (leg.ExpectedPrice*leg.Volume + level.Price*take) / (leg.Volume + take)
+		leg.Volume += take
+	}
+	if remaining > 1e-9 {
+		return nil, fmt.Errorf("%w: depth covers %.2f of %.2f lots of %s", ErrInsufficientLiquidity, volume-remaining, volume, symbol)
+	}
+
+	plan.ExpectedVWAP = notional / volume
+	for _, lp := range order {
+		plan.Legs = append(plan.Legs, *legs[lp])
+	}
+	return plan, nil
+}
+
+// GetDepth returns the top-of-book of every LP with a fresh quote as depth
+// levels for side. Sizes come from the LPs' quoted sizes.
+func (s *SmartOrderRouter) GetDepth(symbol, side string) []DepthLevel {
+	s.quoteCacheMu.RLock()
+	defer s.quoteCacheMu.RUnlock()
+
+	quote, exists := s.quoteCache[symbol]
+	if !exists {
+		return nil
+	}
+
+	levels := make([]DepthLevel, 0, len(quote.LPQuotes))
+	for lpID, lpQuote := range quote.LPQuotes {
+		if time.Since(lpQuote.Timestamp) > 5*time.Second {
+			continue
+		}
+		if side == "BUY" {
+			levels = append(levels, DepthLevel{LP: lpID, Price: lpQuote.Ask, Size: lpQuote.AskSize / standardLotSize})
+		} else {
+			levels = append(levels, DepthLevel{LP: lpID, Price: lpQuote.Bid, Size: lpQuote.BidSize / standardLotSize})
+		}
+	}
+	return levels
+}
+
+// GatewayDepth returns a depth provider reading the top of book the FIX
+// sessions quote, with the LP's quoted sizes
+func GatewayDepth(gw *fix.FIXGateway) DepthProvider {
+	return func(symbol, side string) []DepthLevel {
+		md, ok := gw.GetTopOfBook(symbol)
+		if !ok {
+			return nil
+		}
+		level := DepthLevel{LP: md.SessionID, SessionID: md.SessionID, Price: md.Bid, Size: md.BidSize / standardLotSize}
+		if side == "BUY" {
+			level.Price, level.Size = md.Ask, md.AskSize/standardLotSize
+		}
+		if level.Price <= 0 || level.Size <= 0 {
+			return nil
+		}
+		return []DepthLevel{level}
+	}
+}
+
+// SetDepthProvider replaces the depth sweeps are planned on. The LPs' top of
+// book is used while the provider has no depth for a symbol.
+func (e *ExecutionEngine) SetDepthProvider(provider DepthProvider) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.depthProvider = provider
+}
+
+// SetOnSlippageCallback sets the callback notified of expected vs actual slippage on fills
+func (e *ExecutionEngine) SetOnSlippageCallback(callback func(SlippageRecord)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onSlippage = callback
+}
+
+// GetSlippageRecords returns the expected vs actual slippage of filled orders
+func (e *ExecutionEngine) GetSlippageRecords() []SlippageRecord {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	records := make([]SlippageRecord, len(e.slippageRecords))
+	copy(records, e.slippageRecords)
+	return records
+}
+
+// PlanSweep returns the expected execution of a market order sweeping LP depth
+func (e *ExecutionEngine) PlanSweep(symbol, side string, volume float64, split bool) (*SweepPlan, error) {
+	e.mu.RLock()
+	provider := e.depthProvider
+	e.mu.RUnlock()
+
+	var levels []DepthLevel
+	if provider != nil {
+		levels = provider(symbol, side)
+	}
+	if len(levels) == 0 {
+		levels = e.sor.GetDepth(symbol, side)
+	}
+	return planSweep(symbol, side, volume, levels, split)
+}
+
+// routeSweep sends one order per sweep leg to its LP. Legs that fail to send
+// are dropped from the plan and the order shrinks to the legs sent; the sweep
+// fails only if no leg was sent.
+func (e *ExecutionEngine) routeSweep(order *Order) error {
+	fixSide := "1" // Buy
+	if order.Side == "SELL" {
+		fixSide = "2" // Sell
+	}
+
+	var sent []SweepLeg
+	var sentVolume float64
+	var lastErr error
+	for _, leg := range order.Sweep.Legs {
+		if leg.SessionID == "" {
+			leg.SessionID = e.sor.mapLPToSession(leg.LP)
+		}
+		clOrdID, err := e.fixGateway.SendOrder(leg.SessionID, order.Symbol, fixSide, leg.Volume, 0)
+		if err != nil {
+			log.Printf("[A-Book] Sweep leg %s %.2f lots failed: %v", leg.LP, leg.Volume, err)
+			lastErr = err
+			continue
+		}
+		leg.LPOrderID = clOrdID
+		leg.Status = "SENT"
+		e.reconciler.track(clOrdID, order, leg.LP, leg.Volume, order.ExpectedPrice)
+		sent = append(sent, leg)
+		sentVolume += leg.Volume
+	}
+	if len(sent) == 0 {
+		return fmt.Errorf("FIX order send failed: %w", lastErr)
+	}
+	if len(sent) < len(order.Sweep.Legs) {
+		log.Printf("[A-Book] Sweep order %s reduced to the %.2f of %.2f lots sent", order.ClientOrderID, sentVolume, order.Volume)
+		order.Volume = sentVolume
+	}
+	order.Sweep.Legs = sent
+	order.LPOrderID = sent[0].LPOrderID
+	return nil
+}
+
+// recordSlippageLocked records expected vs actual slippage of a filled order (caller must hold lock)
+func (e *ExecutionEngine) recordSlippageLocked(order *Order) {
+	if order.ExpectedPrice <= 0 {
+		return
+	}
+	topOfBook := order.ExpectedPrice
+	if order.Sweep != nil {
+		topOfBook = order.Sweep.TopOfBook
+	}
+
+	record := SlippageRecord{
+		OrderID:          order.ID,
+		Symbol:           order.Symbol,
+		Side:             order.Side,
+		LP:               order.SelectedLP,
+		Volume:           order.FilledQty,
+		TopOfBook:        topOfBook,
+		ExpectedPrice:    order.ExpectedPrice,
+		ActualPrice:      order.AvgFillPrice,
+		ExpectedSlippage: order.ExpectedSlippage,
+		ActualSlippage:   adverse(order.Side, order.AvgFillPrice, topOfBook),
+		Timestamp:        time.Now(),
+	}
+	if order.SentAt != nil && order.FilledAt != nil {
+		record.Latency = order.FilledAt.Sub(*order.SentAt)
+	}
+	e.slippageRecords = append(e.slippageRecords, record)
+	if n := len(e.slippageRecords); n > maxSlippageRecords {
+		e.slippageRecords = append(e.slippageRecords[:0:0], e.slippageRecords[n-maxSlippageRecords:]...)
+	}
+
+	if e.onSlippage != nil {
+		e.onSlippage(record)
+	}
+}
+
+// sweepLegsSettled totals the fills of a split sweep's legs into the order and
+// reports whether the LPs are done with every leg, filled or not
+func sweepLegsSettled(order *Order) bool {
+	var filled, notional float64
+	settled := true
+	for _, leg := range order.Sweep.Legs {
+		filled += leg.FilledQty
+		notional += leg.FilledQty * leg.AvgFillPrice
+		if !leg.settled() {
+			settled = false
+		}
+	}
+	order.FilledQty = filled
+	if filled > 0 {
+		order.AvgFillPrice = notional / filled
+	}
+	return settled
+}
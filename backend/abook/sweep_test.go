@@ -0,0 +1,153 @@
+package abook
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// syntheticDepth is ask-side depth over two LPs, best price first per LP
+var syntheticDepth = []DepthLevel{
+	{LP: "lp-a", Price: 1.10000, Size: 20},
+	{LP: "lp-b", Price: 1.10010, Size: 20},
+	{LP: "lp-a", Price: 1.10020, Size: 30},
+	{LP: "lp-b", Price: 1.10040, Size: 40},
+}
+
+// TestSweepWalksThreeLevels tests that a 50-lot buy split across LPs takes the
+// three best levels for the expected VWAP and leaves the fourth untouched
+func TestSweepWalksThreeLevels(t *testing.T) {
+	plan, err := planSweep("EURUSD", "BUY", 50, syntheticDepth, true)
+	if err != nil {
+		t.Fatalf("planSweep() error = %v", err)
+	}
+
+	// 20 @ 1.10000 + 20 @ 1.10010 + 10 @ 1.10020
+	wantVWAP := (20*1.10000 + 20*1.10010 + 10*1.10020) / 50
+	if len(plan.Levels) != 3 || plan.Levels[2].Size != 10 {
+		t.Errorf("levels = %+v, want three with 10 lots taken at the third", plan.Levels)
+	}
+	if math.Abs(plan.ExpectedVWAP-wantVWAP) > 1e-9 || plan.TopOfBook != 1.10000 {
+		t.Errorf("VWAP = %.6f, top of book %.5f; want %.6f, 1.10000", plan.ExpectedVWAP, plan.TopOfBook, wantVWAP)
+	}
+	if math.Abs(plan.ExpectedSlippage-(wantVWAP-1.10000)) > 1e-9 {
+		t.Errorf("expected slippage = %.6f, want %.6f", plan.ExpectedSlippage, wantVWAP-1.10000)
+	}
+	if len(plan.Legs) != 2 || plan.Legs[0].LP != "lp-a" || plan.Legs[0].Volume != 30 || plan.Legs[1].Volume != 20 {
+		t.Errorf("legs = %+v, want lp-a 30 lots and lp-b 20 lots", plan.Legs)
+	}
+}
+
+// TestSweepSingleLP tests that without splitting the order goes to the one LP
+// with the best average price for the whole volume, or fails for lack of depth
+func TestSweepSingleLP(t *testing.T) {
+	plan, err := planSweep("EURUSD", "BUY", 50, syntheticDepth, false)
+	if err != nil {
+		t.Fatalf("planSweep() error = %v", err)
+	}
+	// lp-a: 20 @ 1.10000 + 30 @ 1.10020 beats lp-b: 20 @ 1.10010 + 30 @ 1.10040
+	if len(plan.Legs) != 1 || plan.Legs[0].LP != "lp-a" {
+		t.Errorf("legs = %+v, want the whole order on lp-a", plan.Legs)
+	}
+
+	if _, err := planSweep("EURUSD", "BUY", 70, syntheticDepth, false); !errors.Is(err, ErrInsufficientLiquidity) {
+		t.Errorf("planSweep() of 70 lots on one LP error = %v, want ErrInsufficientLiquidity", err)
+	}
+	if _, err := planSweep("EURUSD", "BUY", 120, syntheticDepth, true); !errors.Is(err, ErrInsufficientLiquidity) {
+		t.Errorf("planSweep() beyond all depth error = %v, want ErrInsufficientLiquidity", err)
+	}
+}
+
+// TestSplitSweepRecordsSlippage tests that a split sweep fills once every leg
+// has, and records the actual against the expected slippage
+func TestSplitSweepRecordsSlippage(t *testing.T) {
+	plan, _ := planSweep("EURUSD", "BUY", 50, syntheticDepth, true)
+	plan.Legs[0].LPOrderID = "CL-1"
+	plan.Legs[1].LPOrderID = "CL-2"
+
+	sent := time.Now()
+	order := &Order{ID: "o-1", ClientOrderID: "client-1", Symbol: "EURUSD", Side: "BUY", Volume: 50, Status: "SENT",
+		SelectedLP: "lp-a", ExpectedPrice: plan.ExpectedVWAP, ExpectedSlippage: plan.ExpectedSlippage, Sweep: plan, SentAt: &sent}
+	e := &ExecutionEngine{
+		orders:    map[string]*Order{order.ID: order},
+		positions: make(map[string]*Position),
+		metrics:   &ExecutionMetrics{FillRateByLP: map[string]float64{}, SlippageByLP: map[string]float64{}, AvgLatencyByLP: map[string]time.Duration{}},
+	}
+	var records []SlippageRecord
+	e.SetOnSlippageCallback(func(r SlippageRecord) { records = append(records, r) })
+
+	e.handleExecutionReport(&ExecutionReport{ClientOrderID: "CL-1", ExecType: "FILL", LastQty: 30, CumQty: 30, AvgPx: 1.10012})
+	if order.Status != "PARTIAL" || order.FilledQty != 30 {
+		t.Fatalf("order = %s %.0f lots after one leg, want PARTIAL with 30", order.Status, order.FilledQty)
+	}
+
+	e.handleExecutionReport(&ExecutionReport{ClientOrderID: "CL-2", ExecType: "FILL", LastQty: 20, CumQty: 20, AvgPx: 1.10015})
+	wantAvg := (30*1.10012 + 20*1.10015) / 50
+	if order.Status != "FILLED" || order.FilledQty != 50 || math.Abs(order.AvgFillPrice-wantAvg) > 1e-9 {
+		t.Fatalf("order = %s %.0f lots @ %.6f, want FILLED 50 @ %.6f", order.Status, order.FilledQty, order.AvgFillPrice, wantAvg)
+	}
+
+	if len(records) != 1 || len(e.GetSlippageRecords()) != 1 {
+		t.Fatalf("slippage records = %+v, want one", records)
+	}
+	r := records[0]
+	if r.ExpectedPrice != plan.ExpectedVWAP || math.Abs(r.ActualSlippage-(wantAvg-1.10000)) > 1e-9 || r.ActualSlippage <= r.ExpectedSlippage {
+		t.Errorf("record = %+v, want actual slippage %.6f worse than expected", r, wantAvg-1.10000)
+	}
+}
+
+// TestSplitSweepBooksFilledOnRejectedLeg tests that a rejected leg settles the
+// sweep at what the other legs filled, and that a sweep with nothing filled is
+// rejected
+func TestSplitSweepBooksFilledOnRejectedLeg(t *testing.T) {
+	newSweep := func(id string) *Order {
+		plan, _ := planSweep("EURUSD", "BUY", 50, syntheticDepth, true)
+		plan.Legs[0].LPOrderID, plan.Legs[0].Status = id+"-1", "SENT"
+		plan.Legs[1].LPOrderID, plan.Legs[1].Status = id+"-2", "SENT"
+		return &Order{ID: id, ClientOrderID: id, Symbol: "EURUSD", Side: "BUY", Volume: 50, Status: "SENT",
+			SelectedLP: "lp-a", ExpectedPrice: plan.ExpectedVWAP, Sweep: plan}
+	}
+	partial, rejected := newSweep("o-1"), newSweep("o-2")
+	e := &ExecutionEngine{
+		orders:    map[string]*Order{partial.ID: partial, rejected.ID: rejected},
+		positions: make(map[string]*Position),
+		metrics:   &ExecutionMetrics{FillRateByLP: map[string]float64{}, SlippageByLP: map[string]float64{}, AvgLatencyByLP: map[string]time.Duration{}},
+	}
+
+	e.handleExecutionReport(&ExecutionReport{ClientOrderID: "o-1-2", ExecType: "REJECTED", Text: "no liquidity"})
+	if partial.Status != "SENT" || partial.Sweep.Legs[1].Status != "REJECTED" {
+		t.Fatalf("order = %s with leg %s, want SENT until the other leg is done", partial.Status, partial.Sweep.Legs[1].Status)
+	}
+	e.handleExecutionReport(&ExecutionReport{ClientOrderID: "o-1-1", ExecType: "FILL", LastQty: 30, CumQty: 30, AvgPx: 1.10012})
+	if partial.Status != "FILLED" || partial.FilledQty != 30 || partial.LeavesQty != 0 {
+		t.Errorf("order = %s %.0f lots (%.0f leaves), want FILLED at the 30 lots filled", partial.Status, partial.FilledQty, partial.LeavesQty)
+	}
+	if positions := e.GetPositions(""); len(positions) != 1 || positions[0].Volume != 30 {
+		t.Errorf("positions = %+v, want one of 30 lots", positions)
+	}
+
+	e.handleExecutionReport(&ExecutionReport{ClientOrderID: "o-2-1", ExecType: "REJECTED", RejectCode: "2"})
+	e.handleExecutionReport(&ExecutionReport{ClientOrderID: "o-2-2", ExecType: "CANCELED"})
+	if rejected.Status != "CANCELED" || rejected.FilledQty != 0 {
+		t.Errorf("order = %s %.0f lots, want it ended with nothing filled", rejected.Status, rejected.FilledQty)
+	}
+	if len(e.GetPositions("")) != 1 {
+		t.Error("a sweep with nothing filled booked a position")
+	}
+}
+
+// TestSlippageRecordsBounded tests that only the newest slippage records are kept
+func TestSlippageRecordsBounded(t *testing.T) {
+	e := &ExecutionEngine{}
+	order := &Order{Symbol: "EURUSD", Side: "BUY", ExpectedPrice: 1.1, AvgFillPrice: 1.1}
+	for i := 0; i < maxSlippageRecords+5; i++ {
+		order.ID = fmt.Sprintf("o-%d", i)
+		e.recordSlippageLocked(order)
+	}
+	records := e.GetSlippageRecords()
+	if len(records) != maxSlippageRecords || records[len(records)-1].OrderID != fmt.Sprintf("o-%d", maxSlippageRecords+4) {
+		t.Errorf("kept %d records ending with %s, want the newest %d", len(records), records[len(records)-1].OrderID, maxSlippageRecords)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"sort"
 	"sync"
 	"time"
 
@@ -21,14 +22,30 @@ type AuthService struct {
 	adminsByUsername map[string]*Admin
 	sessions        map[string]*AdminSession
 	nextAdminID     int64
+
+	// Concurrent session limit per admin (0 = unlimited). With takeover the
+	// oldest session is invalidated instead of rejecting the new login.
+	maxSessions     int
+	sessionTakeover bool
+	auditLog        *AuditLog
+
+	// Short-lived cache of successful session validations (0 TTL = disabled)
+	authCache       map[string]*authCacheEntry
+	authCacheTTL    time.Duration
+	authCacheHits   int64
+	authCacheMisses int64
 }
 
+// ErrSessionLimitReached is returned when a login exceeds the concurrent session limit
+var ErrSessionLimitReached = errors.New("maximum concurrent sessions reached")
+
 // NewAuthService creates a new admin auth service
 func NewAuthService() *AuthService {
 	svc := &AuthService{
 		admins:           make(map[int64]*Admin),
 		adminsByUsername: make(map[string]*Admin),
 		sessions:         make(map[string]*AdminSession),
+		authCache:        make(map[string]*authCacheEntry),
 		nextAdminID:      1,
 	}
 
@@ -129,16 +146,101 @@ func (s *AuthService) Login(username, password, ipAddress, userAgent string) (*A
 	}
 
 	s.mu.Lock()
+	evicted, err := s.enforceSessionLimitUnlocked(admin.ID, now)
+	if err != nil {
+		s.mu.Unlock()
+		log.Printf("[AdminAuth] Login rejected for %s from %s: session limit %d reached", username, ipAddress, s.maxSessions)
+		s.audit(admin, "SESSION_LIMIT_REJECTED", map[string]interface{}{
+			"maxSessions": s.maxSessions,
+			"userAgent":   userAgent,
+		}, ipAddress, "FAILED", err.Error())
+		return nil, err
+	}
 	s.sessions[sessionID] = session
 	admin.LastLogin = now
 	s.mu.Unlock()
 
+	for _, old := range evicted {
+		log.Printf("[AdminAuth] Session takeover for %s: invalidated session from %s created %s",
+			username, old.IPAddress, old.CreatedAt.Format(time.RFC3339))
+		s.audit(admin, "SESSION_TAKEOVER", map[string]interface{}{
+			"invalidatedIP":        old.IPAddress,
+			"invalidatedUserAgent": old.UserAgent,
+			"invalidatedCreatedAt": old.CreatedAt,
+			"maxSessions":          s.maxSessions,
+		}, ipAddress, "SUCCESS", "")
+	}
+
 	log.Printf("[AdminAuth] Admin logged in: %s from %s", username, ipAddress)
 	return session, nil
 }
 
-// ValidateSession validates a session token and returns the admin
+// SetSessionLimit sets the maximum concurrent sessions per admin (0 = unlimited).
+// With takeover a login over the limit invalidates the oldest session instead of being rejected.
+func (s *AuthService) SetSessionLimit(maxSessions int, takeover bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxSessions = maxSessions
+	s.sessionTakeover = takeover
+}
+
+// SetAuditLog sets the audit log used to record session limit events
+func (s *AuthService) SetAuditLog(auditLog *AuditLog) {
+	s.auditLog = auditLog
+}
+
+// enforceSessionLimitUnlocked makes room for a new session of adminID, returning
+// the sessions invalidated by takeover (caller must hold lock)
+func (s *AuthService) enforceSessionLimitUnlocked(adminID int64, now time.Time) ([]*AdminSession, error) {
+	if s.maxSessions <= 0 {
+		return nil, nil
+	}
+
+	var active []*AdminSession
+	for sessionID, session := range s.sessions {
+		if session.AdminID != adminID {
+			continue
+		}
+		if now.After(session.ExpiresAt) {
+			delete(s.sessions, sessionID)
+			continue
+		}
+		active = append(active, session)
+	}
+
+	if len(active) < s.maxSessions {
+		return nil, nil
+	}
+	if !s.sessionTakeover {
+		return nil, ErrSessionLimitReached
+	}
+
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].CreatedAt.Before(active[j].CreatedAt)
+	})
+	evicted := active[:len(active)-s.maxSessions+1]
+	for _, session := range evicted {
+		delete(s.sessions, session.SessionID)
+	}
+	s.invalidateAuthCacheUnlocked()
+	return evicted, nil
+}
+
+func (s *AuthService) audit(admin *Admin, action string, changes interface{}, ipAddress, status, errorMsg string) {
+	if s.auditLog == nil {
+		return
+	}
+	s.auditLog.Log(admin.ID, admin.Username, action, "SESSION", admin.ID, changes, "", ipAddress, "", status, errorMsg)
+}
+
+// ValidateSession validates a session token and returns the admin.
+// Repeated requests from the same IP within the cache TTL reuse the previous
+// validation.
 func (s *AuthService) ValidateSession(sessionID, ipAddress string) (*Admin, error) {
+	if admin, ok := s.cachedValidation(sessionID, ipAddress, time.Now()); ok {
+		return admin, nil
+	}
+
 	s.mu.RLock()
 	session, exists := s.sessions[sessionID]
 	s.mu.RUnlock()
@@ -151,6 +253,7 @@ func (s *AuthService) ValidateSession(sessionID, ipAddress string) (*Admin, erro
 	if time.Now().After(session.ExpiresAt) {
 		s.mu.Lock()
 		delete(s.sessions, sessionID)
+		s.invalidateAuthCacheUnlocked()
 		s.mu.Unlock()
 		return nil, errors.New("session expired")
 	}
@@ -172,6 +275,9 @@ func (s *AuthService) ValidateSession(sessionID, ipAddress string) (*Admin, erro
 	// Update last active
 	s.mu.Lock()
 	session.LastActive = time.Now()
+	if _, live := s.sessions[sessionID]; live && admin.Status == "ACTIVE" {
+		s.cacheValidationUnlocked(session, admin, ipAddress, session.LastActive)
+	}
 	s.mu.Unlock()
 
 	return admin, nil
@@ -184,6 +290,7 @@ func (s *AuthService) Logout(sessionID string) error {
 
 	if session, exists := s.sessions[sessionID]; exists {
 		delete(s.sessions, sessionID)
+		s.invalidateAuthCacheUnlocked()
 		log.Printf("[AdminAuth] Admin logged out: %s", session.Username)
 		return nil
 	}
@@ -246,6 +353,7 @@ func (s *AuthService) UpdatePassword(adminID int64, oldPassword, newPassword str
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
+	s.invalidateAuthCacheUnlocked()
 	admin.PasswordHash = string(hash)
 	log.Printf("[AdminAuth] Password updated for admin: %s", admin.Username)
 	return nil
@@ -266,6 +374,7 @@ func (s *AuthService) ResetPassword(adminID int64, newPassword string) error {
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
+	s.invalidateAuthCacheUnlocked()
 	admin.PasswordHash = string(hash)
 	log.Printf("[AdminAuth] Password reset for admin: %s", admin.Username)
 	return nil
@@ -282,6 +391,7 @@ func (s *AuthService) SetIPWhitelist(adminID int64, ips []string) error {
 	}
 
 	admin.IPWhitelist = ips
+	s.invalidateAuthCacheUnlocked()
 	log.Printf("[AdminAuth] IP whitelist updated for %s: %v", admin.Username, ips)
 	return nil
 }
@@ -323,6 +433,7 @@ func (s *AuthService) DisableAdmin(adminID int64) error {
 	}
 
 	admin.Status = "DISABLED"
+	s.invalidateAuthCacheUnlocked()
 	log.Printf("[AdminAuth] Admin disabled: %s", admin.Username)
 
 	// Terminate all sessions for this admin
@@ -346,6 +457,7 @@ func (s *AuthService) EnableAdmin(adminID int64) error {
 	}
 
 	admin.Status = "ACTIVE"
+	s.invalidateAuthCacheUnlocked()
 	log.Printf("[AdminAuth] Admin enabled: %s", admin.Username)
 	return nil
 }
@@ -412,6 +524,9 @@ func (s *AuthService) CleanupExpiredSessions() int {
 			count++
 		}
 	}
+	if count > 0 {
+		s.invalidateAuthCacheUnlocked()
+	}
 
 	if count > 0 {
 		log.Printf("[AdminAuth] Cleaned up %d expired sessions", count)
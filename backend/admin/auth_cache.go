@@ -0,0 +1,70 @@
+package admin
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// authCacheEntry is a recent successful session validation
+type authCacheEntry struct {
+	admin     *Admin
+	ipAddress string    // Hits require the same client IP
+	expiresAt time.Time // Cache TTL, capped at the session expiry
+}
+
+// SetAuthCacheTTL sets how long a successful session validation is reused for
+// repeated requests from the same IP (0 disables caching)
+func (s *AuthService) SetAuthCacheTTL(ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authCacheTTL = ttl
+	s.invalidateAuthCacheUnlocked()
+}
+
+// AuthCacheStats returns the number of session validations served from the
+// cache and the number that needed full validation
+func (s *AuthService) AuthCacheStats() (hits, misses int64) {
+	return atomic.LoadInt64(&s.authCacheHits), atomic.LoadInt64(&s.authCacheMisses)
+}
+
+// cachedValidation returns the cached admin for a session token if the entry is
+// still fresh and the request comes from the IP it was validated for
+func (s *AuthService) cachedValidation(sessionID, ipAddress string, now time.Time) (*Admin, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.authCacheTTL <= 0 {
+		return nil, false
+	}
+	entry, ok := s.authCache[sessionID]
+	if !ok || now.After(entry.expiresAt) || entry.ipAddress != ipAddress {
+		atomic.AddInt64(&s.authCacheMisses, 1)
+		return nil, false
+	}
+	atomic.AddInt64(&s.authCacheHits, 1)
+	return entry.admin, true
+}
+
+// cacheValidationUnlocked stores a successful validation (caller must hold lock)
+func (s *AuthService) cacheValidationUnlocked(session *AdminSession, admin *Admin, ipAddress string, now time.Time) {
+	if s.authCacheTTL <= 0 {
+		return
+	}
+	expiresAt := now.Add(s.authCacheTTL)
+	if session.ExpiresAt.Before(expiresAt) {
+		expiresAt = session.ExpiresAt
+	}
+	s.authCache[session.SessionID] = &authCacheEntry{
+		admin:     admin,
+		ipAddress: ipAddress,
+		expiresAt: expiresAt,
+	}
+}
+
+// invalidateAuthCacheUnlocked drops every cached validation. Called on any
+// session or admin change that could affect authentication (caller must hold lock)
+func (s *AuthService) invalidateAuthCacheUnlocked() {
+	if len(s.authCache) > 0 {
+		s.authCache = make(map[string]*authCacheEntry)
+	}
+}
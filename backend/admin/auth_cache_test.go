@@ -0,0 +1,67 @@
+package admin
+
+import (
+	"testing"
+	"time"
+)
+
+// TestAuthCacheRejectsLoggedOutToken tests that logout takes effect immediately despite a warm cache
+func TestAuthCacheRejectsLoggedOutToken(t *testing.T) {
+	svc := NewAuthService()
+	svc.SetAuthCacheTTL(time.Minute)
+
+	session, err := svc.Login("admin", "Admin@123", "10.0.0.1", "browser")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := svc.ValidateSession(session.SessionID, "10.0.0.1"); err != nil {
+			t.Fatalf("ValidateSession() error = %v", err)
+		}
+	}
+	if hits, _ := svc.AuthCacheStats(); hits != 1 {
+		t.Fatalf("cache hits = %d, want 1 before logout", hits)
+	}
+
+	if err := svc.Logout(session.SessionID); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if _, err := svc.ValidateSession(session.SessionID, "10.0.0.1"); err == nil {
+		t.Error("logged-out token accepted from the cache")
+	}
+}
+
+// TestAuthCacheServesRepeatedRequests tests that repeated validations within the TTL skip full validation
+func TestAuthCacheServesRepeatedRequests(t *testing.T) {
+	svc := NewAuthService()
+	svc.SetAuthCacheTTL(time.Minute)
+
+	session, err := svc.Login("admin", "Admin@123", "10.0.0.1", "browser")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		admin, err := svc.ValidateSession(session.SessionID, "10.0.0.1")
+		if err != nil || admin.Username != "admin" {
+			t.Fatalf("ValidateSession() = %v, %v; want admin", admin, err)
+		}
+	}
+	if hits, misses := svc.AuthCacheStats(); hits != 4 || misses != 1 {
+		t.Errorf("cache hits/misses = %d/%d, want 4/1", hits, misses)
+	}
+
+	// A different client IP is not served from the cache entry bound to the first IP
+	if _, err := svc.ValidateSession(session.SessionID, "10.0.0.2"); err != nil {
+		t.Fatalf("ValidateSession() from new IP error = %v", err)
+	}
+	if _, misses := svc.AuthCacheStats(); misses != 2 {
+		t.Errorf("cache misses = %d, want 2 after an IP change", misses)
+	}
+
+	// Disabling the admin invalidates cached validations
+	svc.DisableAdmin(1)
+	if _, err := svc.ValidateSession(session.SessionID, "10.0.0.1"); err == nil {
+		t.Error("disabled admin accepted from the cache")
+	}
+}
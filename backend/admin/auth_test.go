@@ -0,0 +1,76 @@
+package admin
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestSessionLimitRejectsExtraLogin tests that logins over the limit are rejected without takeover
+func TestSessionLimitRejectsExtraLogin(t *testing.T) {
+	auditLog := NewAuditLog(100)
+	svc := NewAuthService()
+	svc.SetAuditLog(auditLog)
+	svc.SetSessionLimit(1, false)
+
+	first, err := svc.Login("admin", "Admin@123", "10.0.0.1", "browser-1")
+	if err != nil {
+		t.Fatalf("first Login() error = %v", err)
+	}
+
+	if _, err := svc.Login("admin", "Admin@123", "10.0.0.2", "browser-2"); !errors.Is(err, ErrSessionLimitReached) {
+		t.Fatalf("second Login() error = %v, want %v", err, ErrSessionLimitReached)
+	}
+
+	if _, err := svc.ValidateSession(first.SessionID, "10.0.0.1"); err != nil {
+		t.Errorf("first session invalidated by rejected login: %v", err)
+	}
+	if n := len(svc.GetActiveSessions()); n != 1 {
+		t.Errorf("active sessions = %d, want 1", n)
+	}
+
+	entries := auditLog.GetEntriesByAction("SESSION_LIMIT_REJECTED", 10)
+	if len(entries) != 1 || entries[0].Status != "FAILED" {
+		t.Errorf("audit entries = %+v, want one failed SESSION_LIMIT_REJECTED", entries)
+	}
+}
+
+// TestSessionLimitTakeoverInvalidatesOldest tests that takeover logs out the oldest session
+func TestSessionLimitTakeoverInvalidatesOldest(t *testing.T) {
+	auditLog := NewAuditLog(100)
+	svc := NewAuthService()
+	svc.SetAuditLog(auditLog)
+	svc.SetSessionLimit(2, true)
+
+	oldest, err := svc.Login("admin", "Admin@123", "10.0.0.1", "browser-1")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	second, err := svc.Login("admin", "Admin@123", "10.0.0.2", "browser-2")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	third, err := svc.Login("admin", "Admin@123", "10.0.0.3", "browser-3")
+	if err != nil {
+		t.Fatalf("Login() over limit with takeover error = %v", err)
+	}
+
+	if _, err := svc.ValidateSession(oldest.SessionID, "10.0.0.1"); err == nil {
+		t.Error("oldest session still valid after takeover")
+	}
+	for _, session := range []*AdminSession{second, third} {
+		if _, err := svc.ValidateSession(session.SessionID, session.IPAddress); err != nil {
+			t.Errorf("session from %s invalidated: %v", session.IPAddress, err)
+		}
+	}
+
+	entries := auditLog.GetEntriesByAction("SESSION_TAKEOVER", 10)
+	if len(entries) != 1 {
+		t.Fatalf("SESSION_TAKEOVER audit entries = %d, want 1", len(entries))
+	}
+	if changes, ok := entries[0].Changes.(map[string]interface{}); !ok || changes["invalidatedIP"] != "10.0.0.1" {
+		t.Errorf("takeover audit changes = %+v, want invalidatedIP 10.0.0.1", entries[0].Changes)
+	}
+}
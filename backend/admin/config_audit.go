@@ -0,0 +1,104 @@
+package admin
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/epic1st/rtx/backend/auth"
+)
+
+// SetExecutionModeControl sets how the global execution mode is read and switched
+func (h *AdminHandler) SetExecutionModeControl(get func() string, set func(mode string) error) {
+	h.executionModeGet = get
+	h.executionModeSet = set
+}
+
+// AuditConfigChange records a change to global broker configuration under the
+// identity of the admin token that made it. Routes calling it must be wrapped
+// with RequireRole(auth.RoleAdmin).
+func (h *AdminHandler) AuditConfigChange(r *http.Request, action string, oldValue, newValue interface{}) {
+	var adminID int64
+	adminName := "unknown"
+	if claims, ok := auth.ClaimsFromContext(r.Context()); ok {
+		adminID, _ = strconv.ParseInt(claims.UserID, 10, 64)
+		adminName = claims.Username
+	}
+
+	h.auditLog.Log(adminID, adminName, action, "CONFIG", 0, map[string]interface{}{
+		"old": oldValue,
+		"new": newValue,
+	}, "", getIPAddress(r), r.UserAgent(), "SUCCESS", "")
+
+	log.Printf("[ADMIN] %s by %s: %v -> %v", action, adminName, oldValue, newValue)
+}
+
+// AuditEntriesBetween returns the audit entries recorded between from and to,
+// oldest first, of entityType when it is not empty
+func (h *AdminHandler) AuditEntriesBetween(from, to time.Time, entityType string) []AuditEntry {
+	var entityFilter *string
+	if entityType != "" {
+		entityFilter = &entityType
+	}
+	entries := h.auditLog.GetEntries(nil, nil, entityFilter, nil, &from, &to, 0)
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries
+}
+
+// HandleExecutionMode returns the global execution mode, or switches it on
+// POST and records the change in the audit log
+func (h *AdminHandler) HandleExecutionMode(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if h.executionModeGet == nil || h.executionModeSet == nil {
+		respondError(w, "Execution mode control not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		respondJSON(w, map[string]interface{}{
+			"mode": h.executionModeGet(),
+			"description": map[string]string{
+				"BBOOK":  "Internal execution - orders processed by RTX engine using internal balance",
+				"ABOOK":  "LP passthrough - orders routed to OANDA (requires active LP connection)",
+				"HYBRID": "Routing engine decides the book per order",
+			},
+			"priceFeed": "OANDA", // Always OANDA for prices
+		})
+
+	case http.MethodPost:
+		var req struct {
+			Mode string `json:"mode"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		oldMode := h.executionModeGet()
+		if err := h.executionModeSet(req.Mode); err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		newMode := h.executionModeGet()
+		h.AuditConfigChange(r, "EXECUTION_MODE_UPDATE", oldMode, newMode)
+
+		respondJSON(w, map[string]interface{}{
+			"success": true,
+			"oldMode": oldMode,
+			"newMode": newMode,
+			"message": "Execution mode updated. Price feed remains connected to OANDA.",
+		})
+
+	default:
+		respondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/epic1st/rtx/backend/auth"
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+// TestExecutionModeToggleIsAudited tests that switching the execution mode
+// records the admin, the old and the new mode, and that it requires an admin token
+func TestExecutionModeToggleIsAudited(t *testing.T) {
+	engine := core.NewEngine()
+	h := NewAdminHandler(engine)
+	mode := "BBOOK"
+	h.SetExecutionModeControl(func() string { return mode }, func(m string) error {
+		mode = m
+		return nil
+	})
+
+	authService := auth.NewService(engine, "$2a$10$invalidhashforadminlogintests", "test-secret")
+	route := authService.RequireRole(auth.RoleAdmin, h.HandleExecutionMode)
+
+	// No token: rejected, nothing changes
+	rec := httptest.NewRecorder()
+	route(rec, httptest.NewRequest("POST", "/admin/execution-mode", strings.NewReader(`{"mode":"ABOOK"}`)))
+	if rec.Code != http.StatusUnauthorized || mode != "BBOOK" {
+		t.Fatalf("unauthenticated toggle: status %d, mode %s; want 401 and BBOOK", rec.Code, mode)
+	}
+
+	token, err := authService.GenerateToken(&auth.User{ID: "0", Username: "admin", Role: auth.RoleAdmin})
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	req := httptest.NewRequest("POST", "/admin/execution-mode", strings.NewReader(`{"mode":"ABOOK"}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.RemoteAddr = "10.0.0.7:5000"
+	rec = httptest.NewRecorder()
+	route(rec, req)
+	if rec.Code != http.StatusOK || mode != "ABOOK" {
+		t.Fatalf("admin toggle: status %d, mode %s; want 200 and ABOOK", rec.Code, mode)
+	}
+
+	entries := h.auditLog.GetEntriesByAction("EXECUTION_MODE_UPDATE", 10)
+	if len(entries) != 1 {
+		t.Fatalf("audit entries = %+v, want one EXECUTION_MODE_UPDATE", entries)
+	}
+	entry := entries[0]
+	changes, _ := entry.Changes.(map[string]interface{})
+	if changes["old"] != "BBOOK" || changes["new"] != "ABOOK" {
+		t.Errorf("audited changes = %+v, want BBOOK -> ABOOK", entry.Changes)
+	}
+	if entry.AdminName != "admin" || entry.IPAddress != "10.0.0.7" {
+		t.Errorf("audited identity = %s from %s, want admin from 10.0.0.7", entry.AdminName, entry.IPAddress)
+	}
+	if len(h.AuditEntriesBetween(entry.CreatedAt.Add(-1), entry.CreatedAt.Add(1), "CONFIG")) != 1 {
+		t.Error("AuditEntriesBetween() missed the config change")
+	}
+}
@@ -126,8 +126,8 @@ func (s *FundManagementService) Withdraw(accountID int64, amount float64, method
 		return nil, fmt.Errorf("cannot withdraw while user has %d open positions", len(positions))
 	}
 
-	// Execute withdrawal via ledger
-	entry, err := s.engine.GetLedger().Withdraw(accountID, amount, method, reference, description, admin.Username)
+	// Execute withdrawal via ledger, within the withdrawable balance
+	entry, err := s.engine.Withdraw(accountID, amount, method, reference, description, admin.Username)
 	if err != nil {
 		s.auditLog.Log(admin.ID, admin.Username, "FUND_WITHDRAW", "FUND", accountID, map[string]interface{}{
 			"amount":      amount,
@@ -139,9 +139,6 @@ func (s *FundManagementService) Withdraw(accountID int64, amount float64, method
 		return nil, fmt.Errorf("withdrawal failed: %w", err)
 	}
 
-	// Update account balance
-	account.Balance = entry.BalanceAfter
-
 	// Create operation record
 	now := time.Now()
 	operation := &FundOperation{
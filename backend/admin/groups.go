@@ -2,9 +2,14 @@ package admin
 
 import (
 	"errors"
+	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/epic1st/rtx/backend/internal/core"
+	"github.com/epic1st/rtx/backend/orders"
 )
 
 // GroupManagementService handles trading group operations
@@ -319,6 +324,384 @@ func (s *GroupManagementService) SetSymbolSettings(groupID int64, symbol string,
 	return nil
 }
 
+// SetOrderRules sets the allowed order types and placement defaults for a group
+func (s *GroupManagementService) SetOrderRules(groupID int64, allowedOrderTypes []string, defaultTIF string, defaultSLPips, defaultTPPips, defaultMinFillRatio float64, admin *Admin, reason string, ipAddress string) error {
+	types := make([]string, 0, len(allowedOrderTypes))
+	for _, t := range allowedOrderTypes {
+		t = strings.ToUpper(t)
+		switch orders.OrderType(t) {
+		case orders.OrderTypeMarket, orders.OrderTypeLimit, orders.OrderTypeStop, orders.OrderTypeStopLimit:
+			types = append(types, t)
+		default:
+			return fmt.Errorf("invalid order type: %s", t)
+		}
+	}
+
+	defaultTIF = strings.ToUpper(defaultTIF)
+	switch defaultTIF {
+	case "", "GTC", "GTD", "DAY", "IOC", "FOK":
+	default:
+		return fmt.Errorf("invalid time in force: %s", defaultTIF)
+	}
+
+	if defaultSLPips < 0 || defaultTPPips < 0 {
+		return errors.New("default SL/TP pips cannot be negative")
+	}
+
+	if defaultMinFillRatio < 0 || defaultMinFillRatio > 1 {
+		return errors.New("default minimum fill ratio must be between 0 and 1")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, exists := s.groups[groupID]
+	if !exists {
+		return errors.New("group not found")
+	}
+
+	oldRules := map[string]interface{}{
+		"allowedOrderTypes":   group.AllowedOrderTypes,
+		"defaultTif":          group.DefaultTIF,
+		"defaultSlPips":       group.DefaultSLPips,
+		"defaultTpPips":       group.DefaultTPPips,
+		"defaultMinFillRatio": group.DefaultMinFillRatio,
+	}
+
+	group.AllowedOrderTypes = types
+	group.DefaultTIF = defaultTIF
+	group.DefaultSLPips = defaultSLPips
+	group.DefaultTPPips = defaultTPPips
+	group.DefaultMinFillRatio = defaultMinFillRatio
+	group.UpdatedAt = time.Now()
+
+	s.auditLog.Log(admin.ID, admin.Username, "GROUP_ORDER_RULES_UPDATE", "GROUP", groupID, map[string]interface{}{
+		"old": oldRules,
+		"new": map[string]interface{}{
+			"allowedOrderTypes":   types,
+			"defaultTif":          defaultTIF,
+			"defaultSlPips":       defaultSLPips,
+			"defaultTpPips":       defaultTPPips,
+			"defaultMinFillRatio": defaultMinFillRatio,
+		},
+		"reason": reason,
+	}, reason, ipAddress, "", "SUCCESS", "")
+
+	log.Printf("[GroupMgmt] Order rules for group %s updated by %s", group.Name, admin.Username)
+
+	return nil
+}
+
+// OrderRules returns the placement rules of a group, or nil if the group does not exist
+func (s *GroupManagementService) OrderRules(groupID int64) *orders.GroupOrderRules {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	group, exists := s.groups[groupID]
+	if !exists {
+		return nil
+	}
+
+	rules := &orders.GroupOrderRules{
+		GroupID:             group.ID,
+		DefaultTIF:          group.DefaultTIF,
+		DefaultSLPips:       group.DefaultSLPips,
+		DefaultTPPips:       group.DefaultTPPips,
+		DefaultMinFillRatio: group.DefaultMinFillRatio,
+	}
+	for _, t := range group.AllowedOrderTypes {
+		rules.AllowedOrderTypes = append(rules.AllowedOrderTypes, orders.OrderType(t))
+	}
+	return rules
+}
+
+// SetCommissionModel chooses between markup and explicit commission pricing for a
+// group. An empty model falls back to each symbol's own model.
+func (s *GroupManagementService) SetCommissionModel(groupID int64, model string, admin *Admin, reason string, ipAddress string) error {
+	if model != "" {
+		normalized, err := core.NormalizeCommissionModel(model)
+		if err != nil {
+			return err
+		}
+		model = normalized
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, exists := s.groups[groupID]
+	if !exists {
+		return errors.New("group not found")
+	}
+
+	oldModel := group.CommissionModel
+	group.CommissionModel = model
+	group.UpdatedAt = time.Now()
+
+	s.auditLog.Log(admin.ID, admin.Username, "GROUP_COMMISSION_MODEL_UPDATE", "GROUP", groupID, map[string]interface{}{
+		"old":    oldModel,
+		"new":    model,
+		"reason": reason,
+	}, reason, ipAddress, "", "SUCCESS", "")
+
+	log.Printf("[GroupMgmt] Commission model for group %s set to %q by %s", group.Name, model, admin.Username)
+
+	return nil
+}
+
+// CommissionModel returns the commission model a group applies to a symbol: a
+// per-symbol setting wins over the group setting. Empty means no override.
+func (s *GroupManagementService) CommissionModel(groupID int64, symbol string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	group, exists := s.groups[groupID]
+	if !exists {
+		return ""
+	}
+	if settings, ok := group.SymbolSettings[symbol]; ok && settings.CommissionModel != "" {
+		return settings.CommissionModel
+	}
+	return group.CommissionModel
+}
+
+// CommissionRate returns the commission a group charges per side on a symbol: the
+// symbol setting wins over the group's Commission. False when neither is set.
+func (s *GroupManagementService) CommissionRate(groupID int64, symbol string) (float64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	group, exists := s.groups[groupID]
+	if !exists {
+		return 0, false
+	}
+	if settings, ok := group.SymbolSettings[symbol]; ok && settings.Commission > 0 {
+		return settings.Commission, true
+	}
+	return group.Commission, group.Commission > 0
+}
+
+// SetStopOutCooldown sets how long a group's accounts are blocked from opening
+// positions after a stop-out. Empty falls back to the broker default, "0s" disables it.
+func (s *GroupManagementService) SetStopOutCooldown(groupID int64, cooldown string, admin *Admin, reason string, ipAddress string) error {
+	if cooldown != "" {
+		d, err := time.ParseDuration(cooldown)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid stop-out cooldown %q", cooldown)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, exists := s.groups[groupID]
+	if !exists {
+		return errors.New("group not found")
+	}
+
+	oldCooldown := group.StopOutCooldown
+	group.StopOutCooldown = cooldown
+	group.UpdatedAt = time.Now()
+
+	s.auditLog.Log(admin.ID, admin.Username, "GROUP_STOPOUT_COOLDOWN_UPDATE", "GROUP", groupID, map[string]interface{}{
+		"old":    oldCooldown,
+		"new":    cooldown,
+		"reason": reason,
+	}, reason, ipAddress, "", "SUCCESS", "")
+
+	log.Printf("[GroupMgmt] Stop-out cooldown for group %s set to %q by %s", group.Name, cooldown, admin.Username)
+
+	return nil
+}
+
+// StopOutCooldown returns a group's post stop-out cooldown, or -1 when the group
+// has no override
+func (s *GroupManagementService) StopOutCooldown(groupID int64) time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	group, exists := s.groups[groupID]
+	if !exists || group.StopOutCooldown == "" {
+		return -1
+	}
+	d, err := time.ParseDuration(group.StopOutCooldown)
+	if err != nil {
+		return -1
+	}
+	return d
+}
+
+// ExecutionMode returns a group's ABOOK or BBOOK execution mode, or false when
+// the group is HYBRID and leaves the book to the symbol and global modes
+func (s *GroupManagementService) ExecutionMode(groupID int64) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	group, exists := s.groups[groupID]
+	if !exists || group.ExecutionMode == "" || group.ExecutionMode == "HYBRID" {
+		return "", false
+	}
+	return group.ExecutionMode, true
+}
+
+// MarginMode returns a group's HEDGING or NETTING mode, or false when the
+// group sets none
+func (s *GroupManagementService) MarginMode(groupID int64) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	group, exists := s.groups[groupID]
+	if !exists || group.MarginMode == "" {
+		return "", false
+	}
+	return group.MarginMode, true
+}
+
+// SetMarginLevels sets the margin call and stop-out levels of a group's accounts.
+// Both 0 falls back to the broker default.
+func (s *GroupManagementService) SetMarginLevels(groupID int64, marginCall, stopOut float64, admin *Admin, reason string, ipAddress string) error {
+	if err := (core.MarginLevels{MarginCall: marginCall, StopOut: stopOut}).Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, exists := s.groups[groupID]
+	if !exists {
+		return errors.New("group not found")
+	}
+
+	oldMarginCall, oldStopOut := group.MarginCallLevel, group.StopOutLevel
+	group.MarginCallLevel = marginCall
+	group.StopOutLevel = stopOut
+	group.UpdatedAt = time.Now()
+
+	s.auditLog.Log(admin.ID, admin.Username, "GROUP_MARGIN_LEVELS_UPDATE", "GROUP", groupID, map[string]interface{}{
+		"oldMarginCall": oldMarginCall,
+		"oldStopOut":    oldStopOut,
+		"newMarginCall": marginCall,
+		"newStopOut":    stopOut,
+		"reason":        reason,
+	}, reason, ipAddress, "", "SUCCESS", "")
+
+	log.Printf("[GroupMgmt] Margin levels for group %s set to %.2f%%/%.2f%% by %s", group.Name, marginCall, stopOut, admin.Username)
+
+	return nil
+}
+
+// MarginLevels returns a group's margin call and stop-out levels, or false when
+// the group has no override
+func (s *GroupManagementService) MarginLevels(groupID int64) (core.MarginLevels, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	group, exists := s.groups[groupID]
+	if !exists || (group.MarginCallLevel == 0 && group.StopOutLevel == 0) {
+		return core.MarginLevels{}, false
+	}
+	return core.MarginLevels{MarginCall: group.MarginCallLevel, StopOut: group.StopOutLevel}, true
+}
+
+// SetQuoteMarkup sets an asymmetric markup of a group's quotes in pips. Both 0
+// returns the group to splitting Markup evenly between bid and ask.
+func (s *GroupManagementService) SetQuoteMarkup(groupID int64, bidPips, askPips float64, admin *Admin, reason string, ipAddress string) error {
+	if bidPips < 0 || askPips < 0 {
+		return errors.New("markup must not be negative")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, exists := s.groups[groupID]
+	if !exists {
+		return errors.New("group not found")
+	}
+
+	oldBid, oldAsk := group.MarkupBid, group.MarkupAsk
+	group.MarkupBid = bidPips
+	group.MarkupAsk = askPips
+	group.UpdatedAt = time.Now()
+
+	s.auditLog.Log(admin.ID, admin.Username, "GROUP_MARKUP_UPDATE", "GROUP", groupID, map[string]interface{}{
+		"oldMarkupBid": oldBid,
+		"oldMarkupAsk": oldAsk,
+		"newMarkupBid": bidPips,
+		"newMarkupAsk": askPips,
+		"reason":       reason,
+	}, reason, ipAddress, "", "SUCCESS", "")
+
+	log.Printf("[GroupMgmt] Quote markup for group %s set to %.2f/%.2f pips by %s", group.Name, bidPips, askPips, admin.Username)
+
+	return nil
+}
+
+// QuoteMarkup returns the pips a group takes off the bid and puts on the ask of
+// a symbol: the symbol's override split evenly, else the group's asymmetric
+// markup, else its Markup split evenly
+func (s *GroupManagementService) QuoteMarkup(groupID int64, symbol string) (bidPips, askPips float64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	group, exists := s.groups[groupID]
+	if !exists {
+		return 0, 0
+	}
+	if settings, ok := group.SymbolSettings[symbol]; ok && settings.Markup > 0 {
+		return settings.Markup / 2, settings.Markup / 2
+	}
+	if group.MarkupBid > 0 || group.MarkupAsk > 0 {
+		return group.MarkupBid, group.MarkupAsk
+	}
+	return group.Markup / 2, group.Markup / 2
+}
+
+// SetSlippage sets the slippage model of a group's B-Book market fills. nil
+// falls back to each symbol's model.
+func (s *GroupManagementService) SetSlippage(groupID int64, slippage *core.SlippageConfig, admin *Admin, reason string, ipAddress string) error {
+	if slippage != nil {
+		normalized, err := core.NormalizeSlippageConfig(*slippage)
+		if err != nil {
+			return err
+		}
+		slippage = &normalized
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, exists := s.groups[groupID]
+	if !exists {
+		return errors.New("group not found")
+	}
+
+	oldSlippage := group.Slippage
+	group.Slippage = slippage
+	group.UpdatedAt = time.Now()
+
+	s.auditLog.Log(admin.ID, admin.Username, "GROUP_SLIPPAGE_UPDATE", "GROUP", groupID, map[string]interface{}{
+		"old":    oldSlippage,
+		"new":    slippage,
+		"reason": reason,
+	}, reason, ipAddress, "", "SUCCESS", "")
+
+	log.Printf("[GroupMgmt] Slippage for group %s set to %+v by %s", group.Name, slippage, admin.Username)
+
+	return nil
+}
+
+// Slippage returns a group's slippage model, or false when the group has no override
+func (s *GroupManagementService) Slippage(groupID int64) (core.SlippageConfig, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	group, exists := s.groups[groupID]
+	if !exists || group.Slippage == nil {
+		return core.SlippageConfig{}, false
+	}
+	return *group.Slippage, true
+}
+
 // EnableGroup enables a disabled group
 func (s *GroupManagementService) EnableGroup(groupID int64, admin *Admin, reason string, ipAddress string) error {
 	s.mu.Lock()
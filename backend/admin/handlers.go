@@ -2,12 +2,15 @@ package admin
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/epic1st/rtx/backend/internal/core"
+	"github.com/epic1st/rtx/backend/orders"
 )
 
 // AdminHandler provides HTTP handlers for admin operations
@@ -17,17 +20,27 @@ type AdminHandler struct {
 	fundMgmt     *FundManagementService
 	orderMgmt    *OrderManagementService
 	groupMgmt    *GroupManagementService
+	symbolMgmt   *SymbolManagementService
 	auditLog     *AuditLog
+
+	// Global execution mode, owned by the server
+	executionModeGet func() string
+	executionModeSet func(mode string) error
+
+	// Called after a change that may alter an account's quote markup
+	markupChanged func()
 }
 
 // NewAdminHandler creates a new admin handler
 func NewAdminHandler(engine *core.Engine) *AdminHandler {
 	auditLog := NewAuditLog(10000)
 	authService := NewAuthService()
+	authService.SetAuditLog(auditLog)
 	userMgmt := NewUserManagementService(engine, authService, auditLog)
 	fundMgmt := NewFundManagementService(engine, auditLog)
 	orderMgmt := NewOrderManagementService(engine, auditLog)
 	groupMgmt := NewGroupManagementService(auditLog)
+	symbolMgmt := NewSymbolManagementService(engine, auditLog)
 
 	return &AdminHandler{
 		authService: authService,
@@ -35,18 +48,24 @@ func NewAdminHandler(engine *core.Engine) *AdminHandler {
 		fundMgmt:    fundMgmt,
 		orderMgmt:   orderMgmt,
 		groupMgmt:   groupMgmt,
+		symbolMgmt:  symbolMgmt,
 		auditLog:    auditLog,
 	}
 }
 
-// Helper functions
+// SetSessionLimit limits concurrent sessions per admin (0 = unlimited), optionally
+// invalidating the oldest session when a new login exceeds the limit
+func (h *AdminHandler) SetSessionLimit(maxSessions int, takeover bool) {
+	h.authService.SetSessionLimit(maxSessions, takeover)
+}
 
-func cors(w http.ResponseWriter) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+// SetAuthCacheTTL sets how long session validations are cached (0 disables caching)
+func (h *AdminHandler) SetAuthCacheTTL(ttl time.Duration) {
+	h.authService.SetAuthCacheTTL(ttl)
 }
 
+// Helper functions
+
 func getIPAddress(r *http.Request) string {
 	// Check X-Forwarded-For header first
 	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
@@ -104,7 +123,6 @@ func respondError(w http.ResponseWriter, message string, code int) {
 // Authentication Endpoints
 
 func (h *AdminHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -124,6 +142,10 @@ func (h *AdminHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	userAgent := r.UserAgent()
 
 	session, err := h.authService.Login(req.Username, req.Password, ipAddress, userAgent)
+	if errors.Is(err, ErrSessionLimitReached) {
+		respondError(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		respondError(w, err.Error(), http.StatusUnauthorized)
 		return
@@ -137,7 +159,6 @@ func (h *AdminHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *AdminHandler) HandleLogout(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -163,7 +184,6 @@ func (h *AdminHandler) HandleLogout(w http.ResponseWriter, r *http.Request) {
 // User Management Endpoints
 
 func (h *AdminHandler) HandleGetUsers(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -190,7 +210,6 @@ func (h *AdminHandler) HandleGetUsers(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *AdminHandler) HandleGetUser(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -223,7 +242,6 @@ func (h *AdminHandler) HandleGetUser(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *AdminHandler) HandleUpdateUser(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -264,7 +282,6 @@ func (h *AdminHandler) HandleUpdateUser(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *AdminHandler) HandleEnableUser(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -301,7 +318,6 @@ func (h *AdminHandler) HandleEnableUser(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *AdminHandler) HandleDisableUser(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -338,7 +354,6 @@ func (h *AdminHandler) HandleDisableUser(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *AdminHandler) HandleResetUserPassword(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -378,7 +393,6 @@ func (h *AdminHandler) HandleResetUserPassword(w http.ResponseWriter, r *http.Re
 // Fund Management Endpoints
 
 func (h *AdminHandler) HandleDeposit(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -423,7 +437,6 @@ func (h *AdminHandler) HandleDeposit(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *AdminHandler) HandleWithdraw(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -468,7 +481,6 @@ func (h *AdminHandler) HandleWithdraw(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *AdminHandler) HandleAdjust(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -511,7 +523,6 @@ func (h *AdminHandler) HandleAdjust(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *AdminHandler) HandleBonus(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -556,7 +567,6 @@ func (h *AdminHandler) HandleBonus(w http.ResponseWriter, r *http.Request) {
 // Order Management Endpoints
 
 func (h *AdminHandler) HandleGetAllOrders(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -584,7 +594,6 @@ func (h *AdminHandler) HandleGetAllOrders(w http.ResponseWriter, r *http.Request
 }
 
 func (h *AdminHandler) HandleGetAllPositions(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -611,7 +620,6 @@ func (h *AdminHandler) HandleGetAllPositions(w http.ResponseWriter, r *http.Requ
 }
 
 func (h *AdminHandler) HandleModifyOrder(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -652,7 +660,6 @@ func (h *AdminHandler) HandleModifyOrder(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *AdminHandler) HandleModifyPosition(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -691,7 +698,6 @@ func (h *AdminHandler) HandleModifyPosition(w http.ResponseWriter, r *http.Reque
 }
 
 func (h *AdminHandler) HandleReversePosition(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -728,7 +734,6 @@ func (h *AdminHandler) HandleReversePosition(w http.ResponseWriter, r *http.Requ
 }
 
 func (h *AdminHandler) HandleClosePosition(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -766,7 +771,6 @@ func (h *AdminHandler) HandleClosePosition(w http.ResponseWriter, r *http.Reques
 }
 
 func (h *AdminHandler) HandleDeleteOrder(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -805,7 +809,6 @@ func (h *AdminHandler) HandleDeleteOrder(w http.ResponseWriter, r *http.Request)
 // Group Management Endpoints
 
 func (h *AdminHandler) HandleGetGroups(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -827,7 +830,6 @@ func (h *AdminHandler) HandleGetGroups(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *AdminHandler) HandleCreateGroup(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -875,7 +877,6 @@ func (h *AdminHandler) HandleCreateGroup(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *AdminHandler) HandleUpdateGroup(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -916,12 +917,14 @@ func (h *AdminHandler) HandleUpdateGroup(w http.ResponseWriter, r *http.Request)
 		respondError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if req.Markup != nil {
+		h.notifyMarkupChanged()
+	}
 
 	respondJSON(w, map[string]bool{"success": true})
 }
 
 func (h *AdminHandler) HandleDeleteGroup(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -953,6 +956,475 @@ func (h *AdminHandler) HandleDeleteGroup(w http.ResponseWriter, r *http.Request)
 		respondError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	h.notifyMarkupChanged()
+
+	respondJSON(w, map[string]bool{"success": true})
+}
+
+func (h *AdminHandler) HandleSetGroupOrderRules(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	admin, err := h.authenticate(r)
+	if err != nil {
+		respondError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if !h.authService.CheckPermission(admin, "modify_group") {
+		respondError(w, "Insufficient permissions", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		GroupID           int64    `json:"groupId"`
+		AllowedOrderTypes []string `json:"allowedOrderTypes"`
+		DefaultTIF        string   `json:"defaultTif"`
+		DefaultSLPips     float64  `json:"defaultSlPips"`
+		DefaultTPPips     float64  `json:"defaultTpPips"`
+		DefaultMinFillRatio float64 `json:"defaultMinFillRatio"`
+		Reason            string   `json:"reason"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ipAddress := getIPAddress(r)
+	if err := h.groupMgmt.SetOrderRules(req.GroupID, req.AllowedOrderTypes, req.DefaultTIF, req.DefaultSLPips, req.DefaultTPPips, req.DefaultMinFillRatio, admin, req.Reason, ipAddress); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondJSON(w, map[string]bool{"success": true})
+}
+
+// OrderRulesForAccount resolves the placement rules of the account's group.
+// Accounts without a group are unrestricted.
+func (h *AdminHandler) OrderRulesForAccount(accountID int64) *orders.GroupOrderRules {
+	groupID := h.userMgmt.GetUserGroupID(accountID)
+	if groupID == 0 {
+		return nil
+	}
+	return h.groupMgmt.OrderRules(groupID)
+}
+
+// HandleSetGroupCommissionModel chooses markup or explicit commission pricing for a group
+func (h *AdminHandler) HandleSetGroupCommissionModel(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	admin, err := h.authenticate(r)
+	if err != nil {
+		respondError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if !h.authService.CheckPermission(admin, "modify_group") {
+		respondError(w, "Insufficient permissions", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		GroupID         int64  `json:"groupId"`
+		CommissionModel string `json:"commissionModel"`
+		Reason          string `json:"reason"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ipAddress := getIPAddress(r)
+	if err := h.groupMgmt.SetCommissionModel(req.GroupID, req.CommissionModel, admin, req.Reason, ipAddress); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondJSON(w, map[string]bool{"success": true})
+}
+
+// CommissionModelForAccount resolves the commission model override of the
+// account's group for a symbol. Empty means the symbol's own model applies.
+func (h *AdminHandler) CommissionModelForAccount(accountID int64, symbol string) string {
+	groupID := h.userMgmt.GetUserGroupID(accountID)
+	if groupID == 0 {
+		return ""
+	}
+	return h.groupMgmt.CommissionModel(groupID, symbol)
+}
+
+// CommissionRateForAccount resolves the commission rate of the account's group
+// for a symbol, or false when the symbol's own rate applies
+func (h *AdminHandler) CommissionRateForAccount(accountID int64, symbol string) (float64, bool) {
+	groupID := h.userMgmt.GetUserGroupID(accountID)
+	if groupID == 0 {
+		return 0, false
+	}
+	return h.groupMgmt.CommissionRate(groupID, symbol)
+}
+
+// HandleSetGroupStopOutCooldown sets how long a group's accounts cannot open
+// positions after a stop-out
+func (h *AdminHandler) HandleSetGroupStopOutCooldown(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	admin, err := h.authenticate(r)
+	if err != nil {
+		respondError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if !h.authService.CheckPermission(admin, "modify_group") {
+		respondError(w, "Insufficient permissions", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		GroupID  int64  `json:"groupId"`
+		Cooldown string `json:"cooldown"` // e.g. "15m"; empty uses the default, "0s" disables
+		Reason   string `json:"reason"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ipAddress := getIPAddress(r)
+	if err := h.groupMgmt.SetStopOutCooldown(req.GroupID, req.Cooldown, admin, req.Reason, ipAddress); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondJSON(w, map[string]bool{"success": true})
+}
+
+// StopOutCooldownForAccount resolves the stop-out cooldown of the account's
+// group. Negative means the broker default applies.
+func (h *AdminHandler) StopOutCooldownForAccount(accountID int64) time.Duration {
+	groupID := h.userMgmt.GetUserGroupID(accountID)
+	if groupID == 0 {
+		return -1
+	}
+	return h.groupMgmt.StopOutCooldown(groupID)
+}
+
+// AuditStopOutCooldown records that an account entered its post stop-out cooldown
+func (h *AdminHandler) AuditStopOutCooldown(accountID int64, until time.Time) {
+	h.auditLog.Log(0, "SYSTEM", "STOPOUT_COOLDOWN_START", "ACCOUNT", accountID, map[string]interface{}{
+		"until": until,
+	}, "Stop-out", "", "", "SUCCESS", "")
+}
+
+// HandleSetGroupMarginLevels sets the margin call and stop-out levels of a group
+func (h *AdminHandler) HandleSetGroupMarginLevels(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	admin, err := h.authenticate(r)
+	if err != nil {
+		respondError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if !h.authService.CheckPermission(admin, "modify_group") {
+		respondError(w, "Insufficient permissions", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		GroupID         int64   `json:"groupId"`
+		MarginCallLevel float64 `json:"marginCallLevel"` // Both 0 uses the broker default
+		StopOutLevel    float64 `json:"stopOutLevel"`
+		Reason          string  `json:"reason"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ipAddress := getIPAddress(r)
+	if err := h.groupMgmt.SetMarginLevels(req.GroupID, req.MarginCallLevel, req.StopOutLevel, admin, req.Reason, ipAddress); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondJSON(w, map[string]bool{"success": true})
+}
+
+// HandleSetGroupMarkup sets the asymmetric quote markup of a group
+func (h *AdminHandler) HandleSetGroupMarkup(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	admin, err := h.authenticate(r)
+	if err != nil {
+		respondError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if !h.authService.CheckPermission(admin, "modify_group") {
+		respondError(w, "Insufficient permissions", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		GroupID   int64   `json:"groupId"`
+		MarkupBid float64 `json:"markupBid"` // Both 0 splits the group markup evenly
+		MarkupAsk float64 `json:"markupAsk"`
+		Reason    string  `json:"reason"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ipAddress := getIPAddress(r)
+	if err := h.groupMgmt.SetQuoteMarkup(req.GroupID, req.MarkupBid, req.MarkupAsk, admin, req.Reason, ipAddress); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.notifyMarkupChanged()
+
+	respondJSON(w, map[string]bool{"success": true})
+}
+
+// SetMarkupChangeCallback sets the function called after a group's quote
+// markup or an account's group changes, so cached markups are resolved again
+func (h *AdminHandler) SetMarkupChangeCallback(fn func()) {
+	h.markupChanged = fn
+	h.userMgmt.SetGroupChangeCallback(func(accountID int64) { h.notifyMarkupChanged() })
+}
+
+func (h *AdminHandler) notifyMarkupChanged() {
+	if h.markupChanged != nil {
+		h.markupChanged()
+	}
+}
+
+// QuoteMarkupForAccount resolves the markup in pips the account's group adds
+// to the bid and ask of a symbol; accounts without a group see raw quotes
+func (h *AdminHandler) QuoteMarkupForAccount(accountID int64, symbol string) (bidPips, askPips float64) {
+	groupID := h.userMgmt.GetUserGroupID(accountID)
+	if groupID == 0 {
+		return 0, 0
+	}
+	return h.groupMgmt.QuoteMarkup(groupID, symbol)
+}
+
+// MarginLevelsForAccount resolves the margin call and stop-out levels of the
+// account's group, or false when the broker default applies
+func (h *AdminHandler) MarginLevelsForAccount(accountID int64) (core.MarginLevels, bool) {
+	groupID := h.userMgmt.GetUserGroupID(accountID)
+	if groupID == 0 {
+		return core.MarginLevels{}, false
+	}
+	return h.groupMgmt.MarginLevels(groupID)
+}
+
+// HandleSetGroupSlippage sets the slippage model of a group's market fills
+func (h *AdminHandler) HandleSetGroupSlippage(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	admin, err := h.authenticate(r)
+	if err != nil {
+		respondError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if !h.authService.CheckPermission(admin, "modify_group") {
+		respondError(w, "Insufficient permissions", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		GroupID  int64                `json:"groupId"`
+		Slippage *core.SlippageConfig `json:"slippage"` // null uses each symbol's model
+		Reason   string               `json:"reason"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ipAddress := getIPAddress(r)
+	if err := h.groupMgmt.SetSlippage(req.GroupID, req.Slippage, admin, req.Reason, ipAddress); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondJSON(w, map[string]bool{"success": true})
+}
+
+// SlippageForAccount resolves the slippage model of the account's group, or
+// false when the symbol's own model applies
+func (h *AdminHandler) SlippageForAccount(accountID int64, symbol string) (core.SlippageConfig, bool) {
+	groupID := h.userMgmt.GetUserGroupID(accountID)
+	if groupID == 0 {
+		return core.SlippageConfig{}, false
+	}
+	return h.groupMgmt.Slippage(groupID)
+}
+
+// ExecutionModeForAccount resolves the ABOOK or BBOOK execution mode of the
+// account's group, or false when the symbol and global modes apply
+func (h *AdminHandler) ExecutionModeForAccount(accountID int64) (string, bool) {
+	groupID := h.userMgmt.GetUserGroupID(accountID)
+	if groupID == 0 {
+		return "", false
+	}
+	return h.groupMgmt.ExecutionMode(groupID)
+}
+
+// MarginModeForAccount resolves the HEDGING or NETTING mode of the account's
+// group, or false when the account's own mode applies
+func (h *AdminHandler) MarginModeForAccount(accountID int64) (string, bool) {
+	groupID := h.userMgmt.GetUserGroupID(accountID)
+	if groupID == 0 {
+		return "", false
+	}
+	return h.groupMgmt.MarginMode(groupID)
+}
+
+// AuditMarginEvent records a margin call or a stop-out close of an account
+func (h *AdminHandler) AuditMarginEvent(event core.MarginEvent) {
+	details := map[string]interface{}{
+		"marginLevel": event.MarginLevel,
+		"threshold":   event.Threshold,
+		"equity":      event.Equity,
+		"margin":      event.Margin,
+	}
+	if event.Trade != nil {
+		details["positionId"] = event.Trade.PositionID
+		details["tradeId"] = event.Trade.ID
+		details["realizedPnL"] = event.Trade.RealizedPnL
+	}
+	h.auditLog.Log(0, "SYSTEM", event.Type, "ACCOUNT", event.AccountID, details, "Margin level", "", "", "SUCCESS", "")
+}
+
+// Symbol Management Endpoints
+
+// HandleSuspendSymbol suspends a symbol. Policy controls existing positions:
+// FREEZE holds them, FORCE_CLOSE liquidates them at market, READONLY locks them.
+func (h *AdminHandler) HandleSuspendSymbol(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "POST" {
+		respondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	admin, err := h.authenticate(r)
+	if err != nil {
+		respondError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if !h.authService.CheckPermission(admin, "system_config") {
+		respondError(w, "Insufficient permissions", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		Symbol string `json:"symbol"`
+		Policy string `json:"policy"`
+		Reason string `json:"reason"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Symbol == "" || req.Policy == "" {
+		respondError(w, "symbol and policy are required", http.StatusBadRequest)
+		return
+	}
+
+	ipAddress := getIPAddress(r)
+	result, err := h.symbolMgmt.SuspendSymbol(req.Symbol, req.Policy, admin, req.Reason, ipAddress)
+	if errors.Is(err, core.ErrForceCloseIncomplete) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+			"result":  result,
+		})
+		return
+	}
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondJSON(w, map[string]interface{}{
+		"success": true,
+		"result":  result,
+	})
+}
+
+// HandleResumeSymbol lifts a symbol suspension
+func (h *AdminHandler) HandleResumeSymbol(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "POST" {
+		respondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	admin, err := h.authenticate(r)
+	if err != nil {
+		respondError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if !h.authService.CheckPermission(admin, "system_config") {
+		respondError(w, "Insufficient permissions", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		Symbol string `json:"symbol"`
+		Reason string `json:"reason"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ipAddress := getIPAddress(r)
+	if err := h.symbolMgmt.ResumeSymbol(req.Symbol, admin, req.Reason, ipAddress); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	respondJSON(w, map[string]bool{"success": true})
 }
@@ -960,7 +1432,6 @@ func (h *AdminHandler) HandleDeleteGroup(w http.ResponseWriter, r *http.Request)
 // Audit Trail Endpoints
 
 func (h *AdminHandler) HandleGetAuditLog(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -1028,6 +1499,16 @@ func (h *AdminHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/admin/group/create", h.HandleCreateGroup)
 	mux.HandleFunc("/admin/group/update", h.HandleUpdateGroup)
 	mux.HandleFunc("/admin/group/delete", h.HandleDeleteGroup)
+	mux.HandleFunc("/admin/group/order-rules", h.HandleSetGroupOrderRules)
+	mux.HandleFunc("/admin/group/commission-model", h.HandleSetGroupCommissionModel)
+	mux.HandleFunc("/admin/group/stopout-cooldown", h.HandleSetGroupStopOutCooldown)
+	mux.HandleFunc("/admin/group/margin-levels", h.HandleSetGroupMarginLevels)
+	mux.HandleFunc("/admin/group/slippage", h.HandleSetGroupSlippage)
+	mux.HandleFunc("/admin/group/markup", h.HandleSetGroupMarkup)
+
+	// Symbol Management
+	mux.HandleFunc("/admin/symbols/suspend", h.HandleSuspendSymbol)
+	mux.HandleFunc("/admin/symbols/resume", h.HandleResumeSymbol)
 
 	// Audit Trail
 	mux.HandleFunc("/admin/audit", h.HandleGetAuditLog)
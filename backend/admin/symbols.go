@@ -0,0 +1,93 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+// SymbolManagementService handles admin symbol operations
+type SymbolManagementService struct {
+	engine   *core.Engine
+	auditLog *AuditLog
+}
+
+// NewSymbolManagementService creates a new symbol management service
+func NewSymbolManagementService(engine *core.Engine, auditLog *AuditLog) *SymbolManagementService {
+	return &SymbolManagementService{
+		engine:   engine,
+		auditLog: auditLog,
+	}
+}
+
+// SuspendSymbol suspends a symbol with the given open-position policy
+func (s *SymbolManagementService) SuspendSymbol(symbol, policy string, admin *Admin, reason, ipAddress string) (*core.SuspensionResult, error) {
+	symbol = strings.ToUpper(symbol)
+	policy = strings.ToUpper(policy)
+
+	result, err := s.engine.SuspendSymbol(symbol, policy)
+	if errors.Is(err, core.ErrForceCloseIncomplete) {
+		// Some positions were closed: record them with the failures
+		s.auditLog.Log(admin.ID, admin.Username, "SYMBOL_SUSPEND", "SYMBOL", 0, map[string]interface{}{
+			"symbol":          symbol,
+			"policy":          policy,
+			"openPositions":   result.OpenPositions,
+			"closedPositions": closedPositionIDs(result),
+			"failedPositions": result.FailedPositions,
+		}, reason, ipAddress, "", "PARTIAL", err.Error())
+		return result, fmt.Errorf("failed to suspend symbol: %w", err)
+	}
+	if err != nil {
+		s.auditLog.Log(admin.ID, admin.Username, "SYMBOL_SUSPEND", "SYMBOL", 0, map[string]interface{}{
+			"symbol": symbol,
+			"policy": policy,
+		}, reason, ipAddress, "", "FAILED", err.Error())
+		return nil, fmt.Errorf("failed to suspend symbol: %w", err)
+	}
+
+	closedIDs := closedPositionIDs(result)
+
+	s.auditLog.Log(admin.ID, admin.Username, "SYMBOL_SUSPEND", "SYMBOL", 0, map[string]interface{}{
+		"symbol":          symbol,
+		"policy":          policy,
+		"previousPolicy":  result.PreviousPolicy,
+		"openPositions":   result.OpenPositions,
+		"closedPositions": closedIDs,
+	}, reason, ipAddress, "", "SUCCESS", "")
+
+	log.Printf("[SymbolMgmt] %s suspended by %s (policy=%s, closed %d positions)",
+		symbol, admin.Username, policy, len(closedIDs))
+
+	return result, nil
+}
+
+func closedPositionIDs(result *core.SuspensionResult) []int64 {
+	ids := make([]int64, 0, len(result.ClosedPositions))
+	for _, trade := range result.ClosedPositions {
+		ids = append(ids, trade.PositionID)
+	}
+	return ids
+}
+
+// ResumeSymbol lifts a symbol suspension
+func (s *SymbolManagementService) ResumeSymbol(symbol string, admin *Admin, reason, ipAddress string) error {
+	symbol = strings.ToUpper(symbol)
+
+	if err := s.engine.ResumeSymbol(symbol); err != nil {
+		s.auditLog.Log(admin.ID, admin.Username, "SYMBOL_RESUME", "SYMBOL", 0, map[string]interface{}{
+			"symbol": symbol,
+		}, reason, ipAddress, "", "FAILED", err.Error())
+		return fmt.Errorf("failed to resume symbol: %w", err)
+	}
+
+	s.auditLog.Log(admin.ID, admin.Username, "SYMBOL_RESUME", "SYMBOL", 0, map[string]interface{}{
+		"symbol": symbol,
+	}, reason, ipAddress, "", "SUCCESS", "")
+
+	log.Printf("[SymbolMgmt] %s resumed by %s", symbol, admin.Username)
+
+	return nil
+}
@@ -1,6 +1,10 @@
 package admin
 
-import "time"
+import (
+	"time"
+
+	"github.com/epic1st/rtx/backend/internal/core"
+)
 
 // AdminRole defines admin privilege levels
 type AdminRole string
@@ -47,12 +51,24 @@ type UserGroup struct {
 	Description     string            `json:"description"`
 	ExecutionMode   string            `json:"executionMode"` // BBOOK, ABOOK, HYBRID
 	Markup          float64           `json:"markup"`        // Spread markup in pips
+	MarkupBid       float64           `json:"markupBid,omitempty"` // Pips off the bid; with MarkupAsk replaces the even split of Markup
+	MarkupAsk       float64           `json:"markupAsk,omitempty"` // Pips on the ask
 	Commission      float64           `json:"commission"`    // Commission per lot
+	CommissionModel string            `json:"commissionModel,omitempty"` // COMMISSION or MARKUP; empty uses the symbol's model
 	MaxLeverage     float64           `json:"maxLeverage"`
 	EnabledSymbols  []string          `json:"enabledSymbols"`
 	SymbolSettings  map[string]SymbolGroupSettings `json:"symbolSettings"`
 	DefaultBalance  float64           `json:"defaultBalance"`
 	MarginMode      string            `json:"marginMode"` // HEDGING, NETTING
+	AllowedOrderTypes []string        `json:"allowedOrderTypes,omitempty"` // MARKET, LIMIT, STOP, STOP_LIMIT; empty allows all
+	DefaultTIF      string            `json:"defaultTif,omitempty"`        // Applied when an order omits time-in-force
+	DefaultSLPips   float64           `json:"defaultSlPips,omitempty"`     // Applied when an order omits SL
+	DefaultTPPips   float64           `json:"defaultTpPips,omitempty"`     // Applied when an order omits TP
+	DefaultMinFillRatio float64       `json:"defaultMinFillRatio,omitempty"` // Applied when a market order omits its minimum fill ratio
+	StopOutCooldown string            `json:"stopOutCooldown,omitempty"` // New orders blocked this long after a stop-out, e.g. "15m"; empty uses the default
+	MarginCallLevel float64           `json:"marginCallLevel,omitempty"` // Margin level % raising a margin call; with StopOutLevel 0 uses the default
+	StopOutLevel    float64           `json:"stopOutLevel,omitempty"`    // Margin level % at which positions are force closed, 0 disables when MarginCallLevel is set
+	Slippage        *core.SlippageConfig `json:"slippage,omitempty"`    // Slippage on market fills; nil uses each symbol's model
 	Status          string            `json:"status"`     // ACTIVE, DISABLED
 	CreatedAt       time.Time         `json:"createdAt"`
 	UpdatedAt       time.Time         `json:"updatedAt"`
@@ -64,6 +80,7 @@ type SymbolGroupSettings struct {
 	Symbol         string  `json:"symbol"`
 	Markup         float64 `json:"markup"`     // Override group markup
 	Commission     float64 `json:"commission"` // Override group commission
+	CommissionModel string `json:"commissionModel,omitempty"` // Override group commission model
 	MaxVolume      float64 `json:"maxVolume"`
 	MinVolume      float64 `json:"minVolume"`
 	Disabled       bool    `json:"disabled"`
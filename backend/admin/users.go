@@ -12,6 +12,8 @@ import (
 
 // UserManagementService handles admin user operations
 type UserManagementService struct {
+	// mu guards the maps below. It is never held while calling into the
+	// engine: the engine's group resolvers read userGroups under its own lock.
 	mu          sync.RWMutex
 	engine      *core.Engine
 	authService *AuthService
@@ -19,6 +21,8 @@ type UserManagementService struct {
 	userGroups  map[int64]int64 // accountID -> groupID
 	userEmails  map[int64]string
 	lastLogins  map[int64]time.Time
+
+	groupChanged func(accountID int64) // Called without mu held
 }
 
 // NewUserManagementService creates a new user management service
@@ -33,11 +37,21 @@ func NewUserManagementService(engine *core.Engine, authService *AuthService, aud
 	}
 }
 
+// SetGroupChangeCallback sets the function called after an account moves to
+// another group
+func (s *UserManagementService) SetGroupChangeCallback(fn func(accountID int64)) {
+	s.groupChanged = fn
+}
+
+// notifyGroupChanged runs the group change callback (caller must not hold lock)
+func (s *UserManagementService) notifyGroupChanged(accountID int64) {
+	if s.groupChanged != nil {
+		s.groupChanged(accountID)
+	}
+}
+
 // GetAllUsers returns all user accounts with detailed info
 func (s *UserManagementService) GetAllUsers() ([]*UserAccountInfo, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	var users []*UserAccountInfo
 
 	// Iterate through accounts (assuming IDs 1-1000)
@@ -47,45 +61,11 @@ func (s *UserManagementService) GetAllUsers() ([]*UserAccountInfo, error) {
 			continue
 		}
 
-		summary, err := s.engine.GetAccountSummary(i)
+		userInfo, err := s.userInfo(account)
 		if err != nil {
 			log.Printf("[UserMgmt] Failed to get summary for account %d: %v", i, err)
 			continue
 		}
-
-		// Get trades to calculate stats
-		trades := s.engine.GetTrades(i)
-		totalVolume := 0.0
-		totalPnL := 0.0
-		for _, trade := range trades {
-			totalVolume += trade.Volume
-			totalPnL += trade.RealizedPnL
-		}
-
-		userInfo := &UserAccountInfo{
-			ID:            account.ID,
-			AccountNumber: account.AccountNumber,
-			UserID:        account.UserID,
-			Username:      account.Username,
-			Email:         s.userEmails[account.ID],
-			Balance:       summary.Balance,
-			Equity:        summary.Equity,
-			Margin:        summary.Margin,
-			FreeMargin:    summary.FreeMargin,
-			Leverage:      account.Leverage,
-			GroupID:       s.userGroups[account.ID],
-			Status:        account.Status,
-			IsDemo:        account.IsDemo,
-			CreatedAt:     time.Unix(account.CreatedAt, 0),
-			OpenPositions: summary.OpenPositions,
-			TotalVolume:   totalVolume,
-			TotalPnL:      totalPnL,
-		}
-
-		if lastLogin, ok := s.lastLogins[account.ID]; ok {
-			userInfo.LastLogin = &lastLogin
-		}
-
 		users = append(users, userInfo)
 	}
 
@@ -94,20 +74,28 @@ func (s *UserManagementService) GetAllUsers() ([]*UserAccountInfo, error) {
 
 // GetUser returns detailed info for a specific user
 func (s *UserManagementService) GetUser(accountID int64) (*UserAccountInfo, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	account, ok := s.engine.GetAccount(accountID)
 	if !ok {
 		return nil, errors.New("account not found")
 	}
 
-	summary, err := s.engine.GetAccountSummary(accountID)
+	userInfo, err := s.userInfo(account)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get account summary: %w", err)
 	}
+	return userInfo, nil
+}
 
-	trades := s.engine.GetTrades(accountID)
+// userInfo combines an account's engine state with its user management
+// details
+func (s *UserManagementService) userInfo(account *core.Account) (*UserAccountInfo, error) {
+	summary, err := s.engine.GetAccountSummary(account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get trades to calculate stats
+	trades := s.engine.GetTrades(account.ID)
 	totalVolume := 0.0
 	totalPnL := 0.0
 	for _, trade := range trades {
@@ -115,6 +103,9 @@ func (s *UserManagementService) GetUser(accountID int64) (*UserAccountInfo, erro
 		totalPnL += trade.RealizedPnL
 	}
 
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	userInfo := &UserAccountInfo{
 		ID:            account.ID,
 		AccountNumber: account.AccountNumber,
@@ -144,9 +135,6 @@ func (s *UserManagementService) GetUser(accountID int64) (*UserAccountInfo, erro
 
 // UpdateUserAccount updates user account settings
 func (s *UserManagementService) UpdateUserAccount(accountID int64, leverage *float64, marginMode *string, groupID *int64, email *string, admin *Admin, reason string, ipAddress string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	account, ok := s.engine.GetAccount(accountID)
 	if !ok {
 		return errors.New("account not found")
@@ -175,8 +163,10 @@ func (s *UserManagementService) UpdateUserAccount(accountID int64, leverage *flo
 		}
 	}
 
+	s.mu.Lock()
 	// Update group
-	if groupID != nil && s.userGroups[accountID] != *groupID {
+	groupChanged := groupID != nil && s.userGroups[accountID] != *groupID
+	if groupChanged {
 		oldValues["groupID"] = s.userGroups[accountID]
 		changes["groupID"] = *groupID
 		s.userGroups[accountID] = *groupID
@@ -188,6 +178,11 @@ func (s *UserManagementService) UpdateUserAccount(accountID int64, leverage *flo
 		changes["email"] = *email
 		s.userEmails[accountID] = *email
 	}
+	s.mu.Unlock()
+
+	if groupChanged {
+		s.notifyGroupChanged(accountID)
+	}
 
 	// Log audit
 	s.auditLog.Log(admin.ID, admin.Username, "USER_UPDATE", "USER", accountID, map[string]interface{}{
@@ -203,9 +198,6 @@ func (s *UserManagementService) UpdateUserAccount(accountID int64, leverage *flo
 
 // EnableUserAccount enables a disabled account
 func (s *UserManagementService) EnableUserAccount(accountID int64, admin *Admin, reason string, ipAddress string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	account, ok := s.engine.GetAccount(accountID)
 	if !ok {
 		return errors.New("account not found")
@@ -231,9 +223,6 @@ func (s *UserManagementService) EnableUserAccount(accountID int64, admin *Admin,
 
 // DisableUserAccount disables an account
 func (s *UserManagementService) DisableUserAccount(accountID int64, admin *Admin, reason string, ipAddress string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	account, ok := s.engine.GetAccount(accountID)
 	if !ok {
 		return errors.New("account not found")
@@ -265,9 +254,6 @@ func (s *UserManagementService) DisableUserAccount(accountID int64, admin *Admin
 
 // ResetUserPassword resets a user's password
 func (s *UserManagementService) ResetUserPassword(accountID int64, newPassword string, admin *Admin, reason string, ipAddress string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	account, ok := s.engine.GetAccount(accountID)
 	if !ok {
 		return errors.New("account not found")
@@ -318,18 +304,28 @@ func (s *UserManagementService) RecordLogin(accountID int64) {
 	s.lastLogins[accountID] = time.Now()
 }
 
+// GetUserGroupID returns the group an account is assigned to, or 0 if unassigned
+func (s *UserManagementService) GetUserGroupID(accountID int64) int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.userGroups[accountID]
+}
+
 // AssignUserToGroup assigns a user to a trading group
 func (s *UserManagementService) AssignUserToGroup(accountID, groupID int64, admin *Admin, reason string, ipAddress string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	account, ok := s.engine.GetAccount(accountID)
 	if !ok {
 		return errors.New("account not found")
 	}
 
+	s.mu.Lock()
 	oldGroupID := s.userGroups[accountID]
 	s.userGroups[accountID] = groupID
+	s.mu.Unlock()
+
+	if oldGroupID != groupID {
+		s.notifyGroupChanged(accountID)
+	}
 
 	s.auditLog.Log(admin.ID, admin.Username, "USER_GROUP_ASSIGN", "USER", accountID, map[string]interface{}{
 		"oldGroupID": oldGroupID,
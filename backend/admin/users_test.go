@@ -0,0 +1,76 @@
+package admin
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+// TestGroupReassignmentDuringOrders tests that reassigning groups while orders
+// resolve group overrides under the engine lock cannot deadlock
+func TestGroupReassignmentDuringOrders(t *testing.T) {
+	engine := core.NewEngine()
+	h := NewAdminHandler(engine)
+	engine.SetCommissionModelResolver(h.CommissionModelForAccount)
+	engine.SetPriceCallback(func(symbol string) (float64, float64, bool) { return 1.1000, 1.1002, true })
+
+	account := engine.CreateAccount("alice", "alice", "password", true)
+	account.Balance = 1000000
+	admin := &Admin{ID: 1, Username: "admin"}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 2000; i++ {
+			h.userMgmt.AssignUserToGroup(account.ID, int64(i%3), admin, "test", "127.0.0.1")
+			groupID := int64(i % 2)
+			h.userMgmt.UpdateUserAccount(account.ID, nil, nil, &groupID, nil, admin, "test", "127.0.0.1")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 2000; i++ {
+			engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.01, 0, 0)
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("group reassignment and order placement deadlocked")
+	}
+}
+
+// TestGroupChangeInvalidatesMarkups tests that moving an account to another
+// group, through either update path, drops the cached quote markups
+func TestGroupChangeInvalidatesMarkups(t *testing.T) {
+	engine := core.NewEngine()
+	h := NewAdminHandler(engine)
+	invalidations := 0
+	h.SetMarkupChangeCallback(func() { invalidations++ })
+
+	account := engine.CreateAccount("alice", "alice", "password", true)
+	admin := &Admin{ID: 1, Username: "admin"}
+
+	groupID := int64(2)
+	if err := h.userMgmt.UpdateUserAccount(account.ID, nil, nil, &groupID, nil, admin, "test", "127.0.0.1"); err != nil {
+		t.Fatalf("UpdateUserAccount() error = %v", err)
+	}
+	if err := h.userMgmt.UpdateUserAccount(account.ID, nil, nil, &groupID, nil, admin, "unchanged", "127.0.0.1"); err != nil {
+		t.Fatalf("UpdateUserAccount() error = %v", err)
+	}
+	if err := h.userMgmt.AssignUserToGroup(account.ID, 3, admin, "test", "127.0.0.1"); err != nil {
+		t.Fatalf("AssignUserToGroup() error = %v", err)
+	}
+	if invalidations != 2 {
+		t.Errorf("markup invalidations = %d, want one per group change", invalidations)
+	}
+}
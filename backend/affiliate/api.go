@@ -51,63 +51,47 @@ func NewAffiliateAPI() *AffiliateAPI {
 // RegisterRoutes registers all affiliate API routes
 func (api *AffiliateAPI) RegisterRoutes() {
 	// Affiliate Management
-	http.HandleFunc("/api/affiliate/register", api.handleCORS(api.HandleRegisterAffiliate))
-	http.HandleFunc("/api/affiliate/login", api.handleCORS(api.HandleAffiliateLogin))
-	http.HandleFunc("/api/affiliate/profile", api.handleCORS(api.HandleGetProfile))
-	http.HandleFunc("/api/affiliate/update", api.handleCORS(api.HandleUpdateProfile))
+	http.HandleFunc("/api/affiliate/register", api.HandleRegisterAffiliate)
+	http.HandleFunc("/api/affiliate/login", api.HandleAffiliateLogin)
+	http.HandleFunc("/api/affiliate/profile", api.HandleGetProfile)
+	http.HandleFunc("/api/affiliate/update", api.HandleUpdateProfile)
 
 	// Links
-	http.HandleFunc("/api/affiliate/links", api.handleCORS(api.HandleGetLinks))
-	http.HandleFunc("/api/affiliate/links/create", api.handleCORS(api.HandleCreateLink))
+	http.HandleFunc("/api/affiliate/links", api.HandleGetLinks)
+	http.HandleFunc("/api/affiliate/links/create", api.HandleCreateLink)
 
 	// Dashboard & Analytics
-	http.HandleFunc("/api/affiliate/dashboard", api.handleCORS(api.HandleGetDashboard))
-	http.HandleFunc("/api/affiliate/stats", api.handleCORS(api.HandleGetStats))
-	http.HandleFunc("/api/affiliate/funnel", api.handleCORS(api.HandleGetFunnel))
-	http.HandleFunc("/api/affiliate/traffic", api.handleCORS(api.HandleGetTraffic))
-	http.HandleFunc("/api/affiliate/performance", api.handleCORS(api.HandleGetPerformance))
+	http.HandleFunc("/api/affiliate/dashboard", api.HandleGetDashboard)
+	http.HandleFunc("/api/affiliate/stats", api.HandleGetStats)
+	http.HandleFunc("/api/affiliate/funnel", api.HandleGetFunnel)
+	http.HandleFunc("/api/affiliate/traffic", api.HandleGetTraffic)
+	http.HandleFunc("/api/affiliate/performance", api.HandleGetPerformance)
 
 	// Commissions & Payouts
-	http.HandleFunc("/api/affiliate/commissions", api.handleCORS(api.HandleGetCommissions))
-	http.HandleFunc("/api/affiliate/payouts", api.handleCORS(api.HandleGetPayouts))
-	http.HandleFunc("/api/affiliate/payout/request", api.handleCORS(api.HandleRequestPayout))
+	http.HandleFunc("/api/affiliate/commissions", api.HandleGetCommissions)
+	http.HandleFunc("/api/affiliate/payouts", api.HandleGetPayouts)
+	http.HandleFunc("/api/affiliate/payout/request", api.HandleRequestPayout)
 
 	// Marketing Materials
-	http.HandleFunc("/api/affiliate/materials", api.handleCORS(api.HandleGetMaterials))
+	http.HandleFunc("/api/affiliate/materials", api.HandleGetMaterials)
 
 	// Referral Program (User-to-User)
-	http.HandleFunc("/api/referral/code", api.handleCORS(api.HandleGetReferralCode))
-	http.HandleFunc("/api/referral/apply", api.handleCORS(api.HandleApplyReferralCode))
-	http.HandleFunc("/api/referral/stats", api.handleCORS(api.HandleGetReferralStats))
-	http.HandleFunc("/api/referral/leaderboard", api.handleCORS(api.HandleGetLeaderboard))
+	http.HandleFunc("/api/referral/code", api.HandleGetReferralCode)
+	http.HandleFunc("/api/referral/apply", api.HandleApplyReferralCode)
+	http.HandleFunc("/api/referral/stats", api.HandleGetReferralStats)
+	http.HandleFunc("/api/referral/leaderboard", api.HandleGetLeaderboard)
 
 	// Public Tracking (No auth)
-	http.HandleFunc("/track/click", api.handleCORS(api.HandleTrackClick))
-	http.HandleFunc("/track/pixel.gif", api.handleCORS(api.HandleTrackingPixel))
+	http.HandleFunc("/track/click", api.HandleTrackClick)
+	http.HandleFunc("/track/pixel.gif", api.HandleTrackingPixel)
 
 	// Admin Endpoints
-	http.HandleFunc("/admin/affiliate/list", api.handleCORS(api.HandleAdminListAffiliates))
-	http.HandleFunc("/admin/affiliate/approve", api.handleCORS(api.HandleAdminApproveAffiliate))
-	http.HandleFunc("/admin/affiliate/suspend", api.handleCORS(api.HandleAdminSuspendAffiliate))
-	http.HandleFunc("/admin/affiliate/commissions/approve", api.handleCORS(api.HandleAdminApproveCommission))
-	http.HandleFunc("/admin/affiliate/payouts/process", api.handleCORS(api.HandleAdminProcessPayout))
-	http.HandleFunc("/admin/affiliate/fraud", api.handleCORS(api.HandleAdminGetFraudIncidents))
-}
-
-// handleCORS wraps handlers with CORS headers
-func (api *AffiliateAPI) handleCORS(handler http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-
-		handler(w, r)
-	}
+	http.HandleFunc("/admin/affiliate/list", api.HandleAdminListAffiliates)
+	http.HandleFunc("/admin/affiliate/approve", api.HandleAdminApproveAffiliate)
+	http.HandleFunc("/admin/affiliate/suspend", api.HandleAdminSuspendAffiliate)
+	http.HandleFunc("/admin/affiliate/commissions/approve", api.HandleAdminApproveCommission)
+	http.HandleFunc("/admin/affiliate/payouts/process", api.HandleAdminProcessPayout)
+	http.HandleFunc("/admin/affiliate/fraud", api.HandleAdminGetFraudIncidents)
 }
 
 // HandleRegisterAffiliate handles affiliate registration
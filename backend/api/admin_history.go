@@ -91,8 +91,6 @@ func NewAdminHistoryHandler(ts tickstore.TickStorageService, authService *auth.S
 
 // HandleGetStats returns comprehensive statistics about historical data
 func (h *AdminHistoryHandler) HandleGetStats(w http.ResponseWriter, r *http.Request) {
-	h.setCORS(w, r)
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -174,8 +172,6 @@ func (h *AdminHistoryHandler) HandleGetStats(w http.ResponseWriter, r *http.Requ
 
 // HandleImportData handles bulk data import
 func (h *AdminHistoryHandler) HandleImportData(w http.ResponseWriter, r *http.Request) {
-	h.setCORS(w, r)
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -218,8 +214,6 @@ func (h *AdminHistoryHandler) HandleImportData(w http.ResponseWriter, r *http.Re
 
 // HandleCleanupOldData handles cleanup of old historical data
 func (h *AdminHistoryHandler) HandleCleanupOldData(w http.ResponseWriter, r *http.Request) {
-	h.setCORS(w, r)
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -307,8 +301,6 @@ func (h *AdminHistoryHandler) HandleCleanupOldData(w http.ResponseWriter, r *htt
 
 // HandleCompressData handles compression of historical data
 func (h *AdminHistoryHandler) HandleCompressData(w http.ResponseWriter, r *http.Request) {
-	h.setCORS(w, r)
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -335,8 +327,6 @@ func (h *AdminHistoryHandler) HandleCompressData(w http.ResponseWriter, r *http.
 
 // HandleBackup handles backing up historical data
 func (h *AdminHistoryHandler) HandleBackup(w http.ResponseWriter, r *http.Request) {
-	h.setCORS(w, r)
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -368,8 +358,6 @@ func (h *AdminHistoryHandler) HandleBackup(w http.ResponseWriter, r *http.Reques
 
 // HandleGetMonitoring returns real-time monitoring data
 func (h *AdminHistoryHandler) HandleGetMonitoring(w http.ResponseWriter, r *http.Request) {
-	h.setCORS(w, r)
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -411,21 +399,3 @@ func (h *AdminHistoryHandler) HandleGetMonitoring(w http.ResponseWriter, r *http
 	json.NewEncoder(w).Encode(monitoring)
 }
 
-// Helper: setCORS sets CORS headers
-func (h *AdminHistoryHandler) setCORS(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-}
-
-// Helper: isValidSymbol validates symbol format to prevent path traversal
-func isValidSymbol(symbol string) bool {
-	// Only allow alphanumeric characters (A-Z, 0-9)
-	// Prevents path traversal attacks like "../../../etc/passwd"
-	for _, c := range symbol {
-		if !((c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
-			return false
-		}
-	}
-	return len(symbol) > 0 && len(symbol) <= 20
-}
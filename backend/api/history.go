@@ -5,6 +5,7 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
@@ -12,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/epic1st/rtx/backend/auth"
 	"github.com/epic1st/rtx/backend/tickstore"
 )
 
@@ -22,6 +24,13 @@ type HistoryHandler struct {
 	// Rate limiting
 	rateLimiter *RateLimiter
 
+	// Bearer token validation for admin endpoints
+	authService *auth.Service
+
+	// LP history for fetch backfills, and where backfills land without a DailyStore
+	historyFetcher HistoryFetcher
+	backfillPath   string
+
 	// Symbol metadata cache
 	symbolCache map[string]SymbolMetadata
 	symbolMu    sync.RWMutex
@@ -49,6 +58,7 @@ type TicksResponse struct {
 	HasMore    bool               `json:"has_more,omitempty"`
 	Ticks      []tickstore.Tick   `json:"ticks"`
 	Format     string             `json:"format"`
+	Resolution string             `json:"resolution,omitempty"` // Bucket size ticks were downsampled to
 }
 
 // BulkTicksRequest is the request format for bulk download
@@ -74,6 +84,21 @@ type AvailableDataResponse struct {
 	Total   int              `json:"total"`
 }
 
+// FetchBackfillRequest asks for a symbol's LP history to be fetched and backfilled
+type FetchBackfillRequest struct {
+	Symbol      string    `json:"symbol"`
+	Granularity string    `json:"granularity"` // LP candle size, e.g. M1 (default)
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"` // Default: now
+}
+
+// maxFetchBackfillRange caps one fetch backfill, keeping LP requests and
+// merged files to a manageable size
+const maxFetchBackfillRange = 31 * 24 * time.Hour
+
+// HistoryFetcher fetches a symbol's history in [from, to) from an LP as ticks
+type HistoryFetcher func(symbol, granularity string, from, to time.Time) ([]tickstore.Tick, error)
+
 // BackfillRequest is the request format for backfilling historical data
 type BackfillRequest struct {
 	Symbol string             `json:"symbol"`
@@ -81,6 +106,12 @@ type BackfillRequest struct {
 	Source string             `json:"source"` // Source of the data (e.g., "external", "provider_name")
 }
 
+// Rate limiter keys idle this long are forgotten, checked every janitor interval
+const (
+	rateLimitIdleTTL         = 10 * time.Minute
+	rateLimitJanitorInterval = time.Minute
+)
+
 // RateLimiter implements token bucket rate limiting
 type RateLimiter struct {
 	mu            sync.Mutex
@@ -88,15 +119,71 @@ type RateLimiter struct {
 	maxTokens     int
 	refillRate    int // tokens per second
 	lastRefill    map[string]time.Time
+	stopChan      chan struct{}
 }
 
-// NewRateLimiter creates a new rate limiter
+// NewRateLimiter creates a new rate limiter whose janitor drops idle keys
 func NewRateLimiter(maxTokens, refillRate int) *RateLimiter {
-	return &RateLimiter{
+	rl := &RateLimiter{
 		tokens:     make(map[string]int),
 		maxTokens:  maxTokens,
 		refillRate: refillRate,
 		lastRefill: make(map[string]time.Time),
+		stopChan:   make(chan struct{}),
+	}
+	go rl.janitor(rateLimitJanitorInterval, rateLimitIdleTTL)
+	return rl
+}
+
+// janitor removes idle keys every interval until Stop
+func (rl *RateLimiter) janitor(interval, idle time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rl.stopChan:
+			return
+		case now := <-ticker.C:
+			if removed := rl.removeIdle(now, idle); removed > 0 {
+				log.Printf("[HistoryAPI] Rate limiter dropped %d idle clients", removed)
+			}
+		}
+	}
+}
+
+// removeIdle forgets keys not refilled since idle before now and returns how
+// many went. Their buckets would be full again by the next request, so
+// dropping them changes no one's limit.
+func (rl *RateLimiter) removeIdle(now time.Time, idle time.Duration) int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	removed := 0
+	for key, last := range rl.lastRefill {
+		if now.Sub(last) > idle {
+			delete(rl.lastRefill, key)
+			delete(rl.tokens, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Size returns the number of clients being tracked
+func (rl *RateLimiter) Size() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return len(rl.tokens)
+}
+
+// Stop stops the idle key janitor
+func (rl *RateLimiter) Stop() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.stopChan != nil {
+		close(rl.stopChan)
+		rl.stopChan = nil
 	}
 }
 
@@ -137,50 +224,83 @@ func NewHistoryHandler(ts tickstore.TickStorageService) *HistoryHandler {
 		tickStore:   ts,
 		rateLimiter: NewRateLimiter(100, 10), // 100 tokens, refill 10/sec
 		symbolCache: make(map[string]SymbolMetadata),
+		backfillPath: "data/ticks",
 	}
 }
 
+// SetAuthService sets the token validation admin endpoints require. Until
+// set, admin endpoints reject every request.
+func (h *HistoryHandler) SetAuthService(authService *auth.Service) {
+	h.authService = authService
+}
+
+// SetHistoryFetcher sets the LP history source fetch backfills pull from
+func (h *HistoryHandler) SetHistoryFetcher(fetch HistoryFetcher) {
+	h.historyFetcher = fetch
+}
+
 // RegisterRoutes registers all history API routes with standard http.ServeMux
 func (h *HistoryHandler) RegisterRoutes(mux *http.ServeMux) {
 	// Public endpoints
-	mux.HandleFunc("/api/history/ticks/", h.handleCORS(h.rateLimitMiddleware(h.HandleGetTicks)))
-	mux.HandleFunc("/api/history/ticks", h.handleCORS(h.rateLimitMiddleware(h.HandleGetTicksQuery))) // Query param version
-	mux.HandleFunc("/api/history/ticks/bulk", h.handleCORS(h.rateLimitMiddleware(h.HandleBulkDownload)))
-	mux.HandleFunc("/api/history/available", h.handleCORS(h.HandleGetAvailable))
-	mux.HandleFunc("/api/history/symbols", h.handleCORS(h.HandleGetSymbols))
-	mux.HandleFunc("/api/history/info", h.handleCORS(h.HandleGetSymbolInfo)) // Symbol info endpoint
-
-	// Admin endpoints (require authentication in production)
-	mux.HandleFunc("/admin/history/backfill", h.handleCORS(h.HandleBackfill))
+	mux.HandleFunc("/api/history/ticks/", h.rateLimitMiddleware(h.HandleGetTicks))
+	mux.HandleFunc("/api/history/ticks", h.rateLimitMiddleware(h.HandleGetTicksQuery)) // Query param version
+	mux.HandleFunc("/api/history/ticks/bulk", h.rateLimitMiddleware(h.HandleBulkDownload))
+	mux.HandleFunc("/api/history/available", h.HandleGetAvailable)
+	mux.HandleFunc("/api/history/symbols", h.HandleGetSymbols)
+	mux.HandleFunc("/api/history/info", h.HandleGetSymbolInfo) // Symbol info endpoint
+
+	// Admin endpoints (require an admin bearer token)
+	mux.HandleFunc("/admin/history/backfill", h.HandleBackfill)
+	mux.HandleFunc("/admin/history/backfill/fetch", h.HandleFetchBackfill)
 }
 
-// handleCORS wraps a handler with CORS headers
-func (h *HistoryHandler) handleCORS(next http.HandlerFunc) http.HandlerFunc {
+// adminAuthMiddleware runs next only for a valid admin bearer token, as
+// checked by auth.Service.RequireRole, logging every refused attempt with its
+// source IP
+func (h *HistoryHandler) adminAuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Range")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Expose-Headers", "Content-Range, Accept-Ranges, Content-Encoding")
-
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
+		if h.authService == nil {
+			log.Printf("[HistoryAPI] Refused %s %s from %s: admin authentication not configured", r.Method, r.URL.Path, requestIP(r))
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 			return
 		}
 
-		next(w, r)
+		admitted := false
+		rec := &statusRecorder{ResponseWriter: w}
+		h.authService.RequireRole(auth.RoleAdmin, func(_ http.ResponseWriter, r *http.Request) {
+			admitted = true
+			next(w, r)
+		})(rec, r)
+		if !admitted {
+			log.Printf("[HistoryAPI] Refused %s %s from %s: %d %s", r.Method, r.URL.Path, requestIP(r), rec.status, http.StatusText(rec.status))
+		}
+	}
+}
+
+// statusRecorder captures the status of a refused request
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// requestIP returns the client address, preferring the first X-Forwarded-For hop
+func requestIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
 	}
+	return r.RemoteAddr
 }
 
 // rateLimitMiddleware applies rate limiting to endpoints
 func (h *HistoryHandler) rateLimitMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Use IP address as rate limit key
-		ip := r.RemoteAddr
-		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
-			ip = strings.Split(forwarded, ",")[0]
-		}
-
-		if !h.rateLimiter.Allow(ip) {
+		if !h.rateLimiter.Allow(requestIP(r)) {
 			w.Header().Set("Retry-After", "10")
 			http.Error(w, "Rate limit exceeded. Please try again later.", http.StatusTooManyRequests)
 			return
@@ -191,7 +311,8 @@ func (h *HistoryHandler) rateLimitMiddleware(next http.HandlerFunc) http.Handler
 }
 
 // HandleGetTicks handles GET /api/history/ticks/{symbol}
-// Query params: from, to, format (json/csv/binary), page, page_size
+// Query params: from, to, format (json/csv/binary), page, page_size (not csv),
+// resolution (e.g. 1s, 1m: the last tick of each bucket, before paging)
 func (h *HistoryHandler) HandleGetTicks(w http.ResponseWriter, r *http.Request) {
 	// Extract symbol from URL path: /api/history/ticks/EURUSD
 	parts := strings.Split(r.URL.Path, "/")
@@ -220,6 +341,17 @@ func (h *HistoryHandler) HandleGetTicks(w http.ResponseWriter, r *http.Request)
 		format = "json"
 	}
 
+	// Downsampling bucket, empty returns every tick
+	var resolution time.Duration
+	if resStr := r.URL.Query().Get("resolution"); resStr != "" {
+		parsed, err := time.ParseDuration(resStr)
+		if err != nil || parsed < time.Millisecond {
+			http.Error(w, "Invalid 'resolution'. Use a duration such as 1s or 1m", http.StatusBadRequest)
+			return
+		}
+		resolution = parsed
+	}
+
 	// Validate and sanitize page parameter
 	page, err := strconv.Atoi(r.URL.Query().Get("page"))
 	if err != nil || page < 1 || page > 100000 {
@@ -234,7 +366,6 @@ func (h *HistoryHandler) HandleGetTicks(w http.ResponseWriter, r *http.Request)
 
 	// Parse date range
 	var from, to time.Time
-	var err error
 
 	if fromStr != "" {
 		from, err = time.Parse(time.RFC3339, fromStr)
@@ -265,7 +396,7 @@ func (h *HistoryHandler) HandleGetTicks(w http.ResponseWriter, r *http.Request)
 	// Fetch ticks from storage
 	// For now, we'll get all ticks and filter/paginate in memory
 	// TODO: Optimize with database queries for better performance
-	allTicks := h.getTicksInRange(symbol, from, to, daysBack)
+	allTicks := h.getTicksInRange(symbol, from, to, daysBack, resolution)
 
 	// Apply pagination
 	totalCount := len(allTicks)
@@ -290,7 +421,9 @@ func (h *HistoryHandler) HandleGetTicks(w http.ResponseWriter, r *http.Request)
 	// Handle different formats
 	switch format {
 	case "csv":
-		h.respondCSV(w, symbol, pageTicks)
+		// Exports cover the whole range; paging applies to json and binary
+		pageTicks = allTicks
+		h.respondCSV(w, r, symbol, from, to, allTicks)
 	case "binary":
 		h.respondBinary(w, symbol, pageTicks)
 	default: // json
@@ -310,6 +443,9 @@ func (h *HistoryHandler) HandleGetTicks(w http.ResponseWriter, r *http.Request)
 			Ticks:      pageTicks,
 			Format:     format,
 		}
+		if resolution > 0 {
+			response.Resolution = resolution.String()
+		}
 
 		w.Header().Set("Content-Type", "application/json")
 
@@ -371,7 +507,7 @@ func (h *HistoryHandler) HandleBulkDownload(w http.ResponseWriter, r *http.Reque
 	totalCount := 0
 
 	for _, symbol := range req.Symbols {
-		ticks := h.getTicksInRange(symbol, req.From, req.To, daysBack)
+		ticks := h.getTicksInRange(symbol, req.From, req.To, daysBack, 0)
 		data[symbol] = ticks
 		totalCount += len(ticks)
 	}
@@ -462,7 +598,13 @@ func (h *HistoryHandler) HandleGetSymbols(w http.ResponseWriter, r *http.Request
 }
 
 // HandleBackfill handles POST /admin/history/backfill
+// Requires an admin bearer token.
 func (h *HistoryHandler) HandleBackfill(w http.ResponseWriter, r *http.Request) {
+	h.adminAuthMiddleware(h.handleBackfill)(w, r)
+}
+
+// handleBackfill merges the posted ticks into the daily store
+func (h *HistoryHandler) handleBackfill(w http.ResponseWriter, r *http.Request) {
 	var req BackfillRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -487,38 +629,117 @@ func (h *HistoryHandler) HandleBackfill(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Get DailyStore from TickStore (if available)
-	if ts, ok := h.tickStore.(*tickstore.TickStore); ok {
-		dailyStore := ts.GetDailyStore()
+	if err := h.mergeBackfill(req.Symbol, req.Ticks); err != nil {
+		http.Error(w, fmt.Sprintf("Backfill failed: %v", err), http.StatusInternalServerError)
+		return
+	}
 
-		if err := dailyStore.MergeHistoricalData(req.Symbol, req.Ticks); err != nil {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"symbol":  req.Symbol,
+		"count":   len(req.Ticks),
+		"source":  req.Source,
+		"message": fmt.Sprintf("Successfully backfilled %d ticks for %s", len(req.Ticks), req.Symbol),
+	})
+
+	log.Printf("[HistoryAPI] POST /admin/history/backfill: backfilled %d ticks for %s from %s",
+		len(req.Ticks), req.Symbol, req.Source)
+}
+
+// HandleFetchBackfill handles POST /admin/history/backfill/fetch: ticks for a
+// symbol and range are fetched from the LP history source and backfilled.
+// Requires an admin bearer token.
+func (h *HistoryHandler) HandleFetchBackfill(w http.ResponseWriter, r *http.Request) {
+	h.adminAuthMiddleware(h.handleFetchBackfill)(w, r)
+}
+
+// handleFetchBackfill fetches and merges LP history
+func (h *HistoryHandler) handleFetchBackfill(w http.ResponseWriter, r *http.Request) {
+	var req FetchBackfillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !isValidSymbol(req.Symbol) {
+		http.Error(w, "Invalid symbol format", http.StatusBadRequest)
+		log.Printf("[HistoryAPI] Invalid symbol attempt in fetch backfill: %s", req.Symbol)
+		return
+	}
+	if req.Granularity == "" {
+		req.Granularity = "M1"
+	}
+	if req.To.IsZero() {
+		req.To = time.Now()
+	}
+	if req.From.IsZero() || !req.From.Before(req.To) {
+		http.Error(w, "'from' is required and must be before 'to'", http.StatusBadRequest)
+		return
+	}
+	if req.To.Sub(req.From) > maxFetchBackfillRange {
+		http.Error(w, fmt.Sprintf("Range exceeds %d days; split the backfill", int(maxFetchBackfillRange.Hours()/24)), http.StatusBadRequest)
+		return
+	}
+	if h.historyFetcher == nil {
+		http.Error(w, "No LP history source configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	ticks, err := h.historyFetcher(req.Symbol, req.Granularity, req.From, req.To)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("History fetch failed: %v", err), http.StatusBadGateway)
+		return
+	}
+	if len(ticks) > 0 {
+		if err := h.mergeBackfill(req.Symbol, ticks); err != nil {
 			http.Error(w, fmt.Sprintf("Backfill failed: %v", err), http.StatusInternalServerError)
 			return
 		}
+	}
 
-		// Invalidate cache for this symbol
-		h.symbolMu.Lock()
-		delete(h.symbolCache, req.Symbol)
-		h.symbolMu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"symbol":      req.Symbol,
+		"granularity": req.Granularity,
+		"from":        req.From,
+		"to":          req.To,
+		"count":       len(ticks),
+	})
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": true,
-			"symbol":  req.Symbol,
-			"count":   len(req.Ticks),
-			"source":  req.Source,
-			"message": fmt.Sprintf("Successfully backfilled %d ticks for %s", len(req.Ticks), req.Symbol),
-		})
-
-		log.Printf("[HistoryAPI] POST /admin/history/backfill: backfilled %d ticks for %s from %s",
-			len(req.Ticks), req.Symbol, req.Source)
+	log.Printf("[HistoryAPI] POST /admin/history/backfill/fetch: backfilled %d %s candles for %s",
+		len(ticks), req.Granularity, req.Symbol)
+}
+
+// mergeBackfill merges ticks into the daily store, or straight into the daily
+// files when the running store keeps none
+func (h *HistoryHandler) mergeBackfill(symbol string, ticks []tickstore.Tick) error {
+	var err error
+	if ts, ok := h.tickStore.(*tickstore.TickStore); ok {
+		err = ts.GetDailyStore().MergeHistoricalData(symbol, ticks)
 	} else {
-		http.Error(w, "Backfill not supported with current storage implementation", http.StatusNotImplemented)
+		err = tickstore.MergeDailyFiles(h.backfillPath, symbol, ticks)
 	}
+	if err != nil {
+		return err
+	}
+
+	// Invalidate cache for this symbol
+	h.symbolMu.Lock()
+	delete(h.symbolCache, symbol)
+	h.symbolMu.Unlock()
+	return nil
+}
+
+// Helper: getTicksInRange fetches ticks in a date range, downsampled to the
+// last tick of each resolution bucket when resolution is set
+func (h *HistoryHandler) getTicksInRange(symbol string, from, to time.Time, daysBack int, resolution time.Duration) []tickstore.Tick {
+	return downsampleTicks(h.ticksInRange(symbol, from, to, daysBack), resolution)
 }
 
-// Helper: getTicksInRange fetches ticks in a date range
-func (h *HistoryHandler) getTicksInRange(symbol string, from, to time.Time, daysBack int) []tickstore.Tick {
+// Helper: ticksInRange fetches every tick in a date range
+func (h *HistoryHandler) ticksInRange(symbol string, from, to time.Time, daysBack int) []tickstore.Tick {
 	// Try to get from DailyStore if available
 	if ts, ok := h.tickStore.(*tickstore.TickStore); ok {
 		dailyStore := ts.GetDailyStore()
@@ -557,23 +778,13 @@ func (h *HistoryHandler) getSymbolMetadata(symbol string) SymbolMetadata {
 		LastUpdated: time.Now(),
 	}
 
-	// Get tick data to compute metadata
+	// Read the daily store's index rather than the tick files
 	if ts, ok := h.tickStore.(*tickstore.TickStore); ok {
-		dailyStore := ts.GetDailyStore()
-		dates := dailyStore.GetAvailableDates(symbol)
-		metadata.AvailableDays = len(dates)
-
-		if len(dates) > 0 {
-			// Get earliest and latest ticks
-			earliestTicks := dailyStore.GetHistory(symbol, 1, 365) // Look back 1 year
-			if len(earliestTicks) > 0 {
-				metadata.EarliestTick = earliestTicks[0].Timestamp
-				metadata.LatestTick = earliestTicks[len(earliestTicks)-1].Timestamp
-			}
-
-			// Estimate total ticks (rough)
-			metadata.TotalTicks = int64(len(earliestTicks))
-		}
+		index := ts.GetDailyStore().GetSymbolIndex(symbol)
+		metadata.EarliestTick = index.Earliest
+		metadata.LatestTick = index.Latest
+		metadata.TotalTicks = index.TotalTicks
+		metadata.AvailableDays = index.Days
 	} else {
 		ticks := h.tickStore.GetHistory(symbol, 0)
 		metadata.TotalTicks = int64(len(ticks))
@@ -611,40 +822,82 @@ func (h *HistoryHandler) categorizeSymbol(symbol string) string {
 	return "other"
 }
 
-// Helper: respondCSV responds with CSV format
-func (h *HistoryHandler) respondCSV(w http.ResponseWriter, symbol string, ticks []tickstore.Tick) {
+// tickCSVHeader is the header row of CSV tick exports. Timestamps are RFC3339
+// in UTC; bid_size and ask_size are empty while tick storage keeps no sizes.
+var tickCSVHeader = []string{"timestamp", "bid", "ask", "bid_size", "ask_size", "lp"}
+
+// Helper: respondCSV streams ticks as CSV, gzipped if the client accepts it
+func (h *HistoryHandler) respondCSV(w http.ResponseWriter, r *http.Request, symbol string, from, to time.Time, ticks []tickstore.Tick) {
 	w.Header().Set("Content-Type", "text/csv")
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s_ticks.csv\"", symbol))
+	filename := fmt.Sprintf("%s_%s_%s_ticks.csv", symbol, from.Format("2006-01-02"), to.Format("2006-01-02"))
+
+	var out io.Writer = w
+	if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		out = gz
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	if err := writeTicksCSV(out, ticks); err != nil {
+		log.Printf("[HistoryAPI] CSV export failed for %s: %v", symbol, err)
+	}
+}
 
+// Helper: writeTicksCSV writes the header row and one row per tick, flushing
+// as it goes so exports are not held in memory
+func writeTicksCSV(w io.Writer, ticks []tickstore.Tick) error {
 	writer := csv.NewWriter(w)
-	defer writer.Flush()
-
-	// Header
-	writer.Write([]string{"Timestamp", "Symbol", "Bid", "Ask", "Spread", "LP"})
-
-	// Data
-	for _, tick := range ticks {
-		writer.Write([]string{
-			tick.Timestamp.Format(time.RFC3339),
-			tick.Symbol,
-			fmt.Sprintf("%.5f", tick.Bid),
-			fmt.Sprintf("%.5f", tick.Ask),
-			fmt.Sprintf("%.5f", tick.Spread),
+	if err := writer.Write(tickCSVHeader); err != nil {
+		return err
+	}
+
+	for i, tick := range ticks {
+		if err := writer.Write([]string{
+			tick.Timestamp.UTC().Format(time.RFC3339Nano),
+			strconv.FormatFloat(tick.Bid, 'f', -1, 64),
+			strconv.FormatFloat(tick.Ask, 'f', -1, 64),
+			"",
+			"",
 			tick.LP,
-		})
+		}); err != nil {
+			return err
+		}
+		if i%1000 == 999 {
+			writer.Flush()
+		}
 	}
+	writer.Flush()
+	return writer.Error()
 }
 
-// Helper: respondBinary responds with binary format (custom protocol)
+// Helper: respondBinary streams ticks in the binary tick format (see tick_binary.go)
 func (h *HistoryHandler) respondBinary(w http.ResponseWriter, symbol string, ticks []tickstore.Tick) {
 	w.Header().Set("Content-Type", "application/octet-stream")
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s_ticks.bin\"", symbol))
 
-	// Simple binary format: each tick is 40 bytes
-	// 8 bytes timestamp (Unix nano), 8 bytes bid, 8 bytes ask, 8 bytes spread, 8 bytes reserved
-	// For simplicity, we'll use JSON encoding for now
-	// TODO: Implement efficient binary protocol
-	json.NewEncoder(w).Encode(ticks)
+	if err := EncodeTicksBinary(w, symbol, ticks); err != nil {
+		log.Printf("[HistoryAPI] Binary encode failed for %s: %v", symbol, err)
+	}
+}
+
+// Helper: downsampleTicks keeps the last tick of each resolution-wide time
+// bucket; ticks must be oldest first. A zero resolution keeps every tick.
+func downsampleTicks(ticks []tickstore.Tick, resolution time.Duration) []tickstore.Tick {
+	if resolution <= 0 || len(ticks) == 0 {
+		return ticks
+	}
+
+	sampled := make([]tickstore.Tick, 0, len(ticks))
+	for i, tick := range ticks {
+		bucket := tick.Timestamp.Truncate(resolution)
+		if i+1 < len(ticks) && ticks[i+1].Timestamp.Truncate(resolution).Equal(bucket) {
+			continue
+		}
+		sampled = append(sampled, tick)
+	}
+	return sampled
 }
 
 // Helper: min returns the minimum of two integers
@@ -747,7 +1000,7 @@ func (h *HistoryHandler) HandleGetTicksQuery(w http.ResponseWriter, r *http.Requ
 	if _, ok := h.tickStore.(*tickstore.TickStore); ok {
 		// Get ticks for the specific date (1 day back from target date end)
 		endDate := targetDate.AddDate(0, 0, 1)
-		allTicks = h.getTicksInRange(symbol, targetDate, endDate, 1)
+		allTicks = h.getTicksInRange(symbol, targetDate, endDate, 1, 0)
 	} else {
 		allTicks = h.tickStore.GetHistory(symbol, limit+offset)
 	}
@@ -0,0 +1,267 @@
+package api
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/auth"
+	"github.com/epic1st/rtx/backend/internal/core"
+	"github.com/epic1st/rtx/backend/tickstore"
+)
+
+// fakeTickStore serves a fixed tick history
+type fakeTickStore struct {
+	ticks []tickstore.Tick
+}
+
+func (f *fakeTickStore) StoreTick(symbol string, bid, ask, spread float64, lp string, timestamp time.Time) {
+}
+func (f *fakeTickStore) GetHistory(symbol string, limit int) []tickstore.Tick { return f.ticks }
+func (f *fakeTickStore) GetOHLC(symbol string, timeframeSecs int64, limit int) []tickstore.OHLC {
+	return nil
+}
+func (f *fakeTickStore) GetSymbols() []string           { return []string{"EURUSD"} }
+func (f *fakeTickStore) GetTickCount(symbol string) int { return len(f.ticks) }
+
+// hourOfTicks returns a tick every second for an hour from start
+func hourOfTicks(start time.Time) []tickstore.Tick {
+	ticks := make([]tickstore.Tick, 3600)
+	for i := range ticks {
+		bid := 1.1 + float64(i)*0.000001
+		ticks[i] = tickstore.Tick{Symbol: "EURUSD", Bid: bid, Ask: bid + 0.0001, Timestamp: start.Add(time.Duration(i) * time.Second)}
+	}
+	return ticks
+}
+
+// TestDownsampleOneHourAtOneMinute tests that an hour of ticks at 1m
+// resolution is one point per minute, each the last tick of its minute
+func TestDownsampleOneHourAtOneMinute(t *testing.T) {
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	h := NewHistoryHandler(&fakeTickStore{ticks: hourOfTicks(start)})
+
+	ticks := h.getTicksInRange("EURUSD", start, start.Add(time.Hour), 1, time.Minute)
+	if len(ticks) == 0 || len(ticks) > 61 {
+		t.Fatalf("got %d points, want at most ~60", len(ticks))
+	}
+	if want := start.Add(59 * time.Second); !ticks[0].Timestamp.Equal(want) {
+		t.Errorf("first point at %v, want the minute's last tick at %v", ticks[0].Timestamp, want)
+	}
+
+	if raw := h.getTicksInRange("EURUSD", start, start.Add(time.Hour), 1, 0); len(raw) != 3600 {
+		t.Errorf("without resolution got %d ticks, want all 3600", len(raw))
+	}
+}
+
+// TestHandleGetTicksResolution tests that resolution applies before paging
+// and invalid resolutions are rejected
+func TestHandleGetTicksResolution(t *testing.T) {
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	h := NewHistoryHandler(&fakeTickStore{ticks: hourOfTicks(start)})
+
+	rec := httptest.NewRecorder()
+	h.HandleGetTicks(rec, httptest.NewRequest("GET", "/api/history/ticks/EURUSD?resolution=1m&page_size=50", nil))
+	var resp TicksResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.TotalCount != 60 || resp.Count != 50 || !resp.HasMore || resp.Resolution != "1m0s" {
+		t.Errorf("response = %d of %d (more %v, resolution %q), want 50 of 60 at 1m0s", resp.Count, resp.TotalCount, resp.HasMore, resp.Resolution)
+	}
+
+	rec = httptest.NewRecorder()
+	h.HandleGetTicks(rec, httptest.NewRequest("GET", "/api/history/ticks/EURUSD?resolution=fast", nil))
+	if rec.Code != 400 {
+		t.Errorf("invalid resolution status = %d, want 400", rec.Code)
+	}
+}
+
+// TestHandleGetTicksInvalidFrom tests that a malformed from date is rejected
+func TestHandleGetTicksInvalidFrom(t *testing.T) {
+	h := NewHistoryHandler(&fakeTickStore{})
+
+	rec := httptest.NewRecorder()
+	h.HandleGetTicks(rec, httptest.NewRequest("GET", "/api/history/ticks/EURUSD?from=2026-13-01", nil))
+	if rec.Code != 400 {
+		t.Errorf("status = %d, want 400 for an invalid from date", rec.Code)
+	}
+}
+
+// TestTicksCSVExport tests the CSV header row, a known tick's line and the
+// gzipped download's headers
+func TestTicksCSVExport(t *testing.T) {
+	tick := tickstore.Tick{Symbol: "EURUSD", Bid: 1.08245, Ask: 1.08255, LP: "YOFX",
+		Timestamp: time.Date(2026, 1, 13, 0, 0, 1, 250e6, time.UTC)}
+	want := "timestamp,bid,ask,bid_size,ask_size,lp\n2026-01-13T00:00:01.25Z,1.08245,1.08255,,,YOFX\n"
+
+	var buf strings.Builder
+	if err := writeTicksCSV(&buf, []tickstore.Tick{tick}); err != nil {
+		t.Fatalf("writeTicksCSV() error = %v", err)
+	}
+	if buf.String() != want {
+		t.Errorf("CSV = %q, want %q", buf.String(), want)
+	}
+
+	h := NewHistoryHandler(&fakeTickStore{ticks: []tickstore.Tick{tick}})
+	req := httptest.NewRequest("GET", "/api/history/ticks/EURUSD?format=csv&from=2026-01-13T00:00:00Z&to=2026-01-14T00:00:00Z", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.HandleGetTicks(rec, req)
+
+	if cd := rec.Header().Get("Content-Disposition"); cd != `attachment; filename="EURUSD_2026-01-13_2026-01-14_ticks.csv"` {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	if rec.Header().Get("Content-Encoding") != "gzip" || rec.Header().Get("Content-Type") != "text/csv" {
+		t.Errorf("headers = %v, want gzipped text/csv", rec.Header())
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	if body, _ := io.ReadAll(zr); string(body) != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
+
+// TestBackfillRequiresAdmin tests that backfill refuses requests without an
+// admin token and accepts a valid one
+func TestBackfillRequiresAdmin(t *testing.T) {
+	authService := auth.NewService(core.NewEngine(), "", "history-test-secret")
+	adminToken, _ := authService.GenerateToken(&auth.User{ID: "0", Username: "admin", Role: auth.RoleAdmin})
+	traderToken, _ := authService.GenerateToken(&auth.User{ID: "1", Username: "trader1", Role: auth.RoleTrader})
+
+	// Backfill writes through the daily store under data/ticks
+	t.Chdir(t.TempDir())
+	store := tickstore.NewTickStore("test", 100)
+	h := NewHistoryHandler(store)
+	h.SetAuthService(authService)
+	body := `{"symbol":"EURUSD","ticks":[{"symbol":"EURUSD","bid":1.1,"ask":1.1002,"timestamp":"2026-01-13T12:00:00Z"}]}`
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"no token", "", 401},
+		{"invalid token", "not.a.jwt", 401},
+		{"trader token", traderToken, 403},
+		{"admin token", adminToken, 200},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/admin/history/backfill", strings.NewReader(body))
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		rec := httptest.NewRecorder()
+		h.HandleBackfill(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+	if dates := store.GetDailyStore().GetAvailableDates("EURUSD"); len(dates) != 1 || dates[0] != "2026-01-13" {
+		t.Errorf("backfilled dates = %v, want only the admin's 2026-01-13", dates)
+	}
+
+	// Without an auth service every request is refused
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/admin/history/backfill", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	NewHistoryHandler(&fakeTickStore{}).HandleBackfill(rec, req)
+	if rec.Code != 401 {
+		t.Errorf("unconfigured auth status = %d, want 401", rec.Code)
+	}
+}
+
+// TestRateLimiterDropsIdleKeys tests that keys of many one-off clients are
+// removed once idle past the window, while recently seen clients stay
+func TestRateLimiterDropsIdleKeys(t *testing.T) {
+	rl := NewRateLimiter(100, 10)
+	defer rl.Stop()
+
+	for i := 0; i < 5000; i++ {
+		rl.Allow(fmt.Sprintf("203.0.113.%d:%d", i%256, i))
+	}
+	if rl.Size() != 5000 {
+		t.Fatalf("tracked clients = %d, want 5000", rl.Size())
+	}
+
+	if removed := rl.removeIdle(time.Now().Add(rateLimitIdleTTL/2), rateLimitIdleTTL); removed != 0 {
+		t.Errorf("removed %d clients before the idle window, want 0", removed)
+	}
+
+	later := time.Now().Add(rateLimitIdleTTL + time.Minute)
+	rl.mu.Lock()
+	rl.lastRefill["198.51.100.7:1"] = later // Active client
+	rl.tokens["198.51.100.7:1"] = 99
+	rl.mu.Unlock()
+
+	if removed := rl.removeIdle(later, rateLimitIdleTTL); removed != 5000 || rl.Size() != 1 {
+		t.Errorf("removed %d, %d left; want 5000 removed and the active client kept", removed, rl.Size())
+	}
+}
+
+// TestFetchBackfill tests that fetched LP history is merged into the daily
+// files and fetch failures and oversized ranges are refused
+func TestFetchBackfill(t *testing.T) {
+	authService := auth.NewService(core.NewEngine(), "", "history-test-secret")
+	adminToken, _ := authService.GenerateToken(&auth.User{ID: "0", Username: "admin", Role: auth.RoleAdmin})
+
+	h := NewHistoryHandler(&fakeTickStore{})
+	h.SetAuthService(authService)
+	h.backfillPath = t.TempDir()
+	h.SetHistoryFetcher(func(symbol, granularity string, from, to time.Time) ([]tickstore.Tick, error) {
+		if granularity != "M1" {
+			return nil, fmt.Errorf("unsupported granularity %q", granularity)
+		}
+		return []tickstore.Tick{
+			{Symbol: symbol, Bid: 1.1, Ask: 1.1002, Timestamp: from, LP: "OANDA"},
+			{Symbol: symbol, Bid: 1.1001, Ask: 1.1003, Timestamp: from.Add(time.Minute), LP: "OANDA"},
+		}, nil
+	})
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/admin/history/backfill/fetch", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		rec := httptest.NewRecorder()
+		h.HandleFetchBackfill(rec, req)
+		return rec
+	}
+
+	rec := post(`{"symbol":"EURUSD","from":"2026-01-13T12:00:00Z","to":"2026-01-13T13:00:00Z"}`)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d (%s), want 200", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Count int `json:"count"`
+	}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Count != 2 {
+		t.Errorf("count = %d, want 2", resp.Count)
+	}
+	if _, err := os.Stat(filepath.Join(h.backfillPath, "EURUSD", time.Date(2026, 1, 13, 12, 0, 0, 0, time.UTC).Local().Format("2006-01-02")+".json")); err != nil {
+		t.Errorf("backfilled day file missing: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"fetch failure", `{"symbol":"EURUSD","granularity":"H1","from":"2026-01-13T12:00:00Z","to":"2026-01-13T13:00:00Z"}`, 502},
+		{"range too long", `{"symbol":"EURUSD","from":"2025-01-01T00:00:00Z","to":"2026-01-01T00:00:00Z"}`, 400},
+		{"from after to", `{"symbol":"EURUSD","from":"2026-01-13T13:00:00Z","to":"2026-01-13T12:00:00Z"}`, 400},
+		{"invalid symbol", `{"symbol":"../etc","from":"2026-01-13T12:00:00Z","to":"2026-01-13T13:00:00Z"}`, 400},
+	}
+	for _, tt := range tests {
+		if rec := post(tt.body); rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/epic1st/rtx/backend/internal/api/handlers"
+	"github.com/epic1st/rtx/backend/orders"
+)
+
+// SetOrderRules sets the group placement rules resolver and the pip size lookup
+// used to turn default SL/TP distances into prices
+func (s *Server) SetOrderRules(resolver orders.OrderRulesResolver, pipSize func(symbol string) float64) {
+	s.orderRules = resolver
+	s.pipSize = pipSize
+}
+
+// applyOrderRules enforces the placement rules of the caller's group, resolved
+// from the auth token. Returns false after writing the rejection if refused.
+func (s *Server) applyOrderRules(w http.ResponseWriter, r *http.Request, symbol string, p *orders.Placement) bool {
+	if s.orderRules == nil || s.authService == nil {
+		return true
+	}
+
+	accountID, ok := s.authService.AccountIDFromRequest(r)
+	if !ok {
+		return true
+	}
+	rules := s.orderRules(accountID)
+	if rules == nil {
+		return true
+	}
+
+	// Market orders are anchored to the current quote
+	if p.Price <= 0 && s.hub != nil {
+		if tick := s.hub.GetLatestPrice(symbol); tick != nil {
+			p.Price = tick.Ask
+			if p.Side == orders.OrderSideSell {
+				p.Price = tick.Bid
+			}
+		}
+	}
+
+	pipSize := 0.0
+	if s.pipSize != nil {
+		pipSize = s.pipSize(symbol)
+	}
+
+	if err := rules.Apply(p, pipSize); err != nil {
+		handlers.RespondOrderRejection(w, err)
+		return false
+	}
+	return true
+}
@@ -2,16 +2,19 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/epic1st/rtx/backend/abook"
 	"github.com/epic1st/rtx/backend/auth"
 	"github.com/epic1st/rtx/backend/fix"
 	"github.com/epic1st/rtx/backend/internal/api/handlers"
+	"github.com/epic1st/rtx/backend/internal/core"
 	"github.com/epic1st/rtx/backend/lpmanager"
 	"github.com/epic1st/rtx/backend/oms"
 	"github.com/epic1st/rtx/backend/orders"
@@ -36,6 +39,10 @@ type Server struct {
 	trailingService *orders.TrailingStopService
 	riskCalculator  *risk.RiskCalculator
 
+	// Group placement rules
+	orderRules orders.OrderRulesResolver
+	pipSize    func(symbol string) float64
+
 	// A-Book execution
 	abookEngine     *abook.ExecutionEngine
 	abookHandler    *handlers.ABookHandler
@@ -47,6 +54,8 @@ func NewServer(authService *auth.Service, bbookAPI *handlers.APIHandler, lpMgr *
 
 	// Initialize A-Book execution engine
 	abookEngine := abook.NewExecutionEngine(fixGateway, lpMgr, riskEngine)
+	// Sweeps take the sizes the FIX sessions quote
+	abookEngine.SetDepthProvider(abook.GatewayDepth(fixGateway))
 	abookHandler := handlers.NewABookHandler(abookEngine)
 
 	return &Server{
@@ -57,7 +66,7 @@ func NewServer(authService *auth.Service, bbookAPI *handlers.APIHandler, lpMgr *
 		smartRouter:     router.NewSmartRouter(),
 		fixGateway:      fixGateway,
 		orderService:    orders.NewOrderService(),
-		positionManager: orders.NewPositionManager(true), // Hedging mode
+		positionManager: orders.NewPositionManager(true), // Hedging until SetMarginMode applies the broker's
 		trailingService: orders.NewTrailingStopService(),
 		riskCalculator:  risk.NewRiskCalculator(),
 		abookEngine:     abookEngine,
@@ -78,6 +87,12 @@ func (s *Server) GetOrderService() *orders.OrderService {
 	return s.orderService
 }
 
+// SetMarginMode sets the LP position manager to the broker's HEDGING or
+// NETTING margin mode
+func (s *Server) SetMarginMode(mode string) {
+	s.positionManager.SetHedgingMode(!strings.EqualFold(mode, core.MarginModeNetting))
+}
+
 // GetPositionManager returns the position manager
 func (s *Server) GetPositionManager() *orders.PositionManager {
 	return s.positionManager
@@ -88,6 +103,11 @@ func (s *Server) GetTrailingService() *orders.TrailingStopService {
 	return s.trailingService
 }
 
+// GetRiskEngine returns the risk engine used for pre-trade checks
+func (s *Server) GetRiskEngine() *risk.Engine {
+	return s.riskEngine
+}
+
 // GetRiskCalculator returns the risk calculator
 func (s *Server) GetRiskCalculator() *risk.RiskCalculator {
 	return s.riskCalculator
@@ -98,10 +118,12 @@ func (s *Server) GetFIXGateway() *fix.FIXGateway {
 	return s.fixGateway
 }
 
-func (s *Server) HandleLogin(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+// GetABookEngine returns the A-Book execution engine
+func (s *Server) GetABookEngine() *abook.ExecutionEngine {
+	return s.abookEngine
+}
 
+func (s *Server) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -135,10 +157,65 @@ func (s *Server) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
-func (s *Server) HandlePlaceOrder(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+// HandleRefreshToken exchanges a valid, unexpired bearer token for a new one
+// with a fresh expiry. The token may also be sent as {"token": "..."}.
+func (s *Server) HandleRefreshToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := ""
+	if parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2); len(parts) == 2 && parts[0] == "Bearer" {
+		token = parts[1]
+	} else {
+		var req struct {
+			Token string `json:"token"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		token = req.Token
+	}
+	if token == "" {
+		http.Error(w, "Missing token", http.StatusUnauthorized)
+		return
+	}
+
+	newToken, user, err := s.authService.RefreshToken(token)
+	if errors.Is(err, auth.ErrTokenExpired) {
+		http.Error(w, "Token expired", http.StatusUnauthorized)
+		return
+	}
+	if errors.Is(err, auth.ErrAccountInactive) {
+		http.Error(w, "Account is not active", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
 
+	resp := struct {
+		Token     string     `json:"token"`
+		User      *auth.User `json:"user"`
+		ExpiresIn int64      `json:"expiresIn"` // Seconds
+	}{
+		Token:     newToken,
+		User:      user,
+		ExpiresIn: int64(s.authService.TokenTTL().Seconds()),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *Server) HandlePlaceOrder(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -170,6 +247,18 @@ func (s *Server) HandlePlaceOrder(w http.ResponseWriter, r *http.Request) {
 		req.AccountID = "demo_001"
 	}
 
+	placement := &orders.Placement{
+		Type:  orders.OrderType(strings.ToUpper(req.Type)),
+		Side:  orders.OrderSide(strings.ToUpper(req.Side)),
+		Price: req.Price,
+		SL:    req.SL,
+		TP:    req.TP,
+	}
+	if !s.applyOrderRules(w, r, req.Symbol, placement) {
+		return
+	}
+	req.SL, req.TP = placement.SL, placement.TP
+
 	log.Printf("[A-Book] Executing %s %s %.2f lots %s via LP",
 		req.Side, req.Symbol, req.Volume, req.Type)
 
@@ -192,6 +281,16 @@ func (s *Server) HandlePlaceOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Give the LP a moment to reject so the client sees why
+	outcome, err := s.abookEngine.WaitForOrder(order.ID, abookOutcomeWait)
+	if err != nil {
+		// outcome is nil when the order is no longer known
+		log.Printf("[A-Book] Order %s: %v", order.ClientOrderID, err)
+		respondOrderRejected(w, err)
+		return
+	}
+	order = outcome
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
@@ -200,23 +299,48 @@ func (s *Server) HandlePlaceOrder(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// abookOutcomeWait is how long HandlePlaceOrder waits for the LP to accept or
+// reject an order before answering with the order as sent
+const abookOutcomeWait = 2 * time.Second
+
+// respondOrderRejected writes an LP reject as 422 with the LP's reason, and
+// any other error as 400
+func respondOrderRejected(w http.ResponseWriter, err error) {
+	var rejectErr *abook.RejectError
+	if !errors.As(err, &rejectErr) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error":   rejectErr.Error(),
+		"reason":  rejectErr.Text,
+		"code":    rejectErr.Code,
+		"orderId": rejectErr.OrderID,
+	})
+}
+
 // HandlePlaceLimitOrder handles limit order placement
 func (s *Server) HandlePlaceLimitOrder(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
 	var req struct {
-		Symbol string  `json:"symbol"`
-		Side   string  `json:"side"`
-		Volume float64 `json:"volume"`
-		Price  float64 `json:"price"`
-		SL     float64 `json:"sl,omitempty"`
-		TP     float64 `json:"tp,omitempty"`
+		Symbol string     `json:"symbol"`
+		Side   string     `json:"side"`
+		Volume float64    `json:"volume"`
+		Price  float64    `json:"price"`
+		SL     float64    `json:"sl,omitempty"`
+		TP     float64    `json:"tp,omitempty"`
+		TIF    string     `json:"timeInForce,omitempty"` // GTC (default), DAY or GTD
+		Expiry *time.Time `json:"expiry,omitempty"`      // Required for GTD
+		// Run the pre-trade checks and routing without placing the order
+		ValidateOnly bool `json:"validateOnly,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -229,7 +353,33 @@ func (s *Server) HandlePlaceLimitOrder(w http.ResponseWriter, r *http.Request) {
 		side = orders.OrderSideSell
 	}
 
-	order, err := s.orderService.PlaceLimitOrder(req.Symbol, side, req.Volume, req.Price, req.SL, req.TP)
+	placement := &orders.Placement{Type: orders.OrderTypeLimit, Side: side, Price: req.Price, SL: req.SL, TP: req.TP, TimeInForce: req.TIF}
+	if !s.applyOrderRules(w, r, req.Symbol, placement) {
+		return
+	}
+	if _, err := orders.ValidateTimeInForce(placement.TimeInForce, req.Expiry, time.Now()); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.ValidateOnly {
+		if s.bbookAPI == nil {
+			http.Error(w, "Order validation not available", http.StatusServiceUnavailable)
+			return
+		}
+		accountID, ok := s.requestAccountID(w, r)
+		if !ok {
+			return
+		}
+		handlers.RespondOrderValidation(w, s.bbookAPI.ValidateOrder(accountID, req.Symbol, string(side), req.Volume, req.Price))
+		return
+	}
+
+	opts, ok := s.placeOptions(w, r, placement, req.Expiry)
+	if !ok {
+		return
+	}
+	order, err := s.orderService.PlaceLimitOrder(req.Symbol, side, req.Volume, req.Price, placement.SL, placement.TP, opts)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -239,23 +389,49 @@ func (s *Server) HandlePlaceLimitOrder(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(order)
 }
 
+// requestAccountID returns the trading account of the request's trader
+// token. Without one it writes 401 and returns false: pending orders are
+// never placed on a default account.
+func (s *Server) requestAccountID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if ok && claims.Role == auth.RoleTrader {
+		if id, err := strconv.ParseInt(claims.UserID, 10, 64); err == nil {
+			return id, true
+		}
+	}
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	return 0, false
+}
+
+// isAdminRequest reports whether the request carries an admin token
+func (s *Server) isAdminRequest(r *http.Request) bool {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	return ok && claims.Role == auth.RoleAdmin
+}
+
+// placeOptions returns the account and time-in-force a pending order is
+// placed with, or writes 401 and returns false
+func (s *Server) placeOptions(w http.ResponseWriter, r *http.Request, placement *orders.Placement, expiry *time.Time) (orders.PlaceOptions, bool) {
+	accountID, ok := s.requestAccountID(w, r)
+	return orders.PlaceOptions{AccountID: accountID, TimeInForce: placement.TimeInForce, Expiry: expiry}, ok
+}
+
 // HandlePlaceStopOrder handles stop order placement
 func (s *Server) HandlePlaceStopOrder(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
 	var req struct {
-		Symbol       string  `json:"symbol"`
-		Side         string  `json:"side"`
-		Volume       float64 `json:"volume"`
-		TriggerPrice float64 `json:"triggerPrice"`
-		SL           float64 `json:"sl,omitempty"`
-		TP           float64 `json:"tp,omitempty"`
+		Symbol       string     `json:"symbol"`
+		Side         string     `json:"side"`
+		Volume       float64    `json:"volume"`
+		TriggerPrice float64    `json:"triggerPrice"`
+		SL           float64    `json:"sl,omitempty"`
+		TP           float64    `json:"tp,omitempty"`
+		TIF          string     `json:"timeInForce,omitempty"` // GTC (default), DAY or GTD
+		Expiry       *time.Time `json:"expiry,omitempty"`      // Required for GTD
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -268,7 +444,20 @@ func (s *Server) HandlePlaceStopOrder(w http.ResponseWriter, r *http.Request) {
 		side = orders.OrderSideSell
 	}
 
-	order, err := s.orderService.PlaceStopOrder(req.Symbol, side, req.Volume, req.TriggerPrice, req.SL, req.TP)
+	placement := &orders.Placement{Type: orders.OrderTypeStop, Side: side, Price: req.TriggerPrice, SL: req.SL, TP: req.TP, TimeInForce: req.TIF}
+	if !s.applyOrderRules(w, r, req.Symbol, placement) {
+		return
+	}
+	if _, err := orders.ValidateTimeInForce(placement.TimeInForce, req.Expiry, time.Now()); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opts, ok := s.placeOptions(w, r, placement, req.Expiry)
+	if !ok {
+		return
+	}
+	order, err := s.orderService.PlaceStopOrder(req.Symbol, side, req.Volume, req.TriggerPrice, placement.SL, placement.TP, opts)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -280,22 +469,21 @@ func (s *Server) HandlePlaceStopOrder(w http.ResponseWriter, r *http.Request) {
 
 // HandlePlaceStopLimitOrder handles stop-limit order placement
 func (s *Server) HandlePlaceStopLimitOrder(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
 	var req struct {
-		Symbol       string  `json:"symbol"`
-		Side         string  `json:"side"`
-		Volume       float64 `json:"volume"`
-		TriggerPrice float64 `json:"triggerPrice"`
-		LimitPrice   float64 `json:"limitPrice"`
-		SL           float64 `json:"sl,omitempty"`
-		TP           float64 `json:"tp,omitempty"`
+		Symbol       string     `json:"symbol"`
+		Side         string     `json:"side"`
+		Volume       float64    `json:"volume"`
+		TriggerPrice float64    `json:"triggerPrice"`
+		LimitPrice   float64    `json:"limitPrice"`
+		SL           float64    `json:"sl,omitempty"`
+		TP           float64    `json:"tp,omitempty"`
+		TIF          string     `json:"timeInForce,omitempty"` // GTC (default), DAY or GTD
+		Expiry       *time.Time `json:"expiry,omitempty"`      // Required for GTD
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -308,7 +496,20 @@ func (s *Server) HandlePlaceStopLimitOrder(w http.ResponseWriter, r *http.Reques
 		side = orders.OrderSideSell
 	}
 
-	order, err := s.orderService.PlaceStopLimitOrder(req.Symbol, side, req.Volume, req.TriggerPrice, req.LimitPrice, req.SL, req.TP)
+	placement := &orders.Placement{Type: orders.OrderTypeStopLimit, Side: side, Price: req.LimitPrice, SL: req.SL, TP: req.TP, TimeInForce: req.TIF}
+	if !s.applyOrderRules(w, r, req.Symbol, placement) {
+		return
+	}
+	if _, err := orders.ValidateTimeInForce(placement.TimeInForce, req.Expiry, time.Now()); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opts, ok := s.placeOptions(w, r, placement, req.Expiry)
+	if !ok {
+		return
+	}
+	order, err := s.orderService.PlaceStopLimitOrder(req.Symbol, side, req.Volume, req.TriggerPrice, req.LimitPrice, placement.SL, placement.TP, opts)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -318,21 +519,25 @@ func (s *Server) HandlePlaceStopLimitOrder(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(order)
 }
 
-// HandleGetPendingOrders returns all pending orders
+// HandleGetPendingOrders returns the caller's pending orders, or every
+// pending order for an admin token
 func (s *Server) HandleGetPendingOrders(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-	orders := s.orderService.GetPendingOrders()
+	var pending []*orders.PendingOrder
+	if s.isAdminRequest(r) {
+		pending = s.orderService.GetPendingOrders()
+	} else {
+		accountID, ok := s.requestAccountID(w, r)
+		if !ok {
+			return
+		}
+		pending = s.orderService.GetAccountPendingOrders(accountID)
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(orders)
+	json.NewEncoder(w).Encode(pending)
 }
 
 // HandleCancelOrder cancels a pending order
 func (s *Server) HandleCancelOrder(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -347,7 +552,18 @@ func (s *Server) HandleCancelOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.orderService.CancelOrder(req.OrderID); err != nil {
+	// A trader only cancels their own orders; an admin any
+	var err error
+	if s.isAdminRequest(r) {
+		err = s.orderService.CancelOrder(req.OrderID)
+	} else {
+		accountID, ok := s.requestAccountID(w, r)
+		if !ok {
+			return
+		}
+		err = s.orderService.CancelAccountOrder(req.OrderID, accountID)
+	}
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
@@ -358,9 +574,6 @@ func (s *Server) HandleCancelOrder(w http.ResponseWriter, r *http.Request) {
 
 // HandlePartialClose handles partial position close
 func (s *Server) HandlePartialClose(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -387,9 +600,6 @@ func (s *Server) HandlePartialClose(w http.ResponseWriter, r *http.Request) {
 
 // HandleCloseAll closes all positions
 func (s *Server) HandleCloseAll(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -407,9 +617,6 @@ func (s *Server) HandleCloseAll(w http.ResponseWriter, r *http.Request) {
 
 // HandleModifySLTP modifies stop loss and take profit
 func (s *Server) HandleModifySLTP(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -440,9 +647,6 @@ func (s *Server) HandleModifySLTP(w http.ResponseWriter, r *http.Request) {
 
 // HandleBreakeven sets SL to entry price
 func (s *Server) HandleBreakeven(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -464,19 +668,14 @@ func (s *Server) HandleBreakeven(w http.ResponseWriter, r *http.Request) {
 
 // HandleSetTrailingStop sets a trailing stop
 func (s *Server) HandleSetTrailingStop(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
 	var req struct {
-		TradeID  string  `json:"tradeId"`
-		Symbol   string  `json:"symbol"`
-		Side     string  `json:"side"`
-		Type     string  `json:"type"` // FIXED, STEP, ATR
+		TradeID  string  `json:"tradeId"` // B-Book position ID
+		Type     string  `json:"type"`    // FIXED, STEP, ATR
 		Distance float64 `json:"distance"`
 		StepSize float64 `json:"stepSize,omitempty"`
 	}
@@ -485,6 +684,21 @@ func (s *Server) HandleSetTrailingStop(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	if req.Distance <= 0 {
+		http.Error(w, "distance must be positive", http.StatusBadRequest)
+		return
+	}
+
+	// Symbol and side are the position's own, not the client's
+	positionID, err := strconv.ParseInt(req.TradeID, 10, 64)
+	if err != nil {
+		http.Error(w, "tradeId must be a position ID", http.StatusBadRequest)
+		return
+	}
+	position, ok := s.bbookAPI.AuthorizePosition(w, r, positionID)
+	if !ok {
+		return
+	}
 
 	tsType := orders.TrailingFixed
 	switch req.Type {
@@ -494,7 +708,7 @@ func (s *Server) HandleSetTrailingStop(w http.ResponseWriter, r *http.Request) {
 		tsType = orders.TrailingATR
 	}
 
-	s.trailingService.SetTrailingStop(req.TradeID, req.Symbol, req.Side, tsType, req.Distance, req.StepSize)
+	s.trailingService.SetTrailingStop(req.TradeID, position.AccountID, position.Symbol, position.Side, tsType, req.Distance, req.StepSize)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -507,8 +721,6 @@ func (s *Server) HandleSetTrailingStop(w http.ResponseWriter, r *http.Request) {
 
 // HandleCalculateLot calculates lot size from risk
 func (s *Server) HandleCalculateLot(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-
 	symbol := r.URL.Query().Get("symbol")
 	riskPercent, _ := strconv.ParseFloat(r.URL.Query().Get("riskPercent"), 64)
 	slPips, _ := strconv.ParseFloat(r.URL.Query().Get("slPips"), 64)
@@ -530,8 +742,6 @@ func (s *Server) HandleCalculateLot(w http.ResponseWriter, r *http.Request) {
 
 // HandleMarginPreview previews margin requirements
 func (s *Server) HandleMarginPreview(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-
 	symbol := r.URL.Query().Get("symbol")
 	volume, _ := strconv.ParseFloat(r.URL.Query().Get("volume"), 64)
 	side := r.URL.Query().Get("side")
@@ -561,9 +771,6 @@ func (s *Server) HandleMarginPreview(w http.ResponseWriter, r *http.Request) {
 
 // HandleGetAccountInfo returns detailed account information
 func (s *Server) HandleGetAccountInfo(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	// Legacy OANDA logic removed
 	http.Error(w, "No LP connection", http.StatusServiceUnavailable)
 }
@@ -573,9 +780,6 @@ func (s *Server) HandleGetAccount(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) HandleGetPositions(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -587,9 +791,6 @@ func (s *Server) HandleGetPositions(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) HandleClosePosition(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -743,8 +944,6 @@ func getSymbolSpecification(symbol string) *SymbolSpecification {
 
 // HandleGetSymbolSpec returns symbol specifications
 func (s *Server) HandleGetSymbolSpec(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 	w.Header().Set("Content-Type", "application/json")
 
 	if r.Method == "OPTIONS" {
@@ -778,9 +977,6 @@ func (s *Server) HandleGetSymbolSpec(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) HandleGetTicks(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -811,9 +1007,6 @@ func (s *Server) HandleGetTicks(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) HandleGetOHLC(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -866,9 +1059,6 @@ func (s *Server) HandleGetOHLC(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) HandleGetRoutes(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	rules := s.smartRouter.GetRules()
 
 	w.Header().Set("Content-Type", "application/json")
@@ -877,7 +1067,6 @@ func (s *Server) HandleGetRoutes(w http.ResponseWriter, r *http.Request) {
 
 // HandleLPStatus returns the status of LPs (Legacy - use /admin/lp-status)
 func (s *Server) HandleLPStatus(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Content-Type", "application/json")
 
 	// Legacy endpoint compatibility
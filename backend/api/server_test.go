@@ -0,0 +1,40 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/epic1st/rtx/backend/abook"
+)
+
+// TestRespondOrderRejected tests that an LP reject reaches the client with its
+// reason instead of a generic 400
+func TestRespondOrderRejected(t *testing.T) {
+	w := httptest.NewRecorder()
+	respondOrderRejected(w, &abook.RejectError{OrderID: "o-1", Code: "2", Text: "market closed"})
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+	var body struct {
+		Success bool   `json:"success"`
+		Error   string `json:"error"`
+		Code    string `json:"code"`
+		OrderID string `json:"orderId"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Success || body.Error != "Rejected: market closed" || body.Code != "2" || body.OrderID != "o-1" {
+		t.Errorf("body = %+v, want the LP's reject reason", body)
+	}
+
+	w = httptest.NewRecorder()
+	respondOrderRejected(w, errors.New("validation failed"))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("other error status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
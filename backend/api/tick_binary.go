@@ -0,0 +1,113 @@
+package api
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/epic1st/rtx/backend/tickstore"
+)
+
+// Binary tick format (format=binary), all integers and floats little-endian:
+//
+//	Header
+//	  [4]byte  magic "RTXT"
+//	  uint16   version (1)
+//	  uint16   record size in bytes (40)
+//	  uint32   record count
+//	  uint8    symbol length N
+//	  [N]byte  symbol (ASCII)
+//
+//	Record (40 bytes, repeated count times, oldest first)
+//	  int64    timestamp, Unix milliseconds
+//	  float64  bid
+//	  float64  ask
+//	  float64  spread
+//	  [8]byte  reserved, zero
+//
+// Readers must skip unknown trailing record bytes using the header's record
+// size, so later versions can append fields.
+const (
+	tickBinaryMagic      = "RTXT"
+	tickBinaryVersion    = 1
+	tickBinaryRecordSize = 40
+)
+
+// ErrInvalidTickBinary is returned when decoding data that is not a binary tick stream
+var ErrInvalidTickBinary = errors.New("invalid binary tick data")
+
+// EncodeTicksBinary writes ticks for symbol in the binary tick format
+func EncodeTicksBinary(w io.Writer, symbol string, ticks []tickstore.Tick) error {
+	if len(symbol) > math.MaxUint8 {
+		return fmt.Errorf("symbol %q too long", symbol)
+	}
+
+	bw := bufio.NewWriter(w)
+	header := make([]byte, 0, 13+len(symbol))
+	header = append(header, tickBinaryMagic...)
+	header = binary.LittleEndian.AppendUint16(header, tickBinaryVersion)
+	header = binary.LittleEndian.AppendUint16(header, tickBinaryRecordSize)
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(ticks)))
+	header = append(header, byte(len(symbol)))
+	header = append(header, symbol...)
+	if _, err := bw.Write(header); err != nil {
+		return err
+	}
+
+	var record [tickBinaryRecordSize]byte
+	for _, tick := range ticks {
+		binary.LittleEndian.PutUint64(record[0:], uint64(tick.Timestamp.UnixMilli()))
+		binary.LittleEndian.PutUint64(record[8:], math.Float64bits(tick.Bid))
+		binary.LittleEndian.PutUint64(record[16:], math.Float64bits(tick.Ask))
+		binary.LittleEndian.PutUint64(record[24:], math.Float64bits(tick.Spread))
+		if _, err := bw.Write(record[:]); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// DecodeTicksBinary reads a binary tick stream back into its symbol and ticks
+func DecodeTicksBinary(r io.Reader) (string, []tickstore.Tick, error) {
+	var fixed [13]byte
+	if _, err := io.ReadFull(r, fixed[:]); err != nil {
+		return "", nil, fmt.Errorf("%w: header: %v", ErrInvalidTickBinary, err)
+	}
+	if string(fixed[0:4]) != tickBinaryMagic {
+		return "", nil, fmt.Errorf("%w: bad magic", ErrInvalidTickBinary)
+	}
+	if version := binary.LittleEndian.Uint16(fixed[4:]); version != tickBinaryVersion {
+		return "", nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidTickBinary, version)
+	}
+	recordSize := int(binary.LittleEndian.Uint16(fixed[6:]))
+	if recordSize < 32 {
+		return "", nil, fmt.Errorf("%w: record size %d", ErrInvalidTickBinary, recordSize)
+	}
+	count := binary.LittleEndian.Uint32(fixed[8:])
+
+	symbolBytes := make([]byte, fixed[12])
+	if _, err := io.ReadFull(r, symbolBytes); err != nil {
+		return "", nil, fmt.Errorf("%w: symbol: %v", ErrInvalidTickBinary, err)
+	}
+	symbol := string(symbolBytes)
+
+	ticks := make([]tickstore.Tick, 0, min(int(count), 10000))
+	record := make([]byte, recordSize)
+	for i := uint32(0); i < count; i++ {
+		if _, err := io.ReadFull(r, record); err != nil {
+			return "", nil, fmt.Errorf("%w: record %d: %v", ErrInvalidTickBinary, i, err)
+		}
+		ticks = append(ticks, tickstore.Tick{
+			Symbol:    symbol,
+			Timestamp: time.UnixMilli(int64(binary.LittleEndian.Uint64(record[0:]))),
+			Bid:       math.Float64frombits(binary.LittleEndian.Uint64(record[8:])),
+			Ask:       math.Float64frombits(binary.LittleEndian.Uint64(record[16:])),
+			Spread:    math.Float64frombits(binary.LittleEndian.Uint64(record[24:])),
+		})
+	}
+	return symbol, ticks, nil
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/tickstore"
+)
+
+// TestTickBinaryRoundTrip tests that encoded ticks decode back unchanged, with
+// the documented header, record size and little-endian byte order
+func TestTickBinaryRoundTrip(t *testing.T) {
+	base := time.UnixMilli(1767225600123)
+	ticks := []tickstore.Tick{
+		{Symbol: "EURUSD", Bid: 1.08245, Ask: 1.08255, Spread: 0.0001, Timestamp: base},
+		{Symbol: "EURUSD", Bid: 1.08250, Ask: 1.08262, Spread: 0.00012, Timestamp: base.Add(250 * time.Millisecond)},
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeTicksBinary(&buf, "EURUSD", ticks); err != nil {
+		t.Fatalf("EncodeTicksBinary() error = %v", err)
+	}
+
+	data := buf.Bytes()
+	headerSize := 13 + len("EURUSD")
+	if len(data) != headerSize+len(ticks)*tickBinaryRecordSize {
+		t.Fatalf("encoded %d bytes, want %d", len(data), headerSize+len(ticks)*tickBinaryRecordSize)
+	}
+	if string(data[:4]) != "RTXT" || binary.LittleEndian.Uint16(data[6:]) != 40 || binary.LittleEndian.Uint32(data[8:]) != 2 {
+		t.Errorf("header = % x, want magic RTXT, record size 40 and count 2", data[:headerSize])
+	}
+	first := data[headerSize:]
+	if ts := int64(binary.LittleEndian.Uint64(first)); ts != base.UnixMilli() {
+		t.Errorf("first timestamp = %d, want %d little-endian", ts, base.UnixMilli())
+	}
+	if bid := math.Float64frombits(binary.LittleEndian.Uint64(first[8:])); bid != 1.08245 {
+		t.Errorf("first bid = %v, want 1.08245", bid)
+	}
+
+	symbol, decoded, err := DecodeTicksBinary(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("DecodeTicksBinary() error = %v", err)
+	}
+	if symbol != "EURUSD" || len(decoded) != len(ticks) {
+		t.Fatalf("decoded %s with %d ticks, want EURUSD with %d", symbol, len(decoded), len(ticks))
+	}
+	for i, tick := range decoded {
+		want := ticks[i]
+		if !tick.Timestamp.Equal(want.Timestamp) || tick.Bid != want.Bid || tick.Ask != want.Ask || tick.Spread != want.Spread {
+			t.Errorf("tick %d = %+v, want %+v", i, tick, want)
+		}
+	}
+
+	if _, _, err := DecodeTicksBinary(bytes.NewReader(data[:len(data)-1])); !errors.Is(err, ErrInvalidTickBinary) {
+		t.Errorf("DecodeTicksBinary() of a truncated stream error = %v, want ErrInvalidTickBinary", err)
+	}
+}
+
+// TestRespondBinaryHeaders tests the content type and download name of binary responses
+func TestRespondBinaryHeaders(t *testing.T) {
+	rec := httptest.NewRecorder()
+	(&HistoryHandler{}).respondBinary(rec, "GBPUSD", nil)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/octet-stream" {
+		t.Errorf("Content-Type = %q, want application/octet-stream", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != `attachment; filename="GBPUSD_ticks.bin"` {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	if symbol, ticks, err := DecodeTicksBinary(rec.Body); err != nil || symbol != "GBPUSD" || len(ticks) != 0 {
+		t.Errorf("body decoded to %q, %d ticks, %v; want an empty GBPUSD stream", symbol, len(ticks), err)
+	}
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Token roles. ADMIN satisfies every role requirement.
+const (
+	RoleAdmin  = "ADMIN"
+	RoleTrader = "TRADER"
+)
+
+type claimsContextKey struct{}
+
+// HasRole reports whether the token may act as role
+func (c *Claims) HasRole(role string) bool {
+	return c.Role == RoleAdmin || c.Role == role
+}
+
+// AccountID returns the trading account a trader token is bound to. Admin
+// tokens are not bound to an account and return false.
+func (c *Claims) AccountID() (int64, bool) {
+	if c.Role != RoleTrader {
+		return 0, false
+	}
+	accountID, err := strconv.ParseInt(c.UserID, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return accountID, true
+}
+
+// HasScope reports whether the token lists scope. Admin tokens hold every scope.
+func (c *Claims) HasScope(scope string) bool {
+	if c.Role == RoleAdmin {
+		return true
+	}
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ClaimsFromContext returns the claims RequireRole or RequireScope stored on
+// the request, and false for routes that are not protected
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*Claims)
+	return claims, ok
+}
+
+// RequireRole wraps next so it only runs for a valid bearer token of role.
+// Missing, invalid or expired tokens get 401, a token of another role 403.
+// CORS preflight requests pass through to next unchecked.
+func (s *Service) RequireRole(role string, next http.HandlerFunc) http.HandlerFunc {
+	return s.require(func(c *Claims) bool { return c.HasRole(role) }, next)
+}
+
+// RequireScope wraps next so it only runs for a valid bearer token listing scope
+func (s *Service) RequireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return s.require(func(c *Claims) bool { return c.HasScope(scope) }, next)
+}
+
+// require validates the bearer token and runs next if allowed accepts its claims
+func (s *Service) require(allowed func(*Claims) bool, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "OPTIONS" {
+			next(w, r)
+			return
+		}
+
+		parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		claims, err := s.ValidateToken(parts[1])
+		if errors.Is(err, ErrTokenExpired) {
+			http.Error(w, "Token expired", http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !allowed(claims) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), claimsContextKey{}, claims)))
+	}
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+// TestRequireRole tests that admin routes refuse trader and missing tokens
+// while trader routes accept both roles
+func TestRequireRole(t *testing.T) {
+	service := NewService(core.NewEngine(), "", testSecret)
+	adminToken, _ := service.GenerateToken(&User{ID: "0", Username: "admin", Role: RoleAdmin})
+	traderToken, _ := service.GenerateToken(&User{ID: "1", Username: "trader1", Role: RoleTrader})
+	expiredToken, _ := GenerateJWTWithTTL(&User{ID: "0", Role: RoleAdmin}, []byte(testSecret), -time.Minute)
+
+	var gotClaims *Claims
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		gotClaims, _ = ClaimsFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}
+	adminOnly := service.RequireRole(RoleAdmin, handler)
+	traderRoute := service.RequireRole(RoleTrader, handler)
+
+	tests := []struct {
+		name   string
+		route  http.HandlerFunc
+		method string
+		token  string
+		want   int
+	}{
+		{"admin on admin route", adminOnly, "POST", adminToken, http.StatusOK},
+		{"trader on admin route", adminOnly, "POST", traderToken, http.StatusForbidden},
+		{"no token", adminOnly, "POST", "", http.StatusUnauthorized},
+		{"expired token", adminOnly, "POST", expiredToken, http.StatusUnauthorized},
+		{"garbage token", adminOnly, "POST", "not.a.jwt", http.StatusUnauthorized},
+		{"preflight without token", adminOnly, "OPTIONS", "", http.StatusOK},
+		{"trader on trader route", traderRoute, "GET", traderToken, http.StatusOK},
+		{"admin on trader route", traderRoute, "GET", adminToken, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/admin/deposit", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			tt.route(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+
+	req := httptest.NewRequest("GET", "/api/orders", nil)
+	req.Header.Set("Authorization", "Bearer "+traderToken)
+	traderRoute(httptest.NewRecorder(), req)
+	if gotClaims == nil || gotClaims.UserID != "1" {
+		t.Errorf("claims in context = %+v, want trader 1", gotClaims)
+	}
+}
+
+// TestRequireScope tests that scopes are carried in the token and enforced
+func TestRequireScope(t *testing.T) {
+	engine := core.NewEngine()
+	account := engine.CreateAccount("user1", "trader1", "password", true)
+	service := NewService(engine, "", testSecret)
+	scoped, _ := service.GenerateToken(&User{ID: strconv.FormatInt(account.ID, 10), Role: RoleTrader, Scopes: []string{"trade"}})
+	unscoped, _ := service.GenerateToken(&User{ID: "2", Role: RoleTrader})
+
+	route := service.RequireScope("trade", func(w http.ResponseWriter, r *http.Request) {})
+	for token, want := range map[string]int{scoped: http.StatusOK, unscoped: http.StatusForbidden} {
+		req := httptest.NewRequest("POST", "/api/orders/market", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		route(rec, req)
+		if rec.Code != want {
+			t.Errorf("status = %d, want %d", rec.Code, want)
+		}
+	}
+
+	// Refreshing keeps the scopes
+	refreshed, user, err := service.RefreshToken(scoped)
+	if err != nil || len(user.Scopes) != 1 {
+		t.Fatalf("RefreshToken() = %+v, %v; want the trade scope kept", user, err)
+	}
+	if claims, _ := service.ValidateToken(refreshed); !claims.HasScope("trade") {
+		t.Error("refreshed token lost its scope")
+	}
+}
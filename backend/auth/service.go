@@ -3,17 +3,25 @@ package auth
 import (
 	"errors"
 	"log"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/epic1st/rtx/backend/internal/core"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrAccountInactive is returned when refreshing the token of a trader whose
+// account was disabled, suspended or removed since the token was issued
+var ErrAccountInactive = errors.New("account is not active")
+
 // User represents a system user
 type User struct {
-	ID       string `json:"id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	ID       string   `json:"id"`
+	Username string   `json:"username"`
+	Role     string   `json:"role"`
+	Scopes   []string `json:"scopes,omitempty"`
 }
 
 // Service handles authentication logic
@@ -21,6 +29,8 @@ type Service struct {
 	engine    *core.Engine
 	adminHash []byte
 	jwtSecret []byte
+	tokenTTL  time.Duration
+	now       func() time.Time
 }
 
 // NewService creates authentication service with admin credentials and JWT secret
@@ -47,7 +57,22 @@ func NewService(engine *core.Engine, adminPasswordHash string, jwtSecret string)
 		engine:    engine,
 		adminHash: hash,
 		jwtSecret: secret,
+		tokenTTL:  DefaultTokenTTL,
+		now:       time.Now,
+	}
+}
+
+// SetTokenTTL sets how long issued tokens stay valid. 0 restores DefaultTokenTTL.
+func (s *Service) SetTokenTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultTokenTTL
 	}
+	s.tokenTTL = ttl
+}
+
+// TokenTTL returns how long issued tokens stay valid
+func (s *Service) TokenTTL() time.Duration {
+	return s.tokenTTL
 }
 
 // Login validates credentials securely
@@ -61,7 +86,7 @@ func (s *Service) Login(username, password string) (string, *User, error) {
 		}
 
 		log.Printf("[INFO] Admin logged in")
-		user := &User{ID: "0", Username: "admin", Role: "ADMIN"}
+		user := &User{ID: "0", Username: "admin", Role: RoleAdmin}
 		token, err := s.GenerateToken(user)
 		if err != nil {
 			log.Printf("[CRITICAL] JWT Generation failed: %v", err)
@@ -118,7 +143,7 @@ func (s *Service) Login(username, password string) (string, *User, error) {
 	user := &User{
 		ID:       strconv.FormatInt(account.ID, 10),
 		Username: account.Username,
-		Role:     "TRADER",
+		Role:     RoleTrader,
 	}
 
 	token, err := s.GenerateToken(user)
@@ -132,10 +157,64 @@ func (s *Service) Login(username, password string) (string, *User, error) {
 
 // GenerateToken creates a JWT token for the given user using the service's secret
 func (s *Service) GenerateToken(user *User) (string, error) {
-	return GenerateJWTWithSecret(user, s.jwtSecret)
+	return generateJWT(user, s.jwtSecret, s.now(), s.tokenTTL)
 }
 
 // ValidateToken validates a JWT token using the service's secret
 func (s *Service) ValidateToken(tokenString string) (*Claims, error) {
-	return ValidateToken(tokenString, s.jwtSecret)
+	return validateTokenAt(tokenString, s.jwtSecret, s.now())
+}
+
+// RefreshToken exchanges a valid, unexpired token for a new one carrying the
+// same user with a fresh expiry. Expired tokens return ErrTokenExpired, and
+// trader tokens whose account is no longer ACTIVE return ErrAccountInactive.
+func (s *Service) RefreshToken(tokenString string) (string, *User, error) {
+	claims, err := s.ValidateToken(tokenString)
+	if err != nil {
+		return "", nil, err
+	}
+	if claims.Role == RoleTrader {
+		accountID, err := strconv.ParseInt(claims.UserID, 10, 64)
+		if err != nil {
+			return "", nil, ErrAccountInactive
+		}
+		if account, ok := s.engine.GetAccount(accountID); !ok || account.Status != "ACTIVE" {
+			log.Printf("[WARN] Token refresh refused for user %s: account is not active", claims.Username)
+			return "", nil, ErrAccountInactive
+		}
+	}
+
+	user := &User{ID: claims.UserID, Username: claims.Username, Role: claims.Role, Scopes: claims.Scopes}
+	token, err := s.GenerateToken(user)
+	if err != nil {
+		log.Printf("[CRITICAL] JWT Generation failed: %v", err)
+		return "", nil, errors.New("system error")
+	}
+	return token, user, nil
+}
+
+// AccountIDFromRequest returns the trading account of the bearer token on r.
+// Only trader tokens carry an account; admin and missing tokens return false.
+func (s *Service) AccountIDFromRequest(r *http.Request) (int64, bool) {
+	parts := strings.Split(r.Header.Get("Authorization"), " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return 0, false
+	}
+
+	claims, err := s.ValidateToken(parts[1])
+	if err != nil {
+		return 0, false
+	}
+	return claims.AccountID()
+}
+
+// IsAdminRequest reports whether r carries a valid admin bearer token
+func (s *Service) IsAdminRequest(r *http.Request) bool {
+	parts := strings.Split(r.Header.Get("Authorization"), " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return false
+	}
+
+	claims, err := s.ValidateToken(parts[1])
+	return err == nil && claims.Role == RoleAdmin
 }
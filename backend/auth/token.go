@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"errors"
 	"os"
 	"time"
 
@@ -9,6 +10,12 @@ import (
 
 var jwtKey = []byte(os.Getenv("JWT_SECRET"))
 
+// DefaultTokenTTL is how long an issued token stays valid unless configured
+const DefaultTokenTTL = 24 * time.Hour
+
+// ErrTokenExpired is returned when a token's exp claim has passed
+var ErrTokenExpired = errors.New("token expired")
+
 func init() {
 	if len(jwtKey) == 0 {
 		// Fallback for development only - strictly speaking this violates "No hidden randomness/globals"
@@ -23,6 +30,8 @@ type Claims struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	// Optional finer-grained permissions, e.g. "trade" or "read_only"
+	Scopes []string `json:"scopes,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -33,14 +42,24 @@ func GenerateJWT(user *User) (string, error) {
 
 // GenerateJWTWithSecret creates a new token for a user with a specific secret
 func GenerateJWTWithSecret(user *User, secret []byte) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+	return GenerateJWTWithTTL(user, secret, DefaultTokenTTL)
+}
+
+// GenerateJWTWithTTL creates a new token for a user that expires after ttl
+func GenerateJWTWithTTL(user *User, secret []byte, ttl time.Duration) (string, error) {
+	return generateJWT(user, secret, time.Now(), ttl)
+}
+
+// generateJWT signs a token issued at issuedAt and expiring ttl later
+func generateJWT(user *User, secret []byte, issuedAt time.Time, ttl time.Duration) (string, error) {
 	claims := &Claims{
 		UserID:   user.ID,
 		Username: user.Username,
 		Role:     user.Role,
+		Scopes:   user.Scopes,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(issuedAt.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
 			Issuer:    "rtx-trading-engine",
 		},
 	}
@@ -54,8 +73,14 @@ func GenerateJWTWithSecret(user *User, secret []byte) (string, error) {
 	return tokenString, nil
 }
 
-// ValidateToken validates a JWT token and returns the claims if valid
+// ValidateToken validates a JWT token and returns the claims if valid.
+// Tokens without an exp claim are rejected; expired ones return ErrTokenExpired.
 func ValidateToken(tokenString string, secret []byte) (*Claims, error) {
+	return validateTokenAt(tokenString, secret, time.Now())
+}
+
+// validateTokenAt validates a token as of now
+func validateTokenAt(tokenString string, secret []byte, now time.Time) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 		// Verify signing method
@@ -63,8 +88,11 @@ func ValidateToken(tokenString string, secret []byte) (*Claims, error) {
 			return nil, jwt.ErrSignatureInvalid
 		}
 		return secret, nil
-	})
+	}, jwt.WithExpirationRequired(), jwt.WithTimeFunc(func() time.Time { return now }))
 
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, ErrTokenExpired
+	}
 	if err != nil {
 		return nil, err
 	}
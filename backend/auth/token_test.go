@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+const testSecret = "test-jwt-secret-for-testing-only"
+
+// TestTokenExpiryBoundary tests that a token is valid until the second before
+// its exp claim and expired from exp onwards
+func TestTokenExpiryBoundary(t *testing.T) {
+	issuedAt := time.Unix(1700000000, 0)
+	user := &User{ID: "1", Username: "trader1", Role: "TRADER"}
+
+	token, err := generateJWT(user, []byte(testSecret), issuedAt, time.Hour)
+	if err != nil {
+		t.Fatalf("generateJWT() error = %v", err)
+	}
+
+	claims, err := validateTokenAt(token, []byte(testSecret), issuedAt.Add(time.Hour-time.Second))
+	if err != nil {
+		t.Fatalf("token a second before expiry: error = %v", err)
+	}
+	if claims.UserID != "1" || claims.ExpiresAt == nil || !claims.ExpiresAt.Time.Equal(issuedAt.Add(time.Hour)) {
+		t.Errorf("claims = %+v, want user 1 expiring at %v", claims, issuedAt.Add(time.Hour))
+	}
+
+	if _, err := validateTokenAt(token, []byte(testSecret), issuedAt.Add(time.Hour)); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("token at expiry: error = %v, want %v", err, ErrTokenExpired)
+	}
+}
+
+// TestTamperedTokenRejected tests that changing the payload or signing with
+// another secret invalidates the token
+func TestTamperedTokenRejected(t *testing.T) {
+	user := &User{ID: "1", Username: "trader1", Role: "TRADER"}
+	token, err := GenerateJWTWithTTL(user, []byte(testSecret), time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateJWTWithTTL() error = %v", err)
+	}
+
+	// Swap the payload for an admin one while keeping the original signature
+	admin, _ := GenerateJWTWithTTL(&User{ID: "0", Username: "admin", Role: "ADMIN"}, []byte(testSecret), time.Hour)
+	parts, adminParts := strings.Split(token, "."), strings.Split(admin, ".")
+	tampered := parts[0] + "." + adminParts[1] + "." + parts[2]
+	if _, err := ValidateToken(tampered, []byte(testSecret)); err == nil {
+		t.Error("token with a swapped payload validated")
+	}
+
+	forged, _ := GenerateJWTWithTTL(user, []byte("some-other-secret"), time.Hour)
+	if _, err := ValidateToken(forged, []byte(testSecret)); err == nil {
+		t.Error("token signed with another secret validated")
+	}
+}
+
+// TestRefreshToken tests that refreshing issues a token with a later expiry
+// for the same user and that expired tokens cannot be refreshed
+func TestRefreshToken(t *testing.T) {
+	engine := core.NewEngine()
+	account := engine.CreateAccount("user7", "trader7", "password", true)
+	id := strconv.FormatInt(account.ID, 10)
+	service := NewService(engine, "", testSecret)
+	service.SetTokenTTL(time.Hour)
+	now := time.Unix(1700000000, 0)
+	service.now = func() time.Time { return now }
+
+	token, err := service.GenerateToken(&User{ID: id, Username: "trader7", Role: "TRADER"})
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	now = now.Add(30 * time.Minute)
+	refreshed, user, err := service.RefreshToken(token)
+	if err != nil {
+		t.Fatalf("RefreshToken() error = %v", err)
+	}
+	if user.ID != id || user.Role != "TRADER" {
+		t.Errorf("refreshed user = %+v, want trader %s", user, id)
+	}
+	claims, err := service.ValidateToken(refreshed)
+	if err != nil {
+		t.Fatalf("ValidateToken(refreshed) error = %v", err)
+	}
+	if want := now.Add(time.Hour); !claims.ExpiresAt.Time.Equal(want) {
+		t.Errorf("refreshed expiry = %v, want %v", claims.ExpiresAt.Time, want)
+	}
+
+	account.Status = "DISABLED"
+	if _, _, err := service.RefreshToken(refreshed); !errors.Is(err, ErrAccountInactive) {
+		t.Errorf("RefreshToken(disabled account) error = %v, want %v", err, ErrAccountInactive)
+	}
+	orphan, _ := service.GenerateToken(&User{ID: "999", Username: "gone", Role: "TRADER"})
+	if _, _, err := service.RefreshToken(orphan); !errors.Is(err, ErrAccountInactive) {
+		t.Errorf("RefreshToken(unknown account) error = %v, want %v", err, ErrAccountInactive)
+	}
+	account.Status = "ACTIVE"
+
+	now = now.Add(2 * time.Hour)
+	if _, _, err := service.RefreshToken(refreshed); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("RefreshToken(expired) error = %v, want %v", err, ErrTokenExpired)
+	}
+}
@@ -0,0 +1,199 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/tickstore"
+)
+
+var fixtureStart = time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)
+
+// fixtureTicks returns EURUSD quotes with a constant 2 pip spread
+func fixtureTicks() []tickstore.Tick {
+	bids := []float64{
+		1.1000, // BUY @ 1.1002, SL 1.0992
+		1.0995,
+		1.0991, // SL hit: -11 pips
+		1.0990, // BUY @ 1.0992, SL 1.0982
+		1.0985,
+		1.0981, // SL hit: -11 pips
+		1.0990, // BUY @ 1.0992
+		1.1000, // End of data: +8 pips
+	}
+	ticks := make([]tickstore.Tick, len(bids))
+	for i, bid := range bids {
+		ticks[i] = tickstore.Tick{
+			Symbol:    "EURUSD",
+			Bid:       bid,
+			Ask:       bid + 0.0002,
+			Spread:    0.0002,
+			Timestamp: fixtureStart.Add(time.Duration(i) * time.Second),
+		}
+	}
+	return ticks
+}
+
+func alwaysBuyRequest() Request {
+	return Request{
+		Symbol:         "EURUSD",
+		From:           fixtureStart,
+		To:             fixtureStart.Add(time.Hour),
+		InitialBalance: 10000,
+		Strategy: StrategySpec{
+			Side:         "BUY",
+			Volume:       1.0,
+			StopLossPips: 10,
+		},
+	}
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+// TestRunAlwaysBuyThenStopLoss tests the trade list and P/L of a trivial always-buy strategy
+func TestRunAlwaysBuyThenStopLoss(t *testing.T) {
+	result, err := Run(alwaysBuyRequest(), fixtureTicks())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := []struct {
+		entry, exit, pnl float64
+		reason           string
+	}{
+		{1.1002, 1.0991, -110, ExitStopLoss},
+		{1.0992, 1.0981, -110, ExitStopLoss},
+		{1.0992, 1.1000, 80, ExitEndOfData},
+	}
+
+	if len(result.Trades) != len(want) {
+		t.Fatalf("trades = %d, want %d: %+v", len(result.Trades), len(want), result.Trades)
+	}
+	for i, w := range want {
+		got := result.Trades[i]
+		if got.Side != "BUY" || got.Volume != 1.0 {
+			t.Errorf("trade %d = %s %.2f, want BUY 1.00", i, got.Side, got.Volume)
+		}
+		if !approx(got.EntryPrice, w.entry) || !approx(got.ExitPrice, w.exit) {
+			t.Errorf("trade %d prices = %.5f -> %.5f, want %.5f -> %.5f", i, got.EntryPrice, got.ExitPrice, w.entry, w.exit)
+		}
+		if !approx(got.PnL, w.pnl) {
+			t.Errorf("trade %d PnL = %.2f, want %.2f", i, got.PnL, w.pnl)
+		}
+		if got.ExitReason != w.reason {
+			t.Errorf("trade %d exit reason = %s, want %s", i, got.ExitReason, w.reason)
+		}
+	}
+
+	if !approx(result.NetPnL, -140) {
+		t.Errorf("NetPnL = %.2f, want -140.00", result.NetPnL)
+	}
+	if !approx(result.FinalBalance, 9860) {
+		t.Errorf("FinalBalance = %.2f, want 9860.00", result.FinalBalance)
+	}
+	if result.WinningTrades != 1 || result.LosingTrades != 2 {
+		t.Errorf("wins/losses = %d/%d, want 1/2", result.WinningTrades, result.LosingTrades)
+	}
+	if !approx(result.WinRate, 100.0/3) {
+		t.Errorf("WinRate = %.2f, want 33.33", result.WinRate)
+	}
+	// Lowest equity is 9760 (two -110 losses plus -20 open) from a 10000 peak
+	if !approx(result.MaxDrawdown, 240) {
+		t.Errorf("MaxDrawdown = %.2f, want 240.00", result.MaxDrawdown)
+	}
+	if result.TicksProcessed != 8 {
+		t.Errorf("TicksProcessed = %d, want 8", result.TicksProcessed)
+	}
+}
+
+// TestRunEntryRuleWithIndicator tests that entry rules gate trades on indicator values
+func TestRunEntryRuleWithIndicator(t *testing.T) {
+	req := alwaysBuyRequest()
+	req.Strategy.StopLossPips = 0
+	// Only buy once the mid price is above its 3-tick SMA
+	req.Strategy.Entry = []Rule{{
+		Left:     Operand{Indicator: "price"},
+		Operator: ">",
+		Right:    Operand{Indicator: "sma", Period: 3},
+	}}
+	req.Strategy.Exit = []Rule{{
+		Left:     Operand{Indicator: "price"},
+		Operator: "<",
+		Right:    Operand{Indicator: "sma", Period: 3},
+	}}
+
+	result, err := Run(req, fixtureTicks())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// Price first rises above the SMA at tick 6 (mid 1.0991) and stays above through the end
+	if len(result.Trades) != 1 {
+		t.Fatalf("trades = %d, want 1: %+v", len(result.Trades), result.Trades)
+	}
+	if tr := result.Trades[0]; !tr.EntryTime.Equal(fixtureStart.Add(6*time.Second)) || tr.ExitReason != ExitEndOfData {
+		t.Errorf("trade = entry %v reason %s, want entry at tick 6 closed at end of data", tr.EntryTime, tr.ExitReason)
+	}
+}
+
+// TestManagerRunsJobAsync tests job submission, polling and range limits
+func TestManagerRunsJobAsync(t *testing.T) {
+	source := func(symbol string, from, to time.Time, limit int) ([]tickstore.Tick, error) {
+		return fixtureTicks(), nil
+	}
+	manager := NewManager(source)
+
+	job, err := manager.Submit(alwaysBuyRequest())
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, ok := manager.Get(job.ID)
+		if !ok {
+			t.Fatal("job not found")
+		}
+		if got.Status == JobCompleted {
+			if got.Result == nil || len(got.Result.Trades) != 3 {
+				t.Errorf("completed job result = %+v, want 3 trades", got.Result)
+			}
+			break
+		}
+		if got.Status == JobFailed {
+			t.Fatalf("job failed: %s", got.Error)
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job still %s after timeout", got.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// A source that fills the tick limit flags the result as truncated
+	manager.maxTicks = len(fixtureTicks())
+	job, err = manager.Submit(alwaysBuyRequest())
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		got, _ := manager.Get(job.ID)
+		if got.Status == JobCompleted || got.Status == JobFailed {
+			if got.Result == nil || !got.Result.Truncated {
+				t.Errorf("job at the tick limit = %s %+v, want a truncated result", got.Status, got.Result)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job still %s after timeout", got.Status)
+		}
+	}
+
+	tooLong := alwaysBuyRequest()
+	tooLong.To = tooLong.From.Add(MaxRange + time.Hour)
+	if _, err := manager.Submit(tooLong); err == nil {
+		t.Error("Submit() expected error for range above maximum")
+	}
+}
@@ -0,0 +1,66 @@
+package backtest
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Handler exposes backtest jobs over HTTP
+type Handler struct {
+	manager *Manager
+}
+
+// NewHandler creates a backtest HTTP handler
+func NewHandler(manager *Manager) *Handler {
+	return &Handler{manager: manager}
+}
+
+// HandleBacktest handles POST /api/backtest (submit) and GET /api/backtest?id=... (poll)
+func (h *Handler) HandleBacktest(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "OPTIONS":
+		w.WriteHeader(http.StatusOK)
+
+	case "POST":
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		job, err := h.manager.Submit(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"jobId":   job.ID,
+			"status":  job.Status,
+		})
+
+	case "GET":
+		w.Header().Set("Content-Type", "application/json")
+
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"jobs": h.manager.List(),
+			})
+			return
+		}
+
+		job, ok := h.manager.Get(id)
+		if !ok {
+			http.Error(w, "Backtest job not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(job)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
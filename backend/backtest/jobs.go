@@ -0,0 +1,197 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/epic1st/rtx/backend/tickstore"
+)
+
+// Limits that keep a single backtest bounded
+const (
+	MaxRange          = 31 * 24 * time.Hour
+	MaxTicks          = 2000000
+	MaxConcurrentJobs = 2
+	maxRetainedJobs   = 100
+)
+
+// Job statuses
+const (
+	JobPending   = "PENDING"
+	JobRunning   = "RUNNING"
+	JobCompleted = "COMPLETED"
+	JobFailed    = "FAILED"
+)
+
+// TickSource loads historical ticks for a symbol and time range, oldest first,
+// returning at most limit ticks from the start of the range
+type TickSource func(symbol string, from, to time.Time, limit int) ([]tickstore.Tick, error)
+
+// Job tracks an asynchronous backtest run
+type Job struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"`
+	Request     Request    `json:"request"`
+	Result      *Result    `json:"result,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// Manager runs backtest jobs in the background
+type Manager struct {
+	mu       sync.RWMutex
+	jobs     map[string]*Job
+	order    []string // Job IDs, oldest first
+	running  int
+	source   TickSource
+	maxTicks int
+}
+
+// NewManager creates a backtest job manager reading ticks from source
+func NewManager(source TickSource) *Manager {
+	return &Manager{
+		jobs:     make(map[string]*Job),
+		order:    make([]string, 0),
+		source:   source,
+		maxTicks: MaxTicks,
+	}
+}
+
+// Submit validates a request and starts it asynchronously
+func (m *Manager) Submit(req Request) (*Job, error) {
+	req.Symbol = strings.ToUpper(req.Symbol)
+	if req.Symbol == "" {
+		return nil, errors.New("symbol is required")
+	}
+	if req.From.IsZero() || req.To.IsZero() || !req.From.Before(req.To) {
+		return nil, errors.New("from must be before to")
+	}
+	if req.To.Sub(req.From) > MaxRange {
+		return nil, fmt.Errorf("range exceeds maximum of %d days", int(MaxRange.Hours()/24))
+	}
+	if req.InitialBalance <= 0 {
+		req.InitialBalance = 10000
+	}
+	if err := req.Strategy.Validate(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running >= MaxConcurrentJobs {
+		return nil, errors.New("too many backtests running, try again later")
+	}
+
+	job := &Job{
+		ID:        uuid.New().String(),
+		Status:    JobPending,
+		Request:   req,
+		CreatedAt: time.Now(),
+	}
+	m.jobs[job.ID] = job
+	m.order = append(m.order, job.ID)
+	m.running++
+	m.trimLocked()
+
+	go m.run(job.ID, req)
+
+	c := *job
+	return &c, nil
+}
+
+func (m *Manager) run(id string, req Request) {
+	m.setStatus(id, JobRunning, nil, "")
+
+	var result *Result
+	ticks, err := m.source(req.Symbol, req.From, req.To, m.maxTicks)
+	if err == nil {
+		result, err = Run(req, ticks)
+	}
+	if err == nil && len(ticks) >= m.maxTicks {
+		// The source stopped at the limit: the end of the range was not replayed
+		result.Truncated = true
+		log.Printf("[Backtest] Job %s truncated at %d ticks (last %s)", id, len(ticks), ticks[len(ticks)-1].Timestamp.Format(time.RFC3339))
+	}
+
+	if err != nil {
+		log.Printf("[Backtest] Job %s failed: %v", id, err)
+		m.setStatus(id, JobFailed, nil, err.Error())
+		return
+	}
+
+	log.Printf("[Backtest] Job %s completed: %s %d ticks, %d trades, P/L %.2f",
+		id, req.Symbol, result.TicksProcessed, result.TotalTrades, result.NetPnL)
+	m.setStatus(id, JobCompleted, result, "")
+}
+
+func (m *Manager) setStatus(id, status string, result *Result, errMsg string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return
+	}
+	job.Status = status
+	job.Result = result
+	job.Error = errMsg
+	if status == JobCompleted || status == JobFailed {
+		now := time.Now()
+		job.CompletedAt = &now
+		m.running--
+	}
+}
+
+// trimLocked drops the oldest finished jobs beyond the retention limit (caller must hold lock)
+func (m *Manager) trimLocked() {
+	for len(m.order) > maxRetainedJobs {
+		removed := false
+		for i, id := range m.order {
+			job := m.jobs[id]
+			if job.Status == JobCompleted || job.Status == JobFailed {
+				delete(m.jobs, id)
+				m.order = append(m.order[:i], m.order[i+1:]...)
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			return
+		}
+	}
+}
+
+// Get returns a snapshot of a job
+func (m *Manager) Get(id string) (*Job, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, false
+	}
+	c := *job
+	return &c, true
+}
+
+// List returns snapshots of retained jobs without their results, newest first
+func (m *Manager) List() []Job {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	jobs := make([]Job, 0, len(m.order))
+	for i := len(m.order) - 1; i >= 0; i-- {
+		c := *m.jobs[m.order[i]]
+		c.Result = nil
+		jobs = append(jobs, c)
+	}
+	return jobs
+}
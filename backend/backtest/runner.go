@@ -0,0 +1,349 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/epic1st/rtx/backend/internal/core"
+	"github.com/epic1st/rtx/backend/tickstore"
+)
+
+// Exit reasons recorded on each trade
+const (
+	ExitStopLoss   = "SL"
+	ExitTakeProfit = "TP"
+	ExitRule       = "EXIT_RULE"
+	ExitEndOfData  = "END_OF_DATA"
+)
+
+// Request describes a backtest run
+type Request struct {
+	Symbol         string       `json:"symbol"`
+	From           time.Time    `json:"from"`
+	To             time.Time    `json:"to"`
+	InitialBalance float64      `json:"initialBalance"`
+	Leverage       float64      `json:"leverage,omitempty"`
+	Strategy       StrategySpec `json:"strategy"`
+}
+
+// Result holds backtest performance metrics
+type Result struct {
+	Symbol             string        `json:"symbol"`
+	From               time.Time     `json:"from"`
+	To                 time.Time     `json:"to"`
+	TicksProcessed     int           `json:"ticksProcessed"`
+	Truncated          bool          `json:"truncated"` // Tick limit reached before the end of the range
+	InitialBalance     float64       `json:"initialBalance"`
+	FinalBalance       float64       `json:"finalBalance"`
+	NetPnL             float64       `json:"netPnL"`
+	GrossProfit        float64       `json:"grossProfit"`
+	GrossLoss          float64       `json:"grossLoss"`
+	ProfitFactor       float64       `json:"profitFactor"`
+	MaxDrawdown        float64       `json:"maxDrawdown"`        // Peak-to-trough equity decline
+	MaxDrawdownPercent float64       `json:"maxDrawdownPercent"` // As a percentage of the peak
+	TotalTrades        int           `json:"totalTrades"`
+	WinningTrades      int           `json:"winningTrades"`
+	LosingTrades       int           `json:"losingTrades"`
+	WinRate            float64       `json:"winRate"` // Percentage of trades with positive net P/L
+	Trades             []TradeResult `json:"trades"`
+}
+
+// TradeResult is a single round-trip trade
+type TradeResult struct {
+	Side       string    `json:"side"`
+	Volume     float64   `json:"volume"`
+	EntryPrice float64   `json:"entryPrice"`
+	ExitPrice  float64   `json:"exitPrice"`
+	EntryTime  time.Time `json:"entryTime"`
+	ExitTime   time.Time `json:"exitTime"`
+	Commission float64   `json:"commission"`
+	PnL        float64   `json:"pnl"` // Net of commission
+	ExitReason string    `json:"exitReason"`
+}
+
+// Run executes the strategy over ticks in a fresh engine instance.
+// Production engine state is never touched.
+func Run(req Request, ticks []tickstore.Tick) (*Result, error) {
+	if err := req.Strategy.Validate(); err != nil {
+		return nil, err
+	}
+	if req.InitialBalance <= 0 {
+		return nil, errors.New("initial balance must be positive")
+	}
+	if len(ticks) == 0 {
+		return nil, errors.New("no historical ticks in range")
+	}
+
+	symbol := strings.ToUpper(req.Symbol)
+	ticks = append([]tickstore.Tick(nil), ticks...)
+	sort.SliceStable(ticks, func(i, j int) bool {
+		return ticks[i].Timestamp.Before(ticks[j].Timestamp)
+	})
+
+	// Isolated engine driven only by the historical feed
+	var current tickstore.Tick
+	engine := core.NewEngine()
+	engine.SetPriceCallback(func(s string) (float64, float64, bool) {
+		if s != symbol || current.Timestamp.IsZero() {
+			return 0, 0, false
+		}
+		return current.Bid, current.Ask, true
+	})
+	spec := engine.GetOrCreateSymbol(symbol)
+
+	account := engine.CreateAccount("backtest", "backtest", "", true)
+	account.Balance = req.InitialBalance
+	if req.Leverage > 0 {
+		engine.UpdateAccount(account.ID, req.Leverage, "")
+	}
+
+	strategy := req.Strategy
+	eval := newEvaluator(&strategy)
+
+	result := &Result{
+		Symbol:         symbol,
+		From:           req.From,
+		To:             req.To,
+		InitialBalance: req.InitialBalance,
+		Trades:         make([]TradeResult, 0),
+	}
+
+	var position *core.Position
+	var entryTime time.Time // Engine stamps positions with wall-clock time
+	peak := req.InitialBalance
+
+	closeAt := func(reason string) error {
+		trade, err := engine.ClosePosition(position.ID, 0)
+		if err != nil {
+			return err
+		}
+		result.Trades = append(result.Trades, TradeResult{
+			Side:       position.Side,
+			Volume:     trade.Volume,
+			EntryPrice: position.OpenPrice,
+			ExitPrice:  trade.Price,
+			EntryTime:  entryTime,
+			ExitTime:   current.Timestamp,
+			Commission: position.Commission,
+			PnL:        trade.RealizedPnL - position.Commission,
+			ExitReason: reason,
+		})
+		position = nil
+		return nil
+	}
+
+	for _, tick := range ticks {
+		current = tick
+		result.TicksProcessed++
+
+		entrySignal, exitSignal := eval.evaluate(tick)
+
+		// SL/TP are evaluated here rather than in the engine so exits fill
+		// deterministically on the tick that triggers them
+		closed := false
+		if position != nil {
+			reason := exitReason(position, &strategy, spec.PipSize, tick, exitSignal)
+			if reason != "" {
+				if err := closeAt(reason); err != nil {
+					return nil, fmt.Errorf("close failed at %s: %w", tick.Timestamp.Format(time.RFC3339), err)
+				}
+				closed = true
+			}
+		}
+
+		if position == nil && !closed && entrySignal {
+			pos, err := engine.ExecuteMarketOrder(account.ID, symbol, strategy.Side, strategy.Volume, 0, 0)
+			if err == nil {
+				position = pos
+				entryTime = tick.Timestamp
+			}
+		}
+
+		// Mark to market after trading so equity reflects positions opened on this tick
+		engine.UpdatePrice(symbol, tick.Bid, tick.Ask)
+		summary, err := engine.GetAccountSummary(account.ID)
+		if err == nil {
+			if summary.Equity > peak {
+				peak = summary.Equity
+			}
+			if dd := peak - summary.Equity; dd > result.MaxDrawdown {
+				result.MaxDrawdown = dd
+				result.MaxDrawdownPercent = dd / peak * 100
+			}
+		}
+	}
+
+	if position != nil {
+		if err := closeAt(ExitEndOfData); err != nil {
+			return nil, fmt.Errorf("final close failed: %w", err)
+		}
+	}
+
+	result.FinalBalance = account.Balance
+	result.NetPnL = round2(result.FinalBalance - result.InitialBalance)
+	for _, t := range result.Trades {
+		if t.PnL > 0 {
+			result.WinningTrades++
+			result.GrossProfit += t.PnL
+		} else {
+			result.LosingTrades++
+			result.GrossLoss += t.PnL
+		}
+	}
+	result.TotalTrades = len(result.Trades)
+	if result.TotalTrades > 0 {
+		result.WinRate = float64(result.WinningTrades) / float64(result.TotalTrades) * 100
+	}
+	if result.GrossLoss < 0 {
+		result.ProfitFactor = result.GrossProfit / math.Abs(result.GrossLoss)
+	}
+
+	return result, nil
+}
+
+// exitReason returns why the open position should close on this tick, if at all
+func exitReason(pos *core.Position, s *StrategySpec, pipSize float64, tick tickstore.Tick, exitSignal bool) string {
+	if pos.Side == "BUY" {
+		if s.StopLossPips > 0 && tick.Bid <= pos.OpenPrice-s.StopLossPips*pipSize {
+			return ExitStopLoss
+		}
+		if s.TakeProfitPips > 0 && tick.Bid >= pos.OpenPrice+s.TakeProfitPips*pipSize {
+			return ExitTakeProfit
+		}
+	} else {
+		if s.StopLossPips > 0 && tick.Ask >= pos.OpenPrice+s.StopLossPips*pipSize {
+			return ExitStopLoss
+		}
+		if s.TakeProfitPips > 0 && tick.Ask <= pos.OpenPrice-s.TakeProfitPips*pipSize {
+			return ExitTakeProfit
+		}
+	}
+	if exitSignal {
+		return ExitRule
+	}
+	return ""
+}
+
+// evaluator maintains indicator state and rule history across ticks
+type evaluator struct {
+	spec       *StrategySpec
+	indicators map[string]indicator
+	barStart   int64
+	barClose   float64
+	hasBar     bool
+	prevEntry  []ruleValues
+	prevExit   []ruleValues
+}
+
+type ruleValues struct {
+	left, right float64
+	ok          bool
+}
+
+func newEvaluator(spec *StrategySpec) *evaluator {
+	e := &evaluator{
+		spec:       spec,
+		indicators: make(map[string]indicator),
+		prevEntry:  make([]ruleValues, len(spec.Entry)),
+		prevExit:   make([]ruleValues, len(spec.Exit)),
+	}
+	for _, rules := range [][]Rule{spec.Entry, spec.Exit} {
+		for _, r := range rules {
+			for _, op := range []Operand{r.Left, r.Right} {
+				if ind := newIndicator(op); ind != nil {
+					e.indicators[op.key()] = ind
+				}
+			}
+		}
+	}
+	return e
+}
+
+// evaluate feeds the tick to the indicators and returns whether all entry
+// rules and any exit rule hold
+func (e *evaluator) evaluate(tick tickstore.Tick) (entry, exit bool) {
+	mid := (tick.Bid + tick.Ask) / 2
+
+	if e.spec.Timeframe <= 0 {
+		for _, ind := range e.indicators {
+			ind.update(mid)
+		}
+	} else {
+		bar := tick.Timestamp.Unix() / e.spec.Timeframe
+		if e.hasBar && bar != e.barStart {
+			// Previous bar closed
+			for _, ind := range e.indicators {
+				ind.update(e.barClose)
+			}
+		}
+		e.barStart = bar
+		e.barClose = mid
+		e.hasBar = true
+	}
+
+	entry = true
+	for i, r := range e.spec.Entry {
+		if !e.check(r, &e.prevEntry[i], tick) {
+			entry = false
+		}
+	}
+	for i, r := range e.spec.Exit {
+		if e.check(r, &e.prevExit[i], tick) {
+			exit = true
+		}
+	}
+	return entry, exit
+}
+
+// check evaluates one rule and records its operand values for crossover detection
+func (e *evaluator) check(r Rule, prev *ruleValues, tick tickstore.Tick) bool {
+	left, lok := e.operandValue(r.Left, tick)
+	right, rok := e.operandValue(r.Right, tick)
+	last := *prev
+	*prev = ruleValues{left: left, right: right, ok: lok && rok}
+	if !lok || !rok {
+		return false
+	}
+
+	switch r.Operator {
+	case ">":
+		return left > right
+	case "<":
+		return left < right
+	case ">=":
+		return left >= right
+	case "<=":
+		return left <= right
+	case "crosses_above":
+		return last.ok && last.left <= last.right && left > right
+	case "crosses_below":
+		return last.ok && last.left >= last.right && left < right
+	}
+	return false
+}
+
+func (e *evaluator) operandValue(op Operand, tick tickstore.Tick) (float64, bool) {
+	switch strings.ToLower(op.Indicator) {
+	case "price":
+		return (tick.Bid + tick.Ask) / 2, true
+	case "bid":
+		return tick.Bid, true
+	case "ask":
+		return tick.Ask, true
+	case "value":
+		return op.Value, true
+	}
+	ind, ok := e.indicators[op.key()]
+	if !ok {
+		return 0, false
+	}
+	return ind.value()
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
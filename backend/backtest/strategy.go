@@ -0,0 +1,197 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// StrategySpec defines a rule-based strategy. The runner holds at most one
+// position at a time: when flat and all entry rules hold it opens Side/Volume,
+// and while open it closes on SL/TP or when any exit rule holds.
+type StrategySpec struct {
+	Side           string  `json:"side"`   // BUY or SELL
+	Volume         float64 `json:"volume"` // Lots per trade
+	Entry          []Rule  `json:"entry"`  // All must hold; empty means enter whenever flat
+	Exit           []Rule  `json:"exit"`   // Any one closes the position
+	StopLossPips   float64 `json:"stopLossPips,omitempty"`
+	TakeProfitPips float64 `json:"takeProfitPips,omitempty"`
+	Timeframe      int64   `json:"timeframe,omitempty"` // Indicator bar size in seconds (0 = every tick)
+}
+
+// Rule compares two operands
+type Rule struct {
+	Left     Operand `json:"left"`
+	Operator string  `json:"operator"` // >, <, >=, <=, crosses_above, crosses_below
+	Right    Operand `json:"right"`
+}
+
+// Operand is a price, indicator or constant value
+type Operand struct {
+	Indicator string  `json:"indicator"` // price (mid), bid, ask, sma, ema, rsi, value
+	Period    int     `json:"period,omitempty"`
+	Value     float64 `json:"value,omitempty"` // Constant when Indicator is "value"
+}
+
+// Validate checks the spec for unsupported sides, operators and indicators
+func (s *StrategySpec) Validate() error {
+	s.Side = strings.ToUpper(s.Side)
+	if s.Side != "BUY" && s.Side != "SELL" {
+		return errors.New("side must be BUY or SELL")
+	}
+	if s.Volume <= 0 {
+		return errors.New("volume must be positive")
+	}
+	if s.StopLossPips < 0 || s.TakeProfitPips < 0 {
+		return errors.New("stop loss and take profit must not be negative")
+	}
+	if s.Timeframe < 0 {
+		return errors.New("timeframe must not be negative")
+	}
+
+	for _, rules := range [][]Rule{s.Entry, s.Exit} {
+		for _, r := range rules {
+			switch r.Operator {
+			case ">", "<", ">=", "<=", "crosses_above", "crosses_below":
+			default:
+				return fmt.Errorf("unsupported operator: %s", r.Operator)
+			}
+			for _, op := range []Operand{r.Left, r.Right} {
+				if err := op.validate(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (o Operand) validate() error {
+	switch strings.ToLower(o.Indicator) {
+	case "price", "bid", "ask", "value":
+		return nil
+	case "sma", "ema", "rsi":
+		if o.Period <= 0 || o.Period > 1000 {
+			return fmt.Errorf("%s period must be between 1 and 1000", o.Indicator)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported indicator: %s", o.Indicator)
+	}
+}
+
+func (o Operand) key() string {
+	return fmt.Sprintf("%s:%d", strings.ToLower(o.Indicator), o.Period)
+}
+
+// indicator is updated with each closed bar (or tick when Timeframe is 0)
+type indicator interface {
+	update(close float64)
+	value() (float64, bool) // false until enough data
+}
+
+func newIndicator(op Operand) indicator {
+	switch strings.ToLower(op.Indicator) {
+	case "sma":
+		return &sma{period: op.Period}
+	case "ema":
+		return &ema{period: op.Period, k: 2.0 / float64(op.Period+1)}
+	case "rsi":
+		return &rsi{period: op.Period}
+	}
+	return nil
+}
+
+// sma is a simple moving average over a sliding window
+type sma struct {
+	period int
+	window []float64
+	sum    float64
+}
+
+func (s *sma) update(close float64) {
+	s.window = append(s.window, close)
+	s.sum += close
+	if len(s.window) > s.period {
+		s.sum -= s.window[0]
+		s.window = s.window[1:]
+	}
+}
+
+func (s *sma) value() (float64, bool) {
+	if len(s.window) < s.period {
+		return 0, false
+	}
+	return s.sum / float64(s.period), true
+}
+
+// ema is an exponential moving average seeded with the SMA of the first period values
+type ema struct {
+	period int
+	k      float64
+	count  int
+	sum    float64
+	ema    float64
+}
+
+func (e *ema) update(close float64) {
+	e.count++
+	if e.count <= e.period {
+		e.sum += close
+		if e.count == e.period {
+			e.ema = e.sum / float64(e.period)
+		}
+		return
+	}
+	e.ema = close*e.k + e.ema*(1-e.k)
+}
+
+func (e *ema) value() (float64, bool) {
+	return e.ema, e.count >= e.period
+}
+
+// rsi is Wilder's relative strength index
+type rsi struct {
+	period  int
+	count   int
+	prev    float64
+	avgGain float64
+	avgLoss float64
+}
+
+func (r *rsi) update(close float64) {
+	if r.count == 0 {
+		r.prev = close
+		r.count++
+		return
+	}
+
+	change := close - r.prev
+	r.prev = close
+	gain, loss := 0.0, 0.0
+	if change > 0 {
+		gain = change
+	} else {
+		loss = -change
+	}
+
+	if r.count <= r.period {
+		r.avgGain += gain / float64(r.period)
+		r.avgLoss += loss / float64(r.period)
+	} else {
+		r.avgGain = (r.avgGain*float64(r.period-1) + gain) / float64(r.period)
+		r.avgLoss = (r.avgLoss*float64(r.period-1) + loss) / float64(r.period)
+	}
+	r.count++
+}
+
+func (r *rsi) value() (float64, bool) {
+	if r.count <= r.period {
+		return 0, false
+	}
+	if r.avgLoss == 0 {
+		return 100, true
+	}
+	rs := r.avgGain / r.avgLoss
+	return 100 - 100/(1+rs), true
+}
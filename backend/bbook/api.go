@@ -23,16 +23,8 @@ func NewAPIHandler(engine *Engine, pnlEngine *PnLEngine) *APIHandler {
 	}
 }
 
-// cors adds CORS headers
-func cors(w http.ResponseWriter) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-}
-
 // HandleGetAccountSummary returns account balance/equity/margin
 func (h *APIHandler) HandleGetAccountSummary(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -58,7 +50,6 @@ func (h *APIHandler) HandleGetAccountSummary(w http.ResponseWriter, r *http.Requ
 
 // HandleGetPositions returns open positions
 func (h *APIHandler) HandleGetPositions(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -82,7 +73,6 @@ func (h *APIHandler) HandleGetPositions(w http.ResponseWriter, r *http.Request)
 
 // HandleGetOrders returns orders
 func (h *APIHandler) HandleGetOrders(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -107,7 +97,6 @@ func (h *APIHandler) HandleGetOrders(w http.ResponseWriter, r *http.Request) {
 
 // HandlePlaceMarketOrder executes a market order
 func (h *APIHandler) HandlePlaceMarketOrder(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -152,7 +141,6 @@ func (h *APIHandler) HandlePlaceMarketOrder(w http.ResponseWriter, r *http.Reque
 
 // HandleClosePosition closes a position
 func (h *APIHandler) HandleClosePosition(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -200,7 +188,6 @@ func (h *APIHandler) HandleClosePosition(w http.ResponseWriter, r *http.Request)
 
 // HandleCloseBulk closes multiple positions based on filter
 func (h *APIHandler) HandleCloseBulk(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -274,7 +261,6 @@ func (h *APIHandler) HandleCloseBulk(w http.ResponseWriter, r *http.Request) {
 
 // HandleModifyPosition modifes SL/TP
 func (h *APIHandler) HandleModifyPosition(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -318,7 +304,6 @@ func (h *APIHandler) HandleModifyPosition(w http.ResponseWriter, r *http.Request
 
 // HandleGetTrades returns trade history
 func (h *APIHandler) HandleGetTrades(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -342,7 +327,6 @@ func (h *APIHandler) HandleGetTrades(w http.ResponseWriter, r *http.Request) {
 
 // HandleGetLedger returns ledger history
 func (h *APIHandler) HandleGetLedger(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -375,7 +359,6 @@ func (h *APIHandler) HandleGetLedger(w http.ResponseWriter, r *http.Request) {
 
 // HandleAdminDeposit adds funds to an account
 func (h *APIHandler) HandleAdminDeposit(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -430,7 +413,6 @@ func (h *APIHandler) HandleAdminDeposit(w http.ResponseWriter, r *http.Request)
 
 // HandleAdminWithdraw removes funds from an account
 func (h *APIHandler) HandleAdminWithdraw(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -457,22 +439,18 @@ func (h *APIHandler) HandleAdminWithdraw(w http.ResponseWriter, r *http.Request)
 		req.Description = "Withdrawal via " + req.Method
 	}
 
-	// Get account to update balance
-	account, ok := h.engine.GetAccount(req.AccountID)
-	if !ok {
+	if _, ok := h.engine.GetAccount(req.AccountID); !ok {
 		http.Error(w, "Account not found", http.StatusNotFound)
 		return
 	}
 
-	entry, err := h.engine.GetLedger().Withdraw(req.AccountID, req.Amount, req.Method, req.Reference, req.Description, req.AdminID)
+	// Margin in use is not withdrawable
+	entry, err := h.engine.Withdraw(req.AccountID, req.Amount, req.Method, req.Reference, req.Description, req.AdminID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Update account balance
-	account.Balance = entry.BalanceAfter
-
 	log.Printf("[ADMIN] Withdraw: Account #%d -%.2f %s by %s", req.AccountID, req.Amount, req.Method, req.AdminID)
 
 	w.Header().Set("Content-Type", "application/json")
@@ -485,7 +463,6 @@ func (h *APIHandler) HandleAdminWithdraw(w http.ResponseWriter, r *http.Request)
 
 // HandleAdminAdjust makes a balance adjustment
 func (h *APIHandler) HandleAdminAdjust(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -531,7 +508,6 @@ func (h *APIHandler) HandleAdminAdjust(w http.ResponseWriter, r *http.Request) {
 
 // HandleAdminBonus adds a bonus
 func (h *APIHandler) HandleAdminBonus(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -581,7 +557,6 @@ func (h *APIHandler) HandleAdminBonus(w http.ResponseWriter, r *http.Request) {
 
 // HandleAdminGetAccounts returns all accounts
 func (h *APIHandler) HandleAdminGetAccounts(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -606,7 +581,6 @@ func (h *APIHandler) HandleAdminGetAccounts(w http.ResponseWriter, r *http.Reque
 
 // HandleAdminGetLedgerAll returns all ledger entries
 func (h *APIHandler) HandleAdminGetLedgerAll(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -638,7 +612,6 @@ func (h *APIHandler) HandleAdminGetLedgerAll(w http.ResponseWriter, r *http.Requ
 
 // HandleCreateAccount creates a new account
 func (h *APIHandler) HandleCreateAccount(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -668,7 +641,6 @@ func (h *APIHandler) HandleCreateAccount(w http.ResponseWriter, r *http.Request)
 
 // HandleAdminResetPassword resets an account password
 func (h *APIHandler) HandleAdminResetPassword(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -701,7 +673,6 @@ func (h *APIHandler) HandleAdminResetPassword(w http.ResponseWriter, r *http.Req
 
 // HandleAdminUpdateAccount updates account configuration
 func (h *APIHandler) HandleAdminUpdateAccount(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -730,7 +701,6 @@ func (h *APIHandler) HandleAdminUpdateAccount(w http.ResponseWriter, r *http.Req
 
 // HandleGetSymbols returns all symbols
 func (h *APIHandler) HandleGetSymbols(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
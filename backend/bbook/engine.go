@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"sync"
 	"time"
@@ -345,6 +346,33 @@ func (e *Engine) GetAccountSummary(accountID int64) (*AccountSummary, error) {
 	}, nil
 }
 
+// Withdraw debits a withdrawal through the ledger if it is within the balance
+// and the free margin. The check and the debit share the engine lock, so a
+// concurrent withdrawal or order cannot spend the same free margin.
+func (e *Engine) Withdraw(accountID int64, amount float64, method, ref, description, adminID string) (*LedgerEntry, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	account, ok := e.accounts[accountID]
+	if !ok {
+		return nil, errors.New("account not found")
+	}
+	summary, err := e.getAccountSummaryUnlocked(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if withdrawable := math.Max(0, math.Min(account.Balance, summary.FreeMargin)); amount > withdrawable {
+		return nil, fmt.Errorf("withdrawal of %.2f exceeds withdrawable balance %.2f", amount, withdrawable)
+	}
+
+	entry, err := e.ledger.Withdraw(accountID, amount, method, ref, description, adminID)
+	if err != nil {
+		return nil, err
+	}
+	account.Balance = entry.BalanceAfter
+	return entry, nil
+}
+
 // ExecuteMarketOrder executes a market order
 func (e *Engine) ExecuteMarketOrder(accountID int64, symbol, side string, volume, sl, tp float64) (*Position, error) {
 	e.mu.Lock()
@@ -308,6 +308,35 @@ func TestExecuteMarketOrderInsufficientMargin(t *testing.T) {
 	}
 }
 
+// TestWithdrawKeepsMarginInUse tests that a withdrawal cannot take the margin
+// held by open positions
+func TestWithdrawKeepsMarginInUse(t *testing.T) {
+	engine := NewEngine()
+	account := engine.CreateAccount("user1", "trader1", "pass1", false)
+	entry, err := engine.GetLedger().Deposit(account.ID, 2000, "BANK", "ref-1", "Deposit", "admin")
+	if err != nil {
+		t.Fatalf("Deposit() error = %v", err)
+	}
+	account.Balance = entry.BalanceAfter
+
+	engine.SetPriceCallback(func(symbol string) (bid, ask float64, ok bool) {
+		return 1.1000, 1.1002, true
+	})
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 1.0, 0, 0); err != nil {
+		t.Fatalf("Failed to open position: %v", err)
+	}
+
+	if _, err := engine.Withdraw(account.ID, 1500, "BANK", "", "Withdrawal", "admin"); err == nil {
+		t.Error("withdrawal of margin in use allowed")
+	}
+	if _, err := engine.Withdraw(account.ID, 500, "BANK", "", "Withdrawal", "admin"); err != nil {
+		t.Errorf("Withdraw(500) error = %v", err)
+	}
+	if account.Balance != 1500 {
+		t.Errorf("Balance = %.2f, want 1500", account.Balance)
+	}
+}
+
 // TestClosePosition tests position closing
 func TestClosePosition(t *testing.T) {
 	engine := NewEngine()
@@ -57,6 +57,7 @@ func (h *APIHandlers) RegisterRoutes(mux *http.ServeMux) {
 
 	// Admin
 	mux.HandleFunc("/api/cbook/admin/config", h.handleAdminConfig)
+	mux.HandleFunc("/api/cbook/admin/execution-modes", h.handleExecutionModes)
 }
 
 // handleGetProfiles returns all client profiles
@@ -504,6 +505,44 @@ func (h *APIHandlers) handleAdminConfig(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// handleExecutionModes lists the global and symbol execution modes, or sets
+// one: a symbol's when the body names a symbol (empty mode removes it), else
+// the global default
+func (h *APIHandlers) handleExecutionModes(w http.ResponseWriter, r *http.Request) {
+	modes := h.engine.GetExecutionModes()
+
+	switch r.Method {
+	case http.MethodGet:
+		respondJSON(w, map[string]interface{}{
+			"global":  modes.Global(),
+			"symbols": modes.Symbols(),
+		})
+
+	case http.MethodPost:
+		var req SymbolExecutionMode
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+
+		var err error
+		if req.Symbol != "" {
+			err = modes.SetSymbol(req.Symbol, req.Mode, req.Force)
+		} else {
+			err = modes.SetGlobal(req.Mode)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		respondJSON(w, map[string]string{"status": "updated"})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
 // Helper function to respond with JSON
 func respondJSON(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -22,6 +22,8 @@ type CBookEngine struct {
 	mlEnabled        bool
 	autoLearn        bool
 	strictCompliance bool
+	splitConfig      SplitConfig
+	executionModes   *ExecutionModes // Group, symbol and global A/B-Book overrides
 
 	// Statistics
 	totalDecisions   int64
@@ -45,6 +47,8 @@ func NewCBookEngine() *CBookEngine {
 		mlEnabled:        true,
 		autoLearn:        true,
 		strictCompliance: true,
+		splitConfig:      DefaultSplitConfig(),
+		executionModes:   NewExecutionModes(),
 		startTime:        time.Now(),
 	}
 
@@ -99,7 +103,13 @@ func (cbe *CBookEngine) RouteOrder(
 		}
 	}
 
-	// 5. Compliance check and audit logging
+	// 5. Execution mode overrides have the final say on the book
+	applyExecutionMode(decision, cbe.executionModes.Resolve(accountID, symbol), cbe.routingEngine.defaultLP)
+
+	// 6. Split a partial hedge into A-Book and B-Book legs
+	decision.Split = splitOrder(cbe.GetSplitConfig(), decision, volume)
+
+	// 7. Compliance check and audit logging
 	if cbe.strictCompliance {
 		cbe.compliance.LogRoutingDecision(
 			accountID, username, symbol, side, volume,
@@ -156,6 +166,11 @@ func (cbe *CBookEngine) RecordTradeOutcome(accountID int64, tradeID int64, decis
 
 	cbe.analytics.RecordTradeOutcome(accountID, username, decision, outcome.RealizedPnL)
 
+	// 3. Attribute the result to the routing rule that made the decision
+	if decision != nil && decision.RuleID != "" {
+		cbe.routingEngine.RecordRuleOutcome(decision.RuleID, outcome.RealizedPnL)
+	}
+
 	log.Printf("[C-Book] Recorded outcome for account %d: PnL=%.2f, Optimal=%v",
 		accountID, outcome.RealizedPnL, outcome.WasOptimal)
 }
@@ -400,6 +415,11 @@ func (cbe *CBookEngine) GetRoutingEngine() *RoutingEngine {
 	return cbe.routingEngine
 }
 
+// GetExecutionModes returns the group, symbol and global execution mode overrides
+func (cbe *CBookEngine) GetExecutionModes() *ExecutionModes {
+	return cbe.executionModes
+}
+
 // GetDecisionHistory returns recent routing decisions for analytics
 func (cbe *CBookEngine) GetDecisionHistory(limit int) []RoutingDecision {
 	return cbe.routingEngine.GetDecisionHistory(limit)
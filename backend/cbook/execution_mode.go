@@ -0,0 +1,181 @@
+package cbook
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Execution modes. HYBRID leaves the book to the routing engine.
+const (
+	ExecutionModeABook  = "ABOOK"
+	ExecutionModeBBook  = "BBOOK"
+	ExecutionModeHybrid = "HYBRID"
+)
+
+// Where a resolved execution mode came from
+const (
+	ExecutionModeSourceGroup  = "GROUP"
+	ExecutionModeSourceSymbol = "SYMBOL"
+	ExecutionModeSourceGlobal = "GLOBAL"
+)
+
+// SymbolExecutionMode overrides the book of every order on a symbol. A forced
+// override also wins over the account group's mode.
+type SymbolExecutionMode struct {
+	Symbol string `json:"symbol"`
+	Mode   string `json:"mode"`            // ABOOK, BBOOK or HYBRID
+	Force  bool   `json:"force,omitempty"` // Win over the group's mode
+}
+
+// ExecutionModeResolution is the execution mode applied to an order
+type ExecutionModeResolution struct {
+	Mode   string `json:"mode"`
+	Source string `json:"source"` // GROUP, SYMBOL or GLOBAL
+}
+
+// GroupExecutionModeResolver returns the execution mode of an account's group,
+// or false when the group leaves it to the symbol and global modes
+type GroupExecutionModeResolver func(accountID int64) (string, bool)
+
+// NormalizeExecutionMode validates an execution mode name
+func NormalizeExecutionMode(mode string) (string, error) {
+	switch normalized := strings.ToUpper(mode); normalized {
+	case ExecutionModeABook, ExecutionModeBBook, ExecutionModeHybrid:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("invalid execution mode %q: must be %s, %s or %s",
+			mode, ExecutionModeABook, ExecutionModeBBook, ExecutionModeHybrid)
+	}
+}
+
+// ExecutionModes resolves the book of an order from the account group's mode,
+// then the symbol's, then the global default
+type ExecutionModes struct {
+	mu            sync.RWMutex
+	global        string
+	symbols       map[string]SymbolExecutionMode
+	groupResolver GroupExecutionModeResolver
+}
+
+// NewExecutionModes creates execution mode overrides with a HYBRID default
+func NewExecutionModes() *ExecutionModes {
+	return &ExecutionModes{
+		global:  ExecutionModeHybrid,
+		symbols: make(map[string]SymbolExecutionMode),
+	}
+}
+
+// SetGlobal sets the execution mode of orders without a group or symbol override
+func (m *ExecutionModes) SetGlobal(mode string) error {
+	normalized, err := NormalizeExecutionMode(mode)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.global = normalized
+	return nil
+}
+
+// Global returns the execution mode of orders without a group or symbol override
+func (m *ExecutionModes) Global() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.global
+}
+
+// SetSymbol sets a symbol's execution mode override. An empty mode removes it.
+func (m *ExecutionModes) SetSymbol(symbol, mode string, force bool) error {
+	if symbol == "" {
+		return fmt.Errorf("symbol is required")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if mode == "" {
+		delete(m.symbols, symbol)
+		log.Printf("[C-Book] Execution mode override removed for %s", symbol)
+		return nil
+	}
+	normalized, err := NormalizeExecutionMode(mode)
+	if err != nil {
+		return err
+	}
+	m.symbols[symbol] = SymbolExecutionMode{Symbol: symbol, Mode: normalized, Force: force}
+	log.Printf("[C-Book] Execution mode for %s set to %s (force=%v)", symbol, normalized, force)
+	return nil
+}
+
+// Symbols returns the symbol overrides sorted by symbol
+func (m *ExecutionModes) Symbols() []SymbolExecutionMode {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	overrides := make([]SymbolExecutionMode, 0, len(m.symbols))
+	for _, override := range m.symbols {
+		overrides = append(overrides, override)
+	}
+	sort.Slice(overrides, func(i, j int) bool { return overrides[i].Symbol < overrides[j].Symbol })
+	return overrides
+}
+
+// SetGroupResolver sets the lookup of account group execution modes
+func (m *ExecutionModes) SetGroupResolver(fn GroupExecutionModeResolver) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.groupResolver = fn
+}
+
+// Resolve returns the execution mode of an order: a forced symbol override,
+// else the account group's mode, else the symbol's, else the global default.
+// HYBRID overrides are skipped so a lower level still applies.
+func (m *ExecutionModes) Resolve(accountID int64, symbol string) ExecutionModeResolution {
+	m.mu.RLock()
+	override, hasSymbol := m.symbols[symbol]
+	global := m.global
+	resolver := m.groupResolver
+	m.mu.RUnlock()
+
+	if hasSymbol && override.Force && override.Mode != ExecutionModeHybrid {
+		return ExecutionModeResolution{Mode: override.Mode, Source: ExecutionModeSourceSymbol}
+	}
+	if resolver != nil {
+		if mode, ok := resolver(accountID); ok {
+			if normalized, err := NormalizeExecutionMode(mode); err == nil && normalized != ExecutionModeHybrid {
+				return ExecutionModeResolution{Mode: normalized, Source: ExecutionModeSourceGroup}
+			}
+		}
+	}
+	if hasSymbol && override.Mode != ExecutionModeHybrid {
+		return ExecutionModeResolution{Mode: override.Mode, Source: ExecutionModeSourceSymbol}
+	}
+	return ExecutionModeResolution{Mode: global, Source: ExecutionModeSourceGlobal}
+}
+
+// applyExecutionMode sends a decision wholly to the book its execution mode
+// names. HYBRID and rejected orders keep the routing engine's decision.
+func applyExecutionMode(decision *RoutingDecision, resolved ExecutionModeResolution, defaultLP string) {
+	decision.ExecutionMode = &resolved
+	if decision.Action == ActionReject {
+		return
+	}
+
+	switch resolved.Mode {
+	case ExecutionModeABook:
+		decision.Action = ActionABook
+		decision.ABookPercent, decision.BBookPercent = 100, 0
+		if decision.TargetLP == "" {
+			decision.TargetLP = defaultLP
+		}
+	case ExecutionModeBBook:
+		decision.Action = ActionBBook
+		decision.ABookPercent, decision.BBookPercent = 0, 100
+		decision.TargetLP = ""
+	default:
+		return
+	}
+	decision.Reason = fmt.Sprintf("Execution mode %s (%s override)", resolved.Mode, strings.ToLower(resolved.Source))
+}
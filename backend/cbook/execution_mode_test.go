@@ -0,0 +1,67 @@
+package cbook
+
+import "testing"
+
+// TestExecutionModePrecedence tests that a forced symbol override beats the
+// account group's mode, and that the group's mode beats a plain symbol override
+func TestExecutionModePrecedence(t *testing.T) {
+	engine := NewCBookEngine()
+	modes := engine.GetExecutionModes()
+	groups := map[int64]string{1: ExecutionModeBBook, 2: ExecutionModeABook}
+	modes.SetGroupResolver(func(accountID int64) (string, bool) {
+		mode, ok := groups[accountID]
+		return mode, ok
+	})
+
+	// A symbol forced to A-Book overrides a group set to B-Book
+	if err := modes.SetSymbol("XAUUSD", ExecutionModeABook, true); err != nil {
+		t.Fatalf("SetSymbol() error = %v", err)
+	}
+	decision, err := engine.RouteOrder(1, "u1", "bbook-group", "XAUUSD", "BUY", 1, 0)
+	if err != nil {
+		t.Fatalf("RouteOrder() error = %v", err)
+	}
+	if decision.Action != ActionABook || decision.ExecutionMode.Source != ExecutionModeSourceSymbol {
+		t.Errorf("forced A-Book symbol in a B-Book group routed %s via %+v, want A_BOOK via SYMBOL", decision.Action, decision.ExecutionMode)
+	}
+
+	// A group set to A-Book overrides a symbol set to B-Book
+	modes.SetSymbol("EURUSD", ExecutionModeBBook, false)
+	decision, _ = engine.RouteOrder(2, "u2", "abook-group", "EURUSD", "BUY", 1, 0)
+	if decision.Action != ActionABook || decision.ExecutionMode.Source != ExecutionModeSourceGroup {
+		t.Errorf("B-Book symbol in an A-Book group routed %s via %+v, want A_BOOK via GROUP", decision.Action, decision.ExecutionMode)
+	}
+
+	// Without a group the symbol's override applies, then the global default
+	decision, _ = engine.RouteOrder(3, "u3", "no-group", "EURUSD", "BUY", 1, 0)
+	if decision.Action != ActionBBook || decision.ExecutionMode.Source != ExecutionModeSourceSymbol {
+		t.Errorf("B-Book symbol without a group routed %s via %+v, want B_BOOK via SYMBOL", decision.Action, decision.ExecutionMode)
+	}
+	modes.SetGlobal(ExecutionModeABook)
+	decision, _ = engine.RouteOrder(3, "u3", "no-group", "GBPUSD", "BUY", 1, 0)
+	if decision.Action != ActionABook || decision.ExecutionMode.Source != ExecutionModeSourceGlobal {
+		t.Errorf("unset symbol without a group routed %s via %+v, want A_BOOK via GLOBAL", decision.Action, decision.ExecutionMode)
+	}
+}
+
+// TestExecutionModeHybridDefersToRouter tests that HYBRID keeps the routing
+// engine's decision and that removing an override restores the default
+func TestExecutionModeHybridDefersToRouter(t *testing.T) {
+	engine := NewCBookEngine()
+	modes := engine.GetExecutionModes()
+
+	// Unclassified clients get a partial hedge from the routing engine
+	decision, _ := engine.RouteOrder(1, "u1", "new", "EURUSD", "BUY", 1, 0)
+	if decision.ExecutionMode.Mode != ExecutionModeHybrid || decision.Action != ActionPartialHedge {
+		t.Errorf("HYBRID decision = %+v, want the routing engine's partial hedge", decision)
+	}
+
+	modes.SetSymbol("EURUSD", ExecutionModeBBook, false)
+	modes.SetSymbol("EURUSD", "", false)
+	if overrides := modes.Symbols(); len(overrides) != 0 {
+		t.Errorf("Symbols() after removal = %+v, want none", overrides)
+	}
+	if err := modes.SetSymbol("EURUSD", "CBOOK", false); err == nil {
+		t.Error("SetSymbol() accepted an unknown mode")
+	}
+}
@@ -22,6 +22,7 @@ const (
 // RoutingDecision represents the routing decision for an order
 type RoutingDecision struct {
 	Action         RoutingAction `json:"action"`
+	RuleID         string        `json:"ruleId,omitempty"` // Set when a manual rule made the decision
 	TargetLP       string        `json:"targetLp,omitempty"`
 	ABookPercent   float64       `json:"aBookPercent"`   // 0-100
 	BBookPercent   float64       `json:"bBookPercent"`   // 0-100
@@ -29,6 +30,8 @@ type RoutingDecision struct {
 	ToxicityScore  float64       `json:"toxicityScore"`
 	ExposureRisk   float64       `json:"exposureRisk"`
 	DecisionTime   time.Time     `json:"decisionTime"`
+	Split          *OrderSplit   `json:"split,omitempty"` // Leg volumes when order splitting is enabled
+	ExecutionMode  *ExecutionModeResolution `json:"executionMode,omitempty"` // Mode the order resolved to
 }
 
 // ExposureLimit defines risk limits per instrument
@@ -89,6 +92,7 @@ type RoutingEngine struct {
 	// Analytics
 	decisions      []RoutingDecision
 	maxDecisions   int // Keep last N decisions
+	ruleStats      map[string]*RuleStats
 }
 
 // SymbolExposure tracks current exposure for a symbol
@@ -110,6 +114,7 @@ func NewRoutingEngine(profileEngine *ClientProfileEngine) *RoutingEngine {
 		symbolExposure:      make(map[string]*SymbolExposure),
 		decisions:           make([]RoutingDecision, 0, 10000),
 		maxDecisions:        10000,
+		ruleStats:           make(map[string]*RuleStats),
 		defaultLP:           "LMAX_PROD",
 		defaultHedgePercent: 70, // Default: 70% A-Book, 30% B-Book
 		maxBBookExposure:    1000, // 1000 lots
@@ -291,6 +296,7 @@ func (re *RoutingEngine) checkRules(accountID int64, symbol string, volume float
 		// Rule matched - create decision
 		decision := &RoutingDecision{
 			Action:       rule.Action,
+			RuleID:       rule.ID,
 			TargetLP:     rule.TargetLP,
 			DecisionTime: time.Now(),
 			Reason:       fmt.Sprintf("Matched rule: %s (%s)", rule.ID, rule.Description),
@@ -309,6 +315,8 @@ func (re *RoutingEngine) checkRules(accountID int64, symbol string, volume float
 			decision.ToxicityScore = profile.ToxicityScore
 		}
 
+		re.recordRuleMatch(rule.ID, volume, decision)
+
 		return decision
 	}
 
@@ -0,0 +1,309 @@
+package cbook
+
+import (
+	"bufio"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RuleStats holds cumulative effectiveness counters of a routing rule
+type RuleStats struct {
+	RuleID      string  `json:"ruleId"`
+	MatchCount  int64   `json:"matchCount"`
+	ABookVolume float64 `json:"aBookVolume"`
+	BBookVolume float64 `json:"bBookVolume"`
+	TradeCount  int64   `json:"tradeCount"`
+	RealizedPnL float64 `json:"realizedPnL"` // Client P/L of trades routed by the rule
+}
+
+// RuleSnapshot is the change in a rule's counters over one snapshot period
+type RuleSnapshot struct {
+	RuleID      string    `json:"ruleId"`
+	Timestamp   time.Time `json:"timestamp"`
+	Matches     int64     `json:"matches"`
+	ABookVolume float64   `json:"aBookVolume"`
+	BBookVolume float64   `json:"bBookVolume"`
+	Trades      int64     `json:"trades"`
+	RealizedPnL float64   `json:"realizedPnL"`
+}
+
+// RuleSeriesPoint is one bucket of a rule effectiveness time series
+type RuleSeriesPoint struct {
+	Timestamp    int64   `json:"timestamp"` // Bucket start (unix seconds)
+	Matches      int64   `json:"matches"`
+	ABookVolume  float64 `json:"abook_volume"`
+	BBookVolume  float64 `json:"bbook_volume"`
+	ABookPercent float64 `json:"abook_pct"`
+	BBookPercent float64 `json:"bbook_pct"`
+	Trades       int64   `json:"trades"`
+	RealizedPnL  float64 `json:"realized_pnl"`
+}
+
+// recordRuleMatch counts a rule match and its A/B split (caller must hold lock)
+func (re *RoutingEngine) recordRuleMatch(ruleID string, volume float64, decision *RoutingDecision) {
+	stats := re.ruleStatsUnlocked(ruleID)
+	stats.MatchCount++
+	stats.ABookVolume += volume * decision.ABookPercent / 100
+	stats.BBookVolume += volume * decision.BBookPercent / 100
+}
+
+// RecordRuleOutcome attributes the realized P/L of a closed trade to the rule that routed it
+func (re *RoutingEngine) RecordRuleOutcome(ruleID string, realizedPnL float64) {
+	re.mu.Lock()
+	defer re.mu.Unlock()
+
+	stats := re.ruleStatsUnlocked(ruleID)
+	stats.TradeCount++
+	stats.RealizedPnL += realizedPnL
+}
+
+// GetRuleStats returns the cumulative counters of every rule that has matched
+func (re *RoutingEngine) GetRuleStats() []RuleStats {
+	re.mu.RLock()
+	defer re.mu.RUnlock()
+
+	result := make([]RuleStats, 0, len(re.ruleStats))
+	for _, stats := range re.ruleStats {
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].RuleID < result[j].RuleID
+	})
+	return result
+}
+
+// ruleStatsUnlocked returns the counters of a rule, creating them if needed (caller must hold lock)
+func (re *RoutingEngine) ruleStatsUnlocked(ruleID string) *RuleStats {
+	stats, ok := re.ruleStats[ruleID]
+	if !ok {
+		stats = &RuleStats{RuleID: ruleID}
+		re.ruleStats[ruleID] = stats
+	}
+	return stats
+}
+
+// RuleSnapshotStore keeps periodic rule effectiveness snapshots, persisted as
+// JSON lines so history survives restarts. Only the latest maxSnapshots are
+// kept; once the file holds twice that many lines it is rewritten with them.
+type RuleSnapshotStore struct {
+	mu           sync.RWMutex
+	path         string
+	snapshots    []RuleSnapshot
+	maxSnapshots int
+	lines        int                  // Lines in the file, including dropped ones
+	last         map[string]RuleStats // Counters at the previous snapshot
+}
+
+// NewRuleSnapshotStore creates a store persisting to path and loads existing
+// snapshots. An empty path keeps snapshots in memory only.
+func NewRuleSnapshotStore(path string) (*RuleSnapshotStore, error) {
+	s := &RuleSnapshotStore{
+		path:         path,
+		snapshots:    make([]RuleSnapshot, 0),
+		maxSnapshots: 100000,
+		last:         make(map[string]RuleStats),
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		s.lines++
+		var snap RuleSnapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snap); err != nil {
+			continue // Skip a torn last line
+		}
+		s.append(snap)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[RuleSnapshots] Loaded %d rule snapshots from %s", len(s.snapshots), path)
+	return s, nil
+}
+
+// Snapshot records how each rule's counters changed since the previous snapshot.
+// Rules without activity in the period are skipped.
+func (s *RuleSnapshotStore) Snapshot(re *RoutingEngine, now time.Time) error {
+	stats := re.GetRuleStats()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var taken []RuleSnapshot
+	for _, cur := range stats {
+		prev := s.last[cur.RuleID]
+		// Counters restart from zero after a process restart
+		if cur.MatchCount < prev.MatchCount || cur.TradeCount < prev.TradeCount {
+			prev = RuleStats{}
+		}
+		s.last[cur.RuleID] = cur
+
+		snap := RuleSnapshot{
+			RuleID:      cur.RuleID,
+			Timestamp:   now,
+			Matches:     cur.MatchCount - prev.MatchCount,
+			ABookVolume: cur.ABookVolume - prev.ABookVolume,
+			BBookVolume: cur.BBookVolume - prev.BBookVolume,
+			Trades:      cur.TradeCount - prev.TradeCount,
+			RealizedPnL: cur.RealizedPnL - prev.RealizedPnL,
+		}
+		if snap.Matches == 0 && snap.Trades == 0 {
+			continue
+		}
+		s.append(snap)
+		taken = append(taken, snap)
+	}
+
+	return s.persist(taken)
+}
+
+// Series returns the snapshots of a rule between from and to, summed into
+// buckets of the given interval
+func (s *RuleSnapshotStore) Series(ruleID string, from, to time.Time, interval time.Duration) []RuleSeriesPoint {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	buckets := make(map[int64]*RuleSeriesPoint)
+	for _, snap := range s.snapshots {
+		if snap.RuleID != ruleID || snap.Timestamp.Before(from) || snap.Timestamp.After(to) {
+			continue
+		}
+		start := snap.Timestamp.Truncate(interval).Unix()
+		point, ok := buckets[start]
+		if !ok {
+			point = &RuleSeriesPoint{Timestamp: start}
+			buckets[start] = point
+		}
+		point.Matches += snap.Matches
+		point.ABookVolume += snap.ABookVolume
+		point.BBookVolume += snap.BBookVolume
+		point.Trades += snap.Trades
+		point.RealizedPnL += snap.RealizedPnL
+	}
+
+	series := make([]RuleSeriesPoint, 0, len(buckets))
+	for _, point := range buckets {
+		if total := point.ABookVolume + point.BBookVolume; total > 0 {
+			point.ABookPercent = point.ABookVolume / total * 100
+			point.BBookPercent = point.BBookVolume / total * 100
+		}
+		series = append(series, *point)
+	}
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Timestamp < series[j].Timestamp
+	})
+	return series
+}
+
+// StartRuleSnapshots snapshots rule effectiveness every interval
+func (s *RuleSnapshotStore) StartRuleSnapshots(re *RoutingEngine, interval time.Duration) {
+	if interval <= 0 {
+		log.Printf("[RuleSnapshots] Snapshots disabled: interval %v must be positive", interval)
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			if err := s.Snapshot(re, now); err != nil {
+				log.Printf("[RuleSnapshots] Failed to persist snapshot: %v", err)
+			}
+		}
+	}()
+
+	log.Printf("[RuleSnapshots] Snapshotting rule effectiveness every %v", interval)
+}
+
+func (s *RuleSnapshotStore) append(snap RuleSnapshot) {
+	s.snapshots = append(s.snapshots, snap)
+	if len(s.snapshots) > s.maxSnapshots {
+		s.snapshots = s.snapshots[len(s.snapshots)-s.maxSnapshots:]
+	}
+}
+
+func (s *RuleSnapshotStore) persist(snaps []RuleSnapshot) error {
+	if s.path == "" || len(snaps) == 0 {
+		return nil
+	}
+	if s.lines+len(snaps) > 2*s.maxSnapshots {
+		return s.rotate()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	enc := json.NewEncoder(file)
+	for _, snap := range snaps {
+		if err := enc.Encode(snap); err != nil {
+			return err
+		}
+		s.lines++
+	}
+	return nil
+}
+
+// rotate rewrites the file with only the snapshots kept in memory, which
+// already include the ones being persisted. The file is replaced atomically
+// so a crash mid-write keeps the previous history.
+func (s *RuleSnapshotStore) rotate() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	for _, snap := range s.snapshots {
+		if err := enc.Encode(snap); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+
+	log.Printf("[RuleSnapshots] Rotated %s: %d lines -> %d", s.path, s.lines, len(s.snapshots))
+	s.lines = len(s.snapshots)
+	return nil
+}
@@ -0,0 +1,58 @@
+package cbook
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestRuleSnapshotsRotate tests that the JSONL file is rewritten with the
+// retained snapshots once it holds twice the in-memory cap, and that the
+// rotated file reloads to the same history
+func TestRuleSnapshotsRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rule_snapshots.jsonl")
+	store, err := NewRuleSnapshotStore(path)
+	if err != nil {
+		t.Fatalf("NewRuleSnapshotStore() error = %v", err)
+	}
+	store.maxSnapshots = 3
+
+	re := NewRoutingEngine(NewClientProfileEngine())
+	base := time.Unix(28333333*60, 0) // Minute aligned
+	lines := func() int {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bytes.Count(data, []byte("\n"))
+	}
+
+	for i := 0; i < 6; i++ {
+		re.RecordRuleOutcome("rule-1", 10)
+		if err := store.Snapshot(re, base.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("Snapshot() error = %v", err)
+		}
+	}
+	if got := lines(); got != 6 {
+		t.Fatalf("file lines = %d before rotation, want 6", got)
+	}
+
+	re.RecordRuleOutcome("rule-1", 10)
+	if err := store.Snapshot(re, base.Add(6*time.Minute)); err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if got := lines(); got != 3 {
+		t.Fatalf("file lines = %d after rotation, want the 3 retained", got)
+	}
+
+	reloaded, err := NewRuleSnapshotStore(path)
+	if err != nil {
+		t.Fatalf("reload NewRuleSnapshotStore() error = %v", err)
+	}
+	series := reloaded.Series("rule-1", base, base.Add(time.Hour), time.Minute)
+	if len(series) != 3 || series[0].Timestamp != base.Add(4*time.Minute).Unix() {
+		t.Errorf("reloaded series = %+v, want the last 3 minutes", series)
+	}
+}
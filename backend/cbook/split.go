@@ -0,0 +1,458 @@
+package cbook
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/epic1st/rtx/backend/fix"
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+// SplitConfig controls partial-hedge order splitting. When enabled, a
+// PARTIAL_HEDGE decision fills the client in full on the B-Book and sends the
+// A-Book share of the volume to the LP as a cover order.
+type SplitConfig struct {
+	Enabled      bool    `json:"enabled"`
+	LotStep      float64 `json:"lotStep"`      // Leg volumes are rounded to this step
+	MinLegVolume float64 `json:"minLegVolume"` // A leg smaller than this is folded into the other one
+}
+
+// DefaultSplitConfig returns splitting defaults (disabled)
+func DefaultSplitConfig() SplitConfig {
+	return SplitConfig{
+		Enabled:      false,
+		LotStep:      0.01,
+		MinLegVolume: 0.01,
+	}
+}
+
+// OrderSplit is the volume a routing decision sends to each book
+type OrderSplit struct {
+	ABookVolume float64 `json:"aBookVolume"`
+	BBookVolume float64 `json:"bBookVolume"`
+}
+
+// ChildExecution is one leg of a split client order. An A-Book leg's volume
+// is what the LP has reported filled, never just what was sent.
+type ChildExecution struct {
+	Book      RoutingAction `json:"book"`                // A_BOOK or B_BOOK
+	Requested float64       `json:"requested,omitempty"` // Sent to the LP, A-Book leg only
+	Volume    float64       `json:"volume"`
+	Price     float64       `json:"price,omitempty"`
+	OrderID   string        `json:"orderId,omitempty"` // LP order ID of the A-Book leg
+	Error     string        `json:"error,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// SplitExecution ties the child executions of one client order to the single
+// client position they make up
+type SplitExecution struct {
+	AccountID   int64            `json:"accountId"`
+	PositionID  int64            `json:"positionId"`
+	Symbol      string           `json:"symbol"`
+	Side        string           `json:"side"`
+	Volume      float64          `json:"volume"`      // Client position volume
+	OpenVolume  float64          `json:"openVolume"`  // Client volume not yet closed
+	CoverVolume float64          `json:"coverVolume"` // LP cover lots filled and not yet unwound
+	Legs        []ChildExecution `json:"legs"`
+	Reconciled  bool             `json:"reconciled"` // Leg volumes add up to the client volume
+
+	unwinding float64 // Cover lots being unwound
+}
+
+// ClientFillFunc books the client's position on the B-Book and returns its ID,
+// fill price and filled volume, which a partial fill may leave below volume
+type ClientFillFunc func(accountID int64, symbol, side string, volume float64) (positionID int64, price, filled float64, err error)
+
+// LPOrderFunc sends a market order to the LP and returns its order ID
+type LPOrderFunc func(symbol, side string, volume float64) (string, error)
+
+// SetSplitConfig sets how partial-hedge decisions are split between the books
+func (cbe *CBookEngine) SetSplitConfig(config SplitConfig) {
+	cbe.mu.Lock()
+	defer cbe.mu.Unlock()
+	cbe.splitConfig = config
+	log.Printf("[C-Book] Order splitting %s", map[bool]string{true: "enabled", false: "disabled"}[config.Enabled])
+}
+
+// GetSplitConfig returns how partial-hedge decisions are split between the books
+func (cbe *CBookEngine) GetSplitConfig() SplitConfig {
+	cbe.mu.RLock()
+	defer cbe.mu.RUnlock()
+	return cbe.splitConfig
+}
+
+// splitOrder divides volume between the books by the decision's percentages.
+// Returns nil unless splitting is enabled and the decision is a partial hedge.
+func splitOrder(config SplitConfig, decision *RoutingDecision, volume float64) *OrderSplit {
+	if !config.Enabled || decision.Action != ActionPartialHedge {
+		return nil
+	}
+
+	step := config.LotStep
+	if step <= 0 {
+		step = 0.01
+	}
+	aBook := math.Round(volume*decision.ABookPercent/100/step) * step
+	if aBook > volume {
+		aBook = volume
+	}
+	bBook := volume - aBook
+
+	// Fold a leg too small to execute into the other one
+	if aBook < config.MinLegVolume {
+		aBook, bBook = 0, volume
+	} else if bBook < config.MinLegVolume {
+		aBook, bBook = volume, 0
+	}
+	return &OrderSplit{ABookVolume: aBook, BBookVolume: bBook}
+}
+
+// BookVolume returns the volume the legs executed on one book
+func (se *SplitExecution) BookVolume(book RoutingAction) float64 {
+	var volume float64
+	for _, leg := range se.Legs {
+		if leg.Book == book {
+			volume += leg.Volume
+		}
+	}
+	return volume
+}
+
+// leg returns the execution's leg on book, adding an empty one if missing
+func (se *SplitExecution) leg(book RoutingAction) *ChildExecution {
+	for i := range se.Legs {
+		if se.Legs[i].Book == book {
+			return &se.Legs[i]
+		}
+	}
+	se.Legs = append(se.Legs, ChildExecution{Book: book, Timestamp: time.Now()})
+	return &se.Legs[len(se.Legs)-1]
+}
+
+// reconcile recomputes whether the legs add up to the client volume
+func (se *SplitExecution) reconcile() {
+	legVolume := se.BookVolume(ActionABook) + se.BookVolume(ActionBBook)
+	se.Reconciled = math.Abs(legVolume-se.Volume) < 1e-9
+}
+
+// coverOrder is an LP order of a split position awaiting execution reports:
+// its cover, or an unwind of the cover after the client closed
+type coverOrder struct {
+	execution *SplitExecution
+	unwind    bool
+	remaining float64
+	filled    float64
+}
+
+// SplitRouter executes split decisions: the client gets one position for the
+// full volume on the B-Book, and the A-Book share is covered at the LP. The
+// cover is unwound as the client position closes.
+type SplitRouter struct {
+	mu         sync.Mutex
+	lpOrder    LPOrderFunc
+	executions map[int64]*SplitExecution // Client position ID -> its legs, kept until closed and unwound
+	orders     map[string]*coverOrder    // LP order ID -> order awaiting reports
+	sending    int                       // LP orders inside lpOrder
+	early      []fix.ExecutionReport     // Reports that arrived while their order was being sent
+}
+
+// NewSplitRouter creates a split router sending A-Book legs through lpOrder
+func NewSplitRouter(lpOrder LPOrderFunc) *SplitRouter {
+	return &SplitRouter{
+		lpOrder:    lpOrder,
+		executions: make(map[int64]*SplitExecution),
+		orders:     make(map[string]*coverOrder),
+	}
+}
+
+// Execute fills a client order per its routing decision. The client position
+// is booked first through fill, and the LP never covers more than was filled.
+// The A-Book leg counts only what the LP reports filled; whatever the LP does
+// not fill is internalized on the B-Book, so the legs reconcile to the client
+// position once the cover is settled.
+func (sr *SplitRouter) Execute(accountID int64, symbol, side string, volume float64, decision *RoutingDecision, fill ClientFillFunc) (*SplitExecution, error) {
+	if decision.Action == ActionReject {
+		return nil, fmt.Errorf("order rejected by routing: %s", decision.Reason)
+	}
+	if decision.Split == nil {
+		return nil, errors.New("routing decision has no split")
+	}
+
+	positionID, price, filled, err := fill(accountID, symbol, side, volume)
+	if err != nil {
+		return nil, err
+	}
+
+	execution := &SplitExecution{
+		AccountID:  accountID,
+		PositionID: positionID,
+		Symbol:     symbol,
+		Side:       side,
+		Volume:     filled,
+		OpenVolume: filled,
+	}
+	aBook := math.Min(decision.Split.ABookVolume, filled)
+	if bBook := filled - aBook; bBook > 0 {
+		b := execution.leg(ActionBBook)
+		b.Volume, b.Price = bBook, price
+	}
+
+	var orderID string
+	if aBook > 0 {
+		a := ChildExecution{Book: ActionABook, Requested: aBook, Timestamp: time.Now()}
+		sr.mu.Lock()
+		sr.sending++
+		sr.mu.Unlock()
+		if sr.lpOrder == nil {
+			err = errors.New("no LP connection")
+		} else {
+			orderID, err = sr.lpOrder(symbol, side, aBook)
+		}
+		a.OrderID = orderID
+		if err != nil {
+			a.Error = err.Error()
+		}
+		execution.Legs = append([]ChildExecution{a}, execution.Legs...)
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if aBook > 0 {
+		sr.sending--
+		if err != nil {
+			log.Printf("[C-Book] A-Book leg of position #%d failed, internalizing %.2f lots: %v", positionID, aBook, err)
+			execution.leg(ActionBBook).Volume += aBook
+		} else {
+			sr.orders[orderID] = &coverOrder{execution: execution, remaining: aBook}
+			sr.replayEarlyLocked(orderID)
+		}
+		if sr.sending == 0 {
+			sr.early = nil
+		}
+	}
+	execution.reconcile()
+	sr.executions[positionID] = execution
+	sr.settleLocked(execution)
+
+	log.Printf("[C-Book] SPLIT: Account=%d %s %s %.2f lots -> position #%d (A-Book %.2f sent, B-Book %.2f)",
+		accountID, side, symbol, filled, positionID, aBook, execution.BookVolume(ActionBBook))
+	result := *execution
+	result.Legs = append([]ChildExecution(nil), execution.Legs...)
+	return &result, nil
+}
+
+// OnExecutionReport applies an LP execution report to the cover or unwind
+// order it belongs to. Reports of other orders are ignored.
+func (sr *SplitRouter) OnExecutionReport(report fix.ExecutionReport) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	id := splitReportID(report)
+	if _, ok := sr.orders[id]; !ok {
+		// The LP can answer before lpOrder has returned the order ID
+		if sr.sending > 0 {
+			sr.early = append(sr.early, report)
+		}
+		return
+	}
+	sr.applyReportLocked(id, report)
+}
+
+// splitReportID returns the order ID lpOrder returned for a report's order
+func splitReportID(report fix.ExecutionReport) string {
+	if report.ClOrdID != "" {
+		return report.ClOrdID
+	}
+	return report.OrderID
+}
+
+// replayEarlyLocked applies reports of orderID that arrived before it was
+// registered (caller must hold lock)
+func (sr *SplitRouter) replayEarlyLocked(orderID string) {
+	kept := sr.early[:0]
+	for _, report := range sr.early {
+		if splitReportID(report) == orderID {
+			sr.applyReportLocked(orderID, report)
+		} else {
+			kept = append(kept, report)
+		}
+	}
+	sr.early = kept
+}
+
+// applyReportLocked books a report's fill on the cover or unwind and settles
+// the order once it is done (caller must hold lock)
+func (sr *SplitRouter) applyReportLocked(orderID string, report fix.ExecutionReport) {
+	o, ok := sr.orders[orderID]
+	if !ok {
+		return
+	}
+	execution := o.execution
+
+	done := false
+	switch report.ExecType {
+	case "PARTIAL", "FILLED":
+		qty := math.Min(report.Volume, o.remaining)
+		if report.CumQty > 0 {
+			qty = math.Min(report.CumQty-o.filled, o.remaining)
+		}
+		if qty > 0 {
+			o.filled += qty
+			o.remaining -= qty
+			if o.unwind {
+				execution.CoverVolume -= qty
+				execution.unwinding -= qty
+			} else {
+				execution.CoverVolume += qty
+				execution.leg(ActionABook).Volume += qty
+			}
+		}
+		done = report.ExecType == "FILLED" || o.remaining <= 1e-9
+	case "REJECTED", "CANCELED":
+		done = true
+		if o.unwind {
+			execution.unwinding -= o.remaining
+			log.Printf("[C-Book] Unwind %s of position #%d %s: %.2f lots still covered at the LP: %s",
+				orderID, execution.PositionID, report.ExecType, o.remaining, report.Text)
+		} else {
+			execution.leg(ActionABook).Error = report.Text
+		}
+	default:
+		return
+	}
+	if !done {
+		return
+	}
+
+	delete(sr.orders, orderID)
+	if !o.unwind && o.remaining > 1e-9 {
+		// The LP did not fill the whole cover: the rest stays internalized
+		execution.leg(ActionBBook).Volume += o.remaining
+		log.Printf("[C-Book] Cover %s of position #%d filled %.2f lots, internalizing %.2f", orderID, execution.PositionID, o.filled, o.remaining)
+	}
+	execution.reconcile()
+	// A cover filling after the client closed is unwound straight away
+	if !o.unwind && execution.OpenVolume <= 1e-9 && o.filled > 0 {
+		execution.unwinding += o.filled
+		go sr.unwind(execution, o.filled)
+	}
+	sr.settleLocked(execution)
+}
+
+// HandleTradeEvent unwinds the LP cover of a split position in proportion to
+// what the client closed. It never blocks, so it can be subscribed directly to
+// the engine's event stream.
+func (sr *SplitRouter) HandleTradeEvent(event core.TradeEvent) {
+	if event.Type != core.TradeEventPositionClosed {
+		return
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	execution, ok := sr.executions[event.PositionID]
+	if !ok || execution.OpenVolume <= 1e-9 {
+		return
+	}
+
+	open := execution.OpenVolume
+	execution.OpenVolume = math.Max(event.RemainingVolume, 0)
+	// Covers still pending are unwound once filled
+	unwind := execution.CoverVolume - execution.unwinding
+	if execution.OpenVolume > 1e-9 {
+		unwind = math.Round(unwind*(open-execution.OpenVolume)/open*100) / 100
+	}
+	if unwind > 1e-9 {
+		execution.unwinding += unwind
+		go sr.unwind(execution, unwind)
+	}
+	sr.settleLocked(execution)
+}
+
+// unwind sends the order closing volume lots of an execution's LP cover
+func (sr *SplitRouter) unwind(execution *SplitExecution, volume float64) {
+	side := "SELL"
+	if execution.Side == "SELL" {
+		side = "BUY"
+	}
+
+	sr.mu.Lock()
+	sr.sending++
+	sr.mu.Unlock()
+
+	var orderID string
+	err := errors.New("no LP connection")
+	if sr.lpOrder != nil {
+		orderID, err = sr.lpOrder(execution.Symbol, side, volume)
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.sending--
+	if err != nil {
+		execution.unwinding -= volume
+		log.Printf("[C-Book] Failed to unwind %.2f lots of position #%d's cover: %v", volume, execution.PositionID, err)
+	} else {
+		sr.orders[orderID] = &coverOrder{execution: execution, unwind: true, remaining: volume}
+		sr.replayEarlyLocked(orderID)
+		log.Printf("[C-Book] Unwinding %.2f lots of position #%d's cover as %s", volume, execution.PositionID, orderID)
+	}
+	if sr.sending == 0 {
+		sr.early = nil
+	}
+	sr.settleLocked(execution)
+}
+
+// settleLocked forgets an execution once the client position is closed and no
+// LP order of it is outstanding. A cover the LP failed to unwind is kept, and
+// reported by GetUnsettled (caller must hold lock).
+func (sr *SplitRouter) settleLocked(execution *SplitExecution) {
+	if execution.OpenVolume > 1e-9 || execution.CoverVolume > 1e-9 || execution.unwinding > 1e-9 {
+		return
+	}
+	for _, o := range sr.orders {
+		if o.execution == execution {
+			return
+		}
+	}
+	delete(sr.executions, execution.PositionID)
+}
+
+// OpenExposure returns the net client volume of open split positions per
+// symbol (+ = clients long). Their A-Book share is covered by the split
+// itself, so the automatic hedger leaves them out.
+func (sr *SplitRouter) OpenExposure() map[string]float64 {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	exposure := make(map[string]float64)
+	for _, execution := range sr.executions {
+		if execution.Side == "SELL" {
+			exposure[execution.Symbol] -= execution.OpenVolume
+		} else {
+			exposure[execution.Symbol] += execution.OpenVolume
+		}
+	}
+	return exposure
+}
+
+// GetUnsettled returns the executions whose client position is closed but
+// whose LP cover is not fully unwound
+func (sr *SplitRouter) GetUnsettled() []SplitExecution {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	var unsettled []SplitExecution
+	for _, execution := range sr.executions {
+		if execution.OpenVolume <= 1e-9 {
+			copied := *execution
+			copied.Legs = append([]ChildExecution(nil), execution.Legs...)
+			unsettled = append(unsettled, copied)
+		}
+	}
+	return unsettled
+}
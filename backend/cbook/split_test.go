@@ -0,0 +1,173 @@
+package cbook
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/fix"
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+// fillAll books the whole client volume as position 7 at 1.1002
+func fillAll(accountID int64, symbol, side string, volume float64) (int64, float64, float64, error) {
+	return 7, 1.1002, volume, nil
+}
+
+// TestSplitOrderByDecision tests that a partial hedge is divided by its A-Book
+// percentage and that legs below the minimum fold into the other book
+func TestSplitOrderByDecision(t *testing.T) {
+	config := SplitConfig{Enabled: true, LotStep: 0.01, MinLegVolume: 0.05}
+	tests := []struct {
+		percent      float64
+		volume       float64
+		aBook, bBook float64
+	}{
+		{70, 1.0, 0.7, 0.3},
+		{33, 0.5, 0.17, 0.33}, // 0.165 rounds to the lot step
+		{2, 1.0, 0, 1.0},      // 0.02 A-Book leg too small
+		{97, 1.0, 1.0, 0},     // 0.03 B-Book leg too small
+	}
+	for _, tt := range tests {
+		decision := &RoutingDecision{Action: ActionPartialHedge, ABookPercent: tt.percent, BBookPercent: 100 - tt.percent}
+		split := splitOrder(config, decision, tt.volume)
+		if split == nil || math.Abs(split.ABookVolume-tt.aBook) > 1e-9 || math.Abs(split.BBookVolume-tt.bBook) > 1e-9 {
+			t.Errorf("splitOrder(%.0f%% of %.2f) = %+v, want %.2f/%.2f", tt.percent, tt.volume, split, tt.aBook, tt.bBook)
+		}
+	}
+
+	if split := splitOrder(SplitConfig{}, &RoutingDecision{Action: ActionPartialHedge, ABookPercent: 50}, 1); split != nil {
+		t.Errorf("splitOrder() with splitting disabled = %+v, want nil", split)
+	}
+	if split := splitOrder(config, &RoutingDecision{Action: ActionABook, ABookPercent: 100}, 1); split != nil {
+		t.Errorf("splitOrder() on a full A-Book decision = %+v, want nil", split)
+	}
+}
+
+// TestSplitRouterReconcilesLegs tests that both legs add up to the one client
+// position, that the LP receives only the A-Book share and that the A-Book leg
+// counts what the LP reports filled, internalizing the rest
+func TestSplitRouterReconcilesLegs(t *testing.T) {
+	var lpVolume float64
+	var router *SplitRouter
+	router = NewSplitRouter(func(symbol, side string, volume float64) (string, error) {
+		lpVolume = volume
+		// Reported before the order ID is returned
+		router.OnExecutionReport(fix.ExecutionReport{ClOrdID: "LP-1", ExecType: "PARTIAL", Volume: 0.2, CumQty: 0.2})
+		return "LP-1", nil
+	})
+	decision := &RoutingDecision{Action: ActionPartialHedge, Split: &OrderSplit{ABookVolume: 0.6, BBookVolume: 0.4}}
+
+	execution, err := router.Execute(1, "EURUSD", "BUY", 1.0, decision, fillAll)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if lpVolume != 0.6 {
+		t.Errorf("LP order volume = %.2f, want 0.60", lpVolume)
+	}
+	if len(execution.Legs) != 2 || execution.Legs[0].OrderID != "LP-1" || execution.Legs[0].Requested != 0.6 {
+		t.Fatalf("execution = %+v, want an A-Book leg for LP-1 and a B-Book leg", execution)
+	}
+	if a := execution.BookVolume(ActionABook); math.Abs(a-0.2) > 1e-9 || execution.Reconciled {
+		t.Errorf("A-Book volume before the fill = %.2f (reconciled %v), want the 0.20 reported and unreconciled", a, execution.Reconciled)
+	}
+
+	router.OnExecutionReport(fix.ExecutionReport{ClOrdID: "LP-1", ExecType: "PARTIAL", Volume: 0.3, CumQty: 0.5})
+	router.OnExecutionReport(fix.ExecutionReport{ClOrdID: "LP-1", ExecType: "CANCELED", Text: "end of book"})
+	router.mu.Lock()
+	settled := *router.executions[7]
+	router.mu.Unlock()
+	if a, b := settled.BookVolume(ActionABook), settled.BookVolume(ActionBBook); math.Abs(a-0.5) > 1e-9 || math.Abs(b-0.5) > 1e-9 || !settled.Reconciled {
+		t.Errorf("book volumes = %.2f/%.2f (reconciled %v), want 0.50/0.50 with the unfilled cover internalized", a, b, settled.Reconciled)
+	}
+	if exposure := router.OpenExposure(); exposure["EURUSD"] != 1.0 {
+		t.Errorf("OpenExposure() = %v, want the open 1.0 lot split position", exposure)
+	}
+}
+
+// TestSplitRouterUnwindsCoverOnClose tests that closing the client position
+// unwinds its LP cover in proportion and forgets the execution once unwound
+func TestSplitRouterUnwindsCoverOnClose(t *testing.T) {
+	type lpCall struct {
+		side   string
+		volume float64
+	}
+	calls := make(chan lpCall, 4)
+	var router *SplitRouter
+	ids := 0
+	router = NewSplitRouter(func(symbol, side string, volume float64) (string, error) {
+		ids++
+		id := fmt.Sprintf("LP-%d", ids)
+		router.OnExecutionReport(fix.ExecutionReport{ClOrdID: id, ExecType: "FILLED", Volume: volume, CumQty: volume})
+		calls <- lpCall{side, volume}
+		return id, nil
+	})
+	decision := &RoutingDecision{Action: ActionPartialHedge, Split: &OrderSplit{ABookVolume: 0.6, BBookVolume: 0.4}}
+	if _, err := router.Execute(1, "EURUSD", "BUY", 1.0, decision, fillAll); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	<-calls
+
+	next := func() lpCall {
+		select {
+		case call := <-calls:
+			return call
+		case <-time.After(time.Second):
+			t.Fatal("the cover was not unwound")
+			return lpCall{}
+		}
+	}
+	router.HandleTradeEvent(core.TradeEvent{Type: core.TradeEventPositionClosed, PositionID: 7, Volume: 0.5, RemainingVolume: 0.5})
+	if call := next(); call.side != "SELL" || math.Abs(call.volume-0.3) > 1e-9 {
+		t.Errorf("partial close unwound %+v, want SELL 0.30", call)
+	}
+	if exposure := router.OpenExposure(); exposure["EURUSD"] != 0.5 {
+		t.Errorf("OpenExposure() after the partial close = %v, want 0.5", exposure)
+	}
+
+	router.HandleTradeEvent(core.TradeEvent{Type: core.TradeEventPositionClosed, PositionID: 7, Volume: 0.5})
+	if call := next(); call.side != "SELL" || math.Abs(call.volume-0.3) > 1e-9 {
+		t.Errorf("full close unwound %+v, want SELL 0.30", call)
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		router.mu.Lock()
+		remaining := len(router.executions)
+		router.mu.Unlock()
+		if remaining == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d executions kept after the cover was unwound", remaining)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestSplitRouterInternalizesFailedCover tests that a failed LP order leaves its
+// volume on the B-Book and that a partial client fill caps the cover
+func TestSplitRouterInternalizesFailedCover(t *testing.T) {
+	router := NewSplitRouter(func(symbol, side string, volume float64) (string, error) {
+		return "", errors.New("session down")
+	})
+	decision := &RoutingDecision{Action: ActionPartialHedge, Split: &OrderSplit{ABookVolume: 0.8, BBookVolume: 0.2}}
+
+	execution, err := router.Execute(1, "EURUSD", "SELL", 1.0, decision, func(accountID int64, symbol, side string, volume float64) (int64, float64, float64, error) {
+		return 8, 1.1000, 0.5, nil // Half filled
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if execution.Volume != 0.5 || execution.BookVolume(ActionABook) != 0 || execution.BookVolume(ActionBBook) != 0.5 {
+		t.Errorf("execution = %+v, want the 0.5 lot fill internalized", execution)
+	}
+	if !execution.Reconciled || execution.Legs[0].Error == "" {
+		t.Errorf("execution = %+v, want reconciled with the LP error recorded", execution)
+	}
+
+	if _, err := router.Execute(1, "EURUSD", "BUY", 1.0, &RoutingDecision{Action: ActionReject, Reason: "toxic"}, fillAll); err == nil {
+		t.Error("Execute() filled a rejected order")
+	}
+}
@@ -17,6 +17,7 @@ func main() {
 	// Step 1: Create FIX Gateway
 	log.Println("\n[1/5] Initializing FIX Gateway...")
 	gateway := fix.NewFIXGateway()
+	marketData := gateway.Subscribe()
 	log.Println("✅ FIX Gateway initialized with YoFX2 session")
 
 	// Step 2: Connect to YoFX2
@@ -70,7 +71,7 @@ func main() {
 	go func() {
 		for {
 			select {
-			case md := <-gateway.GetMarketData():
+			case md := <-marketData:
 				if md.Symbol == "XAUUSD" {
 					tickCount++
 					log.Printf("✅ [%3d] XAUUSD Market Data:", tickCount)
@@ -82,7 +83,7 @@ func main() {
 				if reject.MDReqID == mdReqID {
 					rejectCount++
 					log.Printf("⚠️  [REJECT #%d] MDReqID: %s", rejectCount, reject.MDReqID)
-					log.Printf("       Reason: %s", reject.Reason)
+					log.Printf("       Reason: %s (%s)", reject.Reason, reject.ReasonText)
 					log.Printf("       Text: %s", reject.Text)
 					log.Println()
 				}
@@ -22,18 +22,21 @@ func main() {
 		symbol       string
 		marketDepth  int
 		subType      int
-		updateType   int
+		updateType   *int // nil omits 265
 		description  string
 	}{
-		{"Standard EURUSD", "EURUSD", 0, 1, 0, "Full book, Snapshot+Updates"},
-		{"Depth=1 EURUSD", "EURUSD", 1, 1, 0, "Top of book, Snapshot+Updates"},
-		{"Snapshot Only", "EURUSD", 0, 0, 0, "Full book, Snapshot only"},
-		{"EUR/USD Slash", "EUR/USD", 0, 1, 0, "With slash separator"},
-		{"GBPUSD Test", "GBPUSD", 0, 1, 0, "Different major pair"},
-		{"USDJPY Test", "USDJPY", 0, 1, 0, "JPY cross"},
+		{"Standard EURUSD", "EURUSD", 0, 1, nil, "Full book, Snapshot+Updates"},
+		{"Depth=1 EURUSD", "EURUSD", 1, 1, nil, "Top of book, Snapshot+Updates"},
+		{"Snapshot Only", "EURUSD", 0, 0, nil, "Full book, Snapshot only"},
+		{"Full Refresh 265=0", "EURUSD", 0, 1, intPtr(0), "Snapshot+Updates, full refresh"},
+		{"Incremental 265=1", "EURUSD", 0, 1, intPtr(1), "Snapshot+Updates, incremental"},
+		{"EUR/USD Slash", "EUR/USD", 0, 1, nil, "With slash separator"},
+		{"GBPUSD Test", "GBPUSD", 0, 1, nil, "Different major pair"},
+		{"USDJPY Test", "USDJPY", 0, 1, nil, "JPY cross"},
 	}
 
 	gateway := fix.NewFIXGateway()
+	marketData := gateway.Subscribe()
 
 	// Connect to YOFX2
 	log.Println("\n[1/4] Connecting to YOFX2...")
@@ -63,10 +66,17 @@ func main() {
 		log.Printf("    Symbol: %s", tc.symbol)
 		log.Printf("    MarketDepth: %d (264=%d)", tc.marketDepth, tc.marketDepth)
 		log.Printf("    SubscriptionType: %d (263=%d)", tc.subType, tc.subType)
-		log.Printf("    UpdateType: %d (265=%d)", tc.updateType, tc.updateType)
+		if tc.updateType != nil {
+			log.Printf("    UpdateType: %d (265=%d)", *tc.updateType, *tc.updateType)
+		} else {
+			log.Printf("    UpdateType: omitted (no 265)")
+		}
 
-		// Subscribe (we'll need to modify SubscribeMarketData to accept these params)
-		mdReqID, err := gateway.SubscribeMarketData("YOFX2", tc.symbol)
+		opts := fix.DefaultMarketDataOptions()
+		opts.MarketDepth = tc.marketDepth
+		opts.SubscriptionType = tc.subType
+		opts.UpdateType = tc.updateType
+		mdReqID, err := gateway.SubscribeMarketDataWithOptions("YOFX2", tc.symbol, opts)
 		if err != nil {
 			log.Printf("    ❌ Subscription error: %v\n", err)
 			results[tc.name] = "ERROR: " + err.Error()
@@ -84,7 +94,7 @@ func main() {
 		responseLoop:
 		for elapsed := 0; elapsed < 15; elapsed++ {
 			select {
-			case md := <-gateway.GetMarketData():
+			case md := <-marketData:
 				if md.Symbol == tc.symbol {
 					log.Printf("    ✅ SUCCESS! MarketDataSnapshot received!")
 					log.Printf("       Bid: %.5f | Ask: %.5f | Spread: %.5f", md.Bid, md.Ask, md.Ask-md.Bid)
@@ -97,7 +107,7 @@ func main() {
 			case reject := <-gateway.GetMarketDataRejects():
 				if reject.MDReqID == mdReqID {
 					log.Printf("    ❌ REJECTED!")
-					log.Printf("       Reason: %s", reject.Reason)
+					log.Printf("       Reason: %s (%s)", reject.Reason, reject.ReasonText)
 					log.Printf("       Text: %s", reject.Text)
 					results[tc.name] = fmt.Sprintf("REJECTED: %s", reject.Text)
 					gotResponse = true
@@ -173,3 +183,7 @@ func main() {
 	log.Println("\n⏸️  Keeping connection alive. Press Ctrl+C to exit")
 	select {}
 }
+
+func intPtr(v int) *int {
+	return &v
+}
@@ -19,6 +19,10 @@ func main() {
 	statusCmd := flag.Bool("status", false, "Show migration status")
 	initCmd := flag.Bool("init", false, "Initialize migrations table")
 	version := flag.Int64("version", 0, "Migrate up to specific version")
+	redoCmd := flag.Bool("redo", false, "Rollback the last migration and apply it again")
+	resetCmd := flag.Bool("reset", false, "Rollback all migrations (requires -yes)")
+	yes := flag.Bool("yes", false, "Confirm a destructive command such as -reset")
+	force := flag.Int64("force", -1, "Mark the schema clean at a version after repairing a failed migration")
 
 	flag.Parse()
 
@@ -88,6 +92,49 @@ func main() {
 		}
 		log.Println("[Migrate] ✅ Rollback completed successfully")
 
+	case *redoCmd:
+		log.Println("[Migrate] Redoing last migration...")
+		if err := migrator.Init(); err != nil {
+			log.Fatalf("Failed to initialize: %v", err)
+		}
+		if err := migrator.Redo(); err != nil {
+			log.Fatalf("Redo failed: %v", err)
+		}
+		log.Println("[Migrate] ✅ Redo completed successfully")
+		if err := migrator.Status(); err != nil {
+			log.Fatalf("Failed to get status: %v", err)
+		}
+
+	case *resetCmd:
+		if !*yes {
+			log.Fatalf("Refusing to reset: this rolls back every migration and drops their tables. Re-run with -reset -yes to confirm")
+		}
+		log.Println("[Migrate] Rolling back all migrations...")
+		if err := migrator.Init(); err != nil {
+			log.Fatalf("Failed to initialize: %v", err)
+		}
+		rolledBack, err := migrator.Reset()
+		if err != nil {
+			log.Fatalf("Reset failed after %d rollbacks: %v", rolledBack, err)
+		}
+		log.Printf("[Migrate] ✅ Reset completed successfully: %d migrations rolled back", rolledBack)
+		if err := migrator.Status(); err != nil {
+			log.Fatalf("Failed to get status: %v", err)
+		}
+
+	case *force >= 0:
+		log.Printf("[Migrate] Forcing clean state at version %d...", *force)
+		if err := migrator.Init(); err != nil {
+			log.Fatalf("Failed to initialize: %v", err)
+		}
+		if err := migrator.Force(*force); err != nil {
+			log.Fatalf("Force failed: %v", err)
+		}
+		log.Printf("[Migrate] ✅ Schema marked clean at version %d", *force)
+		if err := migrator.Status(); err != nil {
+			log.Fatalf("Failed to get status: %v", err)
+		}
+
 	case *statusCmd:
 		if err := migrator.Init(); err != nil {
 			log.Fatalf("Failed to initialize: %v", err)
@@ -115,6 +162,9 @@ func main() {
 		fmt.Println("  migrate -down          Rollback last migration")
 		fmt.Println("  migrate -status        Show migration status")
 		fmt.Println("  migrate -version=N     Migrate up to specific version")
+		fmt.Println("  migrate -redo          Rollback the last migration and apply it again")
+		fmt.Println("  migrate -reset -yes    Rollback all migrations")
+		fmt.Println("  migrate -force=N       Mark the schema clean at version N after a failed migration")
 		fmt.Println()
 		fmt.Println("Environment variables (or use .env file):")
 		fmt.Println("  DB_HOST                Database host (default: localhost)")
@@ -1,22 +1,31 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math"
 	"math/rand"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/epic1st/rtx/backend/abook"
 	"github.com/epic1st/rtx/backend/admin"
 	"github.com/epic1st/rtx/backend/api"
 	"github.com/epic1st/rtx/backend/auth"
+	"github.com/epic1st/rtx/backend/backtest"
 	"github.com/epic1st/rtx/backend/cbook"
 	"github.com/epic1st/rtx/backend/config"
 	"github.com/epic1st/rtx/backend/fix"
@@ -25,9 +34,16 @@ import (
 	"github.com/epic1st/rtx/backend/internal/api/websocket"
 	"github.com/epic1st/rtx/backend/internal/compression"
 	"github.com/epic1st/rtx/backend/internal/core"
+	"github.com/epic1st/rtx/backend/internal/httpserver"
 	"github.com/epic1st/rtx/backend/internal/middleware"
+	"github.com/epic1st/rtx/backend/internal/persistence"
+	"github.com/epic1st/rtx/backend/logging"
 	"github.com/epic1st/rtx/backend/lpmanager"
 	"github.com/epic1st/rtx/backend/lpmanager/adapters"
+	"github.com/epic1st/rtx/backend/monitoring"
+	"github.com/epic1st/rtx/backend/notifications"
+	"github.com/epic1st/rtx/backend/orders"
+	"github.com/epic1st/rtx/backend/risk"
 	"github.com/epic1st/rtx/backend/tickstore"
 	"github.com/epic1st/rtx/backend/ws"
 )
@@ -51,12 +67,23 @@ var brokerConfig BrokerConfig
 // For backward compatibility
 var executionMode string
 
-// Global tick tracker for debugging market data flow
-var (
-	latestTicks    = make(map[string]*ws.MarketTick)
-	tickMutex      sync.RWMutex
-	totalTickCount int64
-)
+// Count of ticks received from the FIX gateway, used to decide whether the
+// historical simulation is needed. Tick metrics live in the hub.
+var fixTickCount int64
+
+// defaultForexSymbols are subscribed on YOFX2 when no subscriptions were persisted
+var defaultForexSymbols = []string{
+	// Major pairs
+	"EURUSD", "GBPUSD", "USDJPY", "USDCHF", "USDCAD",
+	"AUDUSD", "NZDUSD",
+	// Cross pairs
+	"EURGBP", "EURJPY", "GBPJPY", "EURAUD", "EURCAD",
+	"EURCHF", "AUDCAD", "AUDCHF", "AUDJPY", "AUDNZD",
+	"CADCHF", "CADJPY", "CHFJPY", "GBPAUD", "GBPCAD",
+	"GBPCHF", "GBPNZD", "NZDCAD", "NZDCHF", "NZDJPY",
+	// Metals
+	"XAUUSD", "XAGUSD",
+}
 
 // HistoricalTick represents a tick from OANDA historical data
 type HistoricalTick struct {
@@ -217,6 +244,18 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Level and format of the structured logs (FIX gateway, WebSocket hub)
+	if err := logging.ConfigureFromEnv(); err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
+
+	// Broker settings saved by /api/config override the environment defaults
+	brokerOverrides, err := config.LoadBrokerOverrides(cfg.Broker.SettingsPath)
+	if err != nil {
+		log.Fatalf("Failed to load broker settings from %s: %v", cfg.Broker.SettingsPath, err)
+	}
+	brokerOverrides.Get().ApplyTo(&cfg.Broker)
+
 	// Initialize broker config from loaded configuration
 	brokerConfig = BrokerConfig{
 		BrokerName:        cfg.Broker.Name,
@@ -239,7 +278,7 @@ func main() {
 
 	// Initialize OPTIMIZED tick storage with SQLite backend:
 	// - Ring buffers (bounded memory, O(1) operations)
-	// - Quote throttling (skip < 0.001% price changes)
+	// - Quote throttling (skip < 0.001% price changes, tunable per symbol)
 	// - Async batch writer (non-blocking disk persistence)
 	// - SQLite persistent storage with daily rotation
 	tickStoreConfig := tickstore.ProductionConfig("BROKER-001")
@@ -258,17 +297,47 @@ func main() {
 	apiHandler := handlers.NewAPIHandler(bbookEngine, pnlEngine)
 	apiHandler.SetCBookEngine(cbookEngine)
 
+	// Periodic rule effectiveness snapshots for dashboard time series
+	ruleSnapshots, err := cbook.NewRuleSnapshotStore(cfg.RuleSnapshot.Path)
+	if err != nil {
+		log.Printf("[RuleSnapshots] Failed to load snapshots: %v", err)
+		ruleSnapshots, _ = cbook.NewRuleSnapshotStore("")
+	}
+	ruleSnapshots.StartRuleSnapshots(cbookEngine.GetRoutingEngine(), config.ParseDuration(cfg.RuleSnapshot.Interval))
+	apiHandler.SetRuleSnapshotStore(ruleSnapshots)
+
 	// Create Compliance Handler
 	complianceHandler := handlers.NewComplianceHandler(bbookEngine)
 
 	// Create Auth Service with admin credentials and JWT secret from config
 	authService := auth.NewService(bbookEngine, cfg.Admin.Password, cfg.JWT.Secret)
+	authService.SetTokenTTL(config.ParseDuration(cfg.JWT.Expiry))
+
+	// Accounts, positions and ledger survive restarts when written through to the database
+	loadedAccounts := 0
+	if cfg.Database.PersistEngine {
+		db, err := sql.Open("postgres", fmt.Sprintf(
+			"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			cfg.Database.Host, cfg.Database.Port, cfg.Database.User,
+			cfg.Database.Password, cfg.Database.Name, cfg.Database.SSLMode))
+		if err == nil {
+			err = db.Ping()
+		}
+		if err != nil {
+			log.Fatalf("Failed to connect to the engine database: %v", err)
+		}
+		bbookEngine.SetStore(persistence.NewPostgresStore(db))
+		if loadedAccounts, err = bbookEngine.LoadFromStore(); err != nil {
+			log.Fatalf("Failed to load engine state: %v", err)
+		}
+	}
 
-	// Create demo account with configured balance (only if configured)
-	if brokerConfig.DefaultBalance > 0 {
+	// Create demo account with configured balance (only if configured and not restored)
+	if brokerConfig.DefaultBalance > 0 && loadedAccounts == 0 {
 		demoAccount := bbookEngine.CreateAccount("demo-user", "Demo User", "password", true)
 		bbookEngine.GetLedger().SetBalance(demoAccount.ID, brokerConfig.DefaultBalance)
 		demoAccount.Balance = brokerConfig.DefaultBalance
+		bbookEngine.PersistAccount(demoAccount.ID)
 		log.Printf("[B-Book] Demo account created: %s with $%.2f", demoAccount.AccountNumber, brokerConfig.DefaultBalance)
 	}
 	hub := ws.NewHub()
@@ -276,6 +345,10 @@ func main() {
 	// Set tick store on hub for storing incoming ticks
 	hub.SetTickStore(tickStore)
 
+	// Broadcast and persistence share the tick store's per-symbol thresholds;
+	// their defaults stay separate (0.0001% broadcast, 0.001% stored)
+	hub.SetThrottleThresholdFunc(tickStore.SymbolThrottleThreshold)
+
 	// Set B-Book engine on hub for dynamic symbol registration
 	hub.SetBBookEngine(bbookEngine)
 
@@ -286,19 +359,15 @@ func main() {
 	analyticsHub := websocket.InitializeAnalyticsHub(authService)
 	log.Println("[Analytics] Real-time analytics WebSocket hub initialized")
 
-	// Initialize Compression Service
+	// Initialize Compression Service: compresses old tick files and rotates
+	// daily files by age and per-symbol disk quota
 	var compressor *compression.Compressor
 	if retentionCfg, err := compression.LoadRetentionConfig("backend/config/retention.yaml"); err != nil {
-		log.Printf("[Compression] Failed to load retention config: %v (compression disabled)", err)
+		log.Printf("[Compression] Failed to load retention config: %v (compression and retention disabled)", err)
 	} else {
 		compressorCfg := retentionCfg.ToCompressorConfig()
 		compressor = compression.NewCompressor(compressorCfg)
-		if compressor.IsEnabled() {
-			compressor.Start()
-			log.Println("[Compression] Compression scheduler started - scans every 7 days")
-		} else {
-			log.Println("[Compression] Compression disabled by configuration")
-		}
+		compressor.Start()
 	}
 
 	apiHandler.SetHub(hub)
@@ -312,6 +381,34 @@ func main() {
 		return 0, 0, false
 	})
 
+	// Recover last known quotes so P/L has a baseline before live ticks arrive.
+	// Recovered quotes are stale: they value positions but never fill orders.
+	bbookEngine.SetStaleQuoteCallback(hub.IsQuoteStale)
+
+	// Market orders never fill on a quote older than the symbol's price age limit
+	bbookEngine.SetQuoteAgeCallback(hub.QuoteAge)
+	bbookEngine.SetMaxPriceAge(config.ParseDuration(cfg.Broker.MaxPriceAge))
+
+	// Ping WebSocket clients and evict those that stop answering
+	hub.SetPingInterval(config.ParseDuration(cfg.Broker.WSPingInterval), config.ParseDuration(cfg.Broker.WSPongTimeout))
+
+	// Reject market orders while no feed at all is delivering quotes
+	hub.SetFeedOutageThreshold(config.ParseDuration(cfg.Broker.FeedOutageThreshold))
+	bbookEngine.SetFeedHealthCallback(hub.IsFeedHealthy)
+
+	// Drop fat-finger LP quotes far from each symbol's recent prices
+	hub.SetDefaultPriceBand(ws.PriceBandConfig{
+		MaxDeviationPct:  cfg.Broker.PriceBandMaxDeviationPct,
+		Window:           cfg.Broker.PriceBandWindow,
+		RecalibrateAfter: cfg.Broker.PriceBandRecalibrateAfter,
+	})
+	if cfg.QuoteSnapshot.Enabled {
+		if _, err := hub.LoadQuoteSnapshot(cfg.QuoteSnapshot.Path); err != nil {
+			log.Printf("[Hub] Failed to load quote snapshot: %v", err)
+		}
+		hub.StartQuoteSnapshots(cfg.QuoteSnapshot.Path, config.ParseDuration(cfg.QuoteSnapshot.Interval))
+	}
+
 	// Initialize LP Manager
 	lpMgr := lpmanager.NewManager("data/lp_config.json")
 
@@ -320,8 +417,10 @@ func main() {
 		lpMgr.RegisterAdapter(adapters.NewBinanceAdapter())
 		log.Println("[LP] Binance adapter registered")
 	}
+	var oandaAdapter *adapters.OANDAAdapter
 	if cfg.LP.OandaAPIKey != "" && cfg.LP.OandaAccountID != "" {
-		lpMgr.RegisterAdapter(adapters.NewOANDAAdapter(cfg.LP.OandaAPIKey, cfg.LP.OandaAccountID))
+		oandaAdapter = adapters.NewOANDAAdapter(cfg.LP.OandaAPIKey, cfg.LP.OandaAccountID)
+		lpMgr.RegisterAdapter(oandaAdapter)
 		log.Println("[LP] OANDA adapter registered")
 	} else {
 		log.Println("[LP WARNING] OANDA credentials not configured - OANDA adapter disabled")
@@ -332,6 +431,20 @@ func main() {
 		log.Printf("[LPManager] Failed to load config: %v", err)
 	}
 
+	// Register polling vendor feeds defined in lp_config.json
+	for _, lpCfg := range lpMgr.GetConfig().LPs {
+		if lpCfg.Type != "REST" {
+			continue
+		}
+		restAdapter, err := adapters.NewRESTAdapter(lpCfg)
+		if err != nil {
+			log.Printf("[LP WARNING] REST feed %s disabled: %v", lpCfg.ID, err)
+			continue
+		}
+		lpMgr.RegisterAdapter(restAdapter)
+		log.Printf("[LP] REST feed %s registered", lpCfg.ID)
+	}
+
 	// Initialize HTTP server with dependencies (pass lpMgr for A-Book)
 	server := api.NewServer(authService, apiHandler, lpMgr)
 
@@ -341,9 +454,90 @@ func main() {
 	// Pass hub to server
 	server.SetHub(hub)
 
+	// LP positions follow the broker's margin mode
+	server.SetMarginMode(cfg.Broker.MarginMode)
+
+	// Trailing stops on B-Book positions move with every tick and close the
+	// position once crossed; the tradeId of a stop is the position ID
+	trailing := server.GetTrailingService()
+	trailing.SetPriceCallback(func(symbol string) (float64, float64, bool) {
+		if tick := hub.GetLatestPrice(symbol); tick != nil {
+			return tick.Bid, tick.Ask, true
+		}
+		return 0, 0, false
+	})
+	trailing.SetATRCallback(func(symbol string, period int) float64 {
+		return tickstore.AverageTrueRange(tickStore.GetOHLC(symbol, 900, period+1), period) // M15 bars
+	})
+	trailing.SetCloseCallback(func(ts orders.TrailingStop, price float64) error {
+		positionID, err := strconv.ParseInt(ts.TradeID, 10, 64)
+		if err != nil {
+			return fmt.Errorf("trade %q is not a position ID", ts.TradeID)
+		}
+		// Only the position the stop was set on by its owner; one closed
+		// meanwhile leaves nothing to do
+		position, ok := bbookEngine.GetPosition(positionID)
+		if !ok {
+			return nil
+		}
+		if position.AccountID != ts.AccountID || position.Symbol != ts.Symbol || position.Side != ts.Side {
+			log.Printf("[TrailingStop] Dropping stop on position #%d: set for account %d %s %s", positionID, ts.AccountID, ts.Symbol, ts.Side)
+			return nil
+		}
+		if _, err := bbookEngine.ClosePositionWithReason(positionID, 0, core.CloseReasonTrailingStop); err != nil {
+			return err
+		}
+		pnlEngine.ForceUpdate()
+		return nil
+	})
+
+	// Pending limit/stop orders trigger on the tick stream and fill as a
+	// market order on the B-Book at the quote that reached them; a limit
+	// order never fills worse than its limit
+	pending := server.GetOrderService()
+	pending.SetExecutionCallback(func(order *orders.PendingOrder) error {
+		if order.AccountID == 0 {
+			return fmt.Errorf("pending order %s has no account", order.ID)
+		}
+		var position *core.Position
+		var err error
+		if order.Type == orders.OrderTypeLimit {
+			position, err = bbookEngine.ExecuteLimitOrder(order.AccountID, order.Symbol, string(order.Side), order.Volume, order.SL, order.TP, order.EntryPrice)
+			if errors.Is(err, core.ErrRequote) {
+				return fmt.Errorf("%w: %v", orders.ErrBeyondLimit, err)
+			}
+		} else {
+			position, err = bbookEngine.ExecuteMarketOrder(order.AccountID, order.Symbol, string(order.Side), order.Volume, order.SL, order.TP)
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("[B-Book] Pending order %s filled: position #%d %s %.2f %s @ %.5f",
+			order.ID, position.ID, position.Side, position.Volume, position.Symbol, position.OpenPrice)
+		pnlEngine.ForceUpdate()
+		return nil
+	})
+	// Orders trigger on the marked-up quote their account fills at
+	pending.SetQuoteMarkCallback(bbookEngine.MarkQuote)
+	hub.SetTickCallback(func(symbol string, bid, ask float64) {
+		trailing.OnTick(symbol, bid, ask)
+		pending.OnTick(symbol, bid, ask)
+	})
+
 	// Start WebSocket hub
 	go hub.Run()
 
+	// Tell clients when a symbol's price feed fails over to another LP
+	lpMgr.SetFailoverCallback(func(event lpmanager.FailoverEvent) {
+		data, err := json.Marshal(struct {
+			Type string `json:"type"`
+			lpmanager.FailoverEvent
+		}{"lp_failover", event})
+		if err == nil {
+			hub.BroadcastMessage(data)
+		}
+	})
+
 	// Start LP Manager Aggregation
 	lpMgr.StartQuoteAggregation()
 
@@ -379,6 +573,140 @@ func main() {
 	// Create WebSocket alert broadcaster
 	wsAlertHub := alerts.NewWSAlertHub(hub)
 
+	// Raise a critical alert while no market data is flowing, resolve it when quotes return
+	var feedOutageMu sync.Mutex
+	var feedOutageAlert *alerts.Alert
+	hub.SetFeedHealthCallback(func(healthy bool, silentFor time.Duration) {
+		feedOutageMu.Lock()
+		defer feedOutageMu.Unlock()
+
+		now := time.Now()
+		if !healthy {
+			feedOutageAlert = &alerts.Alert{
+				ID:          fmt.Sprintf("feed-outage-%d", now.UnixNano()),
+				Type:        alerts.AlertTypeThreshold,
+				Severity:    alerts.AlertSeverityCritical,
+				Status:      alerts.AlertStatusActive,
+				Title:       "Market data outage",
+				Message:     fmt.Sprintf("No quotes from any feed for %v, market orders are rejected with NO_MARKET_DATA", silentFor.Round(time.Second)),
+				Metric:      "feed_silence_seconds",
+				Value:       silentFor.Seconds(),
+				Threshold:   config.ParseDuration(cfg.Broker.FeedOutageThreshold).Seconds(),
+				CreatedAt:   now,
+				UpdatedAt:   now,
+				Fingerprint: "feed-outage",
+			}
+			wsAlertHub.BroadcastAlert(feedOutageAlert)
+			return
+		}
+		if feedOutageAlert == nil {
+			return
+		}
+		feedOutageAlert.Status = alerts.AlertStatusResolved
+		feedOutageAlert.Message = fmt.Sprintf("Market data resumed after %v, market orders accepted again", silentFor.Round(time.Second))
+		feedOutageAlert.Value = silentFor.Seconds()
+		feedOutageAlert.UpdatedAt = now
+		feedOutageAlert.ResolvedAt = &now
+		wsAlertHub.BroadcastAlert(feedOutageAlert)
+		feedOutageAlert = nil
+	})
+
+	// Alert on the first quote of each run rejected by the price sanity band
+	hub.SetPriceBandCallback(func(reject ws.PriceBandReject) {
+		if reject.Consecutive != 1 {
+			return
+		}
+		wsAlertHub.BroadcastAlert(&alerts.Alert{
+			ID:          fmt.Sprintf("price-band-%s-%d", reject.Symbol, reject.At.UnixNano()),
+			Type:        alerts.AlertTypeThreshold,
+			Severity:    alerts.AlertSeverityHigh,
+			Status:      alerts.AlertStatusActive,
+			Title:       "Quote rejected by price band",
+			Message:     fmt.Sprintf("%s quote from %s at %.5f is %.2f%% from the recent median %.5f", reject.Symbol, reject.LP, reject.Mid, reject.DeviationPct, reject.Reference),
+			Metric:      "price_deviation_pct",
+			Value:       reject.DeviationPct,
+			Threshold:   cfg.Broker.PriceBandMaxDeviationPct,
+			CreatedAt:   reject.At,
+			UpdatedAt:   reject.At,
+			Fingerprint: "price-band-" + reject.Symbol,
+		})
+	})
+
+	// Alert on A-Book orders the LP's execution reports don't reconcile
+	server.GetABookEngine().SetReconciliationPolicy(config.ParseDuration(cfg.FIX.ReconcileTimeout), cfg.FIX.ReconcilePriceTolerance)
+	server.GetABookEngine().SetOnReconciliationIssue(func(issue abook.ReconciliationIssue) {
+		o := issue.Order
+		wsAlertHub.BroadcastAlert(&alerts.Alert{
+			ID:          fmt.Sprintf("abook-recon-%s-%d", o.ClOrdID, issue.DetectedAt.UnixNano()),
+			Type:        alerts.AlertTypeThreshold,
+			Severity:    alerts.AlertSeverityHigh,
+			Status:      alerts.AlertStatusActive,
+			Title:       "Unreconciled A-Book order",
+			Message:     fmt.Sprintf("%s: %s %s %.2f via %s (ClOrdID %s), status %s, requested %.5f, filled %.2f @ %.5f", issue.Kind, o.Side, o.Symbol, o.Quantity, o.LP, o.ClOrdID, o.Status, o.RequestedPrice, o.FilledQty, o.AvgFillPrice),
+			Metric:      "abook_unreconciled_orders",
+			Value:       1,
+			CreatedAt:   issue.DetectedAt,
+			UpdatedAt:   issue.DetectedAt,
+			Fingerprint: "abook-recon-" + o.ClOrdID,
+		})
+	})
+
+	// Stop FIX reconnect storms: trip a cooldown after repeated connect failures
+	if fixGateway := server.GetFIXGateway(); fixGateway != nil {
+		fixGateway.SetReconnectBreaker(fix.ReconnectBreakerConfig{
+			MaxFailures: cfg.FIX.ReconnectMaxFailures,
+			Window:      config.ParseDuration(cfg.FIX.ReconnectWindow),
+			Cooldown:    config.ParseDuration(cfg.FIX.ReconnectCooldown),
+		})
+
+		// Reconnect sessions that drop on their own, replaying their subscriptions
+		for sessionID := range fixGateway.GetStatus() {
+			fixGateway.SetAutoReconnect(sessionID, cfg.FIX.AutoReconnect)
+			fixGateway.SetMaxReconnectAttempts(sessionID, cfg.FIX.MaxReconnectAttempts)
+		}
+
+		// Never merge an incremental update onto a side the LP stopped quoting
+		fixGateway.SetQuoteStaleAfter(config.ParseDuration(cfg.FIX.QuoteStaleAfter))
+
+		var breakerMu sync.Mutex
+		breakerAlerts := make(map[string]*alerts.Alert)
+		fixGateway.SetReconnectBreakerCallback(func(state fix.ReconnectBreakerState, tripped bool) {
+			breakerMu.Lock()
+			defer breakerMu.Unlock()
+
+			now := time.Now()
+			if tripped {
+				alert := &alerts.Alert{
+					ID:          fmt.Sprintf("fix-breaker-%s-%d", state.SessionID, now.UnixNano()),
+					Type:        alerts.AlertTypeThreshold,
+					Severity:    alerts.AlertSeverityCritical,
+					Status:      alerts.AlertStatusActive,
+					Title:       "FIX reconnect breaker tripped",
+					Message:     fmt.Sprintf("Session %s failed to connect %d times, reconnects paused: %s", state.SessionID, state.Failures, state.LastError),
+					Metric:      "fix_connect_failures",
+					Value:       float64(state.Failures),
+					Threshold:   float64(cfg.FIX.ReconnectMaxFailures),
+					CreatedAt:   now,
+					UpdatedAt:   now,
+					Fingerprint: "fix-breaker-" + state.SessionID,
+				}
+				breakerAlerts[state.SessionID] = alert
+				wsAlertHub.BroadcastAlert(alert)
+				return
+			}
+			alert, ok := breakerAlerts[state.SessionID]
+			if !ok {
+				return
+			}
+			alert.Status = alerts.AlertStatusResolved
+			alert.Message = fmt.Sprintf("Session %s reconnect breaker closed, connection attempts resumed", state.SessionID)
+			alert.UpdatedAt = now
+			alert.ResolvedAt = &now
+			wsAlertHub.BroadcastAlert(alert)
+			delete(breakerAlerts, state.SessionID)
+		})
+	}
+
 	// Create notification dispatcher
 	notifier := alerts.NewNotifier(wsAlertHub)
 
@@ -403,8 +731,149 @@ func main() {
 	// Initialize Admin System
 	// ============================================
 	adminHandler := admin.NewAdminHandler(bbookEngine)
+	adminHandler.SetSessionLimit(cfg.Admin.MaxSessions, cfg.Admin.SessionTakeover)
+	adminHandler.SetAuthCacheTTL(config.ParseDuration(cfg.Admin.AuthCacheTTL))
 	log.Println("[Admin] Admin system initialized")
 
+	// Enforce per-group allowed order types and placement defaults
+	apiHandler.SetAuthService(authService)
+	apiHandler.SetOrderRulesResolver(adminHandler.OrderRulesForAccount)
+	server.SetOrderRules(adminHandler.OrderRulesForAccount, func(symbol string) float64 {
+		if spec, ok := bbookEngine.GetSymbol(symbol); ok {
+			return spec.PipSize
+		}
+		return 0
+	})
+
+	// Round (or in strict mode reject) pending order prices beyond the symbol's digits
+	orderService := server.GetOrderService()
+	orderService.SetPrecisionCallback(func(symbol string) (int, bool) {
+		if spec, ok := bbookEngine.GetSymbol(symbol); ok {
+			return spec.Digits, true
+		}
+		return 0, false
+	})
+	orderService.SetStrictPrecision(cfg.Broker.StrictPricePrecision)
+
+	// Pending orders never trigger on a lagged feed
+	orderService.SetQuoteAgeCallback(hub.QuoteAge)
+	orderService.SetMaxQuoteAge(config.ParseDuration(cfg.Broker.PendingMaxQuoteAge))
+
+	// FIFO/LIFO lot selection on bulk and partial closes
+	if err := bbookEngine.SetCloseOrder(cfg.Broker.CloseOrder); err != nil {
+		log.Printf("[B-Book] %v, keeping %s", err, bbookEngine.GetCloseOrder())
+	}
+
+	// Post stop-out cooldown, overridable per group and audited when it starts
+	bbookEngine.SetStopOutCooldown(config.ParseDuration(cfg.Broker.StopOutCooldown))
+	bbookEngine.SetStopOutCooldownResolver(adminHandler.StopOutCooldownForAccount)
+	bbookEngine.SetStopOutCooldownCallback(adminHandler.AuditStopOutCooldown)
+
+	// Group markup widens the quotes each client receives
+	hub.SetMarkupResolver(func(accountID, symbol string) ws.QuoteMarkup {
+		id, err := strconv.ParseInt(accountID, 10, 64)
+		if err != nil {
+			return ws.QuoteMarkup{}
+		}
+		bid, ask := adminHandler.QuoteMarkupForAccount(id, symbol)
+		return ws.QuoteMarkup{BidPips: bid, AskPips: ask}
+	})
+	// ...and the engine fills, closes and stops positions at those quotes
+	bbookEngine.SetQuoteMarkupResolver(adminHandler.QuoteMarkupForAccount)
+	adminHandler.SetMarkupChangeCallback(func() {
+		hub.InvalidateMarkups()
+		bbookEngine.InvalidateQuoteMarkups()
+	})
+
+	// Margin call alerts and stop-out closes, overridable per group
+	if err := bbookEngine.SetMarginLevels(core.MarginLevels{
+		MarginCall: cfg.Broker.MarginCallLevel,
+		StopOut:    cfg.Broker.StopOutLevel,
+	}); err != nil {
+		log.Printf("[B-Book] Invalid margin levels: %v, keeping defaults", err)
+	}
+	bbookEngine.SetMarginLevelsResolver(adminHandler.MarginLevelsForAccount)
+
+	// Netting or hedging per group: netting accounts hold one position per symbol
+	bbookEngine.SetMarginModeResolver(adminHandler.MarginModeForAccount)
+	bbookEngine.SetMarginEventCallback(func(event core.MarginEvent) {
+		adminHandler.AuditMarginEvent(event)
+
+		alert := &alerts.Alert{
+			ID:          fmt.Sprintf("margin-%d-%d", event.AccountID, event.Timestamp.UnixNano()),
+			Type:        alerts.AlertTypeThreshold,
+			Severity:    alerts.AlertSeverityHigh,
+			Status:      alerts.AlertStatusActive,
+			Title:       "Margin call",
+			Message:     fmt.Sprintf("Account #%d margin level %.2f%% fell below %.2f%%", event.AccountID, event.MarginLevel, event.Threshold),
+			AccountID:   strconv.FormatInt(event.AccountID, 10),
+			Metric:      "margin_level",
+			Value:       event.MarginLevel,
+			Threshold:   event.Threshold,
+			CreatedAt:   event.Timestamp,
+			UpdatedAt:   event.Timestamp,
+			Fingerprint: fmt.Sprintf("margin-call-%d", event.AccountID),
+		}
+		if event.Type == core.MarginEventStopOut {
+			alert.Severity = alerts.AlertSeverityCritical
+			alert.Title = "Stop-out"
+			alert.Message = fmt.Sprintf("Account #%d stopped out at margin level %.2f%%: closed %s position #%d, P/L %.2f",
+				event.AccountID, event.MarginLevel, event.Trade.Symbol, event.Trade.PositionID, event.Trade.RealizedPnL)
+			alert.Fingerprint = fmt.Sprintf("stop-out-%d-%d", event.AccountID, event.Trade.PositionID)
+		}
+		wsAlertHub.BroadcastAlert(alert)
+	})
+
+	// Last look with a cap on requotes, so a moving market cannot stall an order
+	if err := bbookEngine.SetRequoteConfig(core.RequoteConfig{
+		Enabled:       cfg.Broker.RequoteEnabled,
+		TolerancePips: cfg.Broker.RequoteTolerancePips,
+		LastLook:      config.ParseDuration(cfg.Broker.RequoteLastLook),
+		MaxRequotes:   cfg.Broker.RequoteMax,
+		LimitAction:   cfg.Broker.RequoteLimitAction,
+	}); err != nil {
+		log.Printf("[B-Book] %v, requotes disabled", err)
+	}
+
+	// Slippage on market fills, overridable per symbol and per group
+	if err := bbookEngine.SetSlippageConfig(core.SlippageConfig{
+		Model:       cfg.Broker.SlippageModel,
+		Pips:        cfg.Broker.SlippagePips,
+		RequotePips: cfg.Broker.SlippageRequotePips,
+	}); err != nil {
+		log.Printf("[B-Book] %v, slippage disabled", err)
+	}
+	bbookEngine.SetSlippageResolver(adminHandler.SlippageForAccount)
+
+	// Orders route to the book of their group, else their symbol, else the global mode
+	if err := cbookEngine.GetExecutionModes().SetGlobal(executionMode); err != nil {
+		log.Printf("[C-Book] %v, routing engine decides the book", err)
+	}
+	cbookEngine.GetExecutionModes().SetGroupResolver(adminHandler.ExecutionModeForAccount)
+
+	// Group-level choice between markup and explicit commission pricing
+	bbookEngine.SetCommissionModelResolver(adminHandler.CommissionModelForAccount)
+
+	// Round-turn commission, at the group's rate before the symbol's
+	if err := bbookEngine.SetCommissionType(cfg.Broker.CommissionType); err != nil {
+		log.Printf("[B-Book] %v, keeping %s", err, bbookEngine.GetCommissionType())
+	}
+	bbookEngine.SetCommissionRateResolver(adminHandler.CommissionRateForAccount)
+
+	// Overnight rollover: swap, or the admin fee for swap-free accounts
+	bbookEngine.SetSwapFreePolicy(core.SwapFreePolicy{
+		AdminFeePerLot: cfg.Broker.SwapFreeAdminFee,
+		GraceNights:    cfg.Broker.SwapFreeGraceNights,
+	})
+	rolloverSchedule, err := core.ParseRolloverSchedule(cfg.Broker.RolloverTime, cfg.Broker.TripleSwapDay)
+	if err != nil {
+		log.Printf("[B-Book] %v, using the default rollover schedule", err)
+	}
+	bbookEngine.StartDailyRollover(rolloverSchedule)
+
+	// DAY pending orders are cancelled at the rollover that ends the trading day
+	orderService.SetDayEndCallback(rolloverSchedule.Next)
+
 	// Initialize FIX Provisioning (optional)
 	if cfg.FIX.ProvisioningEnabled {
 		// Create audit logger
@@ -425,6 +894,103 @@ func main() {
 		}
 	}
 
+	// ============================================
+	// Initialize Automatic B-Book Hedging (optional)
+	// ============================================
+	hedgeConfig := abook.DefaultHedgeConfig()
+	hedgeConfig.Enabled = cfg.Hedging.Enabled
+	hedgeConfig.UpperBand = cfg.Hedging.UpperBand
+	hedgeConfig.LowerBand = cfg.Hedging.LowerBand
+	hedgeConfig.HedgeRatio = cfg.Hedging.HedgeRatio
+	hedgeConfig.MinTradeSize = cfg.Hedging.MinTradeSize
+	hedgeConfig.CheckInterval = config.ParseDuration(cfg.Hedging.CheckInterval)
+
+	// Split partial-hedge market orders, covering the A-Book leg on the hedge session
+	cbookEngine.SetSplitConfig(cbook.SplitConfig{
+		Enabled:      cfg.Hedging.SplitEnabled,
+		LotStep:      cfg.Hedging.SplitLotStep,
+		MinLegVolume: cfg.Hedging.SplitMinLegVolume,
+	})
+	splitRouter := cbook.NewSplitRouter(func(symbol, side string, volume float64) (string, error) {
+		fixGateway := server.GetFIXGateway()
+		if fixGateway == nil {
+			return "", fmt.Errorf("FIX gateway not available")
+		}
+		return fixGateway.SendMarketOrder(cfg.Hedging.SessionID, symbol, side, volume)
+	})
+	apiHandler.SetSplitRouter(splitRouter)
+	// The cover is unwound as the client closes
+	bbookEngine.SubscribeTradeEvents(splitRouter.HandleTradeEvent)
+
+	// Split positions carry their own cover, so the hedger leaves them out
+	hedgeExposure := func() map[string]float64 {
+		exposure := bbookEngine.GetNetExposure()
+		for symbol, volume := range splitRouter.OpenExposure() {
+			exposure[symbol] -= volume
+			if math.Abs(exposure[symbol]) < 1e-9 {
+				delete(exposure, symbol)
+			}
+		}
+		return exposure
+	}
+	autoHedger := abook.NewAutoHedger(hedgeConfig, hedgeExposure,
+		func(symbol, side string, volume float64) (string, error) {
+			fixGateway := server.GetFIXGateway()
+			if fixGateway == nil {
+				return "", fmt.Errorf("FIX gateway not available")
+			}
+			return fixGateway.SendMarketOrder(cfg.Hedging.SessionID, symbol, side, volume)
+		})
+	// Hedges and split covers move only by what the LP reports filled
+	server.GetABookEngine().SetOnExecutionReportCallback(func(report fix.ExecutionReport) {
+		autoHedger.OnExecutionReport(report)
+		splitRouter.OnExecutionReport(report)
+	})
+	if hedgeConfig.Enabled {
+		autoHedger.Start()
+		log.Printf("[AutoHedge] Hedging B-Book exposure via %s", cfg.Hedging.SessionID)
+	}
+	apiHandler.SetHedgePositionsProvider(autoHedger.GetHedges)
+
+	// B-Book exposure caps; with the A_BOOK action breaching orders go to the hedge session
+	exposureLimits := core.ExposureLimits{
+		Symbols:   make(map[string]float64),
+		Aggregate: cfg.Broker.ExposureAggregateLimit,
+		Action:    cfg.Broker.ExposureLimitAction,
+	}
+	for _, pair := range cfg.Broker.ExposureSymbolLimits {
+		symbol, lots, _ := strings.Cut(strings.TrimSpace(pair), ":")
+		limit, err := strconv.ParseFloat(lots, 64)
+		if symbol == "" || err != nil {
+			log.Printf("[B-Book] Ignoring invalid exposure limit %q, want SYMBOL:LOTS", pair)
+			continue
+		}
+		exposureLimits.Symbols[symbol] = limit
+	}
+	if err := bbookEngine.SetExposureLimits(exposureLimits); err != nil {
+		log.Printf("[B-Book] Invalid exposure limits: %v, exposure is uncapped", err)
+	}
+	bbookEngine.SetExposureRerouteCallback(func(accountID int64, symbol, side string, volume float64) (string, error) {
+		fixGateway := server.GetFIXGateway()
+		if fixGateway == nil {
+			return "", fmt.Errorf("FIX gateway not available")
+		}
+		return fixGateway.SendMarketOrder(cfg.Hedging.SessionID, symbol, side, volume)
+	})
+
+	// ============================================
+	// PER-ACCOUNT TRADE WEBHOOKS
+	// ============================================
+	webhookDispatcher := notifications.NewAccountWebhookDispatcher(notifications.AccountWebhookConfig{
+		MaxAttempts:    cfg.Webhooks.MaxAttempts,
+		InitialBackoff: config.ParseDuration(cfg.Webhooks.InitialBackoff),
+		MaxBackoff:     config.ParseDuration(cfg.Webhooks.MaxBackoff),
+		Timeout:        config.ParseDuration(cfg.Webhooks.Timeout),
+	})
+	webhookDispatcher.Start()
+	bbookEngine.SubscribeTradeEvents(webhookDispatcher.HandleTradeEvent)
+	apiHandler.SetWebhookDispatcher(webhookDispatcher)
+
 	// ============================================
 	// INITIALIZE RATE LIMITING
 	// ============================================
@@ -458,12 +1024,23 @@ func main() {
 		log.Println("[RateLimit] Rate limiting disabled")
 	}
 
+	// Per-route latency and status codes of every request, slow ones logged
+	requestMetrics := middleware.NewRequestMetrics(http.DefaultServeMux, config.ParseDuration(cfg.HTTPMetrics.SlowRequestThreshold))
+
 	// ============================================
 	// REGISTER API ROUTES
 	// ============================================
 
 	// Health (no rate limit)
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		// Trading carries on while the engine database is down, but the
+		// changes are only in memory until it recovers
+		if status := bbookEngine.StoreStatus(); status.Failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "engine store failing since %s with %d writes pending: %s",
+				status.FailingSince.Format(time.RFC3339), status.Pending, status.LastError)
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
@@ -473,17 +1050,12 @@ func main() {
 		http.ServeFile(w, r, "swagger-ui.html")
 	})
 	http.HandleFunc("/swagger.yaml", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Content-Type", "application/yaml")
 		http.ServeFile(w, r, "swagger.yaml")
 	})
 
 	// ===== DYNAMIC BROKER CONFIGURATION API =====
 	http.HandleFunc("/api/config", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
 			return
@@ -552,47 +1124,72 @@ func main() {
 		}
 
 		if r.Method == "POST" {
-			// Update config from admin
-			var newConfig BrokerConfig
-			if err := json.NewDecoder(r.Body).Decode(&newConfig); err != nil {
-				http.Error(w, "Invalid request body", http.StatusBadRequest)
-				return
-			}
+			authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+				// Update config from admin
+				var newConfig BrokerConfig
+				if err := json.NewDecoder(r.Body).Decode(&newConfig); err != nil {
+					http.Error(w, "Invalid request body", http.StatusBadRequest)
+					return
+				}
+				if newConfig.ExecutionMode != "" {
+					if _, err := cbook.NormalizeExecutionMode(newConfig.ExecutionMode); err != nil {
+						http.Error(w, err.Error(), http.StatusBadRequest)
+						return
+					}
+				}
 
-			// Apply non-empty values
-			if newConfig.BrokerName != "" {
-				brokerConfig.BrokerName = newConfig.BrokerName
-			}
-			if newConfig.PriceFeedLP != "" {
-				brokerConfig.PriceFeedLP = newConfig.PriceFeedLP
-			}
-			if newConfig.ExecutionMode != "" {
-				brokerConfig.ExecutionMode = newConfig.ExecutionMode
-				executionMode = newConfig.ExecutionMode // Sync legacy variable
-			}
-			if newConfig.DefaultLeverage > 0 {
-				brokerConfig.DefaultLeverage = newConfig.DefaultLeverage
-			}
-			if newConfig.DefaultBalance > 0 {
-				brokerConfig.DefaultBalance = newConfig.DefaultBalance
-			}
-			if newConfig.MarginMode != "" {
-				brokerConfig.MarginMode = newConfig.MarginMode
-			}
+				// Apply non-empty values
+				oldConfig := brokerConfig
+				if newConfig.BrokerName != "" {
+					brokerConfig.BrokerName = newConfig.BrokerName
+				}
+				if newConfig.PriceFeedLP != "" {
+					brokerConfig.PriceFeedLP = newConfig.PriceFeedLP
+				}
+				if newConfig.ExecutionMode != "" {
+					cbookEngine.GetExecutionModes().SetGlobal(newConfig.ExecutionMode)
+					executionMode = cbookEngine.GetExecutionModes().Global() // Sync legacy variable
+					brokerConfig.ExecutionMode = executionMode
+				}
+				if newConfig.DefaultLeverage > 0 {
+					brokerConfig.DefaultLeverage = newConfig.DefaultLeverage
+				}
+				if newConfig.DefaultBalance > 0 {
+					brokerConfig.DefaultBalance = newConfig.DefaultBalance
+				}
+				if newConfig.MarginMode != "" {
+					brokerConfig.MarginMode = newConfig.MarginMode
+				}
 
-			log.Printf("[ADMIN] Config updated: %+v", brokerConfig)
+				// Keep the posted values so they are reloaded on restart
+				changes := config.BrokerOverrides{
+					BrokerName:      newConfig.BrokerName,
+					PriceFeedLP:     newConfig.PriceFeedLP,
+					DefaultLeverage: newConfig.DefaultLeverage,
+					DefaultBalance:  newConfig.DefaultBalance,
+					MarginMode:      newConfig.MarginMode,
+				}
+				if newConfig.ExecutionMode != "" {
+					changes.ExecutionMode = brokerConfig.ExecutionMode
+				}
+				if _, err := brokerOverrides.Save(changes); err != nil {
+					log.Printf("[Config] Failed to persist broker settings: %v", err)
+				}
+				adminHandler.AuditConfigChange(r, "BROKER_CONFIG_UPDATE", oldConfig, brokerConfig)
 
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": true,
-				"config":  brokerConfig,
-			})
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"success": true,
+					"config":  brokerConfig,
+				})
+			})(w, r)
 			return
 		}
 	})
 
 	// Auth
 	http.HandleFunc("/login", server.HandleLogin)
+	http.HandleFunc("/api/auth/refresh", server.HandleRefreshToken)
 
 	// ===== B-BOOK API (RTX Internal) =====
 	// These use our internal balance/equity, NOT OANDA
@@ -615,6 +1212,25 @@ func main() {
 	http.HandleFunc("/api/compliance/order-routing", complianceHandler.HandleOrderRouting)
 
 	// Audit Trail Export (7-year retention)
+	// The audit trail export covers the admin audit log, config changes included
+	complianceHandler.SetAuditTrailSource(func(start, end time.Time, entityType string) []handlers.AuditEntry {
+		recorded := adminHandler.AuditEntriesBetween(start, end, entityType)
+		entries := make([]handlers.AuditEntry, 0, len(recorded))
+		for _, entry := range recorded {
+			entries = append(entries, handlers.AuditEntry{
+				ID:         strconv.FormatInt(entry.ID, 10),
+				Timestamp:  entry.CreatedAt.UTC(),
+				UserID:     entry.AdminName,
+				EntityType: entry.EntityType,
+				EntityID:   strconv.FormatInt(entry.EntityID, 10),
+				Action:     entry.Action,
+				IPAddress:  entry.IPAddress,
+				UserAgent:  entry.UserAgent,
+				Details:    map[string]interface{}{"changes": entry.Changes, "status": entry.Status},
+			})
+		}
+		return entries
+	})
 	http.HandleFunc("/api/compliance/audit-trail", complianceHandler.HandleAuditTrail)
 
 	// Internal Audit Logging (WORM pattern)
@@ -622,11 +1238,7 @@ func main() {
 
 	// ===== ROUTING RULES MANAGEMENT =====
 	// Routing Rules CRUD endpoints
-	http.HandleFunc("/api/routing/rules", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-
+	http.HandleFunc("/api/routing/rules", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
 			return
@@ -643,13 +1255,9 @@ func main() {
 		}
 
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	})
-
-	http.HandleFunc("/api/routing/rules/", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, PUT, DELETE, OPTIONS")
+	}))
 
+	http.HandleFunc("/api/routing/rules/", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
 			return
@@ -666,13 +1274,9 @@ func main() {
 		}
 
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	})
-
-	http.HandleFunc("/api/routing/rules/reorder", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	}))
 
+	http.HandleFunc("/api/routing/rules/reorder", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
 			return
@@ -684,15 +1288,11 @@ func main() {
 		}
 
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	})
+	}))
 
 	// ===== ANALYTICS API - RULE EFFECTIVENESS =====
 	// Rule effectiveness metrics endpoints
-	http.HandleFunc("/api/analytics/rules/effectiveness", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-
+	http.HandleFunc("/api/analytics/rules/effectiveness", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
 			return
@@ -704,13 +1304,11 @@ func main() {
 		}
 
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	})
+	}))
 
-	http.HandleFunc("/api/analytics/rules/calculate", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	http.HandleFunc("/api/analytics/rules/timeseries", authService.RequireRole(auth.RoleAdmin, apiHandler.HandleGetRuleTimeSeries))
 
+	http.HandleFunc("/api/analytics/rules/calculate", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
 			return
@@ -722,14 +1320,10 @@ func main() {
 		}
 
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	})
+	}))
 
 	// Note: This must be registered AFTER /api/analytics/rules/effectiveness to avoid path conflicts
-	http.HandleFunc("/api/analytics/rules/", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-
+	http.HandleFunc("/api/analytics/rules/", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
 			return
@@ -741,10 +1335,12 @@ func main() {
 		}
 
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	})
+	}))
 
-	http.HandleFunc("/api/account/summary", apiHandler.HandleGetAccountSummary)
-	http.HandleFunc("/api/account/create", apiHandler.HandleCreateAccount)
+	http.HandleFunc("/api/account/summary", authService.RequireRole(auth.RoleTrader, apiHandler.HandleGetAccountSummary))
+	http.HandleFunc("/api/account/create", authService.RequireRole(auth.RoleAdmin, apiHandler.HandleCreateAccount))
+	http.HandleFunc("/api/account/webhook", authService.RequireRole(auth.RoleTrader, apiHandler.HandleAccountWebhook))
+	http.HandleFunc("/api/account/webhook/deliveries", authService.RequireRole(auth.RoleTrader, apiHandler.HandleAccountWebhookDeliveries))
 
 	// Positions (B-Book)
 	http.HandleFunc("/api/symbols", apiHandler.HandleGetSymbols)
@@ -761,8 +1357,6 @@ func main() {
 
 	// Symbol Management API (for Market Watch)
 	http.HandleFunc("/api/symbols/available", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 		w.Header().Set("Content-Type", "application/json")
 
 		if r.Method == "OPTIONS" {
@@ -834,8 +1428,56 @@ func main() {
 			{"symbol": "NATGASUSD", "name": "Natural Gas", "category": "commodities", "digits": 3},
 		}
 
-		// Check which symbols are currently subscribed via FIX
+		// Prefer the instruments the LPs report in their SecurityList; the list
+		// above then only supplies names and categories for known symbols
 		fixGateway := server.GetFIXGateway()
+		if fixGateway != nil {
+			known := make(map[string]map[string]interface{}, len(availableSymbols))
+			for _, sym := range availableSymbols {
+				known[sym["symbol"].(string)] = sym
+			}
+
+			sessionIDs := make([]string, 0)
+			for sessionID := range fixGateway.GetStatus() {
+				sessionIDs = append(sessionIDs, sessionID)
+			}
+			sort.Strings(sessionIDs)
+
+			discovered := make([]map[string]interface{}, 0)
+			seen := make(map[string]bool)
+			for _, sessionID := range sessionIDs {
+				for _, sec := range fixGateway.GetSecurities(sessionID) {
+					if seen[sec.Symbol] {
+						continue
+					}
+					seen[sec.Symbol] = true
+
+					entry := map[string]interface{}{
+						"symbol":       sec.Symbol,
+						"name":         sec.Description,
+						"category":     strings.ToLower(sec.SecurityType),
+						"digits":       sec.Digits(),
+						"securityType": sec.SecurityType,
+						"source":       sessionID,
+					}
+					if k, ok := known[sec.Symbol]; ok {
+						if sec.Description == "" {
+							entry["name"] = k["name"]
+						}
+						entry["category"] = k["category"]
+						if sec.TickSize == 0 {
+							entry["digits"] = k["digits"]
+						}
+					}
+					discovered = append(discovered, entry)
+				}
+			}
+			if len(discovered) > 0 {
+				availableSymbols = discovered
+			}
+		}
+
+		// Check which symbols are currently subscribed via FIX
 		if fixGateway != nil {
 			subscribedSymbols := fixGateway.GetSubscribedSymbols()
 			subscribedMap := make(map[string]bool)
@@ -854,9 +1496,6 @@ func main() {
 
 	// Subscribe to a symbol (triggers FIX market data subscription)
 	http.HandleFunc("/api/symbols/subscribe", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
 		w.Header().Set("Content-Type", "application/json")
 
 		if r.Method == "OPTIONS" {
@@ -924,8 +1563,6 @@ func main() {
 
 	// Get list of currently subscribed symbols
 	http.HandleFunc("/api/symbols/subscribed", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 		w.Header().Set("Content-Type", "application/json")
 
 		if r.Method == "OPTIONS" {
@@ -945,9 +1582,6 @@ func main() {
 
 	// Unsubscribe from a symbol (removes from FIX market data subscriptions)
 	http.HandleFunc("/api/symbols/unsubscribe", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
 		w.Header().Set("Content-Type", "application/json")
 
 		if r.Method == "OPTIONS" {
@@ -1012,20 +1646,22 @@ func main() {
 		})
 	})
 
-	http.HandleFunc("/api/positions", apiHandler.HandleGetPositions)
-	http.HandleFunc("/api/positions/close", apiHandler.HandleClosePosition)
-	http.HandleFunc("/api/positions/close-bulk", apiHandler.HandleCloseBulk)
+	http.HandleFunc("/api/positions", authService.RequireRole(auth.RoleTrader, apiHandler.HandleGetPositions))
+	// Closing and modifying: trader token for its own positions, or admin
+	http.HandleFunc("/api/positions/close", authService.RequireRole(auth.RoleTrader, apiHandler.HandleClosePosition))
+	http.HandleFunc("/api/positions/close-bulk", authService.RequireRole(auth.RoleTrader, apiHandler.HandleCloseBulk))
 
-	// Orders (B-Book)
-	http.HandleFunc("/api/orders", apiHandler.HandleGetOrders)
-	http.HandleFunc("/api/orders/market", apiHandler.HandlePlaceMarketOrder)
+	// Orders (B-Book): trader or admin token required
+	http.HandleFunc("/api/orders", authService.RequireRole(auth.RoleTrader, apiHandler.HandleGetOrders))
+	http.HandleFunc("/api/orders/market", authService.RequireRole(auth.RoleTrader, apiHandler.HandlePlaceMarketOrder))
 
 	// Trades & Ledger
-	http.HandleFunc("/api/trades", apiHandler.HandleGetTrades)
-	http.HandleFunc("/api/ledger", apiHandler.HandleGetLedger)
+	http.HandleFunc("/api/trades", authService.RequireRole(auth.RoleTrader, apiHandler.HandleGetTrades))
+	http.HandleFunc("/api/ledger", authService.RequireRole(auth.RoleTrader, apiHandler.HandleGetLedger))
 
 	// Position Management
-	http.HandleFunc("/api/positions/modify", apiHandler.HandleModifyPosition)
+	http.HandleFunc("/api/positions/modify", authService.RequireRole(auth.RoleTrader, apiHandler.HandleModifyPosition))
+	http.HandleFunc("/api/positions/tp-ladder", authService.RequireRole(auth.RoleTrader, apiHandler.HandleSetTPLadder))
 
 	// ===== ALERT ENDPOINTS =====
 	// Alert management API
@@ -1041,9 +1677,6 @@ func main() {
 	// Individual rule operations (must be after /api/alerts/rules to avoid conflicts)
 	http.HandleFunc("/api/alerts/rules/", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "OPTIONS" {
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, PUT, DELETE, OPTIONS")
 			w.WriteHeader(http.StatusOK)
 			return
 		}
@@ -1067,10 +1700,11 @@ func main() {
 	http.HandleFunc("/api/analytics/exposure/current", apiHandler.HandleCurrentExposure)
 	http.HandleFunc("/api/analytics/exposure/history/", apiHandler.HandleExposureHistory)
 
+	// Analytics - Position Aging
+	http.HandleFunc("/api/analytics/position-age", apiHandler.HandlePositionAge)
+
 	// Diagnostics - Market Data Status
 	http.HandleFunc("/api/diagnostics/market-data", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 		w.Header().Set("Content-Type", "application/json")
 
 		if r.Method == "OPTIONS" {
@@ -1103,20 +1737,19 @@ func main() {
 		diagnostics["fixSessions"] = fixStatus
 
 		// Get tick stats from hub
-		tickMutex.RLock()
+		tickMetrics := hub.GetTickMetrics()
 		tickStats := make(map[string]interface{})
-		for symbol, tick := range latestTicks {
-			tickStats[symbol] = map[string]interface{}{
-				"bid":       tick.Bid,
-				"ask":       tick.Ask,
-				"spread":    tick.Spread,
-				"timestamp": tick.Timestamp,
+		for _, sm := range tickMetrics.Symbols {
+			tickStats[sm.Symbol] = map[string]interface{}{
+				"bid":       sm.LastBid,
+				"ask":       sm.LastAsk,
+				"spread":    sm.LastAsk - sm.LastBid,
+				"timestamp": sm.LastTickAt.Unix(),
 			}
 		}
-		tickMutex.RUnlock()
 
 		diagnostics["latestTicks"] = tickStats
-		diagnostics["totalTicksReceived"] = totalTickCount
+		diagnostics["totalTicksReceived"] = tickMetrics.TotalTicks
 
 		// Calculate latency stats (if available)
 		latencyStats := map[string]interface{}{
@@ -1130,78 +1763,245 @@ func main() {
 	})
 
 	// ===== ADMIN ENDPOINTS =====
-	// For deposit/withdraw/adjust (Super	// Admin Endpoints
-	http.HandleFunc("/admin/accounts", apiHandler.HandleAdminGetAccounts)
-	http.HandleFunc("/admin/deposit", apiHandler.HandleAdminDeposit)
-	http.HandleFunc("/admin/withdraw", apiHandler.HandleAdminWithdraw)
-	http.HandleFunc("/admin/adjust", apiHandler.HandleAdminAdjust)
-	http.HandleFunc("/admin/bonus", apiHandler.HandleAdminBonus)
-	http.HandleFunc("/admin/ledger", apiHandler.HandleAdminGetLedgerAll)
-	http.HandleFunc("/admin/reset-password", apiHandler.HandleAdminResetPassword)
-	http.HandleFunc("/admin/account/update", apiHandler.HandleAdminUpdateAccount)
-	http.HandleFunc("/admin/symbols", apiHandler.HandleAdminGetSymbols)
-	http.HandleFunc("/admin/symbols/toggle", apiHandler.HandleAdminToggleSymbol)
-	http.HandleFunc("/api/admin/symbols/", apiHandler.HandleAdminUpdateSymbol)
-
-	// Execution Mode Toggle (A-Book vs B-Book)
-	http.HandleFunc("/admin/execution-mode", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	// Account funding, ledger and symbol administration: admin token required
+	http.HandleFunc("/admin/accounts", authService.RequireRole(auth.RoleAdmin, apiHandler.HandleAdminGetAccounts))
+	http.HandleFunc("/admin/deposit", authService.RequireRole(auth.RoleAdmin, apiHandler.HandleAdminDeposit))
+	http.HandleFunc("/admin/withdraw", authService.RequireRole(auth.RoleAdmin, apiHandler.HandleAdminWithdraw))
+	http.HandleFunc("/admin/adjust", authService.RequireRole(auth.RoleAdmin, apiHandler.HandleAdminAdjust))
+	http.HandleFunc("/admin/bonus", authService.RequireRole(auth.RoleAdmin, apiHandler.HandleAdminBonus))
+	http.HandleFunc("/admin/ledger", authService.RequireRole(auth.RoleAdmin, apiHandler.HandleAdminGetLedgerAll))
+	http.HandleFunc("/admin/reset-password", authService.RequireRole(auth.RoleAdmin, apiHandler.HandleAdminResetPassword))
+	http.HandleFunc("/admin/account/update", authService.RequireRole(auth.RoleAdmin, apiHandler.HandleAdminUpdateAccount))
+	http.HandleFunc("/admin/symbols", authService.RequireRole(auth.RoleAdmin, apiHandler.HandleAdminGetSymbols))
+	http.HandleFunc("/admin/symbols/toggle", authService.RequireRole(auth.RoleAdmin, apiHandler.HandleAdminToggleSymbol))
+	http.HandleFunc("/api/admin/symbols/", authService.RequireRole(auth.RoleAdmin, apiHandler.HandleAdminUpdateSymbol))
+
+	// Automatic hedging status and action history
+	// Broker-wide P/L, exposure and risk counts (admin token required)
+	http.HandleFunc("/admin/dashboard", authService.RequireRole(auth.RoleAdmin, apiHandler.HandleAdminDashboard))
+
+	http.HandleFunc("/admin/hedging", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
 
+		limit := 100
+		if l := r.URL.Query().Get("limit"); l != "" {
+			if parsed, err := strconv.Atoi(l); err == nil {
+				limit = parsed
+			}
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"config":   autoHedger.GetConfig(),
+			"exposure": bbookEngine.GetNetExposure(),
+			"hedges":   autoHedger.GetHedges(),
+			"actions":  autoHedger.GetActions(limit),
+		})
+	}))
+
+	// Market data broadcast pause (prices keep being recorded while paused)
+	http.HandleFunc("/admin/feed/broadcast", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
 			return
 		}
 
-		if r.Method == "GET" {
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"mode": executionMode,
-				"description": map[string]string{
-					"BBOOK": "Internal execution - orders processed by RTX engine using internal balance",
-					"ABOOK": "LP passthrough - orders routed to OANDA (requires active LP connection)",
-				},
-				"priceFeed": "OANDA", // Always OANDA for prices
-			})
+		if r.Method == "POST" {
+			var req struct {
+				Action string `json:"action"` // pause or resume
+				Reason string `json:"reason"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+
+			switch strings.ToLower(req.Action) {
+			case "pause":
+				hub.PauseBroadcast(req.Reason)
+			case "resume":
+				hub.ResumeBroadcast()
+			default:
+				http.Error(w, "action must be pause or resume", http.StatusBadRequest)
+				return
+			}
+		} else if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(hub.GetBroadcastStatus())
+	}))
+
+	// Per-symbol quote throttle: ticks moving less than minChangePct percent on
+	// both sides and in spread are neither stored nor broadcast. The default
+	// applies to stored ticks only; broadcasts keep ws.DefaultBroadcastThrottlePct.
+	http.HandleFunc("/admin/symbols/throttle", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
 			return
 		}
 
 		if r.Method == "POST" {
 			var req struct {
-				Mode string `json:"mode"`
+				Symbol       string   `json:"symbol"` // Empty sets the default
+				MinChangePct *float64 `json:"minChangePct"`
+				Reset        bool     `json:"reset"` // Return the symbol to the default
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
 			}
-			json.NewDecoder(r.Body).Decode(&req)
 
-			if req.Mode != "BBOOK" && req.Mode != "ABOOK" {
-				http.Error(w, "mode must be BBOOK or ABOOK", http.StatusBadRequest)
+			switch {
+			case req.Reset && req.Symbol != "":
+				tickStore.ClearThrottleThreshold(req.Symbol)
+			case req.MinChangePct == nil || *req.MinChangePct < 0:
+				http.Error(w, "minChangePct must be zero or positive", http.StatusBadRequest)
 				return
+			case req.Symbol == "":
+				tickStore.SetDefaultThrottleThreshold(*req.MinChangePct)
+			default:
+				tickStore.SetThrottleThreshold(req.Symbol, *req.MinChangePct)
 			}
+		} else if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
 
-			oldMode := executionMode
-			executionMode = req.Mode
-			log.Printf("[ADMIN] Execution mode changed: %s → %s", oldMode, executionMode)
+		defaultPct, symbols := tickStore.ThrottleThresholds()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"defaultMinChangePct":          defaultPct,
+			"broadcastDefaultMinChangePct": ws.DefaultBroadcastThrottlePct,
+			"symbols":                      symbols,
+		})
+	}))
 
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": true,
-				"oldMode": oldMode,
-				"newMode": executionMode,
-				"message": "Execution mode updated. Price feed remains connected to OANDA.",
-			})
+	// A-Book reconciliation: orders sent via FIX matched against the LP's execution reports
+	http.HandleFunc("/admin/abook/reconciliation", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
 			return
 		}
-	})
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(server.GetABookEngine().GetReconciliationReport())
+	}))
+
+	// Daily tick file disk usage per symbol and the retention policy rotating them
+	http.HandleFunc("/admin/tick-storage", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if compressor == nil {
+			http.Error(w, "Compression service not initialized", http.StatusServiceUnavailable)
+			return
+		}
+
+		if r.Method == "POST" {
+			var req struct {
+				ArchiveThresholdDays  *int `json:"archiveThresholdDays"`
+				DeletionThresholdDays *int `json:"deletionThresholdDays"`
+				MaxMBPerSymbol        *int `json:"maxMbPerSymbol"`
+				Sweep                 bool `json:"sweep"` // Apply the policy now
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+
+			policy := compressor.GetRetentionPolicy()
+			if req.ArchiveThresholdDays != nil {
+				policy.ArchiveThresholdDays = *req.ArchiveThresholdDays
+			}
+			if req.DeletionThresholdDays != nil {
+				policy.DeletionThresholdDays = *req.DeletionThresholdDays
+			}
+			if req.MaxMBPerSymbol != nil {
+				policy.MaxMBPerSymbol = *req.MaxMBPerSymbol
+			}
+			if policy.ArchiveThresholdDays < 0 || policy.DeletionThresholdDays < 0 || policy.MaxMBPerSymbol < 0 {
+				http.Error(w, "archiveThresholdDays, deletionThresholdDays and maxMbPerSymbol must be zero or positive", http.StatusBadRequest)
+				return
+			}
+			compressor.SetRetentionPolicy(policy)
+			if req.Sweep {
+				compressor.SweepRetention()
+			}
+		} else if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		usage := compressor.Usage()
+		var totalBytes int64
+		for _, u := range usage {
+			totalBytes += u.Bytes
+		}
+		lastSweep, rotations := compressor.LastSweep()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"policy":        compressor.GetRetentionPolicy(),
+			"symbols":       usage,
+			"totalBytes":    totalBytes,
+			"lastSweep":     lastSweep,
+			"lastRotations": rotations,
+		})
+	}))
+
+	// B-Book exposure caps enforced at order acceptance, with current net exposure
+	http.HandleFunc("/admin/exposure-limits", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == "POST" {
+			var limits core.ExposureLimits
+			if err := json.NewDecoder(r.Body).Decode(&limits); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+			if err := bbookEngine.SetExposureLimits(limits); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		} else if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"limits":   bbookEngine.GetExposureLimits(),
+			"exposure": bbookEngine.GetNetExposure(),
+		})
+	}))
+
+	// Execution Mode Toggle (A-Book vs B-Book), audited with the admin's identity
+	adminHandler.SetExecutionModeControl(
+		func() string { return executionMode },
+		func(mode string) error {
+			if err := cbookEngine.GetExecutionModes().SetGlobal(mode); err != nil {
+				return err
+			}
+			executionMode = cbookEngine.GetExecutionModes().Global()
+			brokerConfig.ExecutionMode = executionMode
+			return nil
+		})
+	http.HandleFunc("/admin/execution-mode", authService.RequireRole(auth.RoleAdmin, adminHandler.HandleExecutionMode))
 
 	// ===== LEGACY ENDPOINTS (OANDA passthrough) =====
 	// Keep for compatibility but prefer /api/ routes
 
 	http.HandleFunc("/order", server.HandlePlaceOrder) // OANDA
-	http.HandleFunc("/order/limit", server.HandlePlaceLimitOrder)
-	http.HandleFunc("/order/stop", server.HandlePlaceStopOrder)
-	http.HandleFunc("/order/stop-limit", server.HandlePlaceStopLimitOrder)
-	http.HandleFunc("/orders/pending", server.HandleGetPendingOrders)
-	http.HandleFunc("/order/cancel", server.HandleCancelOrder)
+	http.HandleFunc("/order/limit", authService.RequireRole(auth.RoleTrader, server.HandlePlaceLimitOrder))
+	http.HandleFunc("/order/stop", authService.RequireRole(auth.RoleTrader, server.HandlePlaceStopOrder))
+	http.HandleFunc("/order/stop-limit", authService.RequireRole(auth.RoleTrader, server.HandlePlaceStopLimitOrder))
+	http.HandleFunc("/orders/pending", authService.RequireRole(auth.RoleTrader, server.HandleGetPendingOrders))
+	http.HandleFunc("/order/cancel", authService.RequireRole(auth.RoleTrader, server.HandleCancelOrder))
 
 	// OANDA account (legacy)
 	http.HandleFunc("/account", server.HandleGetAccount) // Shows OANDA balance
@@ -1212,7 +2012,7 @@ func main() {
 	http.HandleFunc("/position/close-all", server.HandleCloseAll)
 	http.HandleFunc("/position/modify", server.HandleModifySLTP)
 	http.HandleFunc("/position/breakeven", server.HandleBreakeven)
-	http.HandleFunc("/position/trailing-stop", server.HandleSetTrailingStop)
+	http.HandleFunc("/position/trailing-stop", authService.RequireRole(auth.RoleTrader, server.HandleSetTrailingStop))
 
 	// Risk Calculator
 	http.HandleFunc("/risk/calculate-lot", server.HandleCalculateLot)
@@ -1238,7 +2038,7 @@ func main() {
 	http.HandleFunc("/ohlc", server.HandleGetOHLC)
 
 	// Admin (legacy)
-	http.HandleFunc("/admin/routes", server.HandleGetRoutes)
+	http.HandleFunc("/admin/routes", authService.RequireRole(auth.RoleAdmin, server.HandleGetRoutes))
 
 	// ===== NEW ADMIN SYSTEM =====
 	// Register comprehensive admin routes
@@ -1256,6 +2056,10 @@ func main() {
 	// ===== HISTORICAL DATA API =====
 	// Create history API handler
 	historyHandler := api.NewHistoryHandler(tickStore)
+	historyHandler.SetAuthService(authService)
+	if oandaAdapter != nil {
+		historyHandler.SetHistoryFetcher(oandaAdapter.FetchHistory)
+	}
 
 	// Register history routes on a router (using http.DefaultServeMux for now)
 	// In production, use gorilla/mux for better routing
@@ -1278,23 +2082,29 @@ func main() {
 	http.HandleFunc("/api/history/ticks/bulk", historyHandler.HandleBulkDownload)
 	http.HandleFunc("/api/history/available", historyHandler.HandleGetAvailable)
 	http.HandleFunc("/api/history/symbols", historyHandler.HandleGetSymbols)
-	http.HandleFunc("/admin/history/backfill", historyHandler.HandleBackfill)
+	http.HandleFunc("/admin/history/backfill", authService.RequireRole(auth.RoleAdmin, historyHandler.HandleBackfill))
+	http.HandleFunc("/admin/history/backfill/fetch", authService.RequireRole(auth.RoleAdmin, historyHandler.HandleFetchBackfill))
 	log.Println("[HistoryAPI] Historical data API routes registered")
 
 	// ===== ADMIN HISTORY MANAGEMENT (Comprehensive Controls) =====
 	adminHistoryHandler := api.NewAdminHistoryHandler(tickStore, authService)
-	http.HandleFunc("/admin/history/stats", adminHistoryHandler.HandleGetStats)
-	http.HandleFunc("/admin/history/import", adminHistoryHandler.HandleImportData)
-	http.HandleFunc("/admin/history/cleanup", adminHistoryHandler.HandleCleanupOldData)
-	http.HandleFunc("/admin/history/compress", adminHistoryHandler.HandleCompressData)
-	http.HandleFunc("/admin/history/backup", adminHistoryHandler.HandleBackup)
-	http.HandleFunc("/admin/history/monitoring", adminHistoryHandler.HandleGetMonitoring)
+	http.HandleFunc("/admin/history/stats", authService.RequireRole(auth.RoleAdmin, adminHistoryHandler.HandleGetStats))
+	http.HandleFunc("/admin/history/import", authService.RequireRole(auth.RoleAdmin, adminHistoryHandler.HandleImportData))
+	http.HandleFunc("/admin/history/cleanup", authService.RequireRole(auth.RoleAdmin, adminHistoryHandler.HandleCleanupOldData))
+	http.HandleFunc("/admin/history/compress", authService.RequireRole(auth.RoleAdmin, adminHistoryHandler.HandleCompressData))
+	http.HandleFunc("/admin/history/backup", authService.RequireRole(auth.RoleAdmin, adminHistoryHandler.HandleBackup))
+	http.HandleFunc("/admin/history/monitoring", authService.RequireRole(auth.RoleAdmin, adminHistoryHandler.HandleGetMonitoring))
 	log.Println("[AdminHistory] Admin data management routes registered")
 
+	// ===== BACKTESTING (isolated engine over stored ticks) =====
+	backtestManager := backtest.NewManager(tickStore.GetTicksInRange)
+	backtestHandler := backtest.NewHandler(backtestManager)
+	http.HandleFunc("/api/backtest", authService.RequireRole(auth.RoleAdmin, backtestHandler.HandleBacktest))
+	log.Println("[Backtest] Backtest API routes registered")
+
 	// ===== COMPRESSION MANAGEMENT ENDPOINTS =====
 	// Get compression metrics
-	http.HandleFunc("/admin/compression/metrics", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
+	http.HandleFunc("/admin/compression/metrics", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
 		if compressor == nil {
@@ -1313,12 +2123,10 @@ func main() {
 			"lastError":        metrics.LastError,
 			"lastCompression":  metrics.LastCompression,
 		})
-	})
+	}))
 
 	// Trigger manual compression
-	http.HandleFunc("/admin/compression/trigger", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	http.HandleFunc("/admin/compression/trigger", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
 		if r.Method == "OPTIONS" {
@@ -1348,12 +2156,10 @@ func main() {
 			"success": true,
 			"message": "Compression scan triggered in background",
 		})
-	})
+	}))
 
 	// Compress specific file manually
-	http.HandleFunc("/admin/compression/file", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	http.HandleFunc("/admin/compression/file", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
 		if r.Method == "OPTIONS" {
@@ -1394,25 +2200,22 @@ func main() {
 			"filePath": req.FilePath,
 			"message":  "File compressed successfully",
 		})
-	})
+	}))
 
 	log.Println("[Compression] Compression management endpoints registered")
 
 	// ===== ADMIN LP MANAGEMENT ENDPOINTS (v1 - /admin/lps) =====
-	http.HandleFunc("/admin/lps", func(w http.ResponseWriter, r *http.Request) {
+	http.HandleFunc("/admin/lps", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "GET" {
 			lpHandler.HandleListLPs(w, r)
 		} else if r.Method == "POST" {
 			lpHandler.HandleAddLP(w, r)
 		} else {
 			// Options
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 		}
-	})
+	}))
 
-	http.HandleFunc("/admin/lps/", func(w http.ResponseWriter, r *http.Request) {
+	http.HandleFunc("/admin/lps/", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		// Handle subpaths like /admin/lps/{id}/toggle
 		if os.Getenv("DEBUG") == "true" {
 			log.Printf("LP Request: %s %s", r.Method, r.URL.Path)
@@ -1439,16 +2242,18 @@ func main() {
 				return
 			}
 		}
-	})
+	}))
 
-	http.HandleFunc("/admin/lp-status", lpHandler.HandleLPStatus)
+	http.HandleFunc("/admin/lp-status", authService.RequireRole(auth.RoleAdmin, lpHandler.HandleLPStatus))
+	http.HandleFunc("/admin/lp-crossed", authService.RequireRole(auth.RoleAdmin, lpHandler.HandleCrossedMarketStats))
+	http.HandleFunc("/admin/lp-bbo", authService.RequireRole(auth.RoleAdmin, lpHandler.HandleBBO))
 
 	// ===== ADMIN LP MANAGEMENT ENDPOINTS (v2 - /api/admin/lp) =====
 	// GET /api/admin/liquidity-providers - List all LPs with status
-	http.HandleFunc("/api/admin/liquidity-providers", lpHandler.HandleAdminLiquidityProviders)
+	http.HandleFunc("/api/admin/liquidity-providers", authService.RequireRole(auth.RoleAdmin, lpHandler.HandleAdminLiquidityProviders))
 
 	// POST /api/admin/lp/{name}/toggle - Enable/disable LP by name
-	http.HandleFunc("/api/admin/lp/", func(w http.ResponseWriter, r *http.Request) {
+	http.HandleFunc("/api/admin/lp/", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		if strings.HasSuffix(r.URL.Path, "/toggle") {
 			lpHandler.HandleToggleLPByName(w, r)
 			return
@@ -1463,25 +2268,69 @@ func main() {
 				return
 			}
 		}
-	})
+	}))
 
 	// ===== FIX SESSION MANAGEMENT =====
 	// FIX Session Status
-	http.HandleFunc("/admin/fix/status", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
+	http.HandleFunc("/admin/fix/status", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
 		status := make(map[string]interface{})
 		status["sessions"] = server.GetFIXStatus()
 		json.NewEncoder(w).Encode(status)
-	})
+	}))
 
-	// Connect FIX Session
-	http.HandleFunc("/admin/fix/connect", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	// FIX Message Statistics (per-session counters by message type)
+	http.HandleFunc("/admin/fix/stats", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"sessions": server.GetFIXGateway().GetMessageStats(),
+		})
+	}))
+
+	// FIX reconnect breaker state
+	http.HandleFunc("/admin/fix/reconnect-breakers", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
 
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"breakers": server.GetFIXGateway().GetReconnectBreakers(),
+		})
+	}))
+
+	// Reset a tripped FIX reconnect breaker
+	http.HandleFunc("/admin/fix/reconnect-breakers/reset", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if r.Method != "POST" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req struct {
+			SessionID string `json:"sessionId"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := server.GetFIXGateway().ResetReconnectBreaker(req.SessionID); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":   true,
+			"sessionId": req.SessionID,
+		})
+	}))
+
+	// Connect FIX Session
+	http.HandleFunc("/admin/fix/connect", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
 			return
@@ -1506,14 +2355,10 @@ func main() {
 			"sessionId": req.SessionID,
 			"message":   "Connection initiated",
 		})
-	})
+	}))
 
 	// Disconnect FIX Session
-	http.HandleFunc("/admin/fix/disconnect", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-
+	http.HandleFunc("/admin/fix/disconnect", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
 			return
@@ -1538,14 +2383,10 @@ func main() {
 			"sessionId": req.SessionID,
 			"message":   "Disconnected",
 		})
-	})
+	}))
 
 	// Manual FIX Subscription endpoint
-	http.HandleFunc("/admin/fix/subscribe", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-
+	http.HandleFunc("/admin/fix/subscribe", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
 			return
@@ -1579,11 +2420,10 @@ func main() {
 			"symbol":    req.Symbol,
 			"mdReqId":   mdReqID,
 		})
-	})
+	}))
 
 	// Subscribe all forex symbols
-	http.HandleFunc("/admin/fix/subscribe-all", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
+	http.HandleFunc("/admin/fix/subscribe-all", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
 		fixGateway := server.GetFIXGateway()
@@ -1618,69 +2458,79 @@ func main() {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"subscriptions": results,
 		})
-	})
+	}))
 
-	// Auto-Connect FIX Sessions on startup
+	// Auto-Connect FIX Sessions on startup, subscribing once logon is confirmed
 	go func() {
 		time.Sleep(3 * time.Second) // Wait for other services to initialize
 
+		logonTimeout := config.ParseDuration(cfg.FIX.LogonTimeout)
+		fixGateway := server.GetFIXGateway()
+		if fixGateway == nil {
+			return
+		}
+
 		// Connect YOFX1 (Trading)
 		log.Println("[FIX] Auto-connecting YOFX1 session (Trading)...")
 		if err := server.ConnectToLP("YOFX1"); err != nil {
 			log.Printf("[FIX] Failed to auto-connect YOFX1: %v", err)
+		} else if err := fixGateway.WaitForLogon("YOFX1", logonTimeout); err != nil {
+			log.Printf("[FIX] YOFX1 not ready: %v", err)
 		}
 
-		// Connect YOFX2 (Market Data) after short delay
-		time.Sleep(2 * time.Second)
+		// Connect YOFX2 (Market Data)
 		log.Println("[FIX] Auto-connecting YOFX2 session (Market Data)...")
 		if err := server.ConnectToLP("YOFX2"); err != nil {
 			log.Printf("[FIX] Failed to auto-connect YOFX2: %v", err)
+			return
+		}
+		if err := fixGateway.WaitForLogon("YOFX2", logonTimeout); err != nil {
+			log.Printf("[FIX] Skipping auto-subscribe, YOFX2 not ready: %v", err)
+			return
+		}
+
+		// First request security list to discover available symbols
+		log.Println("[FIX] Requesting security list from YOFX2...")
+		if _, err := fixGateway.RequestSecurityList("YOFX2"); err != nil {
+			log.Printf("[FIX] Failed to request security list: %v", err)
+		}
+
+		// Subscribe to session open/close/halt updates for the trading calendar
+		if _, err := fixGateway.RequestTradingSessionStatus("YOFX2", "", true); err != nil {
+			log.Printf("[FIX] Failed to request trading session status: %v", err)
+		}
+
+		// Wait for security list response before subscribing
+		time.Sleep(2 * time.Second)
+
+		// Re-subscribe what YOFX2 was streaming before the restart. The full
+		// list of major pairs and metals is only the first-run default.
+		forexSymbols, err := fixGateway.RestoreSubscriptions("YOFX2")
+		if err != nil {
+			log.Printf("[FIX] Failed to restore YOFX2 subscriptions: %v", err)
+		}
+		if len(forexSymbols) > 0 {
+			log.Printf("[FIX] Restored %d persisted subscriptions for YOFX2", len(forexSymbols))
 		} else {
-			// First request security list to discover available symbols
-			time.Sleep(2 * time.Second)
-			fixGateway := server.GetFIXGateway()
-			if fixGateway != nil {
-				// Request available securities from YOFX
-				log.Println("[FIX] Requesting security list from YOFX2...")
-				if _, err := fixGateway.RequestSecurityList("YOFX2"); err != nil {
-					log.Printf("[FIX] Failed to request security list: %v", err)
-				}
+			forexSymbols = defaultForexSymbols
+		}
 
-				// Wait for security list response before subscribing
-				time.Sleep(2 * time.Second)
-
-				// All major forex pairs and metals available on YOFX
-				forexSymbols := []string{
-					// Major pairs
-					"EURUSD", "GBPUSD", "USDJPY", "USDCHF", "USDCAD",
-					"AUDUSD", "NZDUSD",
-					// Cross pairs
-					"EURGBP", "EURJPY", "GBPJPY", "EURAUD", "EURCAD",
-					"EURCHF", "AUDCAD", "AUDCHF", "AUDJPY", "AUDNZD",
-					"CADCHF", "CADJPY", "CHFJPY", "GBPAUD", "GBPCAD",
-					"GBPCHF", "GBPNZD", "NZDCAD", "NZDCHF", "NZDJPY",
-					// Metals
-					"XAUUSD", "XAGUSD",
-				}
-				log.Printf("[FIX] Auto-subscribing to %d forex symbols on YOFX2...", len(forexSymbols))
-				for _, symbol := range forexSymbols {
-					// Step 1: Request security definition (35=c) for FIX 4.4 compliance
-					if _, err := fixGateway.RequestSecurityDefinition("YOFX2", symbol); err != nil {
-						log.Printf("[FIX] SecurityDefinition request failed for %s: %v", symbol, err)
-					} else {
-						log.Printf("[FIX] SecurityDefinition requested for %s", symbol)
-					}
+		// Step 1: Request security definitions (35=c) for FIX 4.4 compliance
+		for _, symbol := range forexSymbols {
+			if _, err := fixGateway.RequestSecurityDefinition("YOFX2", symbol); err != nil {
+				log.Printf("[FIX] SecurityDefinition request failed for %s: %v", symbol, err)
+			}
+		}
 
-					// Step 2: Subscribe to market data (35=V)
-					if _, err := fixGateway.SubscribeMarketData("YOFX2", symbol); err != nil {
-						log.Printf("[FIX] Failed to subscribe %s: %v", symbol, err)
-					} else {
-						log.Printf("[FIX] Subscribed to %s market data", symbol)
-					}
-					time.Sleep(100 * time.Millisecond) // Rate limit subscriptions
-				}
+		// Step 2: Subscribe to market data (35=V), rate limited
+		log.Printf("[FIX] Auto-subscribing to %d forex symbols on YOFX2...", len(forexSymbols))
+		subscribed := 0
+		for _, result := range fixGateway.SubscribeMarketDataBatch("YOFX2", forexSymbols, 100*time.Millisecond) {
+			if result.Err == nil {
+				subscribed++
 			}
 		}
+		log.Printf("[FIX] Subscribed to %d/%d symbols on YOFX2", subscribed, len(forexSymbols))
 	}()
 
 	// Pipe FIX market data to WebSocket hub
@@ -1694,7 +2544,7 @@ func main() {
 		var tickCount int64 = 0
 		log.Println("[FIX-WS] Starting FIX market data → WebSocket hub pipe...")
 
-		for md := range fixGateway.GetMarketData() {
+		for md := range fixGateway.Subscribe() {
 			tickCount++
 			if tickCount%100 == 1 {
 				log.Printf("[FIX-WS] Piping FIX tick #%d: %s Bid=%.5f Ask=%.5f",
@@ -1711,24 +2561,79 @@ func main() {
 				LP:        "YOFX", // FIX LP source
 			}
 
-			// Store latest tick for debugging
-			tickMutex.Lock()
-			latestTicks[md.Symbol] = tick
-			totalTickCount++
-			tickMutex.Unlock()
+			atomic.AddInt64(&fixTickCount, 1)
 			hub.BroadcastTick(tick)
 		}
 		log.Println("[FIX-WS] FIX market data pipe closed!")
 	}()
 
+	// Feed LP trading session states into the risk engine's trading calendar
+	go func() {
+		fixGateway := server.GetFIXGateway()
+		if fixGateway == nil {
+			return
+		}
+
+		riskEngine := server.GetRiskEngine()
+		for st := range fixGateway.GetTradingSessionStatuses() {
+			riskEngine.UpdateSessionStatus(risk.LPSessionStatus{
+				Symbol:           st.Symbol,
+				TradingSessionID: st.TradingSessionID,
+				State:            st.Status,
+				CloseTime:        st.CloseTime,
+				ReportedAt:       st.Timestamp,
+			})
+		}
+	}()
+
+	// Push FIX session state changes to the analytics websocket instead of polling
+	go func() {
+		fixGateway := server.GetFIXGateway()
+		if fixGateway == nil {
+			return
+		}
+
+		for event := range fixGateway.StateEvents() {
+			log.Printf("[FIX] %s %s -> %s (%s)", event.SessionID, event.OldStatus, event.NewStatus, event.Reason)
+			analyticsHub.PublishLPSessionState(&websocket.LPSessionState{
+				Timestamp: event.Timestamp.UTC().Format(time.RFC3339Nano),
+				SessionID: event.SessionID,
+				OldStatus: event.OldStatus,
+				NewStatus: event.NewStatus,
+				Reason:    event.Reason,
+			})
+		}
+	}()
+
+	// Take symbol specs from the LP's SecurityDefinition responses when enabled
+	go func() {
+		fixGateway := server.GetFIXGateway()
+		if fixGateway == nil || !cfg.FIX.AutoSymbolSpecs {
+			return
+		}
+
+		for def := range fixGateway.GetSecurityDefinitions() {
+			if def.Symbol == "" {
+				continue
+			}
+			spec := bbookEngine.ApplyLPSymbolSpec(def.Symbol, core.LPSymbolSpec{
+				TickSize:     def.TickSize,
+				ContractSize: def.ContractMultiplier,
+				MinQty:       def.MinTradeVol,
+				QtyStep:      def.RoundLot,
+				MaxQty:       def.MaxTradeVol,
+				Currency:     def.Currency,
+			})
+			hub.UpdateSymbol(spec)
+		}
+	}()
+
 	// Simulated market data fallback - uses OANDA historical data when LP unavailable
 	go func() {
 		// Wait 30 seconds to see if real market data arrives
 		time.Sleep(30 * time.Second)
 
-		tickMutex.RLock()
-		hasRealData := totalTickCount > 0
-		tickMutex.RUnlock()
+		hasRealData := atomic.LoadInt64(&fixTickCount) > 0
 
 		if hasRealData {
 			log.Println("[SIM-MD] Real market data detected, simulation not needed")
@@ -1788,9 +2693,7 @@ func main() {
 		defer ticker.Stop()
 
 		for range ticker.C {
-			tickMutex.RLock()
-			realDataArrived := totalTickCount > 0
-			tickMutex.RUnlock()
+			realDataArrived := atomic.LoadInt64(&fixTickCount) > 0
 
 			if realDataArrived {
 				log.Println("[SIM-MD] Real market data now available - stopping historical simulation")
@@ -1798,41 +2701,173 @@ func main() {
 			}
 
 			// Generate tick for each symbol using historical data
-			for symbol, cache := range historicalDataLoaded {
+			for _, cache := range historicalDataLoaded {
 				tick := cache.getNextHistoricalTick()
-
-				tickMutex.Lock()
-				latestTicks[symbol] = tick
-				tickMutex.Unlock()
-
 				hub.BroadcastTick(tick)
 			}
 		}
 	}()
 
 	// Debug endpoint to check market data flow
-	http.HandleFunc("/admin/fix/ticks", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	http.HandleFunc("/admin/fix/ticks", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		tickMutex.RLock()
+		tickMetrics := hub.GetTickMetrics()
+		latestTicks := make(map[string]*ws.MarketTick)
+		for _, sm := range tickMetrics.Symbols {
+			if tick := hub.GetLatestPrice(sm.Symbol); tick != nil {
+				latestTicks[sm.Symbol] = tick
+			}
+		}
 		response := map[string]interface{}{
-			"totalTickCount": totalTickCount,
-			"symbolCount":    len(latestTicks),
+			"totalTickCount": tickMetrics.TotalTicks,
+			"symbolCount":    tickMetrics.SymbolCount,
 			"latestTicks":    latestTicks,
 		}
-		tickMutex.RUnlock()
 
 		json.NewEncoder(w).Encode(response)
-	})
+	}))
 
-	// Backend restart endpoint (graceful)
-	http.HandleFunc("/admin/restart", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	// Tick metrics: per-symbol and global tick counters since the last reset
+	http.HandleFunc("/admin/ticks/metrics", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
 
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(hub.GetTickMetrics())
+	}))
+
+	// WebSocket hub stats; clients_connected only counts connections still
+	// answering heartbeat pings. Served under the path the E2E test polls,
+	// with the per-route API latency alongside.
+	http.HandleFunc("/api/admin/pipeline-stats", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": hub.GetStats(), "http_routes": requestMetrics.Snapshot()})
+	}))
+
+	// Prometheus scrape endpoint: the pipeline-stats counters plus FIX session
+	// status, per-symbol quote age and engine order counts
+	metricsSources := monitoring.PipelineSources{
+		Hub:           hub.GetStats,
+		OHLCBars:      tickStore.GetOHLCCache().BarsGenerated,
+		QuoteAges:     hub.QuoteAges,
+		OrderCounts:   bbookEngine.OrderCounts,
+		OpenPositions: func() int { return len(bbookEngine.GetAllPositions()) },
+		HTTPRoutes:    requestMetrics.Snapshot,
+	}
+	if fixGateway := server.GetFIXGateway(); fixGateway != nil {
+		metricsSources.FIXSessions = fixGateway.GetStatus
+	}
+	http.Handle("/metrics", monitoring.PipelineMetricsHandler(metricsSources))
+
+	// Global feed health: whether market orders are currently accepted
+	http.HandleFunc("/admin/feed/health", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(hub.GetFeedHealth())
+	}))
+
+	// Price sanity band: rejection counters and per-symbol overrides
+	http.HandleFunc("/admin/feed/price-band", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method == "POST" {
+			var req struct {
+				Symbol string `json:"symbol"`
+				ws.PriceBandConfig
+				Clear bool `json:"clear"` // Drop the override, back to the default band
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Symbol == "" {
+				http.Error(w, "symbol is required", http.StatusBadRequest)
+				return
+			}
+			if req.MaxDeviationPct < 0 || req.Window < 0 || req.RecalibrateAfter < 0 {
+				http.Error(w, "band values must not be negative", http.StatusBadRequest)
+				return
+			}
+			if req.Clear {
+				hub.ClearPriceBand(req.Symbol)
+				log.Printf("[Hub] Price band override for %s removed", req.Symbol)
+			} else {
+				hub.SetPriceBand(req.Symbol, req.PriceBandConfig)
+				log.Printf("[Hub] Price band for %s set to %.2f%% over %d ticks", req.Symbol, req.MaxDeviationPct, req.Window)
+			}
+		} else if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(hub.GetPriceBandStats())
+	}))
+
+	// Reset tick metrics to start a new measurement window
+	http.HandleFunc("/admin/ticks/metrics/reset", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if r.Method != "POST" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		hub.ResetTickMetrics()
+		log.Println("[Ticks] Tick metrics reset")
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"message": "Tick metrics reset",
+		})
+	}))
+
+	// Log out of every FIX session before exiting, so LPs do not refuse the
+	// next logon over a session they still consider active
+	shutdownFIX := func() {
+		fixGateway := server.GetFIXGateway()
+		if fixGateway == nil {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := fixGateway.Shutdown(ctx); err != nil {
+			log.Printf("[FIX] %v", err)
+		}
+	}
+
+	// Serves every route below; SIGTERM/SIGINT and /admin/restart drain its
+	// in-flight requests before the process exits
+	var httpServer *httpserver.Server
+
+	// Backend restart endpoint (graceful)
+	http.HandleFunc("/admin/restart", authService.RequireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
 			return
@@ -1841,26 +2876,25 @@ func main() {
 		log.Println("[Admin] Backend restart requested from Admin Panel")
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": true,
-			"message": "Restart initiated. Server will restart in 2 seconds.",
+			"message": "Restart initiated. Server will restart once in-flight requests finish.",
 		})
 
-		// Graceful restart - exit and let process manager (systemd, pm2, etc.) restart
+		// Graceful restart - drain, exit and let process manager (systemd, pm2, etc.) restart
 		go func() {
-			time.Sleep(2 * time.Second)
 			log.Println("[Admin] Performing graceful shutdown for restart...")
-			os.Exit(0)
+			httpServer.Shutdown()
 		}()
-	})
+	}))
 
 	// WebSocket for real-time prices AND account updates
 	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
 		ws.ServeWs(hub, w, r)
 	})
 
-	// WebSocket for B-Book account updates
+	// WebSocket for B-Book account updates: snapshot on connect, then balance,
+	// margin, position and material equity changes for the token's account
 	http.HandleFunc("/ws/account", func(w http.ResponseWriter, r *http.Request) {
-		// TODO: Implement account-specific WebSocket
-		ws.ServeWs(hub, w, r)
+		ws.ServeAccountWs(hub, pnlEngine, w, r)
 	})
 
 	// WebSocket for analytics (routing metrics, LP performance, exposure, alerts)
@@ -1878,21 +2912,37 @@ func main() {
 	log.Println("    GET  /api/positions         - RTX Open Positions")
 	log.Println("    POST /api/orders/market     - Execute Market Order")
 	log.Println("    POST /api/positions/close   - Close Position")
+	log.Println("    POST /api/positions/tp-ladder - Set Take-Profit Ladder")
 	log.Println("    GET  /api/trades            - Trade History")
 	log.Println("    GET  /api/ledger            - Transaction History")
+	log.Println("    PUT  /api/account/webhook   - Account Trade Webhook")
+	log.Println("    GET  /api/account/webhook/deliveries - Webhook Delivery Log")
 	log.Println("")
 	log.Println("  ANALYTICS API:")
 	log.Println("    GET  /api/analytics/exposure/heatmap        - Exposure Heatmap Data")
 	log.Println("    GET  /api/analytics/exposure/current        - Current Exposure by Symbol")
 	log.Println("    GET  /api/analytics/exposure/history/{sym}  - Symbol Exposure Timeline")
+	log.Println("    GET  /api/analytics/position-age            - Position Holding Time Stats")
 	log.Println("")
 	log.Println("  ADMIN ENDPOINTS:")
 	log.Println("    GET  /admin/accounts        - List All Accounts")
+	log.Println("    GET  /admin/dashboard       - Broker P/L and Risk Dashboard")
 	log.Println("    POST /admin/deposit         - Add Funds (Bank/Crypto)")
 	log.Println("    POST /admin/withdraw        - Withdraw Funds")
 	log.Println("    POST /admin/adjust          - Manual Adjustment")
+	log.Println("    POST /admin/feed/broadcast  - Pause/Resume Price Broadcast")
+	log.Println("    POST /admin/symbols/throttle - Per-Symbol Quote Throttle")
 	log.Println("    POST /admin/bonus           - Add Bonus")
 	log.Println("    GET  /admin/ledger          - View All Transactions")
+	log.Println("    GET  /admin/ticks/metrics   - Per-Symbol Tick Counters")
+	log.Println("    POST /admin/ticks/metrics/reset - Reset Tick Counters")
+	log.Println("    GET  /admin/feed/health     - Market Data Outage Gate")
+	log.Println("    GET  /api/admin/pipeline-stats - WebSocket Clients, Tick Counters & API Latency")
+	log.Println("    GET  /metrics               - Prometheus Metrics")
+	log.Println("    GET  /admin/feed/price-band - Price Sanity Band Rejections")
+	log.Println("    POST /admin/feed/price-band - Set Per-Symbol Price Band")
+	log.Println("    GET  /admin/fix/reconnect-breakers - FIX Reconnect Breaker State")
+	log.Println("    POST /admin/fix/reconnect-breakers/reset - Reset FIX Reconnect Breaker")
 	log.Println("")
 	if brokerConfig.DefaultBalance > 0 {
 		log.Printf("  Demo Account: Demo User | Balance: $%.2f", brokerConfig.DefaultBalance)
@@ -1913,7 +2963,9 @@ func main() {
 	if rateLimiter != nil {
 		defer rateLimiter.Stop()
 	}
-	if compressor != nil && compressor.IsEnabled() {
+	defer autoHedger.Stop()
+	defer webhookDispatcher.Stop()
+	if compressor != nil {
 		defer compressor.Stop()
 	}
 
@@ -1928,10 +2980,36 @@ func main() {
 	} else {
 		handler = http.DefaultServeMux
 	}
+	// CORS outside the rate limiter so 429s stay readable cross-origin
+	cors := middleware.NewCORS(middleware.CORSOptions{
+		AllowedOrigins: cfg.CORS.AllowedOrigins,
+		AllowedMethods: cfg.CORS.AllowedMethods,
+		AllowedHeaders: cfg.CORS.AllowedHeaders,
+		MaxAge:         config.ParseDuration(cfg.CORS.MaxAge),
+	})
+	handler = cors.Middleware(handler)
+	handler = requestMetrics.Middleware(handler)
+
+	// After the drain: log out of the FIX sessions and close the WebSocket
+	// connections, which the HTTP server does not track once upgraded
+	httpServer = httpserver.New(port, handler, config.ParseDuration(cfg.ShutdownTimeout))
+	httpServer.OnShutdown(func(ctx context.Context) { shutdownFIX() })
+	httpServer.OnShutdown(func(ctx context.Context) {
+		if err := bbookEngine.FlushStore(ctx); err != nil {
+			log.Printf("[B-Book] Engine changes not written to the store: %v", err)
+		}
+	})
+	httpServer.OnShutdown(func(ctx context.Context) {
+		if err := hub.Close(ctx); err != nil {
+			log.Printf("[Hub] Close: %v", err)
+		}
+	})
+	httpServer.ShutdownOnSignal(syscall.SIGINT, syscall.SIGTERM)
 
-	if err := http.ListenAndServe(port, handler); err != nil {
+	if err := httpServer.ListenAndServe(); err != nil {
 		log.Fatal(err)
 	}
+	log.Println("Server stopped")
 }
 
 func parseFloat(s string) (float64, error) {
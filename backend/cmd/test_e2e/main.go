@@ -41,6 +41,7 @@ func main() {
 	redisAddr := flag.String("redis", "localhost:6379", "Redis address")
 	wsURL := flag.String("ws", "ws://localhost:8080", "WebSocket URL")
 	token := flag.String("token", "", "Auth token (if empty, will try to get from login)")
+	adminToken := flag.String("admin-token", "", "Admin token for /api/admin/pipeline-stats")
 	duration := flag.Duration("duration", 30*time.Second, "Test duration")
 	flag.Parse()
 
@@ -57,7 +58,7 @@ func main() {
 
 	// 1. Check backend health
 	fmt.Println("1. Checking backend health...")
-	statsOk, statsData := checkBackendHealth(*backendURL)
+	statsOk, statsData := checkBackendHealth(*backendURL, *adminToken)
 	if !statsOk {
 		log.Fatal("Backend health check failed")
 	}
@@ -173,7 +174,7 @@ testDone:
 	fmt.Println("\n========================================")
 	fmt.Println("Pipeline Statistics")
 	fmt.Println("========================================")
-	_, finalStats := checkBackendHealth(*backendURL)
+	_, finalStats := checkBackendHealth(*backendURL, *adminToken)
 	fmt.Printf("Total ticks processed by pipeline: %d\n", finalStats.Data.TicksProcessed)
 	fmt.Printf("Pipeline latency: %.2f ms\n", finalStats.Data.AvgLatencyMs)
 	fmt.Printf("Dropped ticks: %d\n", finalStats.Data.TicksDropped)
@@ -195,12 +196,20 @@ testDone:
 	}
 }
 
-func checkBackendHealth(backendURL string) (bool, *PipelineStats) {
-	resp, err := http.Get(fmt.Sprintf("%s/api/admin/pipeline-stats", backendURL))
+func checkBackendHealth(backendURL, adminToken string) (bool, *PipelineStats) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/admin/pipeline-stats", backendURL), nil)
+	if err != nil {
+		return false, nil
+	}
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return false, nil
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, nil
+	}
 
 	var stats PipelineStats
 	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
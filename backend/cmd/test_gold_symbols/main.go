@@ -29,6 +29,7 @@ func main() {
 	// Initialize gateway
 	log.Println("\n[1/3] Initializing FIX Gateway...")
 	gateway := fix.NewFIXGateway()
+	marketData := gateway.Subscribe()
 
 	// Connect to YoFX2
 	log.Println("\n[2/3] Connecting to YoFX2...")
@@ -70,7 +71,7 @@ func main() {
 		received := false
 
 		select {
-		case md := <-gateway.GetMarketData():
+		case md := <-marketData:
 			if md.Symbol == symbol {
 				log.Printf("   ✅ SUCCESS! Bid: %.2f Ask: %.2f\n", md.Bid, md.Ask)
 				results[symbol] = "SUCCESS"
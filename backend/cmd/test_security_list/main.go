@@ -16,6 +16,7 @@ func main() {
 	log.Println("╚══════════════════════════════════════════════════════════╝")
 
 	gateway := fix.NewFIXGateway()
+	marketData := gateway.Subscribe()
 
 	// Step 1: Connect
 	log.Println("\n[1/4] Connecting to YOFX2...")
@@ -64,7 +65,7 @@ func main() {
 
 	for !gotData {
 		select {
-		case md := <-gateway.GetMarketData():
+		case md := <-marketData:
 			log.Printf("✅ MarketDataSnapshot received!")
 			log.Printf("   Symbol: %s", md.Symbol)
 			log.Printf("   Bid: %.5f | Ask: %.5f", md.Bid, md.Ask)
@@ -73,7 +74,7 @@ func main() {
 		case reject := <-gateway.GetMarketDataRejects():
 			log.Printf("❌ MarketDataReject received!")
 			log.Printf("   MDReqID: %s", reject.MDReqID)
-			log.Printf("   Reason: %s", reject.Reason)
+			log.Printf("   Reason: %s (%s)", reject.Reason, reject.ReasonText)
 			log.Printf("   Text: %s", reject.Text)
 			gotData = true
 
@@ -104,6 +104,11 @@ func (cs *ComplianceSystem) OnPositionClosed(userID, clientID, tradeID, symbol s
 	cs.AuditService.LogPositionClosed(userID, clientID, tradeID, symbol, positionData, ip, ua)
 }
 
+// OnExecutionSlippage records an A-Book fill against its expected price for best execution reporting
+func (cs *ComplianceSystem) OnExecutionSlippage(orderID, symbol, lpName string, expectedPrice, executedPrice, quantity float64, latencyMs int64) {
+	cs.BestExecService.TrackExecution(orderID, symbol, "A_BOOK", lpName, expectedPrice, executedPrice, quantity, latencyMs, "AGGRESSIVE")
+}
+
 // OnWithdrawal hooks into withdrawal requests
 func (cs *ComplianceSystem) OnWithdrawal(userID, clientID string, amount float64, ip, ua string) {
 	cs.AuditService.LogWithdrawal(userID, clientID, amount, ip, ua)
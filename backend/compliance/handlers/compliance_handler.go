@@ -38,7 +38,6 @@ func NewComplianceHandler(
 // Transaction Reporting Endpoints
 
 func (h *ComplianceHandler) HandleGetPendingReports(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -55,7 +54,6 @@ func (h *ComplianceHandler) HandleGetPendingReports(w http.ResponseWriter, r *ht
 }
 
 func (h *ComplianceHandler) HandleSubmitReport(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -80,8 +78,6 @@ func (h *ComplianceHandler) HandleSubmitReport(w http.ResponseWriter, r *http.Re
 }
 
 func (h *ComplianceHandler) HandleDailyReport(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
-
 	summary, err := h.transactionService.GenerateDailyReport()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -95,7 +91,6 @@ func (h *ComplianceHandler) HandleDailyReport(w http.ResponseWriter, r *http.Req
 // KYC/AML Endpoints
 
 func (h *ComplianceHandler) HandleCreateKYC(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -129,7 +124,6 @@ func (h *ComplianceHandler) HandleCreateKYC(w http.ResponseWriter, r *http.Reque
 }
 
 func (h *ComplianceHandler) HandleScreenPEP(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -156,7 +150,6 @@ func (h *ComplianceHandler) HandleScreenPEP(w http.ResponseWriter, r *http.Reque
 }
 
 func (h *ComplianceHandler) HandleScreenSanctions(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -187,7 +180,6 @@ func (h *ComplianceHandler) HandleScreenSanctions(w http.ResponseWriter, r *http
 }
 
 func (h *ComplianceHandler) HandleFileSAR(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -215,8 +207,6 @@ func (h *ComplianceHandler) HandleFileSAR(w http.ResponseWriter, r *http.Request
 // Audit Trail Endpoints
 
 func (h *ComplianceHandler) HandleGetAuditHistory(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
-
 	clientID := r.URL.Query().Get("clientId")
 	startStr := r.URL.Query().Get("start")
 	endStr := r.URL.Query().Get("end")
@@ -235,8 +225,6 @@ func (h *ComplianceHandler) HandleGetAuditHistory(w http.ResponseWriter, r *http
 }
 
 func (h *ComplianceHandler) HandleVerifyAuditIntegrity(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
-
 	startStr := r.URL.Query().Get("start")
 	endStr := r.URL.Query().Get("end")
 
@@ -257,8 +245,6 @@ func (h *ComplianceHandler) HandleVerifyAuditIntegrity(w http.ResponseWriter, r
 }
 
 func (h *ComplianceHandler) HandleExportAuditTrail(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
-
 	startStr := r.URL.Query().Get("start")
 	endStr := r.URL.Query().Get("end")
 	format := r.URL.Query().Get("format") // JSON, CSV, XML
@@ -279,7 +265,6 @@ func (h *ComplianceHandler) HandleExportAuditTrail(w http.ResponseWriter, r *htt
 // Best Execution Endpoints
 
 func (h *ComplianceHandler) HandleGenerateRTS27(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -307,8 +292,6 @@ func (h *ComplianceHandler) HandleGenerateRTS27(w http.ResponseWriter, r *http.R
 }
 
 func (h *ComplianceHandler) HandleGetExecutionQuality(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
-
 	lpName := r.URL.Query().Get("lp")
 	symbol := r.URL.Query().Get("symbol")
 	hours, _ := strconv.Atoi(r.URL.Query().Get("hours"))
@@ -336,8 +319,6 @@ func (h *ComplianceHandler) HandleGetExecutionQuality(w http.ResponseWriter, r *
 // Leverage Limits Endpoints
 
 func (h *ComplianceHandler) HandleValidateLeverage(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
-
 	var req struct {
 		Jurisdiction      string `json:"jurisdiction"`
 		ClientClass       string `json:"clientClass"`
@@ -376,8 +357,6 @@ func (h *ComplianceHandler) HandleValidateLeverage(w http.ResponseWriter, r *htt
 }
 
 func (h *ComplianceHandler) HandleGetESMALimits(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
-
 	clientClass := r.URL.Query().Get("clientClass")
 	limits := h.leverageService.GetESMALimits(models.ClientClassification(clientClass))
 
@@ -387,12 +366,6 @@ func (h *ComplianceHandler) HandleGetESMALimits(w http.ResponseWriter, r *http.R
 
 // Helper functions
 
-func setCORSHeaders(w http.ResponseWriter) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-}
-
 func getQualityRating(score float64) string {
 	if score >= 90 {
 		return "EXCELLENT"
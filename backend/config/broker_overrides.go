@@ -0,0 +1,124 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// BrokerOverrides are broker settings changed at runtime through /api/config.
+// Zero fields leave the environment or config file value in place.
+type BrokerOverrides struct {
+	BrokerName      string  `json:"brokerName,omitempty"`
+	PriceFeedLP     string  `json:"priceFeedLP,omitempty"`
+	ExecutionMode   string  `json:"executionMode,omitempty"`
+	DefaultLeverage int     `json:"defaultLeverage,omitempty"`
+	DefaultBalance  float64 `json:"defaultBalance,omitempty"`
+	MarginMode      string  `json:"marginMode,omitempty"`
+}
+
+// ApplyTo overwrites the broker settings the overrides set
+func (o BrokerOverrides) ApplyTo(broker *BrokerConfig) {
+	if o.BrokerName != "" {
+		broker.Name = o.BrokerName
+	}
+	if o.PriceFeedLP != "" {
+		broker.PriceFeedLP = o.PriceFeedLP
+	}
+	if o.ExecutionMode != "" {
+		broker.ExecutionMode = o.ExecutionMode
+	}
+	if o.DefaultLeverage > 0 {
+		broker.DefaultLeverage = o.DefaultLeverage
+	}
+	if o.DefaultBalance > 0 {
+		broker.DefaultBalance = o.DefaultBalance
+	}
+	if o.MarginMode != "" {
+		broker.MarginMode = o.MarginMode
+	}
+}
+
+// merge overwrites the fields changes sets
+func (o *BrokerOverrides) merge(changes BrokerOverrides) {
+	if changes.BrokerName != "" {
+		o.BrokerName = changes.BrokerName
+	}
+	if changes.PriceFeedLP != "" {
+		o.PriceFeedLP = changes.PriceFeedLP
+	}
+	if changes.ExecutionMode != "" {
+		o.ExecutionMode = changes.ExecutionMode
+	}
+	if changes.DefaultLeverage > 0 {
+		o.DefaultLeverage = changes.DefaultLeverage
+	}
+	if changes.DefaultBalance > 0 {
+		o.DefaultBalance = changes.DefaultBalance
+	}
+	if changes.MarginMode != "" {
+		o.MarginMode = changes.MarginMode
+	}
+}
+
+// BrokerOverrideStore keeps the runtime broker overrides in a JSON file so they
+// survive a restart
+type BrokerOverrideStore struct {
+	mu        sync.Mutex
+	path      string
+	overrides BrokerOverrides
+}
+
+// LoadBrokerOverrides reads the overrides saved at path. A missing file holds none.
+func LoadBrokerOverrides(path string) (*BrokerOverrideStore, error) {
+	store := &BrokerOverrideStore{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &store.overrides); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Get returns the saved overrides
+func (s *BrokerOverrideStore) Get() BrokerOverrides {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.overrides
+}
+
+// Save merges changes into the saved overrides and writes them to the file.
+// The overrides are left unchanged when the write fails.
+func (s *BrokerOverrideStore) Save(changes BrokerOverrides) (BrokerOverrides, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	merged := s.overrides
+	merged.merge(changes)
+
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return s.overrides, err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return s.overrides, err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return s.overrides, err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return s.overrides, err
+	}
+
+	s.overrides = merged
+	return merged, nil
+}
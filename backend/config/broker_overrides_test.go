@@ -0,0 +1,39 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestBrokerOverridesSurviveRestart tests that a saved config change is
+// reloaded over the defaults by a new store on the same file
+func TestBrokerOverridesSurviveRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broker_config.json")
+
+	store, err := LoadBrokerOverrides(path)
+	if err != nil {
+		t.Fatalf("LoadBrokerOverrides() on a missing file error = %v", err)
+	}
+	if _, err := store.Save(BrokerOverrides{ExecutionMode: "ABOOK", DefaultLeverage: 200}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	// A later change keeps the earlier fields it does not set
+	if _, err := store.Save(BrokerOverrides{BrokerName: "Renamed Broker"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// Simulated restart: defaults from the environment, then the saved file
+	restarted, err := LoadBrokerOverrides(path)
+	if err != nil {
+		t.Fatalf("LoadBrokerOverrides() error = %v", err)
+	}
+	broker := BrokerConfig{Name: "RTX Trading", ExecutionMode: "BBOOK", DefaultLeverage: 100, MarginMode: "HEDGING"}
+	restarted.Get().ApplyTo(&broker)
+
+	if broker.Name != "Renamed Broker" || broker.ExecutionMode != "ABOOK" || broker.DefaultLeverage != 200 {
+		t.Errorf("reloaded config = %+v, want the saved name, mode and leverage", broker)
+	}
+	if broker.MarginMode != "HEDGING" {
+		t.Errorf("MarginMode = %s, want the unsaved default HEDGING", broker.MarginMode)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,8 +14,9 @@ import (
 // Config holds all application configuration
 type Config struct {
 	// Server
-	Port        string
-	Environment string
+	Port            string
+	Environment     string
+	ShutdownTimeout string // In-flight requests are drained this long on shutdown
 
 	// Database
 	Database DatabaseConfig
@@ -48,12 +50,44 @@ type Config struct {
 
 	// Compliance Settings
 	Compliance ComplianceConfig
+
+	// Automatic B-Book Hedging
+	Hedging HedgingConfig
+
+	// Last-quote persistence for restart recovery
+	QuoteSnapshot QuoteSnapshotConfig
+
+	// Routing rule effectiveness history
+	RuleSnapshot RuleSnapshotConfig
+
+	// Per-account trade execution webhooks
+	Webhooks WebhooksConfig
+
+	// HTTP request metrics
+	HTTPMetrics HTTPMetricsConfig
 }
 
 type FIXConfig struct {
 	ProvisioningEnabled   bool
 	ProvisioningStorePath string
 	MasterPassword        string
+	// How long startup waits for each session's logon before subscribing
+	LogonTimeout string
+	// Override symbol specs with the LP's SecurityDefinition responses
+	AutoSymbolSpecs bool
+	// Reconnect storm protection: failures within the window trip a cooldown (0 disables)
+	ReconnectMaxFailures int
+	ReconnectWindow      string
+	ReconnectCooldown    string
+	// Reconnect dropped sessions with exponential backoff (0 attempts = no limit)
+	AutoReconnect        bool
+	MaxReconnectAttempts int
+	// Cached bid/ask older than this is not merged into incremental updates ("0s" always merges)
+	QuoteStaleAfter string
+	// A-Book reconciliation: flag orders unfilled after the timeout, or filled
+	// further from the requested price than the tolerance (fraction of price)
+	ReconcileTimeout        string
+	ReconcilePriceTolerance float64
 }
 
 type ComplianceConfig struct {
@@ -67,6 +101,43 @@ type ComplianceConfig struct {
 	SECRule606Enabled    bool
 }
 
+type HedgingConfig struct {
+	Enabled       bool
+	SessionID     string  // FIX session used for hedge orders
+	UpperBand     float64 // Net lots per symbol that trigger a hedge
+	LowerBand     float64 // Net lots per symbol below which the hedge is unwound
+	HedgeRatio    float64
+	MinTradeSize  float64
+	CheckInterval string
+	// Split C-Book partial hedges: fill the client in full and cover the A-Book
+	// share on SessionID, with legs rounded to SplitLotStep
+	SplitEnabled      bool
+	SplitLotStep      float64
+	SplitMinLegVolume float64
+}
+
+type QuoteSnapshotConfig struct {
+	Enabled  bool
+	Path     string
+	Interval string
+}
+
+type RuleSnapshotConfig struct {
+	Path     string
+	Interval string
+}
+
+type WebhooksConfig struct {
+	MaxAttempts    int
+	InitialBackoff string // Doubled on each retry up to MaxBackoff
+	MaxBackoff     string
+	Timeout        string
+}
+
+type HTTPMetricsConfig struct {
+	SlowRequestThreshold string // Requests at least this slow are logged; "0" disables
+}
+
 type DatabaseConfig struct {
 	Host     string
 	Port     string
@@ -74,6 +145,9 @@ type DatabaseConfig struct {
 	User     string
 	Password string
 	SSLMode  string
+	// Write the B-Book engine's accounts, positions and ledger through to the
+	// database and load them on startup (needs migration 008)
+	PersistEngine bool
 }
 
 type RedisConfig struct {
@@ -91,6 +165,11 @@ type AdminConfig struct {
 	Email       string
 	IPWhitelist []string
 	Password    string // Bcrypt hashed password
+
+	MaxSessions     int  // Concurrent sessions per admin, 0 = unlimited
+	SessionTakeover bool // Invalidate the oldest session instead of rejecting the login
+
+	AuthCacheTTL string // Reuse of successful session validations, "0s" disables
 }
 
 type DefaultAccountConfig struct {
@@ -109,6 +188,58 @@ type BrokerConfig struct {
 	DefaultBalance    float64
 	MarginMode        string
 	MaxTicksPerSymbol int
+	// Reject pending orders priced beyond the symbol's digits instead of rounding
+	StrictPricePrecision bool
+	// Oldest quote a pending order may trigger on, "0s" disables the guard
+	PendingMaxQuoteAge string
+	// Oldest quote a market order fills on unless the symbol sets its own, "0s" disables
+	MaxPriceAge string
+	// Lot order on bulk and partial closes: FIFO or LIFO
+	CloseOrder string
+	// Commission rates are PER_LOT (money per lot per side) or PERCENT of notional
+	CommissionType string
+	// New orders are rejected this long after a stop-out, "0s" disables
+	StopOutCooldown string
+	// Margin level % (equity / used margin) raising a margin call, and the lower
+	// level at which the worst positions are force closed; 0 disables either
+	MarginCallLevel float64
+	StopOutLevel    float64
+	// B-Book net exposure caps in lots, as SYMBOL:LOTS pairs and across all
+	// symbols (0 = none); orders breaching a cap are REJECTed or sent A_BOOK
+	ExposureSymbolLimits   []string
+	ExposureAggregateLimit float64
+	ExposureLimitAction    string
+	// Last look on market orders: a move against the client beyond the tolerance
+	// requotes the order, and after RequoteMax requotes it is filled or rejected
+	RequoteEnabled       bool
+	RequoteTolerancePips float64
+	RequoteLastLook      string
+	RequoteMax           int
+	RequoteLimitAction   string
+	// Broker default slippage on market fills: NONE, FIXED, RANDOM or WORSE_ONLY
+	// within SlippagePips; fills slipping beyond SlippageRequotePips are requoted
+	SlippageModel       string
+	SlippagePips        float64
+	SlippageRequotePips float64
+	// Market orders are rejected once no feed has ticked for this long, "0s" disables
+	FeedOutageThreshold string
+	// Swap-free accounts pay this per lot per night after the grace nights, 0 disables
+	SwapFreeAdminFee    float64
+	SwapFreeGraceNights int
+	// Daily rollover time (HH:MM UTC) and the weekday booking triple swap
+	RolloverTime  string
+	TripleSwapDay string
+	// Quotes whose mid is further than this from the median of the last
+	// PriceBandWindow accepted mids are dropped, 0 disables
+	PriceBandMaxDeviationPct  float64
+	PriceBandWindow           int
+	PriceBandRecalibrateAfter int
+	// WebSocket clients are pinged this often and evicted when no pong arrives
+	// within the timeout, "0s" interval disables
+	WSPingInterval string
+	WSPongTimeout  string
+	// JSON file keeping broker settings changed through /api/config across restarts
+	SettingsPath string
 }
 
 type LPConfig struct {
@@ -119,7 +250,10 @@ type LPConfig struct {
 }
 
 type CORSConfig struct {
-	AllowedOrigins []string
+	AllowedOrigins []string // Exact origins get credentials; "*" allows any origin without them
+	AllowedMethods []string
+	AllowedHeaders []string
+	MaxAge         string // How long browsers may cache a preflight
 }
 
 type EncryptionConfig struct {
@@ -132,8 +266,9 @@ func Load() (*Config, error) {
 	_ = godotenv.Load()
 
 	cfg := &Config{
-		Port:        getEnv("PORT", "7999"),
-		Environment: getEnv("ENVIRONMENT", "development"),
+		Port:            getEnv("PORT", "7999"),
+		Environment:     getEnv("ENVIRONMENT", "development"),
+		ShutdownTimeout: getEnv("SHUTDOWN_TIMEOUT", "15s"),
 
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -142,6 +277,8 @@ func Load() (*Config, error) {
 			User:     getEnv("DB_USER", "postgres"),
 			Password: getEnv("DB_PASSWORD", ""),
 			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
+
+			PersistEngine: getEnvAsBool("ENGINE_PERSISTENCE", false),
 		},
 
 		Redis: RedisConfig{
@@ -159,6 +296,11 @@ func Load() (*Config, error) {
 			Email:       getEnv("ADMIN_EMAIL", "admin@example.com"),
 			IPWhitelist: getEnvAsSlice("ADMIN_IP_WHITELIST", []string{"127.0.0.1", "::1"}, ","),
 			Password:    getEnv("ADMIN_PASSWORD_HASH", ""),
+
+			MaxSessions:     getEnvAsInt("ADMIN_MAX_SESSIONS", 0),
+			SessionTakeover: getEnvAsBool("ADMIN_SESSION_TAKEOVER", false),
+
+			AuthCacheTTL: getEnv("ADMIN_AUTH_CACHE_TTL", "5s"),
 		},
 
 		DefaultAccount: DefaultAccountConfig{
@@ -168,15 +310,45 @@ func Load() (*Config, error) {
 		},
 
 		Broker: BrokerConfig{
-			Name:              getEnv("BROKER_NAME", "RTX Trading"),
-			DisplayName:       getEnv("BROKER_DISPLAY_NAME", "YoForex"),
-			PriceFeedLP:       getEnv("PRICE_FEED_LP", "OANDA"),
-			PriceFeedName:     getEnv("PRICE_FEED_NAME", "YoForex LP"),
-			ExecutionMode:     getEnv("EXECUTION_MODE", "BBOOK"),
-			DefaultLeverage:   getEnvAsInt("DEFAULT_LEVERAGE", 100),
-			DefaultBalance:    getEnvAsFloat("DEFAULT_BALANCE", 5000.0),
-			MarginMode:        getEnv("MARGIN_MODE", "HEDGING"),
-			MaxTicksPerSymbol: getEnvAsInt("MAX_TICKS_PER_SYMBOL", 50000),
+			Name:                 getEnv("BROKER_NAME", "RTX Trading"),
+			DisplayName:          getEnv("BROKER_DISPLAY_NAME", "YoForex"),
+			PriceFeedLP:          getEnv("PRICE_FEED_LP", "OANDA"),
+			PriceFeedName:        getEnv("PRICE_FEED_NAME", "YoForex LP"),
+			ExecutionMode:        getEnv("EXECUTION_MODE", "BBOOK"),
+			DefaultLeverage:      getEnvAsInt("DEFAULT_LEVERAGE", 100),
+			DefaultBalance:       getEnvAsFloat("DEFAULT_BALANCE", 5000.0),
+			MarginMode:           getEnv("MARGIN_MODE", "HEDGING"),
+			MaxTicksPerSymbol:    getEnvAsInt("MAX_TICKS_PER_SYMBOL", 50000),
+			StrictPricePrecision: getEnvAsBool("STRICT_PRICE_PRECISION", false),
+			PendingMaxQuoteAge:   getEnv("PENDING_MAX_QUOTE_AGE", "5s"),
+			MaxPriceAge:          getEnv("MAX_PRICE_AGE", "3s"),
+			CloseOrder:           getEnv("CLOSE_ORDER", "FIFO"),
+			CommissionType:       getEnv("COMMISSION_TYPE", "PER_LOT"),
+			StopOutCooldown:      getEnv("STOPOUT_COOLDOWN", "0s"),
+			MarginCallLevel:      getEnvAsFloat("MARGIN_CALL_LEVEL", 100),
+			StopOutLevel:         getEnvAsFloat("STOPOUT_LEVEL", 50),
+			ExposureSymbolLimits:   getEnvAsSlice("EXPOSURE_SYMBOL_LIMITS", nil, ","),
+			ExposureAggregateLimit: getEnvAsFloat("EXPOSURE_AGGREGATE_LIMIT", 0),
+			ExposureLimitAction:    getEnv("EXPOSURE_LIMIT_ACTION", "REJECT"),
+			RequoteEnabled:       getEnvAsBool("REQUOTE_ENABLED", false),
+			RequoteTolerancePips: getEnvAsFloat("REQUOTE_TOLERANCE_PIPS", 1),
+			RequoteLastLook:      getEnv("REQUOTE_LAST_LOOK", "200ms"),
+			RequoteMax:           getEnvAsInt("REQUOTE_MAX", 3),
+			RequoteLimitAction:   getEnv("REQUOTE_LIMIT_ACTION", "REJECT"),
+			SlippageModel:        getEnv("SLIPPAGE_MODEL", "NONE"),
+			SlippagePips:         getEnvAsFloat("SLIPPAGE_PIPS", 0),
+			SlippageRequotePips:  getEnvAsFloat("SLIPPAGE_REQUOTE_PIPS", 0),
+			FeedOutageThreshold:  getEnv("FEED_OUTAGE_THRESHOLD", "30s"),
+			SwapFreeAdminFee:     getEnvAsFloat("SWAP_FREE_ADMIN_FEE", 0),
+			SwapFreeGraceNights:  getEnvAsInt("SWAP_FREE_GRACE_NIGHTS", 0),
+			RolloverTime:         getEnv("ROLLOVER_TIME", "22:00"),
+			TripleSwapDay:        getEnv("TRIPLE_SWAP_DAY", "WEDNESDAY"),
+			PriceBandMaxDeviationPct:  getEnvAsFloat("PRICE_BAND_MAX_DEVIATION_PCT", 2),
+			PriceBandWindow:           getEnvAsInt("PRICE_BAND_WINDOW", 50),
+			PriceBandRecalibrateAfter: getEnvAsInt("PRICE_BAND_RECALIBRATE_AFTER", 20),
+			WSPingInterval:            getEnv("WS_PING_INTERVAL", "20s"),
+			WSPongTimeout:             getEnv("WS_PONG_TIMEOUT", "10s"),
+			SettingsPath:              getEnv("BROKER_SETTINGS_PATH", "./data/broker_config.json"),
 		},
 
 		LP: LPConfig{
@@ -188,6 +360,9 @@ func Load() (*Config, error) {
 
 		CORS: CORSConfig{
 			AllowedOrigins: getEnvAsSlice("ALLOWED_ORIGINS", []string{"http://localhost:3000"}, ","),
+			AllowedMethods: getEnvAsSlice("CORS_ALLOWED_METHODS", nil, ","),
+			AllowedHeaders: getEnvAsSlice("CORS_ALLOWED_HEADERS", nil, ","),
+			MaxAge:         getEnv("CORS_MAX_AGE", "10m"),
 		},
 
 		Encryption: EncryptionConfig{
@@ -195,9 +370,19 @@ func Load() (*Config, error) {
 		},
 
 		FIX: FIXConfig{
-			ProvisioningEnabled:   getEnvAsBool("FIX_PROVISIONING_ENABLED", false),
-			ProvisioningStorePath: getEnv("FIX_PROVISIONING_STORE_PATH", "./data/fix_credentials"),
-			MasterPassword:        getEnv("FIX_MASTER_PASSWORD", ""),
+			ProvisioningEnabled:     getEnvAsBool("FIX_PROVISIONING_ENABLED", false),
+			ProvisioningStorePath:   getEnv("FIX_PROVISIONING_STORE_PATH", "./data/fix_credentials"),
+			MasterPassword:          getEnv("FIX_MASTER_PASSWORD", ""),
+			LogonTimeout:            getEnv("FIX_LOGON_TIMEOUT", "30s"),
+			AutoSymbolSpecs:         getEnvAsBool("FIX_AUTO_SYMBOL_SPECS", false),
+			ReconnectMaxFailures:    getEnvAsInt("FIX_RECONNECT_MAX_FAILURES", 5),
+			ReconnectWindow:         getEnv("FIX_RECONNECT_WINDOW", "2m"),
+			ReconnectCooldown:       getEnv("FIX_RECONNECT_COOLDOWN", "10m"),
+			AutoReconnect:           getEnvAsBool("FIX_AUTO_RECONNECT", true),
+			MaxReconnectAttempts:    getEnvAsInt("FIX_MAX_RECONNECT_ATTEMPTS", 0),
+			QuoteStaleAfter:         getEnv("FIX_QUOTE_STALE_AFTER", "5s"),
+			ReconcileTimeout:        getEnv("FIX_RECONCILE_TIMEOUT", "30s"),
+			ReconcilePriceTolerance: getEnvAsFloat("FIX_RECONCILE_PRICE_TOLERANCE", 0.001),
 		},
 
 		Compliance: ComplianceConfig{
@@ -210,6 +395,41 @@ func Load() (*Config, error) {
 			MiFIDIIEnabled:      getEnvAsBool("COMPLIANCE_MIFID_II", true),
 			SECRule606Enabled:   getEnvAsBool("COMPLIANCE_SEC_RULE_606", true),
 		},
+
+		Hedging: HedgingConfig{
+			Enabled:       getEnvAsBool("AUTO_HEDGE_ENABLED", false),
+			SessionID:     getEnv("AUTO_HEDGE_SESSION", "YOFX1"),
+			UpperBand:     getEnvAsFloat("AUTO_HEDGE_UPPER_BAND", 10.0),
+			LowerBand:     getEnvAsFloat("AUTO_HEDGE_LOWER_BAND", 2.0),
+			HedgeRatio:    getEnvAsFloat("AUTO_HEDGE_RATIO", 1.0),
+			MinTradeSize:  getEnvAsFloat("AUTO_HEDGE_MIN_TRADE", 0.01),
+			CheckInterval: getEnv("AUTO_HEDGE_INTERVAL", "5s"),
+			SplitEnabled:      getEnvAsBool("CBOOK_SPLIT_ENABLED", false),
+			SplitLotStep:      getEnvAsFloat("CBOOK_SPLIT_LOT_STEP", 0.01),
+			SplitMinLegVolume: getEnvAsFloat("CBOOK_SPLIT_MIN_LEG", 0.01),
+		},
+
+		QuoteSnapshot: QuoteSnapshotConfig{
+			Enabled:  getEnvAsBool("QUOTE_SNAPSHOT_ENABLED", true),
+			Path:     getEnv("QUOTE_SNAPSHOT_PATH", "./data/quote_snapshot.json"),
+			Interval: getEnv("QUOTE_SNAPSHOT_INTERVAL", "10s"),
+		},
+
+		RuleSnapshot: RuleSnapshotConfig{
+			Path:     getEnv("RULE_SNAPSHOT_PATH", "./data/rule_snapshots.jsonl"),
+			Interval: getEnv("RULE_SNAPSHOT_INTERVAL", "1m"),
+		},
+
+		Webhooks: WebhooksConfig{
+			MaxAttempts:    getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 5),
+			InitialBackoff: getEnv("WEBHOOK_INITIAL_BACKOFF", "1s"),
+			MaxBackoff:     getEnv("WEBHOOK_MAX_BACKOFF", "1m"),
+			Timeout:        getEnv("WEBHOOK_TIMEOUT", "10s"),
+		},
+
+		HTTPMetrics: HTTPMetricsConfig{
+			SlowRequestThreshold: getEnv("HTTP_SLOW_REQUEST_THRESHOLD", "500ms"),
+		},
 	}
 
 	// Validate required fields
@@ -234,6 +454,55 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if c.Hedging.Enabled && c.Hedging.LowerBand >= c.Hedging.UpperBand {
+		return fmt.Errorf("AUTO_HEDGE_LOWER_BAND must be less than AUTO_HEDGE_UPPER_BAND")
+	}
+
+	return c.validateDurations()
+}
+
+// validateDurations rejects duration settings time.ParseDuration cannot parse,
+// so a typo fails startup instead of silently running with another value
+func (c *Config) validateDurations() error {
+	durations := []struct{ key, value string }{
+		{"SHUTDOWN_TIMEOUT", c.ShutdownTimeout},
+		{"JWT_EXPIRY", c.JWT.Expiry},
+		{"ADMIN_AUTH_CACHE_TTL", c.Admin.AuthCacheTTL},
+		{"PENDING_MAX_QUOTE_AGE", c.Broker.PendingMaxQuoteAge},
+		{"MAX_PRICE_AGE", c.Broker.MaxPriceAge},
+		{"STOPOUT_COOLDOWN", c.Broker.StopOutCooldown},
+		{"REQUOTE_LAST_LOOK", c.Broker.RequoteLastLook},
+		{"FEED_OUTAGE_THRESHOLD", c.Broker.FeedOutageThreshold},
+		{"WS_PING_INTERVAL", c.Broker.WSPingInterval},
+		{"WS_PONG_TIMEOUT", c.Broker.WSPongTimeout},
+		{"CORS_MAX_AGE", c.CORS.MaxAge},
+		{"FIX_LOGON_TIMEOUT", c.FIX.LogonTimeout},
+		{"FIX_RECONNECT_WINDOW", c.FIX.ReconnectWindow},
+		{"FIX_RECONNECT_COOLDOWN", c.FIX.ReconnectCooldown},
+		{"FIX_QUOTE_STALE_AFTER", c.FIX.QuoteStaleAfter},
+		{"FIX_RECONCILE_TIMEOUT", c.FIX.ReconcileTimeout},
+		{"AUTO_HEDGE_INTERVAL", c.Hedging.CheckInterval},
+		{"QUOTE_SNAPSHOT_INTERVAL", c.QuoteSnapshot.Interval},
+		{"RULE_SNAPSHOT_INTERVAL", c.RuleSnapshot.Interval},
+		{"WEBHOOK_INITIAL_BACKOFF", c.Webhooks.InitialBackoff},
+		{"WEBHOOK_MAX_BACKOFF", c.Webhooks.MaxBackoff},
+		{"WEBHOOK_TIMEOUT", c.Webhooks.Timeout},
+		{"HTTP_SLOW_REQUEST_THRESHOLD", c.HTTPMetrics.SlowRequestThreshold},
+	}
+	// time.NewTicker panics on a non-positive interval
+	tickers := map[string]bool{
+		"QUOTE_SNAPSHOT_INTERVAL": true,
+		"RULE_SNAPSHOT_INTERVAL":  true,
+	}
+	for _, d := range durations {
+		value, err := time.ParseDuration(d.value)
+		if err != nil {
+			return fmt.Errorf("%s: invalid duration %q", d.key, d.value)
+		}
+		if tickers[d.key] && value <= 0 {
+			return fmt.Errorf("%s: interval must be positive, got %q", d.key, d.value)
+		}
+	}
 	return nil
 }
 
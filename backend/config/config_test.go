@@ -0,0 +1,39 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestLoadRejectsInvalidDurations tests that a malformed duration setting
+// fails startup, naming the variable, instead of falling back to another value
+func TestLoadRejectsInvalidDurations(t *testing.T) {
+	if _, err := Load(); err != nil {
+		t.Fatalf("Load() with defaults error = %v", err)
+	}
+
+	for _, key := range []string{"REQUOTE_LAST_LOOK", "RULE_SNAPSHOT_INTERVAL", "FIX_RECONCILE_TIMEOUT",
+		"HTTP_SLOW_REQUEST_THRESHOLD", "SHUTDOWN_TIMEOUT", "CORS_MAX_AGE"} {
+		t.Setenv(key, "10 minutes")
+		_, err := Load()
+		if err == nil || !strings.Contains(err.Error(), key) {
+			t.Errorf("Load() with %s=%q error = %v, want it rejected", key, "10 minutes", err)
+		}
+		t.Setenv(key, "")
+	}
+}
+
+// TestLoadRejectsNonPositiveIntervals tests that ticker intervals must be
+// positive, since time.NewTicker panics on zero or negative durations
+func TestLoadRejectsNonPositiveIntervals(t *testing.T) {
+	for _, key := range []string{"QUOTE_SNAPSHOT_INTERVAL", "RULE_SNAPSHOT_INTERVAL"} {
+		for _, value := range []string{"0s", "-1m"} {
+			t.Setenv(key, value)
+			_, err := Load()
+			if err == nil || !strings.Contains(err.Error(), key) {
+				t.Errorf("Load() with %s=%q error = %v, want it rejected", key, value, err)
+			}
+		}
+		t.Setenv(key, "")
+	}
+}
@@ -94,7 +94,11 @@ func LoadRateLimitingConfig() (RateLimitingConfig, error) {
 		return RateLimitingConfig{}, fmt.Errorf("failed to parse server.yaml: %w", err)
 	}
 
-	return fullConfig.RateLimiting, nil
+	rl := fullConfig.RateLimiting
+	if err := validateDurations("rate_limiting", rl.CleanupInterval, rl.ClientTimeout); err != nil {
+		return RateLimitingConfig{}, err
+	}
+	return rl, nil
 }
 
 // LoadKeyBasedRateLimitingConfig loads key-based rate limiting configuration
@@ -123,15 +127,30 @@ func LoadKeyBasedRateLimitingConfig() (KeyBasedRateLimitingConfig, error) {
 		return KeyBasedRateLimitingConfig{}, fmt.Errorf("failed to parse server.yaml: %w", err)
 	}
 
-	return fullConfig.KeyBasedRateLimiting, nil
+	kb := fullConfig.KeyBasedRateLimiting
+	if err := validateDurations("key_based_rate_limiting", kb.CleanupInterval, kb.ClientTimeout); err != nil {
+		return KeyBasedRateLimitingConfig{}, err
+	}
+	return kb, nil
+}
+
+// validateDurations checks a section's cleanup_interval and client_timeout
+func validateDurations(section, cleanupInterval, clientTimeout string) error {
+	if _, err := time.ParseDuration(cleanupInterval); err != nil {
+		return fmt.Errorf("server.yaml %s.cleanup_interval: invalid duration %q", section, cleanupInterval)
+	}
+	if _, err := time.ParseDuration(clientTimeout); err != nil {
+		return fmt.Errorf("server.yaml %s.client_timeout: invalid duration %q", section, clientTimeout)
+	}
+	return nil
 }
 
-// ParseDuration parses a duration string
+// ParseDuration parses a duration the loaders have already validated, so it
+// never falls back to a value the operator did not configure
 func ParseDuration(s string) time.Duration {
 	d, err := time.ParseDuration(s)
 	if err != nil {
-		// Default to 5 minutes if parsing fails
-		return 5 * time.Minute
+		panic(fmt.Sprintf("config: unvalidated duration %q: %v", s, err))
 	}
 	return d
 }
@@ -47,7 +47,6 @@ func (h *APIHandler) RegisterRoutes(mux *http.ServeMux) {
 
 // HandleGetLatestQuote returns the latest quote for a symbol
 func (h *APIHandler) HandleGetLatestQuote(w http.ResponseWriter, r *http.Request) {
-	h.setCORSHeaders(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -73,7 +72,6 @@ func (h *APIHandler) HandleGetLatestQuote(w http.ResponseWriter, r *http.Request
 
 // HandleGetQuoteHistory returns recent quotes for a symbol
 func (h *APIHandler) HandleGetQuoteHistory(w http.ResponseWriter, r *http.Request) {
-	h.setCORSHeaders(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -106,8 +104,6 @@ func (h *APIHandler) HandleGetQuoteHistory(w http.ResponseWriter, r *http.Reques
 
 // HandleQuoteStream provides Server-Sent Events stream
 func (h *APIHandler) HandleQuoteStream(w http.ResponseWriter, r *http.Request) {
-	h.setCORSHeaders(w)
-
 	// Set headers for SSE
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -157,7 +153,6 @@ func (h *APIHandler) HandleQuoteStream(w http.ResponseWriter, r *http.Request) {
 
 // HandleGetOHLC returns OHLC bars for a symbol
 func (h *APIHandler) HandleGetOHLC(w http.ResponseWriter, r *http.Request) {
-	h.setCORSHeaders(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -198,7 +193,6 @@ func (h *APIHandler) HandleGetOHLC(w http.ResponseWriter, r *http.Request) {
 
 // HandleGetLatestOHLC returns the current active OHLC bar
 func (h *APIHandler) HandleGetLatestOHLC(w http.ResponseWriter, r *http.Request) {
-	h.setCORSHeaders(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -230,7 +224,6 @@ func (h *APIHandler) HandleGetLatestOHLC(w http.ResponseWriter, r *http.Request)
 
 // HandleGetTicks provides backward compatibility
 func (h *APIHandler) HandleGetTicks(w http.ResponseWriter, r *http.Request) {
-	h.setCORSHeaders(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -261,8 +254,6 @@ func (h *APIHandler) HandleGetTicks(w http.ResponseWriter, r *http.Request) {
 
 // HandleGetStats returns pipeline statistics
 func (h *APIHandler) HandleGetStats(w http.ResponseWriter, r *http.Request) {
-	h.setCORSHeaders(w)
-
 	stats := h.pipeline.GetStats()
 
 	w.Header().Set("Content-Type", "application/json")
@@ -271,8 +262,6 @@ func (h *APIHandler) HandleGetStats(w http.ResponseWriter, r *http.Request) {
 
 // HandleGetHealth returns pipeline health status
 func (h *APIHandler) HandleGetHealth(w http.ResponseWriter, r *http.Request) {
-	h.setCORSHeaders(w)
-
 	health, err := h.pipeline.HealthCheck()
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Health check failed: %v", err), http.StatusInternalServerError)
@@ -285,8 +274,6 @@ func (h *APIHandler) HandleGetHealth(w http.ResponseWriter, r *http.Request) {
 
 // HandleGetFeedHealth returns feed health status
 func (h *APIHandler) HandleGetFeedHealth(w http.ResponseWriter, r *http.Request) {
-	h.setCORSHeaders(w)
-
 	feedHealth := h.pipeline.monitor.GetFeedHealth()
 
 	w.Header().Set("Content-Type", "application/json")
@@ -295,8 +282,6 @@ func (h *APIHandler) HandleGetFeedHealth(w http.ResponseWriter, r *http.Request)
 
 // HandleGetAlerts returns recent alerts
 func (h *APIHandler) HandleGetAlerts(w http.ResponseWriter, r *http.Request) {
-	h.setCORSHeaders(w)
-
 	limit := 50
 	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
 		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
@@ -312,8 +297,6 @@ func (h *APIHandler) HandleGetAlerts(w http.ResponseWriter, r *http.Request) {
 
 // HandleCleanupStorage triggers storage cleanup
 func (h *APIHandler) HandleCleanupStorage(w http.ResponseWriter, r *http.Request) {
-	h.setCORSHeaders(w)
-
 	if r.Method != "POST" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -350,10 +333,3 @@ func (h *APIHandler) parseTimeframe(s string) Timeframe {
 		return TF_M1
 	}
 }
-
-// setCORSHeaders sets CORS headers
-func (h *APIHandler) setCORSHeaders(w http.ResponseWriter) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-}
@@ -0,0 +1,130 @@
+package migrations
+
+import (
+	"database/sql"
+)
+
+func init() {
+	RegisterMigration(&Migration{
+		Version: 8,
+		Name:    "engine_state",
+		Up:      engineStateUp,
+		Down:    engineStateDown,
+	})
+}
+
+func engineStateUp(tx *sql.Tx) error {
+	schema := `
+	-- ========================================
+	-- B-BOOK ENGINE STATE
+	-- Migration 008: accounts, positions, ledger and bonuses
+	-- written through by the in-memory engine
+	-- ========================================
+
+	-- Engine accounts, keyed by the engine's account ID
+	CREATE TABLE IF NOT EXISTS engine_accounts (
+		id BIGINT PRIMARY KEY,
+		account_number VARCHAR(50) UNIQUE NOT NULL,
+		user_id VARCHAR(255) NOT NULL,
+		username VARCHAR(255) NOT NULL,
+		password VARCHAR(255) NOT NULL DEFAULT '',
+		balance DOUBLE PRECISION NOT NULL DEFAULT 0,
+		credit DOUBLE PRECISION NOT NULL DEFAULT 0,
+		leverage DOUBLE PRECISION NOT NULL DEFAULT 100,
+		margin_mode VARCHAR(20) NOT NULL DEFAULT 'HEDGING',
+		currency VARCHAR(10) NOT NULL DEFAULT 'USD',
+		status VARCHAR(50) NOT NULL DEFAULT 'ACTIVE',
+		is_demo BOOLEAN NOT NULL DEFAULT FALSE,
+		swap_free BOOLEAN NOT NULL DEFAULT FALSE,
+		opposite_signal VARCHAR(20) NOT NULL DEFAULT '',
+		created_at BIGINT NOT NULL DEFAULT 0,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_engine_accounts_user_id ON engine_accounts(user_id);
+
+	-- Engine positions, open and closed
+	CREATE TABLE IF NOT EXISTS engine_positions (
+		id BIGINT PRIMARY KEY,
+		account_id BIGINT NOT NULL REFERENCES engine_accounts(id) ON DELETE CASCADE,
+		symbol VARCHAR(50) NOT NULL,
+		side VARCHAR(10) NOT NULL CHECK (side IN ('BUY', 'SELL')),
+		volume DOUBLE PRECISION NOT NULL,
+		open_price DOUBLE PRECISION NOT NULL,
+		current_price DOUBLE PRECISION NOT NULL DEFAULT 0,
+		open_time TIMESTAMPTZ NOT NULL,
+		sl DOUBLE PRECISION NOT NULL DEFAULT 0,
+		tp DOUBLE PRECISION NOT NULL DEFAULT 0,
+		swap DOUBLE PRECISION NOT NULL DEFAULT 0,
+		admin_fee DOUBLE PRECISION NOT NULL DEFAULT 0,
+		nights INT NOT NULL DEFAULT 0,
+		commission DOUBLE PRECISION NOT NULL DEFAULT 0,
+		open_commission DOUBLE PRECISION NOT NULL DEFAULT 0,
+		unrealized_pnl DOUBLE PRECISION NOT NULL DEFAULT 0,
+		status VARCHAR(20) NOT NULL,
+		close_price DOUBLE PRECISION NOT NULL DEFAULT 0,
+		close_time TIMESTAMPTZ,
+		close_reason VARCHAR(50) NOT NULL DEFAULT '',
+		tp_ladder JSONB,
+		book VARCHAR(20) NOT NULL DEFAULT '',
+		last_rollover TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_engine_positions_account_id ON engine_positions(account_id);
+	CREATE INDEX IF NOT EXISTS idx_engine_positions_status ON engine_positions(status);
+
+	-- Engine ledger, append only
+	CREATE TABLE IF NOT EXISTS engine_ledger_entries (
+		id BIGINT PRIMARY KEY,
+		account_id BIGINT NOT NULL REFERENCES engine_accounts(id) ON DELETE CASCADE,
+		type VARCHAR(50) NOT NULL,
+		amount DOUBLE PRECISION NOT NULL,
+		balance_after DOUBLE PRECISION NOT NULL,
+		currency VARCHAR(10) NOT NULL DEFAULT 'USD',
+		description TEXT NOT NULL DEFAULT '',
+		ref_type VARCHAR(50) NOT NULL DEFAULT '',
+		ref_id BIGINT NOT NULL DEFAULT 0,
+		admin_id VARCHAR(255) NOT NULL DEFAULT '',
+		payment_method VARCHAR(50) NOT NULL DEFAULT '',
+		payment_ref VARCHAR(255) NOT NULL DEFAULT '',
+		status VARCHAR(50) NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_engine_ledger_entries_account_id ON engine_ledger_entries(account_id);
+	CREATE INDEX IF NOT EXISTS idx_engine_ledger_entries_created_at ON engine_ledger_entries(created_at);
+
+	-- Engine credit bonuses, pending and released
+	CREATE TABLE IF NOT EXISTS engine_bonuses (
+		id BIGINT PRIMARY KEY,
+		account_id BIGINT NOT NULL REFERENCES engine_accounts(id) ON DELETE CASCADE,
+		amount DOUBLE PRECISION NOT NULL,
+		required_volume DOUBLE PRECISION NOT NULL DEFAULT 0,
+		traded_volume DOUBLE PRECISION NOT NULL DEFAULT 0,
+		status VARCHAR(20) NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		admin_id VARCHAR(255) NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL,
+		released_at TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_engine_bonuses_account_id ON engine_bonuses(account_id);
+	`
+
+	_, err := tx.Exec(schema)
+	return err
+}
+
+func engineStateDown(tx *sql.Tx) error {
+	dropSchema := `
+	DROP TABLE IF EXISTS engine_bonuses;
+	DROP TABLE IF EXISTS engine_ledger_entries;
+	DROP TABLE IF EXISTS engine_positions;
+	DROP TABLE IF EXISTS engine_accounts;
+	`
+
+	_, err := tx.Exec(dropSchema)
+	return err
+}
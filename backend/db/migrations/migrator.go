@@ -44,7 +44,8 @@ func (m *Migrator) Init() error {
 	CREATE TABLE IF NOT EXISTS schema_migrations (
 		version BIGINT PRIMARY KEY,
 		name VARCHAR(255) NOT NULL,
-		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		dirty BOOLEAN NOT NULL DEFAULT FALSE
 	);
 	`
 	_, err := m.db.Exec(createTableSQL)
@@ -52,6 +53,13 @@ func (m *Migrator) Init() error {
 		return fmt.Errorf("failed to create migrations table: %w", err)
 	}
 
+	// Tracking tables created before dirty-state detection lack the column
+	if _, err := m.db.Exec("SELECT dirty FROM schema_migrations LIMIT 1"); err != nil {
+		if _, err := m.db.Exec("ALTER TABLE schema_migrations ADD COLUMN dirty BOOLEAN NOT NULL DEFAULT FALSE"); err != nil {
+			return fmt.Errorf("failed to add dirty column to migrations table: %w", err)
+		}
+	}
+
 	log.Println("[Migrator] Migrations tracking table initialized")
 	return nil
 }
@@ -76,8 +84,61 @@ func (m *Migrator) GetAppliedMigrations() (map[int64]bool, error) {
 	return applied, nil
 }
 
+// DirtyVersion returns the version whose migration failed midway, leaving the
+// schema in an unknown state, or false when every recorded version is clean
+func (m *Migrator) DirtyVersion() (int64, bool, error) {
+	var version int64
+	err := m.db.QueryRow("SELECT version FROM schema_migrations WHERE dirty ORDER BY version DESC LIMIT 1").Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to query dirty migrations: %w", err)
+	}
+	return version, true, nil
+}
+
+// checkClean refuses to migrate while a version is dirty
+func (m *Migrator) checkClean() error {
+	version, dirty, err := m.DirtyVersion()
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("database is dirty at version %d: repair the schema, then run -force with the version it is at", version)
+	}
+	return nil
+}
+
+// Force records the schema as cleanly migrated to version, without running any
+// migration: versions above it are forgotten and the rest marked clean
+func (m *Migrator) Force(version int64) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM schema_migrations WHERE version > $1", version); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to forget migrations above %d: %w", version, err)
+	}
+	if _, err := tx.Exec("UPDATE schema_migrations SET dirty = FALSE WHERE dirty"); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear dirty state: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit force: %w", err)
+	}
+
+	log.Printf("[Migrator] Forced clean state at version %d", version)
+	return nil
+}
+
 // Up runs all pending migrations
 func (m *Migrator) Up() error {
+	if err := m.checkClean(); err != nil {
+		return err
+	}
+
 	// Sort migrations by version
 	sort.Slice(m.migrations, func(i, j int) bool {
 		return m.migrations[i].Version < m.migrations[j].Version
@@ -96,42 +157,61 @@ func (m *Migrator) Up() error {
 			continue
 		}
 
-		log.Printf("[Migrator] Applying migration %d (%s)...", migration.Version, migration.Name)
-
-		// Begin transaction
-		tx, err := m.db.Begin()
-		if err != nil {
-			return fmt.Errorf("failed to begin transaction: %w", err)
+		if err := m.apply(migration); err != nil {
+			return err
 		}
+	}
 
-		// Run migration
-		if err := migration.Up(tx); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("migration %d failed: %w", migration.Version, err)
-		}
+	log.Println("[Migrator] All migrations applied")
+	return nil
+}
 
-		// Record migration
-		_, err = tx.Exec("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)",
-			migration.Version, migration.Name)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("failed to record migration: %w", err)
-		}
+// apply runs a migration in a transaction. The version is recorded dirty
+// first and marked clean with the migration, so a failure leaves it dirty.
+func (m *Migrator) apply(migration *Migration) error {
+	log.Printf("[Migrator] Applying migration %d (%s)...", migration.Version, migration.Name)
 
-		// Commit
-		if err := tx.Commit(); err != nil {
-			return fmt.Errorf("failed to commit migration: %w", err)
-		}
+	// Record migration as dirty until it succeeds
+	_, err := m.db.Exec("INSERT INTO schema_migrations (version, name, dirty) VALUES ($1, $2, TRUE)",
+		migration.Version, migration.Name)
+	if err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
 
-		log.Printf("[Migrator] Migration %d (%s) applied successfully", migration.Version, migration.Name)
+	// Begin transaction
+	tx, err := m.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
-	log.Println("[Migrator] All migrations applied")
+	// Run migration
+	if err := migration.Up(tx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("migration %d failed, version left dirty: %w", migration.Version, err)
+	}
+
+	// Mark migration clean
+	_, err = tx.Exec("UPDATE schema_migrations SET dirty = FALSE WHERE version = $1", migration.Version)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+
+	// Commit
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration: %w", err)
+	}
+
+	log.Printf("[Migrator] Migration %d (%s) applied successfully", migration.Version, migration.Name)
 	return nil
 }
 
 // Down rolls back the last migration
 func (m *Migrator) Down() error {
+	if err := m.checkClean(); err != nil {
+		return err
+	}
+
 	// Get applied migrations
 	applied, err := m.GetAppliedMigrations()
 	if err != nil {
@@ -170,6 +250,12 @@ func (m *Migrator) Down() error {
 
 	log.Printf("[Migrator] Rolling back migration %d (%s)...", targetMigration.Version, targetMigration.Name)
 
+	// Mark migration dirty until the rollback succeeds
+	_, err = m.db.Exec("UPDATE schema_migrations SET dirty = TRUE WHERE version = $1", targetMigration.Version)
+	if err != nil {
+		return fmt.Errorf("failed to mark migration dirty: %w", err)
+	}
+
 	// Begin transaction
 	tx, err := m.db.Begin()
 	if err != nil {
@@ -179,7 +265,7 @@ func (m *Migrator) Down() error {
 	// Run down migration
 	if err := targetMigration.Down(tx); err != nil {
 		tx.Rollback()
-		return fmt.Errorf("rollback failed: %w", err)
+		return fmt.Errorf("rollback of migration %d failed, version left dirty: %w", targetMigration.Version, err)
 	}
 
 	// Remove migration record
@@ -210,12 +296,19 @@ func (m *Migrator) Status() error {
 		return m.migrations[i].Version < m.migrations[j].Version
 	})
 
+	dirtyVersion, dirty, err := m.DirtyVersion()
+	if err != nil {
+		return err
+	}
+
 	log.Println("[Migrator] Migration Status:")
 	log.Println("=====================================")
 
 	for _, migration := range m.migrations {
 		status := "PENDING"
-		if applied[migration.Version] {
+		if dirty && migration.Version == dirtyVersion {
+			status = "DIRTY"
+		} else if applied[migration.Version] {
 			status = "APPLIED"
 		}
 		log.Printf("  %d - %s [%s]", migration.Version, migration.Name, status)
@@ -224,12 +317,19 @@ func (m *Migrator) Status() error {
 	log.Println("=====================================")
 	log.Printf("Total: %d migrations, %d applied, %d pending",
 		len(m.migrations), len(applied), len(m.migrations)-len(applied))
+	if dirty {
+		log.Printf("WARNING: version %d is dirty; migrations are blocked until the schema is repaired and -force is run", dirtyVersion)
+	}
 
 	return nil
 }
 
 // UpTo runs migrations up to a specific version
 func (m *Migrator) UpTo(targetVersion int64) error {
+	if err := m.checkClean(); err != nil {
+		return err
+	}
+
 	// Sort migrations by version
 	sort.Slice(m.migrations, func(i, j int) bool {
 		return m.migrations[i].Version < m.migrations[j].Version
@@ -251,31 +351,72 @@ func (m *Migrator) UpTo(targetVersion int64) error {
 			continue
 		}
 
-		log.Printf("[Migrator] Applying migration %d (%s)...", migration.Version, migration.Name)
-
-		tx, err := m.db.Begin()
-		if err != nil {
-			return fmt.Errorf("failed to begin transaction: %w", err)
+		if err := m.apply(migration); err != nil {
+			return err
 		}
+	}
 
-		if err := migration.Up(tx); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("migration %d failed: %w", migration.Version, err)
-		}
+	return nil
+}
 
-		_, err = tx.Exec("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)",
-			migration.Version, migration.Name)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("failed to record migration: %w", err)
+// Redo rolls back the last applied migration and applies it again
+func (m *Migrator) Redo() error {
+	applied, err := m.GetAppliedMigrations()
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		log.Println("[Migrator] No migrations to redo")
+		return nil
+	}
+
+	var latestVersion int64
+	for version := range applied {
+		if version > latestVersion {
+			latestVersion = version
 		}
+	}
 
-		if err := tx.Commit(); err != nil {
-			return fmt.Errorf("failed to commit migration: %w", err)
+	var target *Migration
+	for _, migration := range m.migrations {
+		if migration.Version == latestVersion {
+			target = migration
+			break
 		}
+	}
+	if target == nil {
+		return fmt.Errorf("migration %d not found in registered migrations", latestVersion)
+	}
 
-		log.Printf("[Migrator] Migration %d (%s) applied successfully", migration.Version, migration.Name)
+	if err := m.Down(); err != nil {
+		return err
+	}
+	if err := m.apply(target); err != nil {
+		return err
 	}
 
+	log.Printf("[Migrator] Migration %d redone", latestVersion)
 	return nil
 }
+
+// Reset rolls back every applied migration, newest first. Returns the number
+// rolled back.
+func (m *Migrator) Reset() (int, error) {
+	rolledBack := 0
+	for {
+		applied, err := m.GetAppliedMigrations()
+		if err != nil {
+			return rolledBack, err
+		}
+		if len(applied) == 0 {
+			break
+		}
+		if err := m.Down(); err != nil {
+			return rolledBack, err
+		}
+		rolledBack++
+	}
+
+	log.Printf("[Migrator] Reset complete: %d migrations rolled back", rolledBack)
+	return rolledBack, nil
+}
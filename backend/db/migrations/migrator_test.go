@@ -0,0 +1,159 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// newTestMigrator returns a migrator on an in-memory database with three
+// migrations, each creating one table
+func newTestMigrator(t *testing.T) (*Migrator, *sql.DB) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	db.SetMaxOpenConns(1) // Every connection would get its own in-memory database
+	t.Cleanup(func() { db.Close() })
+
+	m := NewMigrator(db)
+	for version := int64(1); version <= 3; version++ {
+		table := fmt.Sprintf("table_%d", version)
+		m.Register(&Migration{
+			Version: version,
+			Name:    table,
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec("CREATE TABLE " + table + " (id INTEGER)")
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec("DROP TABLE " + table)
+				return err
+			},
+		})
+	}
+	if err := m.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	return m, db
+}
+
+func appliedVersions(t *testing.T, m *Migrator) map[int64]bool {
+	t.Helper()
+	applied, err := m.GetAppliedMigrations()
+	if err != nil {
+		t.Fatalf("GetAppliedMigrations() error = %v", err)
+	}
+	return applied
+}
+
+// TestRedoReappliesLastMigration tests that redo leaves the same versions applied
+// and runs the last migration's down and up again
+func TestRedoReappliesLastMigration(t *testing.T) {
+	m, db := newTestMigrator(t)
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO table_3 (id) VALUES (1)"); err != nil {
+		t.Fatalf("insert error = %v", err)
+	}
+
+	if err := m.Redo(); err != nil {
+		t.Fatalf("Redo() error = %v", err)
+	}
+	if applied := appliedVersions(t, m); len(applied) != 3 || !applied[1] || !applied[2] || !applied[3] {
+		t.Errorf("applied after redo = %v, want 1, 2 and 3", applied)
+	}
+	var rows int
+	if err := db.QueryRow("SELECT COUNT(*) FROM table_3").Scan(&rows); err != nil || rows != 0 {
+		t.Errorf("table_3 after redo has %d rows (err %v), want a recreated empty table", rows, err)
+	}
+}
+
+// TestResetRollsBackEverything tests that reset leaves no version applied and
+// that redo and reset on an empty database do nothing
+func TestResetRollsBackEverything(t *testing.T) {
+	m, db := newTestMigrator(t)
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	rolledBack, err := m.Reset()
+	if err != nil || rolledBack != 3 {
+		t.Fatalf("Reset() = %d, %v; want 3 rolled back", rolledBack, err)
+	}
+	if applied := appliedVersions(t, m); len(applied) != 0 {
+		t.Errorf("applied after reset = %v, want none", applied)
+	}
+	if _, err := db.Exec("SELECT * FROM table_1"); err == nil {
+		t.Error("table_1 still exists after reset")
+	}
+
+	if err := m.Redo(); err != nil {
+		t.Errorf("Redo() on an empty database error = %v", err)
+	}
+	if rolledBack, err := m.Reset(); err != nil || rolledBack != 0 {
+		t.Errorf("Reset() on an empty database = %d, %v; want 0", rolledBack, err)
+	}
+}
+
+// TestFailedMigrationLeavesDirtyState tests that a failing migration leaves its
+// version dirty, which blocks migrating until forced clean
+func TestFailedMigrationLeavesDirtyState(t *testing.T) {
+	m, _ := newTestMigrator(t)
+	failing := true
+	m.Register(&Migration{
+		Version: 4,
+		Name:    "failing",
+		Up: func(tx *sql.Tx) error {
+			if failing {
+				return fmt.Errorf("simulated failure")
+			}
+			_, err := tx.Exec("CREATE TABLE table_4 (id INTEGER)")
+			return err
+		},
+		Down: func(tx *sql.Tx) error {
+			_, err := tx.Exec("DROP TABLE table_4")
+			return err
+		},
+	})
+
+	if err := m.Up(); err == nil {
+		t.Fatal("Up() with a failing migration succeeded")
+	}
+	if version, dirty, err := m.DirtyVersion(); err != nil || !dirty || version != 4 {
+		t.Fatalf("DirtyVersion() = %d, %v, %v; want 4 dirty", version, dirty, err)
+	}
+	if err := m.Status(); err != nil {
+		t.Errorf("Status() on a dirty database error = %v", err)
+	}
+
+	// Dirty state blocks every migration
+	failing = false
+	if err := m.Up(); err == nil {
+		t.Error("Up() ran on a dirty database")
+	}
+	if err := m.Down(); err == nil {
+		t.Error("Down() ran on a dirty database")
+	}
+	if applied := appliedVersions(t, m); len(applied) != 4 {
+		t.Errorf("applied while dirty = %v, want 1 to 4 untouched", applied)
+	}
+
+	// The failed migration changed nothing, so the schema is clean at 3
+	if err := m.Force(3); err != nil {
+		t.Fatalf("Force() error = %v", err)
+	}
+	if _, dirty, _ := m.DirtyVersion(); dirty {
+		t.Error("still dirty after Force()")
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() after Force() error = %v", err)
+	}
+	if applied := appliedVersions(t, m); !applied[4] {
+		t.Errorf("applied after the retry = %v, want 4", applied)
+	}
+}
@@ -37,7 +37,6 @@ func NewFeatureHandlers(
 // ===== Advanced Order Types =====
 
 func (h *FeatureHandlers) HandlePlaceBracketOrder(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
 	if r.Method == "OPTIONS" {
 		return
 	}
@@ -80,7 +79,6 @@ func (h *FeatureHandlers) HandlePlaceBracketOrder(w http.ResponseWriter, r *http
 }
 
 func (h *FeatureHandlers) HandlePlaceTWAPOrder(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
 	if r.Method == "OPTIONS" {
 		return
 	}
@@ -124,8 +122,6 @@ func (h *FeatureHandlers) HandlePlaceTWAPOrder(w http.ResponseWriter, r *http.Re
 }
 
 func (h *FeatureHandlers) HandleGetBracketOrders(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
-
 	orders := h.orderService.GetBracketOrders()
 
 	w.Header().Set("Content-Type", "application/json")
@@ -135,8 +131,6 @@ func (h *FeatureHandlers) HandleGetBracketOrders(w http.ResponseWriter, r *http.
 // ===== Technical Indicators =====
 
 func (h *FeatureHandlers) HandleCalculateIndicator(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
-
 	symbol := r.URL.Query().Get("symbol")
 	indicator := r.URL.Query().Get("indicator")
 	periodStr := r.URL.Query().Get("period")
@@ -187,7 +181,6 @@ func (h *FeatureHandlers) HandleCalculateIndicator(w http.ResponseWriter, r *htt
 // ===== Strategy Automation =====
 
 func (h *FeatureHandlers) HandleCreateStrategy(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
 	if r.Method == "OPTIONS" {
 		return
 	}
@@ -209,8 +202,6 @@ func (h *FeatureHandlers) HandleCreateStrategy(w http.ResponseWriter, r *http.Re
 }
 
 func (h *FeatureHandlers) HandleGetStrategies(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
-
 	strategies := h.strategyService.GetAllStrategies()
 
 	w.Header().Set("Content-Type", "application/json")
@@ -218,7 +209,6 @@ func (h *FeatureHandlers) HandleGetStrategies(w http.ResponseWriter, r *http.Req
 }
 
 func (h *FeatureHandlers) HandleRunBacktest(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
 	if r.Method == "OPTIONS" {
 		return
 	}
@@ -257,7 +247,6 @@ func (h *FeatureHandlers) HandleRunBacktest(w http.ResponseWriter, r *http.Reque
 // ===== Alerts =====
 
 func (h *FeatureHandlers) HandleCreateAlert(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
 	if r.Method == "OPTIONS" {
 		return
 	}
@@ -279,8 +268,6 @@ func (h *FeatureHandlers) HandleCreateAlert(w http.ResponseWriter, r *http.Reque
 }
 
 func (h *FeatureHandlers) HandleGetUserAlerts(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
-
 	userID := r.URL.Query().Get("userId")
 	if userID == "" {
 		http.Error(w, "userId required", http.StatusBadRequest)
@@ -294,8 +281,6 @@ func (h *FeatureHandlers) HandleGetUserAlerts(w http.ResponseWriter, r *http.Req
 }
 
 func (h *FeatureHandlers) HandleGetAlertTriggers(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
-
 	userID := r.URL.Query().Get("userId")
 	if userID == "" {
 		http.Error(w, "userId required", http.StatusBadRequest)
@@ -311,8 +296,6 @@ func (h *FeatureHandlers) HandleGetAlertTriggers(w http.ResponseWriter, r *http.
 // ===== Reports =====
 
 func (h *FeatureHandlers) HandleGenerateTaxReport(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
-
 	accountID := r.URL.Query().Get("accountId")
 	yearStr := r.URL.Query().Get("year")
 
@@ -338,8 +321,6 @@ func (h *FeatureHandlers) HandleGenerateTaxReport(w http.ResponseWriter, r *http
 }
 
 func (h *FeatureHandlers) HandleGeneratePerformanceReport(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
-
 	accountID := r.URL.Query().Get("accountId")
 	startDateStr := r.URL.Query().Get("startDate")
 	endDateStr := r.URL.Query().Get("endDate")
@@ -370,8 +351,6 @@ func (h *FeatureHandlers) HandleGeneratePerformanceReport(w http.ResponseWriter,
 }
 
 func (h *FeatureHandlers) HandleGenerateDrawdownAnalysis(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
-
 	accountID := r.URL.Query().Get("accountId")
 	balanceStr := r.URL.Query().Get("initialBalance")
 
@@ -401,12 +380,6 @@ func (h *FeatureHandlers) HandleGenerateDrawdownAnalysis(w http.ResponseWriter,
 
 // ===== Helper =====
 
-func setCORSHeaders(w http.ResponseWriter) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-}
-
 // RegisterRoutes registers all feature routes
 func (h *FeatureHandlers) RegisterRoutes() {
 	// Advanced Orders
@@ -0,0 +1,94 @@
+package fix
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fixField is one tag=value pair of a message
+type fixField struct {
+	tag   string
+	value string
+}
+
+// fixFields is a received message split into its fields once, so handlers
+// look tags up instead of rescanning the raw message for each one. Lookups
+// compare whole tags only, and a message has few enough fields that walking
+// the slice beats building a map per message.
+type fixFields struct {
+	ordered []fixField // Every field in wire order, for repeating groups
+}
+
+// nextField splits the leading tag=value field off msg and returns the rest.
+// ok is false once msg is exhausted; fields without '=' come back with an empty tag.
+func nextField(msg string) (tag, value, rest string, ok bool) {
+	if msg == "" {
+		return "", "", "", false
+	}
+	field, rest, _ := strings.Cut(msg, "\x01")
+	tag, value, found := strings.Cut(field, "=")
+	if !found {
+		return "", "", rest, true
+	}
+	return tag, value, rest, true
+}
+
+// parseFields indexes a raw FIX message in a single pass
+func parseFields(msg string) fixFields {
+	f := fixFields{ordered: make([]fixField, 0, strings.Count(msg, "\x01")+1)}
+	for rest := msg; ; {
+		tag, value, next, ok := nextField(rest)
+		if !ok {
+			break
+		}
+		rest = next
+		if tag == "" {
+			continue
+		}
+		f.ordered = append(f.ordered, fixField{tag: tag, value: value})
+	}
+	return f
+}
+
+// get returns the first value of a tag, or "" if the message has none
+func (f fixFields) get(tag string) string {
+	for _, field := range f.ordered {
+		if field.tag == tag {
+			return field.value
+		}
+	}
+	return ""
+}
+
+// has reports whether the first occurrence of tag carries value
+func (f fixFields) has(tag, value string) bool {
+	for _, field := range f.ordered {
+		if field.tag == tag {
+			return field.value == value
+		}
+	}
+	return false
+}
+
+// seqNum returns the message's MsgSeqNum (34), or 0 if it has none
+func (f fixFields) seqNum() int {
+	n, _ := strconv.Atoi(f.get("34"))
+	return n
+}
+
+// sendingLatency returns how long before now the counterparty stamped the
+// message's SendingTime (52), or 0 when it is missing or unparsable
+func (f fixFields) sendingLatency(now time.Time) time.Duration {
+	value := f.get("52")
+	if value == "" {
+		return 0
+	}
+	sent, err := time.Parse("20060102-15:04:05.000", value)
+	if err != nil {
+		if sent, err = time.Parse("20060102-15:04:05", value); err != nil {
+			return 0
+		}
+	}
+	return now.Sub(sent)
+}
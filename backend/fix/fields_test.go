@@ -0,0 +1,129 @@
+package fix
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// snapshotWithEntries builds a MarketDataSnapshot (35=W) with n alternating bid/offer entries
+func snapshotWithEntries(gw *FIXGateway, session *LPSession, n int) string {
+	fields := fmt.Sprintf("262=MD_EURUSD_1\x0155=EURUSD\x01268=%d\x01", n)
+	for i := 0; i < n; i++ {
+		fields += fmt.Sprintf("269=%d\x01270=1.%05d\x01271=%d\x01", i%2, 10000+i, 100000*(i+1))
+	}
+	return inbound(gw, session, MsgTypeMarketDataSnapshot, 1, fields)
+}
+
+// TestParseFields tests that fields are indexed by their first occurrence,
+// repeating groups keep wire order and lookups are anchored on whole tags
+func TestParseFields(t *testing.T) {
+	gw, session := newTestGateway(t)
+	msg := snapshotWithEntries(gw, session, 4)
+	fields := parseFields(msg)
+
+	for tag, want := range map[string]string{"8": "FIX.4.4", "35": "W", "55": "EURUSD", "268": "4", "269": "0", "270": "1.10000"} {
+		if got := fields.get(tag); got != want {
+			t.Errorf("get(%s) = %q, want %q", tag, got, want)
+		}
+	}
+	if got := fields.get("5"); got != "" {
+		t.Errorf("get(5) = %q, want no match inside 35", got)
+	}
+	if !fields.has("35", "W") || fields.has("35", "X") {
+		t.Error("has(35) does not match the message type")
+	}
+
+	var prices []string
+	for _, field := range fields.ordered {
+		if field.tag == "270" {
+			prices = append(prices, field.value)
+		}
+	}
+	if want := "1.10000,1.10001,1.10002,1.10003"; strings.Join(prices, ",") != want {
+		t.Errorf("ordered 270 values = %v, want %s", prices, want)
+	}
+	if last := fields.ordered[len(fields.ordered)-1]; last.tag != "10" {
+		t.Errorf("last field = %s, want the checksum", last.tag)
+	}
+
+	if got := gw.extractTag("108=30\x018=FIX.4.4\x01", "8"); got != "FIX.4.4" {
+		t.Errorf("extractTag(8) = %q, want the 8 field rather than the end of 108", got)
+	}
+}
+
+// TestSnapshotParsedFromFields tests that a multi-entry snapshot still takes
+// the last bid and offer of the book
+func TestSnapshotParsedFromFields(t *testing.T) {
+	gw, session := newTestGateway(t)
+	quotes := gw.Subscribe()
+	gw.processMessage(session, nil, snapshotWithEntries(gw, session, 10))
+
+	md := <-quotes
+	if md.Symbol != "EURUSD" || md.MDReqID != "MD_EURUSD_1" || md.Bid != 1.10008 || md.Ask != 1.10009 ||
+		md.BidSize != 900000 || md.AskSize != 1000000 {
+		t.Errorf("MarketData = %+v", md)
+	}
+}
+
+// substringExtractTag is the previous extractTag, which scanned the raw
+// message for each tag. Kept for benchmark comparison.
+func substringExtractTag(msg, tag string) string {
+	tagPrefix := tag + "="
+	start := 0
+	for i := 0; i <= len(msg)-len(tagPrefix); i++ {
+		if msg[i:i+len(tagPrefix)] == tagPrefix {
+			start = i + len(tagPrefix)
+			break
+		}
+	}
+	if start == 0 {
+		return ""
+	}
+	end := start
+	for end < len(msg) && msg[end] != '\x01' {
+		end++
+	}
+	return msg[start:end]
+}
+
+// BenchmarkSnapshotFields compares reading a 10-entry snapshot the way the
+// handlers used to (a scan per tag plus a split for the entries) with a
+// single parseFields pass
+func BenchmarkSnapshotFields(b *testing.B) {
+	b.Setenv("FIX_STORE_DIR", b.TempDir())
+	gw := NewFIXGateway()
+	session := &LPSession{SenderCompID: "BROKER", TargetCompID: "LP", BeginString: FIXVersion44}
+	msg := snapshotWithEntries(gw, session, 10)
+
+	b.Run("substring", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, tag := range []string{"35", "34", "43", "55", "262", "268"} {
+				substringExtractTag(msg, tag)
+			}
+			var prices int
+			for _, part := range strings.Split(msg, "\x01") {
+				if strings.HasPrefix(part, "269=") || strings.HasPrefix(part, "270=") || strings.HasPrefix(part, "271=") {
+					prices++
+				}
+			}
+		}
+	})
+
+	b.Run("parseFields", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			fields := parseFields(msg)
+			for _, tag := range []string{"35", "34", "43", "55", "262", "268"} {
+				fields.get(tag)
+			}
+			var prices int
+			for _, field := range fields.ordered {
+				if field.tag == "269" || field.tag == "270" || field.tag == "271" {
+					prices++
+				}
+			}
+		}
+	})
+}
@@ -4,16 +4,21 @@ import (
 	"bufio"
 	"crypto/tls"
 	"fmt"
-	"log"
 	"net"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/epic1st/rtx/backend/logging"
 )
 
+// logger writes the gateway's structured log entries, component "fix"
+var logger = logging.With(logging.Component("fix"))
+
 const (
 	// FIX message types - Session Level
 	MsgTypeLogon          = "A"
@@ -53,6 +58,10 @@ const (
 	MsgTypeSecurityDefinitionReq = "c"
 	MsgTypeSecurityDefinition    = "d"
 
+	// FIX message types - Trading Sessions
+	MsgTypeTradingSessionStatusReq = "g"
+	MsgTypeTradingSessionStatus    = "h"
+
 	// Store directory for sequence numbers
 	DefaultStoreDir = "./fixstore"
 )
@@ -80,6 +89,22 @@ type LPSession struct {
 	LastHeartbeat time.Time
 	conn          net.Conn
 
+	// HeartBtInt (108) proposed on Logon, 0 = DefaultHeartbeatInterval. The
+	// counterparty may lower it in its Logon response.
+	HeartbeatInterval time.Duration
+	heartbeatInterval time.Duration // Negotiated at Logon
+
+	// Automatic reconnection after the connection drops. An explicit
+	// Disconnect stops it until the next Connect.
+	ReconnectEnabled     bool
+	MaxReconnectAttempts int // 0 = retry until reconnected
+	reconnectAttempts    int
+	reconnectTimer       *time.Timer
+	manualDisconnect     bool
+
+	logoutSent bool          // Our Logout is out, so the LP's Logout is its confirmation
+	logoutAck  chan struct{} // Closed when the LP confirms a Logout sent by Shutdown
+
 	// Sequence number management (critical for FIX protocol)
 	OutSeqNum       int            // Next outgoing sequence number
 	InSeqNum        int            // Expected incoming sequence number
@@ -92,13 +117,18 @@ type LPSession struct {
 // ExecutionReport represents a fill or reject from LP
 type ExecutionReport struct {
 	OrderID   string
-	ExecType  string // NEW, FILLED, REJECTED, CANCELED
+	ClOrdID   string // Tag 11, as sent on the NewOrderSingle
+	ExecType  string // NEW, PARTIAL, FILLED, REJECTED, CANCELED
 	Symbol    string
 	Side      string
-	Volume    float64
-	Price     float64
+	Volume    float64 // LastQty of this fill
+	Price     float64 // LastPx of this fill
+	CumQty    float64 // Total filled so far (0 = not reported)
+	LeavesQty float64 // Still open at the LP
+	AvgPx     float64 // Average price of all fills so far (0 = not reported)
 	LPOrderID string
 	Text      string
+	RejReason string // OrdRejReason (103) of a reject, e.g. "2" = exchange closed
 	Timestamp time.Time
 }
 
@@ -116,10 +146,11 @@ type MarketData struct {
 
 // MarketDataReject represents a rejected market data subscription
 type MarketDataReject struct {
-	MDReqID   string
-	Reason    string
-	Text      string
-	SessionID string
+	MDReqID    string
+	Reason     string // MDReqRejReason (281) code as received
+	ReasonText string // Reason in words, e.g. "Duplicate MDReqID"
+	Text       string
+	SessionID  string
 }
 
 // Position represents an open position from LP
@@ -177,16 +208,33 @@ type OrderStatus struct {
 type FIXGateway struct {
 	sessions            map[string]*LPSession
 	execReports         chan ExecutionReport
-	marketData          chan MarketData
+	marketData          chan MarketData // Legacy single-consumer quote channel, see GetMarketData
+	legacyMarketData    atomic.Bool     // Set once GetMarketData is called, so an unread channel is not fed
+	mdBroadcast         marketDataBroadcaster
 	mdRejects           chan MarketDataReject
 	positions           chan Position
 	trades              chan TradeCapture
 	orderStatuses       chan OrderStatus
-	mdSubscriptions     map[string]string      // MDReqID -> Symbol mapping
-	symbolSubscriptions map[string]string      // Symbol -> MDReqID mapping (reverse lookup)
-	posSubscriptions    map[string]bool        // PosReqID -> active
-	quoteCache          map[string]*MarketData // Symbol -> Last known quote (for merging incremental updates)
+	tradingSessions     chan TradingSessionStatus
+	securityDefs        chan SecurityDefinition
+	stateEvents         chan SessionStateEvent
+	mdSubscriptions     map[string]string               // MDReqID -> Symbol mapping
+	symbolSubscriptions map[string]string               // Symbol -> MDReqID mapping (reverse lookup)
+	mdRequests          map[string]mdRequest            // MDReqID -> owning session and options, replayed on reconnect
+	savedSubscriptions  map[string]map[string]bool      // SessionID -> symbols persisted across restarts
+	securities          map[string][]SecurityDefinition // SessionID -> instruments from the last SecurityList
+	securityListReqs    map[string]string               // SessionID -> SecurityReqID of the stored list
+	posSubscriptions    map[string]bool                 // PosReqID -> active
+	quoteCache          map[string]*cachedQuote         // Symbol -> Last known quote (for merging incremental updates)
+	quoteStaleAfter     time.Duration                   // Cached sides older than this are not merged
 	quoteCacheMu        sync.RWMutex
+	stats               *sessionStatsTracker
+	logonWaiters        map[string][]chan struct{} // SessionID -> callers blocked in WaitForLogon
+	breakerConfig       ReconnectBreakerConfig
+	breakers            map[string]*reconnectBreaker // SessionID -> reconnect storm protection
+	breakerCallback     func(state ReconnectBreakerState, tripped bool)
+	reconnectBaseDelay  time.Duration
+	reconnectMaxDelay   time.Duration
 	mu                  sync.RWMutex
 }
 
@@ -282,15 +330,31 @@ func NewFIXGateway() *FIXGateway {
 		positions:           make(chan Position, 1000),
 		trades:              make(chan TradeCapture, 5000),
 		orderStatuses:       make(chan OrderStatus, 1000),
+		tradingSessions:     make(chan TradingSessionStatus, 100),
+		securityDefs:        make(chan SecurityDefinition, 500),
+		stateEvents:         make(chan SessionStateEvent, 100),
 		mdSubscriptions:     make(map[string]string),
 		symbolSubscriptions: make(map[string]string),
+		mdRequests:          make(map[string]mdRequest),
+		savedSubscriptions:  make(map[string]map[string]bool),
+		securities:          make(map[string][]SecurityDefinition),
+		securityListReqs:    make(map[string]string),
 		posSubscriptions:    make(map[string]bool),
-		quoteCache:          make(map[string]*MarketData),
+		quoteCache:          make(map[string]*cachedQuote),
+		quoteStaleAfter:     DefaultQuoteStaleAfter,
+		stats:               newSessionStatsTracker(),
+		logonWaiters:        make(map[string][]chan struct{}),
+		breakerConfig:       DefaultReconnectBreakerConfig(),
+		breakers:            make(map[string]*reconnectBreaker),
+		reconnectBaseDelay:  DefaultReconnectBaseDelay,
+		reconnectMaxDelay:   DefaultReconnectMaxDelay,
 	}
 
-	// Load persisted sequence numbers for all sessions
+	// Load persisted sequence numbers and subscriptions for all sessions
 	for _, session := range gw.sessions {
+		applyVersionOverride(session)
 		gw.loadSequenceNumbers(session)
+		gw.loadSubscriptions(session)
 	}
 
 	return gw
@@ -302,7 +366,7 @@ func (g *FIXGateway) loadSequenceNumbers(session *LPSession) {
 	file, err := os.Open(seqFile)
 	if err != nil {
 		// File doesn't exist, start fresh
-		log.Printf("[FIX] No stored sequence numbers for %s, starting fresh", session.ID)
+		logger.Info("No stored sequence numbers, starting fresh", logging.Session(session.ID))
 		session.OutSeqNum = 0
 		session.InSeqNum = 0
 		return
@@ -316,8 +380,8 @@ func (g *FIXGateway) loadSequenceNumbers(session *LPSession) {
 		if len(parts) == 2 {
 			session.OutSeqNum, _ = strconv.Atoi(parts[0])
 			session.InSeqNum, _ = strconv.Atoi(parts[1])
-			log.Printf("[FIX] Loaded sequence numbers for %s: Out=%d, In=%d",
-				session.ID, session.OutSeqNum, session.InSeqNum)
+			logger.Info("Loaded sequence numbers", logging.Session(session.ID),
+				logging.Int("out_seq", session.OutSeqNum), logging.Int("in_seq", session.InSeqNum))
 		}
 	}
 }
@@ -337,7 +401,7 @@ func (g *FIXGateway) resetSequenceNumbers(session *LPSession) {
 	session.msgStore = make(map[int]string) // Clear message store
 	session.msgStoreMu.Unlock()
 	g.saveSequenceNumbers(session)
-	log.Printf("[FIX] Reset sequence numbers for %s", session.ID)
+	logger.Info("Reset sequence numbers", logging.Session(session.ID))
 }
 
 // storeMessage saves a sent message for potential resend
@@ -392,6 +456,12 @@ func getEnvIntOrDefault(key string, defaultValue int) int {
 
 // Connect initiates a FIX session with real TCP connection
 func (g *FIXGateway) Connect(sessionID string) error {
+	return g.connect(sessionID, true)
+}
+
+// connect starts a connection attempt. Automatic reconnects (manual=false) are
+// refused once reconnection is disabled or the session was explicitly disconnected.
+func (g *FIXGateway) connect(sessionID string, manual bool) error {
 	g.mu.Lock()
 	session, ok := g.sessions[sessionID]
 	if !ok {
@@ -399,18 +469,35 @@ func (g *FIXGateway) Connect(sessionID string) error {
 		return fmt.Errorf("session not found: %s", sessionID)
 	}
 
+	if !manual && (!session.ReconnectEnabled || session.manualDisconnect) {
+		g.mu.Unlock()
+		return fmt.Errorf("automatic reconnect stopped for %s", sessionID)
+	}
+
 	if session.Status == "LOGGED_IN" || session.Status == "CONNECTING" {
 		g.mu.Unlock()
 		return fmt.Errorf("session already %s", session.Status)
 	}
 
+	if err := g.checkReconnectBreakerUnlocked(sessionID, time.Now()); err != nil {
+		g.mu.Unlock()
+		return err
+	}
+
 	if session.UseProxy {
-		log.Printf("[FIX] Connecting to %s at %s:%d via proxy %s:%d",
-			session.Name, session.Host, session.Port, session.ProxyHost, session.ProxyPort)
+		logger.Info("Connecting", logging.Session(session.ID), logging.String("addr", fmt.Sprintf("%s:%d", session.Host, session.Port)),
+			logging.String("proxy", fmt.Sprintf("%s:%d", session.ProxyHost, session.ProxyPort)))
 	} else {
-		log.Printf("[FIX] Connecting to %s at %s:%d", session.Name, session.Host, session.Port)
+		logger.Info("Connecting", logging.Session(session.ID), logging.String("addr", fmt.Sprintf("%s:%d", session.Host, session.Port)))
+	}
+	reason := "connect requested"
+	if !manual {
+		reason = "reconnecting"
+	}
+	g.setStatusUnlocked(session, "CONNECTING", reason)
+	if manual {
+		session.manualDisconnect = false
 	}
-	session.Status = "CONNECTING"
 	g.mu.Unlock()
 
 	// Start connection in goroutine
@@ -421,6 +508,7 @@ func (g *FIXGateway) Connect(sessionID string) error {
 
 // connectSession handles the actual TCP connection and FIX logon
 func (g *FIXGateway) connectSession(session *LPSession) {
+	started := time.Now()
 	var conn net.Conn
 	var err error
 
@@ -434,16 +522,18 @@ func (g *FIXGateway) connectSession(session *LPSession) {
 	}
 
 	if err != nil {
-		log.Printf("[FIX] Failed to connect to %s: %v", session.Name, err)
+		logger.Error("Failed to connect", err, logging.Session(session.ID))
+		g.recordConnectFailure(session, err)
 		g.mu.Lock()
-		session.Status = "DISCONNECTED"
+		g.setStatusUnlocked(session, "DISCONNECTED", fmt.Sprintf("connect failed: %v", err))
+		g.scheduleReconnectUnlocked(session)
 		g.mu.Unlock()
 		return
 	}
 
 	// Wrap with TLS if SSL is enabled
 	if session.SSL {
-		log.Printf("[FIX] Upgrading connection to TLS for %s", session.Name)
+		logger.Info("Upgrading connection to TLS", logging.Session(session.ID))
 		tlsConfig := &tls.Config{
 			InsecureSkipVerify: true, // Often needed for FIX servers with IP addresses
 			ServerName:         session.Host,
@@ -453,16 +543,18 @@ func (g *FIXGateway) connectSession(session *LPSession) {
 		// Perform TLS handshake with timeout
 		tlsConn.SetDeadline(time.Now().Add(10 * time.Second))
 		if err := tlsConn.Handshake(); err != nil {
-			log.Printf("[FIX] TLS handshake failed for %s: %v", session.Name, err)
+			logger.Error("TLS handshake failed", err, logging.Session(session.ID))
 			conn.Close()
+			g.recordConnectFailure(session, err)
 			g.mu.Lock()
-			session.Status = "DISCONNECTED"
+			g.setStatusUnlocked(session, "DISCONNECTED", fmt.Sprintf("TLS handshake failed: %v", err))
+			g.scheduleReconnectUnlocked(session)
 			g.mu.Unlock()
 			return
 		}
 		tlsConn.SetDeadline(time.Time{}) // Clear deadline
 		conn = tlsConn
-		log.Printf("[FIX] TLS handshake successful for %s", session.Name)
+		logger.Info("TLS handshake successful", logging.Session(session.ID))
 	}
 
 	// Apply TCP optimizations for low-latency trading
@@ -475,35 +567,42 @@ func (g *FIXGateway) connectSession(session *LPSession) {
 		// Enable TCP keep-alive for connection health monitoring
 		tcpConn.SetKeepAlive(true)
 		tcpConn.SetKeepAlivePeriod(30 * time.Second)
-		log.Printf("[FIX] TCP optimizations applied (NoDelay=true, ReadBuf=128KB, WriteBuf=64KB)")
+		logger.Debug("TCP optimizations applied (NoDelay=true, ReadBuf=128KB, WriteBuf=64KB)", logging.Session(session.ID))
 	}
 
 	g.mu.Lock()
 	session.conn = conn
-	session.Status = "CONNECTED"
+	g.setStatusUnlocked(session, "CONNECTED", "TCP connected")
 	g.mu.Unlock()
-	log.Printf("[FIX] TCP connected to %s", session.Name)
+	logger.Info("TCP connected", logging.Session(session.ID), logging.Latency(time.Since(started)))
 
 	// Send FIX 4.4 Logon message
 	if err := g.sendLogon(session); err != nil {
-		log.Printf("[FIX] Logon failed for %s: %v", session.Name, err)
+		logger.Error("Logon failed", err, logging.Session(session.ID))
 		conn.Close()
+		g.recordConnectFailure(session, err)
 		g.mu.Lock()
-		session.Status = "DISCONNECTED"
+		g.setStatusUnlocked(session, "DISCONNECTED", fmt.Sprintf("logon failed: %v", err))
 		session.conn = nil
+		g.scheduleReconnectUnlocked(session)
 		g.mu.Unlock()
 		return
 	}
 
 	g.mu.Lock()
-	session.Status = "LOGGED_IN"
+	g.markLoggedInUnlocked(session)
 	session.LastHeartbeat = time.Now()
+	session.reconnectAttempts = 0
 	g.mu.Unlock()
-	log.Printf("[FIX] Logged in to %s", session.Name)
+	logger.Info("Logged in", logging.Session(session.ID), logging.Latency(time.Since(started)))
+	g.recordConnectSuccess(session)
 
 	// Start heartbeat and message reading goroutines
 	go g.heartbeatLoop(session)
 	go g.readMessages(session)
+
+	// Replay the market data the session held before it last disconnected
+	g.resubscribeSession(session)
 }
 
 // dialViaHTTPProxy connects to the target through an HTTP CONNECT proxy
@@ -511,7 +610,7 @@ func (g *FIXGateway) dialViaHTTPProxy(session *LPSession) (net.Conn, error) {
 	proxyAddr := fmt.Sprintf("%s:%d", session.ProxyHost, session.ProxyPort)
 	targetAddr := fmt.Sprintf("%s:%d", session.Host, session.Port)
 
-	log.Printf("[FIX] Connecting to proxy %s", proxyAddr)
+	logger.Info("Connecting to proxy", logging.Session(session.ID), logging.String("proxy", proxyAddr))
 
 	// Connect to proxy server
 	conn, err := net.DialTimeout("tcp", proxyAddr, 10*time.Second)
@@ -520,13 +619,13 @@ func (g *FIXGateway) dialViaHTTPProxy(session *LPSession) (net.Conn, error) {
 	}
 
 	// Try SOCKS5 protocol first
-	log.Printf("[FIX] Attempting SOCKS5 handshake to tunnel to %s", targetAddr)
+	logger.Debug("Attempting SOCKS5 handshake", logging.Session(session.ID), logging.String("target", targetAddr))
 	socks5Conn, socks5Err := g.attemptSocks5(conn, session)
 	if socks5Err == nil {
-		log.Printf("[FIX] SOCKS5 tunnel established to %s", targetAddr)
+		logger.Info("SOCKS5 tunnel established", logging.Session(session.ID), logging.String("target", targetAddr))
 		return socks5Conn, nil
 	}
-	log.Printf("[FIX] SOCKS5 failed (%v), falling back to HTTP CONNECT", socks5Err)
+	logger.Warn("SOCKS5 failed, falling back to HTTP CONNECT", logging.Session(session.ID), logging.Err(socks5Err))
 
 	// Reconnect for HTTP CONNECT (SOCKS5 attempt may have corrupted connection)
 	conn.Close()
@@ -544,7 +643,7 @@ func (g *FIXGateway) dialViaHTTPProxy(session *LPSession) (net.Conn, error) {
 		"\r\n",
 		targetAddr, targetAddr, auth)
 
-	log.Printf("[FIX] Sending HTTP CONNECT request to tunnel to %s", targetAddr)
+	logger.Debug("Sending HTTP CONNECT request", logging.Session(session.ID), logging.String("target", targetAddr))
 
 	// Send CONNECT request
 	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
@@ -563,7 +662,7 @@ func (g *FIXGateway) dialViaHTTPProxy(session *LPSession) (net.Conn, error) {
 	}
 
 	respStr := string(response[:n])
-	log.Printf("[FIX] Proxy response: %s", respStr[:min(len(respStr), 100)])
+	logger.Debug("Proxy response", logging.Session(session.ID), logging.String("response", respStr[:min(len(respStr), 100)]))
 
 	// Check for successful connection (HTTP/1.x 200)
 	if len(respStr) < 12 || respStr[9:12] != "200" {
@@ -574,7 +673,7 @@ func (g *FIXGateway) dialViaHTTPProxy(session *LPSession) (net.Conn, error) {
 	// Clear deadlines for ongoing FIX communication
 	conn.SetDeadline(time.Time{})
 
-	log.Printf("[FIX] HTTP tunnel established through proxy to %s", targetAddr)
+	logger.Info("HTTP tunnel established through proxy", logging.Session(session.ID), logging.String("target", targetAddr))
 	return conn, nil
 }
 
@@ -686,7 +785,7 @@ func (g *FIXGateway) sendLogon(session *LPSession) error {
 	// Handle sequence number reset if configured
 	if session.ResetSeqNumFlag {
 		g.resetSequenceNumbers(session)
-		log.Printf("[FIX] Sequence numbers reset for %s (ResetSeqNumFlag=Y)", session.ID)
+		logger.Info("Sequence numbers reset (ResetSeqNumFlag=Y)", logging.Session(session.ID))
 	}
 
 	// Get next sequence number (increments and persists)
@@ -694,20 +793,21 @@ func (g *FIXGateway) sendLogon(session *LPSession) error {
 	sendingTime := time.Now().UTC().Format("20060102-15:04:05.000")
 
 	// Build body first (excluding BeginString, BodyLength, and Checksum)
-	// Tag 35=A (Logon), Tag 98=0 (No encryption), Tag 108=30 (HeartBtInt)
-	// Tag 141=Y (ResetSeqNumFlag) if resetting, Tag 553=Username, Tag 554=Password
+	// Tag 35=A (Logon), Tag 98=0 (No encryption), Tag 108 (HeartBtInt, 30 unless configured)
+	// Tag 141=Y (ResetSeqNumFlag) if resetting, then credentials (553/554, or 95/96 on FIX 4.2)
 	body := fmt.Sprintf("35=%s\x01"+
 		"49=%s\x01"+ // SenderCompID
 		"56=%s\x01"+ // TargetCompID
 		"34=%d\x01"+ // MsgSeqNum
 		"52=%s\x01"+ // SendingTime
 		"98=0\x01"+ // EncryptMethod (None)
-		"108=30\x01", // HeartBtInt (30 seconds)
+		"108=%d\x01", // HeartBtInt (seconds)
 		MsgTypeLogon,
 		session.SenderCompID,
 		session.TargetCompID,
 		msgSeqNum,
 		sendingTime,
+		heartbeatSeconds(session.configuredHeartbeat()),
 	)
 
 	// NOTE: ResetSeqNumFlag (141=Y) is NOT sent in Logon message
@@ -717,13 +817,8 @@ func (g *FIXGateway) sendLogon(session *LPSession) error {
 	// 	body += "141=Y\x01" // ResetSeqNumFlag - DISABLED: Server doesn't support
 	// }
 
-	// Add credentials
-	if session.Username != "" {
-		body += fmt.Sprintf("553=%s\x01", session.Username) // Username
-	}
-	if session.Password != "" {
-		body += fmt.Sprintf("554=%s\x01", session.Password) // Password
-	}
+	// Add credentials in the session's FIX version
+	body += session.logonCredentials()
 
 	// Build complete message
 	fullMsg := g.buildMessage(session, body)
@@ -731,12 +826,11 @@ func (g *FIXGateway) sendLogon(session *LPSession) error {
 	// Store the message for potential resend (excluding Logon per FIX spec - admin messages may be gap-filled)
 	g.storeMessage(session, msgSeqNum, fullMsg)
 
-	log.Printf("[FIX] Sending Logon to %s: SenderCompID=%s, TargetCompID=%s, User=%s, SeqNum=%d, ResetSeqNum=%v",
-		session.Name, session.SenderCompID, session.TargetCompID, session.Username, msgSeqNum, session.ResetSeqNumFlag)
+	logger.Info("Sending Logon", logging.Session(session.ID), logging.Seq(msgSeqNum),
+		logging.String("sender_comp_id", session.SenderCompID), logging.String("target_comp_id", session.TargetCompID),
+		logging.String("user", session.Username), logging.Bool("reset_seq_num", session.ResetSeqNumFlag))
 
-	// Set write deadline
-	session.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-	_, err := session.conn.Write([]byte(fullMsg))
+	err := g.writeMessage(session, session.conn, fullMsg)
 	if err != nil {
 		return fmt.Errorf("failed to send logon: %v", err)
 	}
@@ -750,11 +844,14 @@ func (g *FIXGateway) sendLogon(session *LPSession) error {
 	}
 
 	response := string(buffer[:n])
-	log.Printf("[FIX] Received from %s: %s", session.Name, g.formatFIXMessage(response))
+	if logger.Enabled(logging.DEBUG) {
+		logger.Debug("Received message", logging.Session(session.ID), logging.String("raw", g.formatFIXMessage(response)))
+	}
+	g.stats.recordReceived(session.ID, g.extractTag(response, "35"))
 
 	// Parse and validate incoming sequence number
-	if err := g.validateAndUpdateInSeq(session, response); err != nil {
-		log.Printf("[FIX] Warning: %v", err)
+	if err := g.validateAndUpdateInSeq(session, parseFields(response)); err != nil {
+		logger.Warn("Logon response sequence check failed", logging.Session(session.ID), logging.Err(err))
 		// Don't fail on logon response seq validation - counterparty may have reset
 	}
 
@@ -771,10 +868,12 @@ func (g *FIXGateway) sendLogon(session *LPSession) error {
 
 	// Check if counterparty is also resetting sequence numbers
 	if g.containsTag(response, "141", "Y") {
-		log.Printf("[FIX] Counterparty also reset sequence numbers")
+		logger.Info("Counterparty also reset sequence numbers", logging.Session(session.ID))
 		session.InSeqNum = 1 // They reset, so expect seq 1
 	}
 
+	g.negotiateHeartbeat(session, response)
+
 	return nil
 }
 
@@ -787,8 +886,8 @@ func (g *FIXGateway) buildMessage(session *LPSession, body string) string {
 }
 
 // validateAndUpdateInSeq validates incoming sequence number and updates expected
-func (g *FIXGateway) validateAndUpdateInSeq(session *LPSession, msg string) error {
-	inSeqStr := g.extractTag(msg, "34")
+func (g *FIXGateway) validateAndUpdateInSeq(session *LPSession, fields fixFields) error {
+	inSeqStr := fields.get("34")
 	if inSeqStr == "" {
 		return fmt.Errorf("missing MsgSeqNum (34) in message")
 	}
@@ -802,18 +901,17 @@ func (g *FIXGateway) validateAndUpdateInSeq(session *LPSession, msg string) erro
 
 	if inSeq > expectedSeq {
 		// Gap detected - we missed messages
-		log.Printf("[FIX] Sequence gap detected for %s: expected %d, got %d",
-			session.ID, expectedSeq, inSeq)
+		logger.Warn("Sequence gap detected", logging.Session(session.ID), logging.Seq(inSeq), logging.Int("expected_seq", expectedSeq))
 		// Send ResendRequest for missing messages
 		go g.sendResendRequest(session, expectedSeq, inSeq-1)
 	} else if inSeq < expectedSeq {
 		// Check for PossDupFlag
-		if !g.containsTag(msg, "43", "Y") {
+		if !fields.has("43", "Y") {
 			return fmt.Errorf("sequence number too low: expected %d, got %d (no PossDupFlag)",
 				expectedSeq, inSeq)
 		}
 		// It's a resend, accept it
-		log.Printf("[FIX] Received resent message %d (PossDupFlag=Y)", inSeq)
+		logger.Debug("Received resent message (PossDupFlag=Y)", logging.Session(session.ID), logging.Seq(inSeq))
 		return nil
 	}
 
@@ -928,61 +1026,56 @@ func (g *FIXGateway) formatFIXMessage(msg string) string {
 	return result
 }
 
-// containsTag checks if a FIX message contains a specific tag=value
+// containsTag checks if a FIX message contains a specific tag=value field.
+// The field is anchored on SOH both sides, so 35=A never matches inside 135=A.
+// The first field (8=) has no leading SOH, and a fragment may lack the trailing one.
 func (g *FIXGateway) containsTag(msg string, tag string, value string) bool {
-	search := tag + "=" + value + "\x01"
-	return len(msg) >= len(search) && (msg == search || len(msg) > len(search) && (msg[:len(search)] == search || msg[len(msg)-len(search):] == search || len(msg) > len(search)+1 && findSubstring(msg, search)))
-}
-
-func findSubstring(s, sub string) bool {
-	for i := 0; i <= len(s)-len(sub); i++ {
-		if s[i:i+len(sub)] == sub {
-			return true
-		}
-	}
-	return false
+	field := tag + "=" + value
+	return msg == field ||
+		strings.HasPrefix(msg, field+"\x01") ||
+		strings.Contains(msg, "\x01"+field+"\x01") ||
+		strings.HasSuffix(msg, "\x01"+field)
 }
 
-// extractTag extracts a tag value from a FIX message
+// extractTag extracts a tag value from a FIX message. Received messages are
+// indexed once with parseFields; this is for one-off lookups on raw messages.
 func (g *FIXGateway) extractTag(msg string, tag string) string {
-	tagPrefix := tag + "="
-	start := 0
-	for i := 0; i <= len(msg)-len(tagPrefix); i++ {
-		if msg[i:i+len(tagPrefix)] == tagPrefix {
-			start = i + len(tagPrefix)
-			break
+	for rest := msg; ; {
+		t, value, next, ok := nextField(rest)
+		if !ok {
+			return ""
 		}
+		if t == tag {
+			return value
+		}
+		rest = next
 	}
-	if start == 0 {
-		return ""
-	}
-	end := start
-	for end < len(msg) && msg[end] != '\x01' {
-		end++
-	}
-	return msg[start:end]
 }
 
 // heartbeatLoop sends periodic heartbeats
 func (g *FIXGateway) heartbeatLoop(session *LPSession) {
-	ticker := time.NewTicker(30 * time.Second)
+	ticker := time.NewTicker(g.activeHeartbeat(session))
 	defer ticker.Stop()
 
+	g.mu.RLock()
+	conn := session.conn
+	g.mu.RUnlock()
+
 	for {
 		<-ticker.C
 
+		// Stop once the connection this loop was started for is gone
 		g.mu.RLock()
-		if session.Status != "LOGGED_IN" || session.conn == nil {
+		if session.Status != "LOGGED_IN" || session.conn == nil || session.conn != conn {
 			g.mu.RUnlock()
 			return
 		}
-		conn := session.conn
 		g.mu.RUnlock()
 
 		// Send Heartbeat (35=0) - no TestReqID for unsolicited heartbeats
 		if err := g.sendHeartbeat(session, conn, ""); err != nil {
-			log.Printf("[FIX] Heartbeat failed for %s: %v", session.Name, err)
-			g.Disconnect(session.ID)
+			logger.Error("Heartbeat failed", err, logging.Session(session.ID))
+			g.dropConnection(session, conn, fmt.Sprintf("heartbeat failed: %v", err))
 			return
 		}
 
@@ -1023,10 +1116,9 @@ func (g *FIXGateway) sendHeartbeat(session *LPSession, conn net.Conn, testReqID
 	// Store heartbeat for potential resend
 	g.storeMessage(session, msgSeqNum, fullMsg)
 
-	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-	_, err := conn.Write([]byte(fullMsg))
+	err := g.writeMessage(session, conn, fullMsg)
 	if err == nil {
-		log.Printf("[FIX] Sent Heartbeat to %s: SeqNum=%d", session.Name, msgSeqNum)
+		logger.Debug("Sent Heartbeat", logging.Session(session.ID), logging.Seq(msgSeqNum))
 	}
 	return err
 }
@@ -1057,10 +1149,9 @@ func (g *FIXGateway) sendTestRequest(session *LPSession, conn net.Conn) error {
 	fullMsg := g.buildMessage(session, body)
 	g.storeMessage(session, msgSeqNum, fullMsg)
 
-	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-	_, err := conn.Write([]byte(fullMsg))
+	err := g.writeMessage(session, conn, fullMsg)
 	if err == nil {
-		log.Printf("[FIX] Sent TestRequest to %s: SeqNum=%d, TestReqID=%s", session.Name, msgSeqNum, testReqID)
+		logger.Debug("Sent TestRequest", logging.Session(session.ID), logging.Seq(msgSeqNum), logging.String("test_req_id", testReqID))
 	}
 	return err
 }
@@ -1100,11 +1191,10 @@ func (g *FIXGateway) sendResendRequest(session *LPSession, beginSeqNo, endSeqNo
 	fullMsg := g.buildMessage(session, body)
 	g.storeMessage(session, msgSeqNum, fullMsg)
 
-	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-	_, err := conn.Write([]byte(fullMsg))
+	err := g.writeMessage(session, conn, fullMsg)
 	if err == nil {
-		log.Printf("[FIX] Sent ResendRequest to %s: SeqNum=%d, BeginSeqNo=%d, EndSeqNo=%d",
-			session.Name, msgSeqNum, beginSeqNo, endSeqNo)
+		logger.Info("Sent ResendRequest", logging.Session(session.ID), logging.Seq(msgSeqNum),
+			logging.Int("begin_seq", beginSeqNo), logging.Int("end_seq", endSeqNo))
 	}
 	return err
 }
@@ -1162,22 +1252,21 @@ func (g *FIXGateway) sendSequenceReset(session *LPSession, conn net.Conn, newSeq
 
 	fullMsg := g.buildMessage(session, body)
 
-	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-	_, err := conn.Write([]byte(fullMsg))
+	err := g.writeMessage(session, conn, fullMsg)
 	if err == nil {
-		log.Printf("[FIX] Sent SequenceReset to %s: SeqNum=%d, NewSeqNo=%d, GapFill=%v",
-			session.Name, msgSeqNum, newSeqNo, gapFill)
+		logger.Info("Sent SequenceReset", logging.Session(session.ID), logging.Seq(msgSeqNum),
+			logging.Int("new_seq", newSeqNo), logging.Bool("gap_fill", gapFill))
 	}
 	return err
 }
 
 // handleResendRequest processes an incoming ResendRequest
-func (g *FIXGateway) handleResendRequest(session *LPSession, msg string) {
-	beginSeqNo, _ := strconv.Atoi(g.extractTag(msg, "7"))
-	endSeqNo, _ := strconv.Atoi(g.extractTag(msg, "16"))
+func (g *FIXGateway) handleResendRequest(session *LPSession, fields fixFields) {
+	beginSeqNo, _ := strconv.Atoi(fields.get("7"))
+	endSeqNo, _ := strconv.Atoi(fields.get("16"))
 
-	log.Printf("[FIX] Received ResendRequest from %s: BeginSeqNo=%d, EndSeqNo=%d",
-		session.Name, beginSeqNo, endSeqNo)
+	logger.Info("Received ResendRequest", logging.Session(session.ID), logging.Seq(fields.seqNum()),
+		logging.Int("begin_seq", beginSeqNo), logging.Int("end_seq", endSeqNo))
 
 	g.mu.RLock()
 	conn := session.conn
@@ -1198,13 +1287,16 @@ func (g *FIXGateway) handleResendRequest(session *LPSession, msg string) {
 		if found {
 			// Resend with PossDupFlag=Y
 			resendMsg := g.addPossDupFlag(storedMsg)
-			conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-			conn.Write([]byte(resendMsg))
-			log.Printf("[FIX] Resent message %d to %s", seqNum, session.Name)
+			if err := g.writeMessage(session, conn, resendMsg); err == nil {
+				g.stats.recordResent(session.ID, false)
+			}
+			logger.Debug("Resent message", logging.Session(session.ID), logging.Seq(seqNum))
 		} else {
 			// Message not found - send GapFill to skip it
-			log.Printf("[FIX] Message %d not found, sending GapFill", seqNum)
-			g.sendSequenceReset(session, conn, seqNum+1, true)
+			logger.Debug("Message not stored, sending GapFill", logging.Session(session.ID), logging.Seq(seqNum))
+			if err := g.sendSequenceReset(session, conn, seqNum+1, true); err == nil {
+				g.stats.recordResent(session.ID, true)
+			}
 		}
 	}
 }
@@ -1234,12 +1326,12 @@ func (g *FIXGateway) addPossDupFlag(msg string) string {
 }
 
 // handleSequenceReset processes an incoming SequenceReset message
-func (g *FIXGateway) handleSequenceReset(session *LPSession, msg string) {
-	newSeqNo, _ := strconv.Atoi(g.extractTag(msg, "36"))
-	gapFill := g.containsTag(msg, "123", "Y")
+func (g *FIXGateway) handleSequenceReset(session *LPSession, fields fixFields) {
+	newSeqNo, _ := strconv.Atoi(fields.get("36"))
+	gapFill := fields.has("123", "Y")
 
-	log.Printf("[FIX] Received SequenceReset from %s: NewSeqNo=%d, GapFill=%v",
-		session.Name, newSeqNo, gapFill)
+	logger.Info("Received SequenceReset", logging.Session(session.ID), logging.Seq(fields.seqNum()),
+		logging.Int("new_seq", newSeqNo), logging.Bool("gap_fill", gapFill))
 
 	if gapFill {
 		// GapFill - just advance our expected incoming seq
@@ -1259,13 +1351,16 @@ func (g *FIXGateway) readMessages(session *LPSession) {
 	buffer := make([]byte, 8192)
 	var partialMsg string // Buffer for incomplete messages
 
+	g.mu.RLock()
+	conn := session.conn
+	g.mu.RUnlock()
+
 	for {
 		g.mu.RLock()
-		if session.Status != "LOGGED_IN" || session.conn == nil {
+		if session.Status != "LOGGED_IN" || session.conn == nil || session.conn != conn {
 			g.mu.RUnlock()
 			return
 		}
-		conn := session.conn
 		g.mu.RUnlock()
 
 		conn.SetReadDeadline(time.Now().Add(60 * time.Second))
@@ -1274,8 +1369,8 @@ func (g *FIXGateway) readMessages(session *LPSession) {
 			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
 				continue // Timeout is ok, just retry
 			}
-			log.Printf("[FIX] Read error for %s: %v", session.Name, err)
-			g.Disconnect(session.ID)
+			logger.Error("Read error", err, logging.Session(session.ID))
+			g.dropConnection(session, conn, fmt.Sprintf("read error: %v", err))
 			return
 		}
 
@@ -1340,19 +1435,26 @@ func (g *FIXGateway) splitFIXMessages(data string) []string {
 
 // processMessage handles a single FIX message
 func (g *FIXGateway) processMessage(session *LPSession, conn net.Conn, msg string) {
-	log.Printf("[FIX] Received from %s: %s", session.Name, g.formatFIXMessage(msg))
-
 	// Validate message checksum and body length
 	if err := g.validateMessage(msg); err != nil {
-		log.Printf("[FIX] Message validation error for %s: %v", session.Name, err)
+		logger.Warn("Message validation error", logging.Session(session.ID), logging.Err(err))
 		// Log warning but continue - some LPs may have minor protocol deviations
 	}
 
+	// Index the fields once; handlers read from this instead of rescanning msg
+	fields := parseFields(msg)
+
 	// Validate and update sequence number (skip for SequenceReset which has special handling)
-	msgType := g.extractTag(msg, "35")
+	msgType := fields.get("35")
+	g.stats.recordReceived(session.ID, msgType)
+	if logger.Enabled(logging.DEBUG) {
+		logger.Debug("Received message", logging.Session(session.ID), logging.Seq(fields.seqNum()),
+			logging.String("msg_type", msgType), logging.String("raw", g.formatFIXMessage(msg)))
+	}
+
 	if msgType != MsgTypeSequenceReset {
-		if err := g.validateAndUpdateInSeq(session, msg); err != nil {
-			log.Printf("[FIX] Sequence error for %s: %v", session.Name, err)
+		if err := g.validateAndUpdateInSeq(session, fields); err != nil {
+			logger.Warn("Sequence error", logging.Session(session.ID), logging.Seq(fields.seqNum()), logging.Err(err))
 			// For serious sequence errors, we may need to disconnect
 			// But continue processing for now
 		}
@@ -1361,9 +1463,15 @@ func (g *FIXGateway) processMessage(session *LPSession, conn net.Conn, msg strin
 	// Handle different message types
 	switch msgType {
 	case MsgTypeLogout: // Logout (35=5)
-		text := g.extractTag(msg, "58")
-		log.Printf("[FIX] Received Logout from %s: %s", session.Name, text)
-		g.Disconnect(session.ID)
+		text := fields.get("58")
+		logger.Info("Received Logout", logging.Session(session.ID), logging.Seq(fields.seqNum()), logging.String("text", text))
+		g.mu.Lock()
+		if session.logoutAck != nil {
+			close(session.logoutAck)
+			session.logoutAck = nil
+		}
+		g.mu.Unlock()
+		g.dropConnection(session, conn, "logout received: "+text)
 		return
 
 	case MsgTypeHeartbeat: // Heartbeat (35=0)
@@ -1371,101 +1479,143 @@ func (g *FIXGateway) processMessage(session *LPSession, conn net.Conn, msg strin
 		g.mu.Lock()
 		session.LastHeartbeat = time.Now()
 		g.mu.Unlock()
-		log.Printf("[FIX] Received Heartbeat from %s", session.Name)
+		logger.Debug("Received Heartbeat", logging.Session(session.ID), logging.Seq(fields.seqNum()))
 
 	case MsgTypeTestRequest: // TestRequest (35=1)
-		testReqID := g.extractTag(msg, "112")
-		log.Printf("[FIX] Received TestRequest from %s: TestReqID=%s", session.Name, testReqID)
+		testReqID := fields.get("112")
+		logger.Debug("Received TestRequest", logging.Session(session.ID), logging.Seq(fields.seqNum()), logging.String("test_req_id", testReqID))
 		// Respond with Heartbeat containing the TestReqID
 		g.sendHeartbeat(session, conn, testReqID)
 
 	case MsgTypeResendRequest: // ResendRequest (35=2)
-		g.handleResendRequest(session, msg)
+		g.handleResendRequest(session, fields)
 
 	case MsgTypeReject: // Reject (35=3)
-		refSeqNum := g.extractTag(msg, "45")
-		text := g.extractTag(msg, "58")
-		log.Printf("[FIX] Received Reject from %s: RefSeqNum=%s, Text=%s", session.Name, refSeqNum, text)
+		refSeqNum := fields.get("45")
+		text := fields.get("58")
+		logger.Warn("Received Reject", logging.Session(session.ID), logging.Seq(fields.seqNum()),
+			logging.String("ref_seq_num", refSeqNum), logging.String("text", text))
 
 	case MsgTypeSequenceReset: // SequenceReset (35=4)
-		g.handleSequenceReset(session, msg)
+		g.handleSequenceReset(session, fields)
 
 	case MsgTypeExecutionReport: // ExecutionReport (35=8)
-		g.handleExecutionReport(session, msg)
+		g.handleExecutionReport(session, fields)
 
 	case MsgTypeMarketDataSnapshot: // MarketDataSnapshot (35=W)
-		g.handleMarketDataSnapshot(session, msg)
+		g.handleMarketDataSnapshot(session, fields)
 
 	case MsgTypeMarketDataIncremental: // MarketDataIncremental (35=X)
-		g.handleMarketDataIncremental(session, msg)
+		g.handleMarketDataIncremental(session, fields)
 
 	case MsgTypeMarketDataReject: // MarketDataReject (35=Y)
-		g.handleMarketDataReject(session, msg)
+		g.handleMarketDataReject(session, fields)
 
 	case MsgTypeOrderCancelReject: // OrderCancelReject (35=9)
-		g.handleOrderCancelReject(session, msg)
+		g.handleOrderCancelReject(session, fields)
 
 	case MsgTypeRequestForPositionsAck: // RequestForPositionsAck (35=AO)
-		g.handleRequestForPositionsAck(session, msg)
+		g.handleRequestForPositionsAck(session, fields)
 
 	case MsgTypePositionReport: // PositionReport (35=AP)
-		g.handlePositionReport(session, msg)
+		g.handlePositionReport(session, fields)
 
 	case MsgTypeTradeCaptureReportAck: // TradeCaptureReportAck (35=AQ)
-		g.handleTradeCaptureReportAck(session, msg)
+		g.handleTradeCaptureReportAck(session, fields)
 
 	case MsgTypeTradeCaptureReport: // TradeCaptureReport (35=AE)
-		g.handleTradeCaptureReport(session, msg)
+		g.handleTradeCaptureReport(session, fields)
+
+	case MsgTypeTradingSessionStatus: // TradingSessionStatus (35=h)
+		g.handleTradingSessionStatus(session, fields)
+
+	case MsgTypeSecurityList: // SecurityList (35=y)
+		g.handleSecurityList(session, fields)
+
+	case MsgTypeSecurityDefinition: // SecurityDefinition (35=d)
+		g.handleSecurityDefinition(session, fields)
 
 	case MsgTypeBusinessReject: // BusinessMessageReject (35=j)
-		refMsgType := g.extractTag(msg, "372")
-		reason := g.extractTag(msg, "380")
-		text := g.extractTag(msg, "58")
-		log.Printf("[FIX] BusinessReject from %s: RefMsgType=%s, Reason=%s, Text=%s", session.Name, refMsgType, reason, text)
+		refMsgType := fields.get("372")
+		reason := fields.get("380")
+		text := fields.get("58")
+		logger.Warn("Received BusinessReject", logging.Session(session.ID), logging.Seq(fields.seqNum()), logging.String("ref_msg_type", refMsgType),
+			logging.String("reason", reason), logging.String("text", text))
 
 	default:
-		log.Printf("[FIX] Received message type %s from %s", msgType, session.Name)
+		logger.Debug("Received unhandled message type", logging.Session(session.ID), logging.Seq(fields.seqNum()), logging.String("msg_type", msgType))
 	}
 }
 
 // handleExecutionReport processes incoming execution reports
-func (g *FIXGateway) handleExecutionReport(session *LPSession, msg string) {
+func (g *FIXGateway) handleExecutionReport(session *LPSession, fields fixFields) {
 	report := ExecutionReport{
-		OrderID:   g.extractTag(msg, "37"),
-		Symbol:    g.extractTag(msg, "55"),
-		Side:      g.extractTag(msg, "54"),
-		LPOrderID: g.extractTag(msg, "17"),
-		Text:      g.extractTag(msg, "58"),
+		OrderID:   fields.get("37"),
+		ClOrdID:   fields.get("11"),
+		Symbol:    fields.get("55"),
+		Side:      fields.get("54"),
+		LPOrderID: fields.get("17"),
+		Text:      fields.get("58"),
+		RejReason: fields.get("103"),
 		Timestamp: time.Now(),
 	}
 
-	execType := g.extractTag(msg, "150")
-	switch execType {
-	case "0":
+	execType := fields.get("150")
+	switch {
+	// FIX 4.2 busts (20=1) and corrects (20=2) an earlier fill rather than reporting a new one
+	case session.dialect().ExecTransType && fields.has("20", "1"):
+		report.ExecType = "TRADE_CANCEL"
+	case session.dialect().ExecTransType && fields.has("20", "2"):
+		report.ExecType = "TRADE_CORRECT"
+	case execType == "0":
 		report.ExecType = "NEW"
-	case "F", "2":
+	// 150=1 on FIX 4.2; a trade (150=F) leaving the order partially filled (39=1) on FIX 4.4
+	case execType == "1", execType == "F" && fields.has("39", "1"):
+		report.ExecType = "PARTIAL"
+	case execType == "F", execType == "2":
 		report.ExecType = "FILLED"
-	case "8":
+	case execType == "8":
 		report.ExecType = "REJECTED"
-	case "4":
+	case execType == "4":
 		report.ExecType = "CANCELED"
 	default:
 		report.ExecType = execType
 	}
 
 	// Parse volume and price
-	if qty := g.extractTag(msg, "32"); qty != "" {
+	if qty := fields.get("32"); qty != "" {
 		fmt.Sscanf(qty, "%f", &report.Volume)
 	}
-	if px := g.extractTag(msg, "31"); px != "" {
+	if px := fields.get("31"); px != "" {
 		fmt.Sscanf(px, "%f", &report.Price)
 	}
+	if cum := fields.get("14"); cum != "" {
+		fmt.Sscanf(cum, "%f", &report.CumQty)
+	}
+	if leaves := fields.get("151"); leaves != "" {
+		fmt.Sscanf(leaves, "%f", &report.LeavesQty)
+	}
+	if avg := fields.get("6"); avg != "" {
+		fmt.Sscanf(avg, "%f", &report.AvgPx)
+	}
 
-	log.Printf("[FIX] Execution Report from %s: %s %s %s @ %.5f", session.Name, report.ExecType, report.Side, report.Symbol, report.Price)
+	logger.Info("ExecutionReport received", logging.Session(session.ID), logging.Symbol(report.Symbol), logging.Seq(fields.seqNum()),
+		logging.Latency(fields.sendingLatency(report.Timestamp)), logging.OrderID(report.ClOrdID),
+		logging.String("exec_type", report.ExecType), logging.String("side", report.Side),
+		logging.Float64("volume", report.Volume), logging.Float64("price", report.Price))
+
+	// Nothing downstream can reverse or amend a booked fill, so busts and
+	// corrections are left to manual reconciliation rather than reported
+	if report.ExecType == "TRADE_CANCEL" || report.ExecType == "TRADE_CORRECT" {
+		logger.Error("Fill bust or correction needs manual reconciliation", fmt.Errorf("%s of execution %s", report.ExecType, fields.get("19")),
+			logging.Session(session.ID), logging.OrderID(report.ClOrdID), logging.Symbol(report.Symbol))
+		return
+	}
 	g.execReports <- report
 }
 
-// Disconnect closes a FIX session
+// Disconnect closes a FIX session. Automatic reconnection stays off until the
+// session is connected again.
 func (g *FIXGateway) Disconnect(sessionID string) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
@@ -1475,38 +1625,55 @@ func (g *FIXGateway) Disconnect(sessionID string) error {
 		return fmt.Errorf("session not found: %s", sessionID)
 	}
 
+	session.manualDisconnect = true
+	g.cancelReconnectUnlocked(session)
+	g.disconnectUnlocked(session, "disconnect requested")
+	return nil
+}
+
+// disconnectUnlocked sends Logout and closes the session's connection (caller must hold lock)
+func (g *FIXGateway) disconnectUnlocked(session *LPSession, reason string) {
 	if session.conn != nil {
-		// Send Logout message (35=5) with proper sequence number before closing
-		session.OutSeqNum++
-		msgSeqNum := session.OutSeqNum
-		g.saveSequenceNumbers(session)
+		// A Logout already sent by Shutdown is not repeated
+		if !session.logoutSent {
+			g.sendLogoutUnlocked(session)
+		}
+		session.conn.Close()
+		session.conn = nil
+		session.logoutSent = false
+	}
 
-		sendingTime := time.Now().UTC().Format("20060102-15:04:05.000")
-		body := fmt.Sprintf("35=%s\x01"+
-			"49=%s\x01"+
-			"56=%s\x01"+
-			"34=%d\x01"+
-			"52=%s\x01",
-			MsgTypeLogout,
-			session.SenderCompID,
-			session.TargetCompID,
-			msgSeqNum,
-			sendingTime,
-		)
+	g.setStatusUnlocked(session, "DISCONNECTED", reason)
+	logger.Info("Disconnected", logging.Session(session.ID), logging.String("reason", reason))
+}
 
-		fullMsg := g.buildMessage(session, body)
+// sendLogoutUnlocked sends Logout (35=5) with the next sequence number (caller must hold lock)
+func (g *FIXGateway) sendLogoutUnlocked(session *LPSession) {
+	session.OutSeqNum++
+	msgSeqNum := session.OutSeqNum
+	g.saveSequenceNumbers(session)
+
+	sendingTime := time.Now().UTC().Format("20060102-15:04:05.000")
+	body := fmt.Sprintf("35=%s\x01"+
+		"49=%s\x01"+
+		"56=%s\x01"+
+		"34=%d\x01"+
+		"52=%s\x01",
+		MsgTypeLogout,
+		session.SenderCompID,
+		session.TargetCompID,
+		msgSeqNum,
+		sendingTime,
+	)
 
-		session.conn.SetWriteDeadline(time.Now().Add(2 * time.Second))
-		session.conn.Write([]byte(fullMsg))
-		log.Printf("[FIX] Sent Logout to %s: SeqNum=%d", session.Name, msgSeqNum)
+	fullMsg := g.buildMessage(session, body)
 
-		session.conn.Close()
-		session.conn = nil
+	session.conn.SetWriteDeadline(time.Now().Add(2 * time.Second))
+	if _, err := session.conn.Write([]byte(fullMsg)); err == nil {
+		g.stats.recordSent(session.ID, MsgTypeLogout)
 	}
-
-	session.Status = "DISCONNECTED"
-	log.Printf("[FIX] Disconnected from %s", session.Name)
-	return nil
+	session.logoutSent = true
+	logger.Info("Sent Logout", logging.Session(session.ID), logging.Seq(msgSeqNum))
 }
 
 // SendOrder sends a NewOrderSingle (35=D) to the LP
@@ -1589,14 +1756,13 @@ func (g *FIXGateway) SendOrder(sessionID string, symbol string, side string, vol
 	g.storeMessage(session, msgSeqNum, fullMsg)
 
 	// Send the order
-	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-	_, err := conn.Write([]byte(fullMsg))
+	err := g.writeMessage(session, conn, fullMsg)
 	if err != nil {
 		return "", fmt.Errorf("failed to send order: %v", err)
 	}
 
-	log.Printf("[FIX] Sent NewOrderSingle to %s: ClOrdID=%s, Symbol=%s, Side=%s, Qty=%.2f, Price=%.5f, SeqNum=%d",
-		session.Name, clOrdID, symbol, side, volume, price, msgSeqNum)
+	logger.Info("Sent NewOrderSingle", logging.Session(session.ID), logging.Symbol(symbol), logging.Seq(msgSeqNum),
+		logging.OrderID(clOrdID), logging.String("side", side), logging.Float64("volume", volume), logging.Float64("price", price))
 
 	return clOrdID, nil
 }
@@ -1667,20 +1833,20 @@ func (g *FIXGateway) SendMarketOrder(sessionID string, symbol string, side strin
 	fullMsg := g.buildMessage(session, body)
 	g.storeMessage(session, msgSeqNum, fullMsg)
 
-	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-	_, err := conn.Write([]byte(fullMsg))
+	err := g.writeMessage(session, conn, fullMsg)
 	if err != nil {
 		return "", fmt.Errorf("failed to send market order: %v", err)
 	}
 
-	log.Printf("[FIX] Sent Market Order to %s: ClOrdID=%s, Symbol=%s, Side=%s, Qty=%.2f, SeqNum=%d",
-		session.Name, clOrdID, symbol, side, volume, msgSeqNum)
+	logger.Info("Sent market order", logging.Session(session.ID), logging.Symbol(symbol), logging.Seq(msgSeqNum),
+		logging.OrderID(clOrdID), logging.String("side", side), logging.Float64("volume", volume))
 
 	return clOrdID, nil
 }
 
-// CancelOrder sends an OrderCancelRequest (35=F) to the LP
-func (g *FIXGateway) CancelOrder(sessionID string, origClOrdID string, symbol string, side string) (string, error) {
+// CancelOrder sends an OrderCancelRequest (35=F) to the LP. orderQty is the
+// original order quantity, required by FIX 4.2 and sent whenever it is known.
+func (g *FIXGateway) CancelOrder(sessionID string, origClOrdID string, symbol string, side string, orderQty float64) (string, error) {
 	g.mu.RLock()
 	session, ok := g.sessions[sessionID]
 	g.mu.RUnlock()
@@ -1701,6 +1867,10 @@ func (g *FIXGateway) CancelOrder(sessionID string, origClOrdID string, symbol st
 		return "", fmt.Errorf("connection not available")
 	}
 
+	if orderQty <= 0 && session.dialect().CancelRequiresQty {
+		return "", fmt.Errorf("%s OrderCancelRequest requires the order quantity", session.BeginString)
+	}
+
 	clOrdID := fmt.Sprintf("CXLREQ_%d", time.Now().UnixNano())
 
 	g.mu.Lock()
@@ -1735,18 +1905,20 @@ func (g *FIXGateway) CancelOrder(sessionID string, origClOrdID string, symbol st
 		fixSide,
 		sendingTime,
 	)
+	if orderQty > 0 {
+		body += fmt.Sprintf("38=%.2f\x01", orderQty) // OrderQty
+	}
 
 	fullMsg := g.buildMessage(session, body)
 	g.storeMessage(session, msgSeqNum, fullMsg)
 
-	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-	_, err := conn.Write([]byte(fullMsg))
+	err := g.writeMessage(session, conn, fullMsg)
 	if err != nil {
 		return "", fmt.Errorf("failed to send cancel request: %v", err)
 	}
 
-	log.Printf("[FIX] Sent OrderCancelRequest to %s: ClOrdID=%s, OrigClOrdID=%s, SeqNum=%d",
-		session.Name, clOrdID, origClOrdID, msgSeqNum)
+	logger.Info("Sent OrderCancelRequest", logging.Session(session.ID), logging.Seq(msgSeqNum),
+		logging.OrderID(clOrdID), logging.String("orig_cl_ord_id", origClOrdID))
 
 	return clOrdID, nil
 }
@@ -1776,6 +1948,8 @@ type SessionInfo struct {
 	SenderCompID   string    `json:"senderCompID"`
 	TargetCompID   string    `json:"targetCompID"`
 	TradingAccount string    `json:"tradingAccount"`
+	BeginString    string    `json:"beginString"` // FIX.4.2 or FIX.4.4, set with SetFIXVersion or <ID>_FIX_VERSION
+	HeartBtInt     int       `json:"heartBtInt"`  // Seconds, negotiated once logged in
 }
 
 // GetDetailedStatus returns detailed information about all sessions
@@ -1785,6 +1959,10 @@ func (g *FIXGateway) GetDetailedStatus() map[string]SessionInfo {
 
 	info := make(map[string]SessionInfo)
 	for id, session := range g.sessions {
+		heartbeat := session.heartbeatInterval
+		if heartbeat <= 0 {
+			heartbeat = session.configuredHeartbeat()
+		}
 		info[id] = SessionInfo{
 			ID:             session.ID,
 			Name:           session.Name,
@@ -1797,6 +1975,8 @@ func (g *FIXGateway) GetDetailedStatus() map[string]SessionInfo {
 			SenderCompID:   session.SenderCompID,
 			TargetCompID:   session.TargetCompID,
 			TradingAccount: session.TradingAccount,
+			BeginString:    session.BeginString,
+			HeartBtInt:     heartbeatSeconds(heartbeat),
 		}
 	}
 	return info
@@ -1824,7 +2004,7 @@ func (g *FIXGateway) ResetSessionSequences(sessionID string) error {
 	os.Remove(msgFile)
 
 	g.saveSequenceNumbers(session)
-	log.Printf("[FIX] Manually reset sequence numbers for %s", sessionID)
+	logger.Info("Manually reset sequence numbers", logging.Session(sessionID))
 
 	return nil
 }
@@ -1840,7 +2020,7 @@ func (g *FIXGateway) SetResetSeqNumFlag(sessionID string, reset bool) error {
 	}
 
 	session.ResetSeqNumFlag = reset
-	log.Printf("[FIX] Set ResetSeqNumFlag=%v for %s", reset, sessionID)
+	logger.Info("Set ResetSeqNumFlag", logging.Session(sessionID), logging.Bool("reset", reset))
 
 	return nil
 }
@@ -1850,8 +2030,11 @@ func (g *FIXGateway) GetExecutionReports() <-chan ExecutionReport {
 	return g.execReports
 }
 
-// GetMarketData returns the channel for market data quotes
+// GetMarketData returns the legacy channel for market data quotes. It is fed
+// from the first call on and shared: several readers each get only part of the
+// quotes. New consumers should use Subscribe.
 func (g *FIXGateway) GetMarketData() <-chan MarketData {
+	g.legacyMarketData.Store(true)
 	return g.marketData
 }
 
@@ -1896,8 +2079,8 @@ func (g *FIXGateway) RequestSecurityDefinition(sessionID string, symbol string)
 	// Tag 320 = SecurityReqID (required)
 	// Tag 321 = SecurityRequestType: 0=Request Security identity and specifications
 	// Tag 55  = Symbol
-	// Tag 167 = SecurityType: FXSPOT for forex
-	// Tag 460 = Product: 4=CURRENCY
+	// Tag 167 = SecurityType: FXSPOT for forex (FOR on FIX 4.2)
+	// Tag 460 = Product: 4=CURRENCY (not on FIX 4.2)
 	body := fmt.Sprintf("35=%s\x01"+
 		"49=%s\x01"+
 		"56=%s\x01"+
@@ -1906,8 +2089,7 @@ func (g *FIXGateway) RequestSecurityDefinition(sessionID string, symbol string)
 		"320=%s\x01"+ // SecurityReqID
 		"321=0\x01"+ // SecurityRequestType: 0=Request security identity
 		"55=%s\x01"+ // Symbol
-		"167=FXSPOT\x01"+ // SecurityType
-		"460=4\x01", // Product: CURRENCY
+		"167=%s\x01", // SecurityType
 		MsgTypeSecurityDefinitionReq,
 		session.SenderCompID,
 		session.TargetCompID,
@@ -1915,19 +2097,22 @@ func (g *FIXGateway) RequestSecurityDefinition(sessionID string, symbol string)
 		sendingTime,
 		secReqID,
 		symbol,
+		session.marketDataSecurityType("FXSPOT"),
 	)
+	if session.dialect().ProductTags {
+		body += "460=4\x01" // Product: CURRENCY (FIX 4.3+)
+	}
 
 	fullMsg := g.buildMessage(session, body)
 	g.storeMessage(session, msgSeqNum, fullMsg)
 
-	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-	_, err := conn.Write([]byte(fullMsg))
+	err := g.writeMessage(session, conn, fullMsg)
 	if err != nil {
 		return "", fmt.Errorf("failed to send security definition request: %v", err)
 	}
 
-	log.Printf("[FIX] Sent SecurityDefinitionRequest to %s: SecurityReqID=%s, Symbol=%s, SeqNum=%d",
-		session.Name, secReqID, symbol, msgSeqNum)
+	logger.Info("Sent SecurityDefinitionRequest", logging.Session(session.ID), logging.Symbol(symbol), logging.Seq(msgSeqNum),
+		logging.String("security_req_id", secReqID))
 
 	// Wait briefly for response before allowing MarketDataRequest
 	time.Sleep(500 * time.Millisecond)
@@ -1935,9 +2120,55 @@ func (g *FIXGateway) RequestSecurityDefinition(sessionID string, symbol string)
 	return secReqID, nil
 }
 
+// MarketDataOptions controls the optional tags of a MarketDataRequest (35=V)
+type MarketDataOptions struct {
+	MarketDepth      int    // 264: 0=Full book, 1=Top of book, N=N levels
+	SubscriptionType int    // 263: 0=Snapshot, 1=Snapshot+Updates
+	UpdateType       *int   // 265: 0=Full refresh, 1=Incremental; nil omits the tag
+	SecurityType     string // 167: empty omits the tag
+}
+
+// DefaultMarketDataOptions returns the options SubscribeMarketData uses: full
+// book streaming with no MDUpdateType, which YOFX rejects
+func DefaultMarketDataOptions() MarketDataOptions {
+	return MarketDataOptions{
+		MarketDepth:      0,
+		SubscriptionType: 1,
+		SecurityType:     "FXSPOT",
+	}
+}
+
+// validate rejects option values that are not valid MarketDataRequest tags
+func (o MarketDataOptions) validate() error {
+	if o.MarketDepth < 0 {
+		return fmt.Errorf("invalid MarketDepth %d: must be 0 or greater", o.MarketDepth)
+	}
+	if o.SubscriptionType != 0 && o.SubscriptionType != 1 {
+		return fmt.Errorf("invalid SubscriptionType %d: must be 0 (snapshot) or 1 (snapshot+updates); use UnsubscribeMarketData to unsubscribe", o.SubscriptionType)
+	}
+	if o.UpdateType != nil && *o.UpdateType != 0 && *o.UpdateType != 1 {
+		return fmt.Errorf("invalid UpdateType %d: must be 0 (full refresh) or 1 (incremental)", *o.UpdateType)
+	}
+	if strings.ContainsRune(o.SecurityType, '\x01') {
+		return fmt.Errorf("invalid SecurityType %q: contains SOH", o.SecurityType)
+	}
+	return nil
+}
+
 // SubscribeMarketData subscribes to real-time quotes for a symbol (35=V)
 // IMPORTANT: Call RequestSecurityDefinition first for better FIX 4.4 compliance
 func (g *FIXGateway) SubscribeMarketData(sessionID string, symbol string) (string, error) {
+	return g.SubscribeMarketDataWithOptions(sessionID, symbol, DefaultMarketDataOptions())
+}
+
+// SubscribeMarketDataWithOptions subscribes to quotes for a symbol (35=V) with
+// explicit depth, subscription type, update type and security type, for LPs
+// that need different values than the defaults
+func (g *FIXGateway) SubscribeMarketDataWithOptions(sessionID string, symbol string, opts MarketDataOptions) (string, error) {
+	if err := opts.validate(); err != nil {
+		return "", err
+	}
+
 	g.mu.RLock()
 	session, ok := g.sessions[sessionID]
 	g.mu.RUnlock()
@@ -1965,13 +2196,18 @@ func (g *FIXGateway) SubscribeMarketData(sessionID string, symbol string) (strin
 	g.mu.Lock()
 	g.mdSubscriptions[mdReqID] = symbol
 	g.symbolSubscriptions[symbol] = mdReqID
+	g.mdRequests[mdReqID] = mdRequest{sessionID: sessionID, options: opts}
+	if !g.savedSubscriptions[sessionID][symbol] {
+		g.addSavedSubscriptionUnlocked(sessionID, symbol)
+		g.saveSubscriptionsUnlocked(session)
+	}
 	msgSeqNum := g.getNextOutSeqNum(session)
 	g.mu.Unlock()
 
 	sendingTime := time.Now().UTC().Format("20060102-15:04:05.000")
 
 	// Build Market Data Request (35=V) - FIX 4.4 FULL format with required tags
-	// YOFX Key findings:
+	// YOFX Key findings (the defaults):
 	// - NO Account tag (1) - causes rejection
 	// - NO EUR/USD format - causes "Unknown symbol" rejection
 	// - NO MDUpdateType (265) - causes rejection ("Unsupported MDUpdateType '1'")
@@ -1980,6 +2216,20 @@ func (g *FIXGateway) SubscribeMarketData(sessionID string, symbol string) (strin
 	// - ADDED SecurityExchange (207) - Required: Exchange identifier
 	// - ADDED Currency (15) - Quote currency (USD for EURUSD)
 	// - MarketDepth (264) = 0 (Full book)
+	updateTypeTag := ""
+	if opts.UpdateType != nil {
+		updateTypeTag = fmt.Sprintf("265=%d\x01", *opts.UpdateType) // MDUpdateType
+	}
+	securityTypeTag := ""
+	if opts.SecurityType != "" {
+		securityTypeTag = fmt.Sprintf("167=%s\x01", session.marketDataSecurityType(opts.SecurityType)) // SecurityType, e.g. FXSPOT
+	}
+	// Product (460) was added in FIX 4.3, and the FIX 4.2 NoRelatedSym group
+	// has no Currency, so 4.2 sessions send neither
+	productTag, currencyTag := "", ""
+	if session.dialect().ProductTags {
+		productTag, currencyTag = "460=4\x01", "15=USD\x01" // Product: 4=CURRENCY (FX spot), Currency: quote currency
+	}
 
 	body := fmt.Sprintf("35=%s\x01"+
 		"49=%s\x01"+
@@ -1987,37 +2237,43 @@ func (g *FIXGateway) SubscribeMarketData(sessionID string, symbol string) (strin
 		"34=%d\x01"+
 		"52=%s\x01"+
 		"262=%s\x01"+ // MDReqID
-		"263=1\x01"+ // SubscriptionRequestType: 1=Snapshot+Updates (streaming)
-		"264=0\x01"+ // MarketDepth: 0=Full book
+		"263=%d\x01"+ // SubscriptionRequestType: 0=Snapshot, 1=Snapshot+Updates (streaming)
+		"264=%d\x01"+ // MarketDepth: 0=Full book
+		"%s"+ // MDUpdateType (optional)
 		"267=2\x01"+ // NoMDEntryTypes: 2 (Bid and Offer)
 		"269=0\x01"+ // MDEntryType: 0=Bid
 		"269=1\x01"+ // MDEntryType: 1=Offer
 		"146=1\x01"+ // NoRelatedSym: 1
 		"55=%s\x01"+ // Symbol (EURUSD format)
-		"460=4\x01"+ // Product: 4=CURRENCY (FX spot)
-		"167=FXSPOT\x01"+ // SecurityType: FXSPOT for forex pairs
+		"%s"+ // Product (FIX 4.3+)
+		"%s"+ // SecurityType (optional)
 		"207=YOFX\x01"+ // SecurityExchange: YOFX exchange identifier
-		"15=USD\x01", // Currency: Quote currency (second currency in pair)
+		"%s", // Currency (FIX 4.3+)
 		MsgTypeMarketDataRequest,
 		session.SenderCompID,
 		session.TargetCompID,
 		msgSeqNum,
 		sendingTime,
 		mdReqID,
+		opts.SubscriptionType,
+		opts.MarketDepth,
+		updateTypeTag,
 		symbol,
+		productTag,
+		securityTypeTag,
+		currencyTag,
 	)
 
 	fullMsg := g.buildMessage(session, body)
 	g.storeMessage(session, msgSeqNum, fullMsg)
 
-	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-	_, err := conn.Write([]byte(fullMsg))
+	err := g.writeMessage(session, conn, fullMsg)
 	if err != nil {
 		return "", fmt.Errorf("failed to send market data request: %v", err)
 	}
 
-	log.Printf("[FIX] Sent MarketDataRequest to %s: MDReqID=%s, Symbol=%s, SeqNum=%d",
-		session.Name, mdReqID, symbol, msgSeqNum)
+	logger.Info("Sent MarketDataRequest", logging.Session(session.ID), logging.Symbol(symbol), logging.Seq(msgSeqNum),
+		logging.String("md_req_id", mdReqID))
 
 	return mdReqID, nil
 }
@@ -2066,8 +2322,11 @@ func (g *FIXGateway) UnsubscribeMarketData(sessionID string, mdReqID string) err
 
 	g.mu.Lock()
 	delete(g.mdSubscriptions, mdReqID)
+	delete(g.mdRequests, mdReqID)
 	if symbol != "" {
 		delete(g.symbolSubscriptions, symbol)
+		delete(g.savedSubscriptions[sessionID], symbol)
+		g.saveSubscriptionsUnlocked(session)
 	}
 	msgSeqNum := g.getNextOutSeqNum(session)
 	g.mu.Unlock()
@@ -2099,14 +2358,12 @@ func (g *FIXGateway) UnsubscribeMarketData(sessionID string, mdReqID string) err
 
 	fullMsg := g.buildMessage(session, body)
 
-	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-	_, err := conn.Write([]byte(fullMsg))
+	err := g.writeMessage(session, conn, fullMsg)
 	if err != nil {
 		return fmt.Errorf("failed to send unsubscribe request: %v", err)
 	}
 
-	log.Printf("[FIX] Sent MarketData Unsubscribe to %s: MDReqID=%s, Symbol=%s",
-		session.Name, mdReqID, symbol)
+	logger.Info("Sent MarketData unsubscribe", logging.Session(session.ID), logging.Symbol(symbol), logging.String("md_req_id", mdReqID))
 
 	return nil
 }
@@ -2176,22 +2433,20 @@ func (g *FIXGateway) RequestSecurityList(sessionID string) (string, error) {
 	fullMsg := g.buildMessage(session, body)
 	g.storeMessage(session, msgSeqNum, fullMsg)
 
-	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-	_, err := conn.Write([]byte(fullMsg))
+	err := g.writeMessage(session, conn, fullMsg)
 	if err != nil {
 		return "", fmt.Errorf("failed to send security list request: %v", err)
 	}
 
-	log.Printf("[FIX] Sent SecurityListRequest to %s: SecurityReqID=%s, SeqNum=%d",
-		session.Name, securityReqID, msgSeqNum)
+	logger.Info("Sent SecurityListRequest", logging.Session(session.ID), logging.Seq(msgSeqNum), logging.String("security_req_id", securityReqID))
 
 	return securityReqID, nil
 }
 
 // handleMarketDataSnapshot processes incoming market data (35=W)
-func (g *FIXGateway) handleMarketDataSnapshot(session *LPSession, msg string) {
-	symbol := g.extractTag(msg, "55")
-	mdReqID := g.extractTag(msg, "262")
+func (g *FIXGateway) handleMarketDataSnapshot(session *LPSession, fields fixFields) {
+	symbol := fields.get("55")
+	mdReqID := fields.get("262")
 
 	md := MarketData{
 		Symbol:    symbol,
@@ -2202,28 +2457,26 @@ func (g *FIXGateway) handleMarketDataSnapshot(session *LPSession, msg string) {
 
 	// Parse NoMDEntries (268) and extract bid/ask
 	// Format: 268=N, then N entries with 269 (type), 270 (price), 271 (size)
-	numEntries := g.extractTag(msg, "268")
+	numEntries := fields.get("268")
 	if numEntries == "" {
 		numEntries = "2" // Default to 2 (bid+ask)
 	}
 
 	// Find all 269/270/271 pairs
-	parts := strings.Split(msg, "\x01")
 	var currentType string
-	for _, part := range parts {
-		if strings.HasPrefix(part, "269=") {
-			currentType = part[4:]
-		} else if strings.HasPrefix(part, "270=") {
-			price := 0.0
-			fmt.Sscanf(part[4:], "%f", &price)
+	for _, field := range fields.ordered {
+		switch field.tag {
+		case "269":
+			currentType = field.value
+		case "270":
+			price := parseFIXFloat(field.value)
 			if currentType == "0" { // Bid
 				md.Bid = price
 			} else if currentType == "1" { // Offer/Ask
 				md.Ask = price
 			}
-		} else if strings.HasPrefix(part, "271=") {
-			size := 0.0
-			fmt.Sscanf(part[4:], "%f", &size)
+		case "271":
+			size := parseFIXFloat(field.value)
 			if currentType == "0" {
 				md.BidSize = size
 			} else if currentType == "1" {
@@ -2233,48 +2486,43 @@ func (g *FIXGateway) handleMarketDataSnapshot(session *LPSession, msg string) {
 	}
 
 	// Update quote cache with snapshot data
-	g.quoteCacheMu.Lock()
-	g.quoteCache[symbol] = &MarketData{
-		Symbol:    symbol,
-		Bid:       md.Bid,
-		Ask:       md.Ask,
-		BidSize:   md.BidSize,
-		AskSize:   md.AskSize,
-		SessionID: session.ID,
-		Timestamp: md.Timestamp,
-	}
-	g.quoteCacheMu.Unlock()
+	g.cacheSnapshotQuote(md)
 
-	log.Printf("[FIX] MarketData from %s: %s Bid=%.5f Ask=%.5f",
-		session.Name, symbol, md.Bid, md.Ask)
+	logger.Debug("MarketData snapshot", logging.Session(session.ID), logging.Symbol(symbol), logging.Seq(fields.seqNum()),
+		logging.Latency(fields.sendingLatency(time.Now())), logging.Float64("bid", md.Bid), logging.Float64("ask", md.Ask))
 
-	// Send to channel (non-blocking)
-	select {
-	case g.marketData <- md:
-	default:
-		log.Printf("[FIX] MarketData channel full, dropping quote for %s", symbol)
-	}
+	// Fan out to consumers (non-blocking)
+	g.publishMarketData(md)
 }
 
 // handleMarketDataReject processes market data request reject (35=Y)
-func (g *FIXGateway) handleMarketDataReject(session *LPSession, msg string) {
-	mdReqID := g.extractTag(msg, "262")
-	reason := g.extractTag(msg, "281")
-	text := g.extractTag(msg, "58")
+func (g *FIXGateway) handleMarketDataReject(session *LPSession, fields fixFields) {
+	mdReqID := fields.get("262")
+	reason := fields.get("281")
+	text := fields.get("58")
+
+	reasonText := mdRejectReasonText(reason)
 
-	log.Printf("[FIX] MarketDataReject from %s: MDReqID=%s, Reason=%s, Text=%s",
-		session.Name, mdReqID, reason, text)
+	logger.Warn("MarketDataReject received", logging.Session(session.ID), logging.String("md_req_id", mdReqID),
+		logging.String("reason", reason), logging.String("reason_text", reasonText), logging.String("text", text))
 
 	reject := MarketDataReject{
-		MDReqID:   mdReqID,
-		Reason:    reason,
-		Text:      text,
-		SessionID: session.ID,
+		MDReqID:    mdReqID,
+		Reason:     reason,
+		ReasonText: reasonText,
+		Text:       text,
+		SessionID:  session.ID,
 	}
 
-	// Remove from subscriptions
+	// Remove from subscriptions. A rejected symbol (e.g. delisted) is not
+	// persisted, so it is not requested again after a restart.
 	g.mu.Lock()
+	if symbol, ok := g.mdSubscriptions[mdReqID]; ok && g.savedSubscriptions[session.ID][symbol] {
+		delete(g.savedSubscriptions[session.ID], symbol)
+		g.saveSubscriptionsUnlocked(session)
+	}
 	delete(g.mdSubscriptions, mdReqID)
+	delete(g.mdRequests, mdReqID)
 	g.mu.Unlock()
 
 	select {
@@ -2283,6 +2531,44 @@ func (g *FIXGateway) handleMarketDataReject(session *LPSession, msg string) {
 	}
 }
 
+// mdRejectReasonText maps an MDReqRejReason (281) code to its meaning
+func mdRejectReasonText(code string) string {
+	switch code {
+	case "":
+		return "No reason given"
+	case "0":
+		return "Unknown symbol"
+	case "1":
+		return "Duplicate MDReqID"
+	case "2":
+		return "Insufficient bandwidth"
+	case "3":
+		return "Insufficient permissions"
+	case "4":
+		return "Unsupported SubscriptionRequestType"
+	case "5":
+		return "Unsupported MarketDepth"
+	case "6":
+		return "Unsupported MDUpdateType"
+	case "7":
+		return "Unsupported AggregatedBook"
+	case "8":
+		return "Unsupported MDEntryType"
+	case "9":
+		return "Unsupported TradingSessionID"
+	case "A":
+		return "Unsupported Scope"
+	case "B":
+		return "Unsupported OpenCloseSettlFlag"
+	case "C":
+		return "Unsupported MDImplicitDelete"
+	case "D":
+		return "Insufficient credit"
+	default:
+		return "Unrecognized reason " + code
+	}
+}
+
 // ============================================================================
 // POSITION MANAGEMENT (35=AN, 35=AP)
 // ============================================================================
@@ -2367,30 +2653,29 @@ func (g *FIXGateway) RequestPositions(sessionID string, symbol string) (string,
 	fullMsg := g.buildMessage(session, body)
 	g.storeMessage(session, msgSeqNum, fullMsg)
 
-	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-	_, err := conn.Write([]byte(fullMsg))
+	err := g.writeMessage(session, conn, fullMsg)
 	if err != nil {
 		return "", fmt.Errorf("failed to send position request: %v", err)
 	}
 
-	log.Printf("[FIX] Sent RequestForPositions to %s: PosReqID=%s, Account=%s, Symbol=%s",
-		session.Name, posReqID, session.TradingAccount, symbol)
+	logger.Info("Sent RequestForPositions", logging.Session(session.ID), logging.Symbol(symbol), logging.Seq(msgSeqNum),
+		logging.String("pos_req_id", posReqID), logging.String("account", session.TradingAccount))
 
 	return posReqID, nil
 }
 
 // handlePositionReport processes incoming position reports (35=AP)
-func (g *FIXGateway) handlePositionReport(session *LPSession, msg string) {
+func (g *FIXGateway) handlePositionReport(session *LPSession, fields fixFields) {
 	now := time.Now()
 
-	posReqID := g.extractTag(msg, "710")
-	result := g.extractTag(msg, "728")
-	symbol := g.extractTag(msg, "55")
-	account := g.extractTag(msg, "1")
+	posReqID := fields.get("710")
+	result := fields.get("728")
+	symbol := fields.get("55")
+	account := fields.get("1")
 
 	// Check if no positions found (728=2)
 	if result == "2" {
-		log.Printf("[FIX] PositionReport from %s: No positions found (PosReqID=%s)", session.Name, posReqID)
+		logger.Info("PositionReport: no positions found", logging.Session(session.ID), logging.String("pos_req_id", posReqID))
 		return
 	}
 
@@ -2404,9 +2689,9 @@ func (g *FIXGateway) handlePositionReport(session *LPSession, msg string) {
 	}
 
 	// Parse position quantities
-	longQty := g.extractTag(msg, "704")
-	shortQty := g.extractTag(msg, "705")
-	settlPrice := g.extractTag(msg, "730")
+	longQty := fields.get("704")
+	shortQty := fields.get("705")
+	settlPrice := fields.get("730")
 
 	if longQty != "" && longQty != "0" {
 		fmt.Sscanf(longQty, "%f", &pos.Volume)
@@ -2420,13 +2705,13 @@ func (g *FIXGateway) handlePositionReport(session *LPSession, msg string) {
 		fmt.Sscanf(settlPrice, "%f", &pos.EntryPrice)
 	}
 
-	log.Printf("[FIX] PositionReport from %s: %s %s %.2f @ %.5f (PosReqID=%s)",
-		session.Name, pos.Side, pos.Symbol, pos.Volume, pos.EntryPrice, posReqID)
+	logger.Info("PositionReport received", logging.Session(session.ID), logging.Symbol(pos.Symbol), logging.String("side", pos.Side),
+		logging.Float64("volume", pos.Volume), logging.Float64("entry_price", pos.EntryPrice), logging.String("pos_req_id", posReqID))
 
 	select {
 	case g.positions <- pos:
 	default:
-		log.Printf("[FIX] Position channel full, dropping position for %s", symbol)
+		logger.Warn("Position channel full, dropping position", logging.Session(session.ID), logging.Symbol(symbol))
 	}
 }
 
@@ -2491,14 +2776,12 @@ func (g *FIXGateway) RequestOrderStatus(sessionID string, clOrdID string, symbol
 	fullMsg := g.buildMessage(session, body)
 	g.storeMessage(session, msgSeqNum, fullMsg)
 
-	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-	_, err := conn.Write([]byte(fullMsg))
+	err := g.writeMessage(session, conn, fullMsg)
 	if err != nil {
 		return fmt.Errorf("failed to send order status request: %v", err)
 	}
 
-	log.Printf("[FIX] Sent OrderStatusRequest to %s: ClOrdID=%s, Symbol=%s",
-		session.Name, clOrdID, symbol)
+	logger.Info("Sent OrderStatusRequest", logging.Session(session.ID), logging.Symbol(symbol), logging.Seq(msgSeqNum), logging.OrderID(clOrdID))
 
 	return nil
 }
@@ -2554,14 +2837,12 @@ func (g *FIXGateway) RequestAllOrderStatus(sessionID string) (string, error) {
 	fullMsg := g.buildMessage(session, body)
 	g.storeMessage(session, msgSeqNum, fullMsg)
 
-	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-	_, err := conn.Write([]byte(fullMsg))
+	err := g.writeMessage(session, conn, fullMsg)
 	if err != nil {
 		return "", fmt.Errorf("failed to send mass status request: %v", err)
 	}
 
-	log.Printf("[FIX] Sent OrderMassStatusRequest to %s: MassStatusReqID=%s",
-		session.Name, massStatusReqID)
+	logger.Info("Sent OrderMassStatusRequest", logging.Session(session.ID), logging.Seq(msgSeqNum), logging.String("mass_status_req_id", massStatusReqID))
 
 	return massStatusReqID, nil
 }
@@ -2629,52 +2910,52 @@ func (g *FIXGateway) RequestTradeHistory(sessionID string, startTime, endTime ti
 	fullMsg := g.buildMessage(session, body)
 	g.storeMessage(session, msgSeqNum, fullMsg)
 
-	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-	_, err := conn.Write([]byte(fullMsg))
+	err := g.writeMessage(session, conn, fullMsg)
 	if err != nil {
 		return "", fmt.Errorf("failed to send trade history request: %v", err)
 	}
 
-	log.Printf("[FIX] Sent TradeCaptureReportRequest to %s: TradeReqID=%s, From=%s, To=%s",
-		session.Name, tradeReqID, startTimeStr, endTimeStr)
+	logger.Info("Sent TradeCaptureReportRequest", logging.Session(session.ID), logging.Seq(msgSeqNum), logging.String("trade_req_id", tradeReqID),
+		logging.String("from", startTimeStr), logging.String("to", endTimeStr))
 
 	return tradeReqID, nil
 }
 
 // handleTradeCaptureReportAck handles trade capture request acknowledgment (35=AQ)
-func (g *FIXGateway) handleTradeCaptureReportAck(session *LPSession, msg string) {
-	tradeReqID := g.extractTag(msg, "568")
-	result := g.extractTag(msg, "749")
-	status := g.extractTag(msg, "750")
-	totalReports := g.extractTag(msg, "748")
-	text := g.extractTag(msg, "58")
-
-	log.Printf("[FIX] TradeCaptureReportAck from %s: TradeReqID=%s, Result=%s, Status=%s, TotalReports=%s, Text=%s",
-		session.Name, tradeReqID, result, status, totalReports, text)
-
+func (g *FIXGateway) handleTradeCaptureReportAck(session *LPSession, fields fixFields) {
+	tradeReqID := fields.get("568")
+	result := fields.get("749")
+	status := fields.get("750")
+	totalReports := fields.get("748")
+	text := fields.get("58")
+
+	ackFields := []logging.Field{logging.Session(session.ID), logging.String("trade_req_id", tradeReqID), logging.String("result", result),
+		logging.String("status", status), logging.String("total_reports", totalReports), logging.String("text", text)}
 	if result == "99" || status == "2" {
-		log.Printf("[FIX] Trade request rejected: %s", text)
+		logger.Warn("Trade request rejected", ackFields...)
+	} else {
+		logger.Info("TradeCaptureReportAck received", ackFields...)
 	}
 }
 
 // handleTradeCaptureReport handles individual trade reports (35=AE)
-func (g *FIXGateway) handleTradeCaptureReport(session *LPSession, msg string) {
+func (g *FIXGateway) handleTradeCaptureReport(session *LPSession, fields fixFields) {
 	now := time.Now()
 
 	trade := TradeCapture{
-		TradeID:      g.extractTag(msg, "17"), // ExecID
-		OrderID:      g.extractTag(msg, "37"), // OrderID
-		ClOrdID:      g.extractTag(msg, "11"), // ClOrdID
-		Symbol:       g.extractTag(msg, "55"),
-		Account:      g.extractTag(msg, "1"),
-		TradeDate:    g.extractTag(msg, "75"),
+		TradeID:      fields.get("17"), // ExecID
+		OrderID:      fields.get("37"), // OrderID
+		ClOrdID:      fields.get("11"), // ClOrdID
+		Symbol:       fields.get("55"),
+		Account:      fields.get("1"),
+		TradeDate:    fields.get("75"),
 		SessionID:    session.ID,
 		TransactTime: now,
 		TimestampMs:  now.UnixMilli(),
 	}
 
 	// Parse side
-	side := g.extractTag(msg, "54")
+	side := fields.get("54")
 	if side == "1" {
 		trade.Side = "BUY"
 	} else if side == "2" {
@@ -2682,15 +2963,15 @@ func (g *FIXGateway) handleTradeCaptureReport(session *LPSession, msg string) {
 	}
 
 	// Parse quantity and price
-	if qty := g.extractTag(msg, "32"); qty != "" {
+	if qty := fields.get("32"); qty != "" {
 		fmt.Sscanf(qty, "%f", &trade.Volume)
 	}
-	if px := g.extractTag(msg, "31"); px != "" {
+	if px := fields.get("31"); px != "" {
 		fmt.Sscanf(px, "%f", &trade.Price)
 	}
 
 	// Parse transaction time
-	if transactTime := g.extractTag(msg, "60"); transactTime != "" {
+	if transactTime := fields.get("60"); transactTime != "" {
 		if t, err := time.Parse("20060102-15:04:05.000", transactTime); err == nil {
 			trade.TransactTime = t
 		} else if t, err := time.Parse("20060102-15:04:05", transactTime); err == nil {
@@ -2698,124 +2979,78 @@ func (g *FIXGateway) handleTradeCaptureReport(session *LPSession, msg string) {
 		}
 	}
 
-	log.Printf("[FIX] TradeCaptureReport from %s: %s %s %.2f @ %.5f (TradeID=%s, Date=%s)",
-		session.Name, trade.Side, trade.Symbol, trade.Volume, trade.Price, trade.TradeID, trade.TradeDate)
+	logger.Info("TradeCaptureReport received", logging.Session(session.ID), logging.Symbol(trade.Symbol), logging.TradeID(trade.TradeID),
+		logging.String("side", trade.Side), logging.Float64("volume", trade.Volume), logging.Float64("price", trade.Price),
+		logging.String("trade_date", trade.TradeDate))
 
 	select {
 	case g.trades <- trade:
 	default:
-		log.Printf("[FIX] Trade channel full, dropping trade %s", trade.TradeID)
+		logger.Warn("Trade channel full, dropping trade", logging.Session(session.ID), logging.TradeID(trade.TradeID))
 	}
 }
 
 // handleOrderCancelReject handles order cancel rejections (35=9)
-func (g *FIXGateway) handleOrderCancelReject(session *LPSession, msg string) {
-	clOrdID := g.extractTag(msg, "11")
-	origClOrdID := g.extractTag(msg, "41")
-	ordStatus := g.extractTag(msg, "39")
-	cxlRejReason := g.extractTag(msg, "102")
-	text := g.extractTag(msg, "58")
+func (g *FIXGateway) handleOrderCancelReject(session *LPSession, fields fixFields) {
+	clOrdID := fields.get("11")
+	origClOrdID := fields.get("41")
+	ordStatus := fields.get("39")
+	cxlRejReason := fields.get("102")
+	text := fields.get("58")
 
-	log.Printf("[FIX] OrderCancelReject from %s: ClOrdID=%s, OrigClOrdID=%s, OrdStatus=%s, Reason=%s, Text=%s",
-		session.Name, clOrdID, origClOrdID, ordStatus, cxlRejReason, text)
+	logger.Warn("OrderCancelReject received", logging.Session(session.ID), logging.OrderID(clOrdID),
+		logging.String("orig_cl_ord_id", origClOrdID), logging.String("ord_status", ordStatus),
+		logging.String("reason", cxlRejReason), logging.String("text", text))
 }
 
 // handleRequestForPositionsAck handles position request acknowledgment (35=AO)
-func (g *FIXGateway) handleRequestForPositionsAck(session *LPSession, msg string) {
-	posReqID := g.extractTag(msg, "710")
-	result := g.extractTag(msg, "728")
-	status := g.extractTag(msg, "729")
-	totalReports := g.extractTag(msg, "727")
-	text := g.extractTag(msg, "58")
-
-	log.Printf("[FIX] RequestForPositionsAck from %s: PosReqID=%s, Result=%s, Status=%s, TotalReports=%s, Text=%s",
-		session.Name, posReqID, result, status, totalReports, text)
-
-	if result == "2" {
-		log.Printf("[FIX] No positions found for PosReqID=%s", posReqID)
-	} else if result == "1" || status == "2" {
-		log.Printf("[FIX] Position request rejected: %s", text)
+func (g *FIXGateway) handleRequestForPositionsAck(session *LPSession, fields fixFields) {
+	posReqID := fields.get("710")
+	result := fields.get("728")
+	status := fields.get("729")
+	totalReports := fields.get("727")
+	text := fields.get("58")
+
+	ackFields := []logging.Field{logging.Session(session.ID), logging.String("pos_req_id", posReqID), logging.String("result", result),
+		logging.String("status", status), logging.String("total_reports", totalReports), logging.String("text", text)}
+	if result == "1" || status == "2" {
+		logger.Warn("Position request rejected", ackFields...)
+	} else {
+		logger.Info("RequestForPositionsAck received", ackFields...)
 	}
 }
 
 // handleMarketDataIncremental handles incremental market data updates (35=X)
 // Merges with cached quotes to preserve bid when only ask updates (and vice versa)
-func (g *FIXGateway) handleMarketDataIncremental(session *LPSession, msg string) {
+func (g *FIXGateway) handleMarketDataIncremental(session *LPSession, fields fixFields) {
 	now := time.Now()
 
 	// Parse incremental updates
 	// Format: 268=N entries with 279 (action), 269 (type), 270 (price), 271 (size), 55 (symbol)
-	parts := strings.Split(msg, "\x01")
-
 	var currentSymbol, currentType string
 	var currentAction string // 0=New, 1=Change, 2=Delete
 	var currentPrice, currentSize float64
 
-	for _, part := range parts {
-		if strings.HasPrefix(part, "55=") {
-			currentSymbol = part[3:]
-		} else if strings.HasPrefix(part, "279=") {
-			currentAction = part[4:] // 0=New, 1=Change, 2=Delete
-		} else if strings.HasPrefix(part, "269=") {
-			currentType = part[4:] // 0=Bid, 1=Offer
-		} else if strings.HasPrefix(part, "270=") {
-			fmt.Sscanf(part[4:], "%f", &currentPrice)
-		} else if strings.HasPrefix(part, "271=") {
-			fmt.Sscanf(part[4:], "%f", &currentSize)
+	for _, field := range fields.ordered {
+		switch field.tag {
+		case "55":
+			currentSymbol = field.value
+		case "279":
+			currentAction = field.value // 0=New, 1=Change, 2=Delete
+		case "269":
+			currentType = field.value // 0=Bid, 1=Offer
+		case "270":
+			currentPrice = parseFIXFloat(field.value)
+		case "271":
+			currentSize = parseFIXFloat(field.value)
 
 			// When we have size, we have a complete entry
 			// Skip delete actions (279=2)
 			if currentSymbol != "" && currentPrice > 0 && currentAction != "2" {
-				// Get cached quote to merge with incremental update
-				g.quoteCacheMu.RLock()
-				cached := g.quoteCache[currentSymbol]
-				g.quoteCacheMu.RUnlock()
-
-				// Start with cached values or zeros
-				var bid, ask, bidSize, askSize float64
-				if cached != nil {
-					bid = cached.Bid
-					ask = cached.Ask
-					bidSize = cached.BidSize
-					askSize = cached.AskSize
-				}
-
-				// Apply incremental update
-				if currentType == "0" { // Bid update
-					bid = currentPrice
-					bidSize = currentSize
-				} else if currentType == "1" { // Ask update
-					ask = currentPrice
-					askSize = currentSize
-				}
-
-				// Create merged market data
-				md := MarketData{
-					Symbol:    currentSymbol,
-					SessionID: session.ID,
-					Timestamp: now,
-					Bid:       bid,
-					Ask:       ask,
-					BidSize:   bidSize,
-					AskSize:   askSize,
-				}
-
-				// Update cache with merged data
-				g.quoteCacheMu.Lock()
-				g.quoteCache[currentSymbol] = &MarketData{
-					Symbol:    currentSymbol,
-					Bid:       bid,
-					Ask:       ask,
-					BidSize:   bidSize,
-					AskSize:   askSize,
-					SessionID: session.ID,
-					Timestamp: now,
-				}
-				g.quoteCacheMu.Unlock()
-
-				select {
-				case g.marketData <- md:
-				default:
+				// Merge with the cached other side, unless it has gone stale.
+				// A quote missing a side is held back rather than published.
+				if md, ok := g.mergeIncrementalQuote(currentSymbol, session.ID, currentType, currentPrice, currentSize, now); ok {
+					g.publishMarketData(md)
 				}
 			}
 		}
@@ -0,0 +1,64 @@
+package fix
+
+import "testing"
+
+// TestContainsTag tests that tag=value matches only whole fields
+func TestContainsTag(t *testing.T) {
+	gw, session := newTestGateway(t)
+	logon := inbound(gw, session, MsgTypeLogon, 1, "98=0\x01108=30\x01")
+
+	tests := []struct {
+		name  string
+		msg   string
+		tag   string
+		value string
+		want  bool
+	}{
+		{"first field", logon, "8", "FIX.4.4", true},
+		{"first field wrong value", logon, "8", "FIX.4.2", false},
+		{"header field", logon, "35", MsgTypeLogon, true},
+		{"last field before checksum", logon, "108", "30", true},
+		{"checksum", logon, "10", gw.extractTag(logon, "10"), true},
+		{"value prefix only", logon, "108", "3", false},
+		{"135 substring trap", "8=FIX.4.4\x019=10\x01135=A\x0135=0\x01", "35", "A", false},
+		{"8 inside 108", "35=A\x01108=FIX.4.4\x01", "8", "FIX.4.4", false},
+		{"tag suffix trap", "8=FIX.4.4\x0135=0\x0143=Y\x01", "3", "Y", false},
+		{"missing trailing SOH", "8=FIX.4.4\x0135=0\x0143=Y", "43", "Y", true},
+		{"single field", "141=Y", "141", "Y", true},
+		{"empty message", "", "35", "A", false},
+	}
+	for _, tt := range tests {
+		if got := gw.containsTag(tt.msg, tt.tag, tt.value); got != tt.want {
+			t.Errorf("%s: containsTag(%q, %s=%s) = %v, want %v", tt.name, tt.msg, tt.tag, tt.value, got, tt.want)
+		}
+	}
+}
+
+// TestMarketDataRejectReasonText tests that a reject carries both the raw
+// MDReqRejReason code and its meaning
+func TestMarketDataRejectReasonText(t *testing.T) {
+	gw, session := newTestGateway(t)
+	gw.processMessage(session, session.conn, inbound(gw, session, MsgTypeMarketDataReject, 1,
+		"262=MD1\x01281=1\x0158=Already subscribed\x01"))
+
+	select {
+	case reject := <-gw.GetMarketDataRejects():
+		if reject.Reason != "1" || reject.ReasonText != "Duplicate MDReqID" || reject.Text != "Already subscribed" {
+			t.Errorf("reject = %+v, want code 1 (Duplicate MDReqID)", reject)
+		}
+	default:
+		t.Fatal("no MarketDataReject delivered")
+	}
+
+	for code, want := range map[string]string{
+		"0": "Unknown symbol",
+		"6": "Unsupported MDUpdateType",
+		"C": "Unsupported MDImplicitDelete",
+		"":  "No reason given",
+		"Z": "Unrecognized reason Z",
+	} {
+		if got := mdRejectReasonText(code); got != want {
+			t.Errorf("mdRejectReasonText(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
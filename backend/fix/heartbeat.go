@@ -0,0 +1,63 @@
+package fix
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+// DefaultHeartbeatInterval is the HeartBtInt used by sessions that do not set one
+const DefaultHeartbeatInterval = 30 * time.Second
+
+// configuredHeartbeat returns the HeartBtInt the session proposes on Logon
+func (s *LPSession) configuredHeartbeat() time.Duration {
+	if s.HeartbeatInterval <= 0 {
+		return DefaultHeartbeatInterval
+	}
+	return s.HeartbeatInterval
+}
+
+// heartbeatSeconds converts an interval to HeartBtInt (108) whole seconds, rounding up
+func heartbeatSeconds(d time.Duration) int {
+	seconds := int(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
+// negotiateHeartbeat settles the session's heartbeat interval from the Logon
+// response: the smaller of our HeartBtInt and the counterparty's, when it sent one
+func (g *FIXGateway) negotiateHeartbeat(session *LPSession, response string) time.Duration {
+	interval := time.Duration(heartbeatSeconds(session.configuredHeartbeat())) * time.Second
+
+	if value := g.extractTag(response, "108"); value != "" {
+		theirs, err := strconv.Atoi(value)
+		switch {
+		case err != nil || theirs <= 0:
+			logger.Warn("Ignoring invalid HeartBtInt", logging.Session(session.ID), logging.String("value", value))
+		case time.Duration(theirs)*time.Second < interval:
+			logger.Info("Counterparty requested a shorter heartbeat", logging.Session(session.ID),
+				logging.Int("heart_bt_int", theirs), logging.String("previous", interval.String()))
+			interval = time.Duration(theirs) * time.Second
+		}
+	}
+
+	g.mu.Lock()
+	session.heartbeatInterval = interval
+	g.mu.Unlock()
+	return interval
+}
+
+// activeHeartbeat returns the interval negotiated at Logon, or the configured
+// one before the session has logged on
+func (g *FIXGateway) activeHeartbeat(session *LPSession) time.Duration {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if session.heartbeatInterval > 0 {
+		return session.heartbeatInterval
+	}
+	return session.configuredHeartbeat()
+}
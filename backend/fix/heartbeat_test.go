@@ -0,0 +1,81 @@
+package fix
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+// logonWith runs sendLogon against a counterparty that answers with the given
+// HeartBtInt (empty omits tag 108). Returns the HeartBtInt we proposed.
+func logonWith(t *testing.T, gw *FIXGateway, session *LPSession, theirHeartBtInt string) string {
+	t.Helper()
+	return gw.extractTag(sentLogon(t, gw, session, theirHeartBtInt), "108")
+}
+
+// sentLogon runs sendLogon like logonWith and returns the Logon we sent
+func sentLogon(t *testing.T, gw *FIXGateway, session *LPSession, theirHeartBtInt string) string {
+	t.Helper()
+	local, remote := net.Pipe()
+	defer local.Close()
+	defer remote.Close()
+	session.conn = local
+
+	proposed := make(chan string, 1)
+	go func() {
+		buf := make([]byte, 4096)
+		n, err := remote.Read(buf)
+		if err != nil {
+			return
+		}
+		proposed <- string(buf[:n])
+
+		fields := "98=0\x01"
+		if theirHeartBtInt != "" {
+			fields += fmt.Sprintf("108=%s\x01", theirHeartBtInt)
+		}
+		remote.Write([]byte(inbound(gw, session, MsgTypeLogon, 1, fields)))
+	}()
+
+	if err := gw.sendLogon(session); err != nil {
+		t.Fatalf("sendLogon() error = %v", err)
+	}
+	return <-proposed
+}
+
+// TestHeartbeatIntervalNegotiation tests that Logon proposes the session's
+// HeartBtInt and the smaller of ours and the counterparty's is used
+func TestHeartbeatIntervalNegotiation(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured time.Duration
+		theirs     string
+		wantSent   string
+		want       time.Duration
+	}{
+		{"unset keeps 30s", 0, "30", "30", 30 * time.Second},
+		{"unset with no reply tag", 0, "", "30", 30 * time.Second},
+		{"configured lower than counterparty", 10 * time.Second, "30", "10", 10 * time.Second},
+		{"counterparty lower than configured", 30 * time.Second, "10", "30", 10 * time.Second},
+		{"invalid reply ignored", 20 * time.Second, "abc", "20", 20 * time.Second},
+		{"sub-second rounds up", 500 * time.Millisecond, "", "1", time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gw, session := newTestGateway(t)
+			session.HeartbeatInterval = tt.configured
+
+			if sent := logonWith(t, gw, session, tt.theirs); sent != tt.wantSent {
+				t.Errorf("Logon 108 = %q, want %q", sent, tt.wantSent)
+			}
+			if got := gw.activeHeartbeat(session); got != tt.want {
+				t.Errorf("heartbeat interval = %v, want %v", got, tt.want)
+			}
+			if got := gw.GetDetailedStatus()[session.ID].HeartBtInt; got != int(tt.want/time.Second) {
+				t.Errorf("SessionInfo.HeartBtInt = %d, want %d", got, int(tt.want/time.Second))
+			}
+		})
+	}
+}
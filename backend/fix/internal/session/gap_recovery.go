@@ -2,11 +2,15 @@ package session
 
 import (
 	"fmt"
-	"log"
 	"sync"
 	"time"
+
+	"github.com/epic1st/rtx/backend/logging"
 )
 
+// logger writes gap recovery log entries, component "fix.gap"
+var logger = logging.With(logging.Component("fix.gap"))
+
 // GapStatus represents the status of sequence gap detection
 type GapStatus int
 
@@ -66,8 +70,8 @@ func (m *GapRecoveryManager) CheckMessage(receivedSeqNum int, possResend bool) (
 	// Check for duplicate
 	if receivedSeqNum < m.expectedSeqNum {
 		if !possResend {
-			log.Printf("[GAP] [%s] Duplicate message received: SeqNum=%d, Expected=%d (without PossResend flag)",
-				m.sessionID, receivedSeqNum, m.expectedSeqNum)
+			logger.Warn("Duplicate message received without PossResend flag", logging.Session(m.sessionID),
+				logging.Seq(receivedSeqNum), logging.Int("expected_seq", m.expectedSeqNum))
 		}
 		return GapStatusDuplicate, nil
 	}
@@ -80,8 +84,8 @@ func (m *GapRecoveryManager) CheckMessage(receivedSeqNum int, possResend bool) (
 			return GapStatusNoGap, fmt.Errorf("gap too large: %d (max=%d)", gap, m.maxGapSize)
 		}
 
-		log.Printf("[GAP] [%s] Gap detected: Expected=%d, Received=%d, Gap=%d",
-			m.sessionID, m.expectedSeqNum, receivedSeqNum, gap)
+		logger.Warn("Gap detected", logging.Session(m.sessionID), logging.Seq(receivedSeqNum),
+			logging.Int("expected_seq", m.expectedSeqNum), logging.Int("gap", gap))
 
 		// Create or update gap
 		if m.currentGap == nil {
@@ -124,8 +128,7 @@ func (m *GapRecoveryManager) QueueMessage(seqNum int, msg []byte) {
 		ReceivedAt: time.Now(),
 	})
 
-	log.Printf("[GAP] [%s] Queued message SeqNum=%d (queue size=%d)",
-		m.sessionID, seqNum, len(m.queuedMessages))
+	logger.Debug("Queued message", logging.Session(m.sessionID), logging.Seq(seqNum), logging.Int("queue_size", len(m.queuedMessages)))
 }
 
 // GetCurrentGap returns the current gap if one exists
@@ -164,8 +167,8 @@ func (m *GapRecoveryManager) MarkResendRequestSent() {
 
 	if m.currentGap != nil {
 		m.currentGap.RequestSent = true
-		log.Printf("[GAP] [%s] ResendRequest sent for gap %d-%d",
-			m.sessionID, m.currentGap.BeginSeqNo, m.currentGap.EndSeqNo)
+		logger.Info("ResendRequest sent for gap", logging.Session(m.sessionID),
+			logging.Int("begin_seq", m.currentGap.BeginSeqNo), logging.Int("end_seq", m.currentGap.EndSeqNo))
 	}
 }
 
@@ -180,13 +183,12 @@ func (m *GapRecoveryManager) FillGap(seqNum int) bool {
 
 	// Check if this message fills the gap
 	if seqNum >= m.currentGap.BeginSeqNo && seqNum <= m.currentGap.EndSeqNo {
-		log.Printf("[GAP] [%s] Received gap fill message: SeqNum=%d",
-			m.sessionID, seqNum)
+		logger.Debug("Received gap fill message", logging.Session(m.sessionID), logging.Seq(seqNum))
 
 		// If this fills the entire gap, clear it
 		if seqNum == m.currentGap.EndSeqNo {
-			log.Printf("[GAP] [%s] Gap fully filled: %d-%d",
-				m.sessionID, m.currentGap.BeginSeqNo, m.currentGap.EndSeqNo)
+			logger.Info("Gap fully filled", logging.Session(m.sessionID),
+				logging.Int("begin_seq", m.currentGap.BeginSeqNo), logging.Int("end_seq", m.currentGap.EndSeqNo))
 			m.currentGap = nil
 			return true
 		}
@@ -210,7 +212,7 @@ func (m *GapRecoveryManager) GetQueuedMessages() []QueuedMessage {
 	messages := m.queuedMessages
 	m.queuedMessages = make([]QueuedMessage, 0, 100)
 
-	log.Printf("[GAP] [%s] Releasing %d queued messages", m.sessionID, len(messages))
+	logger.Info("Releasing queued messages", logging.Session(m.sessionID), logging.Int("messages", len(messages)))
 	return messages
 }
 
@@ -254,7 +256,7 @@ func (m *GapRecoveryManager) Reset() {
 	m.queuedMessages = make([]QueuedMessage, 0, 100)
 	m.lastSeenSeqNums = make(map[int]time.Time, 1000)
 
-	log.Printf("[GAP] [%s] Gap recovery manager reset", m.sessionID)
+	logger.Info("Gap recovery manager reset", logging.Session(m.sessionID))
 }
 
 // GetStats returns gap recovery statistics
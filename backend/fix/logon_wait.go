@@ -0,0 +1,90 @@
+package fix
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+// SubscribeResult is the outcome of one symbol in a batch subscription
+type SubscribeResult struct {
+	Symbol  string
+	MDReqID string
+	Err     error
+}
+
+// WaitForLogon blocks until a session reaches LOGGED_IN or the timeout elapses.
+// Returns immediately if the session is already logged in.
+func (g *FIXGateway) WaitForLogon(sessionID string, timeout time.Duration) error {
+	g.mu.Lock()
+	session, ok := g.sessions[sessionID]
+	if !ok {
+		g.mu.Unlock()
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+	if session.Status == "LOGGED_IN" {
+		g.mu.Unlock()
+		return nil
+	}
+	waiter := make(chan struct{})
+	g.logonWaiters[sessionID] = append(g.logonWaiters[sessionID], waiter)
+	g.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-waiter:
+		return nil
+	case <-timer.C:
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// The logon may have landed between the timer firing and taking the lock
+	if session.Status == "LOGGED_IN" {
+		return nil
+	}
+	waiters := g.logonWaiters[sessionID]
+	for i, w := range waiters {
+		if w == waiter {
+			g.logonWaiters[sessionID] = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	return fmt.Errorf("timed out after %v waiting for %s logon (status %s)", timeout, sessionID, session.Status)
+}
+
+// markLoggedInUnlocked moves a session to LOGGED_IN and wakes everyone waiting
+// for its logon (caller must hold lock)
+func (g *FIXGateway) markLoggedInUnlocked(session *LPSession) {
+	g.setStatusUnlocked(session, "LOGGED_IN", "logon accepted")
+	for _, waiter := range g.logonWaiters[session.ID] {
+		close(waiter)
+	}
+	delete(g.logonWaiters, session.ID)
+}
+
+// SubscribeMarketDataBatch subscribes to market data for several symbols,
+// pausing between requests to respect the LP's rate limits. Symbols that are
+// already subscribed are skipped.
+func (g *FIXGateway) SubscribeMarketDataBatch(sessionID string, symbols []string, pace time.Duration) []SubscribeResult {
+	results := make([]SubscribeResult, 0, len(symbols))
+	for _, symbol := range symbols {
+		if g.IsSymbolSubscribed(symbol) {
+			continue
+		}
+		if len(results) > 0 && pace > 0 {
+			time.Sleep(pace)
+		}
+
+		mdReqID, err := g.SubscribeMarketData(sessionID, symbol)
+		results = append(results, SubscribeResult{Symbol: symbol, MDReqID: mdReqID, Err: err})
+		if err != nil {
+			logger.Error("Failed to subscribe", err, logging.Session(sessionID), logging.Symbol(symbol))
+		}
+	}
+	return results
+}
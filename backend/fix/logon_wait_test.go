@@ -0,0 +1,53 @@
+package fix
+
+import (
+	"testing"
+	"time"
+)
+
+// TestWaitForLogonReturnsOnLogon tests that a waiter is released as soon as the session logs in
+func TestWaitForLogonReturnsOnLogon(t *testing.T) {
+	gw, session := newTestGateway(t)
+	session.Status = "CONNECTING"
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		gw.mu.Lock()
+		gw.markLoggedInUnlocked(session)
+		gw.mu.Unlock()
+	}()
+
+	start := time.Now()
+	if err := gw.WaitForLogon(session.ID, 5*time.Second); err != nil {
+		t.Fatalf("WaitForLogon() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("WaitForLogon() took %v, want prompt return after logon", elapsed)
+	}
+
+	// Already logged in returns immediately
+	if err := gw.WaitForLogon(session.ID, time.Millisecond); err != nil {
+		t.Errorf("WaitForLogon() on logged in session error = %v", err)
+	}
+}
+
+// TestWaitForLogonTimesOut tests that a session that never logs in errors after the timeout
+func TestWaitForLogonTimesOut(t *testing.T) {
+	gw, session := newTestGateway(t)
+	session.Status = "CONNECTED"
+
+	start := time.Now()
+	if err := gw.WaitForLogon(session.ID, 50*time.Millisecond); err == nil {
+		t.Fatal("WaitForLogon() error = nil, want timeout")
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("WaitForLogon() returned after %v, before the timeout", elapsed)
+	}
+	if n := len(gw.logonWaiters[session.ID]); n != 0 {
+		t.Errorf("%d waiters left registered after timeout, want 0", n)
+	}
+
+	if err := gw.WaitForLogon("UNKNOWN", time.Millisecond); err == nil {
+		t.Error("WaitForLogon(UNKNOWN) error = nil, want session not found")
+	}
+}
@@ -0,0 +1,77 @@
+package fix
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+// SubscriberBufferSize is the number of quotes each Subscribe channel buffers
+const SubscriberBufferSize = 10000
+
+// marketDataSubscriber is one consumer of the quote fan-out
+type marketDataSubscriber struct {
+	ch      chan MarketData
+	dropped int64 // Quotes dropped because this consumer's buffer was full
+}
+
+// marketDataBroadcaster hands every quote to each subscriber without ever
+// blocking the session reader. A full subscriber loses the quote alone.
+type marketDataBroadcaster struct {
+	mu          sync.RWMutex
+	subscribers []*marketDataSubscriber
+}
+
+// Subscribe returns a new channel receiving every quote from all sessions.
+// Each caller gets its own buffer, so consumers never steal quotes from one
+// another and a slow one only drops its own quotes. Release it with Unsubscribe.
+func (g *FIXGateway) Subscribe() <-chan MarketData {
+	sub := &marketDataSubscriber{ch: make(chan MarketData, SubscriberBufferSize)}
+
+	g.mdBroadcast.mu.Lock()
+	g.mdBroadcast.subscribers = append(g.mdBroadcast.subscribers, sub)
+	g.mdBroadcast.mu.Unlock()
+	return sub.ch
+}
+
+// Unsubscribe stops quotes to a channel returned by Subscribe and closes it
+func (g *FIXGateway) Unsubscribe(ch <-chan MarketData) {
+	g.mdBroadcast.mu.Lock()
+	defer g.mdBroadcast.mu.Unlock()
+
+	for i, sub := range g.mdBroadcast.subscribers {
+		if sub.ch == ch {
+			g.mdBroadcast.subscribers = append(g.mdBroadcast.subscribers[:i], g.mdBroadcast.subscribers[i+1:]...)
+			close(sub.ch)
+			return
+		}
+	}
+}
+
+// publishMarketData delivers a quote to every subscriber, and to the legacy
+// channel once GetMarketData has been called
+func (g *FIXGateway) publishMarketData(md MarketData) {
+	if g.legacyMarketData.Load() {
+		select {
+		case g.marketData <- md:
+		default:
+			logger.Warn("MarketData channel full, dropping quote", logging.Session(md.SessionID), logging.Symbol(md.Symbol))
+		}
+	}
+
+	g.mdBroadcast.mu.RLock()
+	defer g.mdBroadcast.mu.RUnlock()
+
+	for _, sub := range g.mdBroadcast.subscribers {
+		select {
+		case sub.ch <- md:
+		default:
+			// Log the first drop and then every 1000th, so a stalled consumer does not flood the log
+			if dropped := atomic.AddInt64(&sub.dropped, 1); dropped%1000 == 1 {
+				logger.Warn("MarketData subscriber full, dropping quotes", logging.Session(md.SessionID),
+					logging.Symbol(md.Symbol), logging.Int64("dropped", dropped))
+			}
+		}
+	}
+}
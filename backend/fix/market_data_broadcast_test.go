@@ -0,0 +1,77 @@
+package fix
+
+import (
+	"fmt"
+	"testing"
+)
+
+// sendSnapshot processes a one-level snapshot for symbol as if read from the LP
+func sendSnapshot(gw *FIXGateway, session *LPSession, seq int, symbol string) {
+	gw.processMessage(session, nil, inbound(gw, session, MsgTypeMarketDataSnapshot, seq,
+		fmt.Sprintf("55=%s\x01268=2\x01269=0\x01270=1.1000\x01269=1\x01270=1.1002\x01", symbol)))
+}
+
+// TestSubscribeFansOut tests that every subscriber receives every quote
+func TestSubscribeFansOut(t *testing.T) {
+	gw, session := newTestGateway(t)
+	first, second := gw.Subscribe(), gw.Subscribe()
+
+	sendSnapshot(gw, session, 1, "EURUSD")
+	sendSnapshot(gw, session, 2, "GBPUSD")
+
+	for name, ch := range map[string]<-chan MarketData{"first": first, "second": second} {
+		for _, want := range []string{"EURUSD", "GBPUSD"} {
+			select {
+			case md := <-ch:
+				if md.Symbol != want {
+					t.Errorf("%s subscriber got %s, want %s", name, md.Symbol, want)
+				}
+			default:
+				t.Fatalf("%s subscriber missing %s", name, want)
+			}
+		}
+	}
+}
+
+// TestSubscribeSlowConsumerDropsAlone tests that a full subscriber drops its
+// own quotes without blocking the reader or starving other subscribers
+func TestSubscribeSlowConsumerDropsAlone(t *testing.T) {
+	gw, session := newTestGateway(t)
+	slow, fast := gw.Subscribe(), gw.Subscribe()
+
+	for seq := 1; seq <= SubscriberBufferSize+5; seq++ {
+		sendSnapshot(gw, session, seq, "EURUSD")
+		<-fast
+	}
+
+	if got := len(slow); got != SubscriberBufferSize {
+		t.Errorf("slow subscriber buffered %d quotes, want %d", got, SubscriberBufferSize)
+	}
+	if len(fast) != 0 {
+		t.Errorf("fast subscriber has %d unread quotes, want 0", len(fast))
+	}
+}
+
+// TestUnsubscribeAndLegacyChannel tests that Unsubscribe closes the channel and
+// the legacy channel only fills once it has been requested
+func TestUnsubscribeAndLegacyChannel(t *testing.T) {
+	gw, session := newTestGateway(t)
+	sub := gw.Subscribe()
+
+	sendSnapshot(gw, session, 1, "EURUSD")
+	if len(gw.marketData) != 0 {
+		t.Errorf("legacy channel holds %d quotes before GetMarketData, want 0", len(gw.marketData))
+	}
+
+	gw.Unsubscribe(sub)
+	<-sub // the quote sent before unsubscribing
+	if _, ok := <-sub; ok {
+		t.Error("unsubscribed channel is still open")
+	}
+
+	legacy := gw.GetMarketData()
+	sendSnapshot(gw, session, 2, "GBPUSD")
+	if md := <-legacy; md.Symbol != "GBPUSD" {
+		t.Errorf("legacy channel got %s, want GBPUSD", md.Symbol)
+	}
+}
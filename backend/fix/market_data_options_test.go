@@ -0,0 +1,131 @@
+package fix
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// captureSent connects the session to a pipe and returns a function that reads
+// the next message written to the LP
+func captureSent(t *testing.T, session *LPSession) func() string {
+	t.Helper()
+	local, remote := net.Pipe()
+	t.Cleanup(func() {
+		local.Close()
+		remote.Close()
+	})
+	session.conn = local
+
+	sent := make(chan string, 10)
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := remote.Read(buf)
+			if err != nil {
+				return
+			}
+			sent <- string(buf[:n])
+		}
+	}()
+
+	return func() string {
+		select {
+		case msg := <-sent:
+			return msg
+		case <-time.After(time.Second):
+			t.Fatal("no message sent")
+			return ""
+		}
+	}
+}
+
+// tagValues returns every value of a tag in a raw FIX message
+func tagValues(msg, tag string) []string {
+	var values []string
+	for _, field := range strings.Split(msg, "\x01") {
+		if strings.HasPrefix(field, tag+"=") {
+			values = append(values, strings.TrimPrefix(field, tag+"="))
+		}
+	}
+	return values
+}
+
+// TestSubscribeMarketDataDefaults tests that the two-argument subscribe keeps
+// sending full book streaming with no MDUpdateType
+func TestSubscribeMarketDataDefaults(t *testing.T) {
+	gw, session := newTestGateway(t)
+	next := captureSent(t, session)
+
+	if _, err := gw.SubscribeMarketData(session.ID, "EURUSD"); err != nil {
+		t.Fatalf("SubscribeMarketData() error = %v", err)
+	}
+	msg := next()
+
+	for tag, want := range map[string]string{"263": "1", "264": "0", "167": "FXSPOT", "55": "EURUSD"} {
+		if got := tagValues(msg, tag); len(got) != 1 || got[0] != want {
+			t.Errorf("tag %s = %v, want [%s]", tag, got, want)
+		}
+	}
+	if got := tagValues(msg, "265"); len(got) != 0 {
+		t.Errorf("tag 265 = %v, want omitted", got)
+	}
+}
+
+// TestSubscribeMarketDataWithOptions tests that depth, subscription type,
+// update type and security type reach the request, and optional tags are omitted when unset
+func TestSubscribeMarketDataWithOptions(t *testing.T) {
+	gw, session := newTestGateway(t)
+	next := captureSent(t, session)
+
+	incremental := 1
+	mdReqID, err := gw.SubscribeMarketDataWithOptions(session.ID, "GBPUSD", MarketDataOptions{
+		MarketDepth:      1,
+		SubscriptionType: 0,
+		UpdateType:       &incremental,
+		SecurityType:     "FOR",
+	})
+	if err != nil {
+		t.Fatalf("SubscribeMarketDataWithOptions() error = %v", err)
+	}
+	msg := next()
+
+	for tag, want := range map[string]string{"262": mdReqID, "263": "0", "264": "1", "265": "1", "167": "FOR"} {
+		if got := tagValues(msg, tag); len(got) != 1 || got[0] != want {
+			t.Errorf("tag %s = %v, want [%s]", tag, got, want)
+		}
+	}
+	if !gw.IsSymbolSubscribed("GBPUSD") {
+		t.Error("GBPUSD should be tracked as subscribed")
+	}
+
+	// No SecurityType omits tag 167
+	if _, err := gw.SubscribeMarketDataWithOptions(session.ID, "USDJPY", MarketDataOptions{SubscriptionType: 1}); err != nil {
+		t.Fatalf("SubscribeMarketDataWithOptions() error = %v", err)
+	}
+	if got := tagValues(next(), "167"); len(got) != 0 {
+		t.Errorf("tag 167 = %v, want omitted", got)
+	}
+}
+
+// TestSubscribeMarketDataOptionsValidation tests that invalid tag values are rejected before sending
+func TestSubscribeMarketDataOptionsValidation(t *testing.T) {
+	gw, session := newTestGateway(t)
+	bad := 2
+
+	tests := []MarketDataOptions{
+		{MarketDepth: -1, SubscriptionType: 1},
+		{SubscriptionType: 2},
+		{SubscriptionType: 1, UpdateType: &bad},
+		{SubscriptionType: 1, SecurityType: "FX\x01SPOT"},
+	}
+	for _, opts := range tests {
+		if _, err := gw.SubscribeMarketDataWithOptions(session.ID, "EURUSD", opts); err == nil {
+			t.Errorf("SubscribeMarketDataWithOptions(%+v) succeeded, want error", opts)
+		}
+	}
+	if gw.IsSymbolSubscribed("EURUSD") {
+		t.Error("rejected options should not record a subscription")
+	}
+}
@@ -3,9 +3,10 @@ package fix
 import (
 	"errors"
 	"fmt"
-	"log"
 	"sync"
 	"time"
+
+	"github.com/epic1st/rtx/backend/logging"
 )
 
 // ProvisioningService manages FIX API provisioning for users
@@ -336,8 +337,8 @@ func (sal *SimpleAuditLogger) LogCredentialOperation(operation, userID, details
 		status = "FAILED"
 	}
 
-	log.Printf("[AUDIT] [%s] operation=%s user=%s details=%s",
-		status, operation, userID, details)
+	logger.Info("Credential operation", logging.Component("fix.audit"), logging.String("status", status),
+		logging.String("operation", operation), logging.UserID(userID), logging.String("details", details))
 }
 
 // NewSimpleAuditLogger creates a basic audit logger
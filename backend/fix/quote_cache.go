@@ -0,0 +1,88 @@
+package fix
+
+import "time"
+
+// DefaultQuoteStaleAfter is how long a cached bid or ask stays usable for
+// merging with incremental updates to the other side
+const DefaultQuoteStaleAfter = 5 * time.Second
+
+// cachedQuote is the last known top of book of a symbol. Incremental updates
+// move one side at a time, so each side keeps its own update time.
+type cachedQuote struct {
+	MarketData
+	bidAt time.Time
+	askAt time.Time
+}
+
+// SetQuoteStaleAfter sets how old a cached side may be before an incremental
+// update stops merging with it. 0 never treats a side as stale.
+func (g *FIXGateway) SetQuoteStaleAfter(d time.Duration) {
+	g.quoteCacheMu.Lock()
+	defer g.quoteCacheMu.Unlock()
+	g.quoteStaleAfter = d
+}
+
+// GetTopOfBook returns a symbol's cached quote with its sizes. A side older
+// than the stale threshold is reported as 0, and false means no quote has been
+// received for the symbol.
+func (g *FIXGateway) GetTopOfBook(symbol string) (MarketData, bool) {
+	g.quoteCacheMu.RLock()
+	defer g.quoteCacheMu.RUnlock()
+
+	cached, ok := g.quoteCache[symbol]
+	if !ok {
+		return MarketData{}, false
+	}
+	md := cached.MarketData
+	if g.quoteStaleAfter > 0 {
+		if time.Since(cached.bidAt) > g.quoteStaleAfter {
+			md.Bid, md.BidSize = 0, 0
+		}
+		if time.Since(cached.askAt) > g.quoteStaleAfter {
+			md.Ask, md.AskSize = 0, 0
+		}
+	}
+	return md, true
+}
+
+// cacheSnapshotQuote replaces a symbol's cached quote with a full snapshot
+func (g *FIXGateway) cacheSnapshotQuote(md MarketData) {
+	g.quoteCacheMu.Lock()
+	defer g.quoteCacheMu.Unlock()
+	g.quoteCache[md.Symbol] = &cachedQuote{MarketData: md, bidAt: md.Timestamp, askAt: md.Timestamp}
+}
+
+// mergeIncrementalQuote applies one side of an incremental update to the
+// cached quote and returns the merged quote. The other side is dropped when it
+// is older than the stale threshold, and ok is false until both sides are live.
+func (g *FIXGateway) mergeIncrementalQuote(symbol, sessionID, entryType string, price, size float64, now time.Time) (MarketData, bool) {
+	g.quoteCacheMu.Lock()
+	defer g.quoteCacheMu.Unlock()
+
+	cached := g.quoteCache[symbol]
+	if cached == nil {
+		cached = &cachedQuote{MarketData: MarketData{Symbol: symbol}}
+		g.quoteCache[symbol] = cached
+	}
+
+	stale := func(at time.Time) bool {
+		return g.quoteStaleAfter > 0 && now.Sub(at) > g.quoteStaleAfter
+	}
+	if stale(cached.bidAt) {
+		cached.Bid, cached.BidSize = 0, 0
+	}
+	if stale(cached.askAt) {
+		cached.Ask, cached.AskSize = 0, 0
+	}
+
+	switch entryType {
+	case "0": // Bid
+		cached.Bid, cached.BidSize, cached.bidAt = price, size, now
+	case "1": // Offer
+		cached.Ask, cached.AskSize, cached.askAt = price, size, now
+	}
+	cached.SessionID = sessionID
+	cached.Timestamp = now
+
+	return cached.MarketData, cached.Bid > 0 && cached.Ask > 0
+}
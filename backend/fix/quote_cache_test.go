@@ -0,0 +1,88 @@
+package fix
+
+import (
+	"testing"
+	"time"
+)
+
+// sendIncremental delivers a one-entry MarketDataIncrementalRefresh for a side (0=Bid, 1=Offer)
+func sendIncremental(gw *FIXGateway, session *LPSession, symbol, side, price string) {
+	msg := inbound(gw, session, MsgTypeMarketDataIncremental, 1,
+		"268=1\x01279=1\x01269="+side+"\x0155="+symbol+"\x01270="+price+"\x01271=1000000\x01")
+	gw.handleMarketDataIncremental(session, parseFields(msg))
+}
+
+// TestIncrementalSkipsStaleSide tests that an incremental update merges with a
+// fresh cached side but not with one older than the stale threshold
+func TestIncrementalSkipsStaleSide(t *testing.T) {
+	gw, session := newTestGateway(t)
+	quotes := gw.Subscribe()
+
+	sendSnapshot(gw, session, 1, "EURUSD")
+	<-quotes
+
+	sendIncremental(gw, session, "EURUSD", "1", "1.1003")
+	select {
+	case md := <-quotes:
+		if md.Bid != 1.1000 || md.Ask != 1.1003 {
+			t.Errorf("merged quote = %.4f/%.4f, want 1.1000/1.1003", md.Bid, md.Ask)
+		}
+	default:
+		t.Fatal("fresh incremental update not published")
+	}
+
+	// Let the cached bid go stale: an ask update alone must not revive it
+	gw.quoteCacheMu.Lock()
+	gw.quoteCache["EURUSD"].bidAt = time.Now().Add(-2 * DefaultQuoteStaleAfter)
+	gw.quoteCacheMu.Unlock()
+
+	sendIncremental(gw, session, "EURUSD", "1", "1.1004")
+	select {
+	case md := <-quotes:
+		t.Fatalf("published %.4f/%.4f merged onto a stale bid", md.Bid, md.Ask)
+	default:
+	}
+	if md, ok := gw.GetTopOfBook("EURUSD"); !ok || md.Bid != 0 || md.Ask != 1.1004 {
+		t.Errorf("GetTopOfBook() = %.4f/%.4f, %v; want the stale bid dropped", md.Bid, md.Ask, ok)
+	}
+
+	sendIncremental(gw, session, "EURUSD", "0", "1.1002")
+	select {
+	case md := <-quotes:
+		if md.Bid != 1.1002 || md.Ask != 1.1004 {
+			t.Errorf("quote = %.4f/%.4f, want 1.1002/1.1004 once both sides are live", md.Bid, md.Ask)
+		}
+	default:
+		t.Fatal("quote with both sides live not published")
+	}
+	if md, ok := gw.GetTopOfBook("EURUSD"); !ok || md.Bid != 1.1002 || md.Ask != 1.1004 {
+		t.Errorf("GetTopOfBook() = %.4f/%.4f, %v; want both sides live", md.Bid, md.Ask, ok)
+	}
+
+	if _, ok := gw.GetTopOfBook("GBPUSD"); ok {
+		t.Error("GetTopOfBook() reported a symbol with no quotes")
+	}
+}
+
+// TestQuoteStaleAfterDisabled tests that a zero threshold always merges
+func TestQuoteStaleAfterDisabled(t *testing.T) {
+	gw, session := newTestGateway(t)
+	gw.SetQuoteStaleAfter(0)
+	quotes := gw.Subscribe()
+
+	sendSnapshot(gw, session, 1, "EURUSD")
+	<-quotes
+	gw.quoteCacheMu.Lock()
+	gw.quoteCache["EURUSD"].bidAt = time.Now().Add(-time.Hour)
+	gw.quoteCacheMu.Unlock()
+
+	sendIncremental(gw, session, "EURUSD", "1", "1.1003")
+	select {
+	case md := <-quotes:
+		if md.Bid != 1.1000 {
+			t.Errorf("bid = %.4f, want the cached 1.1000", md.Bid)
+		}
+	default:
+		t.Fatal("incremental update not published")
+	}
+}
@@ -0,0 +1,182 @@
+package fix
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+// Reconnect backoff doubles from the base delay up to the cap
+const (
+	DefaultReconnectBaseDelay = time.Second
+	DefaultReconnectMaxDelay  = 60 * time.Second
+)
+
+// resubscribePace spaces out the MarketDataRequests replayed after a reconnect
+const resubscribePace = 50 * time.Millisecond
+
+// mdRequest records who owns a market data subscription so it can be replayed
+type mdRequest struct {
+	sessionID string
+	options   MarketDataOptions
+}
+
+// SetAutoReconnect enables or disables automatic reconnection of a session after
+// its connection drops. Disabling cancels any pending reconnect.
+func (g *FIXGateway) SetAutoReconnect(sessionID string, enabled bool) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	session, ok := g.sessions[sessionID]
+	if !ok {
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+	session.ReconnectEnabled = enabled
+	if !enabled {
+		g.cancelReconnectUnlocked(session)
+	}
+	return nil
+}
+
+// SetMaxReconnectAttempts limits how many reconnects are tried after a drop
+// before giving up. 0 keeps retrying.
+func (g *FIXGateway) SetMaxReconnectAttempts(sessionID string, attempts int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	session, ok := g.sessions[sessionID]
+	if !ok {
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+	session.MaxReconnectAttempts = attempts
+	return nil
+}
+
+// reconnectBackoff returns the delay before the given reconnect attempt (1-based)
+func reconnectBackoff(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// dropConnection tears down a connection that failed underneath the session and
+// schedules a reconnect. Does nothing if the session has already moved on from conn.
+func (g *FIXGateway) dropConnection(session *LPSession, conn net.Conn, reason string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if session.conn != conn {
+		return
+	}
+	g.disconnectUnlocked(session, reason)
+	g.scheduleReconnectUnlocked(session)
+}
+
+// scheduleReconnectUnlocked arms the next reconnect attempt with exponential
+// backoff, unless reconnection is off, already pending, stopped by an explicit
+// Disconnect, or out of attempts (caller must hold lock)
+func (g *FIXGateway) scheduleReconnectUnlocked(session *LPSession) {
+	if !session.ReconnectEnabled || session.manualDisconnect || session.reconnectTimer != nil {
+		return
+	}
+	if session.MaxReconnectAttempts > 0 && session.reconnectAttempts >= session.MaxReconnectAttempts {
+		logger.Error("Giving up reconnecting", nil, logging.Session(session.ID), logging.Int("attempts", session.reconnectAttempts))
+		session.reconnectAttempts = 0
+		return
+	}
+
+	session.reconnectAttempts++
+	delay := reconnectBackoff(session.reconnectAttempts, g.reconnectBaseDelay, g.reconnectMaxDelay)
+	logger.Info("Reconnecting", logging.Session(session.ID), logging.String("delay", delay.String()), logging.Int("attempt", session.reconnectAttempts))
+	session.reconnectTimer = time.AfterFunc(delay, func() { g.attemptReconnect(session) })
+}
+
+// cancelReconnectUnlocked stops a pending reconnect and resets the attempt
+// count (caller must hold lock)
+func (g *FIXGateway) cancelReconnectUnlocked(session *LPSession) {
+	if session.reconnectTimer != nil {
+		session.reconnectTimer.Stop()
+		session.reconnectTimer = nil
+	}
+	session.reconnectAttempts = 0
+}
+
+// attemptReconnect runs one scheduled reconnect. connectSession reports the
+// outcome and schedules the next attempt if it fails.
+func (g *FIXGateway) attemptReconnect(session *LPSession) {
+	g.mu.Lock()
+	session.reconnectTimer = nil
+	g.mu.Unlock()
+
+	err := g.connect(session.ID, false)
+	if err == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if errors.Is(err, ErrReconnectBreakerOpen) {
+		// Wait out the breaker cooldown without spending an attempt
+		if b, ok := g.breakers[session.ID]; ok && session.reconnectTimer == nil {
+			delay := time.Until(b.retryAt)
+			logger.Warn("Reconnect held by breaker", logging.Session(session.ID), logging.String("delay", delay.Round(time.Second).String()))
+			session.reconnectTimer = time.AfterFunc(delay, func() { g.attemptReconnect(session) })
+			return
+		}
+		g.scheduleReconnectUnlocked(session)
+		return
+	}
+	// Disabled, explicitly disconnected, or connected by someone else meanwhile
+	logger.Warn("Reconnect skipped", logging.Session(session.ID), logging.Err(err))
+	session.reconnectAttempts = 0
+}
+
+// resubscribeSession replays the market data subscriptions a session held
+// before its connection dropped, so quotes resume without operator action
+func (g *FIXGateway) resubscribeSession(session *LPSession) {
+	type replay struct {
+		symbol  string
+		options MarketDataOptions
+	}
+
+	g.mu.Lock()
+	var replays []replay
+	for mdReqID, req := range g.mdRequests {
+		if req.sessionID != session.ID {
+			continue
+		}
+		// The old MDReqIDs died with the connection
+		symbol := g.mdSubscriptions[mdReqID]
+		delete(g.mdRequests, mdReqID)
+		delete(g.mdSubscriptions, mdReqID)
+		if symbol == "" || g.symbolSubscriptions[symbol] != mdReqID {
+			continue
+		}
+		delete(g.symbolSubscriptions, symbol)
+		replays = append(replays, replay{symbol: symbol, options: req.options})
+	}
+	g.mu.Unlock()
+
+	if len(replays) == 0 {
+		return
+	}
+
+	logger.Info("Re-subscribing symbols", logging.Session(session.ID), logging.Int("symbols", len(replays)))
+	for i, r := range replays {
+		if i > 0 {
+			time.Sleep(resubscribePace)
+		}
+		if _, err := g.SubscribeMarketDataWithOptions(session.ID, r.symbol, r.options); err != nil {
+			logger.Error("Failed to re-subscribe", err, logging.Session(session.ID), logging.Symbol(r.symbol))
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package fix
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+// ErrReconnectBreakerOpen is returned by Connect while a session's reconnect
+// breaker is cooling down after repeated connection failures
+var ErrReconnectBreakerOpen = errors.New("reconnect breaker open")
+
+// ReconnectBreakerConfig controls reconnect storm protection. After
+// MaxFailures failed connection attempts within Window the breaker trips and
+// only one attempt per Cooldown is allowed until a connection succeeds or the
+// breaker is reset. MaxFailures 0 disables the breaker.
+type ReconnectBreakerConfig struct {
+	MaxFailures int
+	Window      time.Duration
+	Cooldown    time.Duration
+}
+
+// DefaultReconnectBreakerConfig returns the default storm protection: 5
+// failures within 2 minutes trip a 10 minute cooldown
+func DefaultReconnectBreakerConfig() ReconnectBreakerConfig {
+	return ReconnectBreakerConfig{
+		MaxFailures: 5,
+		Window:      2 * time.Minute,
+		Cooldown:    10 * time.Minute,
+	}
+}
+
+// ReconnectBreakerState is the reconnect breaker of one session
+type ReconnectBreakerState struct {
+	SessionID string     `json:"sessionId"`
+	Failures  int        `json:"failures"` // Failed attempts within the window
+	Tripped   bool       `json:"tripped"`
+	TrippedAt *time.Time `json:"trippedAt,omitempty"`
+	RetryAt   *time.Time `json:"retryAt,omitempty"` // Next attempt allowed while tripped
+	LastError string     `json:"lastError,omitempty"`
+}
+
+type reconnectBreaker struct {
+	failures  []time.Time
+	trippedAt time.Time
+	retryAt   time.Time
+	lastError string
+}
+
+// SetReconnectBreaker sets the reconnect storm protection for all sessions
+func (g *FIXGateway) SetReconnectBreaker(config ReconnectBreakerConfig) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.breakerConfig = config
+}
+
+// SetReconnectBreakerCallback sets the function notified when a session's
+// breaker trips (tripped=true) or closes again after a success or reset
+func (g *FIXGateway) SetReconnectBreakerCallback(fn func(state ReconnectBreakerState, tripped bool)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.breakerCallback = fn
+}
+
+// GetReconnectBreakers returns the breaker state of every session that has failed to connect
+func (g *FIXGateway) GetReconnectBreakers() map[string]ReconnectBreakerState {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	now := time.Now()
+	states := make(map[string]ReconnectBreakerState, len(g.breakers))
+	for sessionID, b := range g.breakers {
+		states[sessionID] = g.breakerStateUnlocked(sessionID, b, now)
+	}
+	return states
+}
+
+// ResetReconnectBreaker closes a session's breaker so connection attempts resume immediately
+func (g *FIXGateway) ResetReconnectBreaker(sessionID string) error {
+	g.mu.Lock()
+	if _, ok := g.sessions[sessionID]; !ok {
+		g.mu.Unlock()
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+	b, ok := g.breakers[sessionID]
+	if !ok {
+		g.mu.Unlock()
+		return nil
+	}
+	state := g.breakerStateUnlocked(sessionID, b, time.Now())
+	delete(g.breakers, sessionID)
+	callback := g.breakerCallback
+	g.mu.Unlock()
+
+	logger.Info("Reconnect breaker reset manually", logging.Session(sessionID))
+	if state.Tripped && callback != nil {
+		state.Tripped = false
+		callback(state, false)
+	}
+	return nil
+}
+
+// checkReconnectBreakerUnlocked refuses a connection attempt while the breaker
+// is cooling down. An attempt past the cooldown is let through as a probe and
+// pushes the next allowed attempt a full cooldown out (caller must hold lock).
+func (g *FIXGateway) checkReconnectBreakerUnlocked(sessionID string, now time.Time) error {
+	b, ok := g.breakers[sessionID]
+	if !ok || b.trippedAt.IsZero() {
+		return nil
+	}
+	if now.Before(b.retryAt) {
+		return fmt.Errorf("%w for %s: %d failures, next attempt at %s (last error: %s)",
+			ErrReconnectBreakerOpen, sessionID, len(b.failures), b.retryAt.Format(time.RFC3339), b.lastError)
+	}
+	b.retryAt = now.Add(g.breakerConfig.Cooldown)
+	logger.Info("Reconnect breaker allowing probe attempt", logging.Session(sessionID))
+	return nil
+}
+
+// recordConnectFailure counts a failed connection attempt and trips the breaker
+// once failures within the window reach the limit
+func (g *FIXGateway) recordConnectFailure(session *LPSession, cause error) {
+	g.mu.Lock()
+	config := g.breakerConfig
+	if config.MaxFailures <= 0 {
+		g.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	b, ok := g.breakers[session.ID]
+	if !ok {
+		b = &reconnectBreaker{}
+		g.breakers[session.ID] = b
+	}
+	b.lastError = cause.Error()
+
+	// Only failures within the window count
+	recent := b.failures[:0]
+	for _, at := range b.failures {
+		if now.Sub(at) <= config.Window {
+			recent = append(recent, at)
+		}
+	}
+	b.failures = append(recent, now)
+
+	if !b.trippedAt.IsZero() || len(b.failures) < config.MaxFailures {
+		g.mu.Unlock()
+		return
+	}
+	b.trippedAt = now
+	b.retryAt = now.Add(config.Cooldown)
+	state := g.breakerStateUnlocked(session.ID, b, now)
+	callback := g.breakerCallback
+	g.mu.Unlock()
+
+	logger.Error("Reconnect breaker tripped", nil, logging.Session(session.ID), logging.Int("failures", state.Failures),
+		logging.String("window", config.Window.String()), logging.String("retry_every", config.Cooldown.String()))
+	if callback != nil {
+		callback(state, true)
+	}
+}
+
+// recordConnectSuccess closes the breaker of a session that has logged on
+func (g *FIXGateway) recordConnectSuccess(session *LPSession) {
+	g.mu.Lock()
+	b, ok := g.breakers[session.ID]
+	if !ok {
+		g.mu.Unlock()
+		return
+	}
+	state := g.breakerStateUnlocked(session.ID, b, time.Now())
+	delete(g.breakers, session.ID)
+	callback := g.breakerCallback
+	g.mu.Unlock()
+
+	if state.Tripped {
+		logger.Info("Reconnect breaker closed after successful logon", logging.Session(session.ID))
+		if callback != nil {
+			state.Tripped = false
+			callback(state, false)
+		}
+	}
+}
+
+// breakerStateUnlocked snapshots a breaker (caller must hold lock)
+func (g *FIXGateway) breakerStateUnlocked(sessionID string, b *reconnectBreaker, now time.Time) ReconnectBreakerState {
+	state := ReconnectBreakerState{
+		SessionID: sessionID,
+		LastError: b.lastError,
+	}
+	for _, at := range b.failures {
+		if now.Sub(at) <= g.breakerConfig.Window {
+			state.Failures++
+		}
+	}
+	if !b.trippedAt.IsZero() {
+		trippedAt, retryAt := b.trippedAt, b.retryAt
+		state.Tripped = true
+		state.TrippedAt = &trippedAt
+		state.RetryAt = &retryAt
+	}
+	return state
+}
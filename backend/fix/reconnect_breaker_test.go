@@ -0,0 +1,124 @@
+package fix
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// unreachableSession points the test session at a closed local port so every
+// connection attempt fails fast
+func unreachableSession(t *testing.T, session *LPSession) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	session.Host = "127.0.0.1"
+	session.Port = ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	session.Status = "DISCONNECTED"
+}
+
+// failConnect makes one connection attempt and waits for it to fail
+func failConnect(t *testing.T, gw *FIXGateway, sessionID string) {
+	t.Helper()
+	if err := gw.Connect(sessionID); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		gw.mu.RLock()
+		status := gw.sessions[sessionID].Status
+		gw.mu.RUnlock()
+		if status == "DISCONNECTED" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("connection attempt still %s", status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestReconnectBreakerTripsAndResets tests that repeated connection failures
+// trip the breaker, blocking further attempts, and a manual reset resumes them
+func TestReconnectBreakerTripsAndResets(t *testing.T) {
+	gw, session := newTestGateway(t)
+	unreachableSession(t, session)
+	gw.SetReconnectBreaker(ReconnectBreakerConfig{MaxFailures: 3, Window: time.Minute, Cooldown: time.Hour})
+
+	var mu sync.Mutex
+	var events []bool
+	gw.SetReconnectBreakerCallback(func(state ReconnectBreakerState, tripped bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, tripped)
+	})
+
+	for i := 0; i < 3; i++ {
+		failConnect(t, gw, session.ID)
+	}
+
+	err := gw.Connect(session.ID)
+	if !errors.Is(err, ErrReconnectBreakerOpen) {
+		t.Fatalf("Connect() after %d failures error = %v, want ErrReconnectBreakerOpen", 3, err)
+	}
+	state := gw.GetReconnectBreakers()[session.ID]
+	if !state.Tripped || state.Failures != 3 || state.RetryAt == nil || state.LastError == "" {
+		t.Errorf("breaker state = %+v, want tripped with 3 failures", state)
+	}
+
+	if err := gw.ResetReconnectBreaker(session.ID); err != nil {
+		t.Fatalf("ResetReconnectBreaker() error = %v", err)
+	}
+	if _, ok := gw.GetReconnectBreakers()[session.ID]; ok {
+		t.Error("breaker state should be cleared after reset")
+	}
+	failConnect(t, gw, session.ID) // Attempts are allowed again
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 || !events[0] || events[1] {
+		t.Errorf("breaker events = %v, want [true false]", events)
+	}
+}
+
+// TestReconnectBreakerProbesAfterCooldown tests that a tripped breaker allows
+// one attempt per cooldown and stays tripped when that attempt fails
+func TestReconnectBreakerProbesAfterCooldown(t *testing.T) {
+	gw, session := newTestGateway(t)
+	unreachableSession(t, session)
+	gw.SetReconnectBreaker(ReconnectBreakerConfig{MaxFailures: 2, Window: time.Minute, Cooldown: 50 * time.Millisecond})
+
+	failConnect(t, gw, session.ID)
+	failConnect(t, gw, session.ID)
+	if err := gw.Connect(session.ID); !errors.Is(err, ErrReconnectBreakerOpen) {
+		t.Fatalf("Connect() during cooldown error = %v, want ErrReconnectBreakerOpen", err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	failConnect(t, gw, session.ID) // Probe after the cooldown
+	if err := gw.Connect(session.ID); !errors.Is(err, ErrReconnectBreakerOpen) {
+		t.Errorf("Connect() right after failed probe error = %v, want ErrReconnectBreakerOpen", err)
+	}
+	if !gw.GetReconnectBreakers()[session.ID].Tripped {
+		t.Error("breaker should stay tripped after a failed probe")
+	}
+}
+
+// TestReconnectBreakerDisabled tests that MaxFailures 0 never blocks attempts
+func TestReconnectBreakerDisabled(t *testing.T) {
+	gw, session := newTestGateway(t)
+	unreachableSession(t, session)
+	gw.SetReconnectBreaker(ReconnectBreakerConfig{})
+
+	for i := 0; i < 5; i++ {
+		failConnect(t, gw, session.ID)
+	}
+	if len(gw.GetReconnectBreakers()) != 0 {
+		t.Error("disabled breaker should not track failures")
+	}
+}
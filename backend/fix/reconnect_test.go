@@ -0,0 +1,235 @@
+package fix
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLP is a counterparty that accepts logons and records the symbols of the
+// MarketDataRequests received on each connection
+type fakeLP struct {
+	gw       *FIXGateway
+	session  *LPSession
+	ln       net.Listener
+	mu       sync.Mutex
+	conns    []net.Conn
+	seq      int
+	requests chan string // "<connection index>:<symbol>"
+
+	answerLogout bool // Confirm Logouts with a Logout of our own
+	logouts      int  // Logouts received
+}
+
+func newFakeLP(t *testing.T, gw *FIXGateway, session *LPSession) *fakeLP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	lp := &fakeLP{gw: gw, session: session, ln: ln, requests: make(chan string, 16)}
+	t.Cleanup(func() {
+		ln.Close()
+		lp.mu.Lock()
+		defer lp.mu.Unlock()
+		for _, c := range lp.conns {
+			c.Close()
+		}
+	})
+
+	session.Host = "127.0.0.1"
+	session.Port = ln.Addr().(*net.TCPAddr).Port
+	session.Status = "DISCONNECTED"
+	go lp.serve()
+	return lp
+}
+
+func (lp *fakeLP) serve() {
+	for {
+		conn, err := lp.ln.Accept()
+		if err != nil {
+			return
+		}
+		lp.mu.Lock()
+		index := len(lp.conns)
+		lp.conns = append(lp.conns, conn)
+		lp.mu.Unlock()
+		go lp.handle(index, conn)
+	}
+}
+
+func (lp *fakeLP) handle(index int, conn net.Conn) {
+	buf := make([]byte, 8192)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, msg := range lp.gw.splitFIXMessages(string(buf[:n])) {
+			switch lp.gw.extractTag(msg, "35") {
+			case MsgTypeLogon:
+				lp.mu.Lock()
+				lp.seq++
+				seq := lp.seq
+				lp.mu.Unlock()
+				conn.Write([]byte(inbound(lp.gw, lp.session, MsgTypeLogon, seq, "98=0\x01108=30\x01")))
+			case MsgTypeMarketDataRequest:
+				lp.requests <- fmt.Sprintf("%d:%s", index, lp.gw.extractTag(msg, "55"))
+			case MsgTypeLogout:
+				lp.mu.Lock()
+				lp.logouts++
+				lp.seq++
+				seq, answer := lp.seq, lp.answerLogout
+				lp.mu.Unlock()
+				if answer {
+					conn.Write([]byte(inbound(lp.gw, lp.session, MsgTypeLogout, seq, "")))
+				}
+			}
+		}
+	}
+}
+
+// drop closes a connection from the LP side
+func (lp *fakeLP) drop(index int) {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	lp.conns[index].Close()
+}
+
+func (lp *fakeLP) accepted() int {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	return len(lp.conns)
+}
+
+func (lp *fakeLP) expectRequest(t *testing.T, want string) {
+	t.Helper()
+	select {
+	case got := <-lp.requests:
+		if got != want {
+			t.Fatalf("MarketDataRequest = %s, want %s", got, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("no MarketDataRequest, want %s", want)
+	}
+}
+
+// TestReconnectBackoff tests that the reconnect delay doubles per attempt up to the cap
+func TestReconnectBackoff(t *testing.T) {
+	want := []time.Duration{1, 2, 4, 8, 16, 32, 60, 60}
+	for i, w := range want {
+		attempt := i + 1
+		if got := reconnectBackoff(attempt, time.Second, 60*time.Second); got != w*time.Second {
+			t.Errorf("reconnectBackoff(%d) = %v, want %v", attempt, got, w*time.Second)
+		}
+	}
+}
+
+// TestAutoReconnectResubscribes tests that a session whose connection drops
+// reconnects on its own and replays its market data subscriptions
+func TestAutoReconnectResubscribes(t *testing.T) {
+	gw, session := newTestGateway(t)
+	gw.reconnectBaseDelay = 10 * time.Millisecond
+	lp := newFakeLP(t, gw, session)
+	if err := gw.SetAutoReconnect(session.ID, true); err != nil {
+		t.Fatalf("SetAutoReconnect() error = %v", err)
+	}
+
+	if err := gw.Connect(session.ID); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if err := gw.WaitForLogon(session.ID, 2*time.Second); err != nil {
+		t.Fatalf("WaitForLogon() error = %v", err)
+	}
+	firstReqID, err := gw.SubscribeMarketData(session.ID, "EURUSD")
+	if err != nil {
+		t.Fatalf("SubscribeMarketData() error = %v", err)
+	}
+	lp.expectRequest(t, "0:EURUSD")
+
+	lp.drop(0)
+	lp.expectRequest(t, "1:EURUSD")
+
+	if !gw.IsSymbolSubscribed("EURUSD") {
+		t.Fatal("EURUSD not subscribed after reconnect")
+	}
+	gw.mu.RLock()
+	_, stale := gw.mdSubscriptions[firstReqID]
+	gw.mu.RUnlock()
+	if stale {
+		t.Error("MDReqID from the dropped connection still tracked")
+	}
+}
+
+// TestDisconnectStopsAutoReconnect tests that an explicit Disconnect does not
+// trigger a reconnect
+func TestDisconnectStopsAutoReconnect(t *testing.T) {
+	gw, session := newTestGateway(t)
+	gw.reconnectBaseDelay = 10 * time.Millisecond
+	lp := newFakeLP(t, gw, session)
+	gw.SetAutoReconnect(session.ID, true)
+
+	if err := gw.Connect(session.ID); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if err := gw.WaitForLogon(session.ID, 2*time.Second); err != nil {
+		t.Fatalf("WaitForLogon() error = %v", err)
+	}
+	if err := gw.Disconnect(session.ID); err != nil {
+		t.Fatalf("Disconnect() error = %v", err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if got := lp.accepted(); got != 1 {
+		t.Errorf("connections = %d after Disconnect, want 1", got)
+	}
+}
+
+// TestAutoReconnectGivesUp tests that failed reconnects stop after MaxReconnectAttempts
+func TestAutoReconnectGivesUp(t *testing.T) {
+	gw, session := newTestGateway(t)
+	gw.reconnectBaseDelay = 5 * time.Millisecond
+	gw.SetReconnectBreaker(ReconnectBreakerConfig{})
+
+	// An LP that hangs up before answering the Logon
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer ln.Close()
+	var mu sync.Mutex
+	accepted := 0
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			accepted++
+			mu.Unlock()
+			conn.Close()
+		}
+	}()
+	session.Host = "127.0.0.1"
+	session.Port = ln.Addr().(*net.TCPAddr).Port
+	session.Status = "DISCONNECTED"
+
+	gw.SetAutoReconnect(session.ID, true)
+	gw.SetMaxReconnectAttempts(session.ID, 3)
+	if err := gw.Connect(session.ID); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	time.Sleep(500 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if accepted != 4 {
+		t.Errorf("connection attempts = %d, want 4 (initial + 3 reconnects)", accepted)
+	}
+	if status := gw.GetStatus()[session.ID]; status != "DISCONNECTED" {
+		t.Errorf("status = %s, want DISCONNECTED", status)
+	}
+}
@@ -0,0 +1,92 @@
+package fix
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+// SecurityDefinition is an LP's specification of a symbol (35=d). Zero values
+// mean the LP did not report the field.
+type SecurityDefinition struct {
+	SecurityReqID      string
+	ResponseType       string // SecurityResponseType (323): 1=accepted as is, 5=rejected, 6=cannot match
+	Symbol             string
+	SecurityType       string  // SecurityType (167), e.g. FXSPOT
+	SecurityExchange   string  // SecurityExchange (207)
+	Description        string  // SecurityDesc (107)
+	Currency           string  // Currency (15)
+	TickSize           float64 // MinPriceIncrement (969)
+	ContractMultiplier float64 // ContractMultiplier (231): units per lot
+	MinTradeVol        float64 // MinTradeVol (562), in units
+	RoundLot           float64 // RoundLot (561), in units
+	MaxTradeVol        float64 // MaxTradeVol (1140), in units
+	Text               string
+	SessionID          string
+	Timestamp          time.Time
+}
+
+// Rejected reports whether the LP could not provide the definition
+func (d SecurityDefinition) Rejected() bool {
+	return d.ResponseType == "5" || d.ResponseType == "6"
+}
+
+// Digits returns the decimal places of the tick size, 0 if the LP sent none
+func (d SecurityDefinition) Digits() int {
+	if d.TickSize <= 0 || d.TickSize >= 1 {
+		return 0
+	}
+	return int(math.Round(-math.Log10(d.TickSize)))
+}
+
+// handleSecurityDefinition processes a Security Definition response (35=d)
+func (g *FIXGateway) handleSecurityDefinition(session *LPSession, fields fixFields) {
+	def := SecurityDefinition{
+		SecurityReqID:      fields.get("320"),
+		ResponseType:       fields.get("323"),
+		Symbol:             fields.get("55"),
+		SecurityType:       fields.get("167"),
+		SecurityExchange:   fields.get("207"),
+		Description:        fields.get("107"),
+		Currency:           fields.get("15"),
+		TickSize:           parseFIXFloat(fields.get("969")),
+		ContractMultiplier: parseFIXFloat(fields.get("231")),
+		MinTradeVol:        parseFIXFloat(fields.get("562")),
+		RoundLot:           parseFIXFloat(fields.get("561")),
+		MaxTradeVol:        parseFIXFloat(fields.get("1140")),
+		Text:               fields.get("58"),
+		SessionID:          session.ID,
+		Timestamp:          time.Now(),
+	}
+
+	if def.Rejected() {
+		logger.Warn("SecurityDefinition rejected", logging.Session(session.ID), logging.Symbol(def.Symbol), logging.String("text", def.Text))
+		return
+	}
+
+	logger.Info("SecurityDefinition received", logging.Session(session.ID), logging.Symbol(def.Symbol),
+		logging.Float64("tick_size", def.TickSize), logging.Float64("contract_multiplier", def.ContractMultiplier),
+		logging.Float64("min_trade_vol", def.MinTradeVol), logging.Float64("round_lot", def.RoundLot))
+
+	select {
+	case g.securityDefs <- def:
+	default:
+		logger.Warn("SecurityDefinition channel full, dropping definition", logging.Session(session.ID), logging.Symbol(def.Symbol))
+	}
+}
+
+// GetSecurityDefinitions returns the channel for LP symbol specifications
+func (g *FIXGateway) GetSecurityDefinitions() <-chan SecurityDefinition {
+	return g.securityDefs
+}
+
+// parseFIXFloat parses a numeric field, returning 0 if empty or malformed
+func parseFIXFloat(value string) float64 {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
@@ -0,0 +1,35 @@
+package fix
+
+import "testing"
+
+// TestSecurityDefinitionParsed tests that a 35=d response is parsed onto the definitions channel
+func TestSecurityDefinitionParsed(t *testing.T) {
+	gw, session := newTestGateway(t)
+
+	gw.processMessage(session, nil, inbound(gw, session, MsgTypeSecurityDefinition, 1,
+		"320=SECDEF_EURUSD_1\x01322=R1\x01323=1\x0155=EURUSD\x0115=USD\x01969=0.00001\x01231=100000\x01562=1000\x01561=1000\x011140=50000000\x01"))
+
+	select {
+	case def := <-gw.GetSecurityDefinitions():
+		if def.Symbol != "EURUSD" || def.SecurityReqID != "SECDEF_EURUSD_1" || def.Currency != "USD" {
+			t.Errorf("definition identifiers = %+v", def)
+		}
+		if def.TickSize != 0.00001 || def.ContractMultiplier != 100000 {
+			t.Errorf("TickSize = %g, ContractMultiplier = %g; want 0.00001 / 100000", def.TickSize, def.ContractMultiplier)
+		}
+		if def.MinTradeVol != 1000 || def.RoundLot != 1000 || def.MaxTradeVol != 50000000 {
+			t.Errorf("lot fields = %g / %g / %g", def.MinTradeVol, def.RoundLot, def.MaxTradeVol)
+		}
+	default:
+		t.Fatal("no SecurityDefinition published")
+	}
+
+	// A rejected request publishes nothing
+	gw.processMessage(session, nil, inbound(gw, session, MsgTypeSecurityDefinition, 2,
+		"320=SECDEF_FOO_1\x01323=6\x0155=FOO\x0158=Unknown symbol\x01"))
+	select {
+	case def := <-gw.GetSecurityDefinitions():
+		t.Errorf("rejected definition published: %+v", def)
+	default:
+	}
+}
@@ -0,0 +1,107 @@
+package fix
+
+import (
+	"time"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+// handleSecurityList processes a SecurityList response (35=y), storing the
+// instruments the LP offers. Fragments of the same request (LastFragment 893=N)
+// are appended; a new request replaces the session's list.
+func (g *FIXGateway) handleSecurityList(session *LPSession, fields fixFields) {
+	reqID := fields.get("320")
+	result := fields.get("560") // SecurityRequestResult: 0=valid
+	if result != "" && result != "0" {
+		logger.Warn("SecurityList request rejected", logging.Session(session.ID), logging.String("security_req_id", reqID),
+			logging.String("result", result), logging.String("text", fields.get("58")))
+		return
+	}
+
+	defs := parseSecurityList(fields, session.ID, time.Now())
+
+	g.mu.Lock()
+	if g.securityListReqs[session.ID] == reqID {
+		g.securities[session.ID] = append(g.securities[session.ID], defs...)
+	} else {
+		g.securities[session.ID] = defs
+		g.securityListReqs[session.ID] = reqID
+	}
+	total := len(g.securities[session.ID])
+	g.mu.Unlock()
+
+	logger.Info("SecurityList received", logging.Session(session.ID), logging.Int("securities", len(defs)),
+		logging.Int("known", total), logging.String("expected", fields.get("393")), logging.String("last_fragment", fields.get("893")))
+}
+
+// parseSecurityList parses the NoRelatedSym (146) repeating group of a
+// SecurityList. Each instrument starts at its Symbol (55).
+func parseSecurityList(fields fixFields, sessionID string, at time.Time) []SecurityDefinition {
+	var defs []SecurityDefinition
+	var current *SecurityDefinition
+	reqID := ""
+	inGroup := false
+
+	for _, field := range fields.ordered {
+		tag, value := field.tag, field.value
+		if tag == "320" {
+			reqID = value
+		}
+		if tag == "146" {
+			inGroup = true
+			continue
+		}
+		if !inGroup {
+			continue
+		}
+
+		if tag == "55" {
+			defs = append(defs, SecurityDefinition{
+				SecurityReqID: reqID,
+				Symbol:        value,
+				SessionID:     sessionID,
+				Timestamp:     at,
+			})
+			current = &defs[len(defs)-1]
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		switch tag {
+		case "167":
+			current.SecurityType = value
+		case "207":
+			current.SecurityExchange = value
+		case "107":
+			current.Description = value
+		case "15":
+			current.Currency = value
+		case "969":
+			current.TickSize = parseFIXFloat(value)
+		case "231":
+			current.ContractMultiplier = parseFIXFloat(value)
+		case "562":
+			current.MinTradeVol = parseFIXFloat(value)
+		case "561":
+			current.RoundLot = parseFIXFloat(value)
+		case "1140":
+			current.MaxTradeVol = parseFIXFloat(value)
+		}
+	}
+	return defs
+}
+
+// GetSecurities returns the instruments a session's LP reported in its last
+// SecurityList, or nil if none has been received
+func (g *FIXGateway) GetSecurities(sessionID string) []SecurityDefinition {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	securities := g.securities[sessionID]
+	if len(securities) == 0 {
+		return nil
+	}
+	return append([]SecurityDefinition(nil), securities...)
+}
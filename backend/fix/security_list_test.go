@@ -0,0 +1,67 @@
+package fix
+
+import (
+	"testing"
+)
+
+// TestSecurityListParsed tests that every instrument of the NoRelatedSym group
+// is stored with its own fields
+func TestSecurityListParsed(t *testing.T) {
+	gw, session := newTestGateway(t)
+
+	gw.processMessage(session, nil, inbound(gw, session, MsgTypeSecurityList, 1,
+		"320=SL1\x01322=R1\x01560=0\x01393=3\x01146=3\x01"+
+			"55=EURUSD\x01167=FXSPOT\x01207=YOFX\x0115=USD\x01969=0.00001\x01"+
+			"55=XAUUSD.x\x01167=METAL\x01107=Gold Spot\x01969=0.01\x01231=100\x01"+
+			"55=US30\x01167=CFD\x01"))
+
+	securities := gw.GetSecurities(session.ID)
+	if len(securities) != 3 {
+		t.Fatalf("GetSecurities() returned %d securities, want 3: %+v", len(securities), securities)
+	}
+
+	eur := securities[0]
+	if eur.Symbol != "EURUSD" || eur.SecurityType != "FXSPOT" || eur.SecurityExchange != "YOFX" ||
+		eur.Currency != "USD" || eur.TickSize != 0.00001 || eur.SecurityReqID != "SL1" || eur.SessionID != session.ID {
+		t.Errorf("EURUSD = %+v", eur)
+	}
+	gold := securities[1]
+	if gold.Symbol != "XAUUSD.x" || gold.SecurityType != "METAL" || gold.Description != "Gold Spot" ||
+		gold.TickSize != 0.01 || gold.ContractMultiplier != 100 || gold.SecurityExchange != "" {
+		t.Errorf("XAUUSD.x = %+v", gold)
+	}
+	if securities[2].Symbol != "US30" || securities[2].TickSize != 0 {
+		t.Errorf("US30 = %+v", securities[2])
+	}
+}
+
+// TestSecurityListFragments tests that fragments of one request accumulate and
+// a new request replaces the list
+func TestSecurityListFragments(t *testing.T) {
+	gw, session := newTestGateway(t)
+
+	gw.processMessage(session, nil, inbound(gw, session, MsgTypeSecurityList, 1,
+		"320=SL1\x01560=0\x01893=N\x01146=1\x0155=EURUSD\x01"))
+	gw.processMessage(session, nil, inbound(gw, session, MsgTypeSecurityList, 2,
+		"320=SL1\x01560=0\x01893=Y\x01146=1\x0155=GBPUSD\x01"))
+	if got := len(gw.GetSecurities(session.ID)); got != 2 {
+		t.Fatalf("securities after two fragments = %d, want 2", got)
+	}
+
+	gw.processMessage(session, nil, inbound(gw, session, MsgTypeSecurityList, 3,
+		"320=SL2\x01560=0\x01146=1\x0155=USDJPY\x01"))
+	securities := gw.GetSecurities(session.ID)
+	if len(securities) != 1 || securities[0].Symbol != "USDJPY" {
+		t.Errorf("securities after a new request = %+v, want only USDJPY", securities)
+	}
+
+	// A rejected request keeps the previous list
+	gw.processMessage(session, nil, inbound(gw, session, MsgTypeSecurityList, 4,
+		"320=SL3\x01560=2\x0158=Not authorized\x01"))
+	if got := len(gw.GetSecurities(session.ID)); got != 1 {
+		t.Errorf("securities after a rejected request = %d, want 1", got)
+	}
+	if got := gw.GetSecurities("OTHER"); got != nil {
+		t.Errorf("GetSecurities() of a session without a list = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,170 @@
+package fix
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+// Trading session states reported in TradSesStatus (340)
+const (
+	TradSesStatusUnknown  = "UNKNOWN"
+	TradSesStatusHalted   = "HALTED"
+	TradSesStatusOpen     = "OPEN"
+	TradSesStatusClosed   = "CLOSED"
+	TradSesStatusPreOpen  = "PRE_OPEN"
+	TradSesStatusPreClose = "PRE_CLOSE"
+	TradSesStatusRejected = "REQUEST_REJECTED"
+)
+
+// TradingSessionStatus is an LP report of a market or symbol session state (35=h)
+type TradingSessionStatus struct {
+	TradSesReqID     string
+	TradingSessionID string // Market/venue session, e.g. "FX"
+	Symbol           string // Set when the LP reports per-symbol sessions
+	Status           string // One of the TradSesStatus* constants
+	RawStatus        string // Tag 340 as received
+	StartTime        time.Time
+	OpenTime         time.Time
+	CloseTime        time.Time
+	Text             string
+	SessionID        string
+	Timestamp        time.Time
+}
+
+// tradSesStatusName maps a TradSesStatus (340) code to its state name
+func tradSesStatusName(code string) string {
+	switch code {
+	case "1":
+		return TradSesStatusHalted
+	case "2":
+		return TradSesStatusOpen
+	case "3":
+		return TradSesStatusClosed
+	case "4":
+		return TradSesStatusPreOpen
+	case "5":
+		return TradSesStatusPreClose
+	case "6":
+		return TradSesStatusRejected
+	default:
+		return TradSesStatusUnknown
+	}
+}
+
+// RequestTradingSessionStatus requests the state of a trading session (35=g).
+// An empty tradingSessionID asks for all sessions; subscribe requests updates
+// on every state change rather than a single snapshot.
+func (g *FIXGateway) RequestTradingSessionStatus(sessionID string, tradingSessionID string, subscribe bool) (string, error) {
+	g.mu.RLock()
+	session, ok := g.sessions[sessionID]
+	g.mu.RUnlock()
+
+	if !ok {
+		return "", fmt.Errorf("session not found: %s", sessionID)
+	}
+
+	if session.Status != "LOGGED_IN" {
+		return "", fmt.Errorf("session not logged in: %s", session.Status)
+	}
+
+	g.mu.RLock()
+	conn := session.conn
+	g.mu.RUnlock()
+
+	if conn == nil {
+		return "", fmt.Errorf("connection not available")
+	}
+
+	tradSesReqID := fmt.Sprintf("TRADSES_%d", time.Now().UnixNano())
+
+	g.mu.Lock()
+	msgSeqNum := g.getNextOutSeqNum(session)
+	g.mu.Unlock()
+
+	fullMsg := g.buildMessage(session, tradingSessionStatusRequestBody(session, msgSeqNum, tradSesReqID, tradingSessionID, subscribe))
+	g.storeMessage(session, msgSeqNum, fullMsg)
+
+	err := g.writeMessage(session, conn, fullMsg)
+	if err != nil {
+		return "", fmt.Errorf("failed to send trading session status request: %v", err)
+	}
+
+	logger.Info("Sent TradingSessionStatusRequest", logging.Session(session.ID), logging.Seq(msgSeqNum),
+		logging.String("trad_ses_req_id", tradSesReqID), logging.String("trading_session_id", tradingSessionID))
+
+	return tradSesReqID, nil
+}
+
+// tradingSessionStatusRequestBody builds the body of a Trading Session Status Request (35=g)
+func tradingSessionStatusRequestBody(session *LPSession, msgSeqNum int, tradSesReqID, tradingSessionID string, subscribe bool) string {
+	sendingTime := time.Now().UTC().Format("20060102-15:04:05.000")
+
+	// 263 = SubscriptionRequestType: 0=Snapshot, 1=Snapshot+Updates
+	subType := "0"
+	if subscribe {
+		subType = "1"
+	}
+
+	body := fmt.Sprintf("35=%s\x01"+
+		"49=%s\x01"+
+		"56=%s\x01"+
+		"34=%d\x01"+
+		"52=%s\x01"+
+		"335=%s\x01", // TradSesReqID
+		MsgTypeTradingSessionStatusReq,
+		session.SenderCompID,
+		session.TargetCompID,
+		msgSeqNum,
+		sendingTime,
+		tradSesReqID,
+	)
+	if tradingSessionID != "" {
+		body += "336=" + tradingSessionID + "\x01" // TradingSessionID
+	}
+	body += "263=" + subType + "\x01"
+	return body
+}
+
+// handleTradingSessionStatus processes a Trading Session Status report (35=h)
+func (g *FIXGateway) handleTradingSessionStatus(session *LPSession, fields fixFields) {
+	rawStatus := fields.get("340")
+	status := TradingSessionStatus{
+		TradSesReqID:     fields.get("335"),
+		TradingSessionID: fields.get("336"),
+		Symbol:           fields.get("55"),
+		Status:           tradSesStatusName(rawStatus),
+		RawStatus:        rawStatus,
+		StartTime:        parseFIXTime(fields.get("341")),
+		OpenTime:         parseFIXTime(fields.get("342")),
+		CloseTime:        parseFIXTime(fields.get("344")),
+		Text:             fields.get("58"),
+		SessionID:        session.ID,
+		Timestamp:        time.Now(),
+	}
+
+	logger.Info("TradingSessionStatus received", logging.Session(session.ID), logging.Symbol(status.Symbol),
+		logging.String("trading_session_id", status.TradingSessionID), logging.String("status", status.Status))
+
+	select {
+	case g.tradingSessions <- status:
+	default:
+		logger.Warn("TradingSessionStatus channel full, dropping status", logging.Session(session.ID), logging.String("trading_session_id", status.TradingSessionID))
+	}
+}
+
+// GetTradingSessionStatuses returns the channel for trading session status reports
+func (g *FIXGateway) GetTradingSessionStatuses() <-chan TradingSessionStatus {
+	return g.tradingSessions
+}
+
+// parseFIXTime parses a UTCTimestamp field, returning the zero time if empty or malformed
+func parseFIXTime(value string) time.Time {
+	for _, layout := range []string{"20060102-15:04:05.000", "20060102-15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,71 @@
+package fix
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTradingSessionStatusRequestMessage tests the fields of a built Trading Session Status Request
+func TestTradingSessionStatusRequestMessage(t *testing.T) {
+	gw, session := newTestGateway(t)
+
+	msg := gw.buildMessage(session, tradingSessionStatusRequestBody(session, 7, "TRADSES_1", "FX", true))
+	if err := gw.validateMessage(msg); err != nil {
+		t.Fatalf("built message is invalid: %v", err)
+	}
+
+	tests := []struct {
+		tag, want string
+	}{
+		{"35", MsgTypeTradingSessionStatusReq},
+		{"34", "7"},
+		{"335", "TRADSES_1"},
+		{"336", "FX"},
+		{"263", "1"},
+	}
+	for _, tt := range tests {
+		if got := gw.extractTag(msg, tt.tag); got != tt.want {
+			t.Errorf("tag %s = %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+
+	// All sessions, snapshot only
+	msg = gw.buildMessage(session, tradingSessionStatusRequestBody(session, 8, "TRADSES_2", "", false))
+	if strings.Contains(msg, "\x01336=") {
+		t.Error("TradingSessionID should be omitted when requesting all sessions")
+	}
+	if got := gw.extractTag(msg, "263"); got != "0" {
+		t.Errorf("SubscriptionRequestType = %q, want 0", got)
+	}
+}
+
+// TestTradingSessionStatusParsed tests that a 35=h report is parsed onto the status channel
+func TestTradingSessionStatusParsed(t *testing.T) {
+	gw, session := newTestGateway(t)
+
+	gw.processMessage(session, nil, inbound(gw, session, MsgTypeTradingSessionStatus, 1,
+		"335=TRADSES_1\x01336=FX\x0155=EURUSD\x01340=1\x01344=20240105-22:00:00\x0158=News halt\x01"))
+
+	select {
+	case st := <-gw.GetTradingSessionStatuses():
+		if st.Symbol != "EURUSD" || st.TradingSessionID != "FX" || st.TradSesReqID != "TRADSES_1" {
+			t.Errorf("status identifiers = %+v", st)
+		}
+		if st.Status != TradSesStatusHalted || st.RawStatus != "1" {
+			t.Errorf("Status = %s (%s), want %s (1)", st.Status, st.RawStatus, TradSesStatusHalted)
+		}
+		if want := time.Date(2024, 1, 5, 22, 0, 0, 0, time.UTC); !st.CloseTime.Equal(want) {
+			t.Errorf("CloseTime = %v, want %v", st.CloseTime, want)
+		}
+		if st.Text != "News halt" || st.SessionID != session.ID {
+			t.Errorf("Text/SessionID = %q/%q", st.Text, st.SessionID)
+		}
+	default:
+		t.Fatal("no trading session status received")
+	}
+
+	if got := gw.GetMessageStats()[session.ID].Received["TradingSessionStatus"]; got != 1 {
+		t.Errorf("Received[TradingSessionStatus] = %d, want 1", got)
+	}
+}
@@ -0,0 +1,54 @@
+package fix
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+// Shutdown logs out every logged in session so the LPs see a clean end of
+// session instead of a dropped connection. It waits for each Logout to be
+// confirmed until ctx is done, then persists sequence numbers and closes all
+// connections. Returns ctx's error if some LP had not confirmed by then.
+func (g *FIXGateway) Shutdown(ctx context.Context) error {
+	g.mu.Lock()
+	acks := make(map[string]chan struct{})
+	for id, session := range g.sessions {
+		session.manualDisconnect = true
+		g.cancelReconnectUnlocked(session)
+		if session.Status != "LOGGED_IN" || session.conn == nil {
+			continue
+		}
+		ack := make(chan struct{})
+		session.logoutAck = ack
+		g.sendLogoutUnlocked(session)
+		acks[id] = ack
+	}
+	g.mu.Unlock()
+
+	var err error
+	for id, ack := range acks {
+		select {
+		case <-ack:
+			continue
+		case <-ctx.Done():
+			err = fmt.Errorf("shutdown before %s confirmed Logout: %w", id, ctx.Err())
+		}
+		break
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for id, session := range g.sessions {
+		session.logoutAck = nil
+		if session.conn != nil {
+			g.disconnectUnlocked(session, "gateway shutdown")
+		}
+		if _, ok := acks[id]; ok {
+			g.saveSequenceNumbers(session)
+		}
+	}
+	logger.Info("Gateway shut down", logging.Int("sessions_logged_out", len(acks)))
+	return err
+}
@@ -0,0 +1,89 @@
+package fix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// loggedInFakeLP connects session to a fake LP and waits for the logon
+func loggedInFakeLP(t *testing.T, answerLogout bool) (*FIXGateway, *LPSession, *fakeLP) {
+	t.Helper()
+	gw, session := newTestGateway(t)
+	gw.reconnectBaseDelay = 10 * time.Millisecond
+	lp := newFakeLP(t, gw, session)
+	lp.answerLogout = answerLogout
+	gw.SetAutoReconnect(session.ID, true)
+
+	if err := gw.Connect(session.ID); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if err := gw.WaitForLogon(session.ID, 2*time.Second); err != nil {
+		t.Fatalf("WaitForLogon() error = %v", err)
+	}
+	return gw, session, lp
+}
+
+// TestShutdownLogsOutSessions tests that Shutdown sends one Logout, waits for
+// the LP to confirm it and leaves the session disconnected for good
+func TestShutdownLogsOutSessions(t *testing.T) {
+	gw, session, lp := loggedInFakeLP(t, true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := gw.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	gw.mu.RLock()
+	status, conn := session.Status, session.conn
+	want := fmt.Sprintf("%d:%d", session.OutSeqNum, session.InSeqNum)
+	gw.mu.RUnlock()
+	if status != "DISCONNECTED" || conn != nil {
+		t.Errorf("session %s with connection %v after Shutdown, want DISCONNECTED and closed", status, conn)
+	}
+
+	// The stored sequence numbers include both Logouts
+	stored, err := os.ReadFile(filepath.Join(session.storeDir, session.ID+".seqnums"))
+	if err != nil || string(stored) != want {
+		t.Errorf("stored sequence numbers = %q (%v), want %q", stored, err, want)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	lp.mu.Lock()
+	logouts := lp.logouts
+	lp.mu.Unlock()
+	if logouts != 1 {
+		t.Errorf("LP received %d Logouts, want 1", logouts)
+	}
+	if got := lp.accepted(); got != 1 {
+		t.Errorf("connections = %d after Shutdown, want no reconnect", got)
+	}
+}
+
+// TestShutdownRespectsDeadline tests that an LP that never confirms does not
+// hold up Shutdown past the context deadline
+func TestShutdownRespectsDeadline(t *testing.T) {
+	gw, session, _ := loggedInFakeLP(t, false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := gw.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Shutdown() took %v past a 50ms deadline", elapsed)
+	}
+
+	gw.mu.RLock()
+	defer gw.mu.RUnlock()
+	if session.Status != "DISCONNECTED" || session.conn != nil {
+		t.Errorf("session %s after Shutdown, want DISCONNECTED and closed", session.Status)
+	}
+}
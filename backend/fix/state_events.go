@@ -0,0 +1,45 @@
+package fix
+
+import (
+	"time"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+// SessionStateEvent reports a session moving between connection states
+// (DISCONNECTED, CONNECTING, CONNECTED, LOGGED_IN)
+type SessionStateEvent struct {
+	SessionID string
+	OldStatus string
+	NewStatus string
+	Timestamp time.Time
+	Reason    string // Why the state changed, e.g. "logon accepted" or the read error
+}
+
+// StateEvents returns the channel of session connection state changes
+func (g *FIXGateway) StateEvents() <-chan SessionStateEvent {
+	return g.stateEvents
+}
+
+// setStatusUnlocked moves a session to status and emits a state event if it
+// changed. Never blocks, so a full channel drops the event (caller must hold lock).
+func (g *FIXGateway) setStatusUnlocked(session *LPSession, status, reason string) {
+	if session.Status == status {
+		return
+	}
+	event := SessionStateEvent{
+		SessionID: session.ID,
+		OldStatus: session.Status,
+		NewStatus: status,
+		Timestamp: time.Now(),
+		Reason:    reason,
+	}
+	session.Status = status
+
+	select {
+	case g.stateEvents <- event:
+	default:
+		logger.Warn("State event channel full, dropping event", logging.Session(session.ID),
+			logging.String("old_status", event.OldStatus), logging.String("new_status", status))
+	}
+}
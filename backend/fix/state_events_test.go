@@ -0,0 +1,88 @@
+package fix
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// nextStateEvent returns the next state change or fails after a second
+func nextStateEvent(t *testing.T, gw *FIXGateway) SessionStateEvent {
+	t.Helper()
+	select {
+	case event := <-gw.StateEvents():
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no state event")
+		return SessionStateEvent{}
+	}
+}
+
+// TestStateEventsFollowSessionLifecycle tests that connect, logon and a Logout
+// from the LP each emit a state change with its reason
+func TestStateEventsFollowSessionLifecycle(t *testing.T) {
+	gw, session := newTestGateway(t)
+	lp := newFakeLP(t, gw, session)
+
+	if err := gw.Connect(session.ID); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if err := gw.WaitForLogon(session.ID, 2*time.Second); err != nil {
+		t.Fatalf("WaitForLogon() error = %v", err)
+	}
+
+	lp.mu.Lock()
+	lp.seq++
+	lp.conns[0].Write([]byte(inbound(gw, session, MsgTypeLogout, lp.seq, "58=End of day\x01")))
+	lp.mu.Unlock()
+
+	want := []SessionStateEvent{
+		{OldStatus: "DISCONNECTED", NewStatus: "CONNECTING", Reason: "connect requested"},
+		{OldStatus: "CONNECTING", NewStatus: "CONNECTED", Reason: "TCP connected"},
+		{OldStatus: "CONNECTED", NewStatus: "LOGGED_IN", Reason: "logon accepted"},
+		{OldStatus: "LOGGED_IN", NewStatus: "DISCONNECTED", Reason: "logout received: End of day"},
+	}
+	for _, w := range want {
+		got := nextStateEvent(t, gw)
+		if got.SessionID != session.ID || got.OldStatus != w.OldStatus || got.NewStatus != w.NewStatus || got.Reason != w.Reason {
+			t.Errorf("event = %+v, want %s %s -> %s (%s)", got, session.ID, w.OldStatus, w.NewStatus, w.Reason)
+		}
+		if got.Timestamp.IsZero() {
+			t.Errorf("event %s -> %s has no timestamp", got.OldStatus, got.NewStatus)
+		}
+	}
+}
+
+// TestStateEventsOnConnectFailure tests that a refused connection reports the error
+func TestStateEventsOnConnectFailure(t *testing.T) {
+	gw, session := newTestGateway(t)
+	session.Host = "127.0.0.1"
+	session.Port = 1 // Nothing listens here
+	session.Status = "DISCONNECTED"
+
+	if err := gw.Connect(session.ID); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if got := nextStateEvent(t, gw); got.NewStatus != "CONNECTING" {
+		t.Fatalf("first event = %+v, want CONNECTING", got)
+	}
+	got := nextStateEvent(t, gw)
+	if got.OldStatus != "CONNECTING" || got.NewStatus != "DISCONNECTED" || !strings.HasPrefix(got.Reason, "connect failed") {
+		t.Errorf("event = %+v, want CONNECTING -> DISCONNECTED on connect failure", got)
+	}
+}
+
+// TestStateEventsSkipUnchangedStatus tests that setting the current status emits nothing
+func TestStateEventsSkipUnchangedStatus(t *testing.T) {
+	gw, session := newTestGateway(t)
+
+	gw.mu.Lock()
+	gw.setStatusUnlocked(session, "LOGGED_IN", "logon accepted")
+	gw.mu.Unlock()
+
+	select {
+	case event := <-gw.StateEvents():
+		t.Errorf("unexpected event %+v", event)
+	default:
+	}
+}
@@ -0,0 +1,209 @@
+package fix
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MessageStats holds FIX message counters for a single session
+type MessageStats struct {
+	SessionID              string           `json:"sessionId"`
+	Sent                   map[string]int64 `json:"sent"`     // Message type name -> count
+	Received               map[string]int64 `json:"received"` // Message type name -> count
+	TotalSent              int64            `json:"totalSent"`
+	TotalReceived          int64            `json:"totalReceived"`
+	LastSentAt             time.Time        `json:"lastSentAt,omitempty"`
+	LastReceivedAt         time.Time        `json:"lastReceivedAt,omitempty"`
+	ResendRequestsSent     int64            `json:"resendRequestsSent"`
+	ResendRequestsReceived int64            `json:"resendRequestsReceived"`
+	MessagesResent         int64            `json:"messagesResent"`
+	GapFillsSent           int64            `json:"gapFillsSent"`
+}
+
+// sessionStatsTracker tracks message counters for all sessions
+type sessionStatsTracker struct {
+	mu    sync.RWMutex
+	stats map[string]*MessageStats
+}
+
+func newSessionStatsTracker() *sessionStatsTracker {
+	return &sessionStatsTracker{
+		stats: make(map[string]*MessageStats),
+	}
+}
+
+// get returns the stats entry for a session, creating it if needed (caller must hold lock)
+func (t *sessionStatsTracker) get(sessionID string) *MessageStats {
+	s, ok := t.stats[sessionID]
+	if !ok {
+		s = &MessageStats{
+			SessionID: sessionID,
+			Sent:      make(map[string]int64),
+			Received:  make(map[string]int64),
+		}
+		t.stats[sessionID] = s
+	}
+	return s
+}
+
+func (t *sessionStatsTracker) recordSent(sessionID, msgType string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.get(sessionID)
+	s.Sent[MsgTypeName(msgType)]++
+	s.TotalSent++
+	s.LastSentAt = time.Now()
+
+	switch msgType {
+	case MsgTypeResendRequest:
+		s.ResendRequestsSent++
+	}
+}
+
+func (t *sessionStatsTracker) recordReceived(sessionID, msgType string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.get(sessionID)
+	s.Received[MsgTypeName(msgType)]++
+	s.TotalReceived++
+	s.LastReceivedAt = time.Now()
+
+	if msgType == MsgTypeResendRequest {
+		s.ResendRequestsReceived++
+	}
+}
+
+func (t *sessionStatsTracker) recordResent(sessionID string, gapFill bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.get(sessionID)
+	if gapFill {
+		s.GapFillsSent++
+	} else {
+		s.MessagesResent++
+	}
+}
+
+// snapshot returns a deep copy of all session stats
+func (t *sessionStatsTracker) snapshot() map[string]MessageStats {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	result := make(map[string]MessageStats, len(t.stats))
+	for id, s := range t.stats {
+		c := *s
+		c.Sent = make(map[string]int64, len(s.Sent))
+		for k, v := range s.Sent {
+			c.Sent[k] = v
+		}
+		c.Received = make(map[string]int64, len(s.Received))
+		for k, v := range s.Received {
+			c.Received[k] = v
+		}
+		result[id] = c
+	}
+	return result
+}
+
+// MsgTypeName returns a readable name for a FIX MsgType (35) value
+func MsgTypeName(msgType string) string {
+	switch msgType {
+	case MsgTypeLogon:
+		return "Logon"
+	case MsgTypeLogout:
+		return "Logout"
+	case MsgTypeHeartbeat:
+		return "Heartbeat"
+	case MsgTypeTestRequest:
+		return "TestRequest"
+	case MsgTypeResendRequest:
+		return "ResendRequest"
+	case MsgTypeReject:
+		return "Reject"
+	case MsgTypeSequenceReset:
+		return "SequenceReset"
+	case MsgTypeBusinessReject:
+		return "BusinessMessageReject"
+	case MsgTypeNewOrderSingle:
+		return "NewOrderSingle"
+	case MsgTypeOrderCancelRequest:
+		return "OrderCancelRequest"
+	case MsgTypeOrderCancelReject:
+		return "OrderCancelReject"
+	case MsgTypeOrderStatusRequest:
+		return "OrderStatusRequest"
+	case MsgTypeExecutionReport:
+		return "ExecutionReport"
+	case MsgTypeOrderMassStatusReq:
+		return "OrderMassStatusRequest"
+	case MsgTypeMarketDataRequest:
+		return "MarketDataRequest"
+	case MsgTypeMarketDataSnapshot:
+		return "MarketDataSnapshot"
+	case MsgTypeMarketDataIncremental:
+		return "MarketDataIncremental"
+	case MsgTypeMarketDataReject:
+		return "MarketDataReject"
+	case MsgTypeRequestForPositions:
+		return "RequestForPositions"
+	case MsgTypeRequestForPositionsAck:
+		return "RequestForPositionsAck"
+	case MsgTypePositionReport:
+		return "PositionReport"
+	case MsgTypeTradeCaptureReportReq:
+		return "TradeCaptureReportRequest"
+	case MsgTypeTradeCaptureReportAck:
+		return "TradeCaptureReportAck"
+	case MsgTypeTradeCaptureReport:
+		return "TradeCaptureReport"
+	case MsgTypeSecurityListRequest:
+		return "SecurityListRequest"
+	case MsgTypeSecurityList:
+		return "SecurityList"
+	case MsgTypeSecurityDefinitionReq:
+		return "SecurityDefinitionRequest"
+	case MsgTypeSecurityDefinition:
+		return "SecurityDefinition"
+	case MsgTypeTradingSessionStatusReq:
+		return "TradingSessionStatusRequest"
+	case MsgTypeTradingSessionStatus:
+		return "TradingSessionStatus"
+	case "":
+		return "Unknown"
+	default:
+		return "Type_" + msgType
+	}
+}
+
+// writeMessage sends a complete FIX message and records it in the session stats
+func (g *FIXGateway) writeMessage(session *LPSession, conn net.Conn, msg string) error {
+	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write([]byte(msg)); err != nil {
+		return err
+	}
+	g.stats.recordSent(session.ID, outgoingMsgType(msg))
+	return nil
+}
+
+// outgoingMsgType extracts tag 35 from a message built by buildMessage
+func outgoingMsgType(msg string) string {
+	idx := strings.Index(msg, "\x0135=")
+	if idx == -1 {
+		return ""
+	}
+	rest := msg[idx+4:]
+	if end := strings.IndexByte(rest, '\x01'); end != -1 {
+		return rest[:end]
+	}
+	return rest
+}
+
+// GetMessageStats returns per-session FIX message counters
+func (g *FIXGateway) GetMessageStats() map[string]MessageStats {
+	return g.stats.snapshot()
+}
@@ -0,0 +1,117 @@
+package fix
+
+import (
+	"fmt"
+	"net"
+	"testing"
+)
+
+func newTestGateway(t *testing.T) (*FIXGateway, *LPSession) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("FIX_STORE_DIR", dir)
+
+	gw := NewFIXGateway()
+	session := &LPSession{
+		ID:           "TEST",
+		Name:         "Test LP",
+		SenderCompID: "BROKER",
+		TargetCompID: "LP",
+		BeginString:  "FIX.4.4",
+		Status:       "LOGGED_IN",
+		msgStore:     make(map[int]string),
+		storeDir:     dir,
+	}
+	gw.sessions[session.ID] = session
+	return gw, session
+}
+
+// inbound builds a message from the LP with the given type, sequence number and extra fields
+func inbound(gw *FIXGateway, session *LPSession, msgType string, seq int, fields string) string {
+	body := fmt.Sprintf("35=%s\x0149=LP\x0156=BROKER\x0134=%d\x0152=20240101-00:00:00.000\x01%s",
+		msgType, seq, fields)
+	return gw.buildMessage(session, body)
+}
+
+// TestMessageStatsCountsByType tests that processed messages increment the matching per-type counters
+func TestMessageStatsCountsByType(t *testing.T) {
+	gw, session := newTestGateway(t)
+
+	msgs := []string{
+		inbound(gw, session, MsgTypeHeartbeat, 1, ""),
+		inbound(gw, session, MsgTypeHeartbeat, 2, ""),
+		inbound(gw, session, MsgTypeExecutionReport, 3, "37=ORD1\x0111=CL1\x0117=EX1\x01150=0\x0139=0\x0155=EURUSD\x0154=1\x01"),
+		inbound(gw, session, MsgTypeMarketDataSnapshot, 4, "262=MD1\x0155=EURUSD\x01268=2\x01269=0\x01270=1.1000\x01269=1\x01270=1.1002\x01"),
+		inbound(gw, session, MsgTypeMarketDataSnapshot, 5, "262=MD1\x0155=EURUSD\x01268=2\x01269=0\x01270=1.1001\x01269=1\x01270=1.1003\x01"),
+		inbound(gw, session, MsgTypeMarketDataSnapshot, 6, "262=MD1\x0155=EURUSD\x01268=2\x01269=0\x01270=1.1002\x01269=1\x01270=1.1004\x01"),
+		inbound(gw, session, MsgTypeReject, 7, "45=3\x0158=Invalid tag\x01"),
+		inbound(gw, session, MsgTypeBusinessReject, 8, "372=D\x01380=3\x0158=Unsupported\x01"),
+		inbound(gw, session, MsgTypeMarketDataReject, 9, "262=MD2\x01281=0\x0158=Unknown symbol\x01"),
+	}
+	for _, msg := range msgs {
+		gw.processMessage(session, nil, msg)
+	}
+
+	stats, ok := gw.GetMessageStats()[session.ID]
+	if !ok {
+		t.Fatal("no stats recorded for session")
+	}
+
+	tests := []struct {
+		name string
+		want int64
+	}{
+		{"Heartbeat", 2},
+		{"ExecutionReport", 1},
+		{"MarketDataSnapshot", 3},
+		{"Reject", 1},
+		{"BusinessMessageReject", 1},
+		{"MarketDataReject", 1},
+		{"Logon", 0},
+	}
+	for _, tt := range tests {
+		if got := stats.Received[tt.name]; got != tt.want {
+			t.Errorf("Received[%s] = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+	if stats.TotalReceived != int64(len(msgs)) {
+		t.Errorf("TotalReceived = %d, want %d", stats.TotalReceived, len(msgs))
+	}
+	if stats.LastReceivedAt.IsZero() {
+		t.Error("LastReceivedAt should be set")
+	}
+	if stats.TotalSent != 0 {
+		t.Errorf("TotalSent = %d, want 0", stats.TotalSent)
+	}
+}
+
+// TestMessageStatsCountsSent tests that replies written to the LP are counted as sent
+func TestMessageStatsCountsSent(t *testing.T) {
+	gw, session := newTestGateway(t)
+
+	local, remote := net.Pipe()
+	defer local.Close()
+	defer remote.Close()
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			if _, err := remote.Read(buf); err != nil {
+				return
+			}
+		}
+	}()
+
+	// TestRequest is answered with a Heartbeat
+	gw.processMessage(session, local, inbound(gw, session, MsgTypeTestRequest, 1, "112=PING\x01"))
+
+	stats := gw.GetMessageStats()[session.ID]
+	if got := stats.Received["TestRequest"]; got != 1 {
+		t.Errorf("Received[TestRequest] = %d, want 1", got)
+	}
+	if got := stats.Sent["Heartbeat"]; got != 1 {
+		t.Errorf("Sent[Heartbeat] = %d, want 1", got)
+	}
+	if stats.LastSentAt.IsZero() {
+		t.Error("LastSentAt should be set")
+	}
+}
@@ -0,0 +1,105 @@
+package fix
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+// subscriptionFile returns where a session's market data subscriptions are persisted
+func subscriptionFile(session *LPSession) string {
+	return filepath.Join(session.storeDir, session.ID+".subs")
+}
+
+// readSubscriptionFile reads one symbol per line. A missing file is an empty list.
+func readSubscriptionFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var symbols []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if symbol := strings.TrimSpace(scanner.Text()); symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols, scanner.Err()
+}
+
+// loadSubscriptions reads a session's persisted subscriptions so later changes
+// are written on top of them
+func (g *FIXGateway) loadSubscriptions(session *LPSession) {
+	symbols, err := readSubscriptionFile(subscriptionFile(session))
+	if err != nil {
+		logger.Error("Failed to load subscriptions", err, logging.Session(session.ID))
+		return
+	}
+	for _, symbol := range symbols {
+		g.addSavedSubscriptionUnlocked(session.ID, symbol)
+	}
+	if len(symbols) > 0 {
+		logger.Info("Loaded persisted subscriptions", logging.Session(session.ID), logging.Int("symbols", len(symbols)))
+	}
+}
+
+// addSavedSubscriptionUnlocked records a symbol in a session's persisted set
+// (caller must hold lock)
+func (g *FIXGateway) addSavedSubscriptionUnlocked(sessionID, symbol string) {
+	set, ok := g.savedSubscriptions[sessionID]
+	if !ok {
+		set = make(map[string]bool)
+		g.savedSubscriptions[sessionID] = set
+	}
+	set[symbol] = true
+}
+
+// saveSubscriptionsUnlocked writes a session's subscribed symbols to its store
+// file (caller must hold lock)
+func (g *FIXGateway) saveSubscriptionsUnlocked(session *LPSession) {
+	symbols := make([]string, 0, len(g.savedSubscriptions[session.ID]))
+	for symbol := range g.savedSubscriptions[session.ID] {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	content := strings.Join(symbols, "\n")
+	if len(symbols) > 0 {
+		content += "\n"
+	}
+	if err := os.WriteFile(subscriptionFile(session), []byte(content), 0644); err != nil {
+		logger.Error("Failed to persist subscriptions", err, logging.Session(session.ID))
+	}
+}
+
+// RestoreSubscriptions returns the symbols a session was subscribed to when
+// the backend last ran, to re-subscribe after logon. Returns an empty list when
+// nothing was persisted.
+func (g *FIXGateway) RestoreSubscriptions(sessionID string) ([]string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	session, ok := g.sessions[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("session not found: %s", sessionID)
+	}
+
+	symbols, err := readSubscriptionFile(subscriptionFile(session))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subscriptions for %s: %v", sessionID, err)
+	}
+	for _, symbol := range symbols {
+		g.addSavedSubscriptionUnlocked(sessionID, symbol)
+	}
+	return symbols, nil
+}
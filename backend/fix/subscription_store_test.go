@@ -0,0 +1,81 @@
+package fix
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestRestoreSubscriptionsMissingFile tests that a session with nothing
+// persisted restores an empty list without error
+func TestRestoreSubscriptionsMissingFile(t *testing.T) {
+	gw, session := newTestGateway(t)
+
+	symbols, err := gw.RestoreSubscriptions(session.ID)
+	if err != nil {
+		t.Fatalf("RestoreSubscriptions() error = %v", err)
+	}
+	if len(symbols) != 0 {
+		t.Errorf("RestoreSubscriptions() = %v, want empty", symbols)
+	}
+	if _, err := gw.RestoreSubscriptions("UNKNOWN"); err == nil {
+		t.Error("RestoreSubscriptions() of an unknown session succeeded")
+	}
+}
+
+// TestSubscriptionsPersistAcrossRestart tests that subscribe and unsubscribe
+// update the store file and a fresh gateway reads the set back
+func TestSubscriptionsPersistAcrossRestart(t *testing.T) {
+	gw, session := newTestGateway(t)
+	next := captureSent(t, session)
+
+	for _, symbol := range []string{"GBPUSD", "EURUSD", "XAUUSD"} {
+		if _, err := gw.SubscribeMarketData(session.ID, symbol); err != nil {
+			t.Fatalf("SubscribeMarketData(%s) error = %v", symbol, err)
+		}
+		next()
+	}
+	if err := gw.UnsubscribeMarketDataBySymbol(session.ID, "GBPUSD"); err != nil {
+		t.Fatalf("UnsubscribeMarketDataBySymbol() error = %v", err)
+	}
+	next()
+
+	// A restarted gateway sharing the store directory
+	restarted := NewFIXGateway()
+	restarted.sessions[session.ID] = &LPSession{ID: session.ID, Status: "DISCONNECTED", storeDir: session.storeDir}
+
+	symbols, err := restarted.RestoreSubscriptions(session.ID)
+	if err != nil {
+		t.Fatalf("RestoreSubscriptions() error = %v", err)
+	}
+	if want := []string{"EURUSD", "XAUUSD"}; !reflect.DeepEqual(symbols, want) {
+		t.Errorf("RestoreSubscriptions() = %v, want %v", symbols, want)
+	}
+}
+
+// TestRejectedSubscriptionNotRestored tests that a symbol the LP rejects, e.g.
+// because it was delisted, is dropped from the persisted set
+func TestRejectedSubscriptionNotRestored(t *testing.T) {
+	gw, session := newTestGateway(t)
+	next := captureSent(t, session)
+
+	if _, err := gw.SubscribeMarketData(session.ID, "EURUSD"); err != nil {
+		t.Fatalf("SubscribeMarketData() error = %v", err)
+	}
+	next()
+	mdReqID, err := gw.SubscribeMarketData(session.ID, "OLDPAIR")
+	if err != nil {
+		t.Fatalf("SubscribeMarketData() error = %v", err)
+	}
+	next()
+
+	gw.processMessage(session, session.conn, inbound(gw, session, MsgTypeMarketDataReject, 1,
+		"262="+mdReqID+"\x01281=0\x0158=Unknown symbol\x01"))
+
+	symbols, err := gw.RestoreSubscriptions(session.ID)
+	if err != nil {
+		t.Fatalf("RestoreSubscriptions() error = %v", err)
+	}
+	if want := []string{"EURUSD"}; !reflect.DeepEqual(symbols, want) {
+		t.Errorf("RestoreSubscriptions() = %v, want %v", symbols, want)
+	}
+}
@@ -0,0 +1,136 @@
+package fix
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+// Supported FIX versions (BeginString, tag 8)
+const (
+	FIXVersion42 = "FIX.4.2"
+	FIXVersion44 = "FIX.4.4"
+)
+
+// NormalizeFIXVersion validates a FIX version, accepting "4.2" or "FIX.4.2"
+// style names. Empty selects FIX 4.4.
+func NormalizeFIXVersion(version string) (string, error) {
+	v := strings.ToUpper(strings.TrimSpace(version))
+	if v != "" && !strings.HasPrefix(v, "FIX") {
+		v = "FIX." + v
+	}
+	switch v {
+	case "", FIXVersion44:
+		return FIXVersion44, nil
+	case FIXVersion42:
+		return FIXVersion42, nil
+	default:
+		return "", fmt.Errorf("unsupported FIX version %q: must be %s or %s", version, FIXVersion42, FIXVersion44)
+	}
+}
+
+// SetFIXVersion sets the FIX version a session speaks. Takes effect on the
+// next connection, so it is refused while the session is connected.
+func (g *FIXGateway) SetFIXVersion(sessionID, version string) error {
+	normalized, err := NormalizeFIXVersion(version)
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	session, ok := g.sessions[sessionID]
+	if !ok {
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+	if session.Status != "DISCONNECTED" {
+		return fmt.Errorf("cannot change FIX version while session is %s", session.Status)
+	}
+
+	session.BeginString = normalized
+	logger.Info("FIX version set", logging.Session(session.ID), logging.String("version", normalized))
+	return nil
+}
+
+// applyVersionOverride takes a session's FIX version from <SESSIONID>_FIX_VERSION
+func applyVersionOverride(session *LPSession) {
+	value := os.Getenv(session.ID + "_FIX_VERSION")
+	if value == "" {
+		return
+	}
+	version, err := NormalizeFIXVersion(value)
+	if err != nil {
+		logger.Warn("Ignoring "+session.ID+"_FIX_VERSION", logging.Session(session.ID), logging.Err(err))
+		return
+	}
+	session.BeginString = version
+}
+
+// fixDialect holds where the FIX versions a session may speak differ in the
+// messages the gateway builds and parses
+type fixDialect struct {
+	BeginString string
+	// Logon carries the password in RawData (95/96); FIX 4.4 has Username/Password (553/554)
+	RawDataLogon bool
+	// Instruments carry Product (460) and Currency (15), both added in FIX 4.3
+	ProductTags bool
+	// SecurityType (167) of an FX spot instrument
+	FXSecuritySpot string
+	// OrderCancelRequest requires OrderQty (38)
+	CancelRequiresQty bool
+	// Execution reports use ExecTransType (20) to cancel or correct earlier fills
+	ExecTransType bool
+}
+
+var (
+	dialectFIX42 = fixDialect{
+		BeginString:       FIXVersion42,
+		RawDataLogon:      true,
+		FXSecuritySpot:    "FOR", // Foreign exchange contract
+		CancelRequiresQty: true,
+		ExecTransType:     true,
+	}
+	dialectFIX44 = fixDialect{
+		BeginString:    FIXVersion44,
+		ProductTags:    true,
+		FXSecuritySpot: "FXSPOT",
+	}
+)
+
+// dialect returns the message rules for the session's BeginString
+func (s *LPSession) dialect() fixDialect {
+	if s.BeginString == FIXVersion42 {
+		return dialectFIX42
+	}
+	return dialectFIX44
+}
+
+// logonCredentials returns the Logon fields carrying the session's credentials
+func (s *LPSession) logonCredentials() string {
+	if s.dialect().RawDataLogon {
+		if s.Password == "" {
+			return ""
+		}
+		return fmt.Sprintf("95=%d\x0196=%s\x01", len(s.Password), s.Password) // RawDataLength, RawData
+	}
+
+	fields := ""
+	if s.Username != "" {
+		fields += fmt.Sprintf("553=%s\x01", s.Username) // Username
+	}
+	if s.Password != "" {
+		fields += fmt.Sprintf("554=%s\x01", s.Password) // Password
+	}
+	return fields
+}
+
+// marketDataSecurityType maps a 4.4 SecurityType to the session's dialect
+func (s *LPSession) marketDataSecurityType(securityType string) string {
+	if securityType == "FXSPOT" {
+		return s.dialect().FXSecuritySpot
+	}
+	return securityType
+}
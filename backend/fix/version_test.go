@@ -0,0 +1,205 @@
+package fix
+
+import (
+	"testing"
+)
+
+// TestNormalizeFIXVersion tests the accepted FIX version spellings
+func TestNormalizeFIXVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"", FIXVersion44, false},
+		{"FIX.4.4", FIXVersion44, false},
+		{"4.4", FIXVersion44, false},
+		{"fix.4.2", FIXVersion42, false},
+		{" 4.2 ", FIXVersion42, false},
+		{"FIX.4.3", "", true},
+		{"FIXT.1.1", "", true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeFIXVersion(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NormalizeFIXVersion(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// TestLogonPerFIXVersion tests that a FIX 4.2 Logon carries the password in
+// RawData instead of the 4.4 Username/Password tags
+func TestLogonPerFIXVersion(t *testing.T) {
+	for _, tt := range []struct {
+		version string
+		present []string
+		absent  []string
+	}{
+		{FIXVersion44, []string{"553", "554"}, []string{"95", "96"}},
+		{FIXVersion42, []string{"95", "96"}, []string{"553", "554"}},
+	} {
+		t.Run(tt.version, func(t *testing.T) {
+			gw, session := newTestGateway(t)
+			session.BeginString = tt.version
+			session.Username = "user"
+			session.Password = "secret"
+
+			msg := sentLogon(t, gw, session, "30")
+			if got := tagValues(msg, "8"); len(got) != 1 || got[0] != tt.version {
+				t.Errorf("BeginString = %v, want %s", got, tt.version)
+			}
+			for _, tag := range tt.present {
+				if len(tagValues(msg, tag)) != 1 {
+					t.Errorf("tag %s missing from Logon", tag)
+				}
+			}
+			for _, tag := range tt.absent {
+				if got := tagValues(msg, tag); len(got) != 0 {
+					t.Errorf("tag %s = %v, want omitted", tag, got)
+				}
+			}
+			if tt.version == FIXVersion42 {
+				if got := tagValues(msg, "95"); got[0] != "6" {
+					t.Errorf("RawDataLength = %v, want 6", got)
+				}
+			}
+		})
+	}
+}
+
+// TestMarketDataRequestPerFIXVersion tests that a FIX 4.2 request drops the
+// 4.3+ tags and uses the 4.2 FX security type
+func TestMarketDataRequestPerFIXVersion(t *testing.T) {
+	gw, session := newTestGateway(t)
+	session.BeginString = FIXVersion42
+	next := captureSent(t, session)
+
+	if _, err := gw.SubscribeMarketData(session.ID, "EURUSD"); err != nil {
+		t.Fatalf("SubscribeMarketData() error = %v", err)
+	}
+	msg := next()
+
+	if got := tagValues(msg, "167"); len(got) != 1 || got[0] != "FOR" {
+		t.Errorf("tag 167 = %v, want [FOR]", got)
+	}
+	for _, tag := range []string{"460", "15"} {
+		if got := tagValues(msg, tag); len(got) != 0 {
+			t.Errorf("tag %s = %v, want omitted on FIX 4.2", tag, got)
+		}
+	}
+
+	session.BeginString = FIXVersion44
+	if _, err := gw.SubscribeMarketData(session.ID, "GBPUSD"); err != nil {
+		t.Fatalf("SubscribeMarketData() error = %v", err)
+	}
+	msg = next()
+	for tag, want := range map[string]string{"167": "FXSPOT", "460": "4", "15": "USD"} {
+		if got := tagValues(msg, tag); len(got) != 1 || got[0] != want {
+			t.Errorf("FIX 4.4 tag %s = %v, want [%s]", tag, got, want)
+		}
+	}
+}
+
+// TestSetFIXVersion tests that the version can only change while disconnected
+func TestSetFIXVersion(t *testing.T) {
+	gw, session := newTestGateway(t)
+	if err := gw.SetFIXVersion(session.ID, "4.2"); err == nil {
+		t.Error("SetFIXVersion() allowed a change on a logged in session")
+	}
+
+	session.Status = "DISCONNECTED"
+	if err := gw.SetFIXVersion(session.ID, "4.2"); err != nil {
+		t.Fatalf("SetFIXVersion() error = %v", err)
+	}
+	if session.BeginString != FIXVersion42 {
+		t.Errorf("BeginString = %s, want %s", session.BeginString, FIXVersion42)
+	}
+	if err := gw.SetFIXVersion(session.ID, "5.0"); err == nil {
+		t.Error("SetFIXVersion() accepted an unsupported version")
+	}
+	if err := gw.SetFIXVersion("MISSING", "4.4"); err == nil {
+		t.Error("SetFIXVersion() accepted an unknown session")
+	}
+}
+
+// TestFIXVersionEnvOverride tests the per-session <ID>_FIX_VERSION override
+func TestFIXVersionEnvOverride(t *testing.T) {
+	t.Setenv("FIX_STORE_DIR", t.TempDir())
+	t.Setenv("YOFX2_FIX_VERSION", "4.2")
+	t.Setenv("YOFX1_FIX_VERSION", "bogus")
+
+	gw := NewFIXGateway()
+	if got := gw.sessions["YOFX2"].BeginString; got != FIXVersion42 {
+		t.Errorf("YOFX2 BeginString = %s, want %s", got, FIXVersion42)
+	}
+	if got := gw.sessions["YOFX1"].BeginString; got != FIXVersion44 {
+		t.Errorf("YOFX1 BeginString = %s, want the default after an invalid override", got)
+	}
+}
+
+// TestCancelOrderPerFIXVersion tests that FIX 4.2 cancels must carry OrderQty
+func TestCancelOrderPerFIXVersion(t *testing.T) {
+	gw, session := newTestGateway(t)
+	next := captureSent(t, session)
+
+	if _, err := gw.CancelOrder(session.ID, "CL1", "EURUSD", "BUY", 0); err != nil {
+		t.Fatalf("CancelOrder() error = %v", err)
+	}
+	if got := tagValues(next(), "38"); len(got) != 0 {
+		t.Errorf("FIX 4.4 cancel without quantity sent OrderQty %v", got)
+	}
+
+	session.BeginString = FIXVersion42
+	if _, err := gw.CancelOrder(session.ID, "CL1", "EURUSD", "BUY", 0); err == nil {
+		t.Error("CancelOrder() sent a FIX 4.2 cancel without OrderQty")
+	}
+	if _, err := gw.CancelOrder(session.ID, "CL1", "EURUSD", "BUY", 0.5); err != nil {
+		t.Fatalf("CancelOrder() error = %v", err)
+	}
+	if got := tagValues(next(), "38"); len(got) != 1 || got[0] != "0.50" {
+		t.Errorf("FIX 4.2 OrderQty = %v, want [0.50]", got)
+	}
+}
+
+// TestExecutionReportPerFIXVersion tests that a FIX 4.2 bust or correction is
+// not reported at all, while ExecTransType means nothing on FIX 4.4, and that
+// partial fills carry their cumulative quantities and rejects their reason
+func TestExecutionReportPerFIXVersion(t *testing.T) {
+	report := func(version, fields string) ExecutionReport {
+		gw, session := newTestGateway(t)
+		session.BeginString = version
+		gw.handleExecutionReport(session, parseFields(inbound(gw, session, MsgTypeExecutionReport, 1, fields)))
+		select {
+		case r := <-gw.execReports:
+			return r
+		default:
+			return ExecutionReport{ExecType: "NOT_REPORTED"}
+		}
+	}
+
+	for _, tt := range []struct {
+		version, fields, want string
+	}{
+		{FIXVersion42, "37=ORD1\x0120=0\x01150=2\x0132=1\x0131=1.1\x01", "FILLED"},
+		{FIXVersion42, "37=ORD1\x0120=1\x01150=2\x0132=1\x0131=1.1\x01", "NOT_REPORTED"},
+		{FIXVersion42, "37=ORD1\x0120=2\x01150=2\x0132=1\x0131=1.1\x01", "NOT_REPORTED"},
+		{FIXVersion44, "37=ORD1\x0120=1\x01150=F\x0132=1\x0131=1.1\x01", "FILLED"},
+		{FIXVersion42, "37=ORD1\x0120=0\x01150=1\x0132=1\x0131=1.1\x01", "PARTIAL"},
+		{FIXVersion44, "37=ORD1\x01150=F\x0139=1\x0132=1\x0131=1.1\x01", "PARTIAL"},
+		{FIXVersion44, "37=ORD1\x01150=F\x0139=2\x0132=1\x0131=1.1\x01", "FILLED"},
+	} {
+		if got := report(tt.version, tt.fields).ExecType; got != tt.want {
+			t.Errorf("%s report %q ExecType = %s, want %s", tt.version, tt.fields, got, tt.want)
+		}
+	}
+
+	partial := report(FIXVersion44, "11=CL1\x0137=ORD1\x01150=F\x0139=1\x0132=3\x0131=1.1002\x0114=3\x01151=7\x016=1.1002\x01")
+	if partial.ClOrdID != "CL1" || partial.CumQty != 3 || partial.LeavesQty != 7 || partial.AvgPx != 1.1002 {
+		t.Errorf("partial fill = %+v, want CL1 with CumQty 3, LeavesQty 7, AvgPx 1.1002", partial)
+	}
+
+	reject := report(FIXVersion44, "11=CL237=NONE150=839=8103=258=market closed")
+	if reject.ExecType != "REJECTED" || reject.RejReason != "2" || reject.Text != "market closed" {
+		t.Errorf("reject = %+v, want REJECTED with OrdRejReason 2 and the LP's text", reject)
+	}
+}
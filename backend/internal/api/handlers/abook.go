@@ -22,9 +22,6 @@ func NewABookHandler(engine *abook.ExecutionEngine) *ABookHandler {
 
 // HandlePlaceOrder handles A-Book order placement
 func (h *ABookHandler) HandlePlaceOrder(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -39,6 +36,9 @@ func (h *ABookHandler) HandlePlaceOrder(w http.ResponseWriter, r *http.Request)
 		Price     float64 `json:"price,omitempty"`
 		SL        float64 `json:"sl,omitempty"`
 		TP        float64 `json:"tp,omitempty"`
+		// Sweep LP depth for large market orders, optionally split across LPs
+		Sweep    bool `json:"sweep,omitempty"`
+		SplitLPs bool `json:"splitLps,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -56,6 +56,8 @@ func (h *ABookHandler) HandlePlaceOrder(w http.ResponseWriter, r *http.Request)
 		Price:     req.Price,
 		SL:        req.SL,
 		TP:        req.TP,
+		Sweep:     req.Sweep,
+		SplitLPs:  req.SplitLPs,
 	}
 
 	// Place order
@@ -70,17 +72,19 @@ func (h *ABookHandler) HandlePlaceOrder(w http.ResponseWriter, r *http.Request)
 		order.Symbol, order.Side, order.Volume, order.Type, order.Price, order.Status)
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	resp := map[string]interface{}{
 		"success": true,
 		"order":   order,
-	})
+	}
+	if order.Sweep != nil {
+		resp["expectedVwap"] = order.Sweep.ExpectedVWAP
+		resp["expectedSlippage"] = order.Sweep.ExpectedSlippage
+	}
+	json.NewEncoder(w).Encode(resp)
 }
 
 // HandleCancelOrder handles order cancellation
 func (h *ABookHandler) HandleCancelOrder(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -110,9 +114,6 @@ func (h *ABookHandler) HandleCancelOrder(w http.ResponseWriter, r *http.Request)
 
 // HandleGetOrder handles order status retrieval
 func (h *ABookHandler) HandleGetOrder(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	orderID := r.URL.Query().Get("orderId")
 	if orderID == "" {
 		http.Error(w, "orderId parameter required", http.StatusBadRequest)
@@ -131,9 +132,6 @@ func (h *ABookHandler) HandleGetOrder(w http.ResponseWriter, r *http.Request) {
 
 // HandleGetPositions handles position listing
 func (h *ABookHandler) HandleGetPositions(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	accountID := r.URL.Query().Get("accountId")
 
 	positions := h.engine.GetPositions(accountID)
@@ -144,9 +142,6 @@ func (h *ABookHandler) HandleGetPositions(w http.ResponseWriter, r *http.Request
 
 // HandleClosePosition handles position closing
 func (h *ABookHandler) HandleClosePosition(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -176,9 +171,6 @@ func (h *ABookHandler) HandleClosePosition(w http.ResponseWriter, r *http.Reques
 
 // HandleGetMetrics handles execution metrics retrieval
 func (h *ABookHandler) HandleGetMetrics(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	metrics := h.engine.GetMetrics()
 
 	w.Header().Set("Content-Type", "application/json")
@@ -187,9 +179,6 @@ func (h *ABookHandler) HandleGetMetrics(w http.ResponseWriter, r *http.Request)
 
 // HandleGetLPHealth handles LP health status retrieval
 func (h *ABookHandler) HandleGetLPHealth(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	// This would need to be implemented in the SOR
 	// For now, return basic status
 	w.Header().Set("Content-Type", "application/json")
@@ -201,9 +190,6 @@ func (h *ABookHandler) HandleGetLPHealth(w http.ResponseWriter, r *http.Request)
 
 // HandleGetQuotes handles aggregated quote retrieval
 func (h *ABookHandler) HandleGetQuotes(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	symbol := r.URL.Query().Get("symbol")
 	if symbol == "" {
 		http.Error(w, "symbol parameter required", http.StatusBadRequest)
@@ -3,23 +3,19 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"strconv"
 )
 
-// HandleGetAccountSummary returns account balance/equity/margin
+// HandleGetAccountSummary returns account balance/equity/margin, floating P/L
+// and the P/L realized today
 func (h *APIHandler) HandleGetAccountSummary(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
-	// Get account ID from query or default to 1
-	accountID := int64(1)
-	if id := r.URL.Query().Get("accountId"); id != "" {
-		if parsed, err := strconv.ParseInt(id, 10, 64); err == nil {
-			accountID = parsed
-		}
+	accountID, ok := h.queryAccount(w, r)
+	if !ok {
+		return
 	}
 
 	summary, err := h.engine.GetAccountSummary(accountID)
@@ -34,7 +30,6 @@ func (h *APIHandler) HandleGetAccountSummary(w http.ResponseWriter, r *http.Requ
 
 // HandleCreateAccount creates a new account
 func (h *APIHandler) HandleCreateAccount(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
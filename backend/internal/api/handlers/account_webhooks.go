@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/epic1st/rtx/backend/auth"
+	"github.com/epic1st/rtx/backend/notifications"
+)
+
+// SetWebhookDispatcher sets the dispatcher behind per-account webhook configuration
+func (h *APIHandler) SetWebhookDispatcher(dispatcher *notifications.AccountWebhookDispatcher) {
+	h.webhooks = dispatcher
+}
+
+// webhookAccountID returns the account of the request's trader token. The
+// account is never taken from the request itself, so a trader cannot point
+// another account's events at their own URL.
+func (h *APIHandler) webhookAccountID(r *http.Request) (int64, bool) {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		return 0, false
+	}
+	return claims.AccountID()
+}
+
+// HandleAccountWebhook gets (GET), sets (PUT/POST) or removes (DELETE) the
+// webhook an account is notified on for its order and position events
+func (h *APIHandler) HandleAccountWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if h.webhooks == nil {
+		http.Error(w, "Webhooks not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	accountID, ok := h.webhookAccountID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if _, ok := h.engine.GetAccount(accountID); !ok {
+		http.Error(w, "Account not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		hook, ok := h.webhooks.GetWebhook(accountID)
+		if !ok {
+			http.Error(w, "No webhook configured", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(hook)
+
+	case "PUT", "POST":
+		var req struct {
+			URL     string   `json:"url"`
+			Secret  string   `json:"secret,omitempty"` // Omit to keep the current secret
+			Events  []string `json:"events,omitempty"`
+			Enabled *bool    `json:"enabled,omitempty"` // Defaults to true
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		hook := notifications.AccountWebhook{
+			AccountID: accountID,
+			URL:       req.URL,
+			Secret:    req.Secret,
+			Events:    req.Events,
+			Enabled:   req.Enabled == nil || *req.Enabled,
+		}
+		if hook.Secret == "" {
+			if existing, ok := h.webhooks.GetWebhook(accountID); ok {
+				hook.Secret = existing.Secret
+			}
+		}
+
+		saved, err := h.webhooks.SetWebhook(hook)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(saved)
+
+	case "DELETE":
+		if !h.webhooks.RemoveWebhook(accountID) {
+			http.Error(w, "No webhook configured", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleAccountWebhookDeliveries returns an account's webhook delivery log, newest first
+func (h *APIHandler) HandleAccountWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if h.webhooks == nil {
+		http.Error(w, "Webhooks not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	accountID, ok := h.webhookAccountID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil {
+			limit = parsed
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.webhooks.GetDeliveries(accountID, limit))
+}
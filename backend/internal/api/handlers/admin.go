@@ -5,13 +5,13 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/epic1st/rtx/backend/internal/core"
 )
 
 // HandleAdminDeposit adds funds to an account
 func (h *APIHandler) HandleAdminDeposit(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -66,7 +66,6 @@ func (h *APIHandler) HandleAdminDeposit(w http.ResponseWriter, r *http.Request)
 
 // HandleAdminWithdraw removes funds from an account
 func (h *APIHandler) HandleAdminWithdraw(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -93,22 +92,18 @@ func (h *APIHandler) HandleAdminWithdraw(w http.ResponseWriter, r *http.Request)
 		req.Description = "Withdrawal via " + req.Method
 	}
 
-	// Get account to update balance
-	account, ok := h.engine.GetAccount(req.AccountID)
-	if !ok {
+	if _, ok := h.engine.GetAccount(req.AccountID); !ok {
 		http.Error(w, "Account not found", http.StatusNotFound)
 		return
 	}
 
-	entry, err := h.engine.GetLedger().Withdraw(req.AccountID, req.Amount, req.Method, req.Reference, req.Description, req.AdminID)
+	// Unreleased credit bonuses and margin in use are not withdrawable
+	entry, err := h.engine.Withdraw(req.AccountID, req.Amount, req.Method, req.Reference, req.Description, req.AdminID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Update account balance
-	account.Balance = entry.BalanceAfter
-
 	log.Printf("[ADMIN] Withdraw: Account #%d -%.2f %s by %s", req.AccountID, req.Amount, req.Method, req.AdminID)
 
 	w.Header().Set("Content-Type", "application/json")
@@ -121,7 +116,6 @@ func (h *APIHandler) HandleAdminWithdraw(w http.ResponseWriter, r *http.Request)
 
 // HandleAdminAdjust makes a balance adjustment
 func (h *APIHandler) HandleAdminAdjust(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -165,19 +159,21 @@ func (h *APIHandler) HandleAdminAdjust(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// HandleAdminBonus adds a bonus
+// HandleAdminBonus adds a bonus. A CREDIT bonus supports margin but is only
+// converted to withdrawable balance after requiredVolume lots are traded.
 func (h *APIHandler) HandleAdminBonus(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
 	var req struct {
-		AccountID   int64   `json:"accountId"`
-		Amount      float64 `json:"amount"`
-		Description string  `json:"description"`
-		AdminID     string  `json:"adminId"`
+		AccountID      int64   `json:"accountId"`
+		Amount         float64 `json:"amount"`
+		Type           string  `json:"type,omitempty"`           // BALANCE (default) or CREDIT
+		RequiredVolume float64 `json:"requiredVolume,omitempty"` // Lots to trade before a CREDIT bonus is released
+		Description    string  `json:"description"`
+		AdminID        string  `json:"adminId"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -189,6 +185,23 @@ func (h *APIHandler) HandleAdminBonus(w http.ResponseWriter, r *http.Request) {
 		req.Description = "Bonus"
 	}
 
+	if strings.EqualFold(req.Type, "CREDIT") {
+		bonus, err := h.engine.AddCreditBonus(req.AccountID, req.Amount, req.RequiredVolume, req.Description, req.AdminID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		log.Printf("[ADMIN] Credit bonus: Account #%d +%.2f (release after %.2f lots) by %s", req.AccountID, req.Amount, req.RequiredVolume, req.AdminID)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"bonus":   bonus,
+		})
+		return
+	}
+
 	// Get account to update balance
 	account, ok := h.engine.GetAccount(req.AccountID)
 	if !ok {
@@ -217,7 +230,6 @@ func (h *APIHandler) HandleAdminBonus(w http.ResponseWriter, r *http.Request) {
 
 // HandleAdminGetAccounts returns all accounts
 func (h *APIHandler) HandleAdminGetAccounts(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -242,7 +254,6 @@ func (h *APIHandler) HandleAdminGetAccounts(w http.ResponseWriter, r *http.Reque
 
 // HandleAdminGetLedgerAll returns all ledger entries
 func (h *APIHandler) HandleAdminGetLedgerAll(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -274,7 +285,6 @@ func (h *APIHandler) HandleAdminGetLedgerAll(w http.ResponseWriter, r *http.Requ
 
 // HandleAdminResetPassword resets an account password
 func (h *APIHandler) HandleAdminResetPassword(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -307,7 +317,6 @@ func (h *APIHandler) HandleAdminResetPassword(w http.ResponseWriter, r *http.Req
 
 // HandleAdminUpdateAccount updates account configuration
 func (h *APIHandler) HandleAdminUpdateAccount(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -317,6 +326,9 @@ func (h *APIHandler) HandleAdminUpdateAccount(w http.ResponseWriter, r *http.Req
 		AccountID  int64   `json:"accountId"`
 		Leverage   float64 `json:"leverage"`
 		MarginMode string  `json:"marginMode"`
+		SwapFree   *bool   `json:"swapFree,omitempty"`
+		// HEDGE, CLOSE or REVERSE: how copy/signal accounts treat opposite positions
+		OppositeSignal *string `json:"oppositeSignal,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -330,6 +342,20 @@ func (h *APIHandler) HandleAdminUpdateAccount(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if req.SwapFree != nil {
+		if err := h.engine.SetSwapFree(req.AccountID, *req.SwapFree); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if req.OppositeSignal != nil {
+		if err := h.engine.SetOppositeSignal(req.AccountID, *req.OppositeSignal); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]bool{"success": true})
 }
@@ -10,7 +10,6 @@ import (
 
 // HandleAdminGetSymbols returns all symbols including disabled ones
 func (h *APIHandler) HandleAdminGetSymbols(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -27,7 +26,6 @@ func (h *APIHandler) HandleAdminGetSymbols(w http.ResponseWriter, r *http.Reques
 
 // HandleAdminToggleSymbol toggles a symbol's enabled/disabled status
 func (h *APIHandler) HandleAdminToggleSymbol(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -63,17 +61,26 @@ func (h *APIHandler) HandleAdminToggleSymbol(w http.ResponseWriter, r *http.Requ
 type UpdateSymbolRequest struct {
 	ContractSize     *float64 `json:"contract_size,omitempty"`
 	PipSize          *float64 `json:"pip_size,omitempty"`
+	Digits           *int     `json:"digits,omitempty"`
 	PipValue         *float64 `json:"pip_value,omitempty"`
 	MarginPercent    *float64 `json:"margin_percent,omitempty"`
 	CommissionPerLot *float64 `json:"commission_per_lot,omitempty"`
 	SpreadMarkup     *float64 `json:"spread_markup,omitempty"`
+	CommissionModel  *string  `json:"commission_model,omitempty"`
+	FillLiquidity    *float64 `json:"fill_liquidity,omitempty"`
+	MaxRecordRate    *int     `json:"max_record_rate,omitempty"`
+	SwapLong         *float64 `json:"swap_long,omitempty"`
+	SwapShort        *float64 `json:"swap_short,omitempty"`
+	SwapFreeDisabled *bool    `json:"swap_free_disabled,omitempty"`
+	MaxPriceAgeMs    *int64   `json:"max_price_age_ms,omitempty"`
+	// Slippage on market fills; an empty model returns the symbol to the broker default
+	Slippage *core.SlippageConfig `json:"slippage,omitempty"`
 }
 
 // HandleAdminUpdateSymbol updates symbol parameters via PATCH request
 // URL: PATCH /api/admin/symbols/:symbol
 // Validates input values, updates symbol in engine, and persists to database
 func (h *APIHandler) HandleAdminUpdateSymbol(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -136,6 +143,14 @@ func (h *APIHandler) HandleAdminUpdateSymbol(w http.ResponseWriter, r *http.Requ
 		current.PipSize = *req.PipSize
 	}
 
+	if req.Digits != nil {
+		if *req.Digits < 0 || *req.Digits > 10 {
+			http.Error(w, "digits must be between 0 and 10", http.StatusBadRequest)
+			return
+		}
+		current.Digits = *req.Digits
+	}
+
 	if req.PipValue != nil {
 		if *req.PipValue <= 0 {
 			http.Error(w, "pip_value must be greater than 0", http.StatusBadRequest)
@@ -160,8 +175,73 @@ func (h *APIHandler) HandleAdminUpdateSymbol(w http.ResponseWriter, r *http.Requ
 		current.CommissionPerLot = *req.CommissionPerLot
 	}
 
-	// Note: spread_markup is currently not stored in SymbolSpec, but is accepted for future compatibility
-	// It can be implemented in a future update if needed
+	if req.SpreadMarkup != nil {
+		if *req.SpreadMarkup < 0 {
+			http.Error(w, "spread_markup must be non-negative", http.StatusBadRequest)
+			return
+		}
+		current.SpreadMarkup = *req.SpreadMarkup
+	}
+
+	// Only one of markup or explicit commission is charged, depending on the model
+	if req.CommissionModel != nil {
+		model, err := core.NormalizeCommissionModel(*req.CommissionModel)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		current.CommissionModel = model
+	}
+
+	if req.FillLiquidity != nil {
+		if *req.FillLiquidity < 0 {
+			http.Error(w, "fill_liquidity must be non-negative", http.StatusBadRequest)
+			return
+		}
+		current.FillLiquidity = *req.FillLiquidity
+	}
+
+	// Storage sampling only; the live feed still receives every tick
+	if req.MaxRecordRate != nil {
+		if *req.MaxRecordRate < 0 || *req.MaxRecordRate > 10000 {
+			http.Error(w, "max_record_rate must be between 0 and 10000", http.StatusBadRequest)
+			return
+		}
+		current.MaxRecordRate = *req.MaxRecordRate
+	}
+
+	// Overnight swap per lot; swap-free accounts skip it unless the symbol disables swap-free
+	if req.SwapLong != nil {
+		current.SwapLong = *req.SwapLong
+	}
+	if req.SwapShort != nil {
+		current.SwapShort = *req.SwapShort
+	}
+	if req.SwapFreeDisabled != nil {
+		current.SwapFreeDisabled = *req.SwapFreeDisabled
+	}
+
+	// Market orders reject quotes older than this; 0 uses the broker default
+	if req.MaxPriceAgeMs != nil {
+		if *req.MaxPriceAgeMs < 0 {
+			http.Error(w, "max_price_age_ms must be non-negative", http.StatusBadRequest)
+			return
+		}
+		current.MaxPriceAgeMs = *req.MaxPriceAgeMs
+	}
+
+	if req.Slippage != nil {
+		slippage, err := core.NormalizeSlippageConfig(*req.Slippage)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Slippage.Model == "" {
+			current.Slippage = nil
+		} else {
+			current.Slippage = &slippage
+		}
+	}
 
 	// Update symbol in engine
 	h.engine.UpdateSymbol(current)
@@ -23,8 +23,6 @@ func NewAlertsHandler(engine *alerts.Engine) *AlertsHandler {
 
 // HandleListAlerts - GET /api/alerts
 func (h *AlertsHandler) HandleListAlerts(w http.ResponseWriter, r *http.Request) {
-	cors(w)
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -55,8 +53,6 @@ func (h *AlertsHandler) HandleListAlerts(w http.ResponseWriter, r *http.Request)
 
 // HandleAcknowledgeAlert - POST /api/alerts/acknowledge
 func (h *AlertsHandler) HandleAcknowledgeAlert(w http.ResponseWriter, r *http.Request) {
-	cors(w)
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -104,8 +100,6 @@ func (h *AlertsHandler) HandleAcknowledgeAlert(w http.ResponseWriter, r *http.Re
 
 // HandleSnoozeAlert - POST /api/alerts/snooze
 func (h *AlertsHandler) HandleSnoozeAlert(w http.ResponseWriter, r *http.Request) {
-	cors(w)
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -152,8 +146,6 @@ func (h *AlertsHandler) HandleSnoozeAlert(w http.ResponseWriter, r *http.Request
 
 // HandleResolveAlert - POST /api/alerts/resolve
 func (h *AlertsHandler) HandleResolveAlert(w http.ResponseWriter, r *http.Request) {
-	cors(w)
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -194,8 +186,6 @@ func (h *AlertsHandler) HandleResolveAlert(w http.ResponseWriter, r *http.Reques
 
 // HandleListRules - GET /api/alerts/rules
 func (h *AlertsHandler) HandleListRules(w http.ResponseWriter, r *http.Request) {
-	cors(w)
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -218,8 +208,6 @@ func (h *AlertsHandler) HandleListRules(w http.ResponseWriter, r *http.Request)
 
 // HandleCreateRule - POST /api/alerts/rules
 func (h *AlertsHandler) HandleCreateRule(w http.ResponseWriter, r *http.Request) {
-	cors(w)
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -282,8 +270,6 @@ func (h *AlertsHandler) HandleCreateRule(w http.ResponseWriter, r *http.Request)
 
 // HandleUpdateRule - PUT /api/alerts/rules/{id}
 func (h *AlertsHandler) HandleUpdateRule(w http.ResponseWriter, r *http.Request) {
-	cors(w)
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -327,8 +313,6 @@ func (h *AlertsHandler) HandleUpdateRule(w http.ResponseWriter, r *http.Request)
 
 // HandleDeleteRule - DELETE /api/alerts/rules/{id}
 func (h *AlertsHandler) HandleDeleteRule(w http.ResponseWriter, r *http.Request) {
-	cors(w)
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -363,8 +347,6 @@ func (h *AlertsHandler) HandleDeleteRule(w http.ResponseWriter, r *http.Request)
 
 // HandleGetRule - GET /api/alerts/rules/{id}
 func (h *AlertsHandler) HandleGetRule(w http.ResponseWriter, r *http.Request) {
-	cors(w)
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -49,7 +49,6 @@ type ExposureTimelineEntry struct {
 
 // HandleExposureHeatmap returns exposure heatmap data
 func (h *APIHandler) HandleExposureHeatmap(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -149,7 +148,6 @@ func (h *APIHandler) HandleExposureHeatmap(w http.ResponseWriter, r *http.Reques
 
 // HandleCurrentExposure returns current exposure by symbol
 func (h *APIHandler) HandleCurrentExposure(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -185,7 +183,6 @@ func (h *APIHandler) HandleCurrentExposure(w http.ResponseWriter, r *http.Reques
 
 // HandleExposureHistory returns exposure timeline for a specific symbol
 func (h *APIHandler) HandleExposureHistory(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
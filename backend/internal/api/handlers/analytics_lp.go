@@ -108,8 +108,6 @@ type LPRanking struct {
 
 // HandleLPComparison handles GET /api/analytics/lp/comparison
 func (h *AnalyticsLPHandler) HandleLPComparison(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -231,8 +229,6 @@ func (h *AnalyticsLPHandler) HandleLPComparison(w http.ResponseWriter, r *http.R
 
 // HandleLPPerformance handles GET /api/analytics/lp/performance/{lp_name}
 func (h *AnalyticsLPHandler) HandleLPPerformance(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -416,8 +412,6 @@ func (h *AnalyticsLPHandler) HandleLPPerformance(w http.ResponseWriter, r *http.
 
 // HandleLPRanking handles GET /api/analytics/lp/ranking
 func (h *AnalyticsLPHandler) HandleLPRanking(w http.ResponseWriter, r *http.Request) {
-	setCORSHeaders(w)
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -549,12 +543,6 @@ func (h *AnalyticsLPHandler) HandleLPRanking(w http.ResponseWriter, r *http.Requ
 
 // Helper functions
 
-func setCORSHeaders(w http.ResponseWriter) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-}
-
 func buildSymbolFilter(symbol string) string {
 	if symbol != "" {
 		return fmt.Sprintf(" AND o.symbol = '%s'", symbol)
@@ -293,23 +293,6 @@ func TestSymbolFilter(t *testing.T) {
 	}
 }
 
-func TestCORSHeaders(t *testing.T) {
-	w := httptest.NewRecorder()
-	setCORSHeaders(w)
-
-	headers := []string{
-		"Access-Control-Allow-Origin",
-		"Access-Control-Allow-Methods",
-		"Access-Control-Allow-Headers",
-	}
-
-	for _, header := range headers {
-		if w.Header().Get(header) == "" {
-			t.Errorf("Expected CORS header %s to be set", header)
-		}
-	}
-}
-
 func TestOPTIONSRequest(t *testing.T) {
 	handler, err := NewAnalyticsLPHandler()
 	if err != nil {
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+// Default thresholds for flagging long-held losing positions
+const (
+	defaultLongHoldMinHours   = 24.0
+	defaultLongHoldMultiplier = 3.0
+)
+
+// PositionAgingConfig controls when an open losing position is flagged.
+// A position is flagged once it is older than both MinAge and Multiplier
+// times the median holding time of its symbol.
+type PositionAgingConfig struct {
+	MinAge     time.Duration
+	Multiplier float64
+}
+
+// HoldingTimeStats summarizes holding times for a group of positions
+type HoldingTimeStats struct {
+	OpenPositions     int     `json:"open_positions"`
+	ClosedPositions   int     `json:"closed_positions"`
+	AvgHoldingSecs    float64 `json:"avg_holding_secs"`
+	MedianHoldingSecs float64 `json:"median_holding_secs"`
+	MaxOpenAgeSecs    float64 `json:"max_open_age_secs"`
+}
+
+// SymbolPositionAge is the holding time summary of one symbol
+type SymbolPositionAge struct {
+	Symbol string `json:"symbol"`
+	HoldingTimeStats
+}
+
+// AccountPositionAge is the holding time summary of one account
+type AccountPositionAge struct {
+	AccountID int64 `json:"account_id"`
+	HoldingTimeStats
+}
+
+// FlaggedPosition is an open losing position held unusually long
+type FlaggedPosition struct {
+	PositionID    int64     `json:"position_id"`
+	AccountID     int64     `json:"account_id"`
+	Symbol        string    `json:"symbol"`
+	Side          string    `json:"side"`
+	Volume        float64   `json:"volume"`
+	OpenTime      time.Time `json:"open_time"`
+	AgeSecs       float64   `json:"age_secs"`
+	ThresholdSecs float64   `json:"threshold_secs"`
+	UnrealizedPnL float64   `json:"unrealized_pnl"`
+}
+
+// PositionAgingReport is the response of the position aging analytics
+type PositionAgingReport struct {
+	Timestamp int64                `json:"timestamp"`
+	Overall   HoldingTimeStats     `json:"overall"`
+	BySymbol  []SymbolPositionAge  `json:"by_symbol"`
+	ByAccount []AccountPositionAge `json:"by_account"`
+	Flagged   []FlaggedPosition    `json:"flagged"`
+}
+
+// HandlePositionAge returns holding time statistics per symbol and per account
+// along with long-held losing positions
+func (h *APIHandler) HandlePositionAge(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	cfg := PositionAgingConfig{
+		MinAge:     time.Duration(defaultLongHoldMinHours * float64(time.Hour)),
+		Multiplier: defaultLongHoldMultiplier,
+	}
+	if v := query.Get("min_age_hours"); v != "" {
+		hours, err := strconv.ParseFloat(v, 64)
+		if err != nil || hours < 0 {
+			http.Error(w, "Invalid min_age_hours", http.StatusBadRequest)
+			return
+		}
+		cfg.MinAge = time.Duration(hours * float64(time.Hour))
+	}
+	if v := query.Get("multiplier"); v != "" {
+		multiplier, err := strconv.ParseFloat(v, 64)
+		if err != nil || multiplier < 0 {
+			http.Error(w, "Invalid multiplier", http.StatusBadRequest)
+			return
+		}
+		cfg.Multiplier = multiplier
+	}
+
+	var accountFilter int64
+	if v := query.Get("account_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid account_id", http.StatusBadRequest)
+			return
+		}
+		accountFilter = id
+	}
+	symbolFilter := query.Get("symbol")
+
+	filter := func(positions []*core.Position) []*core.Position {
+		var result []*core.Position
+		for _, pos := range positions {
+			if accountFilter != 0 && pos.AccountID != accountFilter {
+				continue
+			}
+			if symbolFilter != "" && pos.Symbol != symbolFilter {
+				continue
+			}
+			result = append(result, pos)
+		}
+		return result
+	}
+
+	report := calculatePositionAging(
+		filter(h.engine.GetAllPositions()),
+		filter(h.engine.GetClosedPositions()),
+		time.Now(),
+		cfg,
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// holdingSample is the holding time of a single position
+type holdingSample struct {
+	pos  *core.Position
+	secs float64
+	open bool
+}
+
+// calculatePositionAging computes holding time statistics at now. Open
+// positions count with their age so far, closed ones with their full duration.
+func calculatePositionAging(open, closed []*core.Position, now time.Time, cfg PositionAgingConfig) PositionAgingReport {
+	var samples []holdingSample
+	for _, pos := range open {
+		samples = append(samples, holdingSample{pos: pos, secs: now.Sub(pos.OpenTime).Seconds(), open: true})
+	}
+	for _, pos := range closed {
+		if pos.CloseTime.IsZero() {
+			continue
+		}
+		samples = append(samples, holdingSample{pos: pos, secs: pos.CloseTime.Sub(pos.OpenTime).Seconds()})
+	}
+
+	bySymbol := make(map[string][]holdingSample)
+	byAccount := make(map[int64][]holdingSample)
+	for _, s := range samples {
+		bySymbol[s.pos.Symbol] = append(bySymbol[s.pos.Symbol], s)
+		byAccount[s.pos.AccountID] = append(byAccount[s.pos.AccountID], s)
+	}
+
+	report := PositionAgingReport{
+		Timestamp: now.Unix(),
+		Overall:   holdingTimeStats(samples),
+		BySymbol:  []SymbolPositionAge{},
+		ByAccount: []AccountPositionAge{},
+		Flagged:   []FlaggedPosition{},
+	}
+
+	for symbol, group := range bySymbol {
+		report.BySymbol = append(report.BySymbol, SymbolPositionAge{Symbol: symbol, HoldingTimeStats: holdingTimeStats(group)})
+	}
+	sort.Slice(report.BySymbol, func(i, j int) bool {
+		return report.BySymbol[i].Symbol < report.BySymbol[j].Symbol
+	})
+
+	for accountID, group := range byAccount {
+		report.ByAccount = append(report.ByAccount, AccountPositionAge{AccountID: accountID, HoldingTimeStats: holdingTimeStats(group)})
+	}
+	sort.Slice(report.ByAccount, func(i, j int) bool {
+		return report.ByAccount[i].AccountID < report.ByAccount[j].AccountID
+	})
+
+	// Flag losing positions held well beyond what is usual for their symbol
+	medians := make(map[string]float64)
+	for _, s := range report.BySymbol {
+		medians[s.Symbol] = s.MedianHoldingSecs
+	}
+	for _, s := range samples {
+		if !s.open || s.pos.UnrealizedPnL >= 0 {
+			continue
+		}
+		threshold := cfg.MinAge.Seconds()
+		if relative := cfg.Multiplier * medians[s.pos.Symbol]; relative > threshold {
+			threshold = relative
+		}
+		if s.secs < threshold {
+			continue
+		}
+		report.Flagged = append(report.Flagged, FlaggedPosition{
+			PositionID:    s.pos.ID,
+			AccountID:     s.pos.AccountID,
+			Symbol:        s.pos.Symbol,
+			Side:          s.pos.Side,
+			Volume:        s.pos.Volume,
+			OpenTime:      s.pos.OpenTime,
+			AgeSecs:       s.secs,
+			ThresholdSecs: threshold,
+			UnrealizedPnL: s.pos.UnrealizedPnL,
+		})
+	}
+	sort.Slice(report.Flagged, func(i, j int) bool {
+		return report.Flagged[i].AgeSecs > report.Flagged[j].AgeSecs
+	})
+
+	return report
+}
+
+func holdingTimeStats(samples []holdingSample) HoldingTimeStats {
+	var stats HoldingTimeStats
+	if len(samples) == 0 {
+		return stats
+	}
+
+	durations := make([]float64, 0, len(samples))
+	total := 0.0
+	for _, s := range samples {
+		if s.open {
+			stats.OpenPositions++
+			if s.secs > stats.MaxOpenAgeSecs {
+				stats.MaxOpenAgeSecs = s.secs
+			}
+		} else {
+			stats.ClosedPositions++
+		}
+		durations = append(durations, s.secs)
+		total += s.secs
+	}
+
+	sort.Float64s(durations)
+	stats.AvgHoldingSecs = total / float64(len(durations))
+	mid := len(durations) / 2
+	if len(durations)%2 == 0 {
+		stats.MedianHoldingSecs = (durations[mid-1] + durations[mid]) / 2
+	} else {
+		stats.MedianHoldingSecs = durations[mid]
+	}
+	return stats
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+// TestPositionAgingStats tests holding time stats from positions with known open times
+func TestPositionAgingStats(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	hoursAgo := func(h float64) time.Time {
+		return now.Add(-time.Duration(h * float64(time.Hour)))
+	}
+
+	open := []*core.Position{
+		{ID: 1, AccountID: 1, Symbol: "EURUSD", Side: "BUY", Volume: 1, OpenTime: hoursAgo(1), UnrealizedPnL: 10, Status: "OPEN"},
+		{ID: 2, AccountID: 1, Symbol: "EURUSD", Side: "SELL", Volume: 1, OpenTime: hoursAgo(3), UnrealizedPnL: -5, Status: "OPEN"},
+		{ID: 3, AccountID: 2, Symbol: "EURUSD", Side: "BUY", Volume: 2, OpenTime: hoursAgo(100), UnrealizedPnL: -50, Status: "OPEN"},
+		{ID: 4, AccountID: 2, Symbol: "GBPUSD", Side: "BUY", Volume: 1, OpenTime: hoursAgo(30), UnrealizedPnL: -20, Status: "OPEN"},
+	}
+	closed := []*core.Position{
+		{ID: 5, AccountID: 1, Symbol: "GBPUSD", Side: "SELL", Volume: 1, OpenTime: hoursAgo(48), CloseTime: hoursAgo(38), Status: "CLOSED"},
+	}
+
+	report := calculatePositionAging(open, closed, now, PositionAgingConfig{MinAge: 24 * time.Hour, Multiplier: 3})
+
+	hours := func(secs float64) float64 { return secs / 3600 }
+	approx := func(got, want float64) bool { return math.Abs(got-want) < 1e-9 }
+
+	if report.Overall.OpenPositions != 4 || report.Overall.ClosedPositions != 1 {
+		t.Fatalf("overall counts = %d open/%d closed, want 4/1", report.Overall.OpenPositions, report.Overall.ClosedPositions)
+	}
+
+	if len(report.BySymbol) != 2 {
+		t.Fatalf("BySymbol has %d entries, want 2", len(report.BySymbol))
+	}
+	eur, gbp := report.BySymbol[0], report.BySymbol[1]
+	if eur.Symbol != "EURUSD" || !approx(hours(eur.AvgHoldingSecs), 104.0/3) || !approx(hours(eur.MedianHoldingSecs), 3) {
+		t.Errorf("EURUSD stats = %+v, want avg 34.67h median 3h", eur)
+	}
+	if !approx(hours(eur.MaxOpenAgeSecs), 100) {
+		t.Errorf("EURUSD max open age = %.2fh, want 100h", hours(eur.MaxOpenAgeSecs))
+	}
+	if gbp.Symbol != "GBPUSD" || gbp.OpenPositions != 1 || gbp.ClosedPositions != 1 || !approx(hours(gbp.MedianHoldingSecs), 20) {
+		t.Errorf("GBPUSD stats = %+v, want 1 open/1 closed median 20h", gbp)
+	}
+
+	if len(report.ByAccount) != 2 {
+		t.Fatalf("ByAccount has %d entries, want 2", len(report.ByAccount))
+	}
+	acc1, acc2 := report.ByAccount[0], report.ByAccount[1]
+	if acc1.AccountID != 1 || !approx(hours(acc1.AvgHoldingSecs), 14.0/3) || !approx(hours(acc1.MedianHoldingSecs), 3) {
+		t.Errorf("account 1 stats = %+v, want avg 4.67h median 3h", acc1)
+	}
+	if acc2.AccountID != 2 || !approx(hours(acc2.MedianHoldingSecs), 65) {
+		t.Errorf("account 2 stats = %+v, want median 65h", acc2)
+	}
+
+	// Position 3 is past 24h and 3x the EURUSD median. Position 4 is past 24h
+	// but not past 3x the GBPUSD median; position 1 is in profit.
+	if len(report.Flagged) != 1 || report.Flagged[0].PositionID != 3 {
+		t.Fatalf("Flagged = %+v, want only position 3", report.Flagged)
+	}
+	if !approx(hours(report.Flagged[0].ThresholdSecs), 24) {
+		t.Errorf("flag threshold = %.2fh, want 24h", hours(report.Flagged[0].ThresholdSecs))
+	}
+}
@@ -59,8 +59,6 @@ type RoutingConfidenceResponse struct {
 
 // HandleRoutingBreakdown handles GET /api/analytics/routing/breakdown
 func (h *APIHandler) HandleRoutingBreakdown(w http.ResponseWriter, r *http.Request) {
-	cors(w)
-
 	if r.Method == http.MethodOptions {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -213,8 +211,6 @@ func (h *APIHandler) HandleRoutingBreakdown(w http.ResponseWriter, r *http.Reque
 
 // HandleRoutingTimeline handles GET /api/analytics/routing/timeline
 func (h *APIHandler) HandleRoutingTimeline(w http.ResponseWriter, r *http.Request) {
-	cors(w)
-
 	if r.Method == http.MethodOptions {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -340,8 +336,6 @@ func (h *APIHandler) HandleRoutingTimeline(w http.ResponseWriter, r *http.Reques
 
 // HandleRoutingConfidence handles GET /api/analytics/routing/confidence
 func (h *APIHandler) HandleRoutingConfidence(w http.ResponseWriter, r *http.Request) {
-	cors(w)
-
 	if r.Method == http.MethodOptions {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -68,7 +68,6 @@ type CalculateMetricsResponse struct {
 // HandleGetRuleEffectiveness returns effectiveness metrics for all routing rules
 // GET /api/analytics/rules/effectiveness?start_time=<timestamp>&end_time=<timestamp>&min_trades=<n>
 func (h *APIHandler) HandleGetRuleEffectiveness(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -157,7 +156,6 @@ func (h *APIHandler) HandleGetRuleEffectiveness(w http.ResponseWriter, r *http.R
 // HandleGetRuleMetrics returns detailed metrics for a single rule
 // GET /api/analytics/rules/{rule_id}/metrics?start_time=<timestamp>&end_time=<timestamp>
 func (h *APIHandler) HandleGetRuleMetrics(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -217,7 +215,6 @@ func (h *APIHandler) HandleGetRuleMetrics(w http.ResponseWriter, r *http.Request
 // HandleCalculateMetrics calculates metrics for a given set of trades
 // POST /api/analytics/rules/calculate
 func (h *APIHandler) HandleCalculateMetrics(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
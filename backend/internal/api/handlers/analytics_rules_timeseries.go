@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/epic1st/rtx/backend/cbook"
+)
+
+// RuleTimeSeriesResponse is the effectiveness history of a routing rule
+type RuleTimeSeriesResponse struct {
+	RuleID   string                  `json:"rule_id"`
+	Interval string                  `json:"interval"`
+	From     int64                   `json:"from"`
+	To       int64                   `json:"to"`
+	Series   []cbook.RuleSeriesPoint `json:"series"`
+}
+
+// SetRuleSnapshotStore sets the store of periodic rule effectiveness snapshots
+func (h *APIHandler) SetRuleSnapshotStore(store *cbook.RuleSnapshotStore) {
+	h.ruleSnapshots = store
+}
+
+// HandleGetRuleTimeSeries returns rule effectiveness bucketed over time
+// GET /api/analytics/rules/timeseries?rule=<id>&from=<timestamp>&to=<timestamp>&interval=<15m|1h|4h|1d>
+func (h *APIHandler) HandleGetRuleTimeSeries(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Verify admin authentication
+	if !h.isAdminUser(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if h.ruleSnapshots == nil {
+		http.Error(w, "Rule snapshots not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := r.URL.Query()
+	ruleID := query.Get("rule")
+	if ruleID == "" {
+		http.Error(w, "rule parameter required", http.StatusBadRequest)
+		return
+	}
+
+	// Default to last 24 hours if not specified
+	to := time.Now()
+	from := to.Add(-24 * time.Hour)
+
+	if v := query.Get("from"); v != "" {
+		ts, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid from", http.StatusBadRequest)
+			return
+		}
+		from = time.Unix(ts, 0)
+	}
+	if v := query.Get("to"); v != "" {
+		ts, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid to", http.StatusBadRequest)
+			return
+		}
+		to = time.Unix(ts, 0)
+	}
+	if to.Before(from) {
+		http.Error(w, "to must not be before from", http.StatusBadRequest)
+		return
+	}
+
+	interval := query.Get("interval")
+	if interval == "" {
+		interval = "1h"
+	}
+	intervalDuration, err := time.ParseDuration(interval)
+	if err != nil || intervalDuration <= 0 {
+		intervalDuration = h.parseInterval(interval)
+	}
+
+	response := RuleTimeSeriesResponse{
+		RuleID:   ruleID,
+		Interval: interval,
+		From:     from.Unix(),
+		To:       to.Unix(),
+		Series:   h.ruleSnapshots.Series(ruleID, from, to, intervalDuration),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/auth"
+	"github.com/epic1st/rtx/backend/cbook"
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+// TestRuleTimeSeriesBucketsSnapshots tests that snapshots accumulate over simulated time
+// and the endpoint returns them summed per interval
+func TestRuleTimeSeriesBucketsSnapshots(t *testing.T) {
+	cbookEngine := cbook.NewCBookEngine()
+	cbookEngine.AddRoutingRule(&cbook.RoutingRule{
+		ID:           "hedge-eurusd",
+		Priority:     10,
+		Symbols:      []string{"EURUSD"},
+		Action:       cbook.ActionPartialHedge,
+		HedgePercent: 60,
+		Enabled:      true,
+	})
+	routingEngine := cbookEngine.GetRoutingEngine()
+
+	path := filepath.Join(t.TempDir(), "rule_snapshots.jsonl")
+	store, err := cbook.NewRuleSnapshotStore(path)
+	if err != nil {
+		t.Fatalf("NewRuleSnapshotStore() error = %v", err)
+	}
+
+	route := func(volume float64) *cbook.RoutingDecision {
+		decision, err := cbookEngine.RouteOrder(1, "u1", "trader", "EURUSD", "BUY", volume, 0)
+		if err != nil {
+			t.Fatalf("RouteOrder() error = %v", err)
+		}
+		if decision.RuleID != "hedge-eurusd" {
+			t.Fatalf("decision rule = %q, want hedge-eurusd", decision.RuleID)
+		}
+		return decision
+	}
+	snapshot := func(at time.Time) {
+		if err := store.Snapshot(routingEngine, at); err != nil {
+			t.Fatalf("Snapshot() error = %v", err)
+		}
+	}
+
+	base := time.Unix(472222*3600, 0) // Hour aligned
+	decision := route(1)
+	route(1)
+	cbookEngine.RecordTradeOutcome(1, 1, decision, &cbook.TradeOutcome{RealizedPnL: 50})
+	snapshot(base.Add(10 * time.Minute))
+
+	route(2)
+	snapshot(base.Add(40 * time.Minute))
+
+	decision = route(1)
+	cbookEngine.RecordTradeOutcome(1, 2, decision, &cbook.TradeOutcome{RealizedPnL: -20})
+	snapshot(base.Add(70 * time.Minute))
+	snapshot(base.Add(80 * time.Minute)) // No activity, nothing recorded
+
+	// Snapshots are persisted and reload into an equivalent store
+	reloaded, err := cbook.NewRuleSnapshotStore(path)
+	if err != nil {
+		t.Fatalf("reload NewRuleSnapshotStore() error = %v", err)
+	}
+
+	engine := core.NewEngine()
+	h := NewAPIHandler(engine, nil)
+	h.SetCBookEngine(cbookEngine)
+	h.SetRuleSnapshotStore(reloaded)
+	authService := auth.NewService(engine, "", "timeseries-test-secret")
+	h.SetAuthService(authService)
+	adminToken, err := authService.GenerateToken(&auth.User{ID: "0", Username: "admin", Role: auth.RoleAdmin})
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	url := fmt.Sprintf("/api/analytics/rules/timeseries?rule=hedge-eurusd&from=%d&to=%d&interval=1h",
+		base.Unix(), base.Add(2*time.Hour).Unix())
+	req := httptest.NewRequest("GET", url, nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	w := httptest.NewRecorder()
+	h.HandleGetRuleTimeSeries(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp RuleTimeSeriesResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if len(resp.Series) != 2 {
+		t.Fatalf("series has %d buckets, want 2: %+v", len(resp.Series), resp.Series)
+	}
+	approx := func(got, want float64) bool { return math.Abs(got-want) < 1e-9 }
+
+	first, second := resp.Series[0], resp.Series[1]
+	if first.Timestamp != base.Unix() || first.Matches != 3 || first.Trades != 1 || !approx(first.RealizedPnL, 50) {
+		t.Errorf("first bucket = %+v, want 3 matches, 1 trade, P/L 50 at %d", first, base.Unix())
+	}
+	if !approx(first.ABookVolume, 2.4) || !approx(first.BBookVolume, 1.6) || !approx(first.ABookPercent, 60) {
+		t.Errorf("first bucket split = A %.2f / B %.2f (%.1f%%), want 2.4 / 1.6 (60%%)", first.ABookVolume, first.BBookVolume, first.ABookPercent)
+	}
+	if second.Timestamp != base.Add(time.Hour).Unix() || second.Matches != 1 || second.Trades != 1 || !approx(second.RealizedPnL, -20) {
+		t.Errorf("second bucket = %+v, want 1 match, 1 trade, P/L -20", second)
+	}
+
+	// A rule parameter is required
+	req = httptest.NewRequest("GET", "/api/analytics/rules/timeseries", nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	w = httptest.NewRecorder()
+	h.HandleGetRuleTimeSeries(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("missing rule status = %d, want 400", w.Code)
+	}
+}
+
+// TestRuleTimeSeriesRequiresAdmin tests that the route, wrapped as main.go
+// registers it, rejects trader tokens and missing tokens
+func TestRuleTimeSeriesRequiresAdmin(t *testing.T) {
+	engine := core.NewEngine()
+	h := NewAPIHandler(engine, nil)
+	authService := auth.NewService(engine, "", "timeseries-test-secret")
+	h.SetAuthService(authService)
+	route := authService.RequireRole(auth.RoleAdmin, h.HandleGetRuleTimeSeries)
+
+	traderToken, err := authService.GenerateToken(&auth.User{ID: "1", Username: "trader", Role: auth.RoleTrader})
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/analytics/rules/timeseries?rule=hedge-eurusd", nil)
+	req.Header.Set("Authorization", "Bearer "+traderToken)
+	w := httptest.NewRecorder()
+	route(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("trader token status = %d, want 403", w.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/api/analytics/rules/timeseries?rule=hedge-eurusd", nil)
+	w = httptest.NewRecorder()
+	route(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("missing token status = %d, want 401", w.Code)
+	}
+}
@@ -1,10 +1,11 @@
 package handlers
 
 import (
-	"net/http"
-
+	"github.com/epic1st/rtx/backend/auth"
 	"github.com/epic1st/rtx/backend/cbook"
 	"github.com/epic1st/rtx/backend/internal/core"
+	"github.com/epic1st/rtx/backend/notifications"
+	"github.com/epic1st/rtx/backend/orders"
 	"github.com/epic1st/rtx/backend/ws"
 )
 
@@ -14,6 +15,15 @@ type APIHandler struct {
 	pnlEngine   *core.PnLEngine
 	cbookEngine *cbook.CBookEngine
 	hub         *ws.Hub
+	authService *auth.Service
+	orderRules  orders.OrderRulesResolver
+
+	ruleSnapshots *cbook.RuleSnapshotStore
+	splitRouter   *cbook.SplitRouter // Covers the A-Book share of split market orders at the LP
+
+	webhooks *notifications.AccountWebhookDispatcher
+
+	hedgePositions func() map[string]float64 // A-Book hedge lots per symbol, for the dashboard
 }
 
 // NewAPIHandler creates API handlers for B-Book
@@ -29,14 +39,22 @@ func (h *APIHandler) SetCBookEngine(cbookEngine *cbook.CBookEngine) {
 	h.cbookEngine = cbookEngine
 }
 
+// SetSplitRouter enables execution of split routing decisions on market orders
+func (h *APIHandler) SetSplitRouter(router *cbook.SplitRouter) {
+	h.splitRouter = router
+}
+
 // SetHub sets the WebSocket hub reference
 func (h *APIHandler) SetHub(hub *ws.Hub) {
 	h.hub = hub
 }
 
-// cors adds CORS headers
-func cors(w http.ResponseWriter) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+// SetAuthService sets the auth service used to resolve the caller's account
+func (h *APIHandler) SetAuthService(svc *auth.Service) {
+	h.authService = svc
+}
+
+// SetOrderRulesResolver sets the group placement rules applied to market orders
+func (h *APIHandler) SetOrderRulesResolver(resolver orders.OrderRulesResolver) {
+	h.orderRules = resolver
 }
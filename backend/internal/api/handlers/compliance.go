@@ -16,7 +16,17 @@ import (
 
 // ComplianceHandler handles compliance and regulatory reporting
 type ComplianceHandler struct {
-	engine *core.Engine
+	engine      *core.Engine
+	auditSource AuditTrailSource
+}
+
+// AuditTrailSource returns the recorded audit entries between start and end,
+// of entityType when it is not empty
+type AuditTrailSource func(start, end time.Time, entityType string) []AuditEntry
+
+// SetAuditTrailSource sets where the audit trail export reads its entries from
+func (h *ComplianceHandler) SetAuditTrailSource(fn AuditTrailSource) {
+	h.auditSource = fn
 }
 
 // NewComplianceHandler creates a new compliance handler
@@ -137,7 +147,6 @@ type AuditEntry struct {
 // HandleBestExecution generates MiFID II RTS 27/28 best execution report
 // GET /api/compliance/best-execution?start_time=...&end_time=...&format=json|csv|pdf
 func (h *ComplianceHandler) HandleBestExecution(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -188,7 +197,6 @@ func (h *ComplianceHandler) HandleBestExecution(w http.ResponseWriter, r *http.R
 // HandleOrderRouting generates SEC Rule 606 order routing disclosure
 // GET /api/compliance/order-routing?quarter=Q1&year=2026&format=json|csv|pdf
 func (h *ComplianceHandler) HandleOrderRouting(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -239,7 +247,6 @@ func (h *ComplianceHandler) HandleOrderRouting(w http.ResponseWriter, r *http.Re
 // HandleAuditTrail exports audit log entries
 // GET /api/compliance/audit-trail?start_time=...&end_time=...&entity_type=...&format=json|csv
 func (h *ComplianceHandler) HandleAuditTrail(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -289,7 +296,6 @@ func (h *ComplianceHandler) HandleAuditTrail(w http.ResponseWriter, r *http.Requ
 // HandleAuditLog writes a new audit entry (internal use)
 // POST /api/compliance/audit-log
 func (h *ComplianceHandler) HandleAuditLog(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -467,6 +473,28 @@ func (h *ComplianceHandler) generateOrderRoutingReport(quarter string, year int)
 }
 
 func (h *ComplianceHandler) generateAuditTrailExport(startTime, endTime time.Time, entityType string) AuditTrailExport {
+	if h.auditSource != nil {
+		entries := h.auditSource(startTime, endTime, entityType)
+		for i := range entries {
+			entries[i].Hash = h.generateAuditHash(entries[i])
+		}
+		return AuditTrailExport{
+			ReportID:    uuid.New().String(),
+			GeneratedAt: time.Now().UTC(),
+			Period: ReportPeriod{
+				StartTime: startTime,
+				EndTime:   endTime,
+			},
+			TotalCount: int64(len(entries)),
+			Entries:    entries,
+			Metadata: map[string]interface{}{
+				"entity_type_filter": entityType,
+				"retention_years":    7,
+				"tamper_proof":       true,
+			},
+		}
+	}
+
 	// In production: Query audit_log table with filters
 
 	export := AuditTrailExport{
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+// BrokerDashboard is the broker-wide book health returned by /admin/dashboard
+type BrokerDashboard struct {
+	Totals            core.BookTotals    `json:"totals"`
+	ClientRealizedPnL float64            `json:"clientRealizedPnL"`
+	ClientNetPnL      float64            `json:"clientNetPnL"` // Realized plus floating client P/L
+	BrokerPnL         float64            `json:"brokerPnL"`    // B-Book counterparty P/L, the inverse of client net P/L outside A_BOOK positions
+	NetLots           map[string]float64 `json:"netLots"`      // Net client lots per symbol, positive = clients long
+	SymbolExposure    []SymbolExposure   `json:"symbolExposure"`
+	CurrencyExposure  map[string]float64 `json:"currencyExposure"` // Net client position per currency in currency units
+	MarginCallsToday  int                `json:"marginCallsToday"`
+	StopOutsToday     int                `json:"stopOutsToday"`
+	Hedges            map[string]float64 `json:"hedges"`        // Net lots hedged at the LP per symbol
+	HedgeCoverage     float64            `json:"hedgeCoverage"` // Share of net client lots covered by hedges, 0-1
+	GeneratedAt       time.Time          `json:"generatedAt"`
+}
+
+// SetHedgePositionsProvider sets the source of A-Book hedge lots per symbol
+func (h *APIHandler) SetHedgePositionsProvider(fn func() map[string]float64) {
+	h.hedgePositions = fn
+}
+
+// HandleAdminDashboard returns aggregated broker P/L and risk figures
+func (h *APIHandler) HandleAdminDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.authService == nil || !h.authService.IsAdminRequest(r) {
+		http.Error(w, "Admin authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.buildDashboard(time.Now()))
+}
+
+// buildDashboard assembles the dashboard from the engine, exposure analytics and ledger
+func (h *APIHandler) buildDashboard(now time.Time) BrokerDashboard {
+	dashboard := BrokerDashboard{
+		Totals:           h.engine.GetBookTotals(),
+		NetLots:          h.engine.GetNetExposure(),
+		CurrencyExposure: make(map[string]float64),
+		Hedges:           make(map[string]float64),
+		GeneratedAt:      now,
+	}
+
+	for _, entry := range h.engine.GetLedger().GetEntriesByType("REALIZED_PNL", 0) {
+		dashboard.ClientRealizedPnL += entry.Amount
+	}
+	dashboard.ClientNetPnL = dashboard.ClientRealizedPnL + dashboard.Totals.UnrealizedPnL
+	bbookRealized, bbookUnrealized := h.engine.BBookPnL()
+	dashboard.BrokerPnL = -(bbookRealized + bbookUnrealized)
+
+	positions := h.engine.GetAllPositions()
+	dashboard.SymbolExposure = h.calculateSymbolExposures(positions)
+	sort.Slice(dashboard.SymbolExposure, func(i, j int) bool {
+		return dashboard.SymbolExposure[i].Symbol < dashboard.SymbolExposure[j].Symbol
+	})
+	if dashboard.SymbolExposure == nil {
+		dashboard.SymbolExposure = []SymbolExposure{}
+	}
+	h.addCurrencyExposure(dashboard.CurrencyExposure, positions)
+
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	dashboard.MarginCallsToday, dashboard.StopOutsToday = h.engine.RiskEventsSince(startOfDay)
+
+	if h.hedgePositions != nil {
+		dashboard.Hedges = h.hedgePositions()
+	}
+	dashboard.HedgeCoverage = hedgeCoverage(dashboard.NetLots, dashboard.Hedges)
+
+	return dashboard
+}
+
+// addCurrencyExposure splits each position into its base and quote currency legs
+func (h *APIHandler) addCurrencyExposure(exposure map[string]float64, positions []*core.Position) {
+	for _, pos := range positions {
+		spec, ok := h.engine.GetSymbol(pos.Symbol)
+		if !ok {
+			continue
+		}
+		base, quote, ok := currencyLegs(pos.Symbol, spec.Currency)
+		if !ok {
+			continue
+		}
+
+		price := pos.CurrentPrice
+		if price == 0 {
+			price = pos.OpenPrice
+		}
+		units := pos.Volume * spec.ContractSize
+		if pos.Side == "SELL" {
+			units = -units
+		}
+		exposure[base] += units
+		exposure[quote] -= units * price
+	}
+}
+
+// currencyLegs returns the base and quote currency of a symbol such as EURUSD
+// or XAUUSD. Symbols not ending in their quote currency (indices) have no legs.
+func currencyLegs(symbol, quote string) (string, string, bool) {
+	if quote == "" && len(symbol) == 6 {
+		quote = symbol[3:]
+	}
+	if quote == "" || !strings.HasSuffix(symbol, quote) || len(symbol) == len(quote) {
+		return "", "", false
+	}
+	return strings.TrimSuffix(symbol, quote), quote, true
+}
+
+// hedgeCoverage returns the share of net client lots offset by a hedge in the
+// same direction. Hedges beyond a symbol's exposure do not add coverage.
+func hedgeCoverage(netLots, hedges map[string]float64) float64 {
+	var exposed, covered float64
+	for symbol, net := range netLots {
+		exposed += math.Abs(net)
+		if hedge := hedges[symbol]; hedge != 0 && (hedge > 0) == (net > 0) {
+			covered += math.Min(math.Abs(hedge), math.Abs(net))
+		}
+	}
+	if exposed == 0 {
+		return 0
+	}
+	return covered / exposed
+}
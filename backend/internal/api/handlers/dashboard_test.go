@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/auth"
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+// TestAdminDashboardMatchesBook tests that the dashboard figures agree with a
+// seeded B-Book and broker P/L is the inverse of client net P/L
+func TestAdminDashboardMatchesBook(t *testing.T) {
+	engine := core.NewEngine()
+	handler := NewAPIHandler(engine, core.NewPnLEngine(engine))
+	authService := auth.NewService(engine, "", "dashboard-test-secret")
+	handler.SetAuthService(authService)
+	handler.SetHedgePositionsProvider(func() map[string]float64 {
+		return map[string]float64{"EURUSD": 0.25}
+	})
+
+	prices := map[string][2]float64{"EURUSD": {1.1000, 1.1002}, "GBPUSD": {1.2500, 1.2502}}
+	engine.SetPriceCallback(func(symbol string) (float64, float64, bool) {
+		p, ok := prices[symbol]
+		return p[0], p[1], ok
+	})
+
+	alice := engine.CreateAccount("alice", "alice", "password", true)
+	alice.Balance = 10000
+	bob := engine.CreateAccount("bob", "bob", "password", true)
+	bob.Balance = 5000
+	bob.Credit = 500
+
+	if _, err := engine.ExecuteMarketOrder(alice.ID, "EURUSD", "BUY", 1.0, 0, 0); err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	if _, err := engine.ExecuteMarketOrder(bob.ID, "EURUSD", "SELL", 0.5, 0, 0); err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	gbp, err := engine.ExecuteMarketOrder(alice.ID, "GBPUSD", "BUY", 1.0, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+
+	// Close GBPUSD in profit and move EURUSD so the open book floats
+	prices["GBPUSD"] = [2]float64{1.2530, 1.2532}
+	if _, err := engine.ClosePosition(gbp.ID, 0); err != nil {
+		t.Fatalf("ClosePosition() error = %v", err)
+	}
+	prices["EURUSD"] = [2]float64{1.0980, 1.0982}
+	engine.UpdatePrice("EURUSD", 1.0980, 1.0982)
+	engine.RecordStopOut(bob.ID)
+
+	req := httptest.NewRequest("GET", "/admin/dashboard", nil)
+	rec := httptest.NewRecorder()
+	handler.HandleAdminDashboard(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status without admin token = %d, want 401", rec.Code)
+	}
+
+	token, err := authService.GenerateToken(&auth.User{ID: "0", Username: "admin", Role: "ADMIN"})
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec = httptest.NewRecorder()
+	handler.HandleAdminDashboard(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+
+	var dashboard BrokerDashboard
+	if err := json.NewDecoder(rec.Body).Decode(&dashboard); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	var realized, unrealized float64
+	for _, account := range []*core.Account{alice, bob} {
+		for _, trade := range engine.GetTrades(account.ID) {
+			realized += trade.RealizedPnL
+		}
+		for _, pos := range engine.GetPositions(account.ID) {
+			unrealized += pos.UnrealizedPnL
+		}
+	}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
+
+	if realized <= 0 || unrealized >= 0 {
+		t.Fatalf("seeded book realized %.2f / unrealized %.2f, want a profit and a floating loss", realized, unrealized)
+	}
+	if !near(dashboard.ClientRealizedPnL, realized) || !near(dashboard.Totals.UnrealizedPnL, unrealized) {
+		t.Errorf("client P/L = %.2f realized / %.2f floating, want %.2f / %.2f",
+			dashboard.ClientRealizedPnL, dashboard.Totals.UnrealizedPnL, realized, unrealized)
+	}
+	if !near(dashboard.BrokerPnL, -(realized+unrealized)) || !near(dashboard.BrokerPnL, -dashboard.ClientNetPnL) {
+		t.Errorf("broker P/L = %.2f, want %.2f", dashboard.BrokerPnL, -(realized + unrealized))
+	}
+	wantEquity := alice.Balance + bob.Balance + bob.Credit + unrealized
+	if !near(dashboard.Totals.Equity, wantEquity) || dashboard.Totals.Accounts != 2 || dashboard.Totals.OpenPositions != 2 {
+		t.Errorf("totals = %+v, want equity %.2f over 2 accounts and 2 positions", dashboard.Totals, wantEquity)
+	}
+
+	if !near(dashboard.NetLots["EURUSD"], 0.5) {
+		t.Errorf("net EURUSD lots = %.2f, want 0.50", dashboard.NetLots["EURUSD"])
+	}
+	if !near(dashboard.CurrencyExposure["EUR"], 50000) || dashboard.CurrencyExposure["USD"] >= 0 {
+		t.Errorf("currency exposure = %v, want EUR +50000 against USD", dashboard.CurrencyExposure)
+	}
+	if !near(dashboard.HedgeCoverage, 0.5) {
+		t.Errorf("hedge coverage = %.2f, want 0.50", dashboard.HedgeCoverage)
+	}
+	if dashboard.StopOutsToday != 1 || dashboard.MarginCallsToday != 0 {
+		t.Errorf("risk events today = %d margin calls / %d stop-outs, want 0 / 1",
+			dashboard.MarginCallsToday, dashboard.StopOutsToday)
+	}
+}
+
+// TestDashboardBrokerPnLExcludesABook tests that positions routed to the LP do
+// not count toward the broker's counterparty P/L
+func TestDashboardBrokerPnLExcludesABook(t *testing.T) {
+	engine := core.NewEngine()
+	handler := NewAPIHandler(engine, core.NewPnLEngine(engine))
+	engine.SetPriceCallback(func(symbol string) (float64, float64, bool) { return 1.1000, 1.1002, true })
+	engine.SetExposureLimits(core.ExposureLimits{Aggregate: 0.15, Action: core.ExposureActionABook})
+	engine.SetExposureRerouteCallback(func(accountID int64, symbol, side string, volume float64) (string, error) {
+		return "LP-1", nil
+	})
+
+	account := engine.CreateAccount("alice", "alice", "password", true)
+	account.Balance = 10000
+	bbook, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	abook, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0)
+	if err != nil || abook.Book != core.ExposureActionABook {
+		t.Fatalf("second order = %+v, %v; want an A_BOOK position", abook, err)
+	}
+	engine.UpdatePrice("EURUSD", 1.1050, 1.1052)
+
+	dashboard := handler.buildDashboard(time.Now())
+	if math.Abs(dashboard.BrokerPnL+bbook.UnrealizedPnL) > 1e-6 || bbook.UnrealizedPnL <= 0 {
+		t.Errorf("broker P/L = %.2f, want %.2f from the B-Book position only", dashboard.BrokerPnL, -bbook.UnrealizedPnL)
+	}
+	if math.Abs(dashboard.ClientNetPnL-(bbook.UnrealizedPnL+abook.UnrealizedPnL)) > 1e-6 {
+		t.Errorf("client net P/L = %.2f, want both positions", dashboard.ClientNetPnL)
+	}
+}
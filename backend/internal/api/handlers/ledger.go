@@ -10,17 +10,14 @@ import (
 
 // HandleGetTrades returns trade history
 func (h *APIHandler) HandleGetTrades(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
-	accountID := int64(1)
-	if id := r.URL.Query().Get("accountId"); id != "" {
-		if parsed, err := strconv.ParseInt(id, 10, 64); err == nil {
-			accountID = parsed
-		}
+	accountID, ok := h.queryAccount(w, r)
+	if !ok {
+		return
 	}
 
 	trades := h.engine.GetTrades(accountID)
@@ -34,17 +31,14 @@ func (h *APIHandler) HandleGetTrades(w http.ResponseWriter, r *http.Request) {
 
 // HandleGetLedger returns ledger history
 func (h *APIHandler) HandleGetLedger(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
-	accountID := int64(1)
-	if id := r.URL.Query().Get("accountId"); id != "" {
-		if parsed, err := strconv.ParseInt(id, 10, 64); err == nil {
-			accountID = parsed
-		}
+	accountID, ok := h.queryAccount(w, r)
+	if !ok {
+		return
 	}
 
 	limit := 100
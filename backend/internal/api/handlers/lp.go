@@ -22,7 +22,6 @@ func NewLPHandler(manager *lpmanager.Manager) *LPHandler {
 // HandleListLPs returns all LP configurations
 func (h *LPHandler) HandleListLPs(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	config := h.manager.GetConfig()
 	if config == nil {
@@ -36,11 +35,8 @@ func (h *LPHandler) HandleListLPs(w http.ResponseWriter, r *http.Request) {
 // HandleAddLP adds a new LP
 func (h *LPHandler) HandleAddLP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	if r.Method == "OPTIONS" {
-		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 		return
 	}
 
@@ -75,11 +71,8 @@ func (h *LPHandler) HandleAddLP(w http.ResponseWriter, r *http.Request) {
 // HandleUpdateLP updates an existing LP
 func (h *LPHandler) HandleUpdateLP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	if r.Method == "OPTIONS" {
-		w.Header().Set("Access-Control-Allow-Methods", "PUT, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 		return
 	}
 
@@ -115,11 +108,8 @@ func (h *LPHandler) HandleUpdateLP(w http.ResponseWriter, r *http.Request) {
 // HandleDeleteLP removes an LP
 func (h *LPHandler) HandleDeleteLP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	if r.Method == "OPTIONS" {
-		w.Header().Set("Access-Control-Allow-Methods", "DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 		return
 	}
 
@@ -147,11 +137,8 @@ func (h *LPHandler) HandleDeleteLP(w http.ResponseWriter, r *http.Request) {
 // HandleToggleLP enables/disables an LP
 func (h *LPHandler) HandleToggleLP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	if r.Method == "OPTIONS" {
-		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 		return
 	}
 
@@ -189,20 +176,40 @@ func (h *LPHandler) HandleToggleLP(w http.ResponseWriter, r *http.Request) {
 // HandleLPStatus returns status of all LPs
 func (h *LPHandler) HandleLPStatus(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	status := h.manager.GetStatus()
 	json.NewEncoder(w).Encode(status)
 }
 
+// HandleCrossedMarketStats returns the quotes suppressed because the aggregated book was crossed or locked
+func (h *LPHandler) HandleCrossedMarketStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(h.manager.GetCrossedMarketStats())
+}
+
+// HandleBBO returns the best bid/offer of a symbol across all LPs
+func (h *LPHandler) HandleBBO(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	symbol := strings.ToUpper(r.URL.Query().Get("symbol"))
+	if symbol == "" {
+		http.Error(w, "symbol is required", http.StatusBadRequest)
+		return
+	}
+	bbo, ok := h.manager.GetBBO(symbol)
+	if !ok {
+		http.Error(w, "No live quotes for "+symbol, http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(bbo)
+}
+
 // HandleLPSymbols returns available symbols for an LP or updates subscriptions
 func (h *LPHandler) HandleLPSymbols(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	if r.Method == "OPTIONS" {
-		w.Header().Set("Access-Control-Allow-Methods", "GET, PUT, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 		return
 	}
 
@@ -299,11 +306,8 @@ func (h *LPHandler) HandleLPSymbols(w http.ResponseWriter, r *http.Request) {
 // HandleAdminLiquidityProviders returns all LPs with detailed status
 func (h *LPHandler) HandleAdminLiquidityProviders(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	if r.Method == "OPTIONS" {
-		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 		return
 	}
 
@@ -369,11 +373,8 @@ func (h *LPHandler) HandleAdminLiquidityProviders(w http.ResponseWriter, r *http
 // HandleToggleLPByName enables/disables an LP by name
 func (h *LPHandler) HandleToggleLPByName(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	if r.Method == "OPTIONS" {
-		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 		return
 	}
 
@@ -433,11 +434,8 @@ func (h *LPHandler) HandleToggleLPByName(w http.ResponseWriter, r *http.Request)
 // HandleGetLPSubscriptions returns current symbol subscriptions for an LP
 func (h *LPHandler) HandleGetLPSubscriptions(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	if r.Method == "OPTIONS" {
-		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 		return
 	}
 
@@ -492,11 +490,8 @@ func (h *LPHandler) HandleGetLPSubscriptions(w http.ResponseWriter, r *http.Requ
 // HandleUpdateLPSubscriptions updates symbol subscriptions for an LP by name
 func (h *LPHandler) HandleUpdateLPSubscriptions(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	if r.Method == "OPTIONS" {
-		w.Header().Set("Access-Control-Allow-Methods", "PUT, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 		return
 	}
 
@@ -9,7 +9,6 @@ import (
 
 // HandleGetSymbols returns all enabled symbols
 func (h *APIHandler) HandleGetSymbols(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
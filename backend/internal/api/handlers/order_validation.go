@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/epic1st/rtx/backend/cbook"
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+// OrderValidation is the response to an order placed with validateOnly: the
+// pre-trade checks and the routing the order would get, with nothing placed
+type OrderValidation struct {
+	Valid        bool                    `json:"valid"`
+	ValidateOnly bool                    `json:"validateOnly"`
+	Check        *core.OrderCheck        `json:"check"`
+	Routing      *RoutingPreviewResponse `json:"routing,omitempty"` // Omitted without a C-Book engine
+}
+
+// ValidateOrder runs an order's pre-trade checks and routing decision without
+// placing it. An order the C-Book would reject is invalid. A zero price
+// validates a market order at the current quote.
+func (h *APIHandler) ValidateOrder(accountID int64, symbol, side string, volume, price float64) *OrderValidation {
+	side = strings.ToUpper(side)
+	check := h.engine.CheckOrder(accountID, symbol, side, volume, price)
+	result := &OrderValidation{Valid: check.Valid, ValidateOnly: true, Check: check}
+	if !check.Valid || h.cbookEngine == nil {
+		return result
+	}
+
+	routing, err := h.previewRouting(RoutingPreviewRequest{
+		Symbol:    symbol,
+		Volume:    volume,
+		AccountID: accountID,
+		Side:      side,
+	})
+	if err != nil {
+		log.Printf("[API] Routing preview failed for %s: %v", symbol, err)
+		return result
+	}
+	result.Routing = routing
+	if routing.Action == string(cbook.ActionReject) {
+		result.Valid = false
+	}
+	return result
+}
+
+// RespondOrderValidation writes a validateOnly result. Failed checks are
+// reported in the body with 200, like a passing one, so previews need no
+// error handling.
+func RespondOrderValidation(w http.ResponseWriter, result *OrderValidation) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
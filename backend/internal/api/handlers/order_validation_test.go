@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+// TestPlaceMarketOrderValidateOnly tests that validateOnly reports the checks
+// of a market order, passing or not, and places nothing
+func TestPlaceMarketOrderValidateOnly(t *testing.T) {
+	engine := core.NewEngine()
+	handler := NewAPIHandler(engine, core.NewPnLEngine(engine))
+	account := engine.CreateAccount("test-user", "Test User", "password", true)
+	account.Balance = 10000
+	engine.SetPriceCallback(func(symbol string) (bid, ask float64, ok bool) {
+		return 1.1000, 1.1002, true
+	})
+	engine.GetOrCreateSymbol("EURUSD")
+
+	for _, tt := range []struct {
+		volume     float64
+		wantValid  bool
+		wantReason string
+	}{
+		{1, true, ""},
+		{50, false, core.CheckRejectInsufficientMargin},
+	} {
+		body := fmt.Sprintf(`{"accountId":%d,"symbol":"EURUSD","side":"BUY","volume":%v,"validateOnly":true}`, account.ID, tt.volume)
+		w := httptest.NewRecorder()
+		handler.HandlePlaceMarketOrder(w, httptest.NewRequest("POST", "/api/orders/market", strings.NewReader(body)))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%v lots: status = %d, want 200", tt.volume, w.Code)
+		}
+		var result OrderValidation
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if result.Valid != tt.wantValid || !result.ValidateOnly || result.Check == nil || result.Check.Reason != tt.wantReason {
+			t.Errorf("%v lots: result = %+v, want valid %v reason %q", tt.volume, result, tt.wantValid, tt.wantReason)
+		}
+		if result.Check != nil && result.Check.RequiredMargin <= 0 {
+			t.Errorf("%v lots: required margin = %.2f, want the would-be margin", tt.volume, result.Check.RequiredMargin)
+		}
+	}
+
+	if positions := engine.GetPositions(account.ID); len(positions) != 0 {
+		t.Errorf("got %d positions, want none placed", len(positions))
+	}
+}
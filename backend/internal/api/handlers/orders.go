@@ -2,26 +2,26 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
-	"strconv"
+	"strings"
 
+	"github.com/epic1st/rtx/backend/cbook"
 	"github.com/epic1st/rtx/backend/internal/core"
+	"github.com/epic1st/rtx/backend/orders"
 )
 
 // HandleGetOrders returns orders
 func (h *APIHandler) HandleGetOrders(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
-	accountID := int64(1)
-	if id := r.URL.Query().Get("accountId"); id != "" {
-		if parsed, err := strconv.ParseInt(id, 10, 64); err == nil {
-			accountID = parsed
-		}
+	accountID, ok := h.queryAccount(w, r)
+	if !ok {
+		return
 	}
 
 	status := r.URL.Query().Get("status")
@@ -36,7 +36,6 @@ func (h *APIHandler) HandleGetOrders(w http.ResponseWriter, r *http.Request) {
 
 // HandlePlaceMarketOrder executes a market order
 func (h *APIHandler) HandlePlaceMarketOrder(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -49,6 +48,12 @@ func (h *APIHandler) HandlePlaceMarketOrder(w http.ResponseWriter, r *http.Reque
 		Volume    float64 `json:"volume"`
 		SL        float64 `json:"sl,omitempty"`
 		TP        float64 `json:"tp,omitempty"`
+		// Reject instead of partially filling below this share of the volume
+		MinFillRatio float64 `json:"minFillRatio,omitempty"`
+		// Fill at up to this price, confirming a requote
+		AcceptPrice float64 `json:"acceptPrice,omitempty"`
+		// Run the pre-trade checks and routing without placing the order
+		ValidateOnly bool `json:"validateOnly,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -59,8 +64,73 @@ func (h *APIHandler) HandlePlaceMarketOrder(w http.ResponseWriter, r *http.Reque
 	if req.AccountID == 0 {
 		req.AccountID = 1 // Default account
 	}
+	// The token's account takes precedence over the request body
+	accountID, ok := h.authorizeAccount(w, r, req.AccountID)
+	if !ok {
+		return
+	}
+	req.AccountID = accountID
+
+	if h.orderRules != nil {
+		placement := &orders.Placement{
+			Type:         orders.OrderTypeMarket,
+			Side:         orders.OrderSide(strings.ToUpper(req.Side)),
+			SL:           req.SL,
+			TP:           req.TP,
+			MinFillRatio: req.MinFillRatio,
+		}
+		if h.hub != nil {
+			if tick := h.hub.GetLatestPrice(req.Symbol); tick != nil {
+				placement.Price = tick.Ask
+				if placement.Side == orders.OrderSideSell {
+					placement.Price = tick.Bid
+				}
+			}
+		}
+		pipSize := 0.0
+		if spec, ok := h.engine.GetSymbol(req.Symbol); ok {
+			pipSize = spec.PipSize
+		}
+		if err := h.orderRules(req.AccountID).Apply(placement, pipSize); err != nil {
+			log.Printf("[API] Order rejected: %v", err)
+			RespondOrderRejection(w, err)
+			return
+		}
+		req.SL, req.TP, req.MinFillRatio = placement.SL, placement.TP, placement.MinFillRatio
+	}
 
-	position, err := h.engine.ExecuteMarketOrder(req.AccountID, req.Symbol, req.Side, req.Volume, req.SL, req.TP)
+	if req.ValidateOnly {
+		RespondOrderValidation(w, h.ValidateOrder(req.AccountID, req.Symbol, req.Side, req.Volume, 0))
+		return
+	}
+
+	var position *core.Position
+	fill := func(accountID int64, symbol, side string, volume float64) (int64, float64, float64, error) {
+		pos, err := h.engine.ExecuteMarketOrderAt(accountID, symbol, side, volume, req.SL, req.TP, req.MinFillRatio, req.AcceptPrice)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		position = pos
+		return pos.ID, pos.OpenPrice, pos.Volume, nil
+	}
+
+	// With order splitting on, a partial hedge also covers its A-Book share at the LP
+	var split *cbook.SplitExecution
+	var err error
+	if decision := h.splitDecision(req.AccountID, req.Symbol, req.Side, req.Volume); decision != nil {
+		split, err = h.splitRouter.Execute(req.AccountID, req.Symbol, req.Side, req.Volume, decision, fill)
+		if err == nil {
+			h.cbookEngine.UpdateExposure(req.Symbol, strings.ToUpper(req.Side), split.BookVolume(cbook.ActionBBook), cbook.ActionBBook, 100)
+		}
+	} else {
+		_, _, _, err = fill(req.AccountID, req.Symbol, req.Side, req.Volume)
+	}
+	var requote *core.RequoteError
+	if errors.As(err, &requote) {
+		log.Printf("[API] Order requoted: %v", err)
+		respondRequote(w, requote)
+		return
+	}
 	if err != nil {
 		log.Printf("[API] Order rejected: %v", err)
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -72,9 +142,57 @@ func (h *APIHandler) HandlePlaceMarketOrder(w http.ResponseWriter, r *http.Reque
 		h.pnlEngine.ForceUpdate()
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	resp := map[string]interface{}{
 		"success":  true,
 		"position": position,
+	}
+	if split != nil {
+		resp["split"] = split
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// splitDecision routes a market order through the C-Book and returns the
+// decision when it splits the order between the books, nil otherwise
+func (h *APIHandler) splitDecision(accountID int64, symbol, side string, volume float64) *cbook.RoutingDecision {
+	if h.splitRouter == nil || h.cbookEngine == nil || !h.cbookEngine.GetSplitConfig().Enabled {
+		return nil
+	}
+	decision, err := h.cbookEngine.RouteOrder(accountID, "", "", symbol, strings.ToUpper(side), volume, 0)
+	if err != nil || decision.Split == nil {
+		return nil
+	}
+	return decision
+}
+
+// respondRequote offers the client the price a market order fills at, which it
+// confirms by resending the order with that price as its acceptPrice
+func respondRequote(w http.ResponseWriter, requote *core.RequoteError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"code":    core.ErrRequote.Error(),
+		"error":   requote.Error(),
+		"requote": requote,
 	})
 }
+
+// RespondOrderRejection writes a group rule rejection as 403 with its reject
+// code, for every order entry route
+func RespondOrderRejection(w http.ResponseWriter, err error) {
+	resp := map[string]interface{}{
+		"success": false,
+		"error":   err.Error(),
+	}
+	var reject *orders.RejectError
+	if errors.As(err, &reject) {
+		resp["code"] = reject.Code
+		resp["error"] = reject.Message
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -7,22 +7,81 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/epic1st/rtx/backend/auth"
 	"github.com/epic1st/rtx/backend/internal/core"
 )
 
+// AuthorizePosition returns the open position positionID if the request's
+// token may act on it: a trader token only on its own account's positions, an
+// admin token on any. Otherwise it writes the error response and returns false.
+func (h *APIHandler) AuthorizePosition(w http.ResponseWriter, r *http.Request, positionID int64) (*core.Position, bool) {
+	position, ok := h.engine.GetPosition(positionID)
+	if h.authService == nil {
+		if !ok {
+			http.Error(w, fmt.Sprintf("position %d not found", positionID), http.StatusNotFound)
+		}
+		return position, ok
+	}
+
+	claims, authenticated := auth.ClaimsFromContext(r.Context())
+	if !authenticated {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+	// Someone else's position is reported as missing, not as forbidden
+	if !ok || (claims.Role != auth.RoleAdmin && claims.UserID != strconv.FormatInt(position.AccountID, 10)) {
+		http.Error(w, fmt.Sprintf("position %d not found", positionID), http.StatusNotFound)
+		return nil, false
+	}
+	return position, true
+}
+
+// authorizeAccount returns the account a request acts on: a trader token's
+// own account, or for an admin token the requested one. Otherwise it writes
+// the error response and returns false. Every handler binding a request to
+// an account goes through here.
+func (h *APIHandler) authorizeAccount(w http.ResponseWriter, r *http.Request, requested int64) (int64, bool) {
+	if h.authService == nil {
+		return requested, true
+	}
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return 0, false
+	}
+	if claims.Role == auth.RoleAdmin {
+		return requested, true
+	}
+	accountID, ok := claims.AccountID()
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return 0, false
+	}
+	return accountID, true
+}
+
+// queryAccount returns the account of a read request: the ?accountId= an
+// admin token asks for (account 1 by default), or a trader token's own
+func (h *APIHandler) queryAccount(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	requested := int64(1)
+	if id := r.URL.Query().Get("accountId"); id != "" {
+		if parsed, err := strconv.ParseInt(id, 10, 64); err == nil {
+			requested = parsed
+		}
+	}
+	return h.authorizeAccount(w, r, requested)
+}
+
 // HandleGetPositions returns open positions
 func (h *APIHandler) HandleGetPositions(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
-	accountID := int64(1)
-	if id := r.URL.Query().Get("accountId"); id != "" {
-		if parsed, err := strconv.ParseInt(id, 10, 64); err == nil {
-			accountID = parsed
-		}
+	accountID, ok := h.queryAccount(w, r)
+	if !ok {
+		return
 	}
 
 	positions := h.engine.GetPositions(accountID)
@@ -36,7 +95,6 @@ func (h *APIHandler) HandleGetPositions(w http.ResponseWriter, r *http.Request)
 
 // HandleClosePosition closes a position
 func (h *APIHandler) HandleClosePosition(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -64,6 +122,10 @@ func (h *APIHandler) HandleClosePosition(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	if _, ok := h.AuthorizePosition(w, r, req.PositionID); !ok {
+		return
+	}
+
 	trade, err := h.engine.ClosePosition(req.PositionID, req.Volume)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -82,18 +144,23 @@ func (h *APIHandler) HandleClosePosition(w http.ResponseWriter, r *http.Request)
 	})
 }
 
-// HandleCloseBulk closes multiple positions based on filter
+// HandleCloseBulk closes multiple positions: the listed position IDs, or every
+// position matching the symbol, side and type filters, optionally only a
+// volume of them. Each position is reported as closed or failed, with the
+// realized P/L of those closed.
 func (h *APIHandler) HandleCloseBulk(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
 	var req struct {
-		AccountID int64  `json:"accountId"`
-		Type      string `json:"type"`             // ALL, WINNERS, LOSERS
-		Symbol    string `json:"symbol,omitempty"` // Optional: limit to one symbol
+		AccountID   int64   `json:"accountId"`
+		PositionIDs []int64 `json:"positionIds,omitempty"` // Optional: close exactly these positions
+		Type        string  `json:"type,omitempty"`        // ALL (default), WINNERS, LOSERS
+		Symbol      string  `json:"symbol,omitempty"`      // Optional: limit to one symbol
+		Side        string  `json:"side,omitempty"`        // Optional: BUY/LONG or SELL/SHORT positions only
+		Volume      float64 `json:"volume,omitempty"`      // Optional: close only this many lots of the symbol, in FIFO/LIFO order
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -104,61 +171,53 @@ func (h *APIHandler) HandleCloseBulk(w http.ResponseWriter, r *http.Request) {
 	if req.AccountID == 0 {
 		req.AccountID = 1 // Default account
 	}
+	accountID, ok := h.authorizeAccount(w, r, req.AccountID)
+	if !ok {
+		return
+	}
+	req.AccountID = accountID
 
-	positions := h.engine.GetPositions(req.AccountID)
-
-	// Update prices to ensure P/L is fresh
+	// Update prices so WINNERS/LOSERS see fresh P/L
 	h.engine.UpdatePositionPrices()
 
-	closedCount := 0
-	var errors []string
-
-	for _, pos := range positions {
-		// Filter by symbol if provided
-		if req.Symbol != "" && pos.Symbol != req.Symbol {
-			continue
-		}
-
-		shouldClose := false
-		switch req.Type {
-		case "ALL":
-			shouldClose = true
-		case "WINNERS":
-			shouldClose = pos.UnrealizedPnL > 0
-		case "LOSERS":
-			shouldClose = pos.UnrealizedPnL < 0
-		default:
-			// Invalid type
-			http.Error(w, "Invalid Type: must be ALL, WINNERS, or LOSERS", http.StatusBadRequest)
-			return
-		}
+	result, err := h.engine.ClosePositions(req.AccountID, core.BulkCloseFilter{
+		PositionIDs: req.PositionIDs,
+		Symbol:      req.Symbol,
+		Side:        req.Side,
+		Type:        req.Type,
+		Volume:      req.Volume,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-		if shouldClose {
-			_, err := h.engine.ClosePosition(pos.ID, 0) // 0 volume = full close
-			if err != nil {
-				errors = append(errors, fmt.Sprintf("Failed to close position %d: %v", pos.ID, err))
-			} else {
-				closedCount++
-			}
+	var errors []string
+	for _, item := range result.Results {
+		if !item.Closed {
+			errors = append(errors, fmt.Sprintf("Failed to close position %d: %s", item.PositionID, item.Error))
 		}
 	}
 
 	// Force P/L update
-	if h.pnlEngine != nil {
+	if h.pnlEngine != nil && result.ClosedCount > 0 {
 		h.pnlEngine.ForceUpdate()
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success":     true,
-		"closedCount": closedCount,
+		"success":     result.FailedCount == 0,
+		"closedCount": result.ClosedCount,
+		"failedCount": result.FailedCount,
+		"realizedPnL": result.RealizedPnL,
+		"closeOrder":  result.CloseOrder,
+		"results":     result.Results,
 		"errors":      errors,
 	})
 }
 
 // HandleModifyPosition modifes SL/TP
 func (h *APIHandler) HandleModifyPosition(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -187,6 +246,10 @@ func (h *APIHandler) HandleModifyPosition(w http.ResponseWriter, r *http.Request
 		}
 	}
 
+	if _, ok := h.AuthorizePosition(w, r, req.PositionID); !ok {
+		return
+	}
+
 	position, err := h.engine.ModifyPosition(req.PositionID, req.SL, req.TP)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -199,3 +262,38 @@ func (h *APIHandler) HandleModifyPosition(w http.ResponseWriter, r *http.Request
 		"position": position,
 	})
 }
+
+// HandleSetTPLadder configures take-profit tranches on a position
+func (h *APIHandler) HandleSetTPLadder(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var req struct {
+		PositionID    int64          `json:"positionId"`
+		Levels        []core.TPLevel `json:"levels"`
+		TrailDistance float64        `json:"trailDistance"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := h.AuthorizePosition(w, r, req.PositionID); !ok {
+		return
+	}
+
+	position, err := h.engine.SetTPLadder(req.PositionID, req.Levels, req.TrailDistance)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":  true,
+		"position": position,
+	})
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/epic1st/rtx/backend/auth"
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+// TestPositionRoutesCheckOwnership tests that a trader can only modify and
+// close their own positions, while an admin can act on any
+func TestPositionRoutesCheckOwnership(t *testing.T) {
+	engine := core.NewEngine()
+	handler := NewAPIHandler(engine, core.NewPnLEngine(engine))
+	authService := auth.NewService(engine, "", "positions-test-secret")
+	handler.SetAuthService(authService)
+	engine.SetPriceCallback(func(symbol string) (float64, float64, bool) { return 1.1000, 1.1002, true })
+
+	alice := engine.CreateAccount("alice", "alice", "password", true)
+	alice.Balance = 10000
+	bob := engine.CreateAccount("bob", "bob", "password", true)
+	bob.Balance = 10000
+	position, err := engine.ExecuteMarketOrder(alice.ID, "EURUSD", "BUY", 0.1, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+
+	tokenFor := func(id, role string) string {
+		token, err := authService.GenerateToken(&auth.User{ID: id, Username: id, Role: role})
+		if err != nil {
+			t.Fatalf("GenerateToken() error = %v", err)
+		}
+		return token
+	}
+	call := func(route http.HandlerFunc, token, body string) int {
+		req := httptest.NewRequest("POST", "/api/positions", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		authService.RequireRole(auth.RoleTrader, route)(rec, req)
+		return rec.Code
+	}
+
+	modify := fmt.Sprintf(`{"positionId":%d,"sl":1.0900}`, position.ID)
+	if code := call(handler.HandleModifyPosition, "", modify); code != http.StatusUnauthorized {
+		t.Errorf("modify without a token = %d, want 401", code)
+	}
+	if code := call(handler.HandleModifyPosition, tokenFor(strconv.FormatInt(bob.ID, 10), auth.RoleTrader), modify); code != http.StatusNotFound {
+		t.Errorf("modify of another account's position = %d, want 404", code)
+	}
+	if code := call(handler.HandleClosePosition, tokenFor(strconv.FormatInt(bob.ID, 10), auth.RoleTrader),
+		fmt.Sprintf(`{"positionId":%d}`, position.ID)); code != http.StatusNotFound {
+		t.Errorf("close of another account's position = %d, want 404", code)
+	}
+	if _, ok := engine.GetPosition(position.ID); !ok {
+		t.Fatal("another trader closed the position")
+	}
+
+	if code := call(handler.HandleModifyPosition, tokenFor(strconv.FormatInt(alice.ID, 10), auth.RoleTrader), modify); code != http.StatusOK {
+		t.Errorf("modify of own position = %d, want 200", code)
+	}
+	if code := call(handler.HandleClosePosition, tokenFor("0", auth.RoleAdmin),
+		fmt.Sprintf(`{"positionId":%d}`, position.ID)); code != http.StatusOK {
+		t.Errorf("admin close = %d, want 200", code)
+	}
+}
+
+// TestAccountReadRoutesScopeToToken tests that a trader token reads its own
+// orders, positions and trades whatever ?accountId= asks for, while an admin
+// token reads the requested account
+func TestAccountReadRoutesScopeToToken(t *testing.T) {
+	engine := core.NewEngine()
+	handler := NewAPIHandler(engine, core.NewPnLEngine(engine))
+	authService := auth.NewService(engine, "", "positions-test-secret")
+	handler.SetAuthService(authService)
+	engine.SetPriceCallback(func(symbol string) (float64, float64, bool) { return 1.1000, 1.1002, true })
+
+	alice := engine.CreateAccount("alice", "alice", "password", true)
+	alice.Balance = 10000
+	bob := engine.CreateAccount("bob", "bob", "password", true)
+	bob.Balance = 10000
+	if _, err := engine.ExecuteMarketOrder(alice.ID, "EURUSD", "BUY", 0.1, 0, 0); err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	bobPosition, err := engine.ExecuteMarketOrder(bob.ID, "EURUSD", "SELL", 0.2, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+
+	tokenFor := func(id, role string) string {
+		token, err := authService.GenerateToken(&auth.User{ID: id, Username: id, Role: role})
+		if err != nil {
+			t.Fatalf("GenerateToken() error = %v", err)
+		}
+		return token
+	}
+	get := func(route http.HandlerFunc, token string, out interface{}) {
+		t.Helper()
+		req := httptest.NewRequest("GET", fmt.Sprintf("/api/orders?accountId=%d", alice.ID), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		authService.RequireRole(auth.RoleTrader, route)(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		if err := json.NewDecoder(rec.Body).Decode(out); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+	}
+
+	bobToken := tokenFor(strconv.FormatInt(bob.ID, 10), auth.RoleTrader)
+	var orders []core.Order
+	get(handler.HandleGetOrders, bobToken, &orders)
+	if len(orders) != 1 || orders[0].AccountID != bob.ID {
+		t.Errorf("trader orders for another account = %+v, want only bob's", orders)
+	}
+	var positions []core.Position
+	get(handler.HandleGetPositions, bobToken, &positions)
+	if len(positions) != 1 || positions[0].ID != bobPosition.ID {
+		t.Errorf("trader positions for another account = %+v, want only #%d", positions, bobPosition.ID)
+	}
+	var summary core.AccountSummary
+	get(handler.HandleGetAccountSummary, bobToken, &summary)
+	if summary.AccountID != bob.ID {
+		t.Errorf("trader summary for another account = account %d, want %d", summary.AccountID, bob.ID)
+	}
+
+	orders = nil
+	get(handler.HandleGetOrders, tokenFor("0", auth.RoleAdmin), &orders)
+	if len(orders) != 1 || orders[0].AccountID != alice.ID {
+		t.Errorf("admin orders for alice = %+v, want alice's", orders)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/epic1st/rtx/backend/cbook"
+	"github.com/epic1st/rtx/backend/internal/core"
 )
 
 // RoutingPreviewRequest represents a request to preview routing decision
@@ -31,6 +33,14 @@ type RoutingPreviewResponse struct {
 	ExposureRisk    float64 `json:"exposureRisk"`    // Exposure risk level (0-100)
 	DecisionTime    string  `json:"decisionTime"`    // ISO 8601 timestamp
 	ExposureImpact  string  `json:"exposureImpact"`  // Impact on portfolio exposure
+	Split           bool    `json:"split"`           // Order is split between the books
+	ABookVolume     float64 `json:"aBookVolume"`     // Lots covered at the LP
+	BBookVolume     float64 `json:"bBookVolume"`     // Lots internalized
+
+	ExecutionMode       string `json:"executionMode"`       // ABOOK, BBOOK or HYBRID the order resolved to
+	ExecutionModeSource string `json:"executionModeSource"` // GROUP, SYMBOL or GLOBAL
+
+	ExposureLimit *core.ExposureDecision `json:"exposureLimit,omitempty"` // B-Book exposure cap check
 }
 
 // HandleRoutingPreview handles GET /api/routing/preview
@@ -41,11 +51,6 @@ func (h *APIHandler) HandleRoutingPreview(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Set CORS headers
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -126,6 +131,20 @@ func (h *APIHandler) HandleRoutingPreview(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	response, err := h.previewRouting(req)
+	if err != nil {
+		http.Error(w, "Failed to determine routing decision: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return JSON response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// previewRouting returns the routing decision the C-Book engine and exposure
+// limits would make for an order, without executing it
+func (h *APIHandler) previewRouting(req RoutingPreviewRequest) (*RoutingPreviewResponse, error) {
 	// Get routing decision from C-Book engine
 	decision, err := h.cbookEngine.RouteOrder(
 		req.AccountID,
@@ -138,8 +157,7 @@ func (h *APIHandler) HandleRoutingPreview(w http.ResponseWriter, r *http.Request
 	)
 
 	if err != nil {
-		http.Error(w, "Failed to determine routing decision: "+err.Error(), http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 
 	// Convert RoutingDecision to API response
@@ -154,11 +172,38 @@ func (h *APIHandler) HandleRoutingPreview(w http.ResponseWriter, r *http.Request
 		DecisionTime:  decision.DecisionTime.Format("2006-01-02T15:04:05Z07:00"),
 	}
 
+	if decision.ExecutionMode != nil {
+		response.ExecutionMode = decision.ExecutionMode.Mode
+		response.ExecutionModeSource = decision.ExecutionMode.Source
+	}
+
 	// Calculate hedge percent for partial hedge
 	if decision.Action == cbook.ActionPartialHedge {
 		response.HedgePercent = decision.ABookPercent
 	}
 
+	// Leg volumes when order splitting is enabled
+	if decision.Split != nil {
+		response.Split = true
+		response.ABookVolume = decision.Split.ABookVolume
+		response.BBookVolume = decision.Split.BBookVolume
+	}
+
+	// A B-Book fill the exposure limits would block is rejected or flipped to A-Book
+	if h.engine != nil && decision.Action != cbook.ActionABook && decision.Action != cbook.ActionReject {
+		exposure := h.engine.CheckExposure(req.Symbol, strings.ToUpper(req.Side), req.Volume)
+		response.ExposureLimit = &exposure
+		if !exposure.Allowed {
+			response.Action = exposure.Action
+			response.Reason = exposure.Reason
+			response.HedgePercent = 0
+			response.Split, response.ABookVolume, response.BBookVolume = false, 0, 0
+			if exposure.Action == core.ExposureActionABook {
+				response.ABookPercent, response.BBookPercent = 100, 0
+			}
+		}
+	}
+
 	// Determine exposure impact
 	if decision.ExposureRisk > 75 {
 		response.ExposureImpact = "HIGH - Consider hedging"
@@ -168,7 +213,5 @@ func (h *APIHandler) HandleRoutingPreview(w http.ResponseWriter, r *http.Request
 		response.ExposureImpact = "LOW - Within acceptable range"
 	}
 
-	// Return JSON response
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	return &response, nil
 }
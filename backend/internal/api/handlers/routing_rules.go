@@ -73,7 +73,6 @@ type RuleConflict struct {
 
 // HandleListRoutingRules returns paginated list of all routing rules
 func (h *APIHandler) HandleListRoutingRules(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -156,7 +155,6 @@ func (h *APIHandler) HandleListRoutingRules(w http.ResponseWriter, r *http.Reque
 
 // HandleCreateRoutingRule creates a new routing rule
 func (h *APIHandler) HandleCreateRoutingRule(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -238,7 +236,6 @@ func (h *APIHandler) HandleCreateRoutingRule(w http.ResponseWriter, r *http.Requ
 
 // HandleUpdateRoutingRule updates an existing routing rule
 func (h *APIHandler) HandleUpdateRoutingRule(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -353,7 +350,6 @@ func (h *APIHandler) HandleUpdateRoutingRule(w http.ResponseWriter, r *http.Requ
 
 // HandleDeleteRoutingRule deletes a routing rule
 func (h *APIHandler) HandleDeleteRoutingRule(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -396,7 +392,6 @@ func (h *APIHandler) HandleDeleteRoutingRule(w http.ResponseWriter, r *http.Requ
 
 // HandleReorderRoutingRules bulk updates rule priorities
 func (h *APIHandler) HandleReorderRoutingRules(w http.ResponseWriter, r *http.Request) {
-	cors(w)
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
 		return
@@ -454,26 +449,9 @@ func (h *APIHandler) HandleReorderRoutingRules(w http.ResponseWriter, r *http.Re
 
 // Helper functions
 
-// isAdminUser checks if the request contains valid admin credentials
+// isAdminUser reports whether the request carries a valid admin token
 func (h *APIHandler) isAdminUser(r *http.Request) bool {
-	// Check Authorization header for admin token
-	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" {
-		return false
-	}
-
-	// Extract bearer token
-	parts := strings.Split(authHeader, " ")
-	if len(parts) != 2 || parts[0] != "Bearer" {
-		return false
-	}
-
-	token := parts[1]
-
-	// In production, validate JWT token
-	// For now, accept any non-empty token
-	// TODO: Implement proper JWT validation
-	return token != ""
+	return h.authService != nil && h.authService.IsAdminRequest(r)
 }
 
 // getRoutingEngine returns the routing engine from the APIHandler
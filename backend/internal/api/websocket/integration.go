@@ -35,11 +35,6 @@ func RegisterAnalyticsRoutes(hub *AnalyticsHub, mux *http.ServeMux) {
 
 	// WebSocket endpoint for analytics
 	mux.HandleFunc("/ws/analytics", func(w http.ResponseWriter, r *http.Request) {
-		// Enable CORS for WebSocket upgrade
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
 			return
@@ -30,6 +30,15 @@ type LPPerformanceMetrics struct {
 	Uptime           float64 `json:"uptime"` // percentage
 }
 
+// LPSessionState represents a FIX session connection state change
+type LPSessionState struct {
+	Timestamp string `json:"timestamp"`
+	SessionID string `json:"sessionId"`
+	OldStatus string `json:"oldStatus"`
+	NewStatus string `json:"newStatus"` // DISCONNECTED, CONNECTING, CONNECTED, LOGGED_IN
+	Reason    string `json:"reason,omitempty"`
+}
+
 // ExposureUpdate represents real-time exposure changes
 type ExposureUpdate struct {
 	Timestamp      string             `json:"timestamp"`
@@ -70,6 +79,14 @@ func (h *AnalyticsHub) PublishLPPerformance(metrics *LPPerformanceMetrics) {
 	h.Broadcast(ChannelLPPerformance, "lp-metrics", metrics)
 }
 
+// PublishLPSessionState broadcasts a FIX session connection state change
+func (h *AnalyticsHub) PublishLPSessionState(state *LPSessionState) {
+	if state.Timestamp == "" {
+		state.Timestamp = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	h.Broadcast(ChannelLPPerformance, "lp-session-state", state)
+}
+
 // PublishExposureUpdate broadcasts exposure changes
 func (h *AnalyticsHub) PublishExposureUpdate(update *ExposureUpdate) {
 	if update.Timestamp == "" {
@@ -30,15 +30,24 @@ type Config struct {
 	MaxAgeSeconds  int64  // Files older than this will be compressed
 	Schedule       string // Cron-like schedule or duration string
 	MaxConcurrency int
+
+	Retention         RetentionPolicy // Rotation of the daily tick files under DataDir
+	RetentionInterval time.Duration   // How often the retention policy is applied, 0 = never
 }
 
-// Compressor handles tick data compression
+// Compressor handles tick data compression and retention
 type Compressor struct {
 	config  Config
 	metrics *Metrics
 	done    chan struct{}
 	wg      sync.WaitGroup
 	mu      sync.Mutex
+
+	retentionMu   sync.RWMutex
+	retention     RetentionPolicy
+	now           func() time.Time
+	lastSweep     time.Time
+	lastRotations []Rotation
 }
 
 // NewCompressor creates a new compressor instance
@@ -54,9 +63,11 @@ func NewCompressor(config Config) *Compressor {
 	}
 
 	return &Compressor{
-		config:  config,
-		metrics: &Metrics{},
-		done:    make(chan struct{}),
+		config:    config,
+		metrics:   &Metrics{},
+		done:      make(chan struct{}),
+		retention: config.Retention,
+		now:       time.Now,
 	}
 }
 
@@ -92,8 +103,15 @@ func (c *Compressor) recordError(err error) {
 	c.metrics.LastError = err.Error()
 }
 
-// Start begins the compression scheduler
+// Start begins the compression scheduler, and the retention sweeper when a
+// retention interval is set
 func (c *Compressor) Start() {
+	if c.config.RetentionInterval > 0 {
+		c.wg.Add(1)
+		go c.runRetention(c.config.RetentionInterval)
+		log.Printf("[TickRetention] Sweeping %s every %v", c.config.DataDir, c.config.RetentionInterval)
+	}
+
 	if !c.config.Enabled {
 		log.Println("[Compressor] Compression disabled by configuration")
 		return
@@ -219,15 +237,16 @@ func (c *Compressor) compressOldFiles() {
 	)
 }
 
-// compressFile compresses a single file atomically
+// compressFile compresses a single file atomically and returns the size of
+// the compressed file
 // PERFORMANCE FIX #5: Compression error handling with atomic operations
-func (c *Compressor) compressFile(sourceFile string) {
+func (c *Compressor) compressFile(sourceFile string) (int64, error) {
 	// Get file info before compression
 	srcInfo, err := os.Stat(sourceFile)
 	if err != nil {
 		log.Printf("[Compressor] Cannot stat file %s: %v", sourceFile, err)
 		c.recordError(err)
-		return
+		return 0, err
 	}
 
 	srcSize := srcInfo.Size()
@@ -254,7 +273,7 @@ func (c *Compressor) compressFile(sourceFile string) {
 		compressionErr = err
 		log.Printf("[Compressor] Cannot open source file %s: %v", sourceFile, err)
 		c.recordError(err)
-		return
+		return 0, err
 	}
 	defer src.Close()
 
@@ -264,7 +283,7 @@ func (c *Compressor) compressFile(sourceFile string) {
 		compressionErr = err
 		log.Printf("[Compressor] Cannot create temp file %s: %v", tempFile, err)
 		c.recordError(err)
-		return
+		return 0, err
 	}
 	defer dst.Close()
 
@@ -274,7 +293,7 @@ func (c *Compressor) compressFile(sourceFile string) {
 		compressionErr = err
 		log.Printf("[Compressor] Cannot create gzip writer: %v", err)
 		c.recordError(err)
-		return
+		return 0, err
 	}
 
 	// Copy and compress
@@ -284,7 +303,7 @@ func (c *Compressor) compressFile(sourceFile string) {
 		log.Printf("[Compressor] Error during compression of %s: %v", sourceFile, err)
 		c.recordError(err)
 		gzipWriter.Close()
-		return
+		return 0, err
 	}
 	log.Printf("[Compressor] Compressed %d bytes from %s", bytesWritten, sourceFile)
 
@@ -293,14 +312,14 @@ func (c *Compressor) compressFile(sourceFile string) {
 		compressionErr = err
 		log.Printf("[Compressor] Error closing gzip writer: %v", err)
 		c.recordError(err)
-		return
+		return 0, err
 	}
 
 	if err := dst.Close(); err != nil {
 		compressionErr = err
 		log.Printf("[Compressor] Error closing destination file: %v", err)
 		c.recordError(err)
-		return
+		return 0, err
 	}
 
 	// Get compressed file size
@@ -309,7 +328,7 @@ func (c *Compressor) compressFile(sourceFile string) {
 		compressionErr = err
 		log.Printf("[Compressor] Cannot stat compressed file: %v", err)
 		c.recordError(err)
-		return
+		return 0, err
 	}
 
 	dstSize := dstInfo.Size()
@@ -319,7 +338,7 @@ func (c *Compressor) compressFile(sourceFile string) {
 		compressionErr = err
 		log.Printf("[Compressor] Failed to finalize %s: %v", destFile, err)
 		c.recordError(err)
-		return
+		return 0, err
 	}
 
 	// Delete original file only after successful compression and rename
@@ -340,6 +359,7 @@ func (c *Compressor) compressFile(sourceFile string) {
 		float64(dstSize)/1024,
 		compression,
 	)
+	return dstSize, nil
 }
 
 // CompressFile manually compresses a specific file
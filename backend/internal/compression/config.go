@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
@@ -25,11 +26,19 @@ type CompressionConfig struct {
 	MaxConcurrency int    `yaml:"max_concurrency"`
 }
 
-// RetentionPolicy defines retention thresholds
+// RetentionPolicy defines retention thresholds for the daily tick files
+// (SYMBOL/YYYY-MM-DD.json); 0 disables a limit
 type RetentionPolicy struct {
-	ArchiveThresholdDays  int  `yaml:"archive_threshold_days"`
-	DeletionThresholdDays int  `yaml:"deletion_threshold_days"`
-	BackupBeforeDelete    bool `yaml:"backup_before_delete"`
+	ArchiveThresholdDays  int    `yaml:"archive_threshold_days" json:"archiveThresholdDays"`   // Gzip files older than this
+	DeletionThresholdDays int    `yaml:"deletion_threshold_days" json:"deletionThresholdDays"` // Delete files, archived or not, older than this
+	MaxMBPerSymbol        int    `yaml:"max_mb_per_symbol" json:"maxMbPerSymbol"`              // Oldest files are archived, then deleted, while a symbol uses more
+	BackupBeforeDelete    bool   `yaml:"backup_before_delete" json:"-"`
+	SweepInterval         string `yaml:"sweep_interval" json:"-"`
+}
+
+// Enabled reports whether the policy limits anything
+func (p RetentionPolicy) Enabled() bool {
+	return p.ArchiveThresholdDays > 0 || p.DeletionThresholdDays > 0 || p.MaxMBPerSymbol > 0
 }
 
 // PathsConfig defines directory paths
@@ -86,6 +95,12 @@ func LoadRetentionConfig(configPath string) (*RetentionConfig, error) {
 	if config.Compression.Schedule == "" {
 		config.Compression.Schedule = "168h" // 1 week
 	}
+	if config.Retention.SweepInterval == "" {
+		config.Retention.SweepInterval = "1h"
+	}
+	if _, err := time.ParseDuration(config.Retention.SweepInterval); err != nil {
+		return nil, fmt.Errorf("invalid retention.sweep_interval %q: %w", config.Retention.SweepInterval, err)
+	}
 
 	// Make paths absolute relative to config directory
 	if !filepath.IsAbs(config.Paths.TicksDirectory) {
@@ -100,13 +115,24 @@ func LoadRetentionConfig(configPath string) (*RetentionConfig, error) {
 	return &config, nil
 }
 
-// ToCompressorConfig converts RetentionConfig to Compressor Config
+// ToCompressorConfig converts RetentionConfig to Compressor Config. Disabled
+// archival or deletion operations turn off the matching age threshold.
 func (rc *RetentionConfig) ToCompressorConfig() Config {
+	retention := rc.Retention
+	if !rc.Operations.EnableArchival {
+		retention.ArchiveThresholdDays = 0
+	}
+	if !rc.Operations.EnableDeletion {
+		retention.DeletionThresholdDays = 0
+	}
+	interval, _ := time.ParseDuration(retention.SweepInterval) // Validated on load
 	return Config{
-		Enabled:        rc.Compression.Enabled,
-		DataDir:        rc.Paths.TicksDirectory,
-		MaxAgeSeconds:  rc.Compression.MaxAgeSeconds,
-		Schedule:       rc.Compression.Schedule,
-		MaxConcurrency: rc.Compression.MaxConcurrency,
+		Enabled:           rc.Compression.Enabled,
+		DataDir:           rc.Paths.TicksDirectory,
+		MaxAgeSeconds:     rc.Compression.MaxAgeSeconds,
+		Schedule:          rc.Compression.Schedule,
+		MaxConcurrency:    rc.Compression.MaxConcurrency,
+		Retention:         retention,
+		RetentionInterval: interval,
 	}
 }
@@ -0,0 +1,276 @@
+package compression
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Rotation actions and reasons
+const (
+	RotationArchived = "ARCHIVED"
+	RotationDeleted  = "DELETED"
+
+	RotationReasonAge   = "AGE"
+	RotationReasonQuota = "QUOTA"
+)
+
+// SymbolUsage is the disk used by a symbol's daily tick files
+type SymbolUsage struct {
+	Symbol   string `json:"symbol"`
+	Files    int    `json:"files"`
+	Archived int    `json:"archived"`
+	Bytes    int64  `json:"bytes"`
+	Oldest   string `json:"oldest,omitempty"`
+	Newest   string `json:"newest,omitempty"`
+}
+
+// Rotation is one daily file archived or deleted by a retention sweep
+type Rotation struct {
+	Symbol string    `json:"symbol"`
+	Date   string    `json:"date"`
+	Action string    `json:"action"`
+	Reason string    `json:"reason"`
+	Freed  int64     `json:"freed"` // Bytes released
+	At     time.Time `json:"at"`
+}
+
+// dailyFile is one day of a symbol's ticks on disk
+type dailyFile struct {
+	date     string
+	bytes    int64 // -1 once deleted
+	archived bool  // Gzipped (.json.gz)
+}
+
+// SetRetentionPolicy replaces the retention policy, applied from the next sweep
+func (c *Compressor) SetRetentionPolicy(policy RetentionPolicy) {
+	c.retentionMu.Lock()
+	defer c.retentionMu.Unlock()
+	c.retention = policy
+}
+
+// GetRetentionPolicy returns the current retention policy
+func (c *Compressor) GetRetentionPolicy() RetentionPolicy {
+	c.retentionMu.RLock()
+	defer c.retentionMu.RUnlock()
+	return c.retention
+}
+
+// LastSweep returns when the last retention sweep ran and the files it rotated
+func (c *Compressor) LastSweep() (time.Time, []Rotation) {
+	c.retentionMu.RLock()
+	defer c.retentionMu.RUnlock()
+	rotations := make([]Rotation, len(c.lastRotations))
+	copy(rotations, c.lastRotations)
+	return c.lastSweep, rotations
+}
+
+// runRetention sweeps now and then every interval until Stop
+func (c *Compressor) runRetention(interval time.Duration) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	c.SweepRetention()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.SweepRetention()
+		}
+	}
+}
+
+// SweepRetention rotates the daily files exceeding the retention policy across
+// all symbols: files past the age thresholds first, then the oldest files
+// while a symbol is over quota. Today's file is never touched: the store is
+// still writing it.
+func (c *Compressor) SweepRetention() []Rotation {
+	policy := c.GetRetentionPolicy()
+
+	var rotations []Rotation
+	if policy.Enabled() {
+		now := c.now()
+		for _, symbol := range c.symbols() {
+			rotations = append(rotations, c.sweepSymbol(symbol, policy, now)...)
+		}
+	}
+
+	c.retentionMu.Lock()
+	c.lastSweep = c.now()
+	c.lastRotations = rotations
+	c.retentionMu.Unlock()
+	return rotations
+}
+
+// Usage returns the disk used by each symbol's daily files, largest first
+func (c *Compressor) Usage() []SymbolUsage {
+	var usage []SymbolUsage
+	for _, symbol := range c.symbols() {
+		files := listDailyFiles(filepath.Join(c.config.DataDir, symbol))
+		if len(files) == 0 {
+			continue
+		}
+		u := SymbolUsage{Symbol: symbol, Files: len(files), Oldest: files[0].date, Newest: files[len(files)-1].date}
+		for _, f := range files {
+			u.Bytes += f.bytes
+			if f.archived {
+				u.Archived++
+			}
+		}
+		usage = append(usage, u)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Bytes == usage[j].Bytes {
+			return usage[i].Symbol < usage[j].Symbol
+		}
+		return usage[i].Bytes > usage[j].Bytes
+	})
+	return usage
+}
+
+// symbols returns the symbol directories under the data directory
+func (c *Compressor) symbols() []string {
+	dirs, err := os.ReadDir(c.config.DataDir)
+	if err != nil {
+		return nil
+	}
+	var symbols []string
+	for _, d := range dirs {
+		if d.IsDir() {
+			symbols = append(symbols, d.Name())
+		}
+	}
+	return symbols
+}
+
+// sweepSymbol applies policy to one symbol's files
+func (c *Compressor) sweepSymbol(symbol string, policy RetentionPolicy, now time.Time) []Rotation {
+	dir := filepath.Join(c.config.DataDir, symbol)
+	files := listDailyFiles(dir)
+	today := now.Format("2006-01-02")
+
+	var rotations []Rotation
+	archive := func(i int, reason string) {
+		f := &files[i]
+		path := filepath.Join(dir, f.date+".json")
+		size, err := c.compressFile(path)
+		if err != nil {
+			return // Logged and counted by compressFile
+		}
+		rotations = append(rotations, Rotation{Symbol: symbol, Date: f.date, Action: RotationArchived, Reason: reason, Freed: f.bytes - size, At: now})
+		log.Printf("[TickRetention] Archived %s (%s, %d -> %d bytes)", path, reason, f.bytes, size)
+		f.bytes, f.archived = size, true
+	}
+	remove := func(i int, reason string) {
+		f := &files[i]
+		path := dailyFilePath(dir, *f)
+		if err := os.Remove(path); err != nil {
+			log.Printf("[TickRetention] Failed to delete %s: %v", path, err)
+			return
+		}
+		rotations = append(rotations, Rotation{Symbol: symbol, Date: f.date, Action: RotationDeleted, Reason: reason, Freed: f.bytes, At: now})
+		log.Printf("[TickRetention] Deleted %s (%s, %d bytes)", path, reason, f.bytes)
+		f.bytes = -1
+	}
+	olderThan := func(f dailyFile, days int) bool {
+		return days > 0 && f.date < now.AddDate(0, 0, -days).Format("2006-01-02") && f.date < today
+	}
+
+	for i := range files {
+		switch {
+		case olderThan(files[i], policy.DeletionThresholdDays):
+			remove(i, RotationReasonAge)
+		case olderThan(files[i], policy.ArchiveThresholdDays) && !files[i].archived:
+			archive(i, RotationReasonAge)
+		}
+	}
+
+	if policy.MaxMBPerSymbol > 0 {
+		quota := int64(policy.MaxMBPerSymbol) << 20
+		// The oldest files are compressed first and archives deleted only if
+		// the symbol is still over quota
+		for i := range files {
+			if usedBytes(files) <= quota || files[i].date >= today {
+				break
+			}
+			if files[i].bytes >= 0 && !files[i].archived {
+				archive(i, RotationReasonQuota)
+			}
+		}
+		for i := range files {
+			if usedBytes(files) <= quota || files[i].date >= today {
+				break
+			}
+			if files[i].bytes >= 0 {
+				remove(i, RotationReasonQuota)
+			}
+		}
+	}
+	return rotations
+}
+
+// usedBytes totals the files still on disk
+func usedBytes(files []dailyFile) int64 {
+	var total int64
+	for _, f := range files {
+		if f.bytes > 0 {
+			total += f.bytes
+		}
+	}
+	return total
+}
+
+// listDailyFiles returns the daily tick files in dir, oldest first
+func listDailyFiles(dir string) []dailyFile {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var files []dailyFile
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		f := dailyFile{}
+		switch {
+		case strings.HasSuffix(name, ".json.gz"):
+			f.date, f.archived = strings.TrimSuffix(name, ".json.gz"), true
+		case strings.HasSuffix(name, ".json"):
+			f.date = strings.TrimSuffix(name, ".json")
+		default:
+			continue // In-flight .tmp writes and anything else
+		}
+		if _, err := time.Parse("2006-01-02", f.date); err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		f.bytes = info.Size()
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].date == files[j].date {
+			return !files[i].archived
+		}
+		return files[i].date < files[j].date
+	})
+	return files
+}
+
+// dailyFilePath returns the path of a daily file in dir
+func dailyFilePath(dir string, f dailyFile) string {
+	if f.archived {
+		return filepath.Join(dir, f.date+".json.gz")
+	}
+	return filepath.Join(dir, f.date+".json")
+}
@@ -0,0 +1,94 @@
+package compression
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeDailyFiles writes one file of size bytes per date under base/symbol
+func writeDailyFiles(t *testing.T, base, symbol string, size int, dates ...string) {
+	t.Helper()
+	dir := filepath.Join(base, symbol)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte(strings.Repeat(`{"bid":1.1}`, size/11+1)[:size])
+	for _, date := range dates {
+		if err := os.WriteFile(filepath.Join(dir, date+".json"), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// newTestCompressor returns a compressor over a temp dir whose clock reads 2026-03-10
+func newTestCompressor(t *testing.T, policy RetentionPolicy) (*Compressor, string) {
+	t.Helper()
+	base := t.TempDir()
+	c := NewCompressor(Config{DataDir: base, Retention: policy})
+	c.now = func() time.Time { return time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local) }
+	return c, base
+}
+
+// TestRetentionByAge tests that files past the deletion threshold are deleted,
+// files past the archive threshold gzipped and newer ones kept
+func TestRetentionByAge(t *testing.T) {
+	c, base := newTestCompressor(t, RetentionPolicy{ArchiveThresholdDays: 2, DeletionThresholdDays: 5})
+	writeDailyFiles(t, base, "EURUSD", 100, "2026-03-01", "2026-03-06", "2026-03-08", "2026-03-10")
+
+	rotations := c.SweepRetention()
+	if len(rotations) != 2 || rotations[0].Date != "2026-03-01" || rotations[0].Action != RotationDeleted ||
+		rotations[1].Date != "2026-03-06" || rotations[1].Action != RotationArchived {
+		t.Fatalf("rotations = %+v, want 03-01 deleted and 03-06 archived", rotations)
+	}
+
+	f, err := os.Open(filepath.Join(base, "EURUSD", "2026-03-06.json.gz"))
+	if err != nil {
+		t.Fatalf("archive missing: %v", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := io.ReadAll(zr); err != nil || len(data) != 100 {
+		t.Errorf("archived file = %d bytes (%v), want the original 100", len(data), err)
+	}
+
+	// Already archived: a second sweep leaves it alone
+	if rotations := c.SweepRetention(); len(rotations) != 0 {
+		t.Errorf("second sweep rotations = %+v, want none", rotations)
+	}
+}
+
+// TestRetentionQuotaSparesToday tests that the quota archives, then deletes,
+// the oldest files first and never today's file, even when it alone exceeds
+// the quota
+func TestRetentionQuotaSparesToday(t *testing.T) {
+	c, base := newTestCompressor(t, RetentionPolicy{MaxMBPerSymbol: 1})
+	writeDailyFiles(t, base, "EURUSD", 600<<10, "2026-03-08", "2026-03-09")
+	writeDailyFiles(t, base, "EURUSD", 1200<<10, "2026-03-10")
+	writeDailyFiles(t, base, "GBPUSD", 100, "2026-03-09")
+
+	rotations := c.SweepRetention()
+	var actions []string
+	for _, r := range rotations {
+		if r.Symbol != "EURUSD" || r.Reason != RotationReasonQuota {
+			t.Errorf("rotation = %+v, want EURUSD over quota", r)
+		}
+		actions = append(actions, r.Date+" "+r.Action)
+	}
+	want := "2026-03-08 ARCHIVED,2026-03-09 ARCHIVED,2026-03-08 DELETED,2026-03-09 DELETED"
+	if got := strings.Join(actions, ","); got != want {
+		t.Fatalf("rotations = %s, want %s", got, want)
+	}
+
+	usage := c.Usage()
+	if len(usage) != 2 || usage[0].Symbol != "EURUSD" || usage[0].Files != 1 || usage[0].Newest != "2026-03-10" || usage[0].Bytes != 1200<<10 {
+		t.Errorf("usage = %+v, want EURUSD left with today's file", usage)
+	}
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestAccountSummaryPnLBreakdown tests that equity carries the floating P/L
+// of an open position and the balance the realized P/L once it is closed
+func TestAccountSummaryPnLBreakdown(t *testing.T) {
+	engine := NewEngine()
+	quote := [2]float64{1.1000, 1.1002}
+	engine.SetPriceCallback(func(symbol string) (float64, float64, bool) {
+		return quote[0], quote[1], symbol == "EURUSD"
+	})
+	account := engine.CreateAccount("user1", "trader", "password", true)
+	account.Balance = 10000
+
+	pos, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 1.0, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	quote = [2]float64{1.1050, 1.1052}
+	engine.UpdatePositionPrices()
+
+	summary, err := engine.GetAccountSummary(account.ID)
+	if err != nil {
+		t.Fatalf("GetAccountSummary() error = %v", err)
+	}
+	if summary.UnrealizedPnL <= 0 || math.Abs(summary.UnrealizedPnL-pos.UnrealizedPnL) > 1e-9 {
+		t.Errorf("floating P/L = %.2f, want the position's %.2f profit", summary.UnrealizedPnL, pos.UnrealizedPnL)
+	}
+	if math.Abs(summary.Equity-(summary.Balance+summary.Credit+summary.UnrealizedPnL)) > 1e-9 {
+		t.Errorf("equity %.2f != balance %.2f + floating %.2f", summary.Equity, summary.Balance, summary.UnrealizedPnL)
+	}
+	if summary.Margin <= 0 || math.Abs(summary.FreeMargin-(summary.Equity-summary.Margin)) > 1e-9 {
+		t.Errorf("free margin %.2f != equity %.2f - margin %.2f", summary.FreeMargin, summary.Equity, summary.Margin)
+	}
+	if math.Abs(summary.MarginLevel-summary.Equity/summary.Margin*100) > 1e-9 {
+		t.Errorf("margin level = %.2f%%, want equity/margin", summary.MarginLevel)
+	}
+	if summary.RealizedPnLToday != 0 {
+		t.Errorf("realized today = %.2f before any close, want 0", summary.RealizedPnLToday)
+	}
+
+	balanceBefore := summary.Balance
+	trade, err := engine.ClosePosition(pos.ID, 0)
+	if err != nil {
+		t.Fatalf("ClosePosition() error = %v", err)
+	}
+
+	summary, _ = engine.GetAccountSummary(account.ID)
+	if want := balanceBefore + trade.RealizedPnL - trade.Commission; math.Abs(summary.Balance-want) > 1e-9 {
+		t.Errorf("balance = %.2f after close, want %.2f", summary.Balance, want)
+	}
+	if math.Abs(summary.RealizedPnLToday-trade.RealizedPnL) > 1e-9 || trade.RealizedPnL <= 0 {
+		t.Errorf("realized today = %.2f, want the closing trade's %.2f", summary.RealizedPnLToday, trade.RealizedPnL)
+	}
+	if summary.UnrealizedPnL != 0 || summary.Equity != summary.Balance+summary.Credit {
+		t.Errorf("floating P/L = %.2f equity = %.2f after close, want flat", summary.UnrealizedPnL, summary.Equity)
+	}
+}
+
+// TestRealizedPnLTodayStartsAtRollover tests that the trading day of
+// RealizedPnLToday starts at the last rollover, not at midnight
+func TestRealizedPnLTodayStartsAtRollover(t *testing.T) {
+	engine, account := newTestEngine(t)
+	now := time.Now().UTC()
+	// Rolls over an hour from now, so the trading day began ~23h ago
+	engine.rolloverSchedule = RolloverSchedule{Hour: (now.Hour() + 1) % 24, TripleSwapDay: time.Wednesday}
+	dayStart := engine.rolloverSchedule.Last(now)
+
+	for _, offset := range []time.Duration{-time.Minute, time.Minute} {
+		pos, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0)
+		if err != nil {
+			t.Fatalf("ExecuteMarketOrder() error = %v", err)
+		}
+		if _, err := engine.ClosePosition(pos.ID, 0); err != nil {
+			t.Fatalf("ClosePosition() error = %v", err)
+		}
+		entries := engine.ledger.entries[account.ID]
+		entries[len(entries)-1].Amount = 10
+		entries[len(entries)-1].CreatedAt = dayStart.Add(offset)
+	}
+
+	summary, _ := engine.GetAccountSummary(account.ID)
+	if summary.RealizedPnLToday != 10 {
+		t.Errorf("realized today = %.2f, want only the 10 booked after the rollover", summary.RealizedPnLToday)
+	}
+}
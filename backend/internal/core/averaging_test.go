@@ -0,0 +1,95 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+// newNettingTestEngine returns a netting account on an engine whose EURUSD
+// quote the test moves
+func newNettingTestEngine(t *testing.T) (*Engine, *Account, func(bid, ask float64)) {
+	t.Helper()
+
+	engine := NewEngine()
+	quote := [2]float64{1.1000, 1.1002}
+	engine.SetPriceCallback(func(symbol string) (float64, float64, bool) {
+		return quote[0], quote[1], symbol == "EURUSD"
+	})
+
+	account := engine.CreateAccount("user1", "trader", "password", true)
+	account.Balance = 10000
+	if err := engine.UpdateAccount(account.ID, 0, MarginModeNetting); err != nil {
+		t.Fatalf("UpdateAccount() error = %v", err)
+	}
+	return engine, account, func(bid, ask float64) { quote = [2]float64{bid, ask} }
+}
+
+func placeNetting(t *testing.T, engine *Engine, accountID int64, side string, volume float64) *Position {
+	t.Helper()
+	pos, err := engine.ExecuteMarketOrder(accountID, "EURUSD", side, volume, 0, 0)
+	if err != nil {
+		t.Fatalf("%s %.2f error = %v", side, volume, err)
+	}
+	return pos
+}
+
+func assertNetPosition(t *testing.T, engine *Engine, accountID int64, side string, volume, openPrice float64) *Position {
+	t.Helper()
+	positions := engine.GetPositions(accountID)
+	if len(positions) != 1 {
+		t.Fatalf("open positions = %d, want 1", len(positions))
+	}
+	pos := positions[0]
+	if pos.Side != side || math.Abs(pos.Volume-volume) > 1e-9 || math.Abs(pos.OpenPrice-openPrice) > 1e-9 {
+		t.Fatalf("position = %s %.2f @ %.5f, want %s %.2f @ %.5f", pos.Side, pos.Volume, pos.OpenPrice, side, volume, openPrice)
+	}
+	return pos
+}
+
+// TestAveragingAddToWinner tests that adding to a winning position raises its
+// entry to the volume-weighted average
+func TestAveragingAddToWinner(t *testing.T) {
+	engine, account, setQuote := newNettingTestEngine(t)
+	first := placeNetting(t, engine, account.ID, "BUY", 1.0)
+
+	setQuote(1.1100, 1.1102)
+	added := placeNetting(t, engine, account.ID, "BUY", 1.0)
+	if added.ID != first.ID {
+		t.Errorf("add opened position #%d, want #%d averaged", added.ID, first.ID)
+	}
+	pos := assertNetPosition(t, engine, account.ID, "BUY", 2.0, 1.1052)
+
+	// P/L runs against the averaged entry: (1.1100 - 1.1052) on 2 lots
+	engine.UpdatePositionPrices()
+	want := engine.calculatePnL(pos, 1.1100, 2.0, engine.symbols["EURUSD"])
+	if math.Abs(pos.UnrealizedPnL-want) > 1e-6 || want <= 0 {
+		t.Errorf("unrealized P/L = %.2f, want %.2f", pos.UnrealizedPnL, want)
+	}
+}
+
+// TestAveragingAddToLoser tests that adding to a losing position lowers its entry
+func TestAveragingAddToLoser(t *testing.T) {
+	engine, account, setQuote := newNettingTestEngine(t)
+	placeNetting(t, engine, account.ID, "BUY", 1.0)
+
+	setQuote(1.0900, 1.0902)
+	placeNetting(t, engine, account.ID, "BUY", 3.0)
+	assertNetPosition(t, engine, account.ID, "BUY", 4.0, (1.1002+3*1.0902)/4)
+}
+
+// TestAveragingFlipThroughFlat tests that an opposite order larger than the
+// position closes it and opens the rest the other way at the new price
+func TestAveragingFlipThroughFlat(t *testing.T) {
+	engine, account, setQuote := newNettingTestEngine(t)
+	long := placeNetting(t, engine, account.ID, "BUY", 1.0)
+
+	setQuote(1.1050, 1.1052)
+	short := placeNetting(t, engine, account.ID, "SELL", 3.0)
+	if short.ID == long.ID {
+		t.Fatal("flip reused the closed long position")
+	}
+	if long.Status != "CLOSED" {
+		t.Errorf("long status = %s, want CLOSED", long.Status)
+	}
+	assertNetPosition(t, engine, account.ID, "SELL", 2.0, 1.1050)
+}
@@ -0,0 +1,183 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+)
+
+// Bonus statuses
+const (
+	BonusStatusPending  = "PENDING"  // Credit: supports margin, not withdrawable
+	BonusStatusReleased = "RELEASED" // Converted to withdrawable balance
+)
+
+// Bonus is a credit-style bonus that counts toward equity and margin but is
+// only converted to withdrawable balance once the account has traded the
+// required volume
+type Bonus struct {
+	ID             int64      `json:"id"`
+	AccountID      int64      `json:"accountId"`
+	Amount         float64    `json:"amount"`
+	RequiredVolume float64    `json:"requiredVolume"` // Lots to close before release
+	TradedVolume   float64    `json:"tradedVolume"`
+	Status         string     `json:"status"`
+	Description    string     `json:"description"`
+	AdminID        string     `json:"adminId,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	ReleasedAt     *time.Time `json:"releasedAt,omitempty"`
+}
+
+// AddCreditBonus grants a bonus as account credit, released into balance once
+// requiredVolume lots have been closed on the account. A zero requiredVolume
+// releases it immediately.
+func (e *Engine) AddCreditBonus(accountID int64, amount, requiredVolume float64, description, adminID string) (*Bonus, error) {
+	if amount <= 0 {
+		return nil, errors.New("bonus amount must be positive")
+	}
+	if requiredVolume < 0 {
+		return nil, errors.New("required volume cannot be negative")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	account, ok := e.accounts[accountID]
+	if !ok {
+		return nil, errors.New("account not found")
+	}
+
+	bonus := &Bonus{
+		ID:             e.nextBonusID,
+		AccountID:      accountID,
+		Amount:         amount,
+		RequiredVolume: requiredVolume,
+		Status:         BonusStatusPending,
+		Description:    description,
+		AdminID:        adminID,
+		CreatedAt:      time.Now(),
+	}
+	e.nextBonusID++
+	e.bonuses[accountID] = append(e.bonuses[accountID], bonus)
+	account.Credit += amount
+	e.persistAccountUnlocked(account)
+	e.persistBonusUnlocked(bonus)
+
+	log.Printf("[B-Book] CREDIT BONUS: Account #%d +%.2f, released after %.2f lots | Credit: %.2f",
+		accountID, amount, requiredVolume, account.Credit)
+
+	if requiredVolume == 0 {
+		e.releaseBonusUnlocked(account, bonus)
+	}
+	return bonus, nil
+}
+
+// GetBonuses returns the credit bonuses granted to an account
+func (e *Engine) GetBonuses(accountID int64) []*Bonus {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	result := make([]*Bonus, len(e.bonuses[accountID]))
+	copy(result, e.bonuses[accountID])
+	return result
+}
+
+// WithdrawableBalance returns how much can be withdrawn from an account: the
+// balance, capped by the free margin left without the bonus credit
+func (e *Engine) WithdrawableBalance(accountID int64) (float64, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.withdrawableBalanceUnlocked(accountID)
+}
+
+// withdrawableBalanceUnlocked returns the withdrawable balance of an account (caller must hold lock)
+func (e *Engine) withdrawableBalanceUnlocked(accountID int64) (float64, error) {
+	account, ok := e.accounts[accountID]
+	if !ok {
+		return 0, errors.New("account not found")
+	}
+	summary, err := e.getAccountSummaryUnlocked(accountID)
+	if err != nil {
+		return 0, err
+	}
+
+	withdrawable := math.Min(account.Balance, summary.FreeMargin-account.Credit)
+	return math.Max(0, withdrawable), nil
+}
+
+// CheckWithdrawal rejects a withdrawal exceeding the withdrawable balance
+func (e *Engine) CheckWithdrawal(accountID int64, amount float64) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.checkWithdrawalUnlocked(accountID, amount)
+}
+
+// checkWithdrawalUnlocked rejects a withdrawal exceeding the withdrawable balance (caller must hold lock)
+func (e *Engine) checkWithdrawalUnlocked(accountID int64, amount float64) error {
+	withdrawable, err := e.withdrawableBalanceUnlocked(accountID)
+	if err != nil {
+		return err
+	}
+	if amount > withdrawable {
+		return fmt.Errorf("withdrawal of %.2f exceeds withdrawable balance %.2f", amount, withdrawable)
+	}
+	return nil
+}
+
+// Withdraw debits a withdrawal through the ledger once it passes
+// CheckWithdrawal. The check and the debit share the engine lock, so a
+// concurrent withdrawal or order cannot spend the same free margin.
+func (e *Engine) Withdraw(accountID int64, amount float64, method, ref, description, adminID string) (*LedgerEntry, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	account, ok := e.accounts[accountID]
+	if !ok {
+		return nil, errors.New("account not found")
+	}
+	if err := e.checkWithdrawalUnlocked(accountID, amount); err != nil {
+		return nil, err
+	}
+
+	entry, err := e.ledger.Withdraw(accountID, amount, method, ref, description, adminID)
+	if err != nil {
+		return nil, err
+	}
+	account.Balance = entry.BalanceAfter
+	e.persistAccountUnlocked(account)
+	return entry, nil
+}
+
+// recordBonusVolumeUnlocked counts closed volume toward the account's pending
+// bonuses and releases those whose condition is met (caller must hold lock)
+func (e *Engine) recordBonusVolumeUnlocked(account *Account, volume float64) {
+	for _, bonus := range e.bonuses[account.ID] {
+		if bonus.Status != BonusStatusPending {
+			continue
+		}
+		bonus.TradedVolume += volume
+		// Tolerate float noise from summing lot sizes
+		if bonus.TradedVolume+1e-9 >= bonus.RequiredVolume {
+			e.releaseBonusUnlocked(account, bonus)
+		} else {
+			e.persistBonusUnlocked(bonus)
+		}
+	}
+}
+
+// releaseBonusUnlocked converts a pending bonus from credit into balance (caller must hold lock)
+func (e *Engine) releaseBonusUnlocked(account *Account, bonus *Bonus) {
+	now := time.Now()
+	bonus.Status = BonusStatusReleased
+	bonus.ReleasedAt = &now
+
+	account.Credit -= bonus.Amount
+	account.Balance += bonus.Amount
+	e.ledger.AddBonus(account.ID, bonus.Amount, fmt.Sprintf("Bonus #%d released: %s", bonus.ID, bonus.Description), bonus.AdminID)
+	e.persistAccountUnlocked(account)
+	e.persistBonusUnlocked(bonus)
+
+	log.Printf("[B-Book] BONUS RELEASED: Account #%d bonus #%d %.2f after %.2f lots", account.ID, bonus.ID, bonus.Amount, bonus.TradedVolume)
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestCreditBonusSupportsMarginUntilReleased tests that a credit bonus backs margin but is only
+// withdrawable once its volume condition is met
+func TestCreditBonusSupportsMarginUntilReleased(t *testing.T) {
+	engine, account := newTestEngine(t)
+	account.Balance = 100
+
+	// 1 lot of EURUSD at 1:100 needs ~1100 margin, more than the balance alone
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 1.0, 0, 0); err == nil {
+		t.Fatal("order accepted without enough margin")
+	}
+
+	bonus, err := engine.AddCreditBonus(account.ID, 2000, 2.0, "Welcome bonus", "admin")
+	if err != nil {
+		t.Fatalf("AddCreditBonus() error = %v", err)
+	}
+	if account.Balance != 100 || account.Credit != 2000 {
+		t.Fatalf("balance/credit = %.2f/%.2f, want 100/2000", account.Balance, account.Credit)
+	}
+
+	pos, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 1.0, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() with bonus credit error = %v", err)
+	}
+
+	if err := engine.CheckWithdrawal(account.ID, 50); err == nil {
+		t.Error("withdrawal allowed while the bonus backs the open position")
+	}
+
+	// Closing 1 lot does not yet meet the 2 lot condition
+	if _, err := engine.ClosePosition(pos.ID, 0); err != nil {
+		t.Fatalf("ClosePosition() error = %v", err)
+	}
+	if bonus.Status != BonusStatusPending || bonus.TradedVolume != 1.0 {
+		t.Fatalf("bonus = %s after %.2f lots, want PENDING after 1.00", bonus.Status, bonus.TradedVolume)
+	}
+	if withdrawable, _ := engine.WithdrawableBalance(account.ID); withdrawable > account.Balance {
+		t.Errorf("withdrawable = %.2f, want at most the balance %.2f", withdrawable, account.Balance)
+	}
+	if err := engine.CheckWithdrawal(account.ID, 500); err == nil {
+		t.Error("bonus credit withdrawable before release")
+	}
+
+	pos, err = engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 1.0, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	if _, err := engine.ClosePosition(pos.ID, 0); err != nil {
+		t.Fatalf("ClosePosition() error = %v", err)
+	}
+
+	if bonus.Status != BonusStatusReleased || account.Credit != 0 {
+		t.Fatalf("bonus = %s, credit = %.2f; want RELEASED with no credit left", bonus.Status, account.Credit)
+	}
+	balance := account.Balance
+	if balance < 2000 {
+		t.Errorf("balance = %.2f, want the released bonus included", balance)
+	}
+	if err := engine.CheckWithdrawal(account.ID, 2000); err != nil {
+		t.Errorf("CheckWithdrawal(2000) after release error = %v", err)
+	}
+	if err := engine.CheckWithdrawal(account.ID, balance+1); err == nil {
+		t.Error("withdrawal above the balance allowed")
+	}
+}
+
+// TestConcurrentWithdrawalsStayWithinFreeMargin tests that concurrent
+// withdrawals cannot together debit more than the withdrawable balance
+func TestConcurrentWithdrawalsStayWithinFreeMargin(t *testing.T) {
+	engine, account := newTestEngine(t)
+	account.Balance = 0
+	entry, err := engine.GetLedger().Deposit(account.ID, 10000, "BANK", "ref-1", "Deposit", "admin")
+	if err != nil {
+		t.Fatalf("Deposit() error = %v", err)
+	}
+	account.Balance = entry.BalanceAfter
+
+	// ~1100 margin in use leaves room for 8 withdrawals of 1000
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 1.0, 0, 0); err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := engine.Withdraw(account.ID, 1000, "BANK", "", "Withdrawal", "admin"); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 8 {
+		t.Errorf("withdrawals succeeded = %d, want 8", succeeded)
+	}
+	if summary, _ := engine.GetAccountSummary(account.ID); summary.FreeMargin < 0 || account.Balance != 2000 {
+		t.Errorf("balance = %.2f, free margin = %.2f; want 2000 with free margin left", account.Balance, summary.FreeMargin)
+	}
+}
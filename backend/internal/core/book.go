@@ -0,0 +1,93 @@
+package core
+
+import "time"
+
+// BookTotals sums the client side of the B-Book at one instant
+type BookTotals struct {
+	Accounts      int     `json:"accounts"`
+	OpenPositions int     `json:"openPositions"`
+	Balance       float64 `json:"balance"`
+	Credit        float64 `json:"credit"`
+	Equity        float64 `json:"equity"`
+	UnrealizedPnL float64 `json:"unrealizedPnL"`
+}
+
+// GetBookTotals returns client balances, equity and floating P/L across all accounts
+func (e *Engine) GetBookTotals() BookTotals {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	totals := BookTotals{Accounts: len(e.accounts)}
+	for _, account := range e.accounts {
+		totals.Balance += account.Balance
+		totals.Credit += account.Credit
+	}
+	for _, pos := range e.positions {
+		if pos.Status != "OPEN" {
+			continue
+		}
+		totals.OpenPositions++
+		totals.UnrealizedPnL += pos.UnrealizedPnL
+	}
+	totals.Equity = totals.Balance + totals.Credit + totals.UnrealizedPnL
+	return totals
+}
+
+// BBookPnL returns the client P/L the broker is counterparty to: realized on
+// closes and floating on open positions, leaving out A_BOOK positions the LP carries
+func (e *Engine) BBookPnL() (realized, unrealized float64) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, trade := range e.trades {
+		if pos, ok := e.positions[trade.PositionID]; ok && pos.Book == ExposureActionABook {
+			continue
+		}
+		realized += trade.RealizedPnL
+	}
+	for _, pos := range e.positions {
+		if pos.Status == "OPEN" && pos.Book != ExposureActionABook {
+			unrealized += pos.UnrealizedPnL
+		}
+	}
+	return realized, unrealized
+}
+
+// RecordMarginCall counts a margin call of an account toward the risk event totals
+func (e *Engine) RecordMarginCall(accountID int64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.recordRiskEventUnlocked(&e.marginCalls)
+}
+
+// RiskEventsSince returns how many margin calls and stop-outs happened since t.
+// Events older than a day are not kept.
+func (e *Engine) RiskEventsSince(t time.Time) (marginCalls, stopOuts int) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return countSince(e.marginCalls, t), countSince(e.stopOuts, t)
+}
+
+// recordRiskEventUnlocked timestamps an event, dropping those older than a day (caller must hold lock)
+func (e *Engine) recordRiskEventUnlocked(events *[]time.Time) {
+	now := time.Now()
+	cutoff := now.Add(-24 * time.Hour)
+	kept := (*events)[:0]
+	for _, at := range *events {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+	*events = append(kept, now)
+}
+
+// countSince counts the timestamps at or after t
+func countSince(events []time.Time, t time.Time) int {
+	n := 0
+	for _, at := range events {
+		if !at.Before(t) {
+			n++
+		}
+	}
+	return n
+}
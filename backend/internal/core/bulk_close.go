@@ -0,0 +1,179 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Bulk close selections by unrealized P/L
+const (
+	BulkCloseAll     = "ALL"
+	BulkCloseWinners = "WINNERS"
+	BulkCloseLosers  = "LOSERS"
+)
+
+// BulkCloseFilter selects the positions of a bulk close. Listed position IDs
+// are closed as given; otherwise every open position of the account matching
+// the symbol, side and type is closed. Empty fields match any. A volume
+// closes only that many lots of a symbol, taking the selected positions in
+// order and partially closing the last.
+type BulkCloseFilter struct {
+	PositionIDs []int64 `json:"positionIds,omitempty"`
+	Symbol      string  `json:"symbol,omitempty"`
+	Side        string  `json:"side,omitempty"`   // BUY (or LONG) or SELL (or SHORT)
+	Type        string  `json:"type,omitempty"`   // ALL (default), WINNERS or LOSERS
+	Volume      float64 `json:"volume,omitempty"` // 0 closes the selected positions in full
+}
+
+// BulkCloseItem is the outcome of closing one position of a bulk close
+type BulkCloseItem struct {
+	PositionID  int64   `json:"positionId"`
+	Symbol      string  `json:"symbol,omitempty"`
+	Side        string  `json:"side,omitempty"`
+	Volume      float64 `json:"volume,omitempty"`
+	Closed      bool    `json:"closed"`
+	ClosePrice  float64 `json:"closePrice,omitempty"`
+	RealizedPnL float64 `json:"realizedPnL"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// BulkCloseResult is the outcome of a bulk close, one item per selected position
+type BulkCloseResult struct {
+	ClosedCount int             `json:"closedCount"`
+	FailedCount int             `json:"failedCount"`
+	RealizedPnL float64         `json:"realizedPnL"` // Sum over the closed positions
+	CloseOrder  string          `json:"closeOrder"`
+	Results     []BulkCloseItem `json:"results"`
+}
+
+// ClosePositions closes an account's positions selected by filter under one
+// engine lock, so no other close or fill interleaves. Each position closes or
+// fails on its own; the result reports exactly which closed.
+func (e *Engine) ClosePositions(accountID int64, filter BulkCloseFilter) (*BulkCloseResult, error) {
+	side := strings.ToUpper(filter.Side)
+	switch side {
+	case "LONG":
+		side = "BUY"
+	case "SHORT":
+		side = "SELL"
+	case "", "BUY", "SELL":
+	default:
+		return nil, fmt.Errorf("invalid side %q: must be BUY or SELL", filter.Side)
+	}
+	selection := strings.ToUpper(filter.Type)
+	switch selection {
+	case "":
+		selection = BulkCloseAll
+	case BulkCloseAll, BulkCloseWinners, BulkCloseLosers:
+	default:
+		return nil, errors.New("invalid type: must be ALL, WINNERS, or LOSERS")
+	}
+	if filter.Volume < 0 {
+		return nil, errors.New("close volume must not be negative")
+	}
+	if filter.Volume > 0 && filter.Symbol == "" {
+		return nil, errors.New("symbol is required when closing a volume")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	result := &BulkCloseResult{CloseOrder: e.closeOrder, Results: []BulkCloseItem{}}
+
+	var selected []*Position
+	if len(filter.PositionIDs) > 0 {
+		for _, id := range filter.PositionIDs {
+			pos, ok := e.positions[id]
+			switch {
+			case !ok || pos.AccountID != accountID:
+				result.fail(BulkCloseItem{PositionID: id}, "position not found")
+			case pos.Status != "OPEN":
+				result.fail(bulkCloseItem(pos), "position is not open")
+			default:
+				selected = append(selected, pos)
+			}
+		}
+	} else {
+		selected = e.lotsInCloseOrderUnlocked(accountID, filter.Symbol, side)
+	}
+
+	var matched []*Position
+	open := 0.0
+	for _, pos := range selected {
+		if len(filter.PositionIDs) > 0 && !bulkCloseMatches(pos, filter.Symbol, side, BulkCloseAll) {
+			result.fail(bulkCloseItem(pos), "position does not match the filter")
+			continue
+		}
+		if !bulkCloseMatches(pos, "", "", selection) {
+			continue
+		}
+		matched = append(matched, pos)
+		open += pos.Volume
+	}
+	// A volume is refused outright rather than closed in part
+	if filter.Volume > open+1e-9 {
+		return nil, fmt.Errorf("close volume %.2f exceeds open volume %.2f", filter.Volume, open)
+	}
+
+	remaining := filter.Volume
+	for _, pos := range matched {
+		closeVolume := 0.0 // Whole position
+		if filter.Volume > 0 {
+			if remaining <= 1e-9 {
+				break
+			}
+			if remaining < pos.Volume {
+				closeVolume = remaining
+			}
+		}
+
+		item := bulkCloseItem(pos)
+		if closeVolume > 0 {
+			item.Volume = closeVolume
+		}
+		if err := e.checkCanModify(pos.Symbol); err != nil {
+			result.fail(item, err.Error())
+			continue
+		}
+		trade, err := e.closePositionUnlocked(pos, closeVolume)
+		if err != nil {
+			result.fail(item, err.Error())
+			continue
+		}
+		remaining -= item.Volume
+		item.Closed = true
+		item.ClosePrice = trade.Price
+		item.RealizedPnL = trade.RealizedPnL
+		result.ClosedCount++
+		result.RealizedPnL += trade.RealizedPnL
+		result.Results = append(result.Results, item)
+	}
+
+	return result, nil
+}
+
+// bulkCloseMatches reports whether a position matches a symbol, side and P/L
+// selection, empty symbol or side matching any
+func bulkCloseMatches(pos *Position, symbol, side, selection string) bool {
+	if (symbol != "" && pos.Symbol != symbol) || (side != "" && pos.Side != side) {
+		return false
+	}
+	switch selection {
+	case BulkCloseWinners:
+		return pos.UnrealizedPnL > 0
+	case BulkCloseLosers:
+		return pos.UnrealizedPnL < 0
+	}
+	return true
+}
+
+func bulkCloseItem(pos *Position) BulkCloseItem {
+	return BulkCloseItem{PositionID: pos.ID, Symbol: pos.Symbol, Side: pos.Side, Volume: pos.Volume}
+}
+
+func (r *BulkCloseResult) fail(item BulkCloseItem, reason string) {
+	item.Error = reason
+	r.FailedCount++
+	r.Results = append(r.Results, item)
+}
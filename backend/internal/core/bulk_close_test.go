@@ -0,0 +1,125 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+// TestClosePositionsBySymbol tests that a symbol filter closes only that
+// symbol's positions and sums their realized P/L
+func TestClosePositionsBySymbol(t *testing.T) {
+	engine, account := newTestEngine(t)
+	eur1 := openTestPosition(t, engine, account.ID, "EURUSD")
+	eur2 := openTestPosition(t, engine, account.ID, "EURUSD")
+	gbp := openTestPosition(t, engine, account.ID, "GBPUSD")
+
+	result, err := engine.ClosePositions(account.ID, BulkCloseFilter{Symbol: "EURUSD"})
+	if err != nil {
+		t.Fatalf("ClosePositions() error = %v", err)
+	}
+	if result.ClosedCount != 2 || result.FailedCount != 0 || len(result.Results) != 2 {
+		t.Fatalf("closed = %d failed = %d results = %d, want 2/0/2", result.ClosedCount, result.FailedCount, len(result.Results))
+	}
+
+	sum := 0.0
+	for _, item := range result.Results {
+		if !item.Closed || (item.PositionID != eur1.ID && item.PositionID != eur2.ID) {
+			t.Errorf("unexpected result %+v", item)
+		}
+		sum += item.RealizedPnL
+	}
+	if math.Abs(result.RealizedPnL-sum) > 1e-9 || result.RealizedPnL >= 0 {
+		t.Errorf("realized P/L = %.2f, want the sum %.2f of the spread losses", result.RealizedPnL, sum)
+	}
+
+	open := engine.GetPositions(account.ID)
+	if len(open) != 1 || open[0].ID != gbp.ID {
+		t.Errorf("open positions = %d, want only the GBPUSD one", len(open))
+	}
+}
+
+// TestClosePositionsMixedOutcome tests that listed positions which cannot be
+// closed are reported as failures while the rest close
+func TestClosePositionsMixedOutcome(t *testing.T) {
+	engine, account := newTestEngine(t)
+	closed := openTestPosition(t, engine, account.ID, "EURUSD")
+	open := openTestPosition(t, engine, account.ID, "GBPUSD")
+	if _, err := engine.ClosePosition(closed.ID, 0); err != nil {
+		t.Fatalf("ClosePosition() error = %v", err)
+	}
+
+	result, err := engine.ClosePositions(account.ID, BulkCloseFilter{PositionIDs: []int64{closed.ID, open.ID, 9999}})
+	if err != nil {
+		t.Fatalf("ClosePositions() error = %v", err)
+	}
+	if result.ClosedCount != 1 || result.FailedCount != 2 {
+		t.Fatalf("closed = %d failed = %d, want 1/2", result.ClosedCount, result.FailedCount)
+	}
+
+	outcomes := make(map[int64]BulkCloseItem)
+	for _, item := range result.Results {
+		outcomes[item.PositionID] = item
+	}
+	if item := outcomes[open.ID]; !item.Closed || item.RealizedPnL != result.RealizedPnL {
+		t.Errorf("open position result = %+v, want closed carrying the realized P/L", item)
+	}
+	if item := outcomes[closed.ID]; item.Closed || item.Error != "position is not open" {
+		t.Errorf("already closed position result = %+v", item)
+	}
+	if item := outcomes[9999]; item.Closed || item.Error != "position not found" {
+		t.Errorf("unknown position result = %+v", item)
+	}
+}
+
+// TestClosePositionsBySide tests that a direction filter closes only longs
+func TestClosePositionsBySide(t *testing.T) {
+	engine, account := newTestEngine(t)
+	long := openTestPosition(t, engine, account.ID, "EURUSD")
+	short, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 0.1, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+
+	result, err := engine.ClosePositions(account.ID, BulkCloseFilter{Side: "long"})
+	if err != nil {
+		t.Fatalf("ClosePositions() error = %v", err)
+	}
+	if result.ClosedCount != 1 || result.Results[0].PositionID != long.ID {
+		t.Fatalf("results = %+v, want only the long closed", result.Results)
+	}
+	if pos := engine.GetPositions(account.ID); len(pos) != 1 || pos[0].ID != short.ID {
+		t.Error("short position closed by a long filter")
+	}
+}
+
+// TestClosePositionsVolume tests that a volume close reports each lot it
+// closed, partially closing the last, and refuses more than is open
+func TestClosePositionsVolume(t *testing.T) {
+	engine, account := newTestEngine(t)
+	first := openTestPosition(t, engine, account.ID, "EURUSD")
+	second := openTestPosition(t, engine, account.ID, "EURUSD")
+
+	if _, err := engine.ClosePositions(account.ID, BulkCloseFilter{Volume: 0.15}); err == nil {
+		t.Error("volume close without a symbol accepted")
+	}
+	if _, err := engine.ClosePositions(account.ID, BulkCloseFilter{Symbol: "EURUSD", Volume: 0.5}); err == nil {
+		t.Error("volume close beyond the open volume accepted")
+	}
+
+	result, err := engine.ClosePositions(account.ID, BulkCloseFilter{Symbol: "EURUSD", Side: "BUY", Volume: 0.15})
+	if err != nil {
+		t.Fatalf("ClosePositions() error = %v", err)
+	}
+	if result.ClosedCount != 2 || result.FailedCount != 0 || len(result.Results) != 2 {
+		t.Fatalf("closed = %d failed = %d results = %d, want 2/0/2", result.ClosedCount, result.FailedCount, len(result.Results))
+	}
+	if item := result.Results[0]; item.PositionID != first.ID || item.Volume != 0.1 {
+		t.Errorf("first result = %+v, want lot #%d closed in full", item, first.ID)
+	}
+	if item := result.Results[1]; item.PositionID != second.ID || math.Abs(item.Volume-0.05) > 1e-9 {
+		t.Errorf("second result = %+v, want 0.05 lots of #%d", item, second.ID)
+	}
+	if second.Status != "OPEN" || math.Abs(second.Volume-0.05) > 1e-9 {
+		t.Errorf("second lot = %s %.2f, want OPEN with 0.05 lots left", second.Status, second.Volume)
+	}
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// Close order policies: which lots of an aggregate exposure are closed first
+// when only part of it is closed
+const (
+	CloseOrderFIFO = "FIFO" // Oldest lot first
+	CloseOrderLIFO = "LIFO" // Newest lot first
+)
+
+// NormalizeCloseOrder validates a close order policy name. Empty selects FIFO.
+func NormalizeCloseOrder(policy string) (string, error) {
+	switch strings.ToUpper(policy) {
+	case "", CloseOrderFIFO:
+		return CloseOrderFIFO, nil
+	case CloseOrderLIFO:
+		return CloseOrderLIFO, nil
+	default:
+		return "", fmt.Errorf("invalid close order %q: must be %s or %s", policy, CloseOrderFIFO, CloseOrderLIFO)
+	}
+}
+
+// SetCloseOrder sets the policy used to pick lots on bulk and partial closes
+func (e *Engine) SetCloseOrder(policy string) error {
+	normalized, err := NormalizeCloseOrder(policy)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closeOrder = normalized
+	log.Printf("[B-Book] Close order policy set to %s", normalized)
+	return nil
+}
+
+// GetCloseOrder returns the policy used to pick lots on bulk and partial closes
+func (e *Engine) GetCloseOrder() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.closeOrder
+}
+
+// GetPositionsInCloseOrder returns an account's open positions in the order the
+// close policy would close them
+func (e *Engine) GetPositionsInCloseOrder(accountID int64) []*Position {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.lotsInCloseOrderUnlocked(accountID, "", "")
+}
+
+// CloseVolume closes volume lots of an account's exposure on a symbol and side,
+// taking lots in close policy order. A lot is partially closed when it is larger
+// than the volume still to close. Each lot closes or fails on its own, and the
+// result reports which did.
+func (e *Engine) CloseVolume(accountID int64, symbol, side string, volume float64) (*BulkCloseResult, error) {
+	if volume <= 0 {
+		return nil, errors.New("close volume must be positive")
+	}
+	if symbol == "" || side == "" {
+		return nil, errors.New("symbol and side are required when closing a volume")
+	}
+	return e.ClosePositions(accountID, BulkCloseFilter{Symbol: symbol, Side: side, Volume: volume})
+}
+
+// lotsInCloseOrderUnlocked returns matching open positions ordered by entry
+// under the close policy. Entry order is the open time, with the monotonic
+// position ID breaking ties. Empty symbol or side matches any (caller must hold lock).
+func (e *Engine) lotsInCloseOrderUnlocked(accountID int64, symbol, side string) []*Position {
+	var lots []*Position
+	for _, pos := range e.positions {
+		if pos.AccountID != accountID || pos.Status != "OPEN" {
+			continue
+		}
+		if (symbol != "" && pos.Symbol != symbol) || (side != "" && pos.Side != side) {
+			continue
+		}
+		lots = append(lots, pos)
+	}
+
+	lifo := e.closeOrder == CloseOrderLIFO
+	sort.Slice(lots, func(i, j int) bool {
+		a, b := lots[i], lots[j]
+		if lifo {
+			a, b = b, a
+		}
+		if !a.OpenTime.Equal(b.OpenTime) {
+			return a.OpenTime.Before(b.OpenTime)
+		}
+		return a.ID < b.ID
+	})
+	return lots
+}
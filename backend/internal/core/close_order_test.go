@@ -0,0 +1,96 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+// openLots opens one 1-lot EURUSD BUY at each ask and returns the positions in entry order
+func openLots(t *testing.T, engine *Engine, accountID int64, prices map[string][2]float64, asks ...float64) []*Position {
+	t.Helper()
+	var lots []*Position
+	for _, ask := range asks {
+		prices["EURUSD"] = [2]float64{ask - 0.0002, ask}
+		pos, err := engine.ExecuteMarketOrder(accountID, "EURUSD", "BUY", 1.0, 0, 0)
+		if err != nil {
+			t.Fatalf("ExecuteMarketOrder() error = %v", err)
+		}
+		lots = append(lots, pos)
+	}
+	return lots
+}
+
+// TestCloseVolumeFIFOAndLIFO tests that FIFO closes the oldest lot first and LIFO the newest
+func TestCloseVolumeFIFOAndLIFO(t *testing.T) {
+	tests := []struct {
+		policy     string
+		wantPnL    float64
+		wantClosed int // Index of the lot fully closed
+		wantOpen   int // Index of the lot left untouched
+	}{
+		// Close 1.5 lots at bid 1.1030 of lots bought at 1.1002, 1.1012 and 1.1022
+		{CloseOrderFIFO, 280 + 90, 0, 2},
+		{CloseOrderLIFO, 80 + 90, 2, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			engine, account := newTestEngine(t)
+			prices := map[string][2]float64{}
+			engine.SetPriceCallback(func(symbol string) (float64, float64, bool) {
+				p, ok := prices[symbol]
+				return p[0], p[1], ok
+			})
+			if err := engine.SetCloseOrder(tt.policy); err != nil {
+				t.Fatalf("SetCloseOrder() error = %v", err)
+			}
+
+			lots := openLots(t, engine, account.ID, prices, 1.1002, 1.1012, 1.1022)
+			prices["EURUSD"] = [2]float64{1.1030, 1.1032}
+
+			result, err := engine.CloseVolume(account.ID, "EURUSD", "BUY", 1.5)
+			if err != nil {
+				t.Fatalf("CloseVolume() error = %v", err)
+			}
+			if result.ClosedCount != 2 || result.FailedCount != 0 {
+				t.Fatalf("closed %d lots with %d failures, want 2 and none", result.ClosedCount, result.FailedCount)
+			}
+
+			if math.Abs(result.RealizedPnL-tt.wantPnL) > 1e-6 {
+				t.Errorf("realized P/L = %.2f, want %.2f", result.RealizedPnL, tt.wantPnL)
+			}
+			if result.Results[0].PositionID != lots[tt.wantClosed].ID {
+				t.Errorf("first closed position = %d, want lot #%d", result.Results[0].PositionID, lots[tt.wantClosed].ID)
+			}
+			if lots[tt.wantClosed].Status != "CLOSED" {
+				t.Errorf("lot #%d status = %s, want CLOSED", lots[tt.wantClosed].ID, lots[tt.wantClosed].Status)
+			}
+			if lots[1].Status != "OPEN" || math.Abs(lots[1].Volume-0.5) > 1e-9 {
+				t.Errorf("middle lot = %s %.2f, want OPEN with 0.5 lots left", lots[1].Status, lots[1].Volume)
+			}
+			if lots[tt.wantOpen].Status != "OPEN" || lots[tt.wantOpen].Volume != 1.0 {
+				t.Errorf("lot #%d = %s %.2f, want untouched", lots[tt.wantOpen].ID, lots[tt.wantOpen].Status, lots[tt.wantOpen].Volume)
+			}
+		})
+	}
+}
+
+// TestCloseVolumeRejectsExcess tests that closing more than the open exposure, or
+// without a side, fails without closing anything
+func TestCloseVolumeRejectsExcess(t *testing.T) {
+	engine, account := newTestEngine(t)
+	pos := openTestPosition(t, engine, account.ID, "EURUSD")
+
+	if _, err := engine.CloseVolume(account.ID, "EURUSD", "BUY", 0.5); err == nil {
+		t.Fatal("CloseVolume() error = nil, want exceeds open volume")
+	}
+	if _, err := engine.CloseVolume(account.ID, "EURUSD", "", 0.05); err == nil {
+		t.Fatal("CloseVolume() without a side error = nil, want side required")
+	}
+	if pos.Status != "OPEN" || pos.Volume != 0.1 {
+		t.Errorf("position = %s %.2f, want untouched", pos.Status, pos.Volume)
+	}
+	if err := engine.SetCloseOrder("RANDOM"); err == nil {
+		t.Error("SetCloseOrder(RANDOM) error = nil, want invalid policy")
+	}
+}
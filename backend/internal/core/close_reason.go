@@ -0,0 +1,36 @@
+package core
+
+import "errors"
+
+// Close reasons recorded on positions closed by the server rather than the client
+const (
+	CloseReasonStopLoss     = "STOP_LOSS"
+	CloseReasonTakeProfit   = "TAKE_PROFIT"
+	CloseReasonTrailingStop = "TRAILING_STOP"
+)
+
+// ClosePositionWithReason closes a position like ClosePosition, recording why
+// on the position and its close event
+func (e *Engine) ClosePositionWithReason(positionID int64, closeVolume float64, reason string) (*Trade, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	position, ok := e.positions[positionID]
+	if !ok {
+		return nil, errors.New("position not found")
+	}
+	if position.Status != "OPEN" {
+		return nil, errors.New("position is not open")
+	}
+	if err := e.checkCanModify(position.Symbol); err != nil {
+		return nil, err
+	}
+
+	prevReason := position.CloseReason
+	position.CloseReason = reason
+	trade, err := e.closePositionUnlocked(position, closeVolume)
+	if err != nil || position.Status == "OPEN" {
+		position.CloseReason = prevReason // Only a full close keeps the reason
+	}
+	return trade, err
+}
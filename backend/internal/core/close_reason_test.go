@@ -0,0 +1,30 @@
+package core
+
+import "testing"
+
+// TestClosePositionWithReason tests that a server-side close records its
+// reason on the position and the close event, and books the realized P/L
+func TestClosePositionWithReason(t *testing.T) {
+	engine, account := newTestEngine(t)
+	var events []TradeEvent
+	engine.SubscribeTradeEvents(func(event TradeEvent) { events = append(events, event) })
+	pos := openTestPosition(t, engine, account.ID, "EURUSD")
+
+	if _, err := engine.ClosePositionWithReason(pos.ID, 0, CloseReasonTrailingStop); err != nil {
+		t.Fatalf("ClosePositionWithReason() error = %v", err)
+	}
+	if pos.Status != "CLOSED" || pos.CloseReason != CloseReasonTrailingStop {
+		t.Errorf("position = %s (%q), want CLOSED by %s", pos.Status, pos.CloseReason, CloseReasonTrailingStop)
+	}
+	last := events[len(events)-1]
+	if last.Type != TradeEventPositionClosed || last.CloseReason != CloseReasonTrailingStop {
+		t.Errorf("last event = %s (%q), want POSITION_CLOSED by %s", last.Type, last.CloseReason, CloseReasonTrailingStop)
+	}
+	if entries := engine.GetLedger().GetEntriesByType("REALIZED_PNL", 10); len(entries) != 1 {
+		t.Errorf("got %d realized P/L ledger entries, want 1", len(entries))
+	}
+
+	if _, err := engine.ClosePositionWithReason(pos.ID, 0, CloseReasonTrailingStop); err == nil {
+		t.Error("closing a closed position succeeded")
+	}
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Commission models: a symbol either charges an explicit commission on the raw
+// price or bakes its cost into a wider fill price, never both
+const (
+	CommissionModelCommission = "COMMISSION" // Raw spread + explicit commission per lot
+	CommissionModelMarkup     = "MARKUP"     // Commission included in the fill price, no explicit charge
+)
+
+// Commission types: how a commission rate is charged on each side of a round turn
+const (
+	CommissionTypePerLot  = "PER_LOT" // Rate is money per lot
+	CommissionTypePercent = "PERCENT" // Rate is a percentage of the fill's notional
+)
+
+// CommissionRateResolver returns the commission rate of an account's group for a
+// symbol, or false to use the symbol's CommissionPerLot
+type CommissionRateResolver func(accountID int64, symbol string) (float64, bool)
+
+// NormalizeCommissionType validates a commission type name. Empty selects per lot.
+func NormalizeCommissionType(commissionType string) (string, error) {
+	switch strings.ToUpper(commissionType) {
+	case "", CommissionTypePerLot:
+		return CommissionTypePerLot, nil
+	case CommissionTypePercent:
+		return CommissionTypePercent, nil
+	default:
+		return "", fmt.Errorf("invalid commission type %q: must be %s or %s", commissionType, CommissionTypePerLot, CommissionTypePercent)
+	}
+}
+
+// SetCommissionType sets whether commission rates are per lot or a percentage of notional
+func (e *Engine) SetCommissionType(commissionType string) error {
+	normalized, err := NormalizeCommissionType(commissionType)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.commissionType = normalized
+	return nil
+}
+
+// GetCommissionType returns whether commission rates are per lot or a percentage of notional
+func (e *Engine) GetCommissionType() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.commissionType
+}
+
+// SetCommissionRateResolver sets the lookup for group-level commission rates
+func (e *Engine) SetCommissionRateResolver(fn CommissionRateResolver) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.commissionRateResolver = fn
+}
+
+// commissionUnlocked returns the commission of one side of a round turn: the
+// group rate wins over the symbol's, applied per lot or to the notional at
+// price (caller must hold lock)
+func (e *Engine) commissionUnlocked(accountID int64, spec *SymbolSpec, volume, price float64) float64 {
+	rate := spec.CommissionPerLot
+	if e.commissionRateResolver != nil {
+		if override, ok := e.commissionRateResolver(accountID, spec.Symbol); ok {
+			rate = override
+		}
+	}
+	if rate <= 0 {
+		return 0
+	}
+	if e.commissionType == CommissionTypePercent {
+		return volume * spec.ContractSize * price * rate / 100
+	}
+	return rate * volume
+}
+
+// CommissionModelResolver returns the commission model of an account's group for
+// a symbol, or "" to use the symbol's own model
+type CommissionModelResolver func(accountID int64, symbol string) string
+
+// NormalizeCommissionModel validates a commission model name. Empty selects the
+// commission model.
+func NormalizeCommissionModel(model string) (string, error) {
+	switch strings.ToUpper(model) {
+	case "", CommissionModelCommission:
+		return CommissionModelCommission, nil
+	case CommissionModelMarkup:
+		return CommissionModelMarkup, nil
+	default:
+		return "", fmt.Errorf("invalid commission model %q: must be %s or %s", model, CommissionModelCommission, CommissionModelMarkup)
+	}
+}
+
+// SetCommissionModelResolver sets the lookup for group-level commission model overrides
+func (e *Engine) SetCommissionModelResolver(fn CommissionModelResolver) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.commissionModelResolver = fn
+}
+
+// commissionModelUnlocked resolves the commission model for an account trading a
+// symbol: the group override wins over the symbol setting (caller must hold lock)
+func (e *Engine) commissionModelUnlocked(accountID int64, spec *SymbolSpec) string {
+	if e.commissionModelResolver != nil {
+		if override := e.commissionModelResolver(accountID, spec.Symbol); override != "" {
+			if model, err := NormalizeCommissionModel(override); err == nil {
+				return model
+			}
+		}
+	}
+	model, err := NormalizeCommissionModel(spec.CommissionModel)
+	if err != nil {
+		return CommissionModelCommission
+	}
+	return model
+}
+
+// applyCommissionModel returns the fill price, explicit commission and the price
+// markup applied for an opening fill at the raw market price. commission is what
+// the COMMISSION model charges on the fill.
+func applyCommissionModel(model string, spec *SymbolSpec, side string, rawPrice, commission float64) (fillPrice, charged, markup float64) {
+	if model != CommissionModelMarkup {
+		return rawPrice, commission, 0
+	}
+
+	markup = spec.SpreadMarkup * spec.PipSize
+	if side == "BUY" {
+		return rawPrice + markup, 0, markup
+	}
+	return rawPrice - markup, 0, markup
+}
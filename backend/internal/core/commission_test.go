@@ -0,0 +1,145 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+// TestMarkupModelWidensFillWithoutCommission tests that the markup model charges through the price only
+func TestMarkupModelWidensFillWithoutCommission(t *testing.T) {
+	engine, account := newTestEngine(t)
+	spec := engine.GetOrCreateSymbol("EURUSD")
+	spec.CommissionPerLot = 7
+	spec.CommissionModel = CommissionModelMarkup
+	spec.SpreadMarkup = 0.7
+
+	buy, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 1.0, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder(BUY) error = %v", err)
+	}
+	if math.Abs(buy.OpenPrice-1.10027) > 1e-9 {
+		t.Errorf("BUY fill = %.5f, want ask widened to 1.10027", buy.OpenPrice)
+	}
+	if buy.Commission != 0 {
+		t.Errorf("BUY commission = %.2f, want 0", buy.Commission)
+	}
+
+	sell, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 1.0, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder(SELL) error = %v", err)
+	}
+	if math.Abs(sell.OpenPrice-1.09993) > 1e-9 {
+		t.Errorf("SELL fill = %.5f, want bid widened to 1.09993", sell.OpenPrice)
+	}
+
+	if account.Balance != 10000 {
+		t.Errorf("balance = %.2f, want 10000 (no explicit commission)", account.Balance)
+	}
+	for _, trade := range engine.GetTrades(account.ID) {
+		if trade.CommissionModel != CommissionModelMarkup || trade.Commission != 0 || math.Abs(trade.Markup-0.00007) > 1e-12 {
+			t.Errorf("trade = %+v, want MARKUP with 0.00007 markup and no commission", trade)
+		}
+	}
+}
+
+// TestCommissionModelUsesRawSpread tests that the commission model fills at the raw quote with an explicit charge
+func TestCommissionModelUsesRawSpread(t *testing.T) {
+	engine, account := newTestEngine(t)
+	spec := engine.GetOrCreateSymbol("EURUSD")
+	spec.CommissionPerLot = 7
+	spec.CommissionModel = CommissionModelCommission
+	spec.SpreadMarkup = 0.7 // ignored under the commission model
+
+	pos, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 1.0, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	if pos.OpenPrice != 1.1002 {
+		t.Errorf("fill = %.5f, want raw ask 1.10020", pos.OpenPrice)
+	}
+	if pos.Commission != 7 || account.Balance != 9993 {
+		t.Errorf("commission = %.2f, balance = %.2f; want 7 / 9993", pos.Commission, account.Balance)
+	}
+
+	trades := engine.GetTrades(account.ID)
+	if len(trades) != 1 || trades[0].CommissionModel != CommissionModelCommission || trades[0].Markup != 0 || trades[0].Commission != 7 {
+		t.Errorf("trades = %+v, want one COMMISSION trade charging 7", trades)
+	}
+
+	// A group override switches the same symbol to markup pricing
+	engine.SetCommissionModelResolver(func(accountID int64, symbol string) string {
+		return CommissionModelMarkup
+	})
+	pos, err = engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 1.0, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	if pos.Commission != 0 || math.Abs(pos.OpenPrice-1.10027) > 1e-9 {
+		t.Errorf("group override fill = %.5f / commission %.2f, want 1.10027 / 0", pos.OpenPrice, pos.Commission)
+	}
+}
+
+// TestRoundTurnCommission tests that the group rate is charged on the open and on
+// each close, reaches the ledger, and that a close's net P/L carries its share
+// of the opening commission
+func TestRoundTurnCommission(t *testing.T) {
+	engine, account := newTestEngine(t)
+	spec := engine.GetOrCreateSymbol("EURUSD")
+	spec.CommissionPerLot = 7 // Overridden by the group
+	engine.SetCommissionRateResolver(func(accountID int64, symbol string) (float64, bool) {
+		return 4, symbol == "EURUSD"
+	})
+
+	pos, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 1.0, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	if pos.Commission != 4 || account.Balance != 9996 {
+		t.Fatalf("opening commission = %.2f, balance = %.2f; want 4 / 9996", pos.Commission, account.Balance)
+	}
+
+	// +10 pips on the bid: 50 gross per half lot
+	engine.SetPriceCallback(func(symbol string) (float64, float64, bool) {
+		return 1.1012, 1.1014, symbol == "EURUSD"
+	})
+	for i := 0; i < 2; i++ {
+		trade, err := engine.ClosePosition(pos.ID, 0.5)
+		if err != nil {
+			t.Fatalf("ClosePosition() error = %v", err)
+		}
+		if math.Abs(trade.RealizedPnL-50) > 1e-6 || math.Abs(trade.Commission-2) > 1e-9 || math.Abs(trade.NetPnL-46) > 1e-6 {
+			t.Errorf("close %d = %+v, want 50 gross, 2 commission, 46 net", i+1, trade)
+		}
+	}
+
+	if pos.Status != "CLOSED" || math.Abs(pos.Commission-8) > 1e-9 {
+		t.Errorf("position %s commission = %.2f, want CLOSED with 8 round-turn", pos.Status, pos.Commission)
+	}
+	if math.Abs(account.Balance-10092) > 1e-6 {
+		t.Errorf("balance = %.2f, want 10092 (100 gross less 8 commission)", account.Balance)
+	}
+	if total, n := ledgerTotal(engine, account.ID, "COMMISSION"); n != 3 || math.Abs(total-(-8)) > 1e-9 {
+		t.Errorf("COMMISSION ledger = %.2f over %d entries, want -8 over 3", total, n)
+	}
+}
+
+// TestPercentCommission tests that the percent type charges a share of the fill's notional
+func TestPercentCommission(t *testing.T) {
+	engine, account := newTestEngine(t)
+	spec := engine.GetOrCreateSymbol("EURUSD")
+	spec.CommissionPerLot = 0.01 // Percent of notional
+	if err := engine.SetCommissionType("percent"); err != nil {
+		t.Fatalf("SetCommissionType() error = %v", err)
+	}
+
+	pos, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 1.0, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	if want := 1.0 * spec.ContractSize * 1.1002 * 0.0001; math.Abs(pos.Commission-want) > 1e-9 {
+		t.Errorf("commission = %.4f, want %.4f", pos.Commission, want)
+	}
+	if err := engine.SetCommissionType("SPREAD"); err == nil {
+		t.Error("SetCommissionType() accepted an unknown type")
+	}
+}
@@ -0,0 +1,76 @@
+package core
+
+import "strings"
+
+// quoteCurrency returns the currency a symbol's prices are quoted in: the
+// spec's currency, else the last three letters of a forex pair. "" when unknown.
+func quoteCurrency(spec *SymbolSpec) string {
+	if spec.Currency != "" {
+		return strings.ToUpper(spec.Currency)
+	}
+	switch DetectSymbolCategory(spec.Symbol) {
+	case CategoryForexMajor, CategoryForexMinor, CategoryForexExotic:
+		if len(spec.Symbol) == 6 {
+			return strings.ToUpper(spec.Symbol[3:])
+		}
+	}
+	return ""
+}
+
+// conversionRateUnlocked returns how many units of to one unit of from is
+// worth, from the latest quote of the direct (FROMTO) or inverse (TOFROM) pair.
+// price stands in for symbol's own quote, which may not be published yet.
+// ok is false when neither pair is quoted (caller must hold lock).
+func (e *Engine) conversionRateUnlocked(from, to, symbol string, price float64) (float64, bool) {
+	if from == to {
+		return 1, true
+	}
+
+	mid := func(pair string) (float64, bool) {
+		if pair == symbol && price > 0 {
+			return price, true
+		}
+		if _, registered := e.symbols[pair]; !registered || e.priceCallback == nil {
+			return 0, false
+		}
+		bid, ask, ok := e.priceCallback(pair)
+		if !ok || bid <= 0 || ask <= 0 {
+			return 0, false
+		}
+		return (bid + ask) / 2, true
+	}
+
+	if rate, ok := mid(from + to); ok {
+		return rate, true
+	}
+	if rate, ok := mid(to + from); ok {
+		return 1 / rate, true
+	}
+	return 0, false
+}
+
+// accountCurrencyPnLUnlocked converts a price move on a position into the
+// account currency when the symbol is quoted in another currency. ok is false
+// when no conversion applies or the conversion pair has no quote, leaving the
+// spec's fixed pip value to price the move (caller must hold lock).
+func (e *Engine) accountCurrencyPnLUnlocked(pos *Position, priceDiff, currentPrice, volume float64, spec *SymbolSpec) (float64, bool) {
+	account, ok := e.accounts[pos.AccountID]
+	if !ok || spec.ContractSize <= 0 {
+		return 0, false
+	}
+	accountCurrency := strings.ToUpper(account.Currency)
+	if accountCurrency == "" {
+		accountCurrency = "USD"
+	}
+
+	quote := quoteCurrency(spec)
+	if quote == "" || quote == accountCurrency {
+		return 0, false
+	}
+
+	rate, ok := e.conversionRateUnlocked(quote, accountCurrency, spec.Symbol, currentPrice)
+	if !ok {
+		return 0, false
+	}
+	return priceDiff * spec.ContractSize * volume * rate, true
+}
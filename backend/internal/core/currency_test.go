@@ -0,0 +1,80 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+// newConversionTestEngine returns a USD account on an engine with the given quotes
+func newConversionTestEngine(t *testing.T, quotes map[string][2]float64) (*Engine, *Account) {
+	t.Helper()
+
+	engine := NewEngine()
+	engine.SetPriceCallback(func(symbol string) (float64, float64, bool) {
+		q, ok := quotes[symbol]
+		return q[0], q[1], ok
+	})
+	account := engine.CreateAccount("user1", "trader", "password", true)
+	account.Balance = 100000
+	return engine, account
+}
+
+// TestJPYPairPnLConvertedAtOwnRate tests that USDJPY P/L, earned in yen, is
+// converted to USD at the USDJPY rate
+func TestJPYPairPnLConvertedAtOwnRate(t *testing.T) {
+	quotes := map[string][2]float64{"USDJPY": {150.00, 150.02}}
+	engine, account := newConversionTestEngine(t, quotes)
+
+	pos, err := engine.ExecuteMarketOrder(account.ID, "USDJPY", "BUY", 0.1, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	quotes["USDJPY"] = [2]float64{151.00, 151.02}
+	engine.UpdatePositionPrices()
+
+	// 0.98 yen on 10,000 units, converted at the 151.00 close rate
+	want := (151.00 - 150.02) * 10000 / 151.00
+	if math.Abs(pos.UnrealizedPnL-want) > 0.01 {
+		t.Errorf("unrealized P/L = %.2f USD, want %.2f", pos.UnrealizedPnL, want)
+	}
+
+	trade, err := engine.ClosePosition(pos.ID, 0)
+	if err != nil {
+		t.Fatalf("ClosePosition() error = %v", err)
+	}
+	if math.Abs(trade.RealizedPnL-want) > 0.01 {
+		t.Errorf("realized P/L = %.2f USD, want %.2f", trade.RealizedPnL, want)
+	}
+}
+
+// TestCrossPairPnLConvertedAtQuoteRate tests that EURGBP P/L, earned in
+// pounds, is converted to USD at the GBPUSD rate, falling back to the fixed
+// pip value once GBPUSD is no longer quoted
+func TestCrossPairPnLConvertedAtQuoteRate(t *testing.T) {
+	quotes := map[string][2]float64{
+		"EURGBP": {0.8500, 0.8502},
+		"GBPUSD": {1.2500, 1.2502},
+	}
+	engine, account := newConversionTestEngine(t, quotes)
+
+	pos, err := engine.ExecuteMarketOrder(account.ID, "EURGBP", "BUY", 1.0, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	quotes["EURGBP"] = [2]float64{0.8552, 0.8554}
+	engine.UpdatePositionPrices()
+
+	// 50 pips = 500 GBP, at the GBPUSD mid
+	want := (0.8552 - 0.8502) * 100000 * 1.2501
+	if math.Abs(pos.UnrealizedPnL-want) > 0.01 {
+		t.Errorf("unrealized P/L = %.2f USD, want %.2f", pos.UnrealizedPnL, want)
+	}
+
+	delete(quotes, "GBPUSD")
+	engine.UpdatePositionPrices()
+	spec, _ := engine.GetSymbol("EURGBP")
+	fallback := (0.8552 - 0.8502) / spec.PipSize * spec.PipValue
+	if math.Abs(pos.UnrealizedPnL-fallback) > 0.01 {
+		t.Errorf("unrealized P/L without GBPUSD = %.2f, want the pip value estimate %.2f", pos.UnrealizedPnL, fallback)
+	}
+}
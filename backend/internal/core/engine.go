@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"sync"
 	"time"
@@ -11,24 +12,28 @@ import (
 
 // Account represents a trading account
 type Account struct {
-	ID            int64       `json:"id"`
-	AccountNumber string      `json:"accountNumber"`
-	UserID        string      `json:"userId"`
-	Username      string      `json:"username"` // New: Admin-assigned username
-	Password      string      `json:"password"` // New: Admin-assigned password
-	Balance       float64     `json:"balance"`
-	Equity        float64     `json:"equity"`
-	Margin        float64     `json:"margin"`
-	FreeMargin    float64     `json:"freeMargin"`
-	MarginLevel   float64     `json:"marginLevel"`
-	Leverage      float64     `json:"leverage"`
-	MarginMode    string      `json:"marginMode"` // HEDGING or NETTING
-	Currency      string      `json:"currency"`
-	Status        string      `json:"status"` // ACTIVE, DISABLED
-	IsDemo        bool        `json:"isDemo"`
-	CreatedAt     int64       `json:"createdAt"`
-	Positions     []*Position `json:"-"` // Internal use only
-	Orders        []*Order    `json:"-"`
+	ID            int64   `json:"id"`
+	AccountNumber string  `json:"accountNumber"`
+	UserID        string  `json:"userId"`
+	Username      string  `json:"username"` // New: Admin-assigned username
+	Password      string  `json:"password"` // New: Admin-assigned password
+	Balance       float64 `json:"balance"`
+	Credit        float64 `json:"credit"` // Unreleased bonus: counts toward equity, not withdrawable
+	Equity        float64 `json:"equity"`
+	Margin        float64 `json:"margin"`
+	FreeMargin    float64 `json:"freeMargin"`
+	MarginLevel   float64 `json:"marginLevel"`
+	Leverage      float64 `json:"leverage"`
+	MarginMode    string  `json:"marginMode"` // HEDGING or NETTING
+	Currency      string  `json:"currency"`
+	Status        string  `json:"status"` // ACTIVE, DISABLED
+	IsDemo        bool    `json:"isDemo"`
+	SwapFree      bool    `json:"swapFree"` // Islamic account: no overnight swap, admin fee instead
+	// What orders do to opposite positions: HEDGE (default), CLOSE or REVERSE
+	OppositeSignal string      `json:"oppositeSignal,omitempty"`
+	CreatedAt      int64       `json:"createdAt"`
+	Positions      []*Position `json:"-"` // Internal use only
+	Orders         []*Order    `json:"-"`
 }
 
 // UpdatePassword updates an account's password
@@ -42,6 +47,7 @@ func (e *Engine) UpdatePassword(accountID int64, newPassword string) error {
 	}
 
 	account.Password = newPassword
+	e.persistAccountUnlocked(account)
 	log.Printf("[B-Book] Password updated for account %s", account.AccountNumber)
 	return nil
 }
@@ -60,8 +66,13 @@ func (e *Engine) UpdateAccount(accountID int64, leverage float64, marginMode str
 		account.Leverage = leverage
 	}
 	if marginMode != "" {
-		account.MarginMode = marginMode
+		normalized, err := NormalizeMarginMode(marginMode)
+		if err != nil {
+			return err
+		}
+		account.MarginMode = normalized
 	}
+	e.persistAccountUnlocked(account)
 
 	log.Printf("[B-Book] Account %s updated: Leverage=%.0f, Mode=%s", account.AccountNumber, account.Leverage, account.MarginMode)
 	return nil
@@ -80,12 +91,20 @@ type Position struct {
 	SL            float64   `json:"sl,omitempty"`
 	TP            float64   `json:"tp,omitempty"`
 	Swap          float64   `json:"swap"`
+	AdminFee      float64   `json:"adminFee,omitempty"` // Swap-free admin fees charged instead of swap
+	Nights        int       `json:"nights,omitempty"`   // Rollovers the position has been held through
 	Commission    float64   `json:"commission"`
 	UnrealizedPnL float64   `json:"unrealizedPnL"`
 	Status        string    `json:"status"`
 	ClosePrice    float64   `json:"closePrice,omitempty"`
 	CloseTime     time.Time `json:"closeTime,omitempty"`
 	CloseReason   string    `json:"closeReason,omitempty"`
+	TPLadder      *TPLadder `json:"tpLadder,omitempty"`  // Take-profit tranches, replacing TP when set
+	Book          string    `json:"book,omitempty"`      // A_BOOK when an exposure limit routed it to the LP
+	LPOrderID     string    `json:"lpOrderId,omitempty"` // LP order covering an A_BOOK position
+
+	lastRollover   time.Time // Rollover last applied, so a rollover is never applied twice
+	openCommission float64   // Opening commission not yet attributed to a close
 }
 
 // Order represents a trading order
@@ -101,10 +120,12 @@ type Order struct {
 	SL           float64    `json:"sl,omitempty"`
 	TP           float64    `json:"tp,omitempty"`
 	Status       string     `json:"status"`
+	FilledVolume float64    `json:"filledVolume,omitempty"`
 	FilledPrice  float64    `json:"filledPrice,omitempty"`
 	FilledAt     *time.Time `json:"filledAt,omitempty"`
 	PositionID   int64      `json:"positionId,omitempty"`
 	RejectReason string     `json:"rejectReason,omitempty"`
+	Requotes     int        `json:"requotes,omitempty"` // Times the price was requoted before the fill
 	CreatedAt    time.Time  `json:"createdAt"`
 }
 
@@ -121,6 +142,17 @@ type Trade struct {
 	RealizedPnL float64   `json:"realizedPnL"`
 	Commission  float64   `json:"commission"`
 	ExecutedAt  time.Time `json:"executedAt"`
+
+	// Closing fills: RealizedPnL less the round-turn commission of the closed
+	// volume, i.e. this fill's commission plus its share of the opening one
+	NetPnL float64 `json:"netPnL,omitempty"`
+
+	// Cost structure of an opening fill: COMMISSION charges Commission on the raw
+	// price, MARKUP widens Price by Markup with no explicit commission
+	CommissionModel string  `json:"commissionModel,omitempty"`
+	Markup          float64 `json:"markup,omitempty"`
+
+	Requotes int `json:"requotes,omitempty"` // Times the order was requoted before this fill
 }
 
 // AccountSummary contains computed account data
@@ -129,14 +161,18 @@ type AccountSummary struct {
 	AccountNumber string  `json:"accountNumber"`
 	Currency      string  `json:"currency"`
 	Balance       float64 `json:"balance"`
+	Credit        float64 `json:"credit"`
 	Equity        float64 `json:"equity"`
 	Margin        float64 `json:"margin"`
 	FreeMargin    float64 `json:"freeMargin"`
-	MarginLevel   float64 `json:"marginLevel"` // Percentage
-	UnrealizedPnL float64 `json:"unrealizedPnL"`
+	MarginLevel   float64 `json:"marginLevel"`   // Percentage
+	UnrealizedPnL float64 `json:"unrealizedPnL"` // Floating P/L of the open positions
 	Leverage      float64 `json:"leverage"`
-	MarginMode    string  `json:"marginMode"`
+	MarginMode    string  `json:"marginMode"` // Effective mode: the group's, else the account's
 	OpenPositions int     `json:"openPositions"`
+	// Realized P/L of the positions closed in the current trading day, which
+	// starts at the last rollover, from the ledger
+	RealizedPnLToday float64 `json:"realizedPnLToday"`
 }
 
 // Engine is the B-Book execution engine
@@ -151,21 +187,83 @@ type Engine struct {
 	nextOrderID    int64
 	nextTradeID    int64
 	priceCallback  func(symbol string) (bid, ask float64, ok bool)
+	staleCallback  func(symbol string) bool
 	ledger         *Ledger
+
+	quoteAgeCallback func(symbol string) (age time.Duration, ok bool)
+	maxPriceAge      time.Duration // Oldest quote a market order fills on, 0 = no limit
+
+	marginModeResolver MarginModeResolver // Group HEDGING/NETTING override
+
+	commissionModelResolver CommissionModelResolver
+	commissionRateResolver  CommissionRateResolver
+	commissionType          string // PER_LOT or PERCENT
+
+	closeOrder string // FIFO or LIFO lot selection on bulk and partial closes
+
+	requote RequoteConfig // Last look on market orders
+
+	slippage         SlippageConfig // Broker default slippage on market fills
+	slippageResolver SlippageResolver
+	slippageRand     func() float64 // Share of the slippage band drawn per fill, in [0, 1)
+
+	quoteMarkupResolver QuoteMarkupResolver
+	quoteMarkups        map[int64]map[string]quoteMarkup // accountID -> symbol -> resolved group markup
+
+	stopOutCooldown         time.Duration
+	stopOutCooldownResolver StopOutCooldownResolver
+	stopOutCooldownCallback func(accountID int64, until time.Time)
+	stopOutCooldowns        map[int64]time.Time // accountID -> end of post stop-out cooldown
+
+	marginCalls []time.Time // Margin calls and stop-outs of the last day, for risk reporting
+	stopOuts    []time.Time
+
+	marginLevels         MarginLevels // Default margin call and stop-out levels
+	marginLevelsResolver MarginLevelsResolver
+	marginEventCallback  func(MarginEvent)
+	marginCalled         map[int64]bool // Accounts under a margin call, alerted once per breach
+
+	exposureLimits          ExposureLimits // B-Book net exposure caps enforced at order acceptance
+	exposureRerouteCallback ExposureRerouteFunc
+
+	feedHealthCallback func() bool // false while no market data is flowing from any source
+
+	swapFreePolicy   SwapFreePolicy
+	rolloverSchedule RolloverSchedule // Trading day boundaries, set by StartDailyRollover
+
+	tradeEventListeners []func(TradeEvent)
+
+	bonuses     map[int64][]*Bonus // accountID -> credit bonuses
+	nextBonusID int64
+
+	store       Store        // Write-through persistence, nil keeps state in memory only
+	storeWriter *storeWriter // Applies writes to store in order outside the lock
 }
 
 // SymbolSpec contains symbol specifications
 type SymbolSpec struct {
-	Symbol           string  `json:"symbol"`
-	ContractSize     float64 `json:"contractSize"`
-	PipSize          float64 `json:"pipSize"`
-	PipValue         float64 `json:"pipValue"`
-	MinVolume        float64 `json:"minVolume"`
-	MaxVolume        float64 `json:"maxVolume"`
-	VolumeStep       float64 `json:"volumeStep"`
-	MarginPercent    float64 `json:"marginPercent"`
-	CommissionPerLot float64 `json:"commissionPerLot"`
-	Disabled         bool    `json:"disabled"` // True if trading/feed is disabled
+	Symbol           string          `json:"symbol"`
+	ContractSize     float64         `json:"contractSize"`
+	PipSize          float64         `json:"pipSize"`
+	Digits           int             `json:"digits"`             // Decimal places of quoted prices
+	Currency         string          `json:"currency,omitempty"` // Quote currency
+	PipValue         float64         `json:"pipValue"`
+	MinVolume        float64         `json:"minVolume"`
+	MaxVolume        float64         `json:"maxVolume"`
+	VolumeStep       float64         `json:"volumeStep"`
+	MarginPercent    float64         `json:"marginPercent"`
+	CommissionPerLot float64         `json:"commissionPerLot"`
+	CommissionModel  string          `json:"commissionModel"`            // COMMISSION or MARKUP
+	SpreadMarkup     float64         `json:"spreadMarkup,omitempty"`     // Pips added to the fill under the MARKUP model
+	FillLiquidity    float64         `json:"fillLiquidity,omitempty"`    // Lots fillable per market order at the quote, 0 = unlimited
+	MaxRecordRate    int             `json:"maxRecordRate,omitempty"`    // Ticks per second persisted, keeping the last of each interval, 0 = all
+	MaxPriceAgeMs    int64           `json:"maxPriceAgeMs,omitempty"`    // Oldest quote a market order fills on, 0 = engine default
+	Slippage         *SlippageConfig `json:"slippage,omitempty"`         // Slippage on market fills, nil = broker default
+	SwapLong         float64         `json:"swapLong"`                   // Per lot per night held long, negative = charge
+	SwapShort        float64         `json:"swapShort"`                  // Per lot per night held short, negative = charge
+	SwapFreeDisabled bool            `json:"swapFreeDisabled,omitempty"` // Swap-free accounts still pay swap on this symbol
+	Disabled         bool            `json:"disabled"`                   // True if trading/feed is disabled
+	SuspendPolicy    string          `json:"suspendPolicy,omitempty"`    // Non-empty while the symbol is suspended
 }
 
 // NewEngine creates a new B-Book engine
@@ -180,6 +278,19 @@ func NewEngine() *Engine {
 		nextOrderID:    1,
 		nextTradeID:    1,
 		ledger:         NewLedger(),
+		bonuses:        make(map[int64][]*Bonus),
+		nextBonusID:    1,
+		closeOrder:     CloseOrderFIFO,
+		commissionType: CommissionTypePerLot,
+		maxPriceAge:    DefaultMaxPriceAge,
+		slippage:       SlippageConfig{Model: SlippageNone},
+		slippageRand:   defaultSlippageRand,
+
+		rolloverSchedule: DefaultRolloverSchedule,
+
+		stopOutCooldowns: make(map[int64]time.Time),
+		marginLevels:     MarginLevels{MarginCall: DefaultMarginCallLevel, StopOut: DefaultStopOutLevel},
+		marginCalled:     make(map[int64]bool),
 	}
 
 	// Load symbols dynamically from tick data directory
@@ -229,10 +340,19 @@ func (e *Engine) GetSymbols() []*SymbolSpec {
 	return symbols
 }
 
+// GetSymbol returns a registered symbol without auto-registering unknown ones
+func (e *Engine) GetSymbol(symbol string) (*SymbolSpec, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	spec, ok := e.symbols[symbol]
+	return spec, ok
+}
+
 // UpdatePrice updates the current price for a symbol and triggers position checks
 func (e *Engine) UpdatePrice(symbol string, bid, ask float64) {
 	e.mu.Lock()
-	defer e.mu.Unlock()
+	affected := make(map[int64]bool) // Accounts whose margin level moved
 
 	// 1. Update Symbol Price in Memory (if any specific field uses it)
 	// Currently, positions track CurrentPrice individually, so we iterate them.
@@ -242,6 +362,12 @@ func (e *Engine) UpdatePrice(symbol string, bid, ask float64) {
 			continue
 		}
 
+		affected[pos.AccountID] = true
+
+		// Positions are priced and stopped at the quote their group sees
+		spec, ok := e.symbols[symbol]
+		bid, ask := e.markQuoteUnlocked(pos.AccountID, spec, bid, ask)
+
 		// Update in-memory current price for the position
 		var currentPrice float64
 		if pos.Side == "BUY" {
@@ -253,40 +379,35 @@ func (e *Engine) UpdatePrice(symbol string, bid, ask float64) {
 		}
 
 		// Recalculate PnL for display
-		spec, ok := e.symbols[symbol]
 		if ok {
 			pos.UnrealizedPnL = e.calculatePnL(pos, currentPrice, pos.Volume, spec)
 		}
 
-		// Check Stop Loss
-		if pos.SL > 0 {
-			if (pos.Side == "BUY" && currentPrice <= pos.SL) || (pos.Side == "SELL" && currentPrice >= pos.SL) {
-				log.Printf("[B-Book] SL Triggered for Position #%d (%s) @ %.5f", pos.ID, pos.Symbol, currentPrice)
-				// We must unlock to call ClosePosition because it also locks.
-				// However, ClosePosition takes a lock. We are currently holding a lock.
-				// WE CANNOT CALL ClosePosition DIRECTLY. deadlock.
-				// Strategy: Collect triggered positions and close them AFTER unlocking.
-				go func(pid int64, vol float64) {
-					if _, err := e.ClosePosition(pid, vol); err != nil {
-						log.Printf("[B-Book] Failed to execute SL close for #%d: %v", pid, err)
-					}
-				}(pos.ID, pos.Volume)
-				continue
-			}
+		// Read-only suspended symbols keep positions untouched
+		if ok && spec.SuspendPolicy == SuspendPolicyReadOnly {
+			continue
 		}
 
-		// Check Take Profit
-		if pos.TP > 0 {
-			if (pos.Side == "BUY" && currentPrice >= pos.TP) || (pos.Side == "SELL" && currentPrice <= pos.TP) {
-				log.Printf("[B-Book] TP Triggered for Position #%d (%s) @ %.5f", pos.ID, pos.Symbol, currentPrice)
-				go func(pid int64, vol float64) {
-					if _, err := e.ClosePosition(pid, vol); err != nil {
-						log.Printf("[B-Book] Failed to execute TP close for #%d: %v", pid, err)
-					}
-				}(pos.ID, pos.Volume)
-			}
+		// Stop loss closes at its level, or past it on a gap
+		if e.checkStopUnlocked(pos, pos.SL, CloseReasonStopLoss, bid, ask) {
+			continue
+		}
+
+		// Close take-profit ladder tranches the price has reached
+		e.checkTPLadderUnlocked(pos, currentPrice)
+		if pos.Status != "OPEN" {
+			continue
 		}
+
+		// Take profit likewise
+		e.checkStopUnlocked(pos, pos.TP, CloseReasonTakeProfit, bid, ask)
 	}
+
+	// Margin call and stop-out on the accounts this price moved
+	events := e.checkMarginLevelsUnlocked(affected)
+	e.mu.Unlock()
+
+	e.dispatchMarginEvents(events)
 }
 
 // SetPriceCallback sets the function to get current market prices
@@ -294,6 +415,17 @@ func (e *Engine) SetPriceCallback(fn func(symbol string) (bid, ask float64, ok b
 	e.priceCallback = fn
 }
 
+// SetStaleQuoteCallback sets the function reporting whether a symbol's price is
+// a recovered last-known quote. Stale prices value positions but never fill trades.
+func (e *Engine) SetStaleQuoteCallback(fn func(symbol string) bool) {
+	e.staleCallback = fn
+}
+
+// isQuoteStale reports whether the current price for symbol is not yet live
+func (e *Engine) isQuoteStale(symbol string) bool {
+	return e.staleCallback != nil && e.staleCallback(symbol)
+}
+
 // GetLedger returns the ledger
 func (e *Engine) GetLedger() *Ledger {
 	return e.ledger
@@ -320,12 +452,13 @@ func (e *Engine) CreateAccount(userID, username, password string, isDemo bool) *
 		Currency:      "USD",
 		Balance:       0,
 		Leverage:      100,
-		MarginMode:    "HEDGING",
+		MarginMode:    MarginModeHedging,
 		Status:        "ACTIVE",
 		IsDemo:        isDemo,
 	}
 
 	e.accounts[id] = account
+	e.persistAccountUnlocked(account)
 	log.Printf("[B-Book] Created account %s (User: %s, Username: %s)", account.AccountNumber, userID, username)
 	return account
 }
@@ -380,7 +513,7 @@ func (e *Engine) GetAccountSummary(accountID int64) (*AccountSummary, error) {
 		}
 	}
 
-	equity := account.Balance + unrealizedPnL
+	equity := account.Balance + account.Credit + unrealizedPnL
 	freeMargin := equity - usedMargin
 	marginLevel := 0.0
 	if usedMargin > 0 {
@@ -392,74 +525,155 @@ func (e *Engine) GetAccountSummary(accountID int64) (*AccountSummary, error) {
 		AccountNumber: account.AccountNumber,
 		Currency:      account.Currency,
 		Balance:       account.Balance,
+		Credit:        account.Credit,
 		Equity:        equity,
 		Margin:        usedMargin,
 		FreeMargin:    freeMargin,
 		MarginLevel:   marginLevel,
 		UnrealizedPnL: unrealizedPnL,
 		Leverage:      account.Leverage,
-		MarginMode:    account.MarginMode,
+		MarginMode:    e.marginModeUnlocked(account),
 		OpenPositions: openPositions,
+
+		RealizedPnLToday: e.ledger.RealizedPnLSince(accountID, e.rolloverSchedule.Last(time.Now())),
 	}, nil
 }
 
 // ExecuteMarketOrder executes a market order
 func (e *Engine) ExecuteMarketOrder(accountID int64, symbol, side string, volume, sl, tp float64) (*Position, error) {
-	e.mu.Lock()
-	defer e.mu.Unlock()
+	return e.ExecuteMarketOrderMinFill(accountID, symbol, side, volume, sl, tp, 0)
+}
 
-	// Get account
-	account, ok := e.accounts[accountID]
-	if !ok {
-		return nil, errors.New("account not found")
+// ExecuteMarketOrderMinFill executes a market order that may be partially filled
+// when the symbol's fill liquidity is short. A non-zero minFillRatio rejects the
+// whole order instead when the achievable share of the volume is below it.
+func (e *Engine) ExecuteMarketOrderMinFill(accountID int64, symbol, side string, volume, sl, tp, minFillRatio float64) (*Position, error) {
+	return e.ExecuteMarketOrderAt(accountID, symbol, side, volume, sl, tp, minFillRatio, 0)
+}
+
+// ExecuteMarketOrderAt executes a market order the client accepts filling at up
+// to acceptPrice, typically a requoted price being confirmed. 0 holds the
+// fill to the requote tolerance around the current quote.
+func (e *Engine) ExecuteMarketOrderAt(accountID int64, symbol, side string, volume, sl, tp, minFillRatio, acceptPrice float64) (*Position, error) {
+	return e.executeMarketOrder(accountID, symbol, side, volume, sl, tp, minFillRatio, acceptPrice, 0)
+}
+
+// ExecuteLimitOrder fills a triggered limit order at the market, but only at
+// limitPrice or better: a fill that slipped past the limit is rejected with a
+// RequoteError quoting the limit.
+func (e *Engine) ExecuteLimitOrder(accountID int64, symbol, side string, volume, sl, tp, limitPrice float64) (*Position, error) {
+	if limitPrice <= 0 {
+		return nil, errors.New("invalid limit price")
 	}
+	return e.executeMarketOrder(accountID, symbol, side, volume, sl, tp, 0, 0, limitPrice)
+}
 
-	if account.Status != "ACTIVE" {
-		return nil, errors.New("account is not active")
+// executeMarketOrder runs last look and books a market order, routing it
+// through the LP first when an exposure limit sends it to A-Book
+func (e *Engine) executeMarketOrder(accountID int64, symbol, side string, volume, sl, tp, minFillRatio, acceptPrice, limitPrice float64) (*Position, error) {
+	// Last look runs before the lock is taken, since it waits on the price
+	requotes, err := e.lastLook(symbol, side)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get symbol specs
-	spec, ok := e.symbols[symbol]
-	if !ok {
-		return nil, fmt.Errorf("symbol %s not found", symbol)
+	e.mu.Lock()
+	position, route, err := e.executeMarketOrderUnlocked(accountID, symbol, side, volume, sl, tp, minFillRatio, acceptPrice, limitPrice, requotes, nil)
+	e.mu.Unlock()
+	if route == nil {
+		return position, err
 	}
 
-	// Validate volume
-	if volume < spec.MinVolume || volume > spec.MaxVolume {
-		return nil, fmt.Errorf("volume must be between %.2f and %.2f", spec.MinVolume, spec.MaxVolume)
+	// An exposure limit flipped the order to A-Book: it books only once the LP
+	// has taken it, which happens without the lock held
+	if err := e.sendToABook(route); err != nil {
+		return nil, err
 	}
+	e.mu.Lock()
+	position, _, err = e.executeMarketOrderUnlocked(accountID, symbol, side, volume, sl, tp, minFillRatio, acceptPrice, limitPrice, requotes, route)
+	e.mu.Unlock()
+	if err != nil {
+		e.unwindABook(route, err)
+		return nil, err
+	}
+	return position, nil
+}
 
-	// Get current price
-	if e.priceCallback == nil {
-		return nil, errors.New("price feed not available")
+// executeMarketOrderUnlocked validates and books a market order. It returns a
+// route without booking anything when an exposure limit sends the order to
+// A-Book; called again with the route once the LP has taken it, it books the
+// position on the A-Book. A non-zero limitPrice rejects a fill worse than it
+// (caller must hold lock).
+func (e *Engine) executeMarketOrderUnlocked(accountID int64, symbol, side string, volume, sl, tp, minFillRatio, acceptPrice, limitPrice float64, requotes int, route *aBookRoute) (*Position, *aBookRoute, error) {
+	requestedVolume := volume
+	pt, _, err := e.preTradeUnlocked(accountID, symbol, side, volume, minFillRatio)
+	if err != nil {
+		return nil, nil, err
 	}
+	account, spec, plan, bid, ask := pt.account, pt.spec, pt.plan, pt.bid, pt.ask
 
-	bid, ask, ok := e.priceCallback(symbol)
-	if !ok {
-		return nil, fmt.Errorf("no price available for %s", symbol)
+	// Fill at the side of the quote the order crosses
+	rawPrice := ask
+	if side == "SELL" {
+		rawPrice = bid
 	}
 
-	// Determine fill price
-	var fillPrice float64
-	if side == "BUY" {
-		fillPrice = ask
-	} else if side == "SELL" {
-		fillPrice = bid
-	} else {
-		return nil, errors.New("invalid side: must be BUY or SELL")
+	// Slip the fill off the quote, or requote it for the client to confirm
+	rawPrice, err = e.slipFillUnlocked(e.slippageUnlocked(accountID, spec), spec, side, rawPrice, acceptPrice)
+	if err != nil {
+		log.Printf("[B-Book] Order requoted: %v", err)
+		return nil, nil, err
+	}
+	if limitPrice > 0 && (side == "BUY" && rawPrice > limitPrice+1e-9 || side == "SELL" && rawPrice < limitPrice-1e-9) {
+		err := &RequoteError{Symbol: symbol, Side: side, Quoted: limitPrice, Price: rawPrice}
+		log.Printf("[B-Book] Limit order rejected: %v", err)
+		return nil, nil, err
 	}
 
-	// Calculate required margin
-	requiredMargin := e.calculateMargin(symbol, volume, fillPrice, account.Leverage)
+	// Exposure limits, checked on the volume left to open once opposite
+	// positions are netted or closed: reject, or take the order off the B-Book
+	book := ""
+	if route != nil {
+		book = ExposureActionABook
+	} else if exposure := e.checkOrderExposureUnlocked(symbol, side, plan); !exposure.Allowed {
+		if exposure.Action != ExposureActionABook || e.exposureRerouteCallback == nil {
+			log.Printf("[B-Book] Order rejected: %s", exposure.Reason)
+			return nil, nil, fmt.Errorf("%w: %s", ErrExposureLimit, exposure.Reason)
+		}
+		log.Printf("[B-Book] Routing to A-Book: %s", exposure.Reason)
+		return nil, &aBookRoute{
+			accountID: accountID,
+			symbol:    symbol,
+			side:      side,
+			volume:    exposure.OpenVolume,
+			send:      e.exposureRerouteCallback,
+		}, nil
+	}
+
+	volume = plan.open
+
+	// The LP took exactly the volume routed to it
+	if route != nil && math.Abs(volume-route.volume) > 1e-9 {
+		return nil, nil, fmt.Errorf("positions changed while routing to A-Book: %.2f lots to open, %.2f sent to the LP", volume, route.volume)
+	}
 
-	// Check free margin
-	summary, _ := e.getAccountSummaryUnlocked(accountID)
-	if summary.FreeMargin < requiredMargin {
-		return nil, fmt.Errorf("insufficient margin: required %.2f, available %.2f", requiredMargin, summary.FreeMargin)
+	// Apply the commission model: either a marked-up fill or an explicit commission
+	commissionModel := e.commissionModelUnlocked(accountID, spec)
+	fillPrice, commission, markup := applyCommissionModel(commissionModel, spec, side, rawPrice,
+		e.commissionUnlocked(accountID, spec, volume, rawPrice))
+
+	// The positions the order closes release their margin first
+	if volume > 0 {
+		if required, available := e.marginUnlocked(pt, fillPrice); available < required {
+			return nil, nil, fmt.Errorf("insufficient margin: required %.2f, available %.2f", required, available)
+		}
 	}
 
-	// Calculate commission
-	commission := spec.CommissionPerLot * volume
+	// Every check passed: close the opposite positions. An order that only
+	// closes returns the last position it closed.
+	if closed := e.applyOppositeUnlocked(account, plan, bid, ask); closed != nil && volume <= 0 {
+		return closed, nil, nil
+	}
 
 	// Create order
 	orderID := e.nextOrderID
@@ -467,40 +681,59 @@ func (e *Engine) ExecuteMarketOrder(accountID int64, symbol, side string, volume
 
 	now := time.Now()
 	order := &Order{
-		ID:          orderID,
-		AccountID:   accountID,
-		Symbol:      symbol,
-		Type:        "MARKET",
-		Side:        side,
-		Volume:      volume,
-		SL:          sl,
-		TP:          tp,
-		Status:      "FILLED",
-		FilledPrice: fillPrice,
-		FilledAt:    &now,
-		CreatedAt:   now,
-	}
-	e.orders[orderID] = order
-
-	// Create position
-	positionID := e.nextPositionID
-	e.nextPositionID++
-
-	position := &Position{
-		ID:           positionID,
+		ID:           orderID,
 		AccountID:    accountID,
 		Symbol:       symbol,
+		Type:         "MARKET",
 		Side:         side,
-		Volume:       volume,
-		OpenPrice:    fillPrice,
-		CurrentPrice: fillPrice,
-		OpenTime:     now,
+		Volume:       requestedVolume,
+		FilledVolume: volume,
 		SL:           sl,
 		TP:           tp,
-		Commission:   commission,
-		Status:       "OPEN",
+		Status:       "FILLED",
+		FilledPrice:  fillPrice,
+		FilledAt:     &now,
+		Requotes:     requotes,
+		CreatedAt:    now,
+	}
+	if volume < requestedVolume {
+		order.Status = "PARTIALLY_FILLED"
+	}
+	e.orders[orderID] = order
+
+	// Netting accounts add to their position on the symbol at the volume-weighted
+	// average entry; otherwise the fill opens a new position
+	var position *Position
+	if plan.netting && book == "" {
+		position = e.averageIntoUnlocked(accountID, symbol, side, volume, fillPrice, sl, tp, commission)
 	}
-	e.positions[positionID] = position
+	if position == nil {
+		positionID := e.nextPositionID
+		e.nextPositionID++
+
+		position = &Position{
+			ID:           positionID,
+			AccountID:    accountID,
+			Symbol:       symbol,
+			Side:         side,
+			Volume:       volume,
+			OpenPrice:    fillPrice,
+			CurrentPrice: fillPrice,
+			OpenTime:     now,
+			SL:           sl,
+			TP:           tp,
+			Commission:   commission,
+			Status:       "OPEN",
+			Book:         book,
+
+			openCommission: commission,
+		}
+		if route != nil {
+			position.LPOrderID = route.orderID
+		}
+		e.positions[positionID] = position
+	}
+	positionID := position.ID
 
 	order.PositionID = positionID
 
@@ -509,16 +742,19 @@ func (e *Engine) ExecuteMarketOrder(accountID int64, symbol, side string, volume
 	e.nextTradeID++
 
 	trade := Trade{
-		ID:         tradeID,
-		OrderID:    orderID,
-		PositionID: positionID,
-		AccountID:  accountID,
-		Symbol:     symbol,
-		Side:       side,
-		Volume:     volume,
-		Price:      fillPrice,
-		Commission: commission,
-		ExecutedAt: now,
+		ID:              tradeID,
+		OrderID:         orderID,
+		PositionID:      positionID,
+		AccountID:       accountID,
+		Symbol:          symbol,
+		Side:            side,
+		Volume:          volume,
+		Price:           fillPrice,
+		Commission:      commission,
+		CommissionModel: commissionModel,
+		Markup:          markup,
+		Requotes:        requotes,
+		ExecutedAt:      now,
 	}
 	e.trades = append(e.trades, trade)
 
@@ -530,7 +766,34 @@ func (e *Engine) ExecuteMarketOrder(accountID int64, symbol, side string, volume
 
 	log.Printf("[B-Book] EXECUTED: %s %s %.2f lots @ %.5f (Position #%d)", side, symbol, volume, fillPrice, positionID)
 
-	return position, nil
+	e.publishTradeEventUnlocked(TradeEvent{
+		Type:            TradeEventOrderAccepted,
+		AccountID:       accountID,
+		OrderID:         orderID,
+		Symbol:          symbol,
+		Side:            side,
+		OrderType:       order.Type,
+		Volume:          requestedVolume,
+		RequestedVolume: requestedVolume,
+		Timestamp:       now,
+	})
+	e.publishTradeEventUnlocked(TradeEvent{
+		Type:            TradeEventOrderFilled,
+		AccountID:       accountID,
+		OrderID:         orderID,
+		PositionID:      positionID,
+		TradeID:         tradeID,
+		Symbol:          symbol,
+		Side:            side,
+		OrderType:       order.Type,
+		Volume:          volume,
+		Price:           fillPrice,
+		Commission:      commission,
+		RequestedVolume: requestedVolume,
+		Timestamp:       now,
+	})
+
+	return position, nil, nil
 }
 
 // ClosePosition closes a position
@@ -547,6 +810,15 @@ func (e *Engine) ClosePosition(positionID int64, closeVolume float64) (*Trade, e
 		return nil, errors.New("position is not open")
 	}
 
+	if err := e.checkCanModify(position.Symbol); err != nil {
+		return nil, err
+	}
+
+	return e.closePositionUnlocked(position, closeVolume)
+}
+
+// closePositionUnlocked closes a position at market (caller must hold lock)
+func (e *Engine) closePositionUnlocked(position *Position, closeVolume float64) (*Trade, error) {
 	// Get current price
 	if e.priceCallback == nil {
 		return nil, errors.New("price feed not available")
@@ -556,15 +828,25 @@ func (e *Engine) ClosePosition(positionID int64, closeVolume float64) (*Trade, e
 	if !ok {
 		return nil, errors.New("no price available")
 	}
+	if e.isQuoteStale(position.Symbol) {
+		return nil, fmt.Errorf("price for %s is stale, waiting for live quotes", position.Symbol)
+	}
+	bid, ask = e.markQuoteUnlocked(position.AccountID, e.symbols[position.Symbol], bid, ask)
 
-	// Determine close price (opposite of entry)
-	var closePrice float64
-	var closeSide string
-	if position.Side == "BUY" {
-		closePrice = bid
-		closeSide = "CLOSE_BUY"
-	} else {
+	// Close at the opposite side of entry
+	closePrice := bid
+	if position.Side != "BUY" {
 		closePrice = ask
+	}
+	return e.closePositionAtUnlocked(position, closeVolume, closePrice)
+}
+
+// closePositionAtUnlocked closes a position at closePrice (caller must hold lock)
+func (e *Engine) closePositionAtUnlocked(position *Position, closeVolume, closePrice float64) (*Trade, error) {
+	positionID := position.ID
+
+	closeSide := "CLOSE_BUY"
+	if position.Side != "BUY" {
 		closeSide = "CLOSE_SELL"
 	}
 
@@ -580,14 +862,27 @@ func (e *Engine) ClosePosition(positionID int64, closeVolume float64) (*Trade, e
 	// Get account
 	account := e.accounts[position.AccountID]
 
+	// Closing side of the round-turn commission, and the share of the opening
+	// commission the closed volume carries
+	var commission float64
+	if spec != nil && e.commissionModelUnlocked(account.ID, spec) == CommissionModelCommission {
+		commission = e.commissionUnlocked(account.ID, spec, closeVolume, closePrice)
+	}
+	openShare := position.openCommission * closeVolume / position.Volume
+	position.openCommission -= openShare
+
 	// Update account balance
-	account.Balance += realizedPnL
+	account.Balance += realizedPnL - commission
 
 	// Record in ledger
 	tradeID := e.nextTradeID
 	e.nextTradeID++
 
 	e.ledger.RecordRealizedPnL(account.ID, realizedPnL, tradeID)
+	if commission > 0 {
+		e.ledger.RecordCommission(account.ID, -commission, tradeID)
+		position.Commission += commission
+	}
 
 	now := time.Now()
 
@@ -601,10 +896,15 @@ func (e *Engine) ClosePosition(positionID int64, closeVolume float64) (*Trade, e
 		Volume:      closeVolume,
 		Price:       closePrice,
 		RealizedPnL: realizedPnL,
+		Commission:  commission,
+		NetPnL:      realizedPnL - commission - openShare,
 		ExecutedAt:  now,
 	}
 	e.trades = append(e.trades, trade)
 
+	// Closed volume counts toward bonus release conditions
+	e.recordBonusVolumeUnlocked(account, closeVolume)
+
 	// Update position
 	if closeVolume >= position.Volume {
 		position.Status = "CLOSED"
@@ -616,6 +916,37 @@ func (e *Engine) ClosePosition(positionID int64, closeVolume float64) (*Trade, e
 
 	log.Printf("[B-Book] CLOSED: %s Position #%d %.2f lots @ %.5f | P/L: %.2f", position.Symbol, positionID, closeVolume, closePrice, realizedPnL)
 
+	event := TradeEvent{
+		Type:        TradeEventPositionClosed,
+		AccountID:   account.ID,
+		PositionID:  positionID,
+		TradeID:     tradeID,
+		Symbol:      position.Symbol,
+		Side:        closeSide,
+		Volume:      closeVolume,
+		Price:       closePrice,
+		Commission:  commission,
+		RealizedPnL: realizedPnL,
+		CloseReason: position.CloseReason,
+		Timestamp:   now,
+	}
+	if position.Status == "OPEN" {
+		event.RemainingVolume = position.Volume
+	}
+	e.publishTradeEventUnlocked(event)
+
+	// The LP cover of an A-Book position closes with it
+	if position.Book == ExposureActionABook {
+		go e.closeABook(position.ID, &aBookRoute{
+			accountID: account.ID,
+			symbol:    position.Symbol,
+			side:      position.Side,
+			volume:    closeVolume,
+			orderID:   position.LPOrderID,
+			send:      e.exposureRerouteCallback,
+		})
+	}
+
 	return &trade, nil
 }
 
@@ -633,8 +964,13 @@ func (e *Engine) ModifyPosition(positionID int64, sl, tp float64) (*Position, er
 		return nil, errors.New("position is not open")
 	}
 
+	if err := e.checkCanModify(position.Symbol); err != nil {
+		return nil, err
+	}
+
 	position.SL = sl
 	position.TP = tp
+	e.persistPositionUnlocked(position)
 
 	log.Printf("[B-Book] MODIFIED: Position #%d SL: %.5f TP: %.5f", positionID, sl, tp)
 
@@ -655,6 +991,17 @@ func (e *Engine) GetPositions(accountID int64) []*Position {
 	return positions
 }
 
+// GetPosition returns an open position by ID
+func (e *Engine) GetPosition(positionID int64) (*Position, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	pos, ok := e.positions[positionID]
+	if !ok || pos.Status != "OPEN" {
+		return nil, false
+	}
+	return pos, true
+}
+
 // GetAllPositions returns all open positions
 func (e *Engine) GetAllPositions() []*Position {
 	e.mu.RLock()
@@ -669,6 +1016,28 @@ func (e *Engine) GetAllPositions() []*Position {
 	return positions
 }
 
+// GetClosedPositions returns all closed positions
+func (e *Engine) GetClosedPositions() []*Position {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var positions []*Position
+	for _, pos := range e.positions {
+		if pos.Status == "CLOSED" {
+			positions = append(positions, pos)
+		}
+	}
+	return positions
+}
+
+// GetNetExposure returns net client volume per symbol across all open B-Book
+// positions (positive = clients net long, negative = clients net short)
+func (e *Engine) GetNetExposure() map[string]float64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.netExposureUnlocked()
+}
+
 // GetOrders returns orders for an account
 func (e *Engine) GetOrders(accountID int64, status string) []*Order {
 	e.mu.RLock()
@@ -685,6 +1054,18 @@ func (e *Engine) GetOrders(accountID int64, status string) []*Order {
 	return orders
 }
 
+// OrderCounts returns the number of orders in each status across all accounts
+func (e *Engine) OrderCounts() map[string]int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, order := range e.orders {
+		counts[order.Status]++
+	}
+	return counts
+}
+
 // GetTrades returns trades for an account
 func (e *Engine) GetTrades(accountID int64) []Trade {
 	e.mu.RLock()
@@ -706,7 +1087,7 @@ func (e *Engine) UpdatePositionPrices() {
 	}
 
 	e.mu.Lock()
-	defer e.mu.Unlock()
+	affected := make(map[int64]bool)
 
 	for _, pos := range e.positions {
 		if pos.Status != "OPEN" {
@@ -717,6 +1098,7 @@ func (e *Engine) UpdatePositionPrices() {
 		if !ok {
 			continue
 		}
+		bid, ask = e.markQuoteUnlocked(pos.AccountID, e.symbols[pos.Symbol], bid, ask)
 
 		// Update current price
 		if pos.Side == "BUY" {
@@ -730,7 +1112,13 @@ func (e *Engine) UpdatePositionPrices() {
 		if ok {
 			pos.UnrealizedPnL = e.calculatePnL(pos, pos.CurrentPrice, pos.Volume, spec)
 		}
+		affected[pos.AccountID] = true
 	}
+
+	events := e.checkMarginLevelsUnlocked(affected)
+	e.mu.Unlock()
+
+	e.dispatchMarginEvents(events)
 }
 
 // calculateMargin calculates required margin for a trade
@@ -751,7 +1139,7 @@ func (e *Engine) calculatePositionMargin(pos *Position, spec *SymbolSpec, levera
 	return notional / leverage
 }
 
-// calculatePnL calculates P/L for a position
+// calculatePnL calculates P/L for a position in the account currency (caller must hold lock)
 func (e *Engine) calculatePnL(pos *Position, currentPrice, volume float64, spec *SymbolSpec) float64 {
 	if spec == nil {
 		return 0
@@ -764,6 +1152,11 @@ func (e *Engine) calculatePnL(pos *Position, currentPrice, volume float64, spec
 		priceDiff = pos.OpenPrice - currentPrice
 	}
 
+	// Quoted in another currency: convert at the quote currency's latest rate
+	if pnl, ok := e.accountCurrencyPnLUnlocked(pos, priceDiff, currentPrice, volume, spec); ok {
+		return pnl
+	}
+
 	// P/L = (PriceDiff / PipSize) * PipValue * Volume
 	pips := priceDiff / spec.PipSize
 	return pips * spec.PipValue * volume
@@ -789,11 +1182,12 @@ func (e *Engine) getAccountSummaryUnlocked(accountID int64) (*AccountSummary, er
 		}
 	}
 
-	equity := account.Balance + unrealizedPnL
+	equity := account.Balance + account.Credit + unrealizedPnL
 	freeMargin := equity - usedMargin
 
 	return &AccountSummary{
 		Balance:    account.Balance,
+		Credit:     account.Credit,
 		Equity:     equity,
 		Margin:     usedMargin,
 		FreeMargin: freeMargin,
@@ -0,0 +1,262 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+)
+
+// Exposure limit actions, taken when an order would breach a cap
+const (
+	ExposureActionReject = "REJECT" // Refuse the order
+	ExposureActionABook  = "A_BOOK" // Route the order to the LP instead of the B-Book
+)
+
+// ErrExposureLimit is returned when an order would push B-Book net exposure past a cap
+var ErrExposureLimit = errors.New("EXPOSURE_LIMIT")
+
+// ExposureLimits caps the net client volume the B-Book carries. Symbol limits
+// cap |net lots| per symbol, Aggregate caps the sum of |net lots| across symbols.
+// 0 or a missing symbol means no limit.
+type ExposureLimits struct {
+	Symbols   map[string]float64 `json:"symbols,omitempty"`
+	Aggregate float64            `json:"aggregate"`
+	Action    string             `json:"action"` // REJECT or A_BOOK
+}
+
+// NormalizeExposureAction validates an exposure limit action, defaulting to REJECT
+func NormalizeExposureAction(action string) (string, error) {
+	switch strings.ToUpper(action) {
+	case "", ExposureActionReject:
+		return ExposureActionReject, nil
+	case ExposureActionABook, "ABOOK":
+		return ExposureActionABook, nil
+	default:
+		return "", fmt.Errorf("invalid exposure action %q: must be REJECT or A_BOOK", action)
+	}
+}
+
+// Validate rejects negative limits and unknown actions
+func (l ExposureLimits) Validate() error {
+	if l.Aggregate < 0 {
+		return fmt.Errorf("aggregate exposure limit must not be negative")
+	}
+	for symbol, limit := range l.Symbols {
+		if limit < 0 {
+			return fmt.Errorf("exposure limit for %s must not be negative", symbol)
+		}
+	}
+	_, err := NormalizeExposureAction(l.Action)
+	return err
+}
+
+// ExposureDecision is the outcome of checking an order against the exposure limits
+type ExposureDecision struct {
+	Allowed        bool    `json:"allowed"`          // Fits on the B-Book
+	Action         string  `json:"action,omitempty"` // Action taken when not allowed
+	Reason         string  `json:"reason,omitempty"`
+	SymbolNet      float64 `json:"symbolNet"`      // Net lots of the symbol after the order
+	SymbolLimit    float64 `json:"symbolLimit"`    // 0 = no limit
+	AggregateNet   float64 `json:"aggregateNet"`   // Sum of |net lots| after the order
+	AggregateLimit float64 `json:"aggregateLimit"` // 0 = no limit
+	OpenVolume     float64 `json:"openVolume"`     // Lots the order opens once opposite positions are netted or closed
+}
+
+// ExposureRerouteFunc sends an order flipped to A-Book to the LP and returns its order ID
+type ExposureRerouteFunc func(accountID int64, symbol, side string, volume float64) (string, error)
+
+// SetExposureLimits sets the B-Book exposure caps
+func (e *Engine) SetExposureLimits(limits ExposureLimits) error {
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+	limits.Action, _ = NormalizeExposureAction(limits.Action)
+
+	symbols := make(map[string]float64, len(limits.Symbols))
+	for symbol, limit := range limits.Symbols {
+		if limit > 0 {
+			symbols[strings.ToUpper(symbol)] = limit
+		}
+	}
+	limits.Symbols = symbols
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.exposureLimits = limits
+	log.Printf("[B-Book] Exposure limits set: %d symbols, aggregate %.2f lots, action %s", len(symbols), limits.Aggregate, limits.Action)
+	return nil
+}
+
+// GetExposureLimits returns the B-Book exposure caps
+func (e *Engine) GetExposureLimits() ExposureLimits {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	limits := e.exposureLimits
+	limits.Symbols = make(map[string]float64, len(e.exposureLimits.Symbols))
+	for symbol, limit := range e.exposureLimits.Symbols {
+		limits.Symbols[symbol] = limit
+	}
+	return limits
+}
+
+// SetExposureRerouteCallback sets the function that sends orders flipped to
+// A-Book by an exposure limit to the LP
+func (e *Engine) SetExposureRerouteCallback(fn ExposureRerouteFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.exposureRerouteCallback = fn
+}
+
+// CheckExposure previews how the exposure limits treat an order without executing it
+func (e *Engine) CheckExposure(symbol, side string, volume float64) ExposureDecision {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.checkExposureUnlocked(symbol, side, volume)
+}
+
+// netExposureUnlocked returns B-Book net client volume per symbol. Positions
+// routed to A-Book are covered at the LP and don't count (caller must hold lock).
+func (e *Engine) netExposureUnlocked() map[string]float64 {
+	exposure := make(map[string]float64)
+	for _, pos := range e.positions {
+		if pos.Status != "OPEN" || pos.Book == ExposureActionABook {
+			continue
+		}
+		if pos.Side == "BUY" {
+			exposure[pos.Symbol] += pos.Volume
+		} else {
+			exposure[pos.Symbol] -= pos.Volume
+		}
+	}
+	return exposure
+}
+
+// checkExposureUnlocked checks an order against the exposure limits as if it
+// opened all of volume (caller must hold lock)
+func (e *Engine) checkExposureUnlocked(symbol, side string, volume float64) ExposureDecision {
+	return e.exposureDecisionUnlocked(symbol, side, 0, volume)
+}
+
+// checkOrderExposureUnlocked checks an account's order against the exposure
+// limits before anything is booked, taking into account the opposite
+// B-Book positions the order nets or closes first (caller must hold lock)
+func (e *Engine) checkOrderExposureUnlocked(symbol, side string, plan oppositePlan) ExposureDecision {
+	var closing float64
+	for _, c := range plan.closes {
+		if c.lot.Book != ExposureActionABook {
+			closing += c.volume
+		}
+	}
+	return e.exposureDecisionUnlocked(symbol, side, closing, plan.open)
+}
+
+// exposureDecisionUnlocked checks an order that closes closing lots of
+// opposite B-Book positions and opens open lots. An order that reduces
+// exposure is always allowed, even while over a cap (caller must hold lock).
+func (e *Engine) exposureDecisionUnlocked(symbol, side string, closing, open float64) ExposureDecision {
+	limits := e.exposureLimits
+	exposure := e.netExposureUnlocked()
+
+	signed := closing + open
+	if side == "SELL" {
+		signed = -signed
+	}
+	before := exposure[symbol]
+	after := before + signed
+
+	aggregate := 0.0
+	for s, net := range exposure {
+		if s != symbol {
+			aggregate += math.Abs(net)
+		}
+	}
+
+	decision := ExposureDecision{
+		Allowed:        true,
+		SymbolNet:      after,
+		SymbolLimit:    limits.Symbols[symbol],
+		AggregateNet:   aggregate + math.Abs(after),
+		AggregateLimit: limits.Aggregate,
+		OpenVolume:     open,
+	}
+	if math.Abs(after) <= math.Abs(before) {
+		return decision
+	}
+
+	const epsilon = 1e-9
+	switch {
+	case decision.SymbolLimit > 0 && math.Abs(after) > decision.SymbolLimit+epsilon:
+		decision.Reason = fmt.Sprintf("%s net exposure %.2f lots would exceed the %.2f lot limit", symbol, after, decision.SymbolLimit)
+	case decision.AggregateLimit > 0 && decision.AggregateNet > decision.AggregateLimit+epsilon:
+		decision.Reason = fmt.Sprintf("aggregate net exposure %.2f lots would exceed the %.2f lot limit", decision.AggregateNet, decision.AggregateLimit)
+	default:
+		return decision
+	}
+	decision.Allowed = false
+	decision.Action = limits.Action
+	if decision.Action == "" {
+		decision.Action = ExposureActionReject
+	}
+	return decision
+}
+
+// aBookRoute is an order an exposure limit sent to A-Book, or the closing
+// of one at the LP
+type aBookRoute struct {
+	accountID    int64
+	symbol, side string
+	volume       float64
+	orderID      string // LP order ID once sent
+	send         ExposureRerouteFunc
+}
+
+// sendToABook sends a routed order to the LP before anything is booked. The
+// order is rejected when the LP does not take it. Must be called without the
+// lock held.
+func (e *Engine) sendToABook(route *aBookRoute) error {
+	if route.send == nil {
+		return fmt.Errorf("%w: no LP connection to route to A-Book", ErrExposureLimit)
+	}
+	orderID, err := route.send(route.accountID, route.symbol, route.side, route.volume)
+	if err != nil {
+		log.Printf("[B-Book] A-Book route of %s %.2f %s failed, rejecting: %v", route.side, route.volume, route.symbol, err)
+		return fmt.Errorf("%w: A-Book route failed: %v", ErrExposureLimit, err)
+	}
+	route.orderID = orderID
+	log.Printf("[B-Book] %s %.2f %s routed to A-Book by exposure limit: LP order %s", route.side, route.volume, route.symbol, orderID)
+	return nil
+}
+
+// unwindABook offsets an LP order whose client order could not be booked
+// after all. Must be called without the lock held.
+func (e *Engine) unwindABook(route *aBookRoute, cause error) {
+	log.Printf("[B-Book] Order routed to A-Book as LP order %s failed to book: %v", route.orderID, cause)
+	if _, err := route.send(route.accountID, route.symbol, oppositeSide(route.side), route.volume); err != nil {
+		log.Printf("[B-Book] CRITICAL: failed to unwind LP order %s, %.2f %s left uncovered at the LP: %v", route.orderID, route.volume, route.symbol, err)
+	}
+}
+
+// closeABook closes the LP cover of an A-Book position as the client closes it
+func (e *Engine) closeABook(positionID int64, route *aBookRoute) {
+	if route.send == nil {
+		log.Printf("[B-Book] CRITICAL: no LP connection to close %.2f lots of position #%d's LP order %s", route.volume, positionID, route.orderID)
+		return
+	}
+	orderID, err := route.send(route.accountID, route.symbol, oppositeSide(route.side), route.volume)
+	if err != nil {
+		log.Printf("[B-Book] CRITICAL: failed to close %.2f lots of position #%d's LP order %s: %v", route.volume, positionID, route.orderID, err)
+		return
+	}
+	log.Printf("[B-Book] Closed %.2f lots of position #%d at the LP: LP order %s", route.volume, positionID, orderID)
+}
+
+// oppositeSide returns the other side of a BUY or SELL
+func oppositeSide(side string) string {
+	if side == "SELL" {
+		return "BUY"
+	}
+	return "SELL"
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// TestExposureLimitRejectsBreach tests that successive same-direction orders fill
+// up to the symbol cap, the breaching one is rejected and a reducing one still fills
+func TestExposureLimitRejectsBreach(t *testing.T) {
+	engine, account := newTestEngine(t)
+	if err := engine.SetExposureLimits(ExposureLimits{Symbols: map[string]float64{"eurusd": 0.3}}); err != nil {
+		t.Fatalf("SetExposureLimits() error = %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		openTestPosition(t, engine, account.ID, "EURUSD") // 0.1, 0.2, then exactly at the 0.3 cap
+	}
+	if decision := engine.CheckExposure("EURUSD", "BUY", 0.1); decision.Allowed || decision.Action != ExposureActionReject {
+		t.Errorf("CheckExposure() = %+v, want a rejection preview", decision)
+	}
+
+	_, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0)
+	if !errors.Is(err, ErrExposureLimit) {
+		t.Fatalf("ExecuteMarketOrder() error = %v, want ErrExposureLimit", err)
+	}
+	if net := engine.GetNetExposure()["EURUSD"]; math.Abs(net-0.3) > 1e-9 {
+		t.Errorf("net exposure = %.2f, want 0.30", net)
+	}
+
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 0.1, 0, 0); err != nil {
+		t.Errorf("ExecuteMarketOrder() reducing exposure error = %v", err)
+	}
+	if _, err := engine.ExecuteMarketOrder(account.ID, "GBPUSD", "BUY", 1, 0, 0); err != nil {
+		t.Errorf("ExecuteMarketOrder() on an uncapped symbol error = %v", err)
+	}
+}
+
+// TestExposureLimitReroutesToABook tests that with the A_BOOK action the breaching
+// order still fills but is sent to the LP and kept out of B-Book exposure
+func TestExposureLimitReroutesToABook(t *testing.T) {
+	engine, account := newTestEngine(t)
+	engine.SetExposureLimits(ExposureLimits{Aggregate: 0.25, Action: ExposureActionABook})
+
+	var lpVolume float64
+	engine.SetExposureRerouteCallback(func(accountID int64, symbol, side string, volume float64) (string, error) {
+		lpVolume = volume
+		return "LP-1", nil
+	})
+
+	openTestPosition(t, engine, account.ID, "EURUSD")
+	openTestPosition(t, engine, account.ID, "GBPUSD")
+	if decision := engine.CheckExposure("EURUSD", "BUY", 0.1); decision.Allowed || decision.Action != ExposureActionABook || math.Abs(decision.AggregateNet-0.3) > 1e-9 {
+		t.Errorf("CheckExposure() = %+v, want an A-Book flip at 0.30 aggregate lots", decision)
+	}
+
+	pos := openTestPosition(t, engine, account.ID, "EURUSD")
+	if pos.Book != ExposureActionABook || lpVolume != pos.Volume {
+		t.Errorf("position book = %q, LP volume %.2f; want A_BOOK with %.2f lots at the LP", pos.Book, lpVolume, pos.Volume)
+	}
+	if net := engine.GetNetExposure()["EURUSD"]; math.Abs(net-0.1) > 1e-9 {
+		t.Errorf("EURUSD B-Book exposure = %.2f, want 0.10", net)
+	}
+
+	if pos.LPOrderID != "LP-1" {
+		t.Errorf("position LP order = %q, want LP-1", pos.LPOrderID)
+	}
+
+	// Without an LP to route to, or when the LP refuses, the breach is rejected
+	// and nothing is booked
+	engine.SetExposureRerouteCallback(nil)
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0); !errors.Is(err, ErrExposureLimit) {
+		t.Errorf("ExecuteMarketOrder() error = %v, want ErrExposureLimit", err)
+	}
+	lpOrders := make(chan string, 1)
+	engine.SetExposureRerouteCallback(func(accountID int64, symbol, side string, volume float64) (string, error) {
+		lpOrders <- side
+		return "", errors.New("session down")
+	})
+	positions := len(engine.GetPositions(account.ID))
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0); !errors.Is(err, ErrExposureLimit) {
+		t.Errorf("ExecuteMarketOrder() with the LP down error = %v, want ErrExposureLimit", err)
+	}
+	<-lpOrders
+	if got := len(engine.GetPositions(account.ID)); got != positions {
+		t.Errorf("%d positions after the failed route, want %d", got, positions)
+	}
+
+	// Closing the client position closes its LP order
+	engine.SetExposureRerouteCallback(func(accountID int64, symbol, side string, volume float64) (string, error) {
+		lpOrders <- side
+		return "LP-2", nil
+	})
+	if _, err := engine.ClosePosition(pos.ID, 0); err != nil {
+		t.Fatalf("ClosePosition() error = %v", err)
+	}
+	select {
+	case side := <-lpOrders:
+		if side != "SELL" {
+			t.Errorf("LP close side = %s, want SELL", side)
+		}
+	case <-time.After(time.Second):
+		t.Error("closing the A-Book position sent nothing to the LP")
+	}
+}
+
+// TestSetExposureLimitsValidates tests that negative limits and unknown actions are rejected
+func TestSetExposureLimitsValidates(t *testing.T) {
+	engine := NewEngine()
+	if err := engine.SetExposureLimits(ExposureLimits{Aggregate: -1}); err == nil {
+		t.Error("SetExposureLimits() accepted a negative aggregate limit")
+	}
+	if err := engine.SetExposureLimits(ExposureLimits{Action: "HEDGE"}); err == nil {
+		t.Error("SetExposureLimits() accepted an unknown action")
+	}
+}
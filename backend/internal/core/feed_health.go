@@ -0,0 +1,25 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoMarketData is returned when a market order arrives while no fresh quotes
+// are flowing from any feed
+var ErrNoMarketData = errors.New("NO_MARKET_DATA")
+
+// SetFeedHealthCallback sets the function reporting whether any market data
+// source is delivering fresh quotes. Market orders are rejected while it
+// reports false, so fills never use prices left over from before an outage.
+func (e *Engine) SetFeedHealthCallback(fn func() bool) {
+	e.feedHealthCallback = fn
+}
+
+// checkFeedHealth rejects fills during a total market data outage
+func (e *Engine) checkFeedHealth() error {
+	if e.feedHealthCallback != nil && !e.feedHealthCallback() {
+		return fmt.Errorf("%w: no fresh quotes from any feed", ErrNoMarketData)
+	}
+	return nil
+}
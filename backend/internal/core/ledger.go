@@ -11,7 +11,7 @@ import (
 type LedgerEntry struct {
 	ID            int64     `json:"id"`
 	AccountID     int64     `json:"accountId"`
-	Type          string    `json:"type"` // DEPOSIT/WITHDRAW/REALIZED_PNL/COMMISSION/SWAP/ADJUSTMENT/BONUS
+	Type          string    `json:"type"` // DEPOSIT/WITHDRAW/REALIZED_PNL/COMMISSION/SWAP/SWAP_FREE_FEE/ADJUSTMENT/BONUS
 	Amount        float64   `json:"amount"`
 	BalanceAfter  float64   `json:"balanceAfter"`
 	Currency      string    `json:"currency"`
@@ -31,6 +31,8 @@ type Ledger struct {
 	entries  map[int64][]LedgerEntry // accountID -> entries
 	nextID   int64
 	balances map[int64]float64 // accountID -> balance cache
+
+	entryCallback func(LedgerEntry) // Write-through of every new entry
 }
 
 // NewLedger creates a new ledger
@@ -72,7 +74,7 @@ func (l *Ledger) Deposit(accountID int64, amount float64, method, ref, descripti
 	}
 	l.nextID++
 
-	l.entries[accountID] = append(l.entries[accountID], entry)
+	l.appendUnlocked(entry)
 
 	log.Printf("[Ledger] DEPOSIT: Account #%d +%.2f via %s | Balance: %.2f", accountID, amount, method, newBalance)
 	return &entry, nil
@@ -112,7 +114,7 @@ func (l *Ledger) Withdraw(accountID int64, amount float64, method, ref, descript
 	}
 	l.nextID++
 
-	l.entries[accountID] = append(l.entries[accountID], entry)
+	l.appendUnlocked(entry)
 
 	log.Printf("[Ledger] WITHDRAW: Account #%d -%.2f via %s | Balance: %.2f", accountID, amount, method, newBalance)
 	return &entry, nil
@@ -148,7 +150,7 @@ func (l *Ledger) Adjust(accountID int64, amount float64, description, adminID st
 	}
 	l.nextID++
 
-	l.entries[accountID] = append(l.entries[accountID], entry)
+	l.appendUnlocked(entry)
 
 	log.Printf("[Ledger] ADJUSTMENT: Account #%d %+.2f | Balance: %.2f", accountID, amount, newBalance)
 	return &entry, nil
@@ -185,7 +187,7 @@ func (l *Ledger) RecordRealizedPnL(accountID int64, amount float64, tradeID int6
 	}
 	l.nextID++
 
-	l.entries[accountID] = append(l.entries[accountID], entry)
+	l.appendUnlocked(entry)
 	return &entry
 }
 
@@ -213,7 +215,7 @@ func (l *Ledger) RecordCommission(accountID int64, amount float64, tradeID int64
 	}
 	l.nextID++
 
-	l.entries[accountID] = append(l.entries[accountID], entry)
+	l.appendUnlocked(entry)
 	return &entry
 }
 
@@ -241,7 +243,35 @@ func (l *Ledger) RecordSwap(accountID int64, amount float64, positionID int64) *
 	}
 	l.nextID++
 
-	l.entries[accountID] = append(l.entries[accountID], entry)
+	l.appendUnlocked(entry)
+	return &entry
+}
+
+// RecordSwapFreeFee records the admin fee charged to a swap-free account in place of swap
+func (l *Ledger) RecordSwapFreeFee(accountID int64, amount float64, positionID int64) *LedgerEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	currentBalance := l.balances[accountID]
+	newBalance := currentBalance + amount
+	l.balances[accountID] = newBalance
+
+	entry := LedgerEntry{
+		ID:           l.nextID,
+		AccountID:    accountID,
+		Type:         "SWAP_FREE_FEE",
+		Amount:       amount,
+		BalanceAfter: newBalance,
+		Currency:     "USD",
+		Description:  "Swap-Free Admin Fee",
+		RefType:      "POSITION",
+		RefID:        positionID,
+		Status:       "COMPLETED",
+		CreatedAt:    time.Now(),
+	}
+	l.nextID++
+
+	l.appendUnlocked(entry)
 	return &entry
 }
 
@@ -274,12 +304,48 @@ func (l *Ledger) AddBonus(accountID int64, amount float64, description, adminID
 	}
 	l.nextID++
 
-	l.entries[accountID] = append(l.entries[accountID], entry)
+	l.appendUnlocked(entry)
 
 	log.Printf("[Ledger] BONUS: Account #%d +%.2f | Balance: %.2f", accountID, amount, newBalance)
 	return &entry, nil
 }
 
+// SetEntryCallback sets a function called with every new entry while the
+// ledger lock is held; it must not call back into the ledger
+func (l *Ledger) SetEntryCallback(fn func(LedgerEntry)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entryCallback = fn
+}
+
+// appendUnlocked records an entry and hands it to the entry callback (caller must hold lock)
+func (l *Ledger) appendUnlocked(entry LedgerEntry) {
+	l.entries[entry.AccountID] = append(l.entries[entry.AccountID], entry)
+	if l.entryCallback != nil {
+		l.entryCallback(entry)
+	}
+}
+
+// Restore replaces the ledger with persisted entries, oldest first, and the
+// balance of each account
+func (l *Ledger) Restore(entries []LedgerEntry, balances map[int64]float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = make(map[int64][]LedgerEntry)
+	l.balances = make(map[int64]float64, len(balances))
+	l.nextID = 1
+	for _, entry := range entries {
+		l.entries[entry.AccountID] = append(l.entries[entry.AccountID], entry)
+		if entry.ID >= l.nextID {
+			l.nextID = entry.ID + 1
+		}
+	}
+	for accountID, balance := range balances {
+		l.balances[accountID] = balance
+	}
+}
+
 // GetHistory returns ledger history for an account
 func (l *Ledger) GetHistory(accountID int64, limit int) []LedgerEntry {
 	l.mu.RLock()
@@ -298,6 +364,24 @@ func (l *Ledger) GetHistory(accountID int64, limit int) []LedgerEntry {
 	return result
 }
 
+// RealizedPnLSince sums an account's realized P/L from trades closed at or after since
+func (l *Ledger) RealizedPnLSince(accountID int64, since time.Time) float64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	total := 0.0
+	entries := l.entries[accountID]
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].CreatedAt.Before(since) {
+			break
+		}
+		if entries[i].Type == "REALIZED_PNL" {
+			total += entries[i].Amount
+		}
+	}
+	return total
+}
+
 // GetBalance returns the cached balance
 func (l *Ledger) GetBalance(accountID int64) float64 {
 	l.mu.RLock()
@@ -0,0 +1,233 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// Margin event types passed to the margin event callback
+const (
+	MarginEventCall    = "MARGIN_CALL"
+	MarginEventStopOut = "STOP_OUT"
+)
+
+// Default margin levels, in percent of used margin
+const (
+	DefaultMarginCallLevel = 100.0
+	DefaultStopOutLevel    = 50.0
+)
+
+// MarginLevels are the margin levels (equity / used margin * 100) below which an
+// account gets a margin call and below which its positions are stopped out.
+// 0 disables the respective check.
+type MarginLevels struct {
+	MarginCall float64 `json:"marginCallLevel"`
+	StopOut    float64 `json:"stopOutLevel"`
+}
+
+// Validate rejects negative levels and a stop-out above the margin call level
+func (l MarginLevels) Validate() error {
+	if l.MarginCall < 0 || l.StopOut < 0 {
+		return fmt.Errorf("margin levels must not be negative")
+	}
+	if l.MarginCall > 0 && l.StopOut > l.MarginCall {
+		return fmt.Errorf("stop-out level %.2f%% is above the margin call level %.2f%%", l.StopOut, l.MarginCall)
+	}
+	return nil
+}
+
+// MarginLevelsResolver returns the margin levels of an account's group, or false
+// to use the engine default
+type MarginLevelsResolver func(accountID int64) (MarginLevels, bool)
+
+// MarginEvent reports a margin call, or a position force closed by a stop-out
+type MarginEvent struct {
+	Type        string    `json:"type"`
+	AccountID   int64     `json:"accountId"`
+	MarginLevel float64   `json:"marginLevel"` // Percent, when the event fired
+	Threshold   float64   `json:"threshold"`   // Level that was breached
+	Equity      float64   `json:"equity"`
+	Margin      float64   `json:"margin"`
+	Trade       *Trade    `json:"trade,omitempty"` // Stop-outs: the forced close
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// SetMarginLevels sets the default margin call and stop-out levels
+func (e *Engine) SetMarginLevels(levels MarginLevels) error {
+	if err := levels.Validate(); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.marginLevels = levels
+	return nil
+}
+
+// GetMarginLevels returns the default margin call and stop-out levels
+func (e *Engine) GetMarginLevels() MarginLevels {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.marginLevels
+}
+
+// SetMarginLevelsResolver sets the lookup for per-group margin level overrides
+func (e *Engine) SetMarginLevelsResolver(fn MarginLevelsResolver) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.marginLevelsResolver = fn
+}
+
+// SetMarginEventCallback sets the function notified of margin calls and stop-out closes
+func (e *Engine) SetMarginEventCallback(fn func(MarginEvent)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.marginEventCallback = fn
+}
+
+// CheckMarginLevels enforces margin levels on every account with open positions
+func (e *Engine) CheckMarginLevels() {
+	e.mu.Lock()
+	accountIDs := make(map[int64]bool)
+	for _, pos := range e.positions {
+		if pos.Status == "OPEN" {
+			accountIDs[pos.AccountID] = true
+		}
+	}
+	events := e.checkMarginLevelsUnlocked(accountIDs)
+	e.mu.Unlock()
+
+	e.dispatchMarginEvents(events)
+}
+
+// marginLevelsForUnlocked resolves the margin levels of an account (caller must hold lock)
+func (e *Engine) marginLevelsForUnlocked(accountID int64) MarginLevels {
+	if e.marginLevelsResolver != nil {
+		if levels, ok := e.marginLevelsResolver(accountID); ok {
+			return levels
+		}
+	}
+	return e.marginLevels
+}
+
+// checkMarginLevelsUnlocked raises a margin call once per breach and, below the
+// stop-out level, closes the worst losing position until the margin level is
+// restored or nothing more can be closed (caller must hold lock)
+func (e *Engine) checkMarginLevelsUnlocked(accountIDs map[int64]bool) []MarginEvent {
+	var events []MarginEvent
+	for accountID := range accountIDs {
+		summary, err := e.getAccountSummaryUnlocked(accountID)
+		if err != nil || summary.Margin <= 0 {
+			delete(e.marginCalled, accountID)
+			continue
+		}
+		levels := e.marginLevelsForUnlocked(accountID)
+		level := summary.Equity / summary.Margin * 100
+
+		if levels.MarginCall > 0 && level < levels.MarginCall {
+			if !e.marginCalled[accountID] {
+				e.marginCalled[accountID] = true
+				log.Printf("[B-Book] Margin call on account #%d: margin level %.2f%% below %.2f%%", accountID, level, levels.MarginCall)
+				events = append(events, MarginEvent{
+					Type:        MarginEventCall,
+					AccountID:   accountID,
+					MarginLevel: level,
+					Threshold:   levels.MarginCall,
+					Equity:      summary.Equity,
+					Margin:      summary.Margin,
+					Timestamp:   time.Now(),
+				})
+			}
+		} else {
+			delete(e.marginCalled, accountID)
+		}
+
+		if levels.StopOut <= 0 || level >= levels.StopOut {
+			continue
+		}
+
+		skipped := make(map[int64]bool) // Positions that cannot be closed right now
+		for level < levels.StopOut {
+			worst := e.worstPositionUnlocked(accountID, skipped)
+			if worst == nil {
+				log.Printf("[B-Book] Stop-out on account #%d left margin level at %.2f%%: no closable positions", accountID, level)
+				break
+			}
+			if err := e.checkCanModify(worst.Symbol); err != nil {
+				skipped[worst.ID] = true
+				continue
+			}
+			trade, err := e.closePositionUnlocked(worst, 0)
+			if err != nil {
+				log.Printf("[B-Book] Stop-out close of position #%d failed: %v", worst.ID, err)
+				skipped[worst.ID] = true
+				continue
+			}
+			log.Printf("[B-Book] Stop-out on account #%d: closed position #%d at margin level %.2f%%", accountID, worst.ID, level)
+			events = append(events, MarginEvent{
+				Type:        MarginEventStopOut,
+				AccountID:   accountID,
+				MarginLevel: level,
+				Threshold:   levels.StopOut,
+				Equity:      summary.Equity,
+				Margin:      summary.Margin,
+				Trade:       trade,
+				Timestamp:   trade.ExecutedAt,
+			})
+
+			summary, _ = e.getAccountSummaryUnlocked(accountID)
+			if summary.Margin <= 0 {
+				break
+			}
+			level = summary.Equity / summary.Margin * 100
+		}
+		if summary.Margin <= 0 || levels.MarginCall <= 0 || level >= levels.MarginCall {
+			delete(e.marginCalled, accountID)
+		}
+	}
+	return events
+}
+
+// worstPositionUnlocked returns the open position of an account with the lowest
+// floating P/L, ignoring skipped ones (caller must hold lock)
+func (e *Engine) worstPositionUnlocked(accountID int64, skipped map[int64]bool) *Position {
+	var worst *Position
+	for _, pos := range e.positions {
+		if pos.AccountID != accountID || pos.Status != "OPEN" || skipped[pos.ID] {
+			continue
+		}
+		if worst == nil || pos.UnrealizedPnL < worst.UnrealizedPnL ||
+			(pos.UnrealizedPnL == worst.UnrealizedPnL && pos.ID < worst.ID) {
+			worst = pos
+		}
+	}
+	return worst
+}
+
+// dispatchMarginEvents counts margin calls and stop-outs toward the risk totals,
+// starts the stop-out cooldown and notifies the callback. Must be called without
+// the lock held.
+func (e *Engine) dispatchMarginEvents(events []MarginEvent) {
+	if len(events) == 0 {
+		return
+	}
+	e.mu.RLock()
+	callback := e.marginEventCallback
+	e.mu.RUnlock()
+
+	stoppedOut := make(map[int64]bool)
+	for _, event := range events {
+		switch event.Type {
+		case MarginEventCall:
+			e.RecordMarginCall(event.AccountID)
+		case MarginEventStopOut:
+			if !stoppedOut[event.AccountID] {
+				stoppedOut[event.AccountID] = true
+				e.RecordStopOut(event.AccountID)
+			}
+		}
+		if callback != nil {
+			callback(event)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package core
+
+import "testing"
+
+// TestMarginCallThenStopOut tests that a losing position dragging equity down
+// raises one margin call, then stops out the worst position first and stops
+// closing once the margin level is restored
+func TestMarginCallThenStopOut(t *testing.T) {
+	engine, account := newTestEngine(t)
+	account.Balance = 300
+
+	eurusd := openTestPosition(t, engine, account.ID, "EURUSD")
+	gbpusd := openTestPosition(t, engine, account.ID, "GBPUSD")
+
+	var events []MarginEvent
+	engine.SetMarginEventCallback(func(event MarginEvent) {
+		events = append(events, event)
+	})
+
+	// Used margin is ~235, so equity below ~235 is a margin call and below ~117 a stop-out
+	engine.UpdatePrice("EURUSD", 1.0920, 1.0922) // -80 pips, equity ~220
+	if len(events) != 1 || events[0].Type != MarginEventCall || events[0].Threshold != DefaultMarginCallLevel {
+		t.Fatalf("events = %+v, want one margin call", events)
+	}
+	engine.UpdatePrice("EURUSD", 1.0915, 1.0917)
+	if len(events) != 1 {
+		t.Fatalf("events = %+v, want the margin call raised only once per breach", events)
+	}
+	if calls, stopOuts := engine.RiskEventsSince(eurusd.OpenTime); calls != 1 || stopOuts != 0 {
+		t.Errorf("RiskEventsSince() = %d calls, %d stop-outs; want 1, 0", calls, stopOuts)
+	}
+
+	engine.UpdatePrice("EURUSD", 1.0800, 1.0802) // -200 pips, equity ~100
+	if len(events) != 2 || events[1].Type != MarginEventStopOut {
+		t.Fatalf("events = %+v, want a stop-out after the margin call", events)
+	}
+	stopOut := events[1]
+	if stopOut.Trade == nil || stopOut.Trade.PositionID != eurusd.ID || stopOut.MarginLevel >= DefaultStopOutLevel {
+		t.Errorf("stop-out = %+v, want the losing EURUSD position closed below %.0f%%", stopOut, DefaultStopOutLevel)
+	}
+
+	if eurusd.Status != "CLOSED" {
+		t.Errorf("EURUSD position status = %s, want CLOSED", eurusd.Status)
+	}
+	if gbpusd.Status != "OPEN" {
+		t.Errorf("GBPUSD position status = %s, want OPEN once the margin level is restored", gbpusd.Status)
+	}
+	if _, stopOuts := engine.RiskEventsSince(eurusd.OpenTime); stopOuts != 1 {
+		t.Errorf("stop-outs = %d, want 1", stopOuts)
+	}
+
+	entries := engine.GetLedger().GetHistory(account.ID, 1)
+	if len(entries) != 1 || entries[0].RefID != stopOut.Trade.ID || entries[0].Amount != stopOut.Trade.RealizedPnL {
+		t.Errorf("ledger = %+v, want the stop-out loss of %.2f recorded", entries, stopOut.Trade.RealizedPnL)
+	}
+}
+
+// TestMarginLevelsResolverOverridesDefault tests that a group's levels replace the
+// engine default and that a zero stop-out level never force-closes
+func TestMarginLevelsResolverOverridesDefault(t *testing.T) {
+	engine, account := newTestEngine(t)
+	account.Balance = 300
+	pos := openTestPosition(t, engine, account.ID, "EURUSD")
+
+	engine.SetMarginLevelsResolver(func(accountID int64) (MarginLevels, bool) {
+		return MarginLevels{MarginCall: 200}, accountID == account.ID
+	})
+	var events []MarginEvent
+	engine.SetMarginEventCallback(func(event MarginEvent) {
+		events = append(events, event)
+	})
+
+	engine.UpdatePrice("EURUSD", 1.0900, 1.0902) // Equity ~200 on ~110 margin: 181%
+	if len(events) != 1 || events[0].Type != MarginEventCall || events[0].Threshold != 200 {
+		t.Fatalf("events = %+v, want a margin call at the group's 200%%", events)
+	}
+
+	engine.UpdatePrice("EURUSD", 1.0750, 1.0752) // Equity ~50: far below the default stop-out
+	if len(events) != 1 || pos.Status != "OPEN" {
+		t.Errorf("events = %+v, position %s; want no stop-out with the group's level disabled", events, pos.Status)
+	}
+}
+
+// TestSetMarginLevelsValidates tests that a stop-out above the margin call level is rejected
+func TestSetMarginLevelsValidates(t *testing.T) {
+	engine := NewEngine()
+	if err := engine.SetMarginLevels(MarginLevels{MarginCall: 50, StopOut: 80}); err == nil {
+		t.Error("SetMarginLevels() accepted a stop-out above the margin call level")
+	}
+	if err := engine.SetMarginLevels(MarginLevels{MarginCall: 120, StopOut: 30}); err != nil {
+		t.Fatalf("SetMarginLevels() error = %v", err)
+	}
+	if got := engine.GetMarginLevels(); got.MarginCall != 120 || got.StopOut != 30 {
+		t.Errorf("GetMarginLevels() = %+v, want 120/30", got)
+	}
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+)
+
+// Account position modes
+const (
+	MarginModeHedging = "HEDGING" // Opposite orders open alongside existing positions
+	MarginModeNetting = "NETTING" // One position per symbol: opposite orders reduce it
+)
+
+// NormalizeMarginMode validates a margin mode name. Empty selects hedging.
+func NormalizeMarginMode(mode string) (string, error) {
+	switch strings.ToUpper(mode) {
+	case "", MarginModeHedging:
+		return MarginModeHedging, nil
+	case MarginModeNetting:
+		return MarginModeNetting, nil
+	default:
+		return "", fmt.Errorf("invalid margin mode %q: must be %s or %s", mode, MarginModeHedging, MarginModeNetting)
+	}
+}
+
+// MarginModeResolver returns the margin mode of an account's group, or false
+// when the account's own mode applies
+type MarginModeResolver func(accountID int64) (string, bool)
+
+// SetMarginModeResolver sets the lookup for group margin modes
+func (e *Engine) SetMarginModeResolver(fn MarginModeResolver) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.marginModeResolver = fn
+}
+
+// marginModeUnlocked returns the effective margin mode of an account: its
+// group's, else its own (caller must hold lock)
+func (e *Engine) marginModeUnlocked(account *Account) string {
+	if e.marginModeResolver != nil {
+		if mode, ok := e.marginModeResolver(account.ID); ok {
+			if normalized, err := NormalizeMarginMode(mode); err == nil {
+				return normalized
+			}
+		}
+	}
+	normalized, err := NormalizeMarginMode(account.MarginMode)
+	if err != nil {
+		return MarginModeHedging
+	}
+	return normalized
+}
+
+// averageIntoUnlocked adds a fill to a netting account's open position on
+// symbol and side, moving its entry to the volume-weighted average price.
+// Non-zero SL/TP replace the position's. Returns nil when there is no
+// position to add to (caller must hold lock).
+func (e *Engine) averageIntoUnlocked(accountID int64, symbol, side string, volume, fillPrice, sl, tp, commission float64) *Position {
+	lots := e.lotsInCloseOrderUnlocked(accountID, symbol, side)
+	if len(lots) == 0 {
+		return nil
+	}
+	position := lots[0]
+	if position.Book != "" {
+		return nil
+	}
+
+	total := position.Volume + volume
+	previous := position.OpenPrice
+	position.OpenPrice = (position.OpenPrice*position.Volume + fillPrice*volume) / total
+	position.Volume = math.Round(total*1e8) / 1e8
+	position.Commission += commission
+	position.openCommission += commission
+	if sl != 0 {
+		position.SL = sl
+	}
+	if tp != 0 {
+		position.TP = tp
+	}
+
+	// Unrealized P/L against the new average entry
+	if spec, ok := e.symbols[symbol]; ok && position.CurrentPrice > 0 {
+		position.UnrealizedPnL = e.calculatePnL(position, position.CurrentPrice, position.Volume, spec)
+	}
+
+	log.Printf("[B-Book] Position #%d averaged: +%.2f lots @ %.5f, entry %.5f -> %.5f, %.2f lots",
+		position.ID, volume, fillPrice, previous, position.OpenPrice, position.Volume)
+	return position
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// openCloseSequence buys 0.2 lots of EURUSD then sells 0.1
+func openCloseSequence(t *testing.T, engine *Engine, accountID int64) {
+	t.Helper()
+	if _, err := engine.ExecuteMarketOrder(accountID, "EURUSD", "BUY", 0.2, 0, 0); err != nil {
+		t.Fatalf("BUY error = %v", err)
+	}
+	if _, err := engine.ExecuteMarketOrder(accountID, "EURUSD", "SELL", 0.1, 0, 0); err != nil {
+		t.Fatalf("SELL error = %v", err)
+	}
+}
+
+// TestNettingModeReducesPosition tests that an opposite order reduces a netting
+// account's position instead of opening another
+func TestNettingModeReducesPosition(t *testing.T) {
+	engine, account := newTestEngine(t)
+	if err := engine.UpdateAccount(account.ID, 0, "netting"); err != nil {
+		t.Fatalf("UpdateAccount() error = %v", err)
+	}
+
+	openCloseSequence(t, engine, account.ID)
+
+	positions := engine.GetPositions(account.ID)
+	if len(positions) != 1 {
+		t.Fatalf("open positions = %d, want 1 net position", len(positions))
+	}
+	if pos := positions[0]; pos.Side != "BUY" || math.Abs(pos.Volume-0.1) > 1e-9 {
+		t.Errorf("net position = %s %.2f, want BUY 0.10", pos.Side, pos.Volume)
+	}
+
+	// Selling the rest flattens the account
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 0.1, 0, 0); err != nil {
+		t.Fatalf("SELL error = %v", err)
+	}
+	if n := len(engine.GetPositions(account.ID)); n != 0 {
+		t.Errorf("open positions = %d after netting to flat, want 0", n)
+	}
+}
+
+// TestHedgingModeKeepsBothPositions tests that a hedging account holds the
+// opposite positions side by side
+func TestHedgingModeKeepsBothPositions(t *testing.T) {
+	engine, account := newTestEngine(t)
+
+	openCloseSequence(t, engine, account.ID)
+
+	positions := engine.GetPositions(account.ID)
+	if len(positions) != 2 {
+		t.Fatalf("open positions = %d, want 2 in hedging mode", len(positions))
+	}
+	sides := map[string]bool{}
+	for _, pos := range positions {
+		sides[pos.Side] = true
+	}
+	if !sides["BUY"] || !sides["SELL"] {
+		t.Errorf("positions = %v, want a BUY and a SELL", sides)
+	}
+}
+
+// TestGroupMarginModeOverridesAccount tests that the group's mode applies and
+// is reported on the account summary
+func TestGroupMarginModeOverridesAccount(t *testing.T) {
+	engine, account := newTestEngine(t)
+	engine.SetMarginModeResolver(func(accountID int64) (string, bool) {
+		return MarginModeNetting, accountID == account.ID
+	})
+
+	summary, err := engine.GetAccountSummary(account.ID)
+	if err != nil {
+		t.Fatalf("GetAccountSummary() error = %v", err)
+	}
+	if summary.MarginMode != MarginModeNetting {
+		t.Errorf("summary margin mode = %s, want the group's NETTING", summary.MarginMode)
+	}
+
+	openCloseSequence(t, engine, account.ID)
+	if n := len(engine.GetPositions(account.ID)); n != 1 {
+		t.Errorf("open positions = %d, want 1 under the group's netting mode", n)
+	}
+}
+
+// TestNettingChecksBeforeReducing tests that a netting order whose remainder
+// fails its checks reduces nothing, and that a pure reduction passes a
+// stop-out cooldown
+func TestNettingChecksBeforeReducing(t *testing.T) {
+	engine, account := newTestEngine(t)
+	engine.UpdateAccount(account.ID, 0, MarginModeNetting)
+	long := openTestPosition(t, engine, account.ID, "EURUSD")
+
+	// The 49.9 lots left after netting need far more margin than the account has
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 50, 0, 0); err == nil {
+		t.Fatal("ExecuteMarketOrder() accepted a remainder beyond the free margin")
+	}
+	if long.Status != "OPEN" || math.Abs(long.Volume-0.1) > 1e-9 {
+		t.Errorf("long = %s %.2f after the rejected order, want it untouched", long.Status, long.Volume)
+	}
+
+	engine.SetStopOutCooldown(time.Hour)
+	engine.RecordStopOut(account.ID)
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 0.2, 0, 0); !errors.Is(err, ErrStopOutCooldown) {
+		t.Errorf("netting order opening a short during the cooldown error = %v, want ErrStopOutCooldown", err)
+	}
+	if long.Status != "OPEN" {
+		t.Error("a rejected netting order reduced the long")
+	}
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 0.1, 0, 0); err != nil {
+		t.Errorf("pure reduction during the cooldown error = %v", err)
+	}
+	if long.Status != "CLOSED" {
+		t.Errorf("long status = %s, want CLOSED", long.Status)
+	}
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+)
+
+// Opposite signal policies: what a market order does to an account's open
+// positions on the other side of the same symbol. Independent of MarginMode, so
+// copy and signal accounts can flip while others hedge.
+const (
+	OppositeSignalHedge   = "HEDGE"   // Open alongside the existing positions (default)
+	OppositeSignalClose   = "CLOSE"   // Close the opposite positions instead of opening
+	OppositeSignalReverse = "REVERSE" // Close the opposite positions, then open the new one
+)
+
+// NormalizeOppositeSignal validates an opposite signal policy name. Empty selects hedging.
+func NormalizeOppositeSignal(policy string) (string, error) {
+	switch strings.ToUpper(policy) {
+	case "", OppositeSignalHedge:
+		return OppositeSignalHedge, nil
+	case OppositeSignalClose:
+		return OppositeSignalClose, nil
+	case OppositeSignalReverse:
+		return OppositeSignalReverse, nil
+	default:
+		return "", fmt.Errorf("invalid opposite signal policy %q: must be %s, %s or %s",
+			policy, OppositeSignalHedge, OppositeSignalClose, OppositeSignalReverse)
+	}
+}
+
+// SetOppositeSignal sets how an account's orders treat opposite open positions
+func (e *Engine) SetOppositeSignal(accountID int64, policy string) error {
+	normalized, err := NormalizeOppositeSignal(policy)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	account, ok := e.accounts[accountID]
+	if !ok {
+		return errors.New("account not found")
+	}
+
+	account.OppositeSignal = normalized
+	e.persistAccountUnlocked(account)
+	log.Printf("[B-Book] Account %s opposite signal policy=%s", account.AccountNumber, normalized)
+	return nil
+}
+
+// oppositeClose is a planned close of an opposite position
+type oppositeClose struct {
+	lot    *Position
+	volume float64
+}
+
+// oppositePlan is what an order does to the account's positions on the other
+// side of its symbol
+type oppositePlan struct {
+	closes  []oppositeClose
+	open    float64 // Lots the order opens once the closes are made
+	netting bool
+}
+
+// planOppositeUnlocked plans the closes an order makes on the account's
+// opposite positions, without changing anything. Positions are closed in close
+// policy order and never beyond the order's volume. A netting account opens
+// only the volume left over; otherwise the opposite signal policy applies:
+// CLOSE opens nothing when it has something to close, REVERSE opens the full
+// volume (caller must hold lock).
+func (e *Engine) planOppositeUnlocked(account *Account, symbol, side string, volume float64) oppositePlan {
+	plan := oppositePlan{open: volume, netting: e.marginModeUnlocked(account) == MarginModeNetting}
+	if !plan.netting && account.OppositeSignal != OppositeSignalClose && account.OppositeSignal != OppositeSignalReverse {
+		return plan
+	}
+
+	remaining := volume
+	for _, lot := range e.lotsInCloseOrderUnlocked(account.ID, symbol, oppositeSide(side)) {
+		if remaining <= 1e-9 {
+			break
+		}
+		closeVolume := math.Min(lot.Volume, remaining)
+		plan.closes = append(plan.closes, oppositeClose{lot: lot, volume: closeVolume})
+		remaining = math.Round((remaining-closeVolume)*1e8) / 1e8
+	}
+	switch {
+	case plan.netting:
+		plan.open = remaining
+	case account.OppositeSignal == OppositeSignalClose && len(plan.closes) > 0:
+		plan.open = 0
+	}
+	return plan
+}
+
+// releasedMarginUnlocked returns the margin a plan's closes free (caller must
+// hold lock)
+func (e *Engine) releasedMarginUnlocked(account *Account, plan oppositePlan) float64 {
+	var released float64
+	for _, c := range plan.closes {
+		if spec, ok := e.symbols[c.lot.Symbol]; ok {
+			released += e.calculatePositionMargin(c.lot, spec, account.Leverage) * c.volume / c.lot.Volume
+		}
+	}
+	return released
+}
+
+// applyOppositeUnlocked makes a plan's closes at the order's quote, which
+// cannot fail. Returns the last position closed, nil when there was nothing
+// to close (caller must hold lock).
+func (e *Engine) applyOppositeUnlocked(account *Account, plan oppositePlan, bid, ask float64) *Position {
+	var last *Position
+	for _, c := range plan.closes {
+		closePrice := bid
+		if c.lot.Side != "BUY" {
+			closePrice = ask
+		}
+		e.closePositionAtUnlocked(c.lot, c.volume, closePrice)
+		last = c.lot
+	}
+	switch {
+	case last != nil && plan.netting:
+		log.Printf("[B-Book] %s %s netted against %d %s positions of account %s, %.2f left to open",
+			oppositeSide(last.Side), last.Symbol, len(plan.closes), last.Side, account.AccountNumber, plan.open)
+	case last != nil:
+		log.Printf("[B-Book] %s signal on %s closed %d %s positions of account %s (%s)",
+			oppositeSide(last.Side), last.Symbol, len(plan.closes), last.Side, account.AccountNumber, account.OppositeSignal)
+	}
+	return last
+}
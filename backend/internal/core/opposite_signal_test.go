@@ -0,0 +1,136 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openPositionsBySide counts an account's open positions on a symbol per side
+func openPositionsBySide(engine *Engine, accountID int64, symbol string) map[string]int {
+	sides := make(map[string]int)
+	for _, pos := range engine.GetPositions(accountID) {
+		if pos.Symbol == symbol && pos.Status == "OPEN" {
+			sides[pos.Side]++
+		}
+	}
+	return sides
+}
+
+// TestOppositeSignalReverseFlipsPosition tests that with REVERSE a sell signal
+// closes the existing long and opens a short
+func TestOppositeSignalReverseFlipsPosition(t *testing.T) {
+	engine, account := newTestEngine(t)
+	if err := engine.SetOppositeSignal(account.ID, "reverse"); err != nil {
+		t.Fatalf("SetOppositeSignal() error = %v", err)
+	}
+	long := openTestPosition(t, engine, account.ID, "EURUSD")
+
+	short, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 0.2, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+
+	if long.Status != "CLOSED" {
+		t.Errorf("long status = %s, want CLOSED", long.Status)
+	}
+	if short.Side != "SELL" || short.Status != "OPEN" || short.Volume != 0.2 {
+		t.Errorf("new position = %s %s %.2f, want an open 0.20 SELL", short.Status, short.Side, short.Volume)
+	}
+	if sides := openPositionsBySide(engine, account.ID, "EURUSD"); sides["BUY"] != 0 || sides["SELL"] != 1 {
+		t.Errorf("open positions = %v, want only the short", sides)
+	}
+}
+
+// TestOppositeSignalCloseOnly tests that with CLOSE a sell signal closes the
+// long without opening a short
+func TestOppositeSignalCloseOnly(t *testing.T) {
+	engine, account := newTestEngine(t)
+	engine.SetOppositeSignal(account.ID, OppositeSignalClose)
+	long := openTestPosition(t, engine, account.ID, "EURUSD")
+
+	pos, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 0.1, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	if pos.ID != long.ID || pos.Status != "CLOSED" {
+		t.Errorf("returned position #%d %s, want the closed long #%d", pos.ID, pos.Status, long.ID)
+	}
+	if sides := openPositionsBySide(engine, account.ID, "EURUSD"); len(sides) != 0 {
+		t.Errorf("open positions = %v, want none", sides)
+	}
+}
+
+// TestOppositeSignalDisabledHedges tests that by default an opposite order
+// opens a hedge and other symbols are never touched
+func TestOppositeSignalDisabledHedges(t *testing.T) {
+	engine, account := newTestEngine(t)
+	openTestPosition(t, engine, account.ID, "EURUSD")
+	openTestPosition(t, engine, account.ID, "GBPUSD")
+
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 0.1, 0, 0); err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	if sides := openPositionsBySide(engine, account.ID, "EURUSD"); sides["BUY"] != 1 || sides["SELL"] != 1 {
+		t.Errorf("open EURUSD positions = %v, want a hedged long and short", sides)
+	}
+
+	// Enabling the policy only affects the signal's own symbol
+	engine.SetOppositeSignal(account.ID, OppositeSignalReverse)
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 0.1, 0, 0); err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	if sides := openPositionsBySide(engine, account.ID, "GBPUSD"); sides["BUY"] != 1 {
+		t.Errorf("open GBPUSD positions = %v, want the long untouched", sides)
+	}
+	if sides := openPositionsBySide(engine, account.ID, "EURUSD"); sides["BUY"] != 0 || sides["SELL"] != 2 {
+		t.Errorf("open EURUSD positions = %v, want two shorts", sides)
+	}
+
+	if err := engine.SetOppositeSignal(account.ID, "NET"); err == nil {
+		t.Error("SetOppositeSignal() accepted an unknown policy")
+	}
+}
+
+// TestOppositeSignalChecksBeforeClosing tests that a signal closes no more
+// than its own volume, that a reverse failing its margin check closes nothing
+// and that a stop-out cooldown still lets a pure close through
+func TestOppositeSignalChecksBeforeClosing(t *testing.T) {
+	engine, account := newTestEngine(t)
+	engine.SetOppositeSignal(account.ID, OppositeSignalReverse)
+	first := openTestPosition(t, engine, account.ID, "EURUSD")
+	second := openTestPosition(t, engine, account.ID, "EURUSD")
+
+	short, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 0.1, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	if first.Status != "CLOSED" || second.Status != "OPEN" || short.Side != "SELL" {
+		t.Errorf("after a 0.10 reverse: first %s, second %s, new %s; want only the first long closed and a short opened",
+			first.Status, second.Status, short.Side)
+	}
+
+	// 50 lots need far more margin than the account has: nothing closes
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 50, 0, 0); err == nil || !strings.Contains(err.Error(), "insufficient margin") {
+		t.Fatalf("ExecuteMarketOrder() beyond the free margin error = %v, want insufficient margin", err)
+	}
+	if second.Status != "OPEN" {
+		t.Error("a rejected reverse closed the long")
+	}
+
+	engine.SetOppositeSignal(account.ID, OppositeSignalClose)
+	engine.SetStopOutCooldown(time.Hour)
+	if _, ok := engine.RecordStopOut(account.ID); !ok {
+		t.Fatal("RecordStopOut() started no cooldown")
+	}
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 0.1, 0, 0); err != nil {
+		t.Fatalf("close-only signal during the cooldown error = %v", err)
+	}
+	if second.Status != "CLOSED" {
+		t.Errorf("second long status = %s, want CLOSED", second.Status)
+	}
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 0.1, 0, 0); !errors.Is(err, ErrStopOutCooldown) {
+		t.Errorf("opening signal during the cooldown error = %v, want ErrStopOutCooldown", err)
+	}
+}
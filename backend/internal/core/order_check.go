@@ -0,0 +1,173 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Pre-trade check rejection reasons
+const (
+	CheckRejectAccount            = "ACCOUNT_INVALID"
+	CheckRejectSide               = "INVALID_SIDE"
+	CheckRejectSymbolUnknown      = "SYMBOL_UNKNOWN"
+	CheckRejectSymbolDisabled     = "SYMBOL_DISABLED"
+	CheckRejectVolume             = "INVALID_VOLUME"
+	CheckRejectMarketClosed       = "MARKET_CLOSED"
+	CheckRejectInsufficientMargin = "INSUFFICIENT_MARGIN"
+)
+
+// OrderCheck is the outcome of validating an order without placing it
+type OrderCheck struct {
+	Valid          bool    `json:"valid"`
+	Reason         string  `json:"reason,omitempty"` // One of the CheckReject* codes
+	Message        string  `json:"message,omitempty"`
+	AccountID      int64   `json:"accountId"`
+	Symbol         string  `json:"symbol"`
+	Side           string  `json:"side"`
+	Volume         float64 `json:"volume"`
+	Price          float64 `json:"price"`          // Fill price the margin is based on
+	RequiredMargin float64 `json:"requiredMargin"` // Margin the order would take
+	FreeMargin     float64 `json:"freeMargin"`     // Free margin before the order, plus what its closes release
+}
+
+// preTrade is what the pre-trade checks of an order established
+type preTrade struct {
+	account  *Account
+	spec     *SymbolSpec
+	volume   float64      // Fillable volume
+	plan     oppositePlan // Opposite positions to close and the volume to open
+	bid, ask float64      // Live quote at the account's markup
+}
+
+// preTradeUnlocked runs the pre-trade checks shared by CheckOrder and market
+// execution: the account is active, the side valid, the symbol enabled, the
+// volume within the symbol's min/max/step and fillable, opening allowed (no
+// stop-out cooldown, symbol not suspended) when the order opens anything,
+// modifying allowed when it closes anything, and a live quote. A rejection
+// carries one of the CheckReject* reasons (caller must hold write lock: the
+// cooldown check lifts an elapsed cooldown).
+func (e *Engine) preTradeUnlocked(accountID int64, symbol, side string, volume, minFillRatio float64) (*preTrade, string, error) {
+	account, ok := e.accounts[accountID]
+	if !ok {
+		return nil, CheckRejectAccount, errors.New("account not found")
+	}
+	if account.Status != "ACTIVE" {
+		return nil, CheckRejectAccount, errors.New("account is not active")
+	}
+	if side != "BUY" && side != "SELL" {
+		return nil, CheckRejectSide, errors.New("invalid side: must be BUY or SELL")
+	}
+
+	spec, ok := e.symbols[symbol]
+	if !ok {
+		return nil, CheckRejectSymbolUnknown, fmt.Errorf("symbol %s not found", symbol)
+	}
+	if spec.Disabled {
+		return nil, CheckRejectSymbolDisabled, fmt.Errorf("symbol %s is disabled", symbol)
+	}
+
+	if volume < spec.MinVolume || volume > spec.MaxVolume {
+		return nil, CheckRejectVolume, fmt.Errorf("volume must be between %.2f and %.2f", spec.MinVolume, spec.MaxVolume)
+	}
+	if spec.VolumeStep > 0 {
+		steps := volume / spec.VolumeStep
+		if math.Abs(steps-math.Round(steps)) > 1e-6 {
+			return nil, CheckRejectVolume, fmt.Errorf("volume must be a multiple of %.2f", spec.VolumeStep)
+		}
+	}
+	// Fill what the available liquidity allows, or reject below the minimum fill ratio
+	volume, err := fillableVolume(spec, volume, minFillRatio)
+	if err != nil {
+		return nil, CheckRejectVolume, err
+	}
+
+	// Netting accounts reduce their opposite position and flip-on-signal
+	// accounts close theirs instead of hedging them. The closes are only
+	// planned here and made once every check has passed.
+	plan := e.planOppositeUnlocked(account, symbol, side, volume)
+	if plan.open > 0 {
+		// Closing is still allowed during a stop-out cooldown, opening is not
+		if err := e.checkStopOutCooldownUnlocked(accountID); err != nil {
+			return nil, CheckRejectAccount, err
+		}
+		if err := e.checkCanOpen(spec); err != nil {
+			return nil, CheckRejectMarketClosed, err
+		}
+	}
+	if len(plan.closes) > 0 {
+		if err := e.checkCanModify(symbol); err != nil {
+			return nil, CheckRejectMarketClosed, err
+		}
+	}
+
+	// Missing or stale quotes leave nothing to trade at
+	if e.priceCallback == nil {
+		return nil, CheckRejectMarketClosed, errors.New("price feed not available")
+	}
+	if err := e.checkFeedHealth(); err != nil {
+		return nil, CheckRejectMarketClosed, err
+	}
+	bid, ask, ok := e.priceCallback(symbol)
+	if !ok {
+		return nil, CheckRejectMarketClosed, fmt.Errorf("no price available for %s", symbol)
+	}
+	if e.isQuoteStale(symbol) {
+		return nil, CheckRejectMarketClosed, fmt.Errorf("price for %s is stale, waiting for live quotes", symbol)
+	}
+	if err := e.checkPriceAgeUnlocked(symbol); err != nil {
+		return nil, CheckRejectMarketClosed, err
+	}
+	bid, ask = e.markQuoteUnlocked(accountID, spec, bid, ask)
+
+	return &preTrade{account: account, spec: spec, volume: volume, plan: plan, bid: bid, ask: ask}, "", nil
+}
+
+// marginUnlocked returns the margin the volume an order opens takes at
+// fillPrice, and the free margin available to it once the positions it closes
+// release theirs (caller must hold lock)
+func (e *Engine) marginUnlocked(pt *preTrade, fillPrice float64) (required, available float64) {
+	required = e.calculateMargin(pt.spec.Symbol, pt.plan.open, fillPrice, pt.account.Leverage)
+	summary, _ := e.getAccountSummaryUnlocked(pt.account.ID)
+	return required, summary.FreeMargin + e.releasedMarginUnlocked(pt.account, pt.plan)
+}
+
+// CheckOrder runs the pre-trade checks of an order without placing it, the
+// same ones a market order passes before it fills, and that the free margin
+// covers it. A zero price checks a market order at the current quote.
+func (e *Engine) CheckOrder(accountID int64, symbol, side string, volume, price float64) *OrderCheck {
+	check := &OrderCheck{AccountID: accountID, Symbol: symbol, Side: side, Volume: volume, Price: price}
+	reject := func(reason, format string, args ...interface{}) *OrderCheck {
+		check.Reason = reason
+		check.Message = fmt.Sprintf(format, args...)
+		return check
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	pt, reason, err := e.preTradeUnlocked(accountID, symbol, side, volume, 0)
+	if err != nil {
+		return reject(reason, "%v", err)
+	}
+
+	rawPrice := price
+	if rawPrice <= 0 {
+		rawPrice = pt.ask
+		if side == "SELL" {
+			rawPrice = pt.bid
+		}
+	}
+	commissionModel := e.commissionModelUnlocked(accountID, pt.spec)
+	fillPrice, _, _ := applyCommissionModel(commissionModel, pt.spec, side, rawPrice,
+		e.commissionUnlocked(accountID, pt.spec, pt.plan.open, rawPrice))
+	check.Price = fillPrice
+	check.RequiredMargin, check.FreeMargin = e.marginUnlocked(pt, fillPrice)
+	if check.FreeMargin < check.RequiredMargin {
+		return reject(CheckRejectInsufficientMargin, "insufficient margin: required %.2f, available %.2f",
+			check.RequiredMargin, check.FreeMargin)
+	}
+
+	check.Valid = true
+	return check
+}
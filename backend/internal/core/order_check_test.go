@@ -0,0 +1,77 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+// TestCheckOrderRejections tests each pre-trade check rejection reason, that
+// a checked order places nothing and that execution rejects it too
+func TestCheckOrderRejections(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(e *Engine, account *Account)
+		symbol string
+		side   string
+		volume float64
+		want   string
+	}{
+		{"inactive account", func(e *Engine, a *Account) { a.Status = "DISABLED" }, "EURUSD", "BUY", 0.1, CheckRejectAccount},
+		{"bad side", nil, "EURUSD", "HOLD", 0.1, CheckRejectSide},
+		{"unknown symbol", nil, "NOSUCH", "BUY", 0.1, CheckRejectSymbolUnknown},
+		{"disabled symbol", func(e *Engine, a *Account) { e.ToggleSymbol("EURUSD", true) }, "EURUSD", "BUY", 0.1, CheckRejectSymbolDisabled},
+		{"below min volume", nil, "EURUSD", "BUY", 0.001, CheckRejectVolume},
+		{"above max volume", nil, "EURUSD", "BUY", 101, CheckRejectVolume},
+		{"off volume step", nil, "EURUSD", "BUY", 0.015, CheckRejectVolume},
+		{"suspended symbol", func(e *Engine, a *Account) { e.SuspendSymbol("EURUSD", SuspendPolicyReadOnly) }, "EURUSD", "BUY", 0.1, CheckRejectMarketClosed},
+		{"feed down", func(e *Engine, a *Account) { e.SetFeedHealthCallback(func() bool { return false }) }, "EURUSD", "BUY", 0.1, CheckRejectMarketClosed},
+		{"stale quote", func(e *Engine, a *Account) { e.SetStaleQuoteCallback(func(string) bool { return true }) }, "EURUSD", "BUY", 0.1, CheckRejectMarketClosed},
+		{"insufficient margin", nil, "EURUSD", "BUY", 50, CheckRejectInsufficientMargin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, account := newTestEngine(t)
+			engine.GetOrCreateSymbol("EURUSD")
+			if tt.setup != nil {
+				tt.setup(engine, account)
+			}
+
+			check := engine.CheckOrder(account.ID, tt.symbol, tt.side, tt.volume, 0)
+			if check.Valid || check.Reason != tt.want {
+				t.Errorf("CheckOrder() = valid %v reason %q (%s), want reason %q", check.Valid, check.Reason, check.Message, tt.want)
+			}
+			if positions := engine.GetPositions(account.ID); len(positions) != 0 {
+				t.Errorf("got %d positions, want none", len(positions))
+			}
+			// Execution runs the same checks
+			if _, err := engine.ExecuteMarketOrder(account.ID, tt.symbol, tt.side, tt.volume, 0, 0); err == nil {
+				t.Error("ExecuteMarketOrder() filled an order CheckOrder rejected")
+			}
+		})
+	}
+}
+
+// TestCheckOrderValid tests that a passing check reports the margin the
+// order would take without placing it
+func TestCheckOrderValid(t *testing.T) {
+	engine, account := newTestEngine(t)
+	engine.GetOrCreateSymbol("EURUSD")
+
+	check := engine.CheckOrder(account.ID, "EURUSD", "BUY", 1, 0)
+	if !check.Valid {
+		t.Fatalf("CheckOrder() rejected: %s %s", check.Reason, check.Message)
+	}
+	// 1 lot x 100,000 x 1.1002 ask / 1:100
+	if math.Abs(check.RequiredMargin-1100.2) > 1e-6 || check.FreeMargin != 10000 {
+		t.Errorf("margin = %.2f of %.2f free, want 1100.20 of 10000.00", check.RequiredMargin, check.FreeMargin)
+	}
+
+	// A limit price is checked at that price
+	check = engine.CheckOrder(account.ID, "EURUSD", "SELL", 1, 1.2)
+	if !check.Valid || math.Abs(check.RequiredMargin-1200) > 1e-6 {
+		t.Errorf("limit check = valid %v margin %.2f, want valid with 1200.00", check.Valid, check.RequiredMargin)
+	}
+	if len(engine.GetPositions(account.ID)) != 0 || len(engine.GetOrders(account.ID, "")) != 0 {
+		t.Error("CheckOrder() placed an order")
+	}
+}
@@ -0,0 +1,39 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrFillBelowMinRatio is returned when the achievable fill of a market order is
+// below its minimum fill ratio, so the order is rejected instead of partially filled
+var ErrFillBelowMinRatio = errors.New("achievable fill below minimum fill ratio")
+
+// fillableVolume returns the volume a market order can fill against the
+// symbol's per-order liquidity. Short liquidity partially fills the order unless
+// the filled share would be below minFillRatio (0 accepts any partial fill).
+func fillableVolume(spec *SymbolSpec, volume, minFillRatio float64) (float64, error) {
+	if minFillRatio < 0 || minFillRatio > 1 {
+		return 0, fmt.Errorf("minimum fill ratio must be between 0 and 1, got %.2f", minFillRatio)
+	}
+	if spec.FillLiquidity <= 0 || volume <= spec.FillLiquidity {
+		return volume, nil
+	}
+
+	fill := spec.FillLiquidity
+	if spec.VolumeStep > 0 {
+		// Round down to the volume step, tolerating float noise
+		fill = math.Floor(fill/spec.VolumeStep+1e-9) * spec.VolumeStep
+	}
+
+	ratio := fill / volume
+	if minFillRatio > 0 && ratio < minFillRatio {
+		return 0, fmt.Errorf("%w: %.2f of %.2f lots available (%.0f%% < %.0f%%)",
+			ErrFillBelowMinRatio, fill, volume, ratio*100, minFillRatio*100)
+	}
+	if fill < spec.MinVolume {
+		return 0, fmt.Errorf("insufficient liquidity for %s: %.2f lots available", spec.Symbol, fill)
+	}
+	return fill, nil
+}
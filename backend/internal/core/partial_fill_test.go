@@ -0,0 +1,67 @@
+package core
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestMinFillRatioPartiallyFillsAboveRatio tests that short liquidity partially fills an order at or above its minimum ratio
+func TestMinFillRatioPartiallyFillsAboveRatio(t *testing.T) {
+	engine, account := newTestEngine(t)
+	spec := engine.GetOrCreateSymbol("EURUSD")
+	spec.FillLiquidity = 0.6
+
+	pos, err := engine.ExecuteMarketOrderMinFill(account.ID, "EURUSD", "BUY", 1.0, 0, 0, 0.5)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrderMinFill() error = %v", err)
+	}
+	if math.Abs(pos.Volume-0.6) > 1e-9 {
+		t.Errorf("position volume = %.2f, want partial fill of 0.60", pos.Volume)
+	}
+
+	orders := engine.GetOrders(account.ID, "")
+	if len(orders) != 1 {
+		t.Fatalf("got %d orders, want 1", len(orders))
+	}
+	order := orders[0]
+	if order.Status != "PARTIALLY_FILLED" || order.Volume != 1.0 || math.Abs(order.FilledVolume-0.6) > 1e-9 {
+		t.Errorf("order = %s %.2f filled %.2f, want PARTIALLY_FILLED 1.00 filled 0.60", order.Status, order.Volume, order.FilledVolume)
+	}
+
+	// Within the available liquidity the order fills in full
+	pos, err = engine.ExecuteMarketOrderMinFill(account.ID, "EURUSD", "BUY", 0.5, 0, 0, 0.9)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrderMinFill() error = %v", err)
+	}
+	if pos.Volume != 0.5 {
+		t.Errorf("position volume = %.2f, want full fill of 0.50", pos.Volume)
+	}
+}
+
+// TestMinFillRatioRejectsBelowRatio tests that an order whose achievable fill is below its ratio is rejected entirely
+func TestMinFillRatioRejectsBelowRatio(t *testing.T) {
+	engine, account := newTestEngine(t)
+	spec := engine.GetOrCreateSymbol("EURUSD")
+	spec.FillLiquidity = 0.6
+	spec.CommissionPerLot = 7
+
+	_, err := engine.ExecuteMarketOrderMinFill(account.ID, "EURUSD", "BUY", 1.0, 0, 0, 0.8)
+	if !errors.Is(err, ErrFillBelowMinRatio) {
+		t.Fatalf("ExecuteMarketOrderMinFill() error = %v, want ErrFillBelowMinRatio", err)
+	}
+
+	if positions := engine.GetPositions(account.ID); len(positions) != 0 {
+		t.Errorf("got %d positions, want none", len(positions))
+	}
+	if orders := engine.GetOrders(account.ID, ""); len(orders) != 0 {
+		t.Errorf("got %d orders, want none", len(orders))
+	}
+	if account.Balance != 10000 {
+		t.Errorf("balance = %.2f, want 10000 (no commission charged)", account.Balance)
+	}
+
+	if _, err := engine.ExecuteMarketOrderMinFill(account.ID, "EURUSD", "BUY", 1.0, 0, 0, 1.5); err == nil {
+		t.Error("ratio above 1 accepted, want error")
+	}
+}
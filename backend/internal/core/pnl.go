@@ -2,17 +2,42 @@ package core
 
 import (
 	"log"
+	"math"
 	"sync"
 	"time"
 )
 
+// DefaultEquityChangePct is how far equity must move, in percent of the last
+// pushed equity, before subscribers are sent an update
+const DefaultEquityChangePct = 0.01
+
+// Reasons an account update was pushed to subscribers
+const (
+	AccountReasonSnapshot       = "snapshot"
+	AccountReasonPositionOpened = "position_opened"
+	AccountReasonPositionClosed = "position_closed"
+	AccountReasonPositionChange = "position_changed"
+	AccountReasonBalance        = "balance"
+	AccountReasonMargin         = "margin"
+	AccountReasonEquity         = "equity"
+)
+
 // PnLEngine handles real-time P/L calculations
 type PnLEngine struct {
-	mu          sync.RWMutex
-	engine      *Engine
-	updateChan  chan AccountUpdate
-	stopChan    chan struct{}
-	subscribers map[int64][]chan AccountUpdate // accountID -> channels
+	mu              sync.RWMutex
+	engine          *Engine
+	updateChan      chan AccountUpdate
+	stopChan        chan struct{}
+	subscribers     map[int64][]*accountSubscriber // accountID -> subscribers
+	equityChangePct float64
+	closed          chan struct{} // Signalled on every position close, for an immediate push
+}
+
+// accountSubscriber is a subscriber channel and the last update it was sent,
+// the baseline for deciding whether the next calculation is worth pushing
+type accountSubscriber struct {
+	ch   chan AccountUpdate
+	last *AccountUpdate // nil until the initial snapshot is delivered
 }
 
 // AccountUpdate contains real-time account data
@@ -26,6 +51,12 @@ type AccountUpdate struct {
 	UnrealizedPnL float64          `json:"unrealizedPnL"`
 	Positions     []PositionUpdate `json:"positions,omitempty"`
 	Timestamp     time.Time        `json:"timestamp"`
+
+	// Set on updates pushed to subscribers: why it was sent, and the
+	// positions opened or closed since the previous one
+	Reason string  `json:"reason,omitempty"`
+	Opened []int64 `json:"opened,omitempty"`
+	Closed []int64 `json:"closed,omitempty"`
 }
 
 // PositionUpdate contains real-time position data
@@ -42,23 +73,40 @@ type PositionUpdate struct {
 // NewPnLEngine creates a P/L calculation engine
 func NewPnLEngine(engine *Engine) *PnLEngine {
 	pnl := &PnLEngine{
-		engine:      engine,
-		updateChan:  make(chan AccountUpdate, 100),
-		stopChan:    make(chan struct{}),
-		subscribers: make(map[int64][]chan AccountUpdate),
+		engine:          engine,
+		updateChan:      make(chan AccountUpdate, 100),
+		stopChan:        make(chan struct{}),
+		subscribers:     make(map[int64][]*accountSubscriber),
+		equityChangePct: DefaultEquityChangePct,
+		closed:          make(chan struct{}, 1),
 	}
 
+	// Server-side closes (SL, TP, stop-out) reach subscribers without waiting for the next tick
+	engine.SubscribeTradeEvents(func(event TradeEvent) {
+		if event.Type != TradeEventPositionClosed {
+			return
+		}
+		select {
+		case pnl.closed <- struct{}{}:
+		default:
+		}
+	})
+
 	go pnl.run()
 
 	log.Println("[PnL Engine] Started")
 	return pnl
 }
 
-// Subscribe adds a subscriber for account updates
+// Subscribe adds a subscriber for account updates. The first update is a
+// snapshot; after that the channel only receives updates when a position
+// opens, closes or changes, the balance or margin changes, or equity moves by
+// the equity change threshold. Updates are dropped while the channel is full
+// and the change is re-sent on the next calculation.
 func (p *PnLEngine) Subscribe(accountID int64, ch chan AccountUpdate) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	p.subscribers[accountID] = append(p.subscribers[accountID], ch)
+	p.subscribers[accountID] = append(p.subscribers[accountID], &accountSubscriber{ch: ch})
 }
 
 // Unsubscribe removes a subscriber
@@ -68,11 +116,22 @@ func (p *PnLEngine) Unsubscribe(accountID int64, ch chan AccountUpdate) {
 
 	subs := p.subscribers[accountID]
 	for i, sub := range subs {
-		if sub == ch {
+		if sub.ch == ch {
 			p.subscribers[accountID] = append(subs[:i], subs[i+1:]...)
 			break
 		}
 	}
+	if len(p.subscribers[accountID]) == 0 {
+		delete(p.subscribers, accountID)
+	}
+}
+
+// SetEquityChangeThreshold sets how far equity must move, in percent, before
+// subscribers get an update. 0 pushes every equity change.
+func (p *PnLEngine) SetEquityChangeThreshold(pct float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.equityChangePct = pct
 }
 
 // GetUpdateChannel returns the broadcast channel
@@ -91,6 +150,8 @@ func (p *PnLEngine) run() {
 			return
 		case <-ticker.C:
 			p.calculate()
+		case <-p.closed:
+			p.calculate()
 		}
 	}
 }
@@ -100,8 +161,8 @@ func (p *PnLEngine) calculate() {
 	// Update position prices from market data
 	p.engine.UpdatePositionPrices()
 
-	p.mu.RLock()
-	defer p.mu.RUnlock()
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
 	// Get all accounts with open positions
 	accountIDs := make(map[int64]bool)
@@ -154,14 +215,74 @@ func (p *PnLEngine) calculate() {
 		default:
 		}
 
-		// Send to specific subscribers
-		for _, ch := range p.subscribers[accountID] {
-			select {
-			case ch <- update:
-			default:
-			}
+		// Send to specific subscribers when something changed for them
+		for _, sub := range p.subscribers[accountID] {
+			p.publishUnlocked(sub, update)
+		}
+	}
+}
+
+// publishUnlocked sends update to sub if it differs materially from the last
+// update sub received (caller must hold lock)
+func (p *PnLEngine) publishUnlocked(sub *accountSubscriber, update AccountUpdate) {
+	if sub.last == nil {
+		update.Reason = AccountReasonSnapshot
+	} else {
+		update.Reason, update.Opened, update.Closed = accountChange(*sub.last, update, p.equityChangePct)
+		if update.Reason == "" {
+			return
 		}
 	}
+
+	select {
+	case sub.ch <- update:
+		sub.last = &update
+	default:
+		// Subscriber is behind, keep the old baseline so the change is re-sent
+	}
+}
+
+// accountChange returns why next is worth pushing after prev, or "" if nothing
+// changed materially, with the positions opened and closed in between
+func accountChange(prev, next AccountUpdate, equityChangePct float64) (reason string, opened, closed []int64) {
+	prevVolumes := make(map[int64]float64, len(prev.Positions))
+	for _, pos := range prev.Positions {
+		prevVolumes[pos.ID] = pos.Volume
+	}
+
+	resized := false
+	for _, pos := range next.Positions {
+		volume, ok := prevVolumes[pos.ID]
+		if !ok {
+			opened = append(opened, pos.ID)
+		} else if volume != pos.Volume {
+			resized = true
+		}
+		delete(prevVolumes, pos.ID)
+	}
+	for id := range prevVolumes {
+		closed = append(closed, id)
+	}
+
+	const epsilon = 1e-9
+	switch {
+	case len(opened) > 0:
+		return AccountReasonPositionOpened, opened, closed
+	case len(closed) > 0:
+		return AccountReasonPositionClosed, opened, closed
+	case resized:
+		return AccountReasonPositionChange, nil, nil
+	case math.Abs(next.Balance-prev.Balance) > epsilon:
+		return AccountReasonBalance, nil, nil
+	case math.Abs(next.Margin-prev.Margin) > epsilon:
+		return AccountReasonMargin, nil, nil
+	}
+
+	change := math.Abs(next.Equity - prev.Equity)
+	if change > epsilon && change >= math.Abs(prev.Equity)*equityChangePct/100 {
+		return AccountReasonEquity, nil, nil
+	}
+	return "", nil, nil
 }
 
 // Stop stops the P/L engine
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+// nextAccountUpdate returns the next update on ch, or fails after a second
+func nextAccountUpdate(t *testing.T, ch chan AccountUpdate) AccountUpdate {
+	t.Helper()
+	select {
+	case update := <-ch:
+		return update
+	case <-time.After(time.Second):
+		t.Fatal("no account update")
+		return AccountUpdate{}
+	}
+}
+
+// TestPnLSubscriberGetsSnapshotThenChanges tests that a subscriber receives a
+// snapshot, then only position changes and material equity moves
+func TestPnLSubscriberGetsSnapshotThenChanges(t *testing.T) {
+	engine, account := newTestEngine(t)
+	bid, ask := 1.1000, 1.1002
+	engine.SetPriceCallback(func(symbol string) (float64, float64, bool) {
+		return bid, ask, symbol == "EURUSD"
+	})
+	pnl := NewPnLEngine(engine)
+	defer pnl.Stop()
+
+	updates := make(chan AccountUpdate, 16)
+	pnl.Subscribe(account.ID, updates)
+	if got := nextAccountUpdate(t, updates); got.Reason != AccountReasonSnapshot || got.Balance != 10000 {
+		t.Fatalf("first update = %+v, want a snapshot of the 10000 balance", got)
+	}
+
+	pos := openTestPosition(t, engine, account.ID, "EURUSD")
+	got := nextAccountUpdate(t, updates)
+	if got.Reason != AccountReasonPositionOpened || len(got.Opened) != 1 || got.Opened[0] != pos.ID {
+		t.Fatalf("update after open = %+v, want position %d opened", got, pos.ID)
+	}
+
+	// Half a pip on 0.1 lots is well under 0.01% of equity
+	bid, ask = 1.10005, 1.10025
+	pnl.ForceUpdate()
+	select {
+	case update := <-updates:
+		t.Fatalf("immaterial equity move pushed %+v", update)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	bid, ask = 1.1100, 1.1102
+	if got := nextAccountUpdate(t, updates); got.Reason != AccountReasonEquity {
+		t.Errorf("update after a 100 pip move = %+v, want an equity update", got)
+	}
+
+	if _, err := engine.ClosePosition(pos.ID, 0); err != nil {
+		t.Fatalf("ClosePosition() error = %v", err)
+	}
+	got = nextAccountUpdate(t, updates)
+	if got.Reason != AccountReasonPositionClosed || len(got.Closed) != 1 || got.Closed[0] != pos.ID {
+		t.Errorf("update after close = %+v, want position %d closed", got, pos.ID)
+	}
+}
+
+// TestAccountChange tests the ordering of change reasons
+func TestAccountChange(t *testing.T) {
+	base := AccountUpdate{Balance: 1000, Equity: 1000, Positions: []PositionUpdate{{ID: 1, Volume: 1}}}
+
+	tests := []struct {
+		name string
+		next AccountUpdate
+		want string
+	}{
+		{"unchanged", base, ""},
+		{"partial close", AccountUpdate{Balance: 1000, Equity: 1000, Positions: []PositionUpdate{{ID: 1, Volume: 0.5}}}, AccountReasonPositionChange},
+		{"deposit", AccountUpdate{Balance: 1500, Equity: 1500, Positions: base.Positions}, AccountReasonBalance},
+		{"margin", AccountUpdate{Balance: 1000, Equity: 1000, Margin: 10, Positions: base.Positions}, AccountReasonMargin},
+		{"small equity move", AccountUpdate{Balance: 1000, Equity: 1000.05, Positions: base.Positions}, ""},
+		{"equity move", AccountUpdate{Balance: 1000, Equity: 1000.5, Positions: base.Positions}, AccountReasonEquity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _, _ := accountChange(base, tt.next, DefaultEquityChangePct); got != tt.want {
+				t.Errorf("accountChange() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
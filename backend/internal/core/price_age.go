@@ -0,0 +1,53 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultMaxPriceAge is the oldest quote a market order fills on unless the
+// symbol sets its own limit
+const DefaultMaxPriceAge = 3 * time.Second
+
+// SetQuoteAgeCallback sets the lookup of how old a symbol's latest live quote
+// is; ok false means no live quote has been received
+func (e *Engine) SetQuoteAgeCallback(fn func(symbol string) (age time.Duration, ok bool)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.quoteAgeCallback = fn
+}
+
+// SetMaxPriceAge sets the default oldest quote a market order fills on. 0
+// disables the guard for symbols without their own limit.
+func (e *Engine) SetMaxPriceAge(d time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.maxPriceAge = d
+}
+
+// maxPriceAgeUnlocked returns the price age limit of a symbol: its spec's,
+// else the engine default (caller must hold lock)
+func (e *Engine) maxPriceAgeUnlocked(symbol string) time.Duration {
+	if spec, ok := e.symbols[symbol]; ok && spec.MaxPriceAgeMs > 0 {
+		return time.Duration(spec.MaxPriceAgeMs) * time.Millisecond
+	}
+	return e.maxPriceAge
+}
+
+// checkPriceAgeUnlocked rejects fills on a symbol whose latest live quote is
+// older than its price age limit (caller must hold lock)
+func (e *Engine) checkPriceAgeUnlocked(symbol string) error {
+	maxAge := e.maxPriceAgeUnlocked(symbol)
+	if maxAge <= 0 || e.quoteAgeCallback == nil {
+		return nil
+	}
+	age, ok := e.quoteAgeCallback(symbol)
+	if !ok {
+		return fmt.Errorf("price for %s is stale: market data unavailable", symbol)
+	}
+	if age > maxAge {
+		return fmt.Errorf("price for %s is stale: last quote %v old (max %v), market data unavailable",
+			symbol, age.Round(time.Millisecond), maxAge)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestStalePriceRejectsMarketOrder tests that a 10-second-old quote rejects
+// a market order while a fresh quote fills
+func TestStalePriceRejectsMarketOrder(t *testing.T) {
+	engine, account := newTestEngine(t)
+	ages := map[string]time.Duration{
+		"EURUSD": 10 * time.Second,
+		"GBPUSD": 100 * time.Millisecond,
+	}
+	engine.SetQuoteAgeCallback(func(symbol string) (time.Duration, bool) {
+		age, ok := ages[symbol]
+		return age, ok
+	})
+
+	_, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0)
+	if err == nil || !strings.Contains(err.Error(), "market data unavailable") {
+		t.Fatalf("ExecuteMarketOrder() on a 10s old quote error = %v, want stale price", err)
+	}
+	if check := engine.CheckOrder(account.ID, "EURUSD", "BUY", 0.1, 0); check.Valid {
+		t.Error("CheckOrder() allowed an order on a 10s old quote")
+	}
+
+	if _, err := engine.ExecuteMarketOrder(account.ID, "GBPUSD", "BUY", 0.1, 0, 0); err != nil {
+		t.Fatalf("ExecuteMarketOrder() on a fresh quote error = %v", err)
+	}
+}
+
+// TestPriceAgePerSymbol tests that a symbol's own limit overrides the default
+func TestPriceAgePerSymbol(t *testing.T) {
+	engine, account := newTestEngine(t)
+	engine.SetQuoteAgeCallback(func(symbol string) (time.Duration, bool) {
+		return 10 * time.Second, true
+	})
+
+	spec, _ := engine.GetSymbol("EURUSD")
+	relaxed := *spec
+	relaxed.MaxPriceAgeMs = 30000
+	engine.UpdateSymbol(&relaxed)
+
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0); err != nil {
+		t.Fatalf("ExecuteMarketOrder() within the symbol's 30s limit error = %v", err)
+	}
+	if _, err := engine.ExecuteMarketOrder(account.ID, "GBPUSD", "BUY", 0.1, 0, 0); err == nil {
+		t.Fatal("ExecuteMarketOrder() beyond the 3s default filled")
+	}
+
+	// No quote at all is unavailable market data
+	engine.SetQuoteAgeCallback(func(symbol string) (time.Duration, bool) { return 0, false })
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0); err == nil {
+		t.Fatal("ExecuteMarketOrder() without a live quote filled")
+	}
+}
@@ -0,0 +1,73 @@
+package core
+
+import "math"
+
+// QuoteMarkupResolver returns the pips an account's group takes off the bid
+// and puts on the ask of a symbol
+type QuoteMarkupResolver func(accountID int64, symbol string) (bidPips, askPips float64)
+
+type quoteMarkup struct {
+	bidPips float64
+	askPips float64
+}
+
+// SetQuoteMarkupResolver sets the lookup of group quote markups. Marked-up
+// accounts fill, close and trigger SL/TP at the quote their group sees rather
+// than the raw LP quote.
+func (e *Engine) SetQuoteMarkupResolver(fn QuoteMarkupResolver) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.quoteMarkupResolver = fn
+	e.quoteMarkups = nil
+}
+
+// InvalidateQuoteMarkups drops the resolved markups, to be called when a
+// group's markup or an account's group changes
+func (e *Engine) InvalidateQuoteMarkups() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.quoteMarkups = nil
+}
+
+// MarkQuote widens a raw quote of symbol by the account's group markup, to
+// the prices the account fills at
+func (e *Engine) MarkQuote(accountID int64, symbol string, bid, ask float64) (float64, float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.markQuoteUnlocked(accountID, e.symbols[symbol], bid, ask)
+}
+
+// markQuoteUnlocked widens a raw quote by the account's group markup, rounded
+// to the symbol's digits. Markups are resolved once per account and symbol
+// until invalidated, so ticks do not call into the group settings (caller
+// must hold write lock).
+func (e *Engine) markQuoteUnlocked(accountID int64, spec *SymbolSpec, bid, ask float64) (float64, float64) {
+	if e.quoteMarkupResolver == nil || spec == nil || spec.PipSize <= 0 {
+		return bid, ask
+	}
+	if e.quoteMarkups == nil {
+		e.quoteMarkups = make(map[int64]map[string]quoteMarkup)
+	}
+	symbols, ok := e.quoteMarkups[accountID]
+	if !ok {
+		symbols = make(map[string]quoteMarkup)
+		e.quoteMarkups[accountID] = symbols
+	}
+	markup, ok := symbols[spec.Symbol]
+	if !ok {
+		markup.bidPips, markup.askPips = e.quoteMarkupResolver(accountID, spec.Symbol)
+		symbols[spec.Symbol] = markup
+	}
+	if markup.bidPips == 0 && markup.askPips == 0 {
+		return bid, ask
+	}
+
+	bid -= markup.bidPips * spec.PipSize
+	ask += markup.askPips * spec.PipSize
+	if spec.Digits > 0 {
+		scale := math.Pow(10, float64(spec.Digits))
+		bid = math.Round(bid*scale) / scale
+		ask = math.Round(ask*scale) / scale
+	}
+	return bid, ask
+}
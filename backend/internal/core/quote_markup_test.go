@@ -0,0 +1,48 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+// TestQuoteMarkupAppliesToFillsAndStops tests that a marked-up account fills
+// and hits its stop loss at its group's quote, resolving the markup once
+func TestQuoteMarkupAppliesToFillsAndStops(t *testing.T) {
+	engine, account := newTestEngine(t)
+	resolved := 0
+	engine.SetQuoteMarkupResolver(func(accountID int64, symbol string) (float64, float64) {
+		resolved++
+		return 1, 1
+	})
+
+	// EURUSD quotes 1.1000/1.1002, 1.0999/1.1003 with the markup
+	buy, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 1.0990, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder(BUY) error = %v", err)
+	}
+	if math.Abs(buy.OpenPrice-1.1003) > 1e-9 {
+		t.Errorf("BUY fill = %.5f, want the marked-up ask 1.10030", buy.OpenPrice)
+	}
+	sell, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 0.1, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder(SELL) error = %v", err)
+	}
+	if math.Abs(sell.OpenPrice-1.0999) > 1e-9 {
+		t.Errorf("SELL fill = %.5f, want the marked-up bid 1.09990", sell.OpenPrice)
+	}
+
+	// The raw bid stays above the stop, the group's bid reaches it
+	engine.UpdatePrice("EURUSD", 1.0991, 1.0993)
+	if buy.Status != "CLOSED" || buy.CloseReason != CloseReasonStopLoss {
+		t.Errorf("BUY = %s (%s), want closed by its stop loss at the group's bid", buy.Status, buy.CloseReason)
+	}
+	if resolved != 1 {
+		t.Errorf("markup resolved %d times, want once", resolved)
+	}
+
+	engine.InvalidateQuoteMarkups()
+	engine.UpdatePrice("EURUSD", 1.0991, 1.0993)
+	if resolved != 2 {
+		t.Errorf("markup resolved %d times after invalidation, want twice", resolved)
+	}
+}
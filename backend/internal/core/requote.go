@@ -0,0 +1,123 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// ErrRequoteLimit is returned when a market order is still moving against the
+// client after the maximum number of requotes and the limit action is REJECT
+var ErrRequoteLimit = errors.New("REQUOTE_LIMIT")
+
+// What a market order does once it has been requoted MaxRequotes times
+const (
+	RequoteLimitReject = "REJECT" // Reject the order with ErrRequoteLimit (default)
+	RequoteLimitFill   = "FILL"   // Fill at the current price
+)
+
+// RequoteConfig controls the last look on B-Book market orders. The quote is
+// taken when the order arrives and checked again after LastLook; a move against
+// the client beyond TolerancePips requotes the order at the new price.
+type RequoteConfig struct {
+	Enabled       bool
+	TolerancePips float64
+	LastLook      time.Duration
+	MaxRequotes   int    // Requotes before LimitAction applies, at least 1
+	LimitAction   string // REJECT or FILL
+}
+
+// NormalizeRequoteLimitAction validates a requote limit action. Empty selects REJECT.
+func NormalizeRequoteLimitAction(action string) (string, error) {
+	switch strings.ToUpper(action) {
+	case "", RequoteLimitReject:
+		return RequoteLimitReject, nil
+	case RequoteLimitFill:
+		return RequoteLimitFill, nil
+	default:
+		return "", fmt.Errorf("invalid requote limit action %q: must be %s or %s",
+			action, RequoteLimitReject, RequoteLimitFill)
+	}
+}
+
+// SetRequoteConfig sets the last look applied to market orders
+func (e *Engine) SetRequoteConfig(cfg RequoteConfig) error {
+	action, err := NormalizeRequoteLimitAction(cfg.LimitAction)
+	if err != nil {
+		return err
+	}
+	if cfg.Enabled && cfg.MaxRequotes < 1 {
+		return fmt.Errorf("max requotes must be at least 1, got %d", cfg.MaxRequotes)
+	}
+	cfg.LimitAction = action
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.requote = cfg
+	return nil
+}
+
+// GetRequoteConfig returns the last look applied to market orders
+func (e *Engine) GetRequoteConfig() RequoteConfig {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.requote
+}
+
+// lastLook holds a market order until its price stops moving against the
+// client. Returns how many times the order was requoted. Runs without the
+// engine lock so other orders and ticks are not blocked while it waits.
+func (e *Engine) lastLook(symbol, side string) (int, error) {
+	e.mu.RLock()
+	cfg := e.requote
+	priceFn := e.priceCallback
+	spec, ok := e.symbols[symbol]
+	e.mu.RUnlock()
+
+	if !cfg.Enabled || priceFn == nil || !ok {
+		return 0, nil
+	}
+
+	quote, ok := sidePrice(priceFn, symbol, side)
+	if !ok {
+		return 0, nil
+	}
+	tolerance := cfg.TolerancePips * spec.PipSize
+
+	for requotes := 0; ; {
+		time.Sleep(cfg.LastLook)
+
+		current, ok := sidePrice(priceFn, symbol, side)
+		if !ok {
+			return requotes, nil
+		}
+		adverse := current - quote
+		if side == "SELL" {
+			adverse = quote - current
+		}
+		if adverse <= tolerance {
+			return requotes, nil
+		}
+
+		requotes++
+		log.Printf("[B-Book] Requote %d/%d: %s %s moved %.5f -> %.5f", requotes, cfg.MaxRequotes, side, symbol, quote, current)
+		if requotes >= cfg.MaxRequotes {
+			if cfg.LimitAction == RequoteLimitFill {
+				return requotes, nil
+			}
+			return requotes, fmt.Errorf("%w: %s %s requoted %d times", ErrRequoteLimit, side, symbol, requotes)
+		}
+		quote = current
+	}
+}
+
+// sidePrice returns the price a market order on side fills at
+func sidePrice(priceFn func(symbol string) (bid, ask float64, ok bool), symbol, side string) (float64, bool) {
+	bid, ask, ok := priceFn(symbol)
+	if side == "SELL" {
+		return bid, ok
+	}
+	return ask, ok
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+// newRequoteEngine returns an engine whose EURUSD ask rises by step pips on
+// every price read, so each last look sees the market move against a BUY
+func newRequoteEngine(t *testing.T, step float64, cfg RequoteConfig) (*Engine, *Account) {
+	t.Helper()
+	engine, account := newTestEngine(t)
+	ask := 1.1002
+	engine.SetPriceCallback(func(symbol string) (float64, float64, bool) {
+		ask += step * 0.0001
+		return ask - 0.0002, ask, symbol == "EURUSD"
+	})
+	if err := engine.SetRequoteConfig(cfg); err != nil {
+		t.Fatalf("SetRequoteConfig() error = %v", err)
+	}
+	return engine, account
+}
+
+// TestRequoteLimitRejects tests that a steadily adverse price rejects the order
+// with REQUOTE_LIMIT once the requote limit is reached
+func TestRequoteLimitRejects(t *testing.T) {
+	engine, account := newRequoteEngine(t, 2, RequoteConfig{
+		Enabled: true, TolerancePips: 1, MaxRequotes: 3, LimitAction: RequoteLimitReject,
+	})
+
+	_, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0)
+	if !errors.Is(err, ErrRequoteLimit) {
+		t.Fatalf("ExecuteMarketOrder() error = %v, want %v", err, ErrRequoteLimit)
+	}
+	if positions := engine.GetPositions(account.ID); len(positions) != 0 {
+		t.Errorf("open positions = %d, want 0 after rejection", len(positions))
+	}
+}
+
+// TestRequoteLimitFills tests that the FILL action fills at the current price
+// and records how often the order was requoted
+func TestRequoteLimitFills(t *testing.T) {
+	engine, account := newRequoteEngine(t, 2, RequoteConfig{
+		Enabled: true, TolerancePips: 1, MaxRequotes: 3, LimitAction: RequoteLimitFill,
+	})
+
+	pos, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+
+	// One read for the first quote, one per requote, one for the fill itself
+	if want := 1.1002 + 5*0.0002; pos.OpenPrice < want-1e-9 || pos.OpenPrice > want+1e-9 {
+		t.Errorf("open price = %.5f, want the current %.5f", pos.OpenPrice, want)
+	}
+	trades := engine.GetTrades(account.ID)
+	if len(trades) != 1 || trades[0].Requotes != 3 {
+		t.Fatalf("trades = %+v, want one fill with 3 requotes", trades)
+	}
+}
+
+// TestRequoteWithinToleranceFills tests that moves within tolerance fill without a requote
+func TestRequoteWithinToleranceFills(t *testing.T) {
+	engine, account := newRequoteEngine(t, 0.5, RequoteConfig{
+		Enabled: true, TolerancePips: 1, MaxRequotes: 3,
+	})
+
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0); err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	if trades := engine.GetTrades(account.ID); len(trades) != 1 || trades[0].Requotes != 0 {
+		t.Errorf("trades = %+v, want one fill with no requotes", trades)
+	}
+}
@@ -0,0 +1,231 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// SwapFreePolicy is what swap-free accounts pay instead of overnight swap
+type SwapFreePolicy struct {
+	AdminFeePerLot float64 `json:"adminFeePerLot"` // Charged per lot per night once the grace period is over, 0 = none
+	GraceNights    int     `json:"graceNights"`    // Nights a position is held fee-free
+}
+
+// RolloverSchedule is when the daily rollover runs and which weekday books
+// three nights of swap to cover the weekend
+type RolloverSchedule struct {
+	Hour          int          `json:"hour"` // UTC
+	Minute        int          `json:"minute"`
+	TripleSwapDay time.Weekday `json:"tripleSwapDay"`
+}
+
+// DefaultRolloverSchedule rolls over at 22:00 UTC with triple swap on Wednesdays
+var DefaultRolloverSchedule = RolloverSchedule{Hour: 22, TripleSwapDay: time.Wednesday}
+
+// ParseRolloverSchedule parses a rollover time such as "22:00" (UTC) and the
+// weekday name of the triple swap, e.g. "WEDNESDAY"
+func ParseRolloverSchedule(at, tripleSwapDay string) (RolloverSchedule, error) {
+	schedule := DefaultRolloverSchedule
+	if at != "" {
+		t, err := time.Parse("15:04", at)
+		if err != nil {
+			return schedule, fmt.Errorf("invalid rollover time %q, want HH:MM", at)
+		}
+		schedule.Hour, schedule.Minute = t.Hour(), t.Minute()
+	}
+	if tripleSwapDay != "" {
+		found := false
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.EqualFold(d.String(), tripleSwapDay) {
+				schedule.TripleSwapDay, found = d, true
+				break
+			}
+		}
+		if !found || schedule.TripleSwapDay == time.Saturday || schedule.TripleSwapDay == time.Sunday {
+			schedule.TripleSwapDay = DefaultRolloverSchedule.TripleSwapDay
+			return schedule, fmt.Errorf("invalid triple swap day %q, want a weekday", tripleSwapDay)
+		}
+	}
+	return schedule, nil
+}
+
+// Next returns the first rollover strictly after now
+func (s RolloverSchedule) Next(now time.Time) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), s.Hour, s.Minute, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// Last returns the latest rollover at or before now, the start of the
+// current trading day
+func (s RolloverSchedule) Last(now time.Time) time.Time {
+	return s.Next(now).AddDate(0, 0, -1)
+}
+
+// NightsAt returns the nights of swap booked by the rollover at: none on
+// Saturdays and Sundays, three on the triple swap day, otherwise one
+func (s RolloverSchedule) NightsAt(at time.Time) int {
+	switch at.UTC().Weekday() {
+	case time.Saturday, time.Sunday:
+		return 0
+	case s.TripleSwapDay:
+		return 3
+	}
+	return 1
+}
+
+// RolloverResult summarizes one rollover run
+type RolloverResult struct {
+	At              time.Time `json:"at"`
+	Nights          int       `json:"nights"`          // Nights of swap booked, 3 on the triple swap day
+	Positions       int       `json:"positions"`       // Open positions rolled over
+	SwapCharged     float64   `json:"swapCharged"`     // Net swap booked, negative = charged to clients
+	SwapFreeSkipped int       `json:"swapFreeSkipped"` // Positions of swap-free accounts not charged swap
+	AdminFees       float64   `json:"adminFees"`       // Swap-free admin fees charged
+}
+
+// SetSwapFreePolicy sets the admin fee charged to swap-free accounts
+func (e *Engine) SetSwapFreePolicy(policy SwapFreePolicy) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.swapFreePolicy = policy
+}
+
+// GetSwapFreePolicy returns the admin fee charged to swap-free accounts
+func (e *Engine) GetSwapFreePolicy() SwapFreePolicy {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.swapFreePolicy
+}
+
+// SetSwapFree marks an account as swap-free (Islamic) or standard
+func (e *Engine) SetSwapFree(accountID int64, swapFree bool) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	account, ok := e.accounts[accountID]
+	if !ok {
+		return errors.New("account not found")
+	}
+
+	account.SwapFree = swapFree
+	e.persistAccountUnlocked(account)
+	log.Printf("[B-Book] Account %s swap-free=%v", account.AccountNumber, swapFree)
+	return nil
+}
+
+// ApplyRollover books one night of swap on every position opened before at.
+// Swap-free accounts are not charged swap, except on symbols that disable
+// swap-free, and pay the policy's admin fee once past the grace period.
+// A position is rolled over at most once per rollover time.
+func (e *Engine) ApplyRollover(at time.Time) RolloverResult {
+	return e.ApplyRolloverNights(at, 1)
+}
+
+// ApplyRolloverNights is ApplyRollover booking nights of swap at once, as the
+// triple swap rollover does for the weekend
+func (e *Engine) ApplyRolloverNights(at time.Time, nights int) RolloverResult {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	result := RolloverResult{At: at, Nights: nights}
+	for _, pos := range e.positions {
+		if pos.Status != "OPEN" || !pos.OpenTime.Before(at) || !pos.lastRollover.Before(at) {
+			continue
+		}
+		account, ok := e.accounts[pos.AccountID]
+		if !ok {
+			continue
+		}
+		spec := e.symbols[pos.Symbol]
+
+		pos.lastRollover = at
+		pos.Nights += nights
+		result.Positions++
+
+		if account.SwapFree && (spec == nil || !spec.SwapFreeDisabled) {
+			result.SwapFreeSkipped++
+			if fee := e.swapFreeFeeUnlocked(pos, nights); fee != 0 {
+				account.Balance += fee
+				pos.AdminFee += fee
+				e.ledger.RecordSwapFreeFee(account.ID, fee, pos.ID)
+				result.AdminFees -= fee
+				e.persistAccountUnlocked(account)
+			}
+			e.persistPositionUnlocked(pos)
+			continue
+		}
+
+		if spec == nil {
+			e.persistPositionUnlocked(pos)
+			continue
+		}
+		rate := spec.SwapLong
+		if pos.Side == "SELL" {
+			rate = spec.SwapShort
+		}
+		swap := rate * pos.Volume * float64(nights)
+		if swap == 0 {
+			e.persistPositionUnlocked(pos)
+			continue
+		}
+		account.Balance += swap
+		pos.Swap += swap
+		e.ledger.RecordSwap(account.ID, swap, pos.ID)
+		result.SwapCharged += swap
+		e.persistPositionUnlocked(pos)
+		e.persistAccountUnlocked(account)
+	}
+
+	log.Printf("[B-Book] Rollover %s: %d positions, swap %.2f, %d swap-free, admin fees %.2f",
+		at.Format(time.RFC3339), result.Positions, result.SwapCharged, result.SwapFreeSkipped, result.AdminFees)
+	return result
+}
+
+// swapFreeFeeUnlocked returns the admin fee (negative) owed by a swap-free
+// position for the nights just rolled over, counting only those past the grace
+// period (caller must hold lock)
+func (e *Engine) swapFreeFeeUnlocked(pos *Position, nights int) float64 {
+	policy := e.swapFreePolicy
+	if policy.AdminFeePerLot <= 0 || pos.Nights <= policy.GraceNights {
+		return 0
+	}
+	charged := pos.Nights - policy.GraceNights
+	if charged > nights {
+		charged = nights
+	}
+	return -policy.AdminFeePerLot * pos.Volume * float64(charged)
+}
+
+// nextRollover returns the first rollover at hourUTC strictly after now
+func nextRollover(now time.Time, hourUTC int) time.Time {
+	return RolloverSchedule{Hour: hourUTC}.Next(now)
+}
+
+// StartDailyRollover applies the rollover every day on the schedule, skipping
+// weekends and booking triple swap on the schedule's triple swap day
+func (e *Engine) StartDailyRollover(schedule RolloverSchedule) {
+	e.mu.Lock()
+	e.rolloverSchedule = schedule
+	e.mu.Unlock()
+
+	go func() {
+		for {
+			next := schedule.Next(time.Now())
+			time.Sleep(time.Until(next))
+			nights := schedule.NightsAt(next)
+			if nights == 0 {
+				log.Printf("[B-Book] Rollover %s skipped: weekend", next.Format(time.RFC3339))
+				continue
+			}
+			e.ApplyRolloverNights(next, nights)
+		}
+	}()
+	log.Printf("[B-Book] Daily rollover scheduled at %02d:%02d UTC, triple swap on %s", schedule.Hour, schedule.Minute, schedule.TripleSwapDay)
+}
@@ -0,0 +1,225 @@
+package core
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// setSwapRates gives a symbol its overnight swap per lot
+func setSwapRates(t *testing.T, engine *Engine, symbol string, long, short float64) {
+	t.Helper()
+	spec, ok := engine.GetSymbol(symbol)
+	if !ok {
+		t.Fatalf("symbol %s not found", symbol)
+	}
+	spec.SwapLong = long
+	spec.SwapShort = short
+	engine.UpdateSymbol(spec)
+}
+
+// ledgerTotal sums an account's ledger entries of one type
+func ledgerTotal(engine *Engine, accountID int64, entryType string) (float64, int) {
+	total, count := 0.0, 0
+	for _, entry := range engine.GetLedger().GetHistory(accountID, 0) {
+		if entry.Type == entryType {
+			total += entry.Amount
+			count++
+		}
+	}
+	return total, count
+}
+
+// TestRolloverChargesSwap tests that a standard account is charged swap each night, once per rollover
+func TestRolloverChargesSwap(t *testing.T) {
+	engine, account := newTestEngine(t)
+	setSwapRates(t, engine, "EURUSD", -7, 2)
+	pos := openTestPosition(t, engine, account.ID, "EURUSD")
+	balance := account.Balance
+
+	night := time.Now().Add(time.Hour)
+	engine.ApplyRollover(night)
+	engine.ApplyRollover(night) // Same rollover again is a no-op
+
+	if math.Abs(pos.Swap-(-0.7)) > 1e-9 || pos.Nights != 1 {
+		t.Errorf("position swap/nights = %.2f / %d, want -0.70 / 1", pos.Swap, pos.Nights)
+	}
+	if math.Abs(account.Balance-(balance-0.7)) > 1e-9 {
+		t.Errorf("balance = %.2f, want %.2f", account.Balance, balance-0.7)
+	}
+	if total, n := ledgerTotal(engine, account.ID, "SWAP"); n != 1 || math.Abs(total-(-0.7)) > 1e-9 {
+		t.Errorf("SWAP ledger = %.2f over %d entries, want -0.70 over 1", total, n)
+	}
+}
+
+// TestSwapFreeAccountAccruesNoSwap tests that a swap-free account pays no swap
+// over several nights and no fee when none is configured
+func TestSwapFreeAccountAccruesNoSwap(t *testing.T) {
+	engine, account := newTestEngine(t)
+	setSwapRates(t, engine, "EURUSD", -7, 2)
+	if err := engine.SetSwapFree(account.ID, true); err != nil {
+		t.Fatalf("SetSwapFree() error = %v", err)
+	}
+	pos := openTestPosition(t, engine, account.ID, "EURUSD")
+	balance := account.Balance
+
+	night := time.Now().Add(time.Hour)
+	for i := 0; i < 5; i++ {
+		result := engine.ApplyRollover(night.AddDate(0, 0, i))
+		if result.SwapFreeSkipped != 1 || result.SwapCharged != 0 {
+			t.Errorf("night %d result = %+v, want 1 swap-free and no swap", i+1, result)
+		}
+	}
+
+	if pos.Swap != 0 || pos.AdminFee != 0 || pos.Nights != 5 {
+		t.Errorf("position swap/fee/nights = %.2f / %.2f / %d, want 0 / 0 / 5", pos.Swap, pos.AdminFee, pos.Nights)
+	}
+	if account.Balance != balance {
+		t.Errorf("balance = %.2f, want unchanged %.2f", account.Balance, balance)
+	}
+	if _, n := ledgerTotal(engine, account.ID, "SWAP"); n != 0 {
+		t.Errorf("SWAP ledger entries = %d, want 0", n)
+	}
+
+	// A symbol that disables swap-free still charges swap-free accounts
+	spec, _ := engine.GetSymbol("EURUSD")
+	spec.SwapFreeDisabled = true
+	engine.UpdateSymbol(spec)
+	engine.ApplyRollover(night.AddDate(0, 0, 5))
+	if math.Abs(pos.Swap-(-0.7)) > 1e-9 {
+		t.Errorf("swap on swap-free-disabled symbol = %.2f, want -0.70", pos.Swap)
+	}
+}
+
+// TestSwapFreeAdminFeeAfterGracePeriod tests that the configured admin fee is
+// charged only for nights beyond the grace period, and reaches P/L and the ledger
+func TestSwapFreeAdminFeeAfterGracePeriod(t *testing.T) {
+	engine, account := newTestEngine(t)
+	setSwapRates(t, engine, "EURUSD", -7, 2)
+	engine.SetSwapFreePolicy(SwapFreePolicy{AdminFeePerLot: 5, GraceNights: 2})
+	if err := engine.SetSwapFree(account.ID, true); err != nil {
+		t.Fatalf("SetSwapFree() error = %v", err)
+	}
+	pos := openTestPosition(t, engine, account.ID, "EURUSD")
+	balance := account.Balance
+
+	night := time.Now().Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		engine.ApplyRollover(night.AddDate(0, 0, i))
+	}
+	if pos.AdminFee != 0 || account.Balance != balance {
+		t.Fatalf("fee charged during grace: fee %.2f, balance %.2f", pos.AdminFee, account.Balance)
+	}
+
+	// Nights 3 and 4 are past the grace period: 5 per lot * 0.1 lots each
+	for i := 2; i < 4; i++ {
+		result := engine.ApplyRollover(night.AddDate(0, 0, i))
+		if math.Abs(result.AdminFees-0.5) > 1e-9 {
+			t.Errorf("night %d admin fees = %.2f, want 0.50", i+1, result.AdminFees)
+		}
+	}
+	if pos.Swap != 0 || math.Abs(pos.AdminFee-(-1.0)) > 1e-9 {
+		t.Errorf("position swap/fee = %.2f / %.2f, want 0 / -1.00", pos.Swap, pos.AdminFee)
+	}
+	if math.Abs(account.Balance-(balance-1.0)) > 1e-9 {
+		t.Errorf("balance = %.2f, want %.2f", account.Balance, balance-1.0)
+	}
+	if total, n := ledgerTotal(engine, account.ID, "SWAP_FREE_FEE"); n != 2 || math.Abs(total-(-1.0)) > 1e-9 {
+		t.Errorf("SWAP_FREE_FEE ledger = %.2f over %d entries, want -1.00 over 2", total, n)
+	}
+	summary, _ := engine.GetAccountSummary(account.ID)
+	if math.Abs(summary.Balance-(balance-1.0)) > 1e-9 {
+		t.Errorf("summary balance = %.2f, want %.2f", summary.Balance, balance-1.0)
+	}
+}
+
+// TestNextRollover tests that the next rollover is always strictly in the future
+func TestNextRollover(t *testing.T) {
+	base := time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{base.Add(21 * time.Hour), base.Add(22 * time.Hour)},
+		{base.Add(22 * time.Hour), base.Add(46 * time.Hour)},
+		{base.Add(23 * time.Hour), base.Add(46 * time.Hour)},
+	}
+	for _, tt := range tests {
+		if got := nextRollover(tt.now, 22); !got.Equal(tt.want) {
+			t.Errorf("nextRollover(%v) = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+}
+
+// TestTripleSwapRollover tests that the triple swap day books three nights of
+// swap and swap-free fees, and that weekend rollovers book none
+func TestTripleSwapRollover(t *testing.T) {
+	schedule, err := ParseRolloverSchedule("21:30", "wednesday")
+	if err != nil {
+		t.Fatalf("ParseRolloverSchedule() error = %v", err)
+	}
+	wednesday := time.Date(2024, 3, 6, 21, 30, 0, 0, time.UTC)
+	for day, want := range []int{3, 1, 1, 0, 0, 1, 1} {
+		if got := schedule.NightsAt(wednesday.AddDate(0, 0, day)); got != want {
+			t.Errorf("NightsAt(%s) = %d, want %d", wednesday.AddDate(0, 0, day).Weekday(), got, want)
+		}
+	}
+	if got := schedule.Next(wednesday.Add(-time.Minute)); !got.Equal(wednesday) {
+		t.Errorf("Next() = %v, want %v", got, wednesday)
+	}
+
+	engine, account := newTestEngine(t)
+	setSwapRates(t, engine, "EURUSD", -7, 2)
+	pos := openTestPosition(t, engine, account.ID, "EURUSD")
+
+	result := engine.ApplyRolloverNights(time.Now().Add(time.Hour), schedule.NightsAt(wednesday))
+	if math.Abs(pos.Swap-(-2.1)) > 1e-9 || pos.Nights != 3 || result.Nights != 3 {
+		t.Errorf("position swap/nights = %.2f / %d, want -2.10 / 3", pos.Swap, pos.Nights)
+	}
+	if total, n := ledgerTotal(engine, account.ID, "SWAP"); n != 1 || math.Abs(total-(-2.1)) > 1e-9 {
+		t.Errorf("SWAP ledger = %.2f over %d entries, want -2.10 in one entry", total, n)
+	}
+
+	// Swap-free fee: of the 3 nights only the one past the 2-night grace is charged
+	engine.SetSwapFreePolicy(SwapFreePolicy{AdminFeePerLot: 5, GraceNights: 2})
+	if err := engine.SetSwapFree(account.ID, true); err != nil {
+		t.Fatalf("SetSwapFree() error = %v", err)
+	}
+	free := openTestPosition(t, engine, account.ID, "EURUSD")
+	engine.ApplyRolloverNights(time.Now().Add(2*time.Hour), 3)
+	if math.Abs(free.AdminFee-(-0.5)) > 1e-9 {
+		t.Errorf("swap-free admin fee = %.2f, want -0.50 for the one night past grace", free.AdminFee)
+	}
+}
+
+// TestRolloverSkipsSameDayPositions tests that a position opened and closed
+// before the rollover accrues no swap
+func TestRolloverSkipsSameDayPositions(t *testing.T) {
+	engine, account := newTestEngine(t)
+	setSwapRates(t, engine, "EURUSD", -7, 2)
+	pos := openTestPosition(t, engine, account.ID, "EURUSD")
+	if _, err := engine.ClosePosition(pos.ID, 0); err != nil {
+		t.Fatalf("ClosePosition() error = %v", err)
+	}
+
+	if result := engine.ApplyRollover(time.Now().Add(time.Hour)); result.Positions != 0 {
+		t.Errorf("rollover result = %+v, want no positions", result)
+	}
+	if _, n := ledgerTotal(engine, account.ID, "SWAP"); n != 0 || pos.Swap != 0 {
+		t.Errorf("swap = %.2f over %d ledger entries, want none", pos.Swap, n)
+	}
+}
+
+// TestParseRolloverScheduleRejectsWeekend tests that the triple swap cannot fall on a weekend
+func TestParseRolloverScheduleRejectsWeekend(t *testing.T) {
+	if _, err := ParseRolloverSchedule("22:00", "SATURDAY"); err == nil {
+		t.Error("ParseRolloverSchedule() accepted a Saturday triple swap")
+	}
+	if _, err := ParseRolloverSchedule("25:00", ""); err == nil {
+		t.Error("ParseRolloverSchedule() accepted 25:00")
+	}
+	schedule, err := ParseRolloverSchedule("", "")
+	if err != nil || schedule != DefaultRolloverSchedule {
+		t.Errorf("ParseRolloverSchedule(\"\", \"\") = %+v, %v; want the default", schedule, err)
+	}
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"log"
+	"math"
+)
+
+// checkStopUnlocked closes a position whose SL or TP level the tick reached,
+// reporting whether it closed. Longs exit at the bid and shorts at the ask.
+// The close is at the level itself unless the exit price jumped past it by
+// more than the spread, in which case the gap fills at the exit price, the
+// first price available beyond the level (caller must hold lock).
+func (e *Engine) checkStopUnlocked(pos *Position, level float64, reason string, bid, ask float64) bool {
+	if level <= 0 {
+		return false
+	}
+
+	exit := bid
+	if pos.Side != "BUY" {
+		exit = ask
+	}
+	hit := false
+	switch {
+	case reason == CloseReasonStopLoss && pos.Side == "BUY":
+		hit = exit <= level
+	case reason == CloseReasonStopLoss:
+		hit = exit >= level
+	case pos.Side == "BUY":
+		hit = exit >= level
+	default:
+		hit = exit <= level
+	}
+	if !hit {
+		return false
+	}
+
+	fill := level
+	gapped := math.Abs(exit-level) > ask-bid
+	if gapped {
+		fill = exit
+	}
+
+	pos.CloseReason = reason
+	if _, err := e.closePositionAtUnlocked(pos, 0, fill); err != nil {
+		pos.CloseReason = ""
+		log.Printf("[B-Book] Failed to execute %s close for #%d: %v", reason, pos.ID, err)
+		return false
+	}
+	if gapped {
+		log.Printf("[B-Book] %s gapped for Position #%d (%s): level %.5f, closed @ %.5f", reason, pos.ID, pos.Symbol, level, fill)
+	} else {
+		log.Printf("[B-Book] %s triggered for Position #%d (%s) @ %.5f", reason, pos.ID, pos.Symbol, fill)
+	}
+	return true
+}
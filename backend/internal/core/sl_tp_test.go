@@ -0,0 +1,59 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+// TestStopLossTakeProfitCloses tests that a tick reaching a long or short
+// position's SL or TP closes it at the level, or at the exit price when the
+// price gapped through the level
+func TestStopLossTakeProfitCloses(t *testing.T) {
+	tests := []struct {
+		name       string
+		side       string
+		sl, tp     float64
+		bid, ask   float64 // Tick that reaches the level
+		wantPrice  float64
+		wantReason string
+	}{
+		{"long stop loss", "BUY", 1.0980, 0, 1.0979, 1.0981, 1.0980, CloseReasonStopLoss},
+		{"long take profit", "BUY", 0, 1.1020, 1.1021, 1.1023, 1.1020, CloseReasonTakeProfit},
+		{"short stop loss", "SELL", 1.1030, 0, 1.1029, 1.1031, 1.1030, CloseReasonStopLoss},
+		{"short take profit", "SELL", 0, 1.0980, 1.0977, 1.0979, 1.0980, CloseReasonTakeProfit},
+		{"long stop loss gap", "BUY", 1.0980, 1.1020, 1.0950, 1.0952, 1.0950, CloseReasonStopLoss},
+		{"short take profit gap", "SELL", 1.1030, 1.0980, 1.0940, 1.0942, 1.0942, CloseReasonTakeProfit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, account := newTestEngine(t)
+			pos, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", tt.side, 1, tt.sl, tt.tp)
+			if err != nil {
+				t.Fatalf("ExecuteMarketOrder() error = %v", err)
+			}
+
+			// Between the levels nothing closes
+			engine.UpdatePrice("EURUSD", 1.1005, 1.1007)
+			if pos.Status != "OPEN" {
+				t.Fatalf("position %s before reaching a level", pos.Status)
+			}
+
+			engine.UpdatePrice("EURUSD", tt.bid, tt.ask)
+			if pos.Status != "CLOSED" || pos.CloseReason != tt.wantReason {
+				t.Fatalf("position = %s (%q), want CLOSED by %s", pos.Status, pos.CloseReason, tt.wantReason)
+			}
+			if math.Abs(pos.ClosePrice-tt.wantPrice) > 1e-9 {
+				t.Errorf("closed @ %.5f, want %.5f", pos.ClosePrice, tt.wantPrice)
+			}
+
+			entries := engine.GetLedger().GetEntriesByType("REALIZED_PNL", 10)
+			wantPnL := (tt.wantPrice - pos.OpenPrice) * 100000
+			if tt.side == "SELL" {
+				wantPnL = -wantPnL
+			}
+			if len(entries) != 1 || math.Abs(entries[0].Amount-wantPnL) > 1e-6 {
+				t.Errorf("ledger realized P/L = %+v, want one entry of %.2f", entries, wantPnL)
+			}
+		})
+	}
+}
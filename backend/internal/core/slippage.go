@@ -0,0 +1,159 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+// Slippage models applied to B-Book market fills
+const (
+	SlippageNone      = "NONE"       // Fill at the quote (default)
+	SlippageFixed     = "FIXED"      // Fill Pips against the client
+	SlippageRandom    = "RANDOM"     // Fill anywhere within Pips either side of the quote
+	SlippageWorseOnly = "WORSE_ONLY" // Fill up to Pips against the client, never better than the quote
+)
+
+// ErrRequote is returned when a market order's fill moved further from the
+// quote than the requote tolerance; the client confirms by resending it with
+// the requoted price as its accept price
+var ErrRequote = errors.New("REQUOTE")
+
+// SlippageConfig is the slippage model of a symbol or group
+type SlippageConfig struct {
+	Model       string  `json:"model"`                 // NONE, FIXED, RANDOM or WORSE_ONLY
+	Pips        float64 `json:"pips"`                  // Fixed slippage, or the width of the band
+	RequotePips float64 `json:"requotePips,omitempty"` // Requote a fill worse than the quote by more than this, 0 = never
+}
+
+// RequoteError carries the price a requoted market order would fill at
+type RequoteError struct {
+	Symbol string  `json:"symbol"`
+	Side   string  `json:"side"`
+	Quoted float64 `json:"quoted"` // The quote, or the client's accept price
+	Price  float64 `json:"price"`  // The price the order fills at once confirmed
+}
+
+func (e *RequoteError) Error() string {
+	return fmt.Sprintf("%v: %s %s moved from %.5f to %.5f", ErrRequote, e.Side, e.Symbol, e.Quoted, e.Price)
+}
+
+func (e *RequoteError) Unwrap() error { return ErrRequote }
+
+// SlippageResolver returns the slippage model of an account's group for a
+// symbol, or false when the symbol's model applies
+type SlippageResolver func(accountID int64, symbol string) (SlippageConfig, bool)
+
+// NormalizeSlippageConfig validates a slippage model. An empty model selects NONE.
+func NormalizeSlippageConfig(cfg SlippageConfig) (SlippageConfig, error) {
+	model := strings.ToUpper(cfg.Model)
+	switch model {
+	case "":
+		model = SlippageNone
+	case SlippageNone, SlippageFixed, SlippageRandom, SlippageWorseOnly:
+	default:
+		return SlippageConfig{}, fmt.Errorf("invalid slippage model %q: must be %s, %s, %s or %s",
+			cfg.Model, SlippageNone, SlippageFixed, SlippageRandom, SlippageWorseOnly)
+	}
+	if cfg.Pips < 0 || cfg.RequotePips < 0 {
+		return SlippageConfig{}, errors.New("slippage pips must not be negative")
+	}
+	cfg.Model = model
+	return cfg, nil
+}
+
+// SetSlippageConfig sets the broker default slippage model, used by symbols
+// and groups without their own
+func (e *Engine) SetSlippageConfig(cfg SlippageConfig) error {
+	normalized, err := NormalizeSlippageConfig(cfg)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.slippage = normalized
+	return nil
+}
+
+// GetSlippageConfig returns the broker default slippage model
+func (e *Engine) GetSlippageConfig() SlippageConfig {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.slippage
+}
+
+// SetSlippageResolver sets the lookup for group-level slippage models
+func (e *Engine) SetSlippageResolver(fn SlippageResolver) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.slippageResolver = fn
+}
+
+// slippageUnlocked resolves the slippage model for an account trading a
+// symbol: the group's, else the symbol's, else the broker default (caller
+// must hold lock)
+func (e *Engine) slippageUnlocked(accountID int64, spec *SymbolSpec) SlippageConfig {
+	if e.slippageResolver != nil {
+		if cfg, ok := e.slippageResolver(accountID, spec.Symbol); ok {
+			if normalized, err := NormalizeSlippageConfig(cfg); err == nil {
+				return normalized
+			}
+		}
+	}
+	if spec.Slippage != nil {
+		if normalized, err := NormalizeSlippageConfig(*spec.Slippage); err == nil {
+			return normalized
+		}
+	}
+	return e.slippage
+}
+
+// slipFillUnlocked moves a market fill off the quote by the slippage model and
+// requotes it when it lands further against the client than the tolerance
+// allows from the quote, or from acceptPrice when the client confirmed a
+// requote (caller must hold lock)
+func (e *Engine) slipFillUnlocked(cfg SlippageConfig, spec *SymbolSpec, side string, quote, acceptPrice float64) (float64, error) {
+	var pips float64
+	switch cfg.Model {
+	case SlippageFixed:
+		pips = cfg.Pips
+	case SlippageRandom:
+		pips = (2*e.slippageRand() - 1) * cfg.Pips
+	case SlippageWorseOnly:
+		pips = e.slippageRand() * cfg.Pips
+	}
+
+	// Positive pips are always against the client
+	fill := quote + pips*spec.PipSize
+	if side == "SELL" {
+		fill = quote - pips*spec.PipSize
+	}
+	if spec.Digits > 0 {
+		scale := math.Pow(10, float64(spec.Digits))
+		fill = math.Round(fill*scale) / scale
+	}
+	// Rounding to the symbol's digits never turns adverse slippage favourable
+	if pips >= 0 && (side == "BUY" && fill < quote || side == "SELL" && fill > quote) {
+		fill = quote
+	}
+
+	reference := quote
+	if acceptPrice > 0 {
+		reference = acceptPrice
+	}
+	adverse := fill - reference
+	if side == "SELL" {
+		adverse = reference - fill
+	}
+	if cfg.RequotePips > 0 && adverse > cfg.RequotePips*spec.PipSize+1e-9 {
+		return 0, &RequoteError{Symbol: spec.Symbol, Side: side, Quoted: reference, Price: fill}
+	}
+	return fill, nil
+}
+
+// defaultSlippageRand draws the share of the band a fill slips by
+func defaultSlippageRand() float64 {
+	return rand.Float64()
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// TestWorseOnlySlippageNeverBetter tests that WORSE_ONLY fills are never
+// better than the quoted side and stay within the band
+func TestWorseOnlySlippageNeverBetter(t *testing.T) {
+	engine, account := newTestEngine(t)
+	account.Balance = 1000000
+	if err := engine.SetSlippageConfig(SlippageConfig{Model: SlippageWorseOnly, Pips: 2}); err != nil {
+		t.Fatalf("SetSlippageConfig() error = %v", err)
+	}
+	engine.slippageRand = rand.New(rand.NewSource(1)).Float64
+
+	// EURUSD quotes 1.1000/1.1002
+	for i := 0; i < 200; i++ {
+		buy, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.01, 0, 0)
+		if err != nil {
+			t.Fatalf("BUY %d error = %v", i, err)
+		}
+		if buy.OpenPrice < 1.1002 || buy.OpenPrice > 1.1004+1e-9 {
+			t.Fatalf("BUY %d filled at %.5f, want within [1.10020, 1.10040]", i, buy.OpenPrice)
+		}
+
+		sell, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 0.01, 0, 0)
+		if err != nil {
+			t.Fatalf("SELL %d error = %v", i, err)
+		}
+		if sell.OpenPrice > 1.1000 || sell.OpenPrice < 1.0998-1e-9 {
+			t.Fatalf("SELL %d filled at %.5f, want within [1.09980, 1.10000]", i, sell.OpenPrice)
+		}
+	}
+}
+
+// TestFixedSlippage tests that FIXED fills slip the configured pips against the client
+func TestFixedSlippage(t *testing.T) {
+	engine, account := newTestEngine(t)
+	engine.SetSlippageConfig(SlippageConfig{Model: SlippageFixed, Pips: 0.5})
+
+	pos, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	if pos.OpenPrice != 1.10025 {
+		t.Errorf("OpenPrice = %.5f, want 1.10025", pos.OpenPrice)
+	}
+}
+
+// TestRandomSlippageBand tests that RANDOM fills can land either side of the quote
+func TestRandomSlippageBand(t *testing.T) {
+	engine, account := newTestEngine(t)
+	engine.SetSlippageConfig(SlippageConfig{Model: SlippageRandom, Pips: 1})
+
+	engine.slippageRand = func() float64 { return 0 }
+	better, _ := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0)
+	engine.slippageRand = func() float64 { return 0.99999 }
+	worse, _ := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0)
+
+	if better == nil || better.OpenPrice != 1.1001 {
+		t.Errorf("best fill = %+v, want 1.10010", better)
+	}
+	if worse == nil || worse.OpenPrice != 1.1003 {
+		t.Errorf("worst fill = %+v, want 1.10030", worse)
+	}
+}
+
+// TestSlippageRequoteConfirm tests that a fill beyond the requote tolerance is
+// requoted and fills once the client accepts the requoted price
+func TestSlippageRequoteConfirm(t *testing.T) {
+	engine, account := newTestEngine(t)
+	engine.SetSlippageConfig(SlippageConfig{Model: SlippageFixed, Pips: 3, RequotePips: 1})
+
+	_, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0)
+	var requote *RequoteError
+	if !errors.As(err, &requote) || !errors.Is(err, ErrRequote) {
+		t.Fatalf("ExecuteMarketOrder() error = %v, want a requote", err)
+	}
+	if requote.Price != 1.1005 {
+		t.Fatalf("requoted price = %.5f, want 1.10050", requote.Price)
+	}
+	if len(engine.GetPositions(account.ID)) != 0 {
+		t.Fatal("requoted order opened a position")
+	}
+
+	pos, err := engine.ExecuteMarketOrderAt(account.ID, "EURUSD", "BUY", 0.1, 0, 0, 0, requote.Price)
+	if err != nil {
+		t.Fatalf("confirmed order error = %v", err)
+	}
+	if pos.OpenPrice != requote.Price {
+		t.Errorf("OpenPrice = %.5f, want the requoted %.5f", pos.OpenPrice, requote.Price)
+	}
+}
+
+// TestSlippageGroupOverridesSymbol tests that the group model wins over the symbol's
+func TestSlippageGroupOverridesSymbol(t *testing.T) {
+	engine, account := newTestEngine(t)
+
+	spec, _ := engine.GetSymbol("EURUSD")
+	slipped := *spec
+	slipped.Slippage = &SlippageConfig{Model: SlippageFixed, Pips: 1}
+	engine.UpdateSymbol(&slipped)
+
+	pos, _ := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0)
+	if pos == nil || pos.OpenPrice != 1.1003 {
+		t.Fatalf("symbol slippage fill = %+v, want 1.10030", pos)
+	}
+
+	engine.SetSlippageResolver(func(accountID int64, symbol string) (SlippageConfig, bool) {
+		return SlippageConfig{Model: SlippageNone}, true
+	})
+	pos, _ = engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0)
+	if pos == nil || pos.OpenPrice != 1.1002 {
+		t.Fatalf("group override fill = %+v, want the raw 1.10020", pos)
+	}
+}
+
+// TestLimitOrderNeverFillsWorse tests that a triggered limit order is
+// rejected when slippage would fill it past its limit, and fills at the limit
+func TestLimitOrderNeverFillsWorse(t *testing.T) {
+	engine, account := newTestEngine(t)
+	engine.SetSlippageConfig(SlippageConfig{Model: SlippageFixed, Pips: 0.5})
+
+	// EURUSD asks 1.1002, slipping to 1.10025
+	_, err := engine.ExecuteLimitOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0, 1.1002)
+	var requote *RequoteError
+	if !errors.As(err, &requote) || requote.Quoted != 1.1002 {
+		t.Fatalf("ExecuteLimitOrder() error = %v, want a requote at the limit", err)
+	}
+	if positions := engine.GetPositions(account.ID); len(positions) != 0 {
+		t.Fatalf("open positions = %d after a rejected limit fill, want 0", len(positions))
+	}
+
+	pos, err := engine.ExecuteLimitOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0, 1.1003)
+	if err != nil {
+		t.Fatalf("ExecuteLimitOrder() error = %v", err)
+	}
+	if pos.OpenPrice != 1.10025 {
+		t.Errorf("OpenPrice = %.5f, want 1.10025", pos.OpenPrice)
+	}
+}
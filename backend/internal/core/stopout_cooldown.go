@@ -0,0 +1,95 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ErrStopOutCooldown is returned when a new order is placed while the account is
+// cooling down after a stop-out
+var ErrStopOutCooldown = errors.New("STOPOUT_COOLDOWN")
+
+// StopOutCooldownResolver returns the post stop-out cooldown of an account, or a
+// negative duration to use the engine default
+type StopOutCooldownResolver func(accountID int64) time.Duration
+
+// SetStopOutCooldown sets the default cooldown after a stop-out during which the
+// account cannot open positions. 0 disables the cooldown.
+func (e *Engine) SetStopOutCooldown(d time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stopOutCooldown = d
+}
+
+// SetStopOutCooldownResolver sets the lookup for per-account or group cooldown overrides
+func (e *Engine) SetStopOutCooldownResolver(fn StopOutCooldownResolver) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stopOutCooldownResolver = fn
+}
+
+// SetStopOutCooldownCallback sets the function notified when a cooldown starts
+func (e *Engine) SetStopOutCooldownCallback(fn func(accountID int64, until time.Time)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stopOutCooldownCallback = fn
+}
+
+// RecordStopOut counts a stop-out and starts the post stop-out cooldown of an
+// account. Returns when the cooldown ends, or false when no cooldown applies.
+func (e *Engine) RecordStopOut(accountID int64) (time.Time, bool) {
+	e.mu.Lock()
+	e.recordRiskEventUnlocked(&e.stopOuts)
+
+	d := e.stopOutCooldown
+	if e.stopOutCooldownResolver != nil {
+		if override := e.stopOutCooldownResolver(accountID); override >= 0 {
+			d = override
+		}
+	}
+	if d <= 0 {
+		e.mu.Unlock()
+		return time.Time{}, false
+	}
+
+	until := time.Now().Add(d)
+	e.stopOutCooldowns[accountID] = until
+	callback := e.stopOutCooldownCallback
+	e.mu.Unlock()
+
+	log.Printf("[B-Book] Account #%d stopped out: new orders blocked until %s", accountID, until.Format(time.RFC3339))
+	if callback != nil {
+		callback(accountID, until)
+	}
+	return until, true
+}
+
+// StopOutCooldownUntil returns when an account's stop-out cooldown ends, or
+// false when the account is not cooling down
+func (e *Engine) StopOutCooldownUntil(accountID int64) (time.Time, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	until, ok := e.stopOutCooldowns[accountID]
+	if !ok || !time.Now().Before(until) {
+		return time.Time{}, false
+	}
+	return until, true
+}
+
+// checkStopOutCooldownUnlocked rejects new orders while the account is cooling
+// down after a stop-out. An elapsed cooldown is lifted (caller must hold lock).
+func (e *Engine) checkStopOutCooldownUnlocked(accountID int64) error {
+	until, ok := e.stopOutCooldowns[accountID]
+	if !ok {
+		return nil
+	}
+	if !time.Now().Before(until) {
+		delete(e.stopOutCooldowns, accountID)
+		log.Printf("[B-Book] Account #%d stop-out cooldown lifted", accountID)
+		return nil
+	}
+	return fmt.Errorf("%w: account cannot open positions until %s", ErrStopOutCooldown, until.Format(time.RFC3339))
+}
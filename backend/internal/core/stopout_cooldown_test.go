@@ -0,0 +1,58 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestStopOutCooldownBlocksNewOrders tests that new orders are rejected during the cooldown,
+// closes are still permitted and trading resumes once it elapses
+func TestStopOutCooldownBlocksNewOrders(t *testing.T) {
+	engine, account := newTestEngine(t)
+	pos := openTestPosition(t, engine, account.ID, "EURUSD")
+
+	var audited []int64
+	engine.SetStopOutCooldown(time.Hour)
+	engine.SetStopOutCooldownResolver(func(accountID int64) time.Duration {
+		return 50 * time.Millisecond // Group override
+	})
+	engine.SetStopOutCooldownCallback(func(accountID int64, until time.Time) {
+		audited = append(audited, accountID)
+	})
+
+	if _, ok := engine.RecordStopOut(account.ID); !ok {
+		t.Fatal("RecordStopOut() = false, want cooldown started")
+	}
+	if len(audited) != 1 || audited[0] != account.ID {
+		t.Errorf("audited = %v, want account %d", audited, account.ID)
+	}
+
+	_, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0)
+	if !errors.Is(err, ErrStopOutCooldown) {
+		t.Fatalf("ExecuteMarketOrder() during cooldown error = %v, want STOPOUT_COOLDOWN", err)
+	}
+	if _, err := engine.ClosePosition(pos.ID, 0); err != nil {
+		t.Errorf("ClosePosition() during cooldown error = %v, want allowed", err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if _, ok := engine.StopOutCooldownUntil(account.ID); ok {
+		t.Error("StopOutCooldownUntil() = true after the cooldown elapsed")
+	}
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0); err != nil {
+		t.Errorf("ExecuteMarketOrder() after cooldown error = %v", err)
+	}
+}
+
+// TestStopOutCooldownDisabled tests that no cooldown applies when the duration is zero
+func TestStopOutCooldownDisabled(t *testing.T) {
+	engine, account := newTestEngine(t)
+
+	if _, ok := engine.RecordStopOut(account.ID); ok {
+		t.Error("RecordStopOut() = true with no cooldown configured")
+	}
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 0.1, 0, 0); err != nil {
+		t.Errorf("ExecuteMarketOrder() error = %v", err)
+	}
+}
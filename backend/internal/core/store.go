@@ -0,0 +1,197 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Store persists accounts, positions, ledger entries and bonuses so the engine's state
+// survives a restart. Reads are served from memory; the engine queues every
+// change for the store, written in order outside the engine lock and retried
+// until it succeeds, and loads from it on startup.
+type Store interface {
+	SaveAccount(account Account) error
+	SavePosition(position PositionRecord) error
+	AppendLedgerEntry(entry LedgerEntry) error
+	SaveBonus(bonus Bonus) error
+	LoadState() (*StoreState, error)
+}
+
+// PositionRecord is a position with the bookkeeping a restart must keep
+type PositionRecord struct {
+	Position
+	OpenCommission float64   `json:"openCommission"` // Opening commission not yet attributed to a close
+	LastRollover   time.Time `json:"lastRollover"`   // Rollover last applied
+}
+
+// StoreState is the engine state held by a store. Ledger entries are oldest first.
+type StoreState struct {
+	Accounts      []Account
+	Positions     []PositionRecord
+	LedgerEntries []LedgerEntry
+	Bonuses       []Bonus
+}
+
+// SetStore sets the store the engine writes accounts, positions, ledger
+// entries and bonuses through to
+func (e *Engine) SetStore(store Store) {
+	writer := newStoreWriter(store)
+	e.mu.Lock()
+	e.store = store
+	e.storeWriter = writer
+	e.mu.Unlock()
+
+	e.ledger.SetEntryCallback(func(entry LedgerEntry) {
+		writer.enqueue(fmt.Sprintf("ledger entry #%d", entry.ID), func(s Store) error {
+			return s.AppendLedgerEntry(entry)
+		})
+	})
+}
+
+// StoreStatus reports the writes not yet in the store and whether the store
+// is failing. The zero status is returned when there is no store.
+func (e *Engine) StoreStatus() StoreStatus {
+	e.mu.RLock()
+	writer := e.storeWriter
+	e.mu.RUnlock()
+	if writer == nil {
+		return StoreStatus{}
+	}
+	return writer.status()
+}
+
+// FlushStore waits until every change made so far is in the store, or ctx is done
+func (e *Engine) FlushStore(ctx context.Context) error {
+	e.mu.RLock()
+	writer := e.storeWriter
+	e.mu.RUnlock()
+	if writer == nil {
+		return nil
+	}
+	return writer.flush(ctx)
+}
+
+// LoadFromStore replaces the engine's accounts, positions, ledger and bonuses
+// with the store's. Returns the number of accounts loaded.
+func (e *Engine) LoadFromStore() (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.store == nil {
+		return 0, nil
+	}
+	state, err := e.store.LoadState()
+	if err != nil {
+		return 0, err
+	}
+
+	e.accounts = make(map[int64]*Account, len(state.Accounts))
+	balances := make(map[int64]float64, len(state.Accounts))
+	for i := range state.Accounts {
+		account := state.Accounts[i]
+		e.accounts[account.ID] = &account
+		balances[account.ID] = account.Balance
+	}
+
+	e.positions = make(map[int64]*Position, len(state.Positions))
+	e.nextPositionID = 1
+	for _, record := range state.Positions {
+		position := record.Position
+		position.openCommission = record.OpenCommission
+		position.lastRollover = record.LastRollover
+		e.positions[position.ID] = &position
+		if position.ID >= e.nextPositionID {
+			e.nextPositionID = position.ID + 1
+		}
+	}
+
+	e.bonuses = make(map[int64][]*Bonus)
+	e.nextBonusID = 1
+	for i := range state.Bonuses {
+		bonus := state.Bonuses[i]
+		e.bonuses[bonus.AccountID] = append(e.bonuses[bonus.AccountID], &bonus)
+		if bonus.ID >= e.nextBonusID {
+			e.nextBonusID = bonus.ID + 1
+		}
+	}
+
+	// Trade IDs are referenced by ledger entries, so they keep counting past them
+	for _, entry := range state.LedgerEntries {
+		if entry.RefType == "TRADE" && entry.RefID >= e.nextTradeID {
+			e.nextTradeID = entry.RefID + 1
+		}
+	}
+	e.ledger.Restore(state.LedgerEntries, balances)
+
+	log.Printf("[B-Book] Loaded %d accounts, %d positions, %d ledger entries and %d bonuses from the store",
+		len(state.Accounts), len(state.Positions), len(state.LedgerEntries), len(state.Bonuses))
+	return len(state.Accounts), nil
+}
+
+// persistAccountUnlocked queues a copy of an account for the store (caller must hold lock)
+func (e *Engine) persistAccountUnlocked(account *Account) {
+	if e.storeWriter == nil || account == nil {
+		return
+	}
+	saved := *account
+	saved.Positions, saved.Orders = nil, nil
+	e.storeWriter.enqueue("account "+account.AccountNumber, func(s Store) error {
+		return s.SaveAccount(saved)
+	})
+}
+
+// persistPositionUnlocked queues a copy of a position for the store (caller must hold lock)
+func (e *Engine) persistPositionUnlocked(position *Position) {
+	if e.storeWriter == nil || position == nil {
+		return
+	}
+	record := PositionRecord{
+		Position:       *position,
+		OpenCommission: position.openCommission,
+		LastRollover:   position.lastRollover,
+	}
+	// The ladder's levels keep filling after the copy is queued
+	if position.TPLadder != nil {
+		ladder := *position.TPLadder
+		ladder.Levels = make([]*TPLevel, len(position.TPLadder.Levels))
+		for i, level := range position.TPLadder.Levels {
+			copied := *level
+			ladder.Levels[i] = &copied
+		}
+		record.TPLadder = &ladder
+	}
+	e.storeWriter.enqueue(fmt.Sprintf("position #%d", position.ID), func(s Store) error {
+		return s.SavePosition(record)
+	})
+}
+
+// persistBonusUnlocked queues a copy of a bonus for the store (caller must hold lock)
+func (e *Engine) persistBonusUnlocked(bonus *Bonus) {
+	if e.storeWriter == nil || bonus == nil {
+		return
+	}
+	saved := *bonus
+	e.storeWriter.enqueue(fmt.Sprintf("bonus #%d", bonus.ID), func(s Store) error {
+		return s.SaveBonus(saved)
+	})
+}
+
+// persistTradeEventUnlocked queues the account and position a fill or close
+// changed for the store (caller must hold lock)
+func (e *Engine) persistTradeEventUnlocked(event TradeEvent) {
+	if e.storeWriter == nil || event.Type == TradeEventOrderAccepted {
+		return
+	}
+	e.persistPositionUnlocked(e.positions[event.PositionID])
+	e.persistAccountUnlocked(e.accounts[event.AccountID])
+}
+
+// PersistAccount queues an account changed outside the engine, such as a demo
+// account's opening balance, for the store
+func (e *Engine) PersistAccount(accountID int64) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	e.persistAccountUnlocked(e.accounts[accountID])
+}
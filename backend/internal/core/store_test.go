@@ -0,0 +1,250 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memStore is a Store kept in memory, standing in for the database
+type memStore struct {
+	mu        sync.Mutex
+	accounts  map[int64]Account
+	positions map[int64]PositionRecord
+	entries   []LedgerEntry
+	bonuses   map[int64]Bonus
+	err       error // Returned by every write while set
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		accounts:  make(map[int64]Account),
+		positions: make(map[int64]PositionRecord),
+		bonuses:   make(map[int64]Bonus),
+	}
+}
+
+func (s *memStore) SaveAccount(account Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.accounts[account.ID] = account
+	return nil
+}
+
+func (s *memStore) SavePosition(position PositionRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.positions[position.ID] = position
+	return nil
+}
+
+func (s *memStore) AppendLedgerEntry(entry LedgerEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.entries = append(s.entries, entry)
+	if account, ok := s.accounts[entry.AccountID]; ok {
+		account.Balance = entry.BalanceAfter
+		s.accounts[entry.AccountID] = account
+	}
+	return nil
+}
+
+func (s *memStore) SaveBonus(bonus Bonus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.bonuses[bonus.ID] = bonus
+	return nil
+}
+
+func (s *memStore) LoadState() (*StoreState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := &StoreState{LedgerEntries: append([]LedgerEntry(nil), s.entries...)}
+	for _, account := range s.accounts {
+		state.Accounts = append(state.Accounts, account)
+	}
+	for _, position := range s.positions {
+		state.Positions = append(state.Positions, position)
+	}
+	sort.Slice(state.Positions, func(i, j int) bool { return state.Positions[i].ID < state.Positions[j].ID })
+	for _, bonus := range s.bonuses {
+		state.Bonuses = append(state.Bonuses, bonus)
+	}
+	sort.Slice(state.Bonuses, func(i, j int) bool { return state.Bonuses[i].ID < state.Bonuses[j].ID })
+	return state, nil
+}
+
+func (s *memStore) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// flushStore waits for the engine's queued writes to reach the store
+func flushStore(t *testing.T, engine *Engine) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := engine.FlushStore(ctx); err != nil {
+		t.Fatalf("FlushStore() error = %v", err)
+	}
+}
+
+// TestStoreSurvivesRestart tests that a deposit, an open position and a
+// pending bonus written through to the store are loaded by a new engine on the
+// same store
+func TestStoreSurvivesRestart(t *testing.T) {
+	store := newMemStore()
+	engine, account := newTestEngine(t)
+	engine.SetStore(store)
+	engine.PersistAccount(account.ID)
+
+	engine.GetLedger().SetBalance(account.ID, account.Balance)
+	entry, err := engine.GetLedger().Deposit(account.ID, 500, "BANK", "ref-1", "Deposit", "admin")
+	if err != nil {
+		t.Fatalf("Deposit() error = %v", err)
+	}
+	account.Balance = entry.BalanceAfter
+	engine.SetCommissionRateResolver(func(int64, string) (float64, bool) { return 7, true })
+	pos := openTestPosition(t, engine, account.ID, "EURUSD")
+	bonus, err := engine.AddCreditBonus(account.ID, 100, pos.Volume, "Welcome bonus", "admin")
+	if err != nil {
+		t.Fatalf("AddCreditBonus() error = %v", err)
+	}
+	balance := account.Balance
+	flushStore(t, engine)
+
+	// Simulated restart: a new engine on the same store
+	restarted, _ := newTestEngine(t)
+	restarted.SetStore(store)
+	if n, err := restarted.LoadFromStore(); err != nil || n != 1 {
+		t.Fatalf("LoadFromStore() = %d, %v; want 1 account", n, err)
+	}
+
+	loaded, ok := restarted.GetAccount(account.ID)
+	if !ok || loaded.Balance != balance || loaded.Credit != 100 {
+		t.Fatalf("reloaded account = %+v, want balance %.2f and credit 100.00", loaded, balance)
+	}
+	bonuses := restarted.GetBonuses(account.ID)
+	if len(bonuses) != 1 || bonuses[0].ID != bonus.ID || bonuses[0].Status != BonusStatusPending {
+		t.Fatalf("reloaded bonuses = %+v, want pending bonus #%d", bonuses, bonus.ID)
+	}
+	positions := restarted.GetPositions(account.ID)
+	if len(positions) != 1 || positions[0].ID != pos.ID || positions[0].Volume != pos.Volume || positions[0].OpenPrice != pos.OpenPrice {
+		t.Fatalf("reloaded positions = %+v, want position #%d", positions, pos.ID)
+	}
+	if pos.openCommission == 0 || positions[0].openCommission != pos.openCommission {
+		t.Errorf("reloaded opening commission = %.2f, want %.2f", positions[0].openCommission, pos.openCommission)
+	}
+	history := restarted.GetLedger().GetHistory(account.ID, 0)
+	if len(history) == 0 || history[len(history)-1].Type != "DEPOSIT" {
+		t.Errorf("reloaded ledger = %+v, want the deposit first", history)
+	}
+
+	// New records continue past the loaded ones
+	next := openTestPosition(t, restarted, account.ID, "GBPUSD")
+	if next.ID <= pos.ID {
+		t.Errorf("position opened after the restart got ID %d, want above %d", next.ID, pos.ID)
+	}
+	if _, err := restarted.ClosePosition(pos.ID, 0); err != nil {
+		t.Fatalf("ClosePosition() on a reloaded position error = %v", err)
+	}
+	flushStore(t, restarted)
+	if saved := store.positions[pos.ID]; saved.Status != "CLOSED" {
+		t.Errorf("stored position status after close = %s, want CLOSED", saved.Status)
+	}
+
+	// Closing the required volume releases the reloaded bonus
+	if bonuses[0].Status != BonusStatusReleased {
+		t.Errorf("bonus status after close = %s, want %s", bonuses[0].Status, BonusStatusReleased)
+	}
+	if loaded, _ := restarted.GetAccount(account.ID); loaded.Credit != 0 {
+		t.Errorf("credit after release = %.2f, want 0", loaded.Credit)
+	}
+	if saved := store.bonuses[bonus.ID]; saved.Status != BonusStatusReleased || saved.ReleasedAt == nil {
+		t.Errorf("stored bonus after release = %+v, want released", saved)
+	}
+	another, err := restarted.AddCreditBonus(account.ID, 50, 1, "Reload bonus", "admin")
+	if err != nil || another.ID <= bonus.ID {
+		t.Errorf("bonus granted after the restart = %+v, %v; want an ID above %d", another, err, bonus.ID)
+	}
+}
+
+// TestStoreRetriesFailedWrites tests that trading carries on while the store
+// fails, that the failure is reported, and that the held writes reach the
+// store in order once it recovers
+func TestStoreRetriesFailedWrites(t *testing.T) {
+	store := newMemStore()
+	engine, account := newTestEngine(t)
+	engine.SetStore(store)
+	engine.storeWriter.mu.Lock()
+	engine.storeWriter.initialBackoff = time.Millisecond
+	engine.storeWriter.maxBackoff = 5 * time.Millisecond
+	engine.storeWriter.mu.Unlock()
+
+	store.setErr(errors.New("connection refused"))
+	engine.GetLedger().SetBalance(account.ID, account.Balance)
+	engine.PersistAccount(account.ID)
+	if _, err := engine.GetLedger().Deposit(account.ID, 500, "BANK", "ref-1", "Deposit", "admin"); err != nil {
+		t.Fatalf("Deposit() error = %v", err)
+	}
+	pos := openTestPosition(t, engine, account.ID, "EURUSD")
+	if _, err := engine.ClosePosition(pos.ID, 0); err != nil {
+		t.Fatalf("ClosePosition() with a failing store error = %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !engine.StoreStatus().Failing && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	status := engine.StoreStatus()
+	if !status.Failing || status.Pending == 0 || status.LastError != "connection refused" || status.FailingSince.IsZero() {
+		t.Fatalf("StoreStatus() with a failing store = %+v, want failing with writes pending", status)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := engine.FlushStore(ctx); err == nil {
+		t.Errorf("FlushStore() with a failing store error = nil, want the pending writes reported")
+	}
+	if len(store.positions) != 0 || len(store.entries) != 0 {
+		t.Fatalf("store written while failing: %d positions, %d ledger entries", len(store.positions), len(store.entries))
+	}
+
+	store.setErr(nil)
+	flushStore(t, engine)
+	if status := engine.StoreStatus(); status != (StoreStatus{}) {
+		t.Errorf("StoreStatus() after recovery = %+v, want clear", status)
+	}
+
+	if saved := store.positions[pos.ID]; saved.Status != "CLOSED" {
+		t.Errorf("stored position status = %s, want CLOSED", saved.Status)
+	}
+	history := engine.GetLedger().GetHistory(account.ID, 0)
+	if len(store.entries) != len(history) {
+		t.Fatalf("stored %d ledger entries, want %d", len(store.entries), len(history))
+	}
+	for i, entry := range store.entries {
+		if i > 0 && entry.ID <= store.entries[i-1].ID {
+			t.Fatalf("stored ledger entries out of order: #%d after #%d", entry.ID, store.entries[i-1].ID)
+		}
+	}
+	live, _ := engine.GetAccount(account.ID)
+	if saved := store.accounts[account.ID]; saved.Balance != live.Balance {
+		t.Errorf("stored balance = %.2f, want %.2f", saved.Balance, live.Balance)
+	}
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Retry delays of a failing store write, doubled after each failure
+const (
+	storeInitialBackoff = 100 * time.Millisecond
+	storeMaxBackoff     = 10 * time.Second
+)
+
+// StoreStatus reports how far the store is behind the engine
+type StoreStatus struct {
+	Pending      int       `json:"pending"` // Writes queued, including one being retried
+	Failing      bool      `json:"failing"`
+	LastError    string    `json:"lastError,omitempty"`
+	FailingSince time.Time `json:"failingSince,omitempty"`
+}
+
+// storeWrite is one queued write and what it persists, for logging
+type storeWrite struct {
+	what  string
+	apply func(Store) error
+}
+
+// storeWriter applies the engine's writes to the store in order on its own
+// goroutine, so the engine never waits on the database while holding its
+// lock. A failed write is retried with backoff and nothing queued after it
+// is written until it succeeds, so the store never skips a change.
+type storeWriter struct {
+	store          Store
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+
+	mu           sync.Mutex
+	wake         chan struct{}
+	queue        []storeWrite
+	lastErr      error
+	failingSince time.Time
+}
+
+func newStoreWriter(store Store) *storeWriter {
+	w := &storeWriter{
+		store:          store,
+		initialBackoff: storeInitialBackoff,
+		maxBackoff:     storeMaxBackoff,
+		wake:           make(chan struct{}, 1),
+	}
+	go w.run()
+	return w
+}
+
+// enqueue queues a write behind every earlier one
+func (w *storeWriter) enqueue(what string, apply func(Store) error) {
+	w.mu.Lock()
+	w.queue = append(w.queue, storeWrite{what: what, apply: apply})
+	w.mu.Unlock()
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *storeWriter) run() {
+	var backoff time.Duration // Zero until a write fails
+	for {
+		w.mu.Lock()
+		if len(w.queue) == 0 {
+			w.mu.Unlock()
+			<-w.wake
+			continue
+		}
+		write := w.queue[0]
+		w.mu.Unlock()
+
+		if err := write.apply(w.store); err != nil {
+			w.mu.Lock()
+			if w.lastErr == nil {
+				w.failingSince = time.Now()
+				log.Printf("[B-Book] Store write failing, holding %d writes until it recovers: %s: %v",
+					len(w.queue), write.what, err)
+			}
+			w.lastErr = err
+			if backoff == 0 {
+				backoff = w.initialBackoff
+			} else if backoff *= 2; backoff > w.maxBackoff {
+				backoff = w.maxBackoff
+			}
+			w.mu.Unlock()
+
+			time.Sleep(backoff)
+			continue
+		}
+		backoff = 0
+
+		w.mu.Lock()
+		w.queue[0] = storeWrite{}
+		w.queue = w.queue[1:]
+		if w.lastErr != nil {
+			log.Printf("[B-Book] Store recovered after %v", time.Since(w.failingSince).Round(time.Millisecond))
+			w.lastErr = nil
+			w.failingSince = time.Time{}
+		}
+		w.mu.Unlock()
+	}
+}
+
+// status returns the writer's backlog and failure state
+func (w *storeWriter) status() StoreStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	status := StoreStatus{Pending: len(w.queue), Failing: w.lastErr != nil, FailingSince: w.failingSince}
+	if w.lastErr != nil {
+		status.LastError = w.lastErr.Error()
+	}
+	return status
+}
+
+// flush waits until every queued write has been applied
+func (w *storeWriter) flush(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		status := w.status()
+		if status.Pending == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			if status.Failing {
+				return fmt.Errorf("%d store writes pending: %s", status.Pending, status.LastError)
+			}
+			return fmt.Errorf("%d store writes pending: %w", status.Pending, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Symbol suspension policies for existing positions
+const (
+	SuspendPolicyFreeze     = "FREEZE"      // No new orders; open positions held and may still be closed/modified
+	SuspendPolicyForceClose = "FORCE_CLOSE" // No new orders; all open positions liquidated at market
+	SuspendPolicyReadOnly   = "READONLY"    // No new orders; open positions cannot be closed or modified
+)
+
+// ErrSymbolSuspended is returned when an operation is blocked by a symbol suspension
+var ErrSymbolSuspended = errors.New("symbol is suspended")
+
+// ErrForceCloseIncomplete is returned when a force-close suspension could not
+// close every position; the symbol is frozen unless it was already suspended
+var ErrForceCloseIncomplete = errors.New("force-close incomplete")
+
+// SuspensionResult describes the outcome of suspending a symbol
+type SuspensionResult struct {
+	Symbol          string          `json:"symbol"`
+	Policy          string          `json:"policy"`
+	PreviousPolicy  string          `json:"previousPolicy,omitempty"`
+	OpenPositions   int             `json:"openPositions"` // Open positions on the symbol at suspension time
+	ClosedPositions []Trade         `json:"closedPositions,omitempty"`
+	FailedPositions []BulkCloseItem `json:"failedPositions,omitempty"` // Positions force-close could not close
+	Suspended       bool            `json:"suspended"`
+	SuspendedAt     time.Time       `json:"suspendedAt"`
+}
+
+// ValidSuspendPolicy reports whether policy is a known suspension policy
+func ValidSuspendPolicy(policy string) bool {
+	switch policy {
+	case SuspendPolicyFreeze, SuspendPolicyForceClose, SuspendPolicyReadOnly:
+		return true
+	}
+	return false
+}
+
+// SuspendSymbol suspends trading on a symbol and applies the policy to open positions.
+// The whole operation is applied under the engine lock: if the symbol has no
+// usable price, nothing is changed. If force-close fails on some positions, the
+// ones closed stay closed and the result lists the failures alongside
+// ErrForceCloseIncomplete, so the call can be retried. The symbol never stays
+// tradable meanwhile: it keeps a previous suspension or is frozen.
+func (e *Engine) SuspendSymbol(symbol, policy string) (*SuspensionResult, error) {
+	if !ValidSuspendPolicy(policy) {
+		return nil, fmt.Errorf("invalid suspend policy: %s", policy)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	spec, ok := e.symbols[symbol]
+	if !ok {
+		return nil, fmt.Errorf("symbol %s not found", symbol)
+	}
+
+	var open []*Position
+	for _, pos := range e.positions {
+		if pos.Status == "OPEN" && pos.Symbol == symbol {
+			open = append(open, pos)
+		}
+	}
+
+	// Verify every position can be closed before touching state
+	if policy == SuspendPolicyForceClose && len(open) > 0 {
+		if e.priceCallback == nil {
+			return nil, errors.New("price feed not available")
+		}
+		if _, _, ok := e.priceCallback(symbol); !ok {
+			return nil, fmt.Errorf("no price available for %s", symbol)
+		}
+		if e.isQuoteStale(symbol) {
+			return nil, fmt.Errorf("price for %s is stale, waiting for live quotes", symbol)
+		}
+	}
+
+	result := &SuspensionResult{
+		Symbol:         symbol,
+		Policy:         policy,
+		PreviousPolicy: spec.SuspendPolicy,
+		OpenPositions:  len(open),
+		SuspendedAt:    time.Now(),
+	}
+
+	if policy == SuspendPolicyForceClose {
+		for _, pos := range open {
+			trade, err := e.closePositionUnlocked(pos, 0)
+			if err != nil {
+				item := bulkCloseItem(pos)
+				item.Error = err.Error()
+				result.FailedPositions = append(result.FailedPositions, item)
+				continue
+			}
+			result.ClosedPositions = append(result.ClosedPositions, *trade)
+		}
+		if len(result.FailedPositions) > 0 {
+			if spec.SuspendPolicy == "" {
+				spec.SuspendPolicy = SuspendPolicyFreeze
+			}
+			result.Policy = spec.SuspendPolicy
+			result.Suspended = true
+			log.Printf("[B-Book] Symbol %s left %s: force-close failed for %d of %d positions",
+				symbol, spec.SuspendPolicy, len(result.FailedPositions), len(open))
+			return result, fmt.Errorf("%w: %d of %d positions on %s could not be closed",
+				ErrForceCloseIncomplete, len(result.FailedPositions), len(open), symbol)
+		}
+	}
+
+	spec.SuspendPolicy = policy
+	result.Suspended = true
+
+	log.Printf("[B-Book] Symbol %s SUSPENDED (policy=%s, open positions=%d, closed=%d)",
+		symbol, policy, len(open), len(result.ClosedPositions))
+
+	return result, nil
+}
+
+// ResumeSymbol lifts a suspension and re-enables trading on a symbol
+func (e *Engine) ResumeSymbol(symbol string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	spec, ok := e.symbols[symbol]
+	if !ok {
+		return fmt.Errorf("symbol %s not found", symbol)
+	}
+	if spec.SuspendPolicy == "" {
+		return fmt.Errorf("symbol %s is not suspended", symbol)
+	}
+
+	spec.SuspendPolicy = ""
+	log.Printf("[B-Book] Symbol %s RESUMED", symbol)
+	return nil
+}
+
+// checkCanOpen rejects new orders on suspended symbols (caller must hold lock)
+func (e *Engine) checkCanOpen(spec *SymbolSpec) error {
+	if spec.SuspendPolicy != "" {
+		return fmt.Errorf("%w: %s (%s)", ErrSymbolSuspended, spec.Symbol, spec.SuspendPolicy)
+	}
+	return nil
+}
+
+// checkCanModify rejects changes to positions on read-only symbols (caller must hold lock)
+func (e *Engine) checkCanModify(symbol string) error {
+	spec, ok := e.symbols[symbol]
+	if ok && spec.SuspendPolicy == SuspendPolicyReadOnly {
+		return fmt.Errorf("%w: %s is read-only", ErrSymbolSuspended, symbol)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+// newTestEngine returns an engine with one funded account and fixed quotes
+func newTestEngine(t *testing.T) (*Engine, *Account) {
+	t.Helper()
+
+	engine := NewEngine()
+	prices := map[string][2]float64{
+		"EURUSD": {1.1000, 1.1002},
+		"GBPUSD": {1.2500, 1.2502},
+	}
+	engine.SetPriceCallback(func(symbol string) (float64, float64, bool) {
+		p, ok := prices[symbol]
+		return p[0], p[1], ok
+	})
+
+	account := engine.CreateAccount("user1", "trader", "password", true)
+	account.Balance = 10000
+	return engine, account
+}
+
+func openTestPosition(t *testing.T, engine *Engine, accountID int64, symbol string) *Position {
+	t.Helper()
+	pos, err := engine.ExecuteMarketOrder(accountID, symbol, "BUY", 0.1, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	return pos
+}
+
+// TestSuspendFreezeBlocksNewOrders tests that freeze rejects orders but holds positions
+func TestSuspendFreezeBlocksNewOrders(t *testing.T) {
+	engine, account := newTestEngine(t)
+	pos := openTestPosition(t, engine, account.ID, "EURUSD")
+
+	result, err := engine.SuspendSymbol("EURUSD", SuspendPolicyFreeze)
+	if err != nil {
+		t.Fatalf("SuspendSymbol() error = %v", err)
+	}
+	if result.OpenPositions != 1 || len(result.ClosedPositions) != 0 {
+		t.Errorf("result = %d open / %d closed, want 1 / 0", result.OpenPositions, len(result.ClosedPositions))
+	}
+
+	_, err = engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0)
+	if !errors.Is(err, ErrSymbolSuspended) {
+		t.Errorf("ExecuteMarketOrder() error = %v, want ErrSymbolSuspended", err)
+	}
+
+	// Other symbols are unaffected
+	openTestPosition(t, engine, account.ID, "GBPUSD")
+
+	if pos.Status != "OPEN" {
+		t.Errorf("position status = %s, want OPEN", pos.Status)
+	}
+	if _, err := engine.ModifyPosition(pos.ID, 1.0900, 0); err != nil {
+		t.Errorf("ModifyPosition() error = %v, want nil under freeze", err)
+	}
+
+	// Resume re-enables trading
+	if err := engine.ResumeSymbol("EURUSD"); err != nil {
+		t.Fatalf("ResumeSymbol() error = %v", err)
+	}
+	openTestPosition(t, engine, account.ID, "EURUSD")
+}
+
+// TestSuspendForceCloseLiquidates tests that force-close closes every open position on the symbol
+func TestSuspendForceCloseLiquidates(t *testing.T) {
+	engine, account := newTestEngine(t)
+	p1 := openTestPosition(t, engine, account.ID, "EURUSD")
+	p2 := openTestPosition(t, engine, account.ID, "EURUSD")
+	other := openTestPosition(t, engine, account.ID, "GBPUSD")
+
+	result, err := engine.SuspendSymbol("EURUSD", SuspendPolicyForceClose)
+	if err != nil {
+		t.Fatalf("SuspendSymbol() error = %v", err)
+	}
+	if len(result.ClosedPositions) != 2 {
+		t.Errorf("closed positions = %d, want 2", len(result.ClosedPositions))
+	}
+
+	for _, pos := range []*Position{p1, p2} {
+		if pos.Status != "CLOSED" {
+			t.Errorf("position #%d status = %s, want CLOSED", pos.ID, pos.Status)
+		}
+		if pos.ClosePrice != 1.1000 {
+			t.Errorf("position #%d close price = %.5f, want 1.10000", pos.ID, pos.ClosePrice)
+		}
+	}
+	if other.Status != "OPEN" {
+		t.Errorf("GBPUSD position status = %s, want OPEN", other.Status)
+	}
+
+	_, err = engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 0.1, 0, 0)
+	if !errors.Is(err, ErrSymbolSuspended) {
+		t.Errorf("ExecuteMarketOrder() error = %v, want ErrSymbolSuspended", err)
+	}
+}
+
+// TestSuspendForceCloseReportsFailures tests that a force-close the feed cuts
+// short reports the positions it could not close and freezes the symbol
+func TestSuspendForceCloseReportsFailures(t *testing.T) {
+	engine, account := newTestEngine(t)
+	p1 := openTestPosition(t, engine, account.ID, "EURUSD")
+	p2 := openTestPosition(t, engine, account.ID, "EURUSD")
+
+	// The quote goes stale after the pre-check and the first close
+	checks := 0
+	engine.SetStaleQuoteCallback(func(symbol string) bool {
+		checks++
+		return checks > 2
+	})
+
+	result, err := engine.SuspendSymbol("EURUSD", SuspendPolicyForceClose)
+	if !errors.Is(err, ErrForceCloseIncomplete) {
+		t.Fatalf("SuspendSymbol() error = %v, want ErrForceCloseIncomplete", err)
+	}
+	if result == nil || len(result.ClosedPositions) != 1 || len(result.FailedPositions) != 1 ||
+		!result.Suspended || result.Policy != SuspendPolicyFreeze {
+		t.Fatalf("result = %+v, want one closed, one failed and frozen", result)
+	}
+	closed, failed := p1, p2
+	if p1.Status == "OPEN" {
+		closed, failed = p2, p1
+	}
+	if closed.Status != "CLOSED" || failed.Status != "OPEN" {
+		t.Errorf("statuses = %s/%s, want CLOSED/OPEN", closed.Status, failed.Status)
+	}
+	if result.FailedPositions[0].PositionID != failed.ID || result.FailedPositions[0].Error == "" {
+		t.Errorf("failed position = %+v, want #%d with its error", result.FailedPositions[0], failed.ID)
+	}
+	for _, s := range engine.GetSymbols() {
+		if s.Symbol == "EURUSD" && s.SuspendPolicy != SuspendPolicyFreeze {
+			t.Errorf("SuspendPolicy = %q, want %s", s.SuspendPolicy, SuspendPolicyFreeze)
+		}
+	}
+
+	// New orders are rejected while the remaining position is open
+	engine.SetStaleQuoteCallback(nil)
+	_, err = engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0)
+	if !errors.Is(err, ErrSymbolSuspended) {
+		t.Errorf("ExecuteMarketOrder() error = %v, want ErrSymbolSuspended", err)
+	}
+
+	// Retrying once quotes are live closes the rest and suspends
+	result, err = engine.SuspendSymbol("EURUSD", SuspendPolicyForceClose)
+	if err != nil || !result.Suspended || result.Policy != SuspendPolicyForceClose || len(result.ClosedPositions) != 1 {
+		t.Fatalf("retry = %+v, %v, want the remaining position closed and suspended", result, err)
+	}
+}
+
+// TestSuspendForceCloseWithoutPriceIsAtomic tests that a failed force-close leaves the symbol untouched
+func TestSuspendForceCloseWithoutPriceIsAtomic(t *testing.T) {
+	engine, account := newTestEngine(t)
+	pos := openTestPosition(t, engine, account.ID, "EURUSD")
+
+	engine.SetPriceCallback(func(symbol string) (float64, float64, bool) {
+		return 0, 0, false
+	})
+
+	if _, err := engine.SuspendSymbol("EURUSD", SuspendPolicyForceClose); err == nil {
+		t.Fatal("SuspendSymbol() expected error without price")
+	}
+	if pos.Status != "OPEN" {
+		t.Errorf("position status = %s, want OPEN", pos.Status)
+	}
+	for _, s := range engine.GetSymbols() {
+		if s.Symbol == "EURUSD" && s.SuspendPolicy != "" {
+			t.Errorf("SuspendPolicy = %q, want empty", s.SuspendPolicy)
+		}
+	}
+}
+
+// TestSuspendReadOnlyBlocksModifications tests that read-only blocks closes and SL/TP changes
+func TestSuspendReadOnlyBlocksModifications(t *testing.T) {
+	engine, account := newTestEngine(t)
+	pos := openTestPosition(t, engine, account.ID, "EURUSD")
+
+	if _, err := engine.SuspendSymbol("EURUSD", SuspendPolicyReadOnly); err != nil {
+		t.Fatalf("SuspendSymbol() error = %v", err)
+	}
+
+	if _, err := engine.ModifyPosition(pos.ID, 1.0900, 1.1100); !errors.Is(err, ErrSymbolSuspended) {
+		t.Errorf("ModifyPosition() error = %v, want ErrSymbolSuspended", err)
+	}
+	if _, err := engine.ClosePosition(pos.ID, 0); !errors.Is(err, ErrSymbolSuspended) {
+		t.Errorf("ClosePosition() error = %v, want ErrSymbolSuspended", err)
+	}
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0); !errors.Is(err, ErrSymbolSuspended) {
+		t.Errorf("ExecuteMarketOrder() error = %v, want ErrSymbolSuspended", err)
+	}
+
+	if pos.Status != "OPEN" || pos.SL != 0 || pos.TP != 0 {
+		t.Errorf("position changed: status=%s SL=%.5f TP=%.5f", pos.Status, pos.SL, pos.TP)
+	}
+}
+
+// TestSuspendInvalidPolicy tests policy validation
+func TestSuspendInvalidPolicy(t *testing.T) {
+	engine, _ := newTestEngine(t)
+
+	if _, err := engine.SuspendSymbol("EURUSD", "PAUSE"); err == nil {
+		t.Error("SuspendSymbol() expected error for unknown policy")
+	}
+	if _, err := engine.SuspendSymbol("NOSUCH", SuspendPolicyFreeze); err == nil {
+		t.Error("SuspendSymbol() expected error for unknown symbol")
+	}
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"encoding/json"
 	"log"
+	"math"
 	"os"
 	"strings"
 )
@@ -106,10 +107,11 @@ func GenerateSymbolSpec(symbol string) *SymbolSpec {
 	symbol = strings.ToUpper(symbol)
 
 	spec := &SymbolSpec{
-		Symbol:     symbol,
-		MinVolume:  0.01,
-		MaxVolume:  100,
-		VolumeStep: 0.01,
+		Symbol:          symbol,
+		MinVolume:       0.01,
+		MaxVolume:       100,
+		VolumeStep:      0.01,
+		CommissionModel: CommissionModelCommission,
 	}
 
 	// Determine pip size based on symbol pattern
@@ -208,9 +210,25 @@ func GenerateSymbolSpec(symbol string) *SymbolSpec {
 		spec.PipValue = 10
 	}
 
+	spec.Digits = priceDigits(category, spec.PipSize)
+
 	return spec
 }
 
+// priceDigits returns the quoted decimal places for a pip size. Forex quotes
+// in fractional pips, one digit beyond the pip.
+func priceDigits(category SymbolCategory, pipSize float64) int {
+	digits := 0
+	if pipSize > 0 && pipSize < 1 {
+		digits = int(math.Round(-math.Log10(pipSize)))
+	}
+	switch category {
+	case CategoryForexMajor, CategoryForexMinor, CategoryForexExotic:
+		digits++
+	}
+	return digits
+}
+
 // LoadSymbolsFromDirectory scans tick data directory and auto-generates specs
 // Tick data is stored in directories named after the symbol
 func (e *Engine) LoadSymbolsFromDirectory(tickDataDir string) error {
@@ -256,6 +274,9 @@ func (e *Engine) LoadSymbolsFromJSON(path string) error {
 
 	for _, spec := range config.Symbols {
 		specCopy := spec
+		if specCopy.Digits == 0 {
+			specCopy.Digits = priceDigits(DetectSymbolCategory(spec.Symbol), spec.PipSize)
+		}
 		e.symbols[spec.Symbol] = &specCopy
 	}
 
@@ -321,3 +342,75 @@ func (e *Engine) GetOrCreateSymbol(symbol string) *SymbolSpec {
 func (e *Engine) DiscoverSymbolsFromFIX(symbol string) {
 	e.GetOrCreateSymbol(symbol)
 }
+
+// LPSymbolSpec is a symbol specification reported by a liquidity provider.
+// Quantities are in base currency units, as on the FIX wire; zero fields are
+// not reported and keep the configured values.
+type LPSymbolSpec struct {
+	TickSize     float64 // Minimum price increment
+	ContractSize float64 // Units per lot
+	MinQty       float64 // Minimum order size in units
+	QtyStep      float64 // Order size increment in units (round lot)
+	MaxQty       float64 // Maximum order size in units
+	Currency     string  // Quote currency
+}
+
+// ApplyLPSymbolSpec overrides a symbol's specification with the values its LP
+// reports, registering the symbol if it is unknown. Fields the LP omits fall
+// back to the configured or generated spec. The registry gets an updated copy,
+// so specs already handed out never change underneath their readers, and the
+// contract size is kept while positions are open on the symbol, since their
+// P/L and margin were computed with it.
+func (e *Engine) ApplyLPSymbolSpec(symbol string, lp LPSymbolSpec) *SymbolSpec {
+	e.GetOrCreateSymbol(symbol)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	spec := new(SymbolSpec)
+	*spec = *e.symbols[symbol]
+
+	if lp.ContractSize > 0 && lp.ContractSize != spec.ContractSize {
+		if open := e.openPositionCountUnlocked(symbol); open > 0 {
+			log.Printf("[B-Book] Symbol %s: keeping contract size %.0f, LP reports %.0f but %d positions are open",
+				spec.Symbol, spec.ContractSize, lp.ContractSize, open)
+		} else {
+			spec.ContractSize = lp.ContractSize
+		}
+	}
+	if lp.TickSize > 0 && lp.TickSize < 1 {
+		spec.Digits = int(math.Round(-math.Log10(lp.TickSize)))
+	} else if lp.TickSize >= 1 {
+		spec.Digits = 0
+	}
+	if spec.ContractSize > 0 {
+		if lp.MinQty > 0 {
+			spec.MinVolume = lp.MinQty / spec.ContractSize
+		}
+		if lp.QtyStep > 0 {
+			spec.VolumeStep = lp.QtyStep / spec.ContractSize
+		}
+		if lp.MaxQty > 0 {
+			spec.MaxVolume = lp.MaxQty / spec.ContractSize
+		}
+	}
+	if lp.Currency != "" {
+		spec.Currency = lp.Currency
+	}
+	e.symbols[symbol] = spec
+
+	log.Printf("[B-Book] Symbol %s spec from LP: digits=%d contractSize=%.0f minVolume=%.2f step=%.2f",
+		spec.Symbol, spec.Digits, spec.ContractSize, spec.MinVolume, spec.VolumeStep)
+	return spec
+}
+
+// openPositionCountUnlocked counts the open positions on symbol (caller must hold lock)
+func (e *Engine) openPositionCountUnlocked(symbol string) int {
+	open := 0
+	for _, pos := range e.positions {
+		if pos.Status == "OPEN" && pos.Symbol == symbol {
+			open++
+		}
+	}
+	return open
+}
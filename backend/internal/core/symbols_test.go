@@ -0,0 +1,67 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+// TestApplyLPSymbolSpecOverridesRegistry tests that LP-reported fields override the
+// configured spec and omitted fields fall back to it
+func TestApplyLPSymbolSpecOverridesRegistry(t *testing.T) {
+	engine := NewEngine()
+	configured := *engine.GetOrCreateSymbol("XAUUSD")
+
+	spec := engine.ApplyLPSymbolSpec("XAUUSD", LPSymbolSpec{
+		TickSize:     0.001,
+		ContractSize: 10,
+		MinQty:       1,
+		Currency:     "USD",
+	})
+
+	got, ok := engine.GetSymbol("XAUUSD")
+	if !ok || got != spec {
+		t.Fatal("registry does not hold the updated XAUUSD spec")
+	}
+	if got.Digits != 3 || got.ContractSize != 10 || got.Currency != "USD" {
+		t.Errorf("digits = %d, contract size = %.0f, currency = %q; want 3 / 10 / USD", got.Digits, got.ContractSize, got.Currency)
+	}
+	if math.Abs(got.MinVolume-0.1) > 1e-12 {
+		t.Errorf("min volume = %.4f lots, want 0.1 (1 unit of a 10-unit lot)", got.MinVolume)
+	}
+	// The LP did not report a round lot or max size
+	if got.VolumeStep != configured.VolumeStep || got.MaxVolume != configured.MaxVolume || got.PipSize != configured.PipSize {
+		t.Errorf("step/max/pip = %g/%g/%g, want configured %g/%g/%g",
+			got.VolumeStep, got.MaxVolume, got.PipSize, configured.VolumeStep, configured.MaxVolume, configured.PipSize)
+	}
+
+	// Unknown symbols are registered from the LP definition
+	spec = engine.ApplyLPSymbolSpec("EURNOK", LPSymbolSpec{TickSize: 0.00001, ContractSize: 100000})
+	if spec.Digits != 5 || spec.ContractSize != 100000 {
+		t.Errorf("EURNOK digits = %d, contract size = %.0f; want 5 / 100000", spec.Digits, spec.ContractSize)
+	}
+}
+
+// TestApplyLPSymbolSpecCopiesOnWrite tests that an LP update replaces the
+// registry entry instead of mutating specs already handed out, and keeps the
+// contract size of a symbol with open positions
+func TestApplyLPSymbolSpecCopiesOnWrite(t *testing.T) {
+	engine, account := newTestEngine(t)
+	before, _ := engine.GetSymbol("EURUSD")
+	contractSize, digits := before.ContractSize, before.Digits
+	openTestPosition(t, engine, account.ID, "EURUSD")
+
+	spec := engine.ApplyLPSymbolSpec("EURUSD", LPSymbolSpec{TickSize: 0.001, ContractSize: 1000})
+
+	if before.ContractSize != contractSize || before.Digits != digits {
+		t.Errorf("earlier spec changed to contract size %.0f / digits %d", before.ContractSize, before.Digits)
+	}
+	if spec == before {
+		t.Fatal("ApplyLPSymbolSpec() mutated the registered spec in place")
+	}
+	if spec.ContractSize != contractSize {
+		t.Errorf("contract size = %.0f with an open position, want %.0f kept", spec.ContractSize, contractSize)
+	}
+	if spec.Digits != 3 {
+		t.Errorf("digits = %d, want 3 from the LP tick size", spec.Digits)
+	}
+}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+// TPLevel is one tranche of a take-profit ladder
+type TPLevel struct {
+	Price    float64   `json:"price"`
+	Fraction float64   `json:"fraction"` // Share of the position's volume when the ladder was set
+	Volume   float64   `json:"volume"`   // Lots closed when Price is reached
+	Filled   bool      `json:"filled"`
+	TradeID  int64     `json:"tradeId,omitempty"` // Closing trade of the tranche
+	FilledAt time.Time `json:"filledAt,omitempty"`
+}
+
+// TPLadder scales a position out at several take-profit levels, nearest first
+type TPLadder struct {
+	Levels        []*TPLevel `json:"levels"`
+	TrailDistance float64    `json:"trailDistance,omitempty"` // Once every level has filled, trail the residual's SL this far behind price
+	Trailing      bool       `json:"trailing,omitempty"`
+}
+
+// SetTPLadder configures take-profit tranches on an open position, replacing
+// its single TP. Fractions are of the current volume and may sum to less than
+// 1, leaving a residual that keeps its SL, or trails it by trailDistance once
+// the last level has filled.
+func (e *Engine) SetTPLadder(positionID int64, levels []TPLevel, trailDistance float64) (*Position, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	position, ok := e.positions[positionID]
+	if !ok {
+		return nil, errors.New("position not found")
+	}
+	if position.Status != "OPEN" {
+		return nil, errors.New("position is not open")
+	}
+	if err := e.checkCanModify(position.Symbol); err != nil {
+		return nil, err
+	}
+	if len(levels) == 0 {
+		return nil, errors.New("TP ladder needs at least one level")
+	}
+	if trailDistance < 0 {
+		return nil, errors.New("trail distance cannot be negative")
+	}
+
+	ladder, err := buildTPLadder(position, e.symbols[position.Symbol], levels)
+	if err != nil {
+		return nil, err
+	}
+	ladder.TrailDistance = trailDistance
+
+	position.TPLadder = ladder
+	position.TP = 0
+	e.persistPositionUnlocked(position)
+
+	log.Printf("[B-Book] TP ladder set on Position #%d: %d levels, trail %.5f", positionID, len(ladder.Levels), trailDistance)
+	return position, nil
+}
+
+// buildTPLadder validates the levels, orders them nearest first and sizes each tranche
+func buildTPLadder(position *Position, spec *SymbolSpec, levels []TPLevel) (*TPLadder, error) {
+	sorted := make([]*TPLevel, len(levels))
+	total := 0.0
+	for i, level := range levels {
+		if level.Price <= 0 {
+			return nil, errors.New("TP ladder price must be positive")
+		}
+		if (position.Side == "BUY" && level.Price <= position.OpenPrice) ||
+			(position.Side == "SELL" && level.Price >= position.OpenPrice) {
+			return nil, fmt.Errorf("TP ladder price %.5f is not in profit for a %s opened at %.5f",
+				level.Price, position.Side, position.OpenPrice)
+		}
+		if level.Fraction <= 0 || level.Fraction > 1 {
+			return nil, fmt.Errorf("TP ladder fraction must be between 0 and 1, got %.2f", level.Fraction)
+		}
+		total += level.Fraction
+		sorted[i] = &TPLevel{Price: level.Price, Fraction: level.Fraction}
+	}
+	if total > 1+1e-9 {
+		return nil, fmt.Errorf("TP ladder fractions sum to %.2f, more than the whole position", total)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if position.Side == "BUY" {
+			return sorted[i].Price < sorted[j].Price
+		}
+		return sorted[i].Price > sorted[j].Price
+	})
+
+	for _, level := range sorted {
+		volume := position.Volume * level.Fraction
+		if spec != nil && spec.VolumeStep > 0 {
+			// Round down to the volume step, tolerating float noise
+			volume = math.Floor(volume/spec.VolumeStep+1e-9) * spec.VolumeStep
+		}
+		if volume <= 0 || (spec != nil && volume < spec.MinVolume) {
+			return nil, fmt.Errorf("TP ladder tranche of %.0f%% at %.5f is below the minimum volume",
+				level.Fraction*100, level.Price)
+		}
+		level.Volume = volume
+	}
+	return &TPLadder{Levels: sorted}, nil
+}
+
+// checkTPLadderUnlocked closes every tranche whose level the price has reached,
+// nearest first, then trails the residual's SL (caller must hold lock)
+func (e *Engine) checkTPLadderUnlocked(position *Position, currentPrice float64) {
+	ladder := position.TPLadder
+	if ladder == nil {
+		return
+	}
+
+	for i, level := range ladder.Levels {
+		if level.Filled {
+			continue
+		}
+		if (position.Side == "BUY" && currentPrice < level.Price) || (position.Side == "SELL" && currentPrice > level.Price) {
+			break
+		}
+
+		// A tranche covering what is left closes the position outright
+		volume := level.Volume
+		if volume >= position.Volume-1e-9 {
+			volume = 0
+		}
+		trade, err := e.closePositionUnlocked(position, volume)
+		if err != nil {
+			log.Printf("[B-Book] Failed to execute TP ladder level %d for #%d: %v", i+1, position.ID, err)
+			return
+		}
+
+		level.Filled = true
+		level.TradeID = trade.ID
+		level.FilledAt = trade.ExecutedAt
+		e.persistPositionUnlocked(position)
+		log.Printf("[B-Book] TP ladder level %d/%d hit for Position #%d @ %.5f: closed %.2f lots",
+			i+1, len(ladder.Levels), position.ID, currentPrice, trade.Volume)
+
+		if position.Status != "OPEN" {
+			return
+		}
+		if i == len(ladder.Levels)-1 && ladder.TrailDistance > 0 {
+			ladder.Trailing = true
+		}
+	}
+
+	if ladder.Trailing {
+		trailStop(position, currentPrice, ladder.TrailDistance)
+	}
+}
+
+// trailStop moves the SL to distance behind price, only ever tightening it
+func trailStop(position *Position, currentPrice, distance float64) {
+	if position.Side == "BUY" {
+		if stop := currentPrice - distance; stop > position.SL {
+			position.SL = stop
+		}
+		return
+	}
+	if stop := currentPrice + distance; position.SL == 0 || stop < position.SL {
+		position.SL = stop
+	}
+}
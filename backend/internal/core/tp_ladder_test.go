@@ -0,0 +1,126 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+// movePrice sets the quote the engine closes at and runs the tick-driven exits
+func movePrice(engine *Engine, prices map[string][2]float64, symbol string, bid, ask float64) {
+	prices[symbol] = [2]float64{bid, ask}
+	engine.UpdatePrice(symbol, bid, ask)
+}
+
+// newLadderEngine returns a test engine whose quotes movePrice can change
+func newLadderEngine(t *testing.T) (*Engine, *Account, map[string][2]float64) {
+	t.Helper()
+	engine, account := newTestEngine(t)
+	prices := map[string][2]float64{"EURUSD": {1.1000, 1.1002}}
+	engine.SetPriceCallback(func(symbol string) (float64, float64, bool) {
+		p, ok := prices[symbol]
+		return p[0], p[1], ok
+	})
+	return engine, account, prices
+}
+
+// TestTPLadderClosesTranchesInOrder tests that reaching TP1 then TP2 closes the
+// configured fractions and the residual keeps its SL
+func TestTPLadderClosesTranchesInOrder(t *testing.T) {
+	engine, account, prices := newLadderEngine(t)
+	pos, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 1.0, 1.0950, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+
+	// Levels are ordered nearest first regardless of input order
+	if _, err := engine.SetTPLadder(pos.ID, []TPLevel{
+		{Price: 1.1100, Fraction: 0.25},
+		{Price: 1.1050, Fraction: 0.5},
+	}, 0); err != nil {
+		t.Fatalf("SetTPLadder() error = %v", err)
+	}
+
+	movePrice(engine, prices, "EURUSD", 1.1040, 1.1042)
+	if pos.Volume != 1.0 {
+		t.Fatalf("volume below TP1 = %.2f, want 1.00", pos.Volume)
+	}
+
+	movePrice(engine, prices, "EURUSD", 1.1050, 1.1052)
+	tp1 := pos.TPLadder.Levels[0]
+	if !tp1.Filled || tp1.Price != 1.1050 || math.Abs(pos.Volume-0.5) > 1e-9 {
+		t.Fatalf("after TP1: level %+v, volume %.2f, want 0.50 left", tp1, pos.Volume)
+	}
+
+	movePrice(engine, prices, "EURUSD", 1.1101, 1.1103)
+	tp2 := pos.TPLadder.Levels[1]
+	if !tp2.Filled || math.Abs(pos.Volume-0.25) > 1e-9 {
+		t.Fatalf("after TP2: level %+v, volume %.2f, want 0.25 left", tp2, pos.Volume)
+	}
+
+	if pos.Status != "OPEN" || pos.SL != 1.0950 {
+		t.Errorf("residual = %s with SL %.5f, want open with SL 1.09500", pos.Status, pos.SL)
+	}
+
+	// Each tranche is recorded as its own closing trade
+	var closes []Trade
+	for _, trade := range engine.GetTrades(account.ID) {
+		if trade.Side == "CLOSE_BUY" {
+			closes = append(closes, trade)
+		}
+	}
+	if len(closes) != 2 || closes[0].ID != tp1.TradeID || closes[0].Volume != 0.5 || closes[0].Price != 1.1050 ||
+		closes[1].ID != tp2.TradeID || closes[1].Volume != 0.25 || closes[1].Price != 1.1101 {
+		t.Errorf("closing trades = %+v", closes)
+	}
+}
+
+// TestTPLadderTrailsResidual tests that after the last level the residual's SL
+// follows price on a short and never loosens
+func TestTPLadderTrailsResidual(t *testing.T) {
+	engine, account, prices := newLadderEngine(t)
+	pos, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "SELL", 1.0, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	if _, err := engine.SetTPLadder(pos.ID, []TPLevel{{Price: 1.0950, Fraction: 0.5}}, 0.0020); err != nil {
+		t.Fatalf("SetTPLadder() error = %v", err)
+	}
+
+	movePrice(engine, prices, "EURUSD", 1.0948, 1.0950)
+	if math.Abs(pos.Volume-0.5) > 1e-9 || math.Abs(pos.SL-1.0970) > 1e-9 {
+		t.Fatalf("after TP1: volume %.2f SL %.5f, want 0.50 trailing at 1.09700", pos.Volume, pos.SL)
+	}
+
+	movePrice(engine, prices, "EURUSD", 1.0928, 1.0930)
+	if math.Abs(pos.SL-1.0950) > 1e-9 {
+		t.Errorf("SL after a favourable move = %.5f, want 1.09500", pos.SL)
+	}
+	movePrice(engine, prices, "EURUSD", 1.0938, 1.0940)
+	if math.Abs(pos.SL-1.0950) > 1e-9 || pos.Status != "OPEN" {
+		t.Errorf("SL after a pullback = %.5f (%s), want it held at 1.09500", pos.SL, pos.Status)
+	}
+}
+
+// TestTPLadderValidation tests rejected ladders
+func TestTPLadderValidation(t *testing.T) {
+	engine, account, _ := newLadderEngine(t)
+	pos := openTestPosition(t, engine, account.ID, "EURUSD")
+
+	tests := []struct {
+		name   string
+		levels []TPLevel
+	}{
+		{"no levels", nil},
+		{"price in loss", []TPLevel{{Price: 1.0900, Fraction: 0.5}}},
+		{"fractions over 100%", []TPLevel{{Price: 1.1050, Fraction: 0.6}, {Price: 1.1100, Fraction: 0.5}}},
+		{"tranche below minimum volume", []TPLevel{{Price: 1.1050, Fraction: 0.01}}},
+	}
+	for _, tt := range tests {
+		if _, err := engine.SetTPLadder(pos.ID, tt.levels, 0); err == nil {
+			t.Errorf("%s: SetTPLadder() accepted the ladder", tt.name)
+		}
+	}
+	if pos.TPLadder != nil {
+		t.Error("a rejected ladder was stored")
+	}
+}
@@ -0,0 +1,51 @@
+package core
+
+import "time"
+
+// Trade event types published on the engine's trade event stream
+const (
+	TradeEventOrderAccepted  = "ORDER_ACCEPTED"
+	TradeEventOrderFilled    = "ORDER_FILLED"
+	TradeEventPositionClosed = "POSITION_CLOSED"
+)
+
+// TradeEvent describes an order or position change of an account
+type TradeEvent struct {
+	Type       string    `json:"type"`
+	AccountID  int64     `json:"accountId"`
+	OrderID    int64     `json:"orderId,omitempty"`
+	PositionID int64     `json:"positionId,omitempty"`
+	TradeID    int64     `json:"tradeId,omitempty"`
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"`
+	OrderType  string    `json:"orderType,omitempty"`
+	Volume     float64   `json:"volume"`
+	Price      float64   `json:"price,omitempty"`
+	Commission float64   `json:"commission,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+
+	// Order events: the volume asked for, which a partial fill may not reach
+	RequestedVolume float64 `json:"requestedVolume,omitempty"`
+
+	// Close events: realized P/L and the volume left open after a partial close
+	RealizedPnL     float64 `json:"realizedPnL,omitempty"`
+	RemainingVolume float64 `json:"remainingVolume,omitempty"`
+	CloseReason     string  `json:"closeReason,omitempty"` // Set when the server closed it, e.g. TRAILING_STOP
+}
+
+// SubscribeTradeEvents registers a listener for every order accepted, order
+// filled and position closed event. Listeners run while the engine lock is held
+// and must hand the event off without blocking or calling back into the engine.
+func (e *Engine) SubscribeTradeEvents(fn func(TradeEvent)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.tradeEventListeners = append(e.tradeEventListeners, fn)
+}
+
+// publishTradeEventUnlocked delivers an event to all listeners (caller must hold lock)
+func (e *Engine) publishTradeEventUnlocked(event TradeEvent) {
+	e.persistTradeEventUnlocked(event)
+	for _, fn := range e.tradeEventListeners {
+		fn(event)
+	}
+}
@@ -0,0 +1,115 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+// DefaultDrainTimeout is how long Shutdown waits for in-flight requests
+const DefaultDrainTimeout = 15 * time.Second
+
+var logger = logging.With(logging.Component("http"))
+
+// Server is an http.Server that, on Shutdown, stops accepting connections,
+// drains in-flight requests up to a timeout and then runs its shutdown hooks
+type Server struct {
+	srv          *http.Server
+	drainTimeout time.Duration
+
+	mu    sync.Mutex
+	hooks []func(ctx context.Context)
+
+	once sync.Once
+	done chan struct{} // Closed once Shutdown has finished
+	err  error
+}
+
+// New creates a server for handler on addr. A drain timeout of 0 uses
+// DefaultDrainTimeout.
+func New(addr string, handler http.Handler, drainTimeout time.Duration) *Server {
+	if drainTimeout <= 0 {
+		drainTimeout = DefaultDrainTimeout
+	}
+	return &Server{
+		srv:          &http.Server{Addr: addr, Handler: handler},
+		drainTimeout: drainTimeout,
+		done:         make(chan struct{}),
+	}
+}
+
+// OnShutdown registers fn to run after requests are drained, e.g. closing
+// WebSocket connections, which http.Server.Shutdown does not track. Hooks run
+// in registration order, sharing a new context with the drain timeout.
+func (s *Server) OnShutdown(fn func(ctx context.Context)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, fn)
+}
+
+// ListenAndServe listens on the server's address and serves until Shutdown
+func (s *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.srv.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve serves on listener until Shutdown. It returns once Shutdown has
+// drained the requests and run the hooks, with nil after a clean drain.
+func (s *Server) Serve(listener net.Listener) error {
+	err := s.srv.Serve(listener)
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	<-s.done
+	return s.err
+}
+
+// Shutdown stops accepting connections, waits for in-flight requests up to
+// the drain timeout and runs the shutdown hooks. Later calls wait for the
+// first one and return its result.
+func (s *Server) Shutdown() error {
+	s.once.Do(func() {
+		defer close(s.done)
+		ctx, cancel := context.WithTimeout(context.Background(), s.drainTimeout)
+		defer cancel()
+
+		started := time.Now()
+		if err := s.srv.Shutdown(ctx); err != nil {
+			// Drain timed out: drop whatever is still running
+			s.err = err
+			s.srv.Close()
+		}
+		s.mu.Lock()
+		hooks := append([]func(context.Context){}, s.hooks...)
+		s.mu.Unlock()
+		hookCtx, cancelHooks := context.WithTimeout(context.Background(), s.drainTimeout)
+		defer cancelHooks()
+		for _, hook := range hooks {
+			hook(hookCtx)
+		}
+		logger.Info("HTTP server shut down", logging.Latency(time.Since(started)), logging.Err(s.err))
+	})
+	<-s.done
+	return s.err
+}
+
+// ShutdownOnSignal calls Shutdown when the process receives one of signals
+func (s *Server) ShutdownOnSignal(signals ...os.Signal) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	go func() {
+		sig := <-ch
+		logger.Info("Received signal, shutting down", logging.String("signal", sig.String()))
+		s.Shutdown()
+	}()
+}
@@ -0,0 +1,94 @@
+package httpserver
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestShutdownDrainsInFlightRequest tests that a request in progress when
+// Shutdown starts completes, new connections are refused, and the hooks run
+// after the drain
+func TestShutdownDrainsInFlightRequest(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	addr := listener.Addr().String()
+	server := New(addr, mux, 5*time.Second)
+	hookRan := make(chan struct{})
+	server.OnShutdown(func(ctx context.Context) { close(hookRan) })
+
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(listener) }()
+
+	type result struct {
+		body string
+		err  error
+	}
+	inFlight := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr + "/slow")
+		if err != nil {
+			inFlight <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		inFlight <- result{body: string(body), err: err}
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- server.Shutdown() }()
+
+	// The listener closes at once; wait for it before dialing again
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		conn, err := net.DialTimeout("tcp", addr, 100*time.Millisecond)
+		if err != nil {
+			break
+		}
+		conn.Close()
+		if time.Now().After(deadline) {
+			t.Fatal("new connections still accepted after Shutdown")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case <-hookRan:
+		t.Fatal("shutdown hook ran before the in-flight request finished")
+	case <-shutdown:
+		t.Fatal("Shutdown returned before the in-flight request finished")
+	default:
+	}
+
+	close(release)
+	if got := <-inFlight; got.err != nil || got.body != "done" {
+		t.Fatalf("in-flight request = %q, %v; want it completed", got.body, got.err)
+	}
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Serve() error = %v, want nil after a clean drain", err)
+	}
+	select {
+	case <-hookRan:
+	default:
+		t.Error("shutdown hook did not run")
+	}
+}
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default CORS methods and headers, covering every route the server exposes
+var (
+	DefaultCORSMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	DefaultCORSHeaders = []string{"Content-Type", "Authorization", "X-CSRF-Token", "Range"}
+)
+
+// CORSOptions configures the CORS middleware
+type CORSOptions struct {
+	AllowedOrigins []string // Exact origins, or "*" for any origin without credentials
+	AllowedMethods []string // Defaults to DefaultCORSMethods
+	AllowedHeaders []string // Defaults to DefaultCORSHeaders
+	MaxAge         time.Duration
+}
+
+// CORS sets the cross-origin headers of every response in one place. An
+// allowed origin is echoed back with Access-Control-Allow-Credentials, which
+// browsers refuse alongside "*"; a "*" entry allows any origin, but without
+// credentials. Requests from other origins get no CORS headers, and their
+// preflights are refused.
+type CORS struct {
+	origins  map[string]bool
+	allowAll bool
+	methods  string
+	headers  string
+	maxAge   string
+}
+
+// NewCORS creates the middleware for opts
+func NewCORS(opts CORSOptions) *CORS {
+	c := &CORS{origins: make(map[string]bool)}
+	for _, origin := range opts.AllowedOrigins {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		switch origin {
+		case "":
+		case "*":
+			c.allowAll = true
+		default:
+			c.origins[origin] = true
+		}
+	}
+	methods, headers := opts.AllowedMethods, opts.AllowedHeaders
+	if len(methods) == 0 {
+		methods = DefaultCORSMethods
+	}
+	if len(headers) == 0 {
+		headers = DefaultCORSHeaders
+	}
+	c.methods = joinTrimmed(methods)
+	c.headers = joinTrimmed(headers)
+	if opts.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(int(opts.MaxAge.Seconds()))
+	}
+	return c
+}
+
+// Allowed reports whether requests from origin may read responses
+func (c *CORS) Allowed(origin string) bool {
+	return origin != "" && (c.allowAll || c.origins[origin])
+}
+
+// Middleware answers preflight requests and adds the CORS headers to the
+// responses of next. Headers set by handlers themselves are replaced, so the
+// allowlist cannot be widened route by route.
+func (c *CORS) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			// Same-origin or non-browser request
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			c.preflight(w, origin)
+			return
+		}
+		cw := &corsWriter{ResponseWriter: w, cors: c, origin: origin}
+		next.ServeHTTP(cw, r)
+		if !cw.wroteHeader && !cw.hijacked {
+			// Nothing written: net/http sends the headers after we return
+			c.apply(w.Header(), origin)
+		}
+	})
+}
+
+// preflight answers an OPTIONS preflight without reaching the handler
+func (c *CORS) preflight(w http.ResponseWriter, origin string) {
+	if !c.Allowed(origin) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	h := w.Header()
+	c.setOrigin(h, origin)
+	h.Set("Access-Control-Allow-Methods", c.methods)
+	h.Set("Access-Control-Allow-Headers", c.headers)
+	if c.maxAge != "" {
+		h.Set("Access-Control-Max-Age", c.maxAge)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// apply replaces whatever CORS headers a handler set with the middleware's
+func (c *CORS) apply(h http.Header, origin string) {
+	for key := range h {
+		if strings.HasPrefix(key, "Access-Control-") {
+			h.Del(key)
+		}
+	}
+	if c.Allowed(origin) {
+		c.setOrigin(h, origin)
+	}
+}
+
+// setOrigin allows origin, echoing it back with credentials unless it is
+// only allowed through "*"
+func (c *CORS) setOrigin(h http.Header, origin string) {
+	if c.origins[origin] {
+		h.Set("Access-Control-Allow-Origin", origin)
+		h.Set("Access-Control-Allow-Credentials", "true")
+		return
+	}
+	h.Set("Access-Control-Allow-Origin", "*")
+}
+
+func joinTrimmed(values []string) string {
+	trimmed := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			trimmed = append(trimmed, v)
+		}
+	}
+	return strings.Join(trimmed, ", ")
+}
+
+// corsWriter applies the CORS headers just before the response headers are
+// written
+type corsWriter struct {
+	http.ResponseWriter
+	cors        *CORS
+	origin      string
+	wroteHeader bool
+	hijacked    bool
+}
+
+func (cw *corsWriter) WriteHeader(status int) {
+	if !cw.wroteHeader {
+		cw.wroteHeader = true
+		cw.cors.apply(cw.Header(), cw.origin)
+	}
+	cw.ResponseWriter.WriteHeader(status)
+}
+
+func (cw *corsWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// Flush lets streaming handlers flush through the writer
+func (cw *corsWriter) Flush() {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the WebSocket upgrades take over the connection
+func (cw *corsWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := cw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	cw.hijacked = true
+	return hijacker.Hijack()
+}
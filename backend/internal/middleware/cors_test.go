@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestCORSAllowlist tests that an allowed origin is echoed back with
+// credentials, overriding a handler's own "*", and a disallowed one gets no
+// CORS headers
+func TestCORSAllowlist(t *testing.T) {
+	cors := NewCORS(CORSOptions{AllowedOrigins: []string{"http://localhost:3000", " https://app.example.com/"}})
+	handler := cors.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Write([]byte("ok"))
+	}))
+
+	for _, origin := range []string{"http://localhost:3000", "https://app.example.com"} {
+		req := httptest.NewRequest("GET", "/api/positions", nil)
+		req.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != origin {
+			t.Errorf("Allow-Origin for %s = %q, want it echoed back", origin, got)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+			t.Errorf("Allow-Credentials for %s = %q, want true", origin, got)
+		}
+		if got := rec.Header().Get("Vary"); got != "Origin" {
+			t.Errorf("Vary for %s = %q, want Origin", origin, got)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/api/positions", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Allow-Origin for a disallowed origin = %q, want none", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Allow-Credentials for a disallowed origin = %q, want none", got)
+	}
+}
+
+// TestCORSPreflight tests that preflights are answered from the configured
+// methods and headers, and refused for a disallowed origin
+func TestCORSPreflight(t *testing.T) {
+	cors := NewCORS(CORSOptions{AllowedOrigins: []string{"http://localhost:3000"}, AllowedMethods: []string{"GET", "POST"}})
+	handler := cors.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("preflight reached the handler")
+	}))
+
+	preflight := func(origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("OPTIONS", "/api/orders/market", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := preflight("http://localhost:3000")
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("preflight status = %d, want 204", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST" {
+		t.Errorf("Allow-Methods = %q, want %q", got, "GET, POST")
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Headers"); got == "" {
+		t.Errorf("Allow-Headers is empty, want the defaults")
+	}
+
+	rec = preflight("https://evil.example.com")
+	if rec.Code != http.StatusForbidden || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("disallowed preflight = %d with Allow-Origin %q, want 403 and none",
+			rec.Code, rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
+
+// TestCORSWildcard tests that "*" allows any origin but never with
+// credentials
+func TestCORSWildcard(t *testing.T) {
+	handler := NewCORS(CORSOptions{AllowedOrigins: []string{"*"}}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest("GET", "/api/config", nil)
+	req.Header.Set("Origin", "https://any.example.com")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Allow-Origin = %q, want *", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Allow-Credentials = %q with *, want none", got)
+	}
+}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+// LatencyBuckets are the upper bounds, in seconds, of the request latency
+// histogram
+var LatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// RouteStats is the latency and status code aggregate of one route
+type RouteStats struct {
+	Method      string        `json:"method"`
+	Route       string        `json:"route"`
+	Count       int64         `json:"count"`
+	SlowCount   int64         `json:"slow_count"`
+	StatusCodes map[int]int64 `json:"status_codes"`
+	AvgMs       float64       `json:"avg_ms"`
+	MaxMs       float64       `json:"max_ms"`
+	SumSeconds  float64       `json:"-"`
+	Buckets     []uint64      `json:"-"` // Per LatencyBuckets bound, not cumulative
+}
+
+// routeKey identifies a route by method and mux pattern
+type routeKey struct {
+	method string
+	route  string
+}
+
+// routeLatency accumulates one route's requests
+type routeLatency struct {
+	count       int64
+	slow        int64
+	statusCodes map[int]int64
+	sum         time.Duration
+	max         time.Duration
+	buckets     []uint64
+}
+
+// RequestMetrics records per-route latency and status codes of HTTP requests
+// and logs the ones slower than a threshold
+type RequestMetrics struct {
+	mu            sync.Mutex
+	routes        map[routeKey]*routeLatency
+	mux           *http.ServeMux
+	slowThreshold time.Duration
+	logger        *logging.FieldLogger
+}
+
+// NewRequestMetrics creates metrics for requests served by mux. Requests are
+// grouped by the mux pattern that matched them, so path parameters do not
+// create a route each. A threshold of 0 disables slow-request logging.
+func NewRequestMetrics(mux *http.ServeMux, slowThreshold time.Duration) *RequestMetrics {
+	return &RequestMetrics{
+		routes:        make(map[routeKey]*routeLatency),
+		mux:           mux,
+		slowThreshold: slowThreshold,
+		logger:        logging.With(logging.Component("http")),
+	}
+}
+
+// SetLogger replaces the logger slow requests are written to
+func (m *RequestMetrics) SetLogger(logger *logging.FieldLogger) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logger = logger
+}
+
+// Middleware times every request passed to next. WebSocket upgrades are not
+// recorded: their handler runs for the life of the connection.
+func (m *RequestMetrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		if !recorder.hijacked {
+			m.record(r, m.routeOf(r), recorder.status, time.Since(start))
+		}
+	})
+}
+
+// routeOf returns the mux pattern serving r, or "unmatched"
+func (m *RequestMetrics) routeOf(r *http.Request) string {
+	if m.mux != nil {
+		if _, pattern := m.mux.Handler(r); pattern != "" {
+			return pattern
+		}
+	}
+	return "unmatched"
+}
+
+// record adds one request to its route and logs it when slow
+func (m *RequestMetrics) record(r *http.Request, route string, status int, elapsed time.Duration) {
+	slow := m.slowThreshold > 0 && elapsed >= m.slowThreshold
+
+	m.mu.Lock()
+	key := routeKey{method: r.Method, route: route}
+	stats, ok := m.routes[key]
+	if !ok {
+		stats = &routeLatency{statusCodes: make(map[int]int64), buckets: make([]uint64, len(LatencyBuckets))}
+		m.routes[key] = stats
+	}
+	stats.count++
+	stats.statusCodes[status]++
+	stats.sum += elapsed
+	if elapsed > stats.max {
+		stats.max = elapsed
+	}
+	seconds := elapsed.Seconds()
+	for i, bound := range LatencyBuckets {
+		if seconds <= bound {
+			stats.buckets[i]++
+			break
+		}
+	}
+	if slow {
+		stats.slow++
+	}
+	logger := m.logger
+	m.mu.Unlock()
+
+	if slow {
+		logger.Warn("Slow request", logging.String("method", r.Method), logging.String("route", route),
+			logging.String("path", r.URL.Path), logging.Int("status", status), logging.Latency(elapsed),
+			logging.String("threshold", m.slowThreshold.String()))
+	}
+}
+
+// Snapshot returns every route's aggregate, sorted by route then method
+func (m *RequestMetrics) Snapshot() []RouteStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]RouteStats, 0, len(m.routes))
+	for key, stats := range m.routes {
+		codes := make(map[int]int64, len(stats.statusCodes))
+		for code, n := range stats.statusCodes {
+			codes[code] = n
+		}
+		result = append(result, RouteStats{
+			Method:      key.method,
+			Route:       key.route,
+			Count:       stats.count,
+			SlowCount:   stats.slow,
+			StatusCodes: codes,
+			AvgMs:       float64(stats.sum.Microseconds()) / 1000 / float64(stats.count),
+			MaxMs:       float64(stats.max.Microseconds()) / 1000,
+			SumSeconds:  stats.sum.Seconds(),
+			Buckets:     append([]uint64(nil), stats.buckets...),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Route != result[j].Route {
+			return result[i].Route < result[j].Route
+		}
+		return result[i].Method < result[j].Method
+	})
+	return result
+}
+
+// statusRecorder captures the status code a handler writes
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	hijacked    bool
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	return rec.ResponseWriter.Write(b)
+}
+
+// Flush lets streaming handlers flush through the recorder
+func (rec *statusRecorder) Flush() {
+	if flusher, ok := rec.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the WebSocket upgrades take over the connection
+func (rec *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rec.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	rec.hijacked = true
+	return hijacker.Hijack()
+}
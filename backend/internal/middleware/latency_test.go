@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+// TestRequestMetricsSlowLogAndAccumulate tests that requests accumulate per
+// mux pattern and that only the one over the threshold is logged as slow
+func TestRequestMetricsSlowLogAndAccumulate(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/fast", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/api/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/api/positions/", func(w http.ResponseWriter, r *http.Request) {})
+
+	var logs bytes.Buffer
+	metrics := NewRequestMetrics(mux, 20*time.Millisecond)
+	metrics.SetLogger(logging.NewLogger(logging.INFO, &logs).With(logging.Component("http")))
+	handler := metrics.Middleware(mux)
+
+	for _, path := range []string{"/api/fast", "/api/fast", "/api/slow", "/api/positions/1", "/api/positions/2", "/missing"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	byRoute := make(map[string]RouteStats)
+	for _, stats := range metrics.Snapshot() {
+		byRoute[stats.Route] = stats
+	}
+	if got := byRoute["/api/fast"]; got.Count != 2 || got.StatusCodes[200] != 2 || got.SlowCount != 0 {
+		t.Errorf("/api/fast stats = %+v, want 2 requests with 200 and none slow", got)
+	}
+	if got := byRoute["/api/slow"]; got.Count != 1 || got.StatusCodes[503] != 1 || got.SlowCount != 1 || got.MaxMs < 30 {
+		t.Errorf("/api/slow stats = %+v, want 1 slow 503 of at least 30ms", got)
+	}
+	if got := byRoute["/api/positions/"]; got.Count != 2 {
+		t.Errorf("/api/positions/ stats = %+v, want both IDs under one pattern", got)
+	}
+	if got := byRoute["unmatched"]; got.Count != 1 {
+		t.Errorf("unmatched stats = %+v, want the 404", got)
+	}
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"message":"Slow request"`) || !strings.Contains(lines[0], `"route":"/api/slow"`) {
+		t.Errorf("slow log = %q, want one entry for /api/slow", logs.String())
+	}
+}
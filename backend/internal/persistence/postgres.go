@@ -0,0 +1,244 @@
+package persistence
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/epic1st/rtx/backend/internal/core"
+	_ "github.com/lib/pq"
+)
+
+// PostgresStore keeps the B-Book engine's accounts, positions, ledger and bonuses in
+// the engine_* tables created by migration 008
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a store on an open database
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// SaveAccount inserts or updates an account
+func (s *PostgresStore) SaveAccount(account core.Account) error {
+	_, err := s.db.Exec(`
+		INSERT INTO engine_accounts (id, account_number, user_id, username, password, balance, credit,
+			leverage, margin_mode, currency, status, is_demo, swap_free, opposite_signal, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NOW())
+		ON CONFLICT (id) DO UPDATE SET
+			account_number = EXCLUDED.account_number, user_id = EXCLUDED.user_id,
+			username = EXCLUDED.username, password = EXCLUDED.password,
+			balance = EXCLUDED.balance, credit = EXCLUDED.credit, leverage = EXCLUDED.leverage,
+			margin_mode = EXCLUDED.margin_mode, currency = EXCLUDED.currency, status = EXCLUDED.status,
+			is_demo = EXCLUDED.is_demo, swap_free = EXCLUDED.swap_free,
+			opposite_signal = EXCLUDED.opposite_signal, updated_at = NOW()`,
+		account.ID, account.AccountNumber, account.UserID, account.Username, account.Password,
+		account.Balance, account.Credit, account.Leverage, account.MarginMode, account.Currency,
+		account.Status, account.IsDemo, account.SwapFree, account.OppositeSignal, account.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("save account %d: %w", account.ID, err)
+	}
+	return nil
+}
+
+// SavePosition inserts or updates a position
+func (s *PostgresStore) SavePosition(record core.PositionRecord) error {
+	var ladder []byte
+	if record.TPLadder != nil {
+		var err error
+		if ladder, err = json.Marshal(record.TPLadder); err != nil {
+			return fmt.Errorf("encode TP ladder of position %d: %w", record.ID, err)
+		}
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO engine_positions (id, account_id, symbol, side, volume, open_price, current_price,
+			open_time, sl, tp, swap, admin_fee, nights, commission, open_commission, unrealized_pnl,
+			status, close_price, close_time, close_reason, tp_ladder, book, last_rollover, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16,
+			$17, $18, $19, $20, $21, $22, $23, NOW())
+		ON CONFLICT (id) DO UPDATE SET
+			volume = EXCLUDED.volume, current_price = EXCLUDED.current_price,
+			sl = EXCLUDED.sl, tp = EXCLUDED.tp, swap = EXCLUDED.swap, admin_fee = EXCLUDED.admin_fee,
+			nights = EXCLUDED.nights, commission = EXCLUDED.commission,
+			open_commission = EXCLUDED.open_commission, unrealized_pnl = EXCLUDED.unrealized_pnl,
+			status = EXCLUDED.status, close_price = EXCLUDED.close_price,
+			close_time = EXCLUDED.close_time, close_reason = EXCLUDED.close_reason,
+			tp_ladder = EXCLUDED.tp_ladder, book = EXCLUDED.book,
+			last_rollover = EXCLUDED.last_rollover, updated_at = NOW()`,
+		record.ID, record.AccountID, record.Symbol, record.Side, record.Volume, record.OpenPrice,
+		record.CurrentPrice, record.OpenTime, record.SL, record.TP, record.Swap, record.AdminFee,
+		record.Nights, record.Commission, record.OpenCommission, record.UnrealizedPnL,
+		record.Status, record.ClosePrice, nullTime(record.CloseTime), record.CloseReason,
+		ladder, record.Book, nullTime(record.LastRollover))
+	if err != nil {
+		return fmt.Errorf("save position %d: %w", record.ID, err)
+	}
+	return nil
+}
+
+// AppendLedgerEntry inserts a ledger entry and moves the account's balance to
+// the entry's balance after, so funding done outside the engine is kept too
+func (s *PostgresStore) AppendLedgerEntry(entry core.LedgerEntry) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("append ledger entry %d: %w", entry.ID, err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO engine_ledger_entries (id, account_id, type, amount, balance_after, currency,
+			description, ref_type, ref_id, admin_id, payment_method, payment_ref, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (id) DO NOTHING`,
+		entry.ID, entry.AccountID, entry.Type, entry.Amount, entry.BalanceAfter, entry.Currency,
+		entry.Description, entry.RefType, entry.RefID, entry.AdminID, entry.PaymentMethod,
+		entry.PaymentRef, entry.Status, entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("append ledger entry %d: %w", entry.ID, err)
+	}
+	_, err = tx.Exec(`UPDATE engine_accounts SET balance = $1, updated_at = NOW() WHERE id = $2`,
+		entry.BalanceAfter, entry.AccountID)
+	if err != nil {
+		return fmt.Errorf("append ledger entry %d: %w", entry.ID, err)
+	}
+	return tx.Commit()
+}
+
+// SaveBonus inserts or updates a credit bonus
+func (s *PostgresStore) SaveBonus(bonus core.Bonus) error {
+	var releasedAt sql.NullTime
+	if bonus.ReleasedAt != nil {
+		releasedAt = nullTime(*bonus.ReleasedAt)
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO engine_bonuses (id, account_id, amount, required_volume, traded_volume, status,
+			description, admin_id, created_at, released_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		ON CONFLICT (id) DO UPDATE SET
+			traded_volume = EXCLUDED.traded_volume, status = EXCLUDED.status,
+			released_at = EXCLUDED.released_at, updated_at = NOW()`,
+		bonus.ID, bonus.AccountID, bonus.Amount, bonus.RequiredVolume, bonus.TradedVolume,
+		bonus.Status, bonus.Description, bonus.AdminID, bonus.CreatedAt, releasedAt)
+	if err != nil {
+		return fmt.Errorf("save bonus %d: %w", bonus.ID, err)
+	}
+	return nil
+}
+
+// LoadState reads every account, position, ledger entry and bonus
+func (s *PostgresStore) LoadState() (*core.StoreState, error) {
+	state := &core.StoreState{}
+
+	rows, err := s.db.Query(`
+		SELECT id, account_number, user_id, username, password, balance, credit, leverage,
+			margin_mode, currency, status, is_demo, swap_free, opposite_signal, created_at
+		FROM engine_accounts ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("load accounts: %w", err)
+	}
+	for rows.Next() {
+		var a core.Account
+		if err := rows.Scan(&a.ID, &a.AccountNumber, &a.UserID, &a.Username, &a.Password,
+			&a.Balance, &a.Credit, &a.Leverage, &a.MarginMode, &a.Currency, &a.Status,
+			&a.IsDemo, &a.SwapFree, &a.OppositeSignal, &a.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("load accounts: %w", err)
+		}
+		state.Accounts = append(state.Accounts, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("load accounts: %w", err)
+	}
+
+	rows, err = s.db.Query(`
+		SELECT id, account_id, symbol, side, volume, open_price, current_price, open_time, sl, tp,
+			swap, admin_fee, nights, commission, open_commission, unrealized_pnl, status,
+			close_price, close_time, close_reason, tp_ladder, book, last_rollover
+		FROM engine_positions ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("load positions: %w", err)
+	}
+	for rows.Next() {
+		var p core.PositionRecord
+		var closeTime, lastRollover sql.NullTime
+		var ladder []byte
+		if err := rows.Scan(&p.ID, &p.AccountID, &p.Symbol, &p.Side, &p.Volume, &p.OpenPrice,
+			&p.CurrentPrice, &p.OpenTime, &p.SL, &p.TP, &p.Swap, &p.AdminFee, &p.Nights,
+			&p.Commission, &p.OpenCommission, &p.UnrealizedPnL, &p.Status, &p.ClosePrice,
+			&closeTime, &p.CloseReason, &ladder, &p.Book, &lastRollover); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("load positions: %w", err)
+		}
+		p.CloseTime = closeTime.Time
+		p.LastRollover = lastRollover.Time
+		if len(ladder) > 0 {
+			p.TPLadder = &core.TPLadder{}
+			if err := json.Unmarshal(ladder, p.TPLadder); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("load TP ladder of position %d: %w", p.ID, err)
+			}
+		}
+		state.Positions = append(state.Positions, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("load positions: %w", err)
+	}
+
+	rows, err = s.db.Query(`
+		SELECT id, account_id, type, amount, balance_after, currency, description, ref_type,
+			ref_id, admin_id, payment_method, payment_ref, status, created_at
+		FROM engine_ledger_entries ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("load ledger: %w", err)
+	}
+	for rows.Next() {
+		var l core.LedgerEntry
+		if err := rows.Scan(&l.ID, &l.AccountID, &l.Type, &l.Amount, &l.BalanceAfter,
+			&l.Currency, &l.Description, &l.RefType, &l.RefID, &l.AdminID, &l.PaymentMethod,
+			&l.PaymentRef, &l.Status, &l.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("load ledger: %w", err)
+		}
+		state.LedgerEntries = append(state.LedgerEntries, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("load ledger: %w", err)
+	}
+
+	rows, err = s.db.Query(`
+		SELECT id, account_id, amount, required_volume, traded_volume, status, description,
+			admin_id, created_at, released_at
+		FROM engine_bonuses ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("load bonuses: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var b core.Bonus
+		var releasedAt sql.NullTime
+		if err := rows.Scan(&b.ID, &b.AccountID, &b.Amount, &b.RequiredVolume, &b.TradedVolume,
+			&b.Status, &b.Description, &b.AdminID, &b.CreatedAt, &releasedAt); err != nil {
+			return nil, fmt.Errorf("load bonuses: %w", err)
+		}
+		if releasedAt.Valid {
+			b.ReleasedAt = &releasedAt.Time
+		}
+		state.Bonuses = append(state.Bonuses, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("load bonuses: %w", err)
+	}
+	return state, nil
+}
+
+// nullTime stores the zero time as NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,85 @@
+package persistence
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/epic1st/rtx/backend/db/migrations"
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+// openTestDB connects to TEST_DATABASE_URL and creates the engine tables
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		t.Skipf("Postgres not available: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	db.Exec(`DROP TABLE IF EXISTS engine_ledger_entries, engine_positions, engine_accounts`)
+	db.Exec(`DELETE FROM schema_migrations WHERE version = 8`)
+	migrator := migrations.NewMigrator(db)
+	for _, m := range migrations.GetRegisteredMigrations() {
+		if m.Version == 8 {
+			migrator.Register(m)
+		}
+	}
+	if err := migrator.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if err := migrator.Up(); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	return db
+}
+
+// TestPostgresStoreSurvivesRestart tests that a deposit and an open position
+// are loaded by a new engine backed by the same database
+func TestPostgresStoreSurvivesRestart(t *testing.T) {
+	db := openTestDB(t)
+	prices := func(symbol string) (float64, float64, bool) { return 1.1000, 1.1002, symbol == "EURUSD" }
+
+	engine := core.NewEngine()
+	engine.SetPriceCallback(prices)
+	engine.SetStore(NewPostgresStore(db))
+	account := engine.CreateAccount("user1", "trader", "password", false)
+
+	entry, err := engine.GetLedger().Deposit(account.ID, 1000, "BANK", "ref-1", "Deposit", "admin")
+	if err != nil {
+		t.Fatalf("Deposit() error = %v", err)
+	}
+	account.Balance = entry.BalanceAfter
+	pos, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 1.0900, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+
+	restarted := core.NewEngine()
+	restarted.SetPriceCallback(prices)
+	restarted.SetStore(NewPostgresStore(db))
+	if n, err := restarted.LoadFromStore(); err != nil || n != 1 {
+		t.Fatalf("LoadFromStore() = %d, %v; want 1 account", n, err)
+	}
+
+	loaded, ok := restarted.GetAccount(account.ID)
+	if !ok || loaded.Balance != account.Balance {
+		t.Fatalf("reloaded account = %+v, want balance %.2f", loaded, account.Balance)
+	}
+	positions := restarted.GetPositions(account.ID)
+	if len(positions) != 1 || positions[0].ID != pos.ID || positions[0].SL != 1.0900 {
+		t.Fatalf("reloaded positions = %+v, want position #%d with its SL", positions, pos.ID)
+	}
+	if history := restarted.GetLedger().GetHistory(account.ID, 0); len(history) != 1 || history[0].Amount != 1000 {
+		t.Errorf("reloaded ledger = %+v, want the deposit", history)
+	}
+}
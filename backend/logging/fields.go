@@ -1,6 +1,9 @@
 package logging
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Field represents a log field that can be added to a log entry
 type Field interface {
@@ -52,6 +55,34 @@ func Symbol(symbol string) Field {
 	})
 }
 
+func Session(id string) Field {
+	return fieldFunc(func(e *LogEntry) {
+		e.Session = id
+	})
+}
+
+func Seq(seq int) Field {
+	return fieldFunc(func(e *LogEntry) {
+		e.Seq = seq
+	})
+}
+
+// Latency records d in milliseconds
+func Latency(d time.Duration) Field {
+	return fieldFunc(func(e *LogEntry) {
+		e.Latency = float64(d.Microseconds()) / 1000
+	})
+}
+
+// Err records err on an entry below ERROR level
+func Err(err error) Field {
+	return fieldFunc(func(e *LogEntry) {
+		if err != nil {
+			e.Error = err.Error()
+		}
+	})
+}
+
 func Component(component string) Field {
 	return fieldFunc(func(e *LogEntry) {
 		e.Component = component
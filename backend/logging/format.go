@@ -0,0 +1,118 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LogFormat selects how entries are written
+type LogFormat int
+
+const (
+	// FormatJSON writes one JSON object per line, for log aggregation
+	FormatJSON LogFormat = iota
+	// FormatConsole writes one human-readable line per entry, for local dev
+	FormatConsole
+)
+
+// ParseLevel parses a level name such as "info" or "WARN"
+func ParseLevel(name string) (LogLevel, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "DEBUG":
+		return DEBUG, nil
+	case "INFO":
+		return INFO, nil
+	case "WARN", "WARNING":
+		return WARN, nil
+	case "ERROR":
+		return ERROR, nil
+	case "FATAL":
+		return FATAL, nil
+	}
+	return INFO, fmt.Errorf("unknown log level %q", name)
+}
+
+// ParseFormat parses "json" or "console"
+func ParseFormat(name string) (LogFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "json":
+		return FormatJSON, nil
+	case "console", "text":
+		return FormatConsole, nil
+	}
+	return FormatJSON, fmt.Errorf("unknown log format %q", name)
+}
+
+// ConfigureFromEnv sets the global logger's level from LOG_LEVEL and its
+// format from LOG_FORMAT. Unset variables keep INFO and JSON.
+func ConfigureFromEnv() error {
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		level, err := ParseLevel(value)
+		if err != nil {
+			return fmt.Errorf("LOG_LEVEL: %w", err)
+		}
+		defaultLogger.SetLevel(level)
+	}
+	if value := os.Getenv("LOG_FORMAT"); value != "" {
+		format, err := ParseFormat(value)
+		if err != nil {
+			return fmt.Errorf("LOG_FORMAT: %w", err)
+		}
+		defaultLogger.SetFormat(format)
+	}
+	return nil
+}
+
+// formatConsole renders an entry as
+//
+//	2006-01-02 15:04:05.000 INFO  [fix] Logged in session=YOFX1 seq=1
+func formatConsole(entry *LogEntry) []byte {
+	var b strings.Builder
+	b.WriteString(entry.Timestamp.Format("2006-01-02 15:04:05.000"))
+	b.WriteByte(' ')
+	fmt.Fprintf(&b, "%-5s ", entry.Level)
+	if entry.Component != "" {
+		b.WriteString("[" + entry.Component + "] ")
+	}
+	b.WriteString(entry.Message)
+
+	pair := func(key, value string) {
+		if value == "" {
+			return
+		}
+		if strings.ContainsAny(value, " \t\"=") {
+			value = strconv.Quote(value)
+		}
+		b.WriteString(" " + key + "=" + value)
+	}
+	pair("session", entry.Session)
+	pair("symbol", entry.Symbol)
+	if entry.Seq != 0 {
+		pair("seq", strconv.Itoa(entry.Seq))
+	}
+	if entry.Latency != 0 {
+		pair("latency_ms", strconv.FormatFloat(entry.Latency, 'f', -1, 64))
+	}
+	pair("request_id", entry.RequestID)
+	pair("user_id", entry.UserID)
+	pair("account_id", entry.AccountID)
+	pair("order_id", entry.OrderID)
+	pair("trade_id", entry.TradeID)
+	if entry.Duration != 0 {
+		pair("duration_ms", strconv.FormatFloat(entry.Duration, 'f', -1, 64))
+	}
+
+	keys := make([]string, 0, len(entry.Extra))
+	for key := range entry.Extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		pair(key, fmt.Sprint(entry.Extra[key]))
+	}
+	pair("error", entry.Error)
+	return []byte(b.String())
+}
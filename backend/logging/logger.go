@@ -42,6 +42,9 @@ type LogEntry struct {
 	TradeID     string                 `json:"trade_id,omitempty"`
 	OrderID     string                 `json:"order_id,omitempty"`
 	Symbol      string                 `json:"symbol,omitempty"`
+	Session     string                 `json:"session,omitempty"`
+	Seq         int                    `json:"seq,omitempty"`
+	Latency     float64                `json:"latency_ms,omitempty"`
 	Component   string                 `json:"component,omitempty"`
 	Function    string                 `json:"function,omitempty"`
 	File        string                 `json:"file,omitempty"`
@@ -65,6 +68,7 @@ type Logger struct {
 	hostname    string
 	pid         int
 	sampling    *SamplingConfig
+	format      LogFormat
 }
 
 // SamplingConfig controls log sampling to reduce volume in production
@@ -104,6 +108,13 @@ func NewLogger(level LogLevel, outputs ...io.Writer) *Logger {
 	}
 }
 
+// SetFormat changes how entries are written
+func (l *Logger) SetFormat(format LogFormat) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.format = format
+}
+
 // SetLevel changes the minimum log level
 func (l *Logger) SetLevel(level LogLevel) {
 	l.mu.Lock()
@@ -258,16 +269,22 @@ func (l *Logger) shouldSample(level LogLevel) bool {
 
 // writeEntry writes the log entry to all outputs
 func (l *Logger) writeEntry(entry *LogEntry) {
-	data, err := json.Marshal(entry)
-	if err != nil {
-		// Fallback to simple output if JSON marshaling fails
-		data = []byte(fmt.Sprintf(`{"level":"%s","message":"Failed to marshal log: %v"}`, entry.Level, err))
-	}
-	data = append(data, '\n')
-
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 
+	var data []byte
+	if l.format == FormatConsole {
+		data = formatConsole(entry)
+	} else {
+		var err error
+		data, err = json.Marshal(entry)
+		if err != nil {
+			// Fallback to simple output if JSON marshaling fails
+			data = []byte(fmt.Sprintf(`{"level":"%s","message":"Failed to marshal log: %v"}`, entry.Level, err))
+		}
+	}
+	data = append(data, '\n')
+
 	for _, output := range l.outputs {
 		_, _ = output.Write(data) // Ignore write errors to prevent cascading failures
 	}
@@ -309,6 +326,56 @@ func (cl *ContextLogger) Fatal(message string, err error, fields ...Field) {
 	cl.logger.Fatal(message, err, fields...)
 }
 
+// FieldLogger wraps Logger with fields added to every entry, e.g. the
+// component of a package
+type FieldLogger struct {
+	logger *Logger
+	fields []Field
+}
+
+// With returns a logger that adds fields to every entry
+func (l *Logger) With(fields ...Field) *FieldLogger {
+	return &FieldLogger{logger: l, fields: fields}
+}
+
+// With returns a logger adding fields on top of this logger's
+func (fl *FieldLogger) With(fields ...Field) *FieldLogger {
+	return &FieldLogger{logger: fl.logger, fields: append(append([]Field(nil), fl.fields...), fields...)}
+}
+
+// Enabled reports whether entries at level are written, so callers can skip
+// building fields on hot paths
+func (fl *FieldLogger) Enabled(level LogLevel) bool {
+	return level >= fl.logger.GetLevel()
+}
+
+// Debug logs a debug message with the logger's fields
+func (fl *FieldLogger) Debug(message string, fields ...Field) {
+	fl.logger.log(DEBUG, message, nil, fl.merge(fields)...)
+}
+
+// Info logs an info message with the logger's fields
+func (fl *FieldLogger) Info(message string, fields ...Field) {
+	fl.logger.log(INFO, message, nil, fl.merge(fields)...)
+}
+
+// Warn logs a warning message with the logger's fields
+func (fl *FieldLogger) Warn(message string, fields ...Field) {
+	fl.logger.log(WARN, message, nil, fl.merge(fields)...)
+}
+
+// Error logs an error message with the logger's fields
+func (fl *FieldLogger) Error(message string, err error, fields ...Field) {
+	fl.logger.log(ERROR, message, err, fl.merge(fields)...)
+}
+
+func (fl *FieldLogger) merge(fields []Field) []Field {
+	if len(fields) == 0 {
+		return fl.fields
+	}
+	return append(append(make([]Field, 0, len(fl.fields)+len(fields)), fl.fields...), fields...)
+}
+
 // Helper functions
 
 func getEnvironment() string {
@@ -383,6 +450,20 @@ func SetLevel(level LogLevel) {
 	defaultLogger.SetLevel(level)
 }
 
+func SetFormat(format LogFormat) {
+	defaultLogger.SetFormat(format)
+}
+
+// Default returns the global logger
+func Default() *Logger {
+	return defaultLogger
+}
+
+// With returns a logger on the global logger that adds fields to every entry
+func With(fields ...Field) *FieldLogger {
+	return defaultLogger.With(fields...)
+}
+
 func AddHook(hook Hook) {
 	defaultLogger.AddHook(hook)
 }
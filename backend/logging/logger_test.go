@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestFieldLoggerJSON tests that component loggers emit JSON with the
+// session, symbol, seq and latency fields and honour the level
+func TestFieldLoggerJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(INFO, &buf).With(Component("fix"))
+
+	logger.Debug("dropped by level")
+	logger.Info("Sent NewOrderSingle", Session("YOFX1"), Symbol("EURUSD"), Seq(42), Latency(1500*time.Microsecond))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("wrote %d lines, want 1 (DEBUG filtered): %q", len(lines), buf.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("entry is not JSON: %v", err)
+	}
+	want := map[string]interface{}{
+		"level": "INFO", "message": "Sent NewOrderSingle", "component": "fix",
+		"session": "YOFX1", "symbol": "EURUSD", "seq": 42.0, "latency_ms": 1.5,
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("%s = %v, want %v", key, entry[key], value)
+		}
+	}
+	if file, _ := entry["file"].(string); !strings.HasSuffix(file, "logger_test.go") {
+		t.Errorf("file = %q, want the caller", file)
+	}
+}
+
+// TestConsoleFormat tests the human-readable mode
+func TestConsoleFormat(t *testing.T) {
+	var buf bytes.Buffer
+	base := NewLogger(DEBUG, &buf)
+	base.SetFormat(FormatConsole)
+
+	base.With(Component("ws")).Warn("Write failed", UserID("u1"), String("remote", "10.0.0.1:5000"), Err(errors.New("broken pipe")))
+
+	line := buf.String()
+	for _, want := range []string{"WARN  [ws] Write failed", "user_id=u1", "remote=10.0.0.1:5000", `error="broken pipe"`} {
+		if !strings.Contains(line, want) {
+			t.Errorf("console line %q is missing %q", line, want)
+		}
+	}
+	if strings.HasPrefix(strings.TrimSpace(line), "{") {
+		t.Errorf("console line is JSON: %q", line)
+	}
+}
+
+// TestConfigureFromEnv tests LOG_LEVEL and LOG_FORMAT on the global logger
+func TestConfigureFromEnv(t *testing.T) {
+	defer func() {
+		defaultLogger.SetLevel(INFO)
+		defaultLogger.SetFormat(FormatJSON)
+	}()
+
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("LOG_FORMAT", "console")
+	if err := ConfigureFromEnv(); err != nil {
+		t.Fatalf("ConfigureFromEnv() error = %v", err)
+	}
+	if got := Default().GetLevel(); got != WARN {
+		t.Errorf("level = %v, want WARN", got)
+	}
+	if Default().format != FormatConsole {
+		t.Errorf("format = %v, want console", Default().format)
+	}
+
+	t.Setenv("LOG_LEVEL", "verbose")
+	if err := ConfigureFromEnv(); err == nil {
+		t.Error("ConfigureFromEnv() accepted LOG_LEVEL=verbose")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -25,16 +26,87 @@ type BinanceAdapter struct {
 	id                string
 	name              string
 	conn              *websocket.Conn
+	writeMu           sync.Mutex // gorilla/websocket allows one writer at a time
 	quotesChan        chan lpmanager.Quote
 	stopChan          chan struct{}
+	stopped           bool
 	mu                sync.RWMutex
 	connected         bool
 	symbols           []lpmanager.SymbolInfo
-	subscribedSymbols []string
+	subscribedSymbols map[string]bool // platform symbols, e.g. BTCUSD
+	requestID         int64
 	lastTick          time.Time
 	errorMsg          string
 }
 
+// binanceDefaultQuote is the quote asset Binance pairs are assumed to trade
+// against when a symbol isn't in the mapping table
+const binanceDefaultQuote = "USDT"
+
+// Binance reconnect backoff: doubles from the minimum after each failed dial
+const (
+	binanceMinReconnectDelay = time.Second
+	binanceMaxReconnectDelay = time.Minute
+)
+
+// binanceSymbolMap maps Binance pairs to platform symbols. Pairs not listed
+// fall back to swapping a USDT suffix for USD.
+var binanceSymbolMap = map[string]string{
+	"BTCUSDT":  "BTCUSD",
+	"ETHUSDT":  "ETHUSD",
+	"BNBUSDT":  "BNBUSD",
+	"SOLUSDT":  "SOLUSD",
+	"XRPUSDT":  "XRPUSD",
+	"ADAUSDT":  "ADAUSD",
+	"DOGEUSDT": "DOGEUSD",
+	"LTCUSDT":  "LTCUSD",
+	"DOTUSDT":  "DOTUSD",
+	"LINKUSDT": "LINKUSD",
+	"TRXUSDT":  "TRXUSD",
+	"BCHUSDT":  "BCHUSD",
+	"USDCUSDT": "USDCUSD",
+}
+
+// platformToBinance is the reverse of binanceSymbolMap
+var platformToBinance = func() map[string]string {
+	m := make(map[string]string, len(binanceSymbolMap))
+	for pair, symbol := range binanceSymbolMap {
+		m[symbol] = pair
+	}
+	return m
+}()
+
+// NormalizeBinanceSymbol converts a Binance pair to the platform symbol:
+// BTCUSDT -> BTCUSD
+func NormalizeBinanceSymbol(pair string) string {
+	pair = strings.ToUpper(pair)
+	if symbol, ok := binanceSymbolMap[pair]; ok {
+		return symbol
+	}
+	if strings.HasSuffix(pair, binanceDefaultQuote) {
+		return strings.TrimSuffix(pair, binanceDefaultQuote) + "USD"
+	}
+	return pair
+}
+
+// ToBinanceSymbol converts a platform symbol to its Binance pair:
+// BTCUSD -> BTCUSDT
+func ToBinanceSymbol(symbol string) string {
+	symbol = strings.ToUpper(symbol)
+	if pair, ok := platformToBinance[symbol]; ok {
+		return pair
+	}
+	if strings.HasSuffix(symbol, "USD") {
+		return strings.TrimSuffix(symbol, "USD") + binanceDefaultQuote
+	}
+	return symbol
+}
+
+// bookTickerStream names the bookTicker stream of a platform symbol
+func bookTickerStream(symbol string) string {
+	return strings.ToLower(ToBinanceSymbol(symbol)) + "@bookTicker"
+}
+
 // NewBinanceAdapter creates a new Binance adapter
 func NewBinanceAdapter() *BinanceAdapter {
 	return &BinanceAdapter{
@@ -43,6 +115,8 @@ func NewBinanceAdapter() *BinanceAdapter {
 		quotesChan: make(chan lpmanager.Quote, 500),
 		stopChan:   make(chan struct{}),
 		symbols:    []lpmanager.SymbolInfo{},
+
+		subscribedSymbols: make(map[string]bool),
 	}
 }
 
@@ -121,7 +195,7 @@ func (b *BinanceAdapter) GetSymbols() ([]lpmanager.SymbolInfo, error) {
 		}
 
 		// Convert BTCUSDT -> BTCUSD format
-		displayName := strings.Replace(s.Symbol, "USDT", "USD", 1)
+		displayName := NormalizeBinanceSymbol(s.Symbol)
 
 		// Get lot size from filters
 		var minLot, maxLot, stepSize float64
@@ -167,6 +241,11 @@ func (b *BinanceAdapter) Connect() error {
 	}
 
 	b.mu.Lock()
+	if b.stopped {
+		// Reconnecting after Disconnect
+		b.stopChan = make(chan struct{})
+		b.stopped = false
+	}
 	b.connected = true
 	b.errorMsg = ""
 	b.mu.Unlock()
@@ -175,23 +254,99 @@ func (b *BinanceAdapter) Connect() error {
 	return nil
 }
 
-// Subscribe starts streaming prices for given symbols
+// Subscribe starts streaming bookTicker quotes for symbols. The first call
+// opens the combined stream; later calls add streams over the live connection.
 func (b *BinanceAdapter) Subscribe(symbols []string) error {
-	if len(symbols) == 0 {
+	b.mu.Lock()
+	var added []string
+	for _, sym := range symbols {
+		sym = strings.ToUpper(sym)
+		if !b.subscribedSymbols[sym] {
+			b.subscribedSymbols[sym] = true
+			added = append(added, sym)
+		}
+	}
+	conn := b.conn
+	b.mu.Unlock()
+
+	if len(added) == 0 {
+		return nil
+	}
+	if conn != nil {
+		if err := b.sendStreamRequest(conn, "SUBSCRIBE", added); err == nil {
+			log.Printf("[Binance] Subscribed to %d more symbols", len(added))
+			return nil
+		}
+		// The read loop notices the broken connection and reconnects with
+		// every subscribed stream
 		return nil
 	}
+	return b.dial()
+}
 
-	// Close existing connection if any
-	if b.conn != nil {
-		b.conn.Close()
+// Unsubscribe stops streaming symbols over the live connection
+func (b *BinanceAdapter) Unsubscribe(symbols []string) error {
+	b.mu.Lock()
+	var removed []string
+	for _, sym := range symbols {
+		sym = strings.ToUpper(sym)
+		if b.subscribedSymbols[sym] {
+			delete(b.subscribedSymbols, sym)
+			removed = append(removed, sym)
+		}
+	}
+	conn := b.conn
+	b.mu.Unlock()
+
+	if len(removed) == 0 || conn == nil {
+		return nil
+	}
+	return b.sendStreamRequest(conn, "UNSUBSCRIBE", removed)
+}
+
+// SubscribedSymbols returns the symbols currently streamed
+func (b *BinanceAdapter) SubscribedSymbols() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	symbols := make([]string, 0, len(b.subscribedSymbols))
+	for sym := range b.subscribedSymbols {
+		symbols = append(symbols, sym)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// sendStreamRequest sends a SUBSCRIBE/UNSUBSCRIBE request for symbols' streams
+func (b *BinanceAdapter) sendStreamRequest(conn *websocket.Conn, method string, symbols []string) error {
+	params := make([]string, len(symbols))
+	for i, sym := range symbols {
+		params[i] = bookTickerStream(sym)
+	}
+
+	b.mu.Lock()
+	b.requestID++
+	id := b.requestID
+	b.mu.Unlock()
+
+	b.writeMu.Lock()
+	defer b.writeMu.Unlock()
+	if err := conn.WriteJSON(map[string]interface{}{"method": method, "params": params, "id": id}); err != nil {
+		log.Printf("[Binance] %s failed: %v", method, err)
+		return fmt.Errorf("%s failed: %w", strings.ToLower(method), err)
+	}
+	return nil
+}
+
+// dial opens the combined bookTicker stream of every subscribed symbol
+func (b *BinanceAdapter) dial() error {
+	symbols := b.SubscribedSymbols()
+	if len(symbols) == 0 {
+		return nil
 	}
 
-	// Build stream URL
 	streams := make([]string, 0, len(symbols))
 	for _, sym := range symbols {
-		// Convert BTCUSD -> btcusdt@bookTicker
-		binanceSym := strings.ToLower(strings.Replace(sym, "USD", "USDT", 1))
-		streams = append(streams, binanceSym+"@bookTicker")
+		streams = append(streams, bookTickerStream(sym))
 	}
 
 	wsURL := BinanceWSURL + "?streams=" + strings.Join(streams, "/")
@@ -203,64 +358,75 @@ func (b *BinanceAdapter) Subscribe(symbols []string) error {
 
 	conn, _, err := dialer.Dial(wsURL, nil)
 	if err != nil {
+		b.mu.Lock()
 		b.errorMsg = err.Error()
+		b.mu.Unlock()
 		return fmt.Errorf("websocket dial failed: %w", err)
 	}
 
-	b.conn = conn
-	b.subscribedSymbols = symbols
-
 	b.mu.Lock()
+	if b.stopped {
+		b.mu.Unlock()
+		conn.Close()
+		return nil
+	}
+	if b.conn != nil {
+		b.conn.Close()
+	}
+	b.conn = conn
 	b.connected = true
+	b.errorMsg = ""
+	stop := b.stopChan
 	b.mu.Unlock()
 
 	log.Println("[Binance] WebSocket connected, reading messages...")
 
 	// Start reading messages
-	go b.readMessages()
+	go b.readMessages(conn)
 
 	// Start heartbeat
-	go b.heartbeat()
+	go b.heartbeat(conn, stop)
 
 	return nil
 }
 
-func (b *BinanceAdapter) Unsubscribe(symbols []string) error {
-	// For Binance, unsubscribe means closing the connection
-	// A new Subscribe call with different symbols would reconnect
-	return nil
-}
-
 func (b *BinanceAdapter) Disconnect() error {
 	b.mu.Lock()
 	b.connected = false
+	if !b.stopped {
+		close(b.stopChan)
+		b.stopped = true
+	}
+	conn := b.conn
+	b.conn = nil
 	b.mu.Unlock()
 
-	close(b.stopChan)
-	if b.conn != nil {
-		b.conn.Close()
+	if conn != nil {
+		conn.Close()
 	}
 	return nil
 }
 
-func (b *BinanceAdapter) readMessages() {
-	defer func() {
-		b.mu.Lock()
-		b.connected = false
-		b.mu.Unlock()
-	}()
-
+// readMessages reads conn until it fails, then reconnects unless the adapter
+// was stopped or conn was already replaced
+func (b *BinanceAdapter) readMessages(conn *websocket.Conn) {
 	for {
-		select {
-		case <-b.stopChan:
-			return
-		default:
-		}
-
-		_, message, err := b.conn.ReadMessage()
+		_, message, err := conn.ReadMessage()
 		if err != nil {
-			log.Printf("[Binance] Read error: %v", err)
-			go b.reconnect()
+			b.mu.Lock()
+			current := b.conn == conn
+			if current {
+				b.conn = nil
+				b.connected = false
+				b.errorMsg = err.Error()
+			}
+			stopped := b.stopped
+			b.mu.Unlock()
+
+			if current && !stopped {
+				log.Printf("[Binance] Read error: %v", err)
+				go b.reconnect()
+			}
 			return
 		}
 
@@ -268,20 +434,17 @@ func (b *BinanceAdapter) readMessages() {
 	}
 }
 
-func (b *BinanceAdapter) handleMessage(message []byte) {
+// parseBookTicker maps a combined stream bookTicker message to a quote.
+// Subscription responses and malformed or one-sided books are rejected.
+func parseBookTicker(message []byte, lp string) (lpmanager.Quote, bool) {
 	// Parse combined stream message
 	var streamMsg struct {
 		Stream string          `json:"stream"`
 		Data   json.RawMessage `json:"data"`
 	}
 
-	if err := json.Unmarshal(message, &streamMsg); err != nil {
-		return
-	}
-
-	// Check if symbol contains BTC to log (noisy otherwise)
-	if strings.Contains(string(streamMsg.Data), "BTCUSDT") {
-		log.Printf("[DEBUG] BTC Payload: %s", string(streamMsg.Data))
+	if err := json.Unmarshal(message, &streamMsg); err != nil || len(streamMsg.Data) == 0 {
+		return lpmanager.Quote{}, false
 	}
 
 	// Parse book ticker
@@ -298,28 +461,30 @@ func (b *BinanceAdapter) handleMessage(message []byte) {
 		AskQty   string `json:"A"`
 	}
 
-	if err := json.Unmarshal(streamMsg.Data, &ticker); err != nil {
-		return
+	if err := json.Unmarshal(streamMsg.Data, &ticker); err != nil || ticker.Symbol == "" {
+		return lpmanager.Quote{}, false
 	}
 
 	bid, _ := strconv.ParseFloat(ticker.BidPrice, 64)
 	ask, _ := strconv.ParseFloat(ticker.AskPrice, 64)
-	// bidQty, _ := strconv.ParseFloat(ticker.BidQty, 64)
-	// askQty, _ := strconv.ParseFloat(ticker.AskQty, 64)
 
 	if bid <= 0 || ask <= 0 {
-		return
+		return lpmanager.Quote{}, false
 	}
 
-	// Convert BTCUSDT -> BTCUSD
-	symbol := strings.Replace(ticker.Symbol, "USDT", "USD", 1)
-
-	quote := lpmanager.Quote{
-		Symbol:    symbol,
+	return lpmanager.Quote{
+		Symbol:    NormalizeBinanceSymbol(ticker.Symbol),
 		Bid:       bid,
 		Ask:       ask,
 		Timestamp: time.Now().UnixMilli(),
-		LP:        b.id,
+		LP:        lp,
+	}, true
+}
+
+func (b *BinanceAdapter) handleMessage(message []byte) {
+	quote, ok := parseBookTicker(message, b.id)
+	if !ok {
+		return
 	}
 
 	b.mu.Lock()
@@ -332,29 +497,53 @@ func (b *BinanceAdapter) handleMessage(message []byte) {
 	}
 }
 
-func (b *BinanceAdapter) heartbeat() {
+// heartbeat pings conn until the adapter stops or conn is replaced
+func (b *BinanceAdapter) heartbeat(conn *websocket.Conn, stop <-chan struct{}) {
 	ticker := time.NewTicker(3 * time.Minute)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-b.stopChan:
+		case <-stop:
 			return
 		case <-ticker.C:
-			if b.IsConnected() && b.conn != nil {
-				b.conn.WriteMessage(websocket.PingMessage, nil)
+			b.mu.RLock()
+			current := b.conn == conn
+			b.mu.RUnlock()
+			if !current {
+				return
 			}
+			b.writeMu.Lock()
+			conn.WriteMessage(websocket.PingMessage, nil)
+			b.writeMu.Unlock()
 		}
 	}
 }
 
+// reconnect redials with a doubling backoff until it succeeds or the adapter stops
 func (b *BinanceAdapter) reconnect() {
-	log.Println("[Binance] Reconnecting in 3 seconds...")
-	time.Sleep(3 * time.Second)
+	delay := binanceMinReconnectDelay
+	for {
+		b.mu.RLock()
+		stop := b.stopChan
+		b.mu.RUnlock()
 
-	if err := b.Subscribe(b.subscribedSymbols); err != nil {
+		log.Printf("[Binance] Reconnecting in %v...", delay)
+		select {
+		case <-stop:
+			return
+		case <-time.After(delay):
+		}
+
+		err := b.dial()
+		if err == nil {
+			return
+		}
 		log.Printf("[Binance] Reconnect failed: %v", err)
-		go b.reconnect()
+		delay *= 2
+		if delay > binanceMaxReconnectDelay {
+			delay = binanceMaxReconnectDelay
+		}
 	}
 }
 
@@ -0,0 +1,47 @@
+package adapters
+
+import "testing"
+
+// TestParseBookTicker tests that a combined stream bookTicker message maps to a
+// quote on the normalized symbol and other messages are ignored
+func TestParseBookTicker(t *testing.T) {
+	msg := []byte(`{"stream":"btcusdt@bookTicker","data":{"u":400900217,"s":"BTCUSDT","b":"64250.10000000","B":"1.25000000","a":"64250.20000000","A":"0.40000000"}}`)
+
+	quote, ok := parseBookTicker(msg, "binance")
+	if !ok {
+		t.Fatal("parseBookTicker() rejected a bookTicker message")
+	}
+	if quote.Symbol != "BTCUSD" || quote.Bid != 64250.10 || quote.Ask != 64250.20 || quote.LP != "binance" || quote.Timestamp == 0 {
+		t.Errorf("quote = %+v, want BTCUSD 64250.1/64250.2 from binance", quote)
+	}
+
+	for _, ignored := range []string{
+		`{"result":null,"id":1}`,
+		`{"stream":"ethusdt@bookTicker","data":{"s":"ETHUSDT","b":"0","a":"3100.5"}}`,
+		`not json`,
+	} {
+		if q, ok := parseBookTicker([]byte(ignored), "binance"); ok {
+			t.Errorf("parseBookTicker(%s) = %+v, want ignored", ignored, q)
+		}
+	}
+}
+
+// TestBinanceSymbolMapping tests the pair to symbol mapping both ways
+func TestBinanceSymbolMapping(t *testing.T) {
+	tests := []struct{ pair, symbol string }{
+		{"BTCUSDT", "BTCUSD"},
+		{"USDCUSDT", "USDCUSD"},
+		{"AVAXUSDT", "AVAXUSD"}, // not in the table
+	}
+	for _, tt := range tests {
+		if got := NormalizeBinanceSymbol(tt.pair); got != tt.symbol {
+			t.Errorf("NormalizeBinanceSymbol(%q) = %q, want %q", tt.pair, got, tt.symbol)
+		}
+		if got := ToBinanceSymbol(tt.symbol); got != tt.pair {
+			t.Errorf("ToBinanceSymbol(%q) = %q, want %q", tt.symbol, got, tt.pair)
+		}
+	}
+	if got := bookTickerStream("ethusd"); got != "ethusdt@bookTicker" {
+		t.Errorf("bookTickerStream(ethusd) = %q", got)
+	}
+}
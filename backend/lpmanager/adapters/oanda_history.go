@@ -0,0 +1,51 @@
+package adapters
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/epic1st/rtx/backend/oanda"
+	"github.com/epic1st/rtx/backend/tickstore"
+)
+
+// FetchHistory fetches symbol's OANDA candles in [from, to) and converts each
+// to a tick at the candle's close, ready for the history backfill
+func (o *OANDAAdapter) FetchHistory(symbol, granularity string, from, to time.Time) ([]tickstore.Tick, error) {
+	o.mu.Lock()
+	if o.client == nil {
+		o.client = oanda.NewClient(o.apiKey)
+	}
+	client := o.client
+	o.mu.Unlock()
+
+	candles, err := client.GetCandles(oanda.ToInstrument(symbol), granularity, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return candlesToTicks(oanda.FromInstrument(oanda.ToInstrument(symbol)), candles), nil
+}
+
+// candlesToTicks turns bid/ask candles into close-price ticks, skipping
+// candles missing either side
+func candlesToTicks(symbol string, candles []oanda.Candle) []tickstore.Tick {
+	ticks := make([]tickstore.Tick, 0, len(candles))
+	for _, candle := range candles {
+		if candle.Bid == nil || candle.Ask == nil {
+			continue
+		}
+		bid, errBid := strconv.ParseFloat(candle.Bid.C, 64)
+		ask, errAsk := strconv.ParseFloat(candle.Ask.C, 64)
+		if errBid != nil || errAsk != nil || bid <= 0 || ask <= 0 {
+			continue
+		}
+		ticks = append(ticks, tickstore.Tick{
+			Symbol:    symbol,
+			Bid:       bid,
+			Ask:       ask,
+			Spread:    ask - bid,
+			Timestamp: candle.Time,
+			LP:        "OANDA",
+		})
+	}
+	return ticks
+}
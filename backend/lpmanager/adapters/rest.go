@@ -0,0 +1,334 @@
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/epic1st/rtx/backend/lpmanager"
+)
+
+const (
+	restDefaultPollInterval = time.Second
+	restMinPollInterval     = 100 * time.Millisecond
+)
+
+// RESTAdapter implements LPAdapter by polling a vendor HTTP endpoint described
+// in lp_config.json, so a data vendor is onboarded without writing an adapter
+type RESTAdapter struct {
+	id         string
+	name       string
+	feed       lpmanager.RESTFeedConfig
+	configured []string
+	interval   time.Duration
+	httpClient *http.Client
+	quotesChan chan lpmanager.Quote
+	stopChan   chan struct{}
+	mu         sync.RWMutex
+	connected  bool
+	polling    bool
+	subscribed map[string]bool
+	lastTick   time.Time
+	errorMsg   string
+}
+
+// NewRESTAdapter creates a polling adapter from an LP config of type REST
+func NewRESTAdapter(cfg lpmanager.LPConfig) (*RESTAdapter, error) {
+	if cfg.REST == nil || cfg.REST.URL == "" {
+		return nil, fmt.Errorf("LP %s: rest.url is required", cfg.ID)
+	}
+	if cfg.REST.BidPath == "" || cfg.REST.AskPath == "" {
+		for _, sym := range cfg.Symbols {
+			if p, ok := cfg.REST.SymbolPaths[sym]; !ok || p.BidPath == "" || p.AskPath == "" {
+				return nil, fmt.Errorf("LP %s: no bid/ask path for %s", cfg.ID, sym)
+			}
+		}
+	}
+
+	interval := restDefaultPollInterval
+	if cfg.REST.PollIntervalMs > 0 {
+		interval = time.Duration(cfg.REST.PollIntervalMs) * time.Millisecond
+	}
+	if interval < restMinPollInterval {
+		interval = restMinPollInterval
+	}
+
+	name := cfg.Name
+	if name == "" {
+		name = cfg.ID
+	}
+	return &RESTAdapter{
+		id:         cfg.ID,
+		name:       name,
+		feed:       *cfg.REST,
+		configured: cfg.Symbols,
+		interval:   interval,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		quotesChan: make(chan lpmanager.Quote, 500),
+		stopChan:   make(chan struct{}),
+		subscribed: make(map[string]bool),
+	}, nil
+}
+
+func (r *RESTAdapter) ID() string   { return r.id }
+func (r *RESTAdapter) Name() string { return r.name }
+func (r *RESTAdapter) Type() string { return "REST" }
+
+func (r *RESTAdapter) IsConnected() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.connected
+}
+
+func (r *RESTAdapter) GetStatus() lpmanager.LPStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return lpmanager.LPStatus{
+		ID:           r.id,
+		Name:         r.name,
+		Type:         "REST",
+		Connected:    r.connected,
+		Enabled:      true,
+		SymbolCount:  len(r.configured),
+		LastTick:     r.lastTick,
+		ErrorMessage: r.errorMsg,
+	}
+}
+
+func (r *RESTAdapter) GetQuotesChan() <-chan lpmanager.Quote {
+	return r.quotesChan
+}
+
+// GetSymbols returns the symbols configured for the feed
+func (r *RESTAdapter) GetSymbols() ([]lpmanager.SymbolInfo, error) {
+	symbols := make([]lpmanager.SymbolInfo, 0, len(r.configured))
+	for _, sym := range r.configured {
+		symbols = append(symbols, lpmanager.SymbolInfo{Symbol: sym, DisplayName: sym})
+	}
+	return symbols, nil
+}
+
+// Connect marks the feed ready; polling starts on Subscribe
+func (r *RESTAdapter) Connect() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	select {
+	case <-r.stopChan:
+		// Reconnecting after Disconnect
+		r.stopChan = make(chan struct{})
+	default:
+	}
+	r.connected = true
+	r.errorMsg = ""
+	log.Printf("[REST:%s] Connected, polling %s every %v", r.id, r.feed.URL, r.interval)
+	return nil
+}
+
+// Subscribe adds symbols to the poll and starts polling
+func (r *RESTAdapter) Subscribe(symbols []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, sym := range symbols {
+		r.subscribed[strings.ToUpper(sym)] = true
+	}
+	if !r.polling && len(r.subscribed) > 0 {
+		r.polling = true
+		go r.pollLoop(r.stopChan)
+	}
+	return nil
+}
+
+// Unsubscribe removes symbols from the poll
+func (r *RESTAdapter) Unsubscribe(symbols []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, sym := range symbols {
+		delete(r.subscribed, strings.ToUpper(sym))
+	}
+	return nil
+}
+
+func (r *RESTAdapter) Disconnect() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.connected || r.polling {
+		close(r.stopChan)
+	}
+	r.connected = false
+	r.polling = false
+	return nil
+}
+
+// pollLoop polls once per interval until stop closes
+func (r *RESTAdapter) pollLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	r.poll()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			r.poll()
+		}
+	}
+}
+
+// poll fetches every subscribed symbol's URL once, so symbols sharing a URL
+// cost a single request
+func (r *RESTAdapter) poll() {
+	r.mu.RLock()
+	byURL := make(map[string][]string)
+	for sym := range r.subscribed {
+		url := strings.ReplaceAll(r.feed.URL, "{symbol}", r.vendorSymbol(sym))
+		byURL[url] = append(byURL[url], sym)
+	}
+	r.mu.RUnlock()
+
+	urls := make([]string, 0, len(byURL))
+	for url := range byURL {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	for _, url := range urls {
+		doc, err := r.fetch(url)
+		if err != nil {
+			r.mu.Lock()
+			r.errorMsg = err.Error()
+			r.mu.Unlock()
+			log.Printf("[REST:%s] Poll failed: %v", r.id, err)
+			continue
+		}
+		for _, sym := range byURL[url] {
+			quote, err := r.extractQuote(doc, sym)
+			if err != nil {
+				log.Printf("[REST:%s] %v", r.id, err)
+				continue
+			}
+			r.emit(quote)
+		}
+	}
+}
+
+// fetch GETs url and decodes its JSON body
+func (r *RESTAdapter) fetch(url string) (interface{}, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if r.feed.AuthHeader != "" {
+		req.Header.Set(r.feed.AuthHeader, r.feed.AuthValue)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	var doc interface{}
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("GET %s: %w", url, err)
+	}
+	return doc, nil
+}
+
+// extractQuote reads symbol's bid and ask from a decoded response
+func (r *RESTAdapter) extractQuote(doc interface{}, symbol string) (lpmanager.Quote, error) {
+	bidPath, askPath := r.feed.BidPath, r.feed.AskPath
+	if p, ok := r.feed.SymbolPaths[symbol]; ok {
+		bidPath, askPath = p.BidPath, p.AskPath
+	}
+	vendor := r.vendorSymbol(symbol)
+
+	bid, err := jsonPathFloat(doc, strings.ReplaceAll(bidPath, "{symbol}", vendor))
+	if err != nil {
+		return lpmanager.Quote{}, fmt.Errorf("%s bid: %w", symbol, err)
+	}
+	ask, err := jsonPathFloat(doc, strings.ReplaceAll(askPath, "{symbol}", vendor))
+	if err != nil {
+		return lpmanager.Quote{}, fmt.Errorf("%s ask: %w", symbol, err)
+	}
+	if bid <= 0 || ask <= 0 {
+		return lpmanager.Quote{}, fmt.Errorf("%s: non-positive price %v/%v", symbol, bid, ask)
+	}
+
+	return lpmanager.Quote{
+		Symbol:    symbol,
+		Bid:       bid,
+		Ask:       ask,
+		Timestamp: time.Now().UnixMilli(),
+		LP:        r.id,
+	}, nil
+}
+
+// vendorSymbol returns the vendor's name for a platform symbol
+func (r *RESTAdapter) vendorSymbol(symbol string) string {
+	if v, ok := r.feed.VendorSymbols[symbol]; ok {
+		return v
+	}
+	return symbol
+}
+
+func (r *RESTAdapter) emit(quote lpmanager.Quote) {
+	r.mu.Lock()
+	r.lastTick = time.Now()
+	r.errorMsg = ""
+	r.mu.Unlock()
+
+	select {
+	case r.quotesChan <- quote:
+	default:
+	}
+}
+
+// jsonPathFloat follows a dot path of object keys and array indexes through a
+// decoded JSON document to a number or numeric string
+func jsonPathFloat(doc interface{}, path string) (float64, error) {
+	node := doc
+	for _, key := range strings.Split(path, ".") {
+		switch v := node.(type) {
+		case map[string]interface{}:
+			next, ok := v[key]
+			if !ok {
+				return 0, fmt.Errorf("path %q: no key %q", path, key)
+			}
+			node = next
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return 0, fmt.Errorf("path %q: bad index %q", path, key)
+			}
+			node = v[i]
+		default:
+			return 0, fmt.Errorf("path %q: %q is not an object or array", path, key)
+		}
+	}
+
+	switch v := node.(type) {
+	case json.Number:
+		return v.Float64()
+	case string:
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fmt.Errorf("path %q: value is not a number", path)
+	}
+}
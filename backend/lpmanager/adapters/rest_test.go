@@ -0,0 +1,85 @@
+package adapters
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/lpmanager"
+)
+
+// TestRESTAdapterPollsQuotes tests that quotes polled from a vendor endpoint
+// reach the channel with the platform symbol and the configured fields
+func TestRESTAdapterPollsQuotes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "vendor-key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Query().Get("pair") {
+		case "EUR/USD":
+			w.Write([]byte(`{"data":{"EUR/USD":{"bid":1.08245,"ask":"1.08255"}}}`))
+		case "XAU/USD":
+			w.Write([]byte(`{"quotes":[{"b":2650.1,"a":2650.6}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	adapter, err := NewRESTAdapter(lpmanager.LPConfig{
+		ID:      "vendor",
+		Type:    "REST",
+		Symbols: []string{"EURUSD", "XAUUSD"},
+		REST: &lpmanager.RESTFeedConfig{
+			URL:           srv.URL + "/quotes?pair={symbol}",
+			AuthHeader:    "X-API-Key",
+			AuthValue:     "vendor-key",
+			BidPath:       "data.{symbol}.bid",
+			AskPath:       "data.{symbol}.ask",
+			VendorSymbols: map[string]string{"EURUSD": "EUR/USD", "XAUUSD": "XAU/USD"},
+			SymbolPaths: map[string]lpmanager.RESTFieldPaths{
+				"XAUUSD": {BidPath: "quotes.0.b", AskPath: "quotes.0.a"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewRESTAdapter() error = %v", err)
+	}
+	if err := adapter.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer adapter.Disconnect()
+	adapter.Subscribe([]string{"EURUSD", "XAUUSD"})
+
+	want := map[string][2]float64{"EURUSD": {1.08245, 1.08255}, "XAUUSD": {2650.1, 2650.6}}
+	got := make(map[string]lpmanager.Quote)
+	timeout := time.After(2 * time.Second)
+	for len(got) < len(want) {
+		select {
+		case q := <-adapter.GetQuotesChan():
+			got[q.Symbol] = q
+		case <-timeout:
+			t.Fatalf("got quotes %v, want both symbols", got)
+		}
+	}
+	for sym, prices := range want {
+		if q := got[sym]; q.Bid != prices[0] || q.Ask != prices[1] || q.LP != "vendor" {
+			t.Errorf("%s quote = %+v, want %v/%v from vendor", sym, q, prices[0], prices[1])
+		}
+	}
+}
+
+// TestNewRESTAdapterRequiresPaths tests that a feed without bid/ask paths for
+// a symbol is rejected
+func TestNewRESTAdapterRequiresPaths(t *testing.T) {
+	_, err := NewRESTAdapter(lpmanager.LPConfig{
+		ID:      "vendor",
+		Symbols: []string{"EURUSD"},
+		REST:    &lpmanager.RESTFeedConfig{URL: "http://vendor/quotes"},
+	})
+	if err == nil {
+		t.Error("NewRESTAdapter() without paths succeeded, want an error")
+	}
+}
@@ -0,0 +1,183 @@
+package lpmanager
+
+import (
+	"sync"
+	"time"
+)
+
+// AggregatedLP is the LP source name of synthetic best bid/offer quotes
+const AggregatedLP = "AGG"
+
+// bboStaleAfter excludes the sides of LPs that stopped updating from the best bid/offer
+const bboStaleAfter = crossedBookStaleAfter
+
+// AggregatedQuote is the best bid and best ask of a symbol across all LPs
+type AggregatedQuote struct {
+	Symbol    string  `json:"symbol"`
+	Bid       float64 `json:"bid"`             // 0 = no live bid on any LP
+	Ask       float64 `json:"ask"`             // 0 = no live ask on any LP
+	BidLP     string  `json:"bidLp,omitempty"` // LP quoting the best bid
+	AskLP     string  `json:"askLp,omitempty"` // LP quoting the best ask
+	LPCount   int     `json:"lpCount"`         // LPs with at least one live side
+	Crossed   bool    `json:"crossed"`         // Best bid at or above best ask
+	Timestamp int64   `json:"timestamp"`       // Latest quote of the sides used
+}
+
+// Quote returns the aggregate as a quote from the AGG source
+func (a *AggregatedQuote) Quote() Quote {
+	return Quote{Symbol: a.Symbol, Bid: a.Bid, Ask: a.Ask, Timestamp: a.Timestamp, LP: AggregatedLP}
+}
+
+// bboSide is one side of an LP's latest quote
+type bboSide struct {
+	price      float64
+	timestamp  int64
+	receivedAt time.Time
+}
+
+// bboEntry is an LP's latest quote, the sides updated independently so a
+// one-sided quote keeps the other side's last price
+type bboEntry struct {
+	bid, ask bboSide
+}
+
+// bboAggregator tracks the latest bid and ask of every LP per symbol
+type bboAggregator struct {
+	mu    sync.Mutex
+	books map[string]map[string]*bboEntry // symbol -> LP -> latest sides
+	last  map[string]AggregatedQuote      // Last aggregate broadcast per symbol
+}
+
+func newBBOAggregator() *bboAggregator {
+	return &bboAggregator{
+		books: make(map[string]map[string]*bboEntry),
+		last:  make(map[string]AggregatedQuote),
+	}
+}
+
+// update records an LP quote. A side priced 0 is missing and leaves that side
+// as it was; a two-sided quote crossed on its own is ignored.
+func (b *bboAggregator) update(quote Quote, now time.Time) {
+	if quote.Bid > 0 && quote.Ask > 0 && quote.Bid >= quote.Ask {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	book, ok := b.books[quote.Symbol]
+	if !ok {
+		book = make(map[string]*bboEntry)
+		b.books[quote.Symbol] = book
+	}
+	entry, ok := book[quote.LP]
+	if !ok {
+		entry = &bboEntry{}
+		book[quote.LP] = entry
+	}
+	if quote.Bid > 0 {
+		entry.bid = bboSide{price: quote.Bid, timestamp: quote.Timestamp, receivedAt: now}
+	}
+	if quote.Ask > 0 {
+		entry.ask = bboSide{price: quote.Ask, timestamp: quote.Timestamp, receivedAt: now}
+	}
+}
+
+// remove drops every quote of an LP, e.g. once it disconnects
+func (b *bboAggregator) remove(lpID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, book := range b.books {
+		delete(book, lpID)
+	}
+}
+
+// get computes the best bid/offer of a symbol from the live sides
+func (b *bboAggregator) get(symbol string, now time.Time) (*AggregatedQuote, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.getLocked(symbol, now)
+}
+
+func (b *bboAggregator) getLocked(symbol string, now time.Time) (*AggregatedQuote, bool) {
+	agg := &AggregatedQuote{Symbol: symbol}
+	for lp, entry := range b.books[symbol] {
+		live := false
+		if entry.bid.price > 0 && now.Sub(entry.bid.receivedAt) <= bboStaleAfter {
+			live = true
+			if entry.bid.price > agg.Bid || (entry.bid.price == agg.Bid && lp < agg.BidLP) {
+				agg.Bid, agg.BidLP = entry.bid.price, lp
+			}
+			if entry.bid.timestamp > agg.Timestamp {
+				agg.Timestamp = entry.bid.timestamp
+			}
+		}
+		if entry.ask.price > 0 && now.Sub(entry.ask.receivedAt) <= bboStaleAfter {
+			live = true
+			if agg.Ask == 0 || entry.ask.price < agg.Ask || (entry.ask.price == agg.Ask && lp < agg.AskLP) {
+				agg.Ask, agg.AskLP = entry.ask.price, lp
+			}
+			if entry.ask.timestamp > agg.Timestamp {
+				agg.Timestamp = entry.ask.timestamp
+			}
+		}
+		if live {
+			agg.LPCount++
+		}
+	}
+	if agg.LPCount == 0 {
+		return nil, false
+	}
+	agg.Crossed = agg.Bid > 0 && agg.Ask > 0 && agg.Bid >= agg.Ask
+	return agg, true
+}
+
+// changed returns the aggregate of a symbol when it is two-sided, uncrossed and
+// differs from the one last returned, for broadcasting
+func (b *bboAggregator) changed(symbol string, now time.Time) (*AggregatedQuote, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	agg, ok := b.getLocked(symbol, now)
+	if !ok || agg.Bid == 0 || agg.Ask == 0 || agg.Crossed {
+		return nil, false
+	}
+	if last, ok := b.last[symbol]; ok && last.Bid == agg.Bid && last.Ask == agg.Ask {
+		return nil, false
+	}
+	b.last[symbol] = *agg
+	return agg, true
+}
+
+// GetBBO returns the best bid (highest) and best ask (lowest) of a symbol
+// across all LPs with live quotes, and the LPs quoting them
+func (m *Manager) GetBBO(symbol string) (*AggregatedQuote, bool) {
+	return m.bbo.get(symbol, time.Now())
+}
+
+// SetBBOBroadcast sets whether the best bid/offer is published as quotes from the AGG source
+func (m *Manager) SetBBOBroadcast(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bboBroadcast = enabled
+}
+
+// publishBBO publishes the symbol's aggregate as an AGG quote when it changed
+func (m *Manager) publishBBO(symbol string, now time.Time) {
+	m.mu.RLock()
+	enabled := m.bboBroadcast
+	m.mu.RUnlock()
+	if !enabled {
+		return
+	}
+
+	agg, ok := m.bbo.changed(symbol, now)
+	if !ok {
+		return
+	}
+	select {
+	case m.quotesChan <- agg.Quote():
+	default:
+		// Channel full, drop quote
+	}
+}
@@ -0,0 +1,72 @@
+package lpmanager
+
+import (
+	"testing"
+	"time"
+)
+
+// TestBBOAcrossLPs tests that the best bid and best ask are taken from
+// different LPs and that a one-sided quote only moves its own side
+func TestBBOAcrossLPs(t *testing.T) {
+	m := newCrossedTestManager(t)
+
+	m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.1000, Ask: 1.1003, LP: "lp-a"})
+	m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.0999, Ask: 1.1002, LP: "lp-b"})
+
+	bbo, ok := m.GetBBO("EURUSD")
+	if !ok || bbo.Bid != 1.1000 || bbo.BidLP != "lp-a" || bbo.Ask != 1.1002 || bbo.AskLP != "lp-b" || bbo.LPCount != 2 {
+		t.Fatalf("GetBBO() = %+v, want bid 1.1000 from lp-a and ask 1.1002 from lp-b", bbo)
+	}
+
+	// lp-b improves only its bid; its ask is kept and the raw quote is not published
+	drainQuotes(m)
+	m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.1001, LP: "lp-b"})
+	if published := drainQuotes(m); len(published) != 0 {
+		t.Errorf("published = %+v, want no one-sided quote", published)
+	}
+	if bbo, _ := m.GetBBO("EURUSD"); bbo.Bid != 1.1001 || bbo.BidLP != "lp-b" || bbo.Ask != 1.1002 {
+		t.Errorf("GetBBO() = %+v, want bid 1.1001 from lp-b and ask 1.1002", bbo)
+	}
+
+	if _, ok := m.GetBBO("GBPUSD"); ok {
+		t.Error("GetBBO() returned a symbol no LP quotes")
+	}
+}
+
+// TestBBOExcludesStaleAndDroppedLPs tests that an LP that stopped quoting or
+// was stopped no longer contributes to the best bid/offer
+func TestBBOExcludesStaleAndDroppedLPs(t *testing.T) {
+	m := newCrossedTestManager(t)
+	now := time.Now()
+
+	m.bbo.update(Quote{Symbol: "EURUSD", Bid: 1.1005, Ask: 1.1006, LP: "lp-a"}, now.Add(-2*bboStaleAfter))
+	m.bbo.update(Quote{Symbol: "EURUSD", Bid: 1.1000, Ask: 1.1002, LP: "lp-b"}, now)
+	if bbo, ok := m.GetBBO("EURUSD"); !ok || bbo.BidLP != "lp-b" || bbo.AskLP != "lp-b" || bbo.LPCount != 1 {
+		t.Errorf("GetBBO() = %+v, want only lp-b's live quote", bbo)
+	}
+
+	m.stopLPLocked("lp-b")
+	if bbo, ok := m.GetBBO("EURUSD"); ok {
+		t.Errorf("GetBBO() = %+v after the only live LP stopped, want none", bbo)
+	}
+}
+
+// TestBBOBroadcast tests that the aggregate is published as an AGG quote when it changes
+func TestBBOBroadcast(t *testing.T) {
+	m := newCrossedTestManager(t)
+	m.SetBBOBroadcast(true)
+
+	m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.1000, Ask: 1.1003, LP: "lp-a"})
+	m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.0998, Ask: 1.1002, LP: "lp-b"})
+	m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.0997, Ask: 1.1002, LP: "lp-b"}) // BBO unchanged
+
+	var agg []Quote
+	for _, q := range drainQuotes(m) {
+		if q.LP == AggregatedLP {
+			agg = append(agg, q)
+		}
+	}
+	if len(agg) != 2 || agg[1].Bid != 1.1000 || agg[1].Ask != 1.1002 {
+		t.Errorf("AGG quotes = %+v, want 2 with the last at 1.1000/1.1002", agg)
+	}
+}
@@ -0,0 +1,205 @@
+package lpmanager
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Crossed market policies: what the aggregator does when the best bid across
+// LPs is at or above the best ask
+const (
+	CrossedPolicySuppress = "SUPPRESS" // Publish nothing for the symbol until the book uncrosses
+	CrossedPolicyFallback = "FALLBACK" // Publish only the highest-priority LP's own quotes
+)
+
+// crossedBookStaleAfter excludes quotes of LPs that stopped updating from the
+// crossed check, so a dropped LP cannot keep the book crossed
+const crossedBookStaleAfter = 10 * time.Second
+
+// CrossedMarketStats counts quotes suppressed because the aggregated book was crossed or locked
+type CrossedMarketStats struct {
+	Policy     string           `json:"policy"`
+	Suppressed int64            `json:"suppressed"`
+	BySymbol   map[string]int64 `json:"bySymbol"`
+}
+
+type bookEntry struct {
+	quote      Quote
+	receivedAt time.Time
+}
+
+// crossedMarketGuard tracks the latest quote of every LP per symbol and
+// decides whether an incoming quote may be published
+type crossedMarketGuard struct {
+	mu         sync.Mutex
+	policy     string
+	books      map[string]map[string]bookEntry // symbol -> LP -> latest quote
+	suppressed map[string]int64
+	crossed    map[string]bool // Symbols currently crossed, for logging transitions
+}
+
+func newCrossedMarketGuard() *crossedMarketGuard {
+	return &crossedMarketGuard{
+		policy:     CrossedPolicySuppress,
+		books:      make(map[string]map[string]bookEntry),
+		suppressed: make(map[string]int64),
+		crossed:    make(map[string]bool),
+	}
+}
+
+// NormalizeCrossedPolicy validates a crossed market policy name. Empty selects suppression.
+func NormalizeCrossedPolicy(policy string) (string, error) {
+	switch strings.ToUpper(policy) {
+	case "", CrossedPolicySuppress:
+		return CrossedPolicySuppress, nil
+	case CrossedPolicyFallback:
+		return CrossedPolicyFallback, nil
+	default:
+		return "", fmt.Errorf("invalid crossed market policy %q: must be %s or %s", policy, CrossedPolicySuppress, CrossedPolicyFallback)
+	}
+}
+
+// allow records the quote in the book and reports whether it may be published.
+// priority returns an LP's configured priority (lower wins) for the fallback policy.
+func (g *crossedMarketGuard) allow(quote Quote, now time.Time, priority func(lpID string) int) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// A quote crossed on its own is never usable
+	if quote.Bid >= quote.Ask {
+		g.suppressed[quote.Symbol]++
+		return false
+	}
+
+	book, ok := g.books[quote.Symbol]
+	if !ok {
+		book = make(map[string]bookEntry)
+		g.books[quote.Symbol] = book
+	}
+	book[quote.LP] = bookEntry{quote: quote, receivedAt: now}
+
+	var bestBid, bestAsk float64
+	first := true
+	fallbackLP := ""
+	for lp, entry := range book {
+		if now.Sub(entry.receivedAt) > crossedBookStaleAfter {
+			delete(book, lp)
+			continue
+		}
+		if first || entry.quote.Bid > bestBid {
+			bestBid = entry.quote.Bid
+		}
+		if first || entry.quote.Ask < bestAsk {
+			bestAsk = entry.quote.Ask
+		}
+		first = false
+		if fallbackLP == "" || priority(lp) < priority(fallbackLP) ||
+			(priority(lp) == priority(fallbackLP) && lp < fallbackLP) {
+			fallbackLP = lp
+		}
+	}
+
+	if bestBid < bestAsk {
+		if g.crossed[quote.Symbol] {
+			delete(g.crossed, quote.Symbol)
+			log.Printf("[LPManager] %s book uncrossed, resuming aggregated quotes", quote.Symbol)
+		}
+		return true
+	}
+
+	if !g.crossed[quote.Symbol] {
+		g.crossed[quote.Symbol] = true
+		log.Printf("[LPManager] %s book crossed/locked across LPs: best bid %.5f >= best ask %.5f (%s)",
+			quote.Symbol, bestBid, bestAsk, g.policy)
+	}
+	if g.policy == CrossedPolicyFallback && quote.LP == fallbackLP {
+		return true
+	}
+	g.suppressed[quote.Symbol]++
+	return false
+}
+
+func (g *crossedMarketGuard) setPolicy(policy string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.policy = policy
+}
+
+func (g *crossedMarketGuard) stats() CrossedMarketStats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	stats := CrossedMarketStats{Policy: g.policy, BySymbol: make(map[string]int64, len(g.suppressed))}
+	for symbol, n := range g.suppressed {
+		stats.BySymbol[symbol] = n
+		stats.Suppressed += n
+	}
+	return stats
+}
+
+// SetCrossedMarketPolicy sets how quotes are handled while the aggregated book is crossed or locked
+func (m *Manager) SetCrossedMarketPolicy(policy string) error {
+	normalized, err := NormalizeCrossedPolicy(policy)
+	if err != nil {
+		return err
+	}
+	m.crossGuard.setPolicy(normalized)
+	return nil
+}
+
+// GetCrossedMarketStats returns the counters of quotes suppressed by the crossed market check
+func (m *Manager) GetCrossedMarketStats() CrossedMarketStats {
+	return m.crossGuard.stats()
+}
+
+// snapshotPrioritiesLocked copies the LP priorities of the config for
+// lpPriority (caller must hold m.mu)
+func (m *Manager) snapshotPrioritiesLocked() {
+	priorities := make(map[string]int)
+	if m.config != nil {
+		for _, lp := range m.config.LPs {
+			priorities[lp.ID] = lp.Priority
+		}
+	}
+	m.priorities.Store(priorities)
+}
+
+// lpPriority returns the configured priority of an LP (lower = preferred). It
+// reads the config snapshot rather than m.mu, since the crossed market guard
+// calls it under its own lock.
+func (m *Manager) lpPriority(lpID string) int {
+	if priorities, ok := m.priorities.Load().(map[string]int); ok {
+		if priority, ok := priorities[lpID]; ok {
+			return priority
+		}
+	}
+	return math.MaxInt // Unconfigured LPs rank last
+}
+
+// publishQuote forwards an LP quote to the aggregated channel unless it comes
+// from an LP the symbol failed over from, or would produce a crossed or locked
+// market. One-sided quotes only update the best bid/offer.
+func (m *Manager) publishQuote(quote Quote) {
+	now := time.Now()
+	m.bbo.update(quote, now)
+	defer m.publishBBO(quote.Symbol, now)
+
+	if quote.Bid <= 0 || quote.Ask <= 0 {
+		return
+	}
+	if !m.failover.allow(quote, now, m.lpConnected) {
+		return
+	}
+	if !m.crossGuard.allow(quote, now, m.lpPriority) {
+		return
+	}
+	select {
+	case m.quotesChan <- quote:
+	default:
+		// Channel full, drop quote
+	}
+}
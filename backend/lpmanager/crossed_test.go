@@ -0,0 +1,122 @@
+package lpmanager
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newCrossedTestManager(t *testing.T) *Manager {
+	t.Helper()
+	m := NewManager(filepath.Join(t.TempDir(), "lp_config.json"))
+	m.config = &LPManagerConfig{LPs: []LPConfig{
+		{ID: "lp-a", Priority: 1, Enabled: true},
+		{ID: "lp-b", Priority: 2, Enabled: true},
+	}}
+	m.snapshotPrioritiesLocked()
+	return m
+}
+
+// drainQuotes returns the quotes published so far
+func drainQuotes(m *Manager) []Quote {
+	var quotes []Quote
+	for {
+		select {
+		case q := <-m.quotesChan:
+			quotes = append(quotes, q)
+		default:
+			return quotes
+		}
+	}
+}
+
+// TestCrossedLPsQuoteSuppressed tests that quotes crossing another LP's book are not published
+func TestCrossedLPsQuoteSuppressed(t *testing.T) {
+	m := newCrossedTestManager(t)
+
+	m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.1000, Ask: 1.1002, LP: "lp-a"})
+	// lp-b bids above lp-a's ask: best bid 1.1003 >= best ask 1.1002
+	m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.1003, Ask: 1.1005, LP: "lp-b"})
+	// lp-a stays crossed against lp-b's bid
+	m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.1001, Ask: 1.1003, LP: "lp-a"})
+	// An LP quote crossed on its own
+	m.publishQuote(Quote{Symbol: "GBPUSD", Bid: 1.2505, Ask: 1.2500, LP: "lp-a"})
+
+	published := drainQuotes(m)
+	if len(published) != 1 || published[0].LP != "lp-a" || published[0].Bid != 1.1000 {
+		t.Fatalf("published = %+v, want only the first uncrossed lp-a quote", published)
+	}
+
+	stats := m.GetCrossedMarketStats()
+	if stats.Suppressed != 3 || stats.BySymbol["EURUSD"] != 2 || stats.BySymbol["GBPUSD"] != 1 {
+		t.Errorf("stats = %+v, want 3 suppressed (EURUSD 2, GBPUSD 1)", stats)
+	}
+
+	// Once lp-b moves back, the book uncrosses and quotes flow again
+	m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.1001, Ask: 1.1004, LP: "lp-b"})
+	if published := drainQuotes(m); len(published) != 1 || published[0].LP != "lp-b" {
+		t.Errorf("published after uncross = %+v, want the lp-b quote", published)
+	}
+}
+
+// TestCrossedLPsFallBackToPreferredLP tests that the fallback policy only publishes the highest-priority LP while crossed
+func TestCrossedLPsFallBackToPreferredLP(t *testing.T) {
+	m := newCrossedTestManager(t)
+	if err := m.SetCrossedMarketPolicy("fallback"); err != nil {
+		t.Fatalf("SetCrossedMarketPolicy() error = %v", err)
+	}
+
+	m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.1000, Ask: 1.1002, LP: "lp-a"})
+	m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.1002, Ask: 1.1004, LP: "lp-b"}) // Locked: bid == ask
+	m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.0999, Ask: 1.1001, LP: "lp-a"})
+
+	published := drainQuotes(m)
+	if len(published) != 2 {
+		t.Fatalf("published = %+v, want the two lp-a quotes", published)
+	}
+	for _, q := range published {
+		if q.LP != "lp-a" || q.Bid >= q.Ask {
+			t.Errorf("published %+v, want only uncrossed lp-a quotes", q)
+		}
+	}
+	if stats := m.GetCrossedMarketStats(); stats.Suppressed != 1 || stats.Policy != CrossedPolicyFallback {
+		t.Errorf("stats = %+v, want 1 suppressed under FALLBACK", stats)
+	}
+
+	if err := m.SetCrossedMarketPolicy("bogus"); err == nil {
+		t.Error("invalid policy accepted")
+	}
+}
+
+// TestConfigReloadDuringQuotes tests that reloading the config while quotes
+// are published neither deadlocks nor loses the LP priorities
+func TestConfigReloadDuringQuotes(t *testing.T) {
+	m := newCrossedTestManager(t)
+	if err := m.SaveConfig(); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			if err := m.LoadConfig(); err != nil {
+				t.Errorf("LoadConfig() error = %v", err)
+				return
+			}
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.1000, Ask: 1.1002, LP: "lp-b"})
+		drainQuotes(m)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("LoadConfig and publishQuote deadlocked")
+	}
+
+	if got := m.lpPriority("lp-a"); got != 1 {
+		t.Errorf("lp-a priority = %d after reloads, want 1", got)
+	}
+}
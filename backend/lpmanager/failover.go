@@ -0,0 +1,198 @@
+package lpmanager
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// defaultFailoverStaleAfter is how long an LP may go without quoting a symbol
+// before the feed fails over to the next LP in the symbol's priority list
+const defaultFailoverStaleAfter = 5 * time.Second
+
+// Failover reasons: why the previously active LP lost a symbol
+const (
+	FailoverReasonStale        = "STALE"        // Stopped quoting the symbol
+	FailoverReasonDisconnected = "DISCONNECTED" // Adapter reports disconnected
+	FailoverReasonRecovered    = "RECOVERED"    // A higher-priority LP is quoting again
+)
+
+// FailoverEvent records a symbol's price feed switching LPs
+type FailoverEvent struct {
+	Symbol    string    `json:"symbol"`
+	FromLP    string    `json:"fromLp"`
+	ToLP      string    `json:"toLp"` // Empty: no LP in the list is live
+	Reason    string    `json:"reason"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// failoverSelector picks each symbol's active LP from its ordered preference
+// list: the first LP that is connected and quoted the symbol recently
+type failoverSelector struct {
+	mu         sync.Mutex
+	priorities map[string][]string             // symbol -> preferred LPs, best first
+	lastQuote  map[string]map[string]time.Time // symbol -> LP -> last quote received
+	active     map[string]string
+	staleAfter time.Duration
+	onEvent    func(FailoverEvent)
+}
+
+func newFailoverSelector() *failoverSelector {
+	return &failoverSelector{
+		priorities: make(map[string][]string),
+		lastQuote:  make(map[string]map[string]time.Time),
+		active:     make(map[string]string),
+		staleAfter: defaultFailoverStaleAfter,
+	}
+}
+
+// allow records the quote and reports whether it comes from the symbol's
+// active LP. Symbols without a priority list accept every LP.
+func (f *failoverSelector) allow(quote Quote, now time.Time, connected func(lpID string) bool) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.priorities[quote.Symbol]; !ok {
+		return true
+	}
+	last, ok := f.lastQuote[quote.Symbol]
+	if !ok {
+		last = make(map[string]time.Time)
+		f.lastQuote[quote.Symbol] = last
+	}
+	last[quote.LP] = now
+
+	return f.selectLocked(quote.Symbol, now, connected) == quote.LP
+}
+
+// selectLocked re-evaluates a symbol's active LP, recording any switch (caller must hold lock)
+func (f *failoverSelector) selectLocked(symbol string, now time.Time, connected func(lpID string) bool) string {
+	live := func(lp string) bool {
+		received, ok := f.lastQuote[symbol][lp]
+		return ok && now.Sub(received) <= f.staleAfter && connected(lp)
+	}
+
+	next := ""
+	for _, lp := range f.priorities[symbol] {
+		if live(lp) {
+			next = lp
+			break
+		}
+	}
+
+	prev := f.active[symbol]
+	if next == prev {
+		return next
+	}
+	f.active[symbol] = next
+	if prev == "" {
+		return next
+	}
+
+	reason := FailoverReasonStale
+	switch {
+	case next != "" && f.rank(symbol, next) < f.rank(symbol, prev):
+		reason = FailoverReasonRecovered
+	case !connected(prev):
+		reason = FailoverReasonDisconnected
+	}
+	event := FailoverEvent{Symbol: symbol, FromLP: prev, ToLP: next, Reason: reason, Timestamp: now}
+	log.Printf("[LPManager] %s price feed failover %s -> %s (%s)", symbol, prev, next, reason)
+	if f.onEvent != nil {
+		go f.onEvent(event)
+	}
+	return next
+}
+
+// rank returns an LP's position in a symbol's list (caller must hold lock)
+func (f *failoverSelector) rank(symbol, lp string) int {
+	for i, id := range f.priorities[symbol] {
+		if id == lp {
+			return i
+		}
+	}
+	return len(f.priorities[symbol])
+}
+
+// activeLP returns a symbol's active LP as of now
+func (f *failoverSelector) activeLP(symbol string, now time.Time, connected func(lpID string) bool) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.priorities[symbol]; !ok {
+		return ""
+	}
+	return f.selectLocked(symbol, now, connected)
+}
+
+func (f *failoverSelector) setPriorities(priorities map[string][]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.priorities = make(map[string][]string, len(priorities))
+	for symbol, lps := range priorities {
+		if len(lps) > 0 {
+			f.priorities[symbol] = append([]string(nil), lps...)
+		}
+	}
+	for symbol := range f.active {
+		if _, ok := f.priorities[symbol]; !ok {
+			delete(f.active, symbol)
+		}
+	}
+}
+
+func (f *failoverSelector) setStaleAfter(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.staleAfter = d
+}
+
+func (f *failoverSelector) setCallback(fn func(FailoverEvent)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onEvent = fn
+}
+
+// remove forgets an LP's quotes when it stops
+func (f *failoverSelector) remove(lpID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, last := range f.lastQuote {
+		delete(last, lpID)
+	}
+}
+
+// SetSymbolPriorities sets each symbol's LPs in order of preference. The
+// symbol's price feed comes from the first LP quoting it live and fails over
+// down the list. Symbols without a list take quotes from every LP.
+func (m *Manager) SetSymbolPriorities(priorities map[string][]string) {
+	m.failover.setPriorities(priorities)
+}
+
+// SetFailoverStaleAfter sets how long an LP may go without quoting a symbol
+// before its feed fails over
+func (m *Manager) SetFailoverStaleAfter(d time.Duration) {
+	if d <= 0 {
+		d = defaultFailoverStaleAfter
+	}
+	m.failover.setStaleAfter(d)
+}
+
+// SetFailoverCallback sets the function called when a symbol's feed switches LPs
+func (m *Manager) SetFailoverCallback(fn func(FailoverEvent)) {
+	m.failover.setCallback(fn)
+}
+
+// GetActiveLPForSymbol returns the LP currently feeding a symbol's prices, or
+// "" if the symbol has no priority list or none of its LPs is live
+func (m *Manager) GetActiveLPForSymbol(symbol string) string {
+	return m.failover.activeLP(symbol, time.Now(), m.lpConnected)
+}
+
+// lpConnected reports whether an LP's adapter is connected. Quotes from LPs
+// without a registered adapter are taken at face value.
+func (m *Manager) lpConnected(lpID string) bool {
+	adapter, ok := m.registry.Get(lpID)
+	return !ok || adapter.IsConnected()
+}
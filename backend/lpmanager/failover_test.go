@@ -0,0 +1,87 @@
+package lpmanager
+
+import (
+	"testing"
+	"time"
+)
+
+// TestFailoverToBackupWhenPrimaryStale tests that the backup's quotes flow
+// once the primary stops quoting, a failover event is emitted, and the
+// primary takes over again when it resumes
+func TestFailoverToBackupWhenPrimaryStale(t *testing.T) {
+	m := newCrossedTestManager(t)
+	m.SetSymbolPriorities(map[string][]string{"EURUSD": {"lp-a", "lp-b"}})
+	m.SetFailoverStaleAfter(50 * time.Millisecond)
+	events := make(chan FailoverEvent, 4)
+	m.SetFailoverCallback(func(e FailoverEvent) { events <- e })
+
+	m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.1000, Ask: 1.1002, LP: "lp-a"})
+	m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.1000, Ask: 1.1003, LP: "lp-b"})
+	if published := drainQuotes(m); len(published) != 1 || published[0].LP != "lp-a" {
+		t.Fatalf("published = %+v, want only the primary's quote", published)
+	}
+	if lp := m.GetActiveLPForSymbol("EURUSD"); lp != "lp-a" {
+		t.Errorf("GetActiveLPForSymbol() = %q, want lp-a", lp)
+	}
+
+	// The primary goes quiet; the backup's quotes take over
+	time.Sleep(80 * time.Millisecond)
+	m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.1001, Ask: 1.1004, LP: "lp-b"})
+	if published := drainQuotes(m); len(published) != 1 || published[0].LP != "lp-b" {
+		t.Fatalf("published = %+v, want the backup's quote after failover", published)
+	}
+	if lp := m.GetActiveLPForSymbol("EURUSD"); lp != "lp-b" {
+		t.Errorf("GetActiveLPForSymbol() = %q, want lp-b", lp)
+	}
+	select {
+	case e := <-events:
+		if e.Symbol != "EURUSD" || e.FromLP != "lp-a" || e.ToLP != "lp-b" || e.Reason != FailoverReasonStale {
+			t.Errorf("event = %+v, want EURUSD lp-a -> lp-b STALE", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no failover event")
+	}
+
+	// The primary resumes and is preferred again
+	m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.1002, Ask: 1.1004, LP: "lp-a"})
+	if published := drainQuotes(m); len(published) != 1 || published[0].LP != "lp-a" {
+		t.Errorf("published = %+v, want the recovered primary's quote", published)
+	}
+	select {
+	case e := <-events:
+		if e.ToLP != "lp-a" || e.Reason != FailoverReasonRecovered {
+			t.Errorf("event = %+v, want lp-b -> lp-a RECOVERED", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no recovery event")
+	}
+
+	// Symbols without a list are unaffected
+	m.publishQuote(Quote{Symbol: "GBPUSD", Bid: 1.2700, Ask: 1.2702, LP: "lp-b"})
+	if published := drainQuotes(m); len(published) != 1 || m.GetActiveLPForSymbol("GBPUSD") != "" {
+		t.Errorf("published = %+v, want GBPUSD quotes from any LP", published)
+	}
+}
+
+// TestFailoverOnDisconnect tests that a disconnected primary fails over
+// immediately, without waiting for it to go stale
+func TestFailoverOnDisconnect(t *testing.T) {
+	m := newCrossedTestManager(t)
+	primary := NewMockLPAdapter("lp-a", "LP A", "MOCK")
+	primary.Connect()
+	m.RegisterAdapter(primary)
+	m.SetSymbolPriorities(map[string][]string{"EURUSD": {"lp-a", "lp-b"}})
+	events := make(chan FailoverEvent, 2)
+	m.SetFailoverCallback(func(e FailoverEvent) { events <- e })
+
+	m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.1000, Ask: 1.1002, LP: "lp-a"})
+	primary.Disconnect()
+	m.publishQuote(Quote{Symbol: "EURUSD", Bid: 1.1000, Ask: 1.1003, LP: "lp-b"})
+
+	if published := drainQuotes(m); len(published) != 2 || published[1].LP != "lp-b" {
+		t.Fatalf("published = %+v, want the backup quote after the primary disconnected", published)
+	}
+	if e := <-events; e.Reason != FailoverReasonDisconnected {
+		t.Errorf("event = %+v, want DISCONNECTED", e)
+	}
+}
@@ -83,6 +83,31 @@ type LPConfig struct {
 	Priority int               `json:"priority"` // Lower = higher priority
 	Settings map[string]string `json:"settings"` // API keys, endpoints, etc.
 	Symbols  []string          `json:"symbols"`  // Empty = all available
+
+	// Polling feed definition, for LPs of type REST
+	REST *RESTFeedConfig `json:"rest,omitempty"`
+}
+
+// RESTFeedConfig describes a vendor HTTP quote endpoint polled by the REST
+// adapter. In the URL and field paths, {symbol} is replaced by the vendor's
+// name for the symbol.
+type RESTFeedConfig struct {
+	URL            string            `json:"url"`            // e.g. https://vendor/quotes?pair={symbol}
+	PollIntervalMs int               `json:"pollIntervalMs"` // Default 1000
+	AuthHeader     string            `json:"authHeader,omitempty"`
+	AuthValue      string            `json:"authValue,omitempty"`
+	BidPath        string            `json:"bidPath"` // Dot path into the response, e.g. data.{symbol}.bid or quotes.0.b
+	AskPath        string            `json:"askPath"`
+	VendorSymbols  map[string]string `json:"vendorSymbols,omitempty"` // Platform -> vendor symbol, default unchanged
+
+	// Per-symbol bid/ask paths overriding BidPath/AskPath
+	SymbolPaths map[string]RESTFieldPaths `json:"symbolPaths,omitempty"`
+}
+
+// RESTFieldPaths locates a symbol's bid and ask in a response
+type RESTFieldPaths struct {
+	BidPath string `json:"bidPath"`
+	AskPath string `json:"askPath"`
 }
 
 // LPManagerConfig represents the full LP manager configuration
@@ -90,6 +115,18 @@ type LPManagerConfig struct {
 	LPs          []LPConfig `json:"lps"`
 	PrimaryLP    string     `json:"primaryLp"` // ID of primary LP for execution
 	LastModified int64      `json:"lastModified"`
+
+	// SUPPRESS or FALLBACK handling of a crossed/locked aggregated book; empty suppresses
+	CrossedMarketPolicy string `json:"crossedMarketPolicy,omitempty"`
+
+	// Also publish the best bid/offer across LPs as quotes from the AGG source
+	BroadcastBBO bool `json:"broadcastBbo,omitempty"`
+
+	// Per-symbol LP IDs in order of preference; the feed fails over down the list
+	SymbolPriorities map[string][]string `json:"symbolPriorities,omitempty"`
+
+	// How long an LP may go without quoting before failover; default 5000
+	FailoverStaleAfterMs int `json:"failoverStaleAfterMs,omitempty"`
 }
 
 // NewDefaultConfig creates a default LP configuration
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -18,6 +19,14 @@ type Manager struct {
 	mu                sync.RWMutex
 	quotesChan        chan Quote
 	activeAggregators map[string]context.CancelFunc
+	crossGuard        *crossedMarketGuard
+	bbo               *bboAggregator
+	bboBroadcast      bool // Publish the best bid/offer as AGG quotes
+	failover          *failoverSelector
+
+	// LP priorities copied from the config on every change, so the quote path
+	// reads them without m.mu: LoadConfig holds m.mu while it sets up the guards
+	priorities atomic.Value // map[string]int
 }
 
 // NewManager creates a new LP manager
@@ -27,6 +36,9 @@ func NewManager(configPath string) *Manager {
 		configPath:        configPath,
 		quotesChan:        make(chan Quote, 1000),
 		activeAggregators: make(map[string]context.CancelFunc),
+		crossGuard:        newCrossedMarketGuard(),
+		bbo:               newBBOAggregator(),
+		failover:          newFailoverSelector(),
 	}
 }
 
@@ -51,6 +63,17 @@ func (m *Manager) LoadConfig() error {
 	}
 
 	m.config = &config
+	m.snapshotPrioritiesLocked()
+	if policy, err := NormalizeCrossedPolicy(config.CrossedMarketPolicy); err == nil {
+		m.crossGuard.setPolicy(policy)
+	} else {
+		log.Printf("[LPManager] %v, suppressing crossed quotes", err)
+	}
+	m.bboBroadcast = config.BroadcastBBO
+	m.failover.setPriorities(config.SymbolPriorities)
+	if config.FailoverStaleAfterMs > 0 {
+		m.failover.setStaleAfter(time.Duration(config.FailoverStaleAfterMs) * time.Millisecond)
+	}
 	log.Printf("[LPManager] Loaded config with %d LPs", len(m.config.LPs))
 	return nil
 }
@@ -66,6 +89,7 @@ func (m *Manager) saveConfigLocked() error {
 	if m.config == nil {
 		return nil
 	}
+	m.snapshotPrioritiesLocked()
 
 	m.config.LastModified = time.Now().Unix()
 
@@ -356,6 +380,8 @@ func (m *Manager) stopLPLocked(id string) {
 	if adapter, exists := m.registry.Get(id); exists {
 		adapter.Disconnect()
 	}
+	m.bbo.remove(id)
+	m.failover.remove(id)
 }
 
 func (m *Manager) aggregateQuotes(ctx context.Context, adapter LPAdapter) {
@@ -376,11 +402,7 @@ func (m *Manager) aggregateQuotes(ctx context.Context, adapter LPAdapter) {
 			if quoteCount%1000 == 1 {
 				log.Printf("[LPManager] Received quote #%d from %s: %s @ %.5f", quoteCount, adapter.ID(), quote.Symbol, quote.Bid)
 			}
-			select {
-			case m.quotesChan <- quote:
-			default:
-				// Channel full, drop quote
-			}
+			m.publishQuote(quote)
 		}
 	}
 }
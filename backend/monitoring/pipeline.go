@@ -0,0 +1,147 @@
+package monitoring
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/epic1st/rtx/backend/internal/middleware"
+	"github.com/epic1st/rtx/backend/ws"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// PipelineSources supplies the stats exported on /metrics. They are read on
+// every scrape; a nil source is left out.
+type PipelineSources struct {
+	Hub           func() ws.HubStats
+	OHLCBars      func() int64                    // Bars completed since startup
+	FIXSessions   func() map[string]string        // session ID -> status
+	QuoteAges     func() map[string]time.Duration // symbol -> time since the last live tick
+	OrderCounts   func() map[string]int           // order status -> count
+	OpenPositions func() int
+	HTTPRoutes    func() []middleware.RouteStats // Per-route API latency and status codes
+}
+
+var (
+	ticksReceivedDesc = prometheus.NewDesc("trading_pipeline_ticks_received_total",
+		"Ticks received from all feeds", nil, nil)
+	ticksProcessedDesc = prometheus.NewDesc("trading_pipeline_ticks_processed_total",
+		"Ticks broadcast to WebSocket clients", nil, nil)
+	ticksThrottledDesc = prometheus.NewDesc("trading_pipeline_ticks_throttled_total",
+		"Ticks stored but not broadcast because the quote moved less than the throttle", nil, nil)
+	ticksDroppedDesc = prometheus.NewDesc("trading_pipeline_ticks_dropped_total",
+		"Ticks dropped because the broadcast buffer was full", nil, nil)
+	tickLatencyDesc = prometheus.NewDesc("trading_pipeline_tick_latency_avg_milliseconds",
+		"Mean time from a tick arriving to it being queued for clients", nil, nil)
+	clientsConnectedDesc = prometheus.NewDesc("trading_pipeline_clients_connected",
+		"WebSocket clients connected", nil, nil)
+	clientsEvictedDesc = prometheus.NewDesc("trading_pipeline_clients_evicted_total",
+		"WebSocket clients evicted for not answering heartbeat pings", nil, nil)
+	ohlcBarsDesc = prometheus.NewDesc("trading_pipeline_ohlc_bars_generated_total",
+		"OHLC bars completed across symbols and timeframes", nil, nil)
+	fixSessionDesc = prometheus.NewDesc("trading_fix_session_up",
+		"1 when the FIX session is logged in, else 0", []string{"session", "status"}, nil)
+	quoteAgeDesc = prometheus.NewDesc("trading_quote_age_seconds",
+		"Time since the symbol's last live tick", []string{"symbol"}, nil)
+	ordersDesc = prometheus.NewDesc("trading_engine_orders",
+		"B-Book engine orders by status", []string{"status"}, nil)
+	openPositionsDesc = prometheus.NewDesc("trading_engine_open_positions",
+		"B-Book engine open positions", nil, nil)
+	httpDurationDesc = prometheus.NewDesc("trading_http_request_duration_seconds",
+		"HTTP request latency by route", []string{"method", "route"}, nil)
+	httpRequestsDesc = prometheus.NewDesc("trading_http_requests_total",
+		"HTTP requests by route and status code", []string{"method", "route", "code"}, nil)
+)
+
+// pipelineCollector exports PipelineSources as Prometheus metrics
+type pipelineCollector struct {
+	sources PipelineSources
+}
+
+// NewPipelineCollector creates a collector reading sources on every scrape
+func NewPipelineCollector(sources PipelineSources) prometheus.Collector {
+	return &pipelineCollector{sources: sources}
+}
+
+// Describe sends the descriptors of every metric the collector may export
+func (c *pipelineCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		ticksReceivedDesc, ticksProcessedDesc, ticksThrottledDesc, ticksDroppedDesc,
+		tickLatencyDesc, clientsConnectedDesc, clientsEvictedDesc, ohlcBarsDesc,
+		fixSessionDesc, quoteAgeDesc, ordersDesc, openPositionsDesc, httpDurationDesc, httpRequestsDesc,
+	} {
+		ch <- desc
+	}
+}
+
+// Collect reads the sources and sends their current values
+func (c *pipelineCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.sources
+
+	if s.Hub != nil {
+		stats := s.Hub()
+		ch <- prometheus.MustNewConstMetric(ticksReceivedDesc, prometheus.CounterValue, float64(stats.TicksReceived))
+		ch <- prometheus.MustNewConstMetric(ticksProcessedDesc, prometheus.CounterValue, float64(stats.TicksBroadcast))
+		ch <- prometheus.MustNewConstMetric(ticksThrottledDesc, prometheus.CounterValue, float64(stats.TicksThrottled))
+		ch <- prometheus.MustNewConstMetric(ticksDroppedDesc, prometheus.CounterValue, float64(stats.TicksDropped))
+		ch <- prometheus.MustNewConstMetric(tickLatencyDesc, prometheus.GaugeValue, stats.AvgLatencyMs)
+		ch <- prometheus.MustNewConstMetric(clientsConnectedDesc, prometheus.GaugeValue, float64(stats.ClientsConnected))
+		ch <- prometheus.MustNewConstMetric(clientsEvictedDesc, prometheus.CounterValue, float64(stats.ClientsEvicted))
+	}
+	if s.OHLCBars != nil {
+		ch <- prometheus.MustNewConstMetric(ohlcBarsDesc, prometheus.CounterValue, float64(s.OHLCBars()))
+	}
+	if s.FIXSessions != nil {
+		for session, status := range s.FIXSessions() {
+			up := 0.0
+			if status == "LOGGED_IN" {
+				up = 1
+			}
+			ch <- prometheus.MustNewConstMetric(fixSessionDesc, prometheus.GaugeValue, up, session, status)
+		}
+	}
+	if s.QuoteAges != nil {
+		for symbol, age := range s.QuoteAges() {
+			ch <- prometheus.MustNewConstMetric(quoteAgeDesc, prometheus.GaugeValue, age.Seconds(), symbol)
+		}
+	}
+	if s.OrderCounts != nil {
+		for status, count := range s.OrderCounts() {
+			ch <- prometheus.MustNewConstMetric(ordersDesc, prometheus.GaugeValue, float64(count), status)
+		}
+	}
+	if s.OpenPositions != nil {
+		ch <- prometheus.MustNewConstMetric(openPositionsDesc, prometheus.GaugeValue, float64(s.OpenPositions()))
+	}
+	if s.HTTPRoutes != nil {
+		for _, route := range s.HTTPRoutes() {
+			// Prometheus buckets are cumulative
+			buckets := make(map[float64]uint64, len(middleware.LatencyBuckets))
+			var cumulative uint64
+			for i, bound := range middleware.LatencyBuckets {
+				cumulative += route.Buckets[i]
+				buckets[bound] = cumulative
+			}
+			ch <- prometheus.MustNewConstHistogram(httpDurationDesc, uint64(route.Count), route.SumSeconds, buckets,
+				route.Method, route.Route)
+			for code, count := range route.StatusCodes {
+				ch <- prometheus.MustNewConstMetric(httpRequestsDesc, prometheus.CounterValue, float64(count),
+					route.Method, route.Route, strconv.Itoa(code))
+			}
+		}
+	}
+}
+
+// PipelineMetricsHandler returns the /metrics handler: the pipeline metrics
+// plus the Go runtime and process metrics, in the Prometheus exposition format
+func PipelineMetricsHandler(sources PipelineSources) http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		NewPipelineCollector(sources),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
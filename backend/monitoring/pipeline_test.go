@@ -0,0 +1,65 @@
+package monitoring
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/internal/middleware"
+	"github.com/epic1st/rtx/backend/ws"
+)
+
+// TestPipelineMetricsScrape tests that /metrics exports the pipeline, FIX,
+// quote age and order metrics in the exposition format
+func TestPipelineMetricsScrape(t *testing.T) {
+	handler := PipelineMetricsHandler(PipelineSources{
+		Hub: func() ws.HubStats {
+			return ws.HubStats{ClientsConnected: 3, TicksReceived: 120, TicksBroadcast: 100, TicksDropped: 2, AvgLatencyMs: 0.4}
+		},
+		OHLCBars:      func() int64 { return 7 },
+		FIXSessions:   func() map[string]string { return map[string]string{"YOFX1": "LOGGED_IN", "YOFX2": "DISCONNECTED"} },
+		QuoteAges:     func() map[string]time.Duration { return map[string]time.Duration{"EURUSD": 1500 * time.Millisecond} },
+		OrderCounts:   func() map[string]int { return map[string]int{"FILLED": 5} },
+		OpenPositions: func() int { return 4 },
+		HTTPRoutes: func() []middleware.RouteStats {
+			buckets := make([]uint64, len(middleware.LatencyBuckets))
+			buckets[0], buckets[3] = 2, 1 // Two under 5ms, one under 50ms
+			return []middleware.RouteStats{{
+				Method: "GET", Route: "/api/positions", Count: 3, SumSeconds: 0.05,
+				StatusCodes: map[int]int64{200: 2, 500: 1}, Buckets: buckets,
+			}}
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	if rec.Code != 200 {
+		t.Fatalf("GET /metrics status = %d", rec.Code)
+	}
+
+	for _, want := range []string{
+		"trading_pipeline_ticks_received_total 120",
+		"trading_pipeline_ticks_processed_total 100",
+		"trading_pipeline_ticks_dropped_total 2",
+		"trading_pipeline_tick_latency_avg_milliseconds 0.4",
+		"trading_pipeline_clients_connected 3",
+		"trading_pipeline_ohlc_bars_generated_total 7",
+		`trading_fix_session_up{session="YOFX1",status="LOGGED_IN"} 1`,
+		`trading_fix_session_up{session="YOFX2",status="DISCONNECTED"} 0`,
+		`trading_quote_age_seconds{symbol="EURUSD"} 1.5`,
+		`trading_engine_orders{status="FILLED"} 5`,
+		"trading_engine_open_positions 4",
+		`trading_http_request_duration_seconds_bucket{method="GET",route="/api/positions",le="0.005"} 2`,
+		`trading_http_request_duration_seconds_bucket{method="GET",route="/api/positions",le="0.05"} 3`,
+		`trading_http_request_duration_seconds_count{method="GET",route="/api/positions"} 3`,
+		`trading_http_requests_total{code="500",method="GET",route="/api/positions"} 1`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("/metrics is missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+// Delivery statuses of an account webhook event
+const (
+	WebhookDeliveryPending   = "PENDING"
+	WebhookDeliveryDelivered = "DELIVERED"
+	WebhookDeliveryFailed    = "FAILED"
+	WebhookDeliveryDropped   = "DROPPED" // Queue full, never attempted
+)
+
+// AccountWebhook is the outbound webhook an account has configured for its
+// trade execution events
+type AccountWebhook struct {
+	AccountID int64     `json:"accountId"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"-"`
+	Events    []string  `json:"events,omitempty"` // Empty = all trade events
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// wants reports whether the webhook subscribes to an event type
+func (w *AccountWebhook) wants(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// AccountWebhookPayload is the signed JSON body POSTed to an account webhook
+type AccountWebhookPayload struct {
+	ID        string          `json:"id"` // Delivery ID, stable across retries
+	Event     string          `json:"event"`
+	AccountID int64           `json:"accountId"`
+	Timestamp int64           `json:"timestamp"`
+	Data      core.TradeEvent `json:"data"`
+}
+
+// WebhookDelivery records the outcome of one event sent to an account webhook
+type WebhookDelivery struct {
+	ID          string     `json:"id"`
+	AccountID   int64      `json:"accountId"`
+	Event       string     `json:"event"`
+	URL         string     `json:"url"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	StatusCode  int        `json:"statusCode,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// AccountWebhookConfig controls delivery of account webhooks
+type AccountWebhookConfig struct {
+	MaxAttempts    int           // Attempts per event including the first
+	InitialBackoff time.Duration // Wait before the first retry, doubled on each further retry
+	MaxBackoff     time.Duration
+	Timeout        time.Duration // Per request
+	QueueSize      int
+	Workers        int
+	MaxLogEntries  int // Delivery log entries kept per account
+	// Allow loopback and private targets. Webhook URLs come from traders, so
+	// only tests should set this.
+	AllowPrivateTargets bool
+}
+
+// DefaultAccountWebhookConfig returns the default delivery settings
+func DefaultAccountWebhookConfig() AccountWebhookConfig {
+	return AccountWebhookConfig{
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Timeout:        10 * time.Second,
+		QueueSize:      1000,
+		Workers:        4,
+		MaxLogEntries:  200,
+	}
+}
+
+type webhookJob struct {
+	hook     AccountWebhook
+	payload  []byte
+	delivery *WebhookDelivery
+}
+
+// AccountWebhookDispatcher POSTs trade events to the webhook of the account
+// they belong to, signing each body and retrying failures with backoff
+type AccountWebhookDispatcher struct {
+	mu         sync.RWMutex
+	config     AccountWebhookConfig
+	hooks      map[int64]*AccountWebhook
+	deliveries map[int64][]*WebhookDelivery // accountID -> delivery log, oldest first
+	nextID     int64
+	queue      chan webhookJob
+	httpClient *http.Client
+	stopChan   chan struct{}
+	wg         sync.WaitGroup
+	running    bool
+}
+
+// NewAccountWebhookDispatcher creates a dispatcher. Zero config fields take the defaults.
+func NewAccountWebhookDispatcher(config AccountWebhookConfig) *AccountWebhookDispatcher {
+	defaults := DefaultAccountWebhookConfig()
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = defaults.InitialBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = defaults.MaxBackoff
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaults.QueueSize
+	}
+	if config.Workers <= 0 {
+		config.Workers = defaults.Workers
+	}
+	if config.MaxLogEntries <= 0 {
+		config.MaxLogEntries = defaults.MaxLogEntries
+	}
+
+	return &AccountWebhookDispatcher{
+		config:     config,
+		hooks:      make(map[int64]*AccountWebhook),
+		deliveries: make(map[int64][]*WebhookDelivery),
+		queue:      make(chan webhookJob, config.QueueSize),
+		httpClient: newWebhookClient(config.Timeout, config.AllowPrivateTargets),
+		stopChan:   make(chan struct{}),
+	}
+}
+
+// Start launches the delivery workers
+func (d *AccountWebhookDispatcher) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.running {
+		return
+	}
+	d.running = true
+
+	for i := 0; i < d.config.Workers; i++ {
+		d.wg.Add(1)
+		go d.worker()
+	}
+	log.Printf("[Webhooks] Account webhook dispatcher started (%d workers)", d.config.Workers)
+}
+
+// Stop halts the workers. Queued events are abandoned.
+func (d *AccountWebhookDispatcher) Stop() {
+	d.mu.Lock()
+	if !d.running {
+		d.mu.Unlock()
+		return
+	}
+	d.running = false
+	close(d.stopChan)
+	d.mu.Unlock()
+
+	d.wg.Wait()
+}
+
+// SetWebhook creates or replaces the webhook of an account
+func (d *AccountWebhookDispatcher) SetWebhook(hook AccountWebhook) (*AccountWebhook, error) {
+	if hook.AccountID <= 0 {
+		return nil, errors.New("accountId is required")
+	}
+	parsed, err := url.Parse(hook.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid webhook url %q: must be an absolute http(s) URL", hook.URL)
+	}
+	if hook.Secret == "" {
+		return nil, errors.New("secret is required to sign webhook payloads")
+	}
+	for _, event := range hook.Events {
+		switch event {
+		case core.TradeEventOrderAccepted, core.TradeEventOrderFilled, core.TradeEventPositionClosed:
+		default:
+			return nil, fmt.Errorf("unknown event %q", event)
+		}
+	}
+	if !d.config.AllowPrivateTargets {
+		if err := CheckWebhookTarget(hook.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	hook.CreatedAt = now
+	if existing, ok := d.hooks[hook.AccountID]; ok {
+		hook.CreatedAt = existing.CreatedAt
+	}
+	hook.UpdatedAt = now
+	d.hooks[hook.AccountID] = &hook
+
+	log.Printf("[Webhooks] Account #%d webhook set to %s (enabled=%v)", hook.AccountID, hook.URL, hook.Enabled)
+	result := hook
+	return &result, nil
+}
+
+// GetWebhook returns the webhook of an account
+func (d *AccountWebhookDispatcher) GetWebhook(accountID int64) (*AccountWebhook, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	hook, ok := d.hooks[accountID]
+	if !ok {
+		return nil, false
+	}
+	result := *hook
+	return &result, true
+}
+
+// RemoveWebhook deletes the webhook of an account
+func (d *AccountWebhookDispatcher) RemoveWebhook(accountID int64) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.hooks[accountID]; !ok {
+		return false
+	}
+	delete(d.hooks, accountID)
+	log.Printf("[Webhooks] Account #%d webhook removed", accountID)
+	return true
+}
+
+// GetDeliveries returns an account's delivery log, newest first. limit <= 0 returns all.
+func (d *AccountWebhookDispatcher) GetDeliveries(accountID int64, limit int) []WebhookDelivery {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	entries := d.deliveries[accountID]
+	if limit <= 0 || limit > len(entries) {
+		limit = len(entries)
+	}
+	result := make([]WebhookDelivery, 0, limit)
+	for i := len(entries) - 1; i >= 0 && len(result) < limit; i-- {
+		result = append(result, *entries[i])
+	}
+	return result
+}
+
+// HandleTradeEvent queues an engine trade event for the account's webhook. It
+// never blocks, so it can be subscribed directly to the engine's event stream.
+func (d *AccountWebhookDispatcher) HandleTradeEvent(event core.TradeEvent) {
+	d.mu.Lock()
+	hook, ok := d.hooks[event.AccountID]
+	if !ok || !hook.Enabled || !hook.wants(event.Type) {
+		d.mu.Unlock()
+		return
+	}
+
+	d.nextID++
+	delivery := &WebhookDelivery{
+		ID:        fmt.Sprintf("whd-%d-%d", event.AccountID, d.nextID),
+		AccountID: event.AccountID,
+		Event:     event.Type,
+		URL:       hook.URL,
+		Status:    WebhookDeliveryPending,
+		CreatedAt: time.Now(),
+	}
+	d.appendDeliveryUnlocked(delivery)
+	job := webhookJob{hook: *hook, delivery: delivery}
+	d.mu.Unlock()
+
+	payload, err := json.Marshal(AccountWebhookPayload{
+		ID:        delivery.ID,
+		Event:     event.Type,
+		AccountID: event.AccountID,
+		Timestamp: event.Timestamp.Unix(),
+		Data:      event,
+	})
+	if err != nil {
+		d.completeDelivery(delivery, WebhookDeliveryFailed, 0, fmt.Sprintf("failed to marshal payload: %v", err))
+		return
+	}
+	job.payload = payload
+
+	select {
+	case d.queue <- job:
+	default:
+		d.completeDelivery(delivery, WebhookDeliveryDropped, 0, "delivery queue full")
+		log.Printf("[Webhooks] Queue full, dropped %s for account #%d", event.Type, event.AccountID)
+	}
+}
+
+// appendDeliveryUnlocked adds a delivery to the account's bounded log (caller must hold lock)
+func (d *AccountWebhookDispatcher) appendDeliveryUnlocked(delivery *WebhookDelivery) {
+	entries := append(d.deliveries[delivery.AccountID], delivery)
+	if len(entries) > d.config.MaxLogEntries {
+		entries = entries[len(entries)-d.config.MaxLogEntries:]
+	}
+	d.deliveries[delivery.AccountID] = entries
+}
+
+// completeDelivery records the final outcome of a delivery
+func (d *AccountWebhookDispatcher) completeDelivery(delivery *WebhookDelivery, status string, statusCode int, errMsg string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	delivery.Status = status
+	delivery.StatusCode = statusCode
+	delivery.Error = errMsg
+	delivery.CompletedAt = &now
+}
+
+func (d *AccountWebhookDispatcher) worker() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.stopChan:
+			return
+		case job := <-d.queue:
+			d.deliver(job)
+		}
+	}
+}
+
+// deliver sends a job, retrying with exponential backoff until it succeeds or
+// the attempts run out
+func (d *AccountWebhookDispatcher) deliver(job webhookJob) {
+	backoff := d.config.InitialBackoff
+	var statusCode int
+	var lastErr error
+
+	for attempt := 1; attempt <= d.config.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-d.stopChan:
+				d.completeDelivery(job.delivery, WebhookDeliveryFailed, statusCode, "dispatcher stopped before retry")
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > d.config.MaxBackoff {
+				backoff = d.config.MaxBackoff
+			}
+		}
+
+		d.mu.Lock()
+		job.delivery.Attempts = attempt
+		d.mu.Unlock()
+
+		statusCode, lastErr = d.post(job)
+		if lastErr == nil {
+			d.completeDelivery(job.delivery, WebhookDeliveryDelivered, statusCode, "")
+			return
+		}
+		log.Printf("[Webhooks] %s for account #%d attempt %d/%d failed: %v",
+			job.delivery.Event, job.delivery.AccountID, attempt, d.config.MaxAttempts, lastErr)
+	}
+
+	d.completeDelivery(job.delivery, WebhookDeliveryFailed, statusCode, lastErr.Error())
+}
+
+// post makes a single signed delivery attempt
+func (d *AccountWebhookDispatcher) post(job webhookJob) (int, error) {
+	statusCode, _, err := postWebhook(context.Background(), d.httpClient, job.hook.URL, job.payload, "application/json", job.hook.Secret,
+		map[string]string{
+			"X-Webhook-Event":    job.delivery.Event,
+			"X-Webhook-Delivery": job.delivery.ID,
+		})
+	return statusCode, err
+}
@@ -0,0 +1,241 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+type webhookRequest struct {
+	body      []byte
+	signature string
+	event     string
+	delivery  string
+}
+
+// stubWebhookServer records every request and fails the first failFirst of them
+func stubWebhookServer(t *testing.T, failFirst int) (*httptest.Server, func() []webhookRequest) {
+	var mu sync.Mutex
+	var requests []webhookRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, webhookRequest{
+			body:      body,
+			signature: r.Header.Get("X-Webhook-Signature"),
+			event:     r.Header.Get("X-Webhook-Event"),
+			delivery:  r.Header.Get("X-Webhook-Delivery"),
+		})
+		fail := len(requests) <= failFirst
+		mu.Unlock()
+
+		if fail {
+			http.Error(w, "temporarily unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []webhookRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]webhookRequest(nil), requests...)
+	}
+}
+
+func newTestDispatcher(t *testing.T) *AccountWebhookDispatcher {
+	d := NewAccountWebhookDispatcher(AccountWebhookConfig{
+		MaxAttempts:    3,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     50 * time.Millisecond,
+		Timeout:        time.Second,
+		Workers:        1,
+		// The stub receiver listens on loopback
+		AllowPrivateTargets: true,
+	})
+	d.Start()
+	t.Cleanup(d.Stop)
+	return d
+}
+
+// waitForDeliveries waits until an account has n completed deliveries
+func waitForDeliveries(t *testing.T, d *AccountWebhookDispatcher, accountID int64, n int) []WebhookDelivery {
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		deliveries := d.GetDeliveries(accountID, 0)
+		done := 0
+		for _, delivery := range deliveries {
+			if delivery.Status != WebhookDeliveryPending {
+				done++
+			}
+		}
+		if done >= n {
+			return deliveries
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d deliveries, got %+v", n, deliveries)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestAccountWebhookSignedPayloadWithRetry tests that a fill from the engine's
+// event stream reaches the account webhook as signed JSON, and that a failed
+// first attempt is retried
+func TestAccountWebhookSignedPayloadWithRetry(t *testing.T) {
+	server, requests := stubWebhookServer(t, 1)
+
+	engine := core.NewEngine()
+	engine.SetPriceCallback(func(symbol string) (float64, float64, bool) {
+		return 1.1000, 1.1002, true
+	})
+	account := engine.CreateAccount("user1", "trader", "password", true)
+	account.Balance = 10000
+
+	d := newTestDispatcher(t)
+	engine.SubscribeTradeEvents(d.HandleTradeEvent)
+	if _, err := d.SetWebhook(AccountWebhook{
+		AccountID: account.ID,
+		URL:       server.URL,
+		Secret:    "s3cret",
+		Events:    []string{core.TradeEventOrderFilled},
+		Enabled:   true,
+	}); err != nil {
+		t.Fatalf("SetWebhook() error = %v", err)
+	}
+
+	pos, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+
+	deliveries := waitForDeliveries(t, d, account.ID, 1)
+	if len(deliveries) != 1 {
+		t.Fatalf("deliveries = %d, want 1 (ORDER_ACCEPTED is not subscribed)", len(deliveries))
+	}
+	delivery := deliveries[0]
+	if delivery.Status != WebhookDeliveryDelivered || delivery.Attempts != 2 || delivery.StatusCode != http.StatusOK {
+		t.Errorf("delivery = %+v, want DELIVERED after 2 attempts", delivery)
+	}
+
+	reqs := requests()
+	if len(reqs) != 2 {
+		t.Fatalf("webhook requests = %d, want 2 (failure then retry)", len(reqs))
+	}
+	if string(reqs[0].body) != string(reqs[1].body) || reqs[0].delivery != reqs[1].delivery {
+		t.Error("retry should resend the same payload and delivery ID")
+	}
+
+	req := reqs[1]
+	if !VerifySignature(req.body, req.signature, "s3cret") {
+		t.Errorf("signature %q does not match payload", req.signature)
+	}
+	if VerifySignature(req.body, req.signature, "wrong") {
+		t.Error("signature verified with the wrong secret")
+	}
+	if req.event != core.TradeEventOrderFilled || req.delivery != delivery.ID {
+		t.Errorf("headers event=%q delivery=%q, want %s / %s", req.event, req.delivery, core.TradeEventOrderFilled, delivery.ID)
+	}
+
+	var payload AccountWebhookPayload
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	if payload.ID != delivery.ID || payload.Event != core.TradeEventOrderFilled || payload.AccountID != account.ID || payload.Timestamp == 0 {
+		t.Errorf("payload envelope = %+v", payload)
+	}
+	data := payload.Data
+	if data.PositionID != pos.ID || data.Symbol != "EURUSD" || data.Side != "BUY" || data.Volume != 0.1 || data.Price != pos.OpenPrice {
+		t.Errorf("payload data = %+v, want fill of position #%d", data, pos.ID)
+	}
+
+	// Closing notifies once POSITION_CLOSED is subscribed
+	if _, err := d.SetWebhook(AccountWebhook{AccountID: account.ID, URL: server.URL, Secret: "s3cret", Enabled: true}); err != nil {
+		t.Fatalf("SetWebhook() error = %v", err)
+	}
+	if _, err := engine.ClosePosition(pos.ID, 0); err != nil {
+		t.Fatalf("ClosePosition() error = %v", err)
+	}
+	deliveries = waitForDeliveries(t, d, account.ID, 2)
+	if deliveries[0].Event != core.TradeEventPositionClosed || deliveries[0].Status != WebhookDeliveryDelivered {
+		t.Errorf("latest delivery = %+v, want delivered POSITION_CLOSED", deliveries[0])
+	}
+}
+
+// TestAccountWebhookGivesUpAfterMaxAttempts tests that a permanently failing
+// endpoint is logged as FAILED after the configured attempts
+func TestAccountWebhookGivesUpAfterMaxAttempts(t *testing.T) {
+	server, requests := stubWebhookServer(t, 100)
+
+	d := newTestDispatcher(t)
+	if _, err := d.SetWebhook(AccountWebhook{AccountID: 7, URL: server.URL, Secret: "k", Enabled: true}); err != nil {
+		t.Fatalf("SetWebhook() error = %v", err)
+	}
+	d.HandleTradeEvent(core.TradeEvent{Type: core.TradeEventOrderAccepted, AccountID: 7, Symbol: "EURUSD", Timestamp: time.Now()})
+
+	delivery := waitForDeliveries(t, d, 7, 1)[0]
+	if delivery.Status != WebhookDeliveryFailed || delivery.Attempts != 3 || delivery.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("delivery = %+v, want FAILED after 3 attempts", delivery)
+	}
+	if got := len(requests()); got != 3 {
+		t.Errorf("webhook requests = %d, want 3", got)
+	}
+
+	// Other accounts and disabled webhooks are not notified
+	d.HandleTradeEvent(core.TradeEvent{Type: core.TradeEventOrderAccepted, AccountID: 8, Timestamp: time.Now()})
+	if _, err := d.SetWebhook(AccountWebhook{AccountID: 7, URL: server.URL, Secret: "k", Enabled: false}); err != nil {
+		t.Fatalf("SetWebhook() error = %v", err)
+	}
+	d.HandleTradeEvent(core.TradeEvent{Type: core.TradeEventOrderAccepted, AccountID: 7, Timestamp: time.Now()})
+	if got := len(d.GetDeliveries(7, 0)) + len(d.GetDeliveries(8, 0)); got != 1 {
+		t.Errorf("deliveries after unmatched events = %d, want 1", got)
+	}
+}
+
+// TestAccountWebhookValidation tests that unusable webhook configurations,
+// and ones aimed at loopback or private addresses, are rejected
+func TestAccountWebhookValidation(t *testing.T) {
+	d := NewAccountWebhookDispatcher(AccountWebhookConfig{})
+	tests := []AccountWebhook{
+		{AccountID: 1, URL: "ftp://example.com/hook", Secret: "k"},
+		{AccountID: 1, URL: "/relative", Secret: "k"},
+		{AccountID: 1, URL: "https://example.com/hook"},
+		{AccountID: 1, URL: "https://example.com/hook", Secret: "k", Events: []string{"MARGIN_CALL"}},
+		{URL: "https://example.com/hook", Secret: "k"},
+		{AccountID: 1, URL: "http://127.0.0.1:8080/hook", Secret: "k"},
+		{AccountID: 1, URL: "http://localhost/hook", Secret: "k"},
+		{AccountID: 1, URL: "http://10.0.0.5/hook", Secret: "k"},
+		{AccountID: 1, URL: "http://169.254.169.254/latest/meta-data", Secret: "k"},
+		{AccountID: 1, URL: "http://[::1]/hook", Secret: "k"},
+	}
+	for _, hook := range tests {
+		if _, err := d.SetWebhook(hook); err == nil {
+			t.Errorf("SetWebhook(%+v) succeeded, want error", hook)
+		}
+	}
+}
+
+// TestWebhookClientRefusesPrivateDial tests that deliveries cannot reach a
+// loopback address even when the URL passed validation, e.g. after the host
+// re-resolved
+func TestWebhookClientRefusesPrivateDial(t *testing.T) {
+	server, requests := stubWebhookServer(t, 0)
+	client := newWebhookClient(time.Second, false)
+	_, _, err := postWebhook(context.Background(), client, server.URL, []byte(`{}`), "application/json", "k", nil)
+	if !errors.Is(err, ErrPrivateWebhookTarget) {
+		t.Fatalf("post to %s error = %v, want ErrPrivateWebhookTarget", server.URL, err)
+	}
+	if got := len(requests()); got != 0 {
+		t.Errorf("receiver got %d requests, want none", got)
+	}
+}
@@ -7,12 +7,122 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"syscall"
 	"time"
 )
 
+// ErrPrivateWebhookTarget is returned for webhooks aimed at a loopback,
+// private, link-local or otherwise non-public address
+var ErrPrivateWebhookTarget = errors.New("webhook target is not a public address")
+
+// isPublicIP reports whether ip is routable on the public internet
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	// Carrier-grade NAT, 100.64.0.0/10
+	if v4 := ip.To4(); v4 != nil && v4[0] == 100 && v4[1]&0xc0 == 64 {
+		return false
+	}
+	return true
+}
+
+// CheckWebhookTarget resolves the host of a webhook URL and fails with
+// ErrPrivateWebhookTarget if any of its addresses is not public
+func CheckWebhookTarget(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := parsed.Hostname()
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		ips, err = net.LookupIP(host)
+		if err != nil {
+			return fmt.Errorf("cannot resolve webhook host %q: %w", host, err)
+		}
+	}
+	for _, ip := range ips {
+		if !isPublicIP(ip) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrPrivateWebhookTarget, host, ip)
+		}
+	}
+	return nil
+}
+
+// newWebhookClient returns the HTTP client webhooks are sent with. Unless
+// allowPrivate, it refuses at dial time to connect to non-public addresses,
+// so a host re-resolving to an internal address after CheckWebhookTarget, or
+// a redirect to one, is still blocked.
+func newWebhookClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	if allowPrivate {
+		return &http.Client{Timeout: timeout}
+	}
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("%w: %s", ErrPrivateWebhookTarget, host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		// No proxy: it would be dialed instead of the target
+		Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: timeout},
+	}
+}
+
+// postWebhook makes one POST of payload, signed with secret when set, and
+// returns the response status code and X-Message-ID header
+func postWebhook(ctx context.Context, client *http.Client, targetURL string, payload []byte, contentType, secret string, headers map[string]string) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", targetURL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "TradingPlatform-Webhook/1.0")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	if secret != "" {
+		req.Header.Set("X-Webhook-Signature", SignWebhookPayload(payload, secret))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, "", fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return resp.StatusCode, resp.Header.Get("X-Message-ID"), nil
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of a payload, as sent in the
+// X-Webhook-Signature header. Receivers check it with VerifySignature.
+func SignWebhookPayload(payload []byte, secret string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 // WebhookConfig holds webhook configuration
 type WebhookConfig struct {
 	URL           string
@@ -42,10 +152,8 @@ func NewWebhookProvider(config WebhookConfig) *WebhookProvider {
 	}
 
 	return &WebhookProvider{
-		config: config,
-		httpClient: &http.Client{
-			Timeout: config.Timeout,
-		},
+		config:     config,
+		httpClient: newWebhookClient(config.Timeout, true), // Configured by the operator, not by users
 	}
 }
 
@@ -99,48 +207,16 @@ func (p *WebhookProvider) sendRequest(ctx context.Context, payload WebhookPayloa
 		return "", fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", p.config.URL, bytes.NewReader(payloadBytes))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", p.config.ContentType)
-	req.Header.Set("User-Agent", "TradingPlatform-Webhook/1.0")
-
-	// Add custom headers
+	headers := map[string]string{"X-Webhook-Timestamp": fmt.Sprintf("%d", payload.Timestamp)}
 	for key, value := range p.config.Headers {
-		req.Header.Set(key, value)
+		headers[key] = value
 	}
-
-	// Add HMAC signature if secret is configured
-	if p.config.Secret != "" {
-		signature := p.generateSignature(payloadBytes)
-		req.Header.Set("X-Webhook-Signature", signature)
-	}
-
-	// Add timestamp
-	req.Header.Set("X-Webhook-Timestamp", fmt.Sprintf("%d", payload.Timestamp))
-
-	resp, err := p.httpClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response
-	body, err := io.ReadAll(resp.Body)
+	_, messageID, err := postWebhook(ctx, p.httpClient, p.config.URL, payloadBytes, p.config.ContentType, p.config.Secret, headers)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
-	}
-
-	// Check status code
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return "", fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, string(body))
+		return "", err
 	}
 
 	// Generate message ID from response or timestamp
-	messageID := resp.Header.Get("X-Message-ID")
 	if messageID == "" {
 		messageID = fmt.Sprintf("%d-%s", payload.Timestamp, payload.Event)
 	}
@@ -148,13 +224,6 @@ func (p *WebhookProvider) sendRequest(ctx context.Context, payload WebhookPayloa
 	return messageID, nil
 }
 
-// generateSignature creates HMAC-SHA256 signature for webhook payload
-func (p *WebhookProvider) generateSignature(payload []byte) string {
-	h := hmac.New(sha256.New, []byte(p.config.Secret))
-	h.Write(payload)
-	return hex.EncodeToString(h.Sum(nil))
-}
-
 // VerifySignature verifies webhook signature from incoming webhooks
 func VerifySignature(payload []byte, signature, secret string) bool {
 	h := hmac.New(sha256.New, []byte(secret))
@@ -0,0 +1,162 @@
+package oanda
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Candle paging and rate limiting. OANDA returns at most 5000 candles per
+// request and answers 429 when requests come too fast.
+const (
+	maxCandlesPerRequest = 5000
+	candlePageInterval   = 100 * time.Millisecond
+	maxCandleRetries     = 5
+)
+
+// candleRetryBackoff is the first wait after a 429 without Retry-After; it doubles per retry
+var candleRetryBackoff = time.Second
+
+// candleGranularities are the v20 candle granularities and their durations
+var candleGranularities = map[string]time.Duration{
+	"S5": 5 * time.Second, "S10": 10 * time.Second, "S15": 15 * time.Second, "S30": 30 * time.Second,
+	"M1": time.Minute, "M2": 2 * time.Minute, "M4": 4 * time.Minute, "M5": 5 * time.Minute,
+	"M10": 10 * time.Minute, "M15": 15 * time.Minute, "M30": 30 * time.Minute,
+	"H1": time.Hour, "H2": 2 * time.Hour, "H3": 3 * time.Hour, "H4": 4 * time.Hour,
+	"H6": 6 * time.Hour, "H8": 8 * time.Hour, "H12": 12 * time.Hour, "D": 24 * time.Hour,
+}
+
+// GranularityDuration returns the length of a candle granularity
+func GranularityDuration(granularity string) (time.Duration, bool) {
+	d, ok := candleGranularities[granularity]
+	return d, ok
+}
+
+// CandlePrice is the open/high/low/close of one side of a candle
+type CandlePrice struct {
+	O string `json:"o"`
+	H string `json:"h"`
+	L string `json:"l"`
+	C string `json:"c"`
+}
+
+// Candle is one bid/ask candle from the candles endpoint
+type Candle struct {
+	Time     time.Time    `json:"time"`
+	Volume   int          `json:"volume"`
+	Complete bool         `json:"complete"`
+	Bid      *CandlePrice `json:"bid"`
+	Ask      *CandlePrice `json:"ask"`
+}
+
+// ToInstrument converts a symbol to OANDA's instrument name: EURUSD -> EUR_USD,
+// SPX500USD -> SPX500_USD. Names already containing "_" are kept.
+func ToInstrument(symbol string) string {
+	symbol = strings.ToUpper(symbol)
+	if strings.Contains(symbol, "_") || len(symbol) < 6 {
+		return symbol
+	}
+	return symbol[:len(symbol)-3] + "_" + symbol[len(symbol)-3:]
+}
+
+// FromInstrument converts an OANDA instrument name to a symbol: EUR_USD -> EURUSD
+func FromInstrument(instrument string) string {
+	return strings.ReplaceAll(instrument, "_", "")
+}
+
+// GetCandles fetches the complete bid/ask candles of instrument in [from, to),
+// paging through the endpoint's per-request limit
+func (c *Client) GetCandles(instrument, granularity string, from, to time.Time) ([]Candle, error) {
+	if _, ok := candleGranularities[granularity]; !ok {
+		return nil, fmt.Errorf("unsupported granularity %q", granularity)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+
+	var candles []Candle
+	cursor := from
+	includeFirst := true
+	for {
+		page, err := c.getCandlePage(instrument, granularity, cursor, includeFirst)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, candle := range page {
+			if !candle.Time.Before(to) {
+				return candles, nil
+			}
+			if candle.Complete {
+				candles = append(candles, candle)
+			}
+		}
+		if len(page) < maxCandlesPerRequest {
+			return candles, nil
+		}
+
+		cursor = page[len(page)-1].Time
+		includeFirst = false
+		time.Sleep(candlePageInterval)
+	}
+}
+
+// getCandlePage requests one page of candles from cursor, backing off while
+// rate limited
+func (c *Client) getCandlePage(instrument, granularity string, cursor time.Time, includeFirst bool) ([]Candle, error) {
+	query := url.Values{}
+	query.Set("price", "BA")
+	query.Set("granularity", granularity)
+	query.Set("from", cursor.UTC().Format(time.RFC3339))
+	query.Set("count", strconv.Itoa(maxCandlesPerRequest))
+	if !includeFirst {
+		query.Set("includeFirst", "false")
+	}
+	endpoint := fmt.Sprintf("%s/v3/instruments/%s/candles?%s", c.restURL, url.PathEscape(instrument), query.Encode())
+
+	backoff := candleRetryBackoff
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequest("GET", endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxCandleRetries {
+			resp.Body.Close()
+			wait := backoff
+			if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+				wait = time.Duration(secs) * time.Second
+			}
+			log.Printf("[OANDA] Candles rate limited for %s, retrying in %v", instrument, wait)
+			time.Sleep(wait)
+			backoff *= 2
+			continue
+		}
+
+		defer resp.Body.Close()
+		if resp.StatusCode != 200 {
+			body, _ := io.ReadAll(resp.Body)
+			return nil, fmt.Errorf("OANDA API error: %s - %s", resp.Status, string(body))
+		}
+
+		var result struct {
+			Candles []Candle `json:"candles"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return nil, err
+		}
+		return result.Candles, nil
+	}
+}
@@ -0,0 +1,94 @@
+package oanda
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestGetCandlesPagesAndRetries tests that candles are paged from the last
+// candle, incomplete candles and candles from `to` on are dropped, and a 429
+// is retried
+func TestGetCandlesPagesAndRetries(t *testing.T) {
+	candleRetryBackoff = time.Millisecond
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/v3/instruments/EUR_USD/candles" || r.URL.Query().Get("price") != "BA" {
+			t.Errorf("request = %s, want EUR_USD bid/ask candles", r.URL)
+		}
+		if requests == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		from, _ := time.Parse(time.RFC3339, r.URL.Query().Get("from"))
+		n := maxCandlesPerRequest
+		if requests > 2 {
+			n = 10 // Last page
+		}
+		first := from
+		if r.URL.Query().Get("includeFirst") == "false" {
+			first = from.Add(time.Minute)
+		}
+		fmt.Fprint(w, `{"candles":[`)
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			ts := first.Add(time.Duration(i) * time.Minute)
+			fmt.Fprintf(w, `{"time":%q,"volume":1,"complete":%v,"bid":{"c":"1.1"},"ask":{"c":"1.1002"}}`,
+				ts.Format(time.RFC3339), !ts.Equal(start.Add(time.Minute)))
+		}
+		fmt.Fprint(w, `]}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient("test-key")
+	c.restURL = srv.URL
+	to := start.Add(time.Duration(maxCandlesPerRequest+5) * time.Minute)
+	candles, err := c.GetCandles(ToInstrument("EURUSD"), "M1", start, to)
+	if err != nil {
+		t.Fatalf("GetCandles() error = %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("requests = %d, want a retry and two pages", requests)
+	}
+	// Minutes [0, 5005) less the incomplete minute 1
+	if len(candles) != maxCandlesPerRequest+4 {
+		t.Fatalf("got %d candles, want %d", len(candles), maxCandlesPerRequest+4)
+	}
+	for i := 1; i < len(candles); i++ {
+		if !candles[i].Time.After(candles[i-1].Time) {
+			t.Fatalf("candle %d at %v not after %v", i, candles[i].Time, candles[i-1].Time)
+		}
+	}
+	if last := candles[len(candles)-1].Time; !last.Before(to) {
+		t.Errorf("last candle at %v, want before %v", last, to)
+	}
+
+	if _, err := c.GetCandles("EUR_USD", "M3", start, to); err == nil {
+		t.Error("GetCandles() with granularity M3 succeeded, want an error")
+	}
+}
+
+// TestInstrumentNames tests the symbol to OANDA instrument mapping both ways
+func TestInstrumentNames(t *testing.T) {
+	tests := []struct{ symbol, instrument string }{
+		{"EURUSD", "EUR_USD"},
+		{"xauusd", "XAU_USD"},
+		{"SPX500USD", "SPX500_USD"},
+		{"EUR_USD", "EUR_USD"},
+	}
+	for _, tt := range tests {
+		if got := ToInstrument(tt.symbol); got != tt.instrument {
+			t.Errorf("ToInstrument(%q) = %q, want %q", tt.symbol, got, tt.instrument)
+		}
+	}
+	if got := FromInstrument("GBP_JPY"); got != "GBPJPY" {
+		t.Errorf("FromInstrument(GBP_JPY) = %q, want GBPJPY", got)
+	}
+}
@@ -49,6 +49,7 @@ type Heartbeat struct {
 type Client struct {
 	config     Config
 	httpClient *http.Client
+	restURL    string // REST base for candles, RestURL unless overridden
 	pricesChan chan Price
 	stopChan   chan struct{}
 	mu         sync.RWMutex
@@ -64,6 +65,7 @@ func NewClient(apiKey string) *Client {
 		httpClient: &http.Client{
 			Timeout: 0, // No timeout for streaming
 		},
+		restURL:    RestURL,
 		pricesChan: make(chan Price, 100),
 		stopChan:   make(chan struct{}),
 	}
@@ -0,0 +1,96 @@
+package orders
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RejectOrderTypeNotAllowed is returned when a group may not use the order type
+const RejectOrderTypeNotAllowed = "ORDER_TYPE_NOT_ALLOWED"
+
+// RejectError is a placement rejection carrying a machine-readable code
+type RejectError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RejectError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// GroupOrderRules restricts and defaults order placement for a user group
+type GroupOrderRules struct {
+	GroupID           int64       `json:"groupId"`
+	AllowedOrderTypes []OrderType `json:"allowedOrderTypes,omitempty"` // Empty allows every type
+	DefaultTIF        string      `json:"defaultTif,omitempty"`
+	DefaultSLPips     float64     `json:"defaultSlPips,omitempty"` // Distance from entry price
+	DefaultTPPips     float64     `json:"defaultTpPips,omitempty"`
+	// Minimum share of a market order that must fill, else it is rejected; 0 accepts any partial fill
+	DefaultMinFillRatio float64 `json:"defaultMinFillRatio,omitempty"`
+}
+
+// OrderRulesResolver returns the placement rules for an account, or nil if unrestricted
+type OrderRulesResolver func(accountID int64) *GroupOrderRules
+
+// Placement holds the order fields that group rules check and default
+type Placement struct {
+	Type         OrderType
+	Side         OrderSide
+	Price        float64 // Entry reference for default SL/TP; 0 skips them
+	SL           float64
+	TP           float64
+	TimeInForce  string
+	MinFillRatio float64
+}
+
+// Allows reports whether the group may place orders of type t
+func (g *GroupOrderRules) Allows(t OrderType) bool {
+	if g == nil || len(g.AllowedOrderTypes) == 0 {
+		return true
+	}
+	for _, allowed := range g.AllowedOrderTypes {
+		if strings.EqualFold(string(allowed), string(t)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply rejects disallowed order types and fills omitted TIF, SL, TP and
+// minimum fill ratio from the group defaults. pipSize converts the pip distances into prices.
+func (g *GroupOrderRules) Apply(p *Placement, pipSize float64) error {
+	if g == nil {
+		return nil
+	}
+
+	if !g.Allows(p.Type) {
+		return &RejectError{
+			Code:    RejectOrderTypeNotAllowed,
+			Message: fmt.Sprintf("%s orders are not allowed for this account group", p.Type),
+		}
+	}
+
+	if p.TimeInForce == "" {
+		p.TimeInForce = g.DefaultTIF
+	}
+	if p.MinFillRatio == 0 {
+		p.MinFillRatio = g.DefaultMinFillRatio
+	}
+
+	if p.Price <= 0 || pipSize <= 0 {
+		return nil
+	}
+
+	// SL sits below a buy entry and above a sell entry; TP the reverse
+	direction := 1.0
+	if p.Side == OrderSideSell {
+		direction = -1.0
+	}
+	if p.SL == 0 && g.DefaultSLPips > 0 {
+		p.SL = p.Price - direction*g.DefaultSLPips*pipSize
+	}
+	if p.TP == 0 && g.DefaultTPPips > 0 {
+		p.TP = p.Price + direction*g.DefaultTPPips*pipSize
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package orders
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestMarketOnlyGroupRejectsStopLimit tests that a restricted group cannot place other order types
+func TestMarketOnlyGroupRejectsStopLimit(t *testing.T) {
+	rules := &GroupOrderRules{AllowedOrderTypes: []OrderType{OrderTypeMarket}}
+
+	err := rules.Apply(&Placement{Type: OrderTypeStopLimit, Side: OrderSideBuy, Price: 1.1000}, 0.0001)
+	var reject *RejectError
+	if !errors.As(err, &reject) || reject.Code != RejectOrderTypeNotAllowed {
+		t.Fatalf("Apply(STOP_LIMIT) error = %v, want %s", err, RejectOrderTypeNotAllowed)
+	}
+
+	if err := rules.Apply(&Placement{Type: OrderTypeMarket, Side: OrderSideBuy}, 0.0001); err != nil {
+		t.Errorf("Apply(MARKET) error = %v, want nil", err)
+	}
+
+	var unrestricted *GroupOrderRules
+	if err := unrestricted.Apply(&Placement{Type: OrderTypeStopLimit}, 0.0001); err != nil {
+		t.Errorf("nil rules Apply() error = %v, want nil", err)
+	}
+}
+
+// TestGroupDefaultsAppliedWhenOmitted tests that omitted TIF/SL/TP take the group defaults
+func TestGroupDefaultsAppliedWhenOmitted(t *testing.T) {
+	rules := &GroupOrderRules{
+		DefaultTIF:    "GTC",
+		DefaultSLPips: 20,
+		DefaultTPPips: 40,
+	}
+
+	buy := &Placement{Type: OrderTypeLimit, Side: OrderSideBuy, Price: 1.1000}
+	if err := rules.Apply(buy, 0.0001); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if buy.TimeInForce != "GTC" {
+		t.Errorf("TimeInForce = %q, want GTC", buy.TimeInForce)
+	}
+	if math.Abs(buy.SL-1.0980) > 1e-9 || math.Abs(buy.TP-1.1040) > 1e-9 {
+		t.Errorf("buy SL/TP = %.5f/%.5f, want 1.09800/1.10400", buy.SL, buy.TP)
+	}
+
+	sell := &Placement{Type: OrderTypeLimit, Side: OrderSideSell, Price: 1.1000}
+	if err := rules.Apply(sell, 0.0001); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if math.Abs(sell.SL-1.1020) > 1e-9 || math.Abs(sell.TP-1.0960) > 1e-9 {
+		t.Errorf("sell SL/TP = %.5f/%.5f, want 1.10200/1.09600", sell.SL, sell.TP)
+	}
+
+	// Explicit values are kept
+	explicit := &Placement{Type: OrderTypeLimit, Side: OrderSideBuy, Price: 1.1000, SL: 1.0950, TP: 1.1100, TimeInForce: "DAY"}
+	if err := rules.Apply(explicit, 0.0001); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if explicit.SL != 1.0950 || explicit.TP != 1.1100 || explicit.TimeInForce != "DAY" {
+		t.Errorf("explicit fields overwritten: %+v", explicit)
+	}
+}
@@ -52,6 +52,7 @@ const (
 // PendingOrder represents a pending order in the system
 type PendingOrder struct {
 	ID           string      `json:"id"`
+	AccountID    int64       `json:"accountId,omitempty"` // Account the fill is booked to
 	Symbol       string      `json:"symbol"`
 	Side         OrderSide   `json:"side"`
 	Type         OrderType   `json:"type"`
@@ -64,6 +65,7 @@ type PendingOrder struct {
 	TP           float64     `json:"tp,omitempty"`
 	OCOPairID    string      `json:"ocoPairId,omitempty"`
 	Expiry       *time.Time  `json:"expiry,omitempty"`
+	TimeInForce  string      `json:"timeInForce,omitempty"`
 	Status       OrderStatus `json:"status"`
 	CreatedAt    time.Time   `json:"createdAt"`
 	TriggeredAt  *time.Time  `json:"triggeredAt,omitempty"`
@@ -85,6 +87,17 @@ type OrderService struct {
 	tpLadders     map[string][]TPLadder // tradeId -> TP levels
 	priceCallback func(symbol string) (bid, ask float64, ok bool)
 	execCallback  func(order *PendingOrder) error
+	markCallback  func(accountID int64, symbol string, bid, ask float64) (float64, float64)
+
+	precisionCallback func(symbol string) (digits int, ok bool)
+	strictPrecision   bool
+
+	// Stale quote guard: triggers wait for a quote no older than maxQuoteAge
+	quoteAgeCallback func(symbol string) (age time.Duration, ok bool)
+	maxQuoteAge      time.Duration
+	staleDeferred    map[string]bool // Orders already logged as deferred
+
+	dayEndCallback func(now time.Time) time.Time // End of the trading day for DAY orders
 }
 
 // NewOrderService creates a new order service
@@ -92,6 +105,7 @@ func NewOrderService() *OrderService {
 	svc := &OrderService{
 		pendingOrders: make(map[string]*PendingOrder),
 		tpLadders:     make(map[string][]TPLadder),
+		staleDeferred: make(map[string]bool),
 	}
 	
 	// Start background processor for pending orders
@@ -106,16 +120,41 @@ func (s *OrderService) SetPriceCallback(fn func(symbol string) (bid, ask float64
 	s.priceCallback = fn
 }
 
-// SetExecutionCallback sets the function to execute triggered orders
+// ErrBeyondLimit is wrapped by an execution callback refusing a limit fill
+// that slipped past the limit price. The order stays pending and triggers
+// again on a later quote.
+var ErrBeyondLimit = errors.New("fill beyond the limit price")
+
+// SetExecutionCallback sets the function to execute triggered orders. A
+// limit order (including a triggered stop-limit) must fill at its EntryPrice
+// or better, so the callback rejects a worse fill with ErrBeyondLimit rather
+// than take it.
 func (s *OrderService) SetExecutionCallback(fn func(order *PendingOrder) error) {
 	s.execCallback = fn
 }
 
+// SetQuoteMarkCallback sets the function marking a raw quote up to the one an
+// account fills at, so orders trigger on the prices their account trades
+func (s *OrderService) SetQuoteMarkCallback(fn func(accountID int64, symbol string, bid, ask float64) (float64, float64)) {
+	s.markCallback = fn
+}
+
+// PlaceOptions are the account and time-in-force a pending order is placed
+// with. An empty TimeInForce is GTC.
+type PlaceOptions struct {
+	AccountID   int64
+	TimeInForce string
+	Expiry      *time.Time // Required for GTD
+}
+
 // PlaceLimitOrder creates a limit order
-func (s *OrderService) PlaceLimitOrder(symbol string, side OrderSide, volume, price, sl, tp float64) (*PendingOrder, error) {
+func (s *OrderService) PlaceLimitOrder(symbol string, side OrderSide, volume, price, sl, tp float64, opts PlaceOptions) (*PendingOrder, error) {
 	if price <= 0 {
 		return nil, errors.New("invalid limit price")
 	}
+	if err := s.normalizePrices(symbol, &price, &sl, &tp); err != nil {
+		return nil, err
+	}
 
 	subtype := SubtypeBuyLimit
 	if side == OrderSideSell {
@@ -132,23 +171,27 @@ func (s *OrderService) PlaceLimitOrder(symbol string, side OrderSide, volume, pr
 		EntryPrice: price,
 		SL:         sl,
 		TP:         tp,
+		AccountID:  opts.AccountID,
 		Status:     StatusPending,
 		CreatedAt:  time.Now(),
 	}
 
-	s.mu.Lock()
-	s.pendingOrders[order.ID] = order
-	s.mu.Unlock()
+	if err := s.insert(order, opts); err != nil {
+		return nil, err
+	}
 
 	log.Printf("[OrderService] Limit order placed: %s %s %.2f lots @ %.5f", side, symbol, volume, price)
 	return order, nil
 }
 
 // PlaceStopOrder creates a stop order
-func (s *OrderService) PlaceStopOrder(symbol string, side OrderSide, volume, triggerPrice, sl, tp float64) (*PendingOrder, error) {
+func (s *OrderService) PlaceStopOrder(symbol string, side OrderSide, volume, triggerPrice, sl, tp float64, opts PlaceOptions) (*PendingOrder, error) {
 	if triggerPrice <= 0 {
 		return nil, errors.New("invalid trigger price")
 	}
+	if err := s.normalizePrices(symbol, &triggerPrice, &sl, &tp); err != nil {
+		return nil, err
+	}
 
 	subtype := SubtypeBuyStop
 	if side == OrderSideSell {
@@ -165,23 +208,27 @@ func (s *OrderService) PlaceStopOrder(symbol string, side OrderSide, volume, tri
 		TriggerPrice: triggerPrice,
 		SL:           sl,
 		TP:           tp,
+		AccountID:    opts.AccountID,
 		Status:       StatusPending,
 		CreatedAt:    time.Now(),
 	}
 
-	s.mu.Lock()
-	s.pendingOrders[order.ID] = order
-	s.mu.Unlock()
+	if err := s.insert(order, opts); err != nil {
+		return nil, err
+	}
 
 	log.Printf("[OrderService] Stop order placed: %s %s %.2f lots @ trigger %.5f", side, symbol, volume, triggerPrice)
 	return order, nil
 }
 
 // PlaceStopLimitOrder creates a stop-limit order
-func (s *OrderService) PlaceStopLimitOrder(symbol string, side OrderSide, volume, triggerPrice, limitPrice, sl, tp float64) (*PendingOrder, error) {
+func (s *OrderService) PlaceStopLimitOrder(symbol string, side OrderSide, volume, triggerPrice, limitPrice, sl, tp float64, opts PlaceOptions) (*PendingOrder, error) {
 	if triggerPrice <= 0 || limitPrice <= 0 {
 		return nil, errors.New("invalid prices")
 	}
+	if err := s.normalizePrices(symbol, &triggerPrice, &limitPrice, &sl, &tp); err != nil {
+		return nil, err
+	}
 
 	order := &PendingOrder{
 		ID:           uuid.New().String(),
@@ -194,6 +241,7 @@ func (s *OrderService) PlaceStopLimitOrder(symbol string, side OrderSide, volume
 		LimitPrice:   limitPrice,
 		SL:           sl,
 		TP:           tp,
+		AccountID:    opts.AccountID,
 		Status:       StatusPending,
 		CreatedAt:    time.Now(),
 	}
@@ -202,15 +250,31 @@ func (s *OrderService) PlaceStopLimitOrder(symbol string, side OrderSide, volume
 		order.Subtype = SubtypeSellStop
 	}
 
-	s.mu.Lock()
-	s.pendingOrders[order.ID] = order
-	s.mu.Unlock()
+	if err := s.insert(order, opts); err != nil {
+		return nil, err
+	}
 
 	log.Printf("[OrderService] Stop-Limit order placed: %s %s %.2f lots @ trigger %.5f limit %.5f",
 		side, symbol, volume, triggerPrice, limitPrice)
 	return order, nil
 }
 
+// insert sets a new order's time-in-force and adds it to the book, so it is
+// never evaluated before it is complete
+func (s *OrderService) insert(order *PendingOrder, opts PlaceOptions) error {
+	now := time.Now()
+	tif, err := ValidateTimeInForce(opts.TimeInForce, opts.Expiry, now)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setTimeInForceUnlocked(order, tif, opts.Expiry, now)
+	s.pendingOrders[order.ID] = order
+	return nil
+}
+
 // PlaceOCO creates a One-Cancels-Other order pair
 func (s *OrderService) PlaceOCO(order1, order2 *PendingOrder) error {
 	if order1 == nil || order2 == nil {
@@ -230,6 +294,42 @@ func (s *OrderService) PlaceOCO(order1, order2 *PendingOrder) error {
 	return nil
 }
 
+// SetTimeInForce sets the time-in-force of a pending order: a DAY order
+// expires at the end of the trading day, a GTD order at expiry
+func (s *OrderService) SetTimeInForce(orderID, tif string, expiry *time.Time) error {
+	now := time.Now()
+	tif, err := ValidateTimeInForce(tif, expiry, now)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, exists := s.pendingOrders[orderID]
+	if !exists {
+		return errors.New("order not found")
+	}
+	s.setTimeInForceUnlocked(order, tif, expiry, now)
+	return nil
+}
+
+// setTimeInForceUnlocked sets a validated time-in-force and the expiry it
+// implies (caller must hold lock)
+func (s *OrderService) setTimeInForceUnlocked(order *PendingOrder, tif string, expiry *time.Time, now time.Time) {
+	order.TimeInForce = tif
+	switch tif {
+	case TIFDay:
+		end := s.dayEndUnlocked(now)
+		order.Expiry = &end
+	case TIFGTD:
+		at := *expiry
+		order.Expiry = &at
+	default:
+		order.Expiry = nil
+	}
+}
+
 // CancelOrder cancels a pending order
 func (s *OrderService) CancelOrder(orderID string) error {
 	s.mu.Lock()
@@ -239,9 +339,29 @@ func (s *OrderService) CancelOrder(orderID string) error {
 	if !exists {
 		return errors.New("order not found")
 	}
+	s.cancelUnlocked(order)
+	return nil
+}
 
+// CancelAccountOrder cancels a pending order of accountID. Another account's
+// order is reported as not found.
+func (s *OrderService) CancelAccountOrder(orderID string, accountID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, exists := s.pendingOrders[orderID]
+	if !exists || order.AccountID != accountID {
+		return errors.New("order not found")
+	}
+	s.cancelUnlocked(order)
+	return nil
+}
+
+// cancelUnlocked cancels order and its OCO pair (caller must hold lock)
+func (s *OrderService) cancelUnlocked(order *PendingOrder) {
 	order.Status = StatusCancelled
-	delete(s.pendingOrders, orderID)
+	delete(s.pendingOrders, order.ID)
+	delete(s.staleDeferred, order.ID)
 
 	// Cancel OCO pair if exists
 	if order.OCOPairID != "" {
@@ -251,8 +371,7 @@ func (s *OrderService) CancelOrder(orderID string) error {
 		}
 	}
 
-	log.Printf("[OrderService] Order cancelled: %s", orderID)
-	return nil
+	log.Printf("[OrderService] Order cancelled: %s", order.ID)
 }
 
 // GetPendingOrders returns all pending orders
@@ -267,6 +386,20 @@ func (s *OrderService) GetPendingOrders() []*PendingOrder {
 	return orders
 }
 
+// GetAccountPendingOrders returns the pending orders of accountID
+func (s *OrderService) GetAccountPendingOrders(accountID int64) []*PendingOrder {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	orders := []*PendingOrder{}
+	for _, order := range s.pendingOrders {
+		if order.AccountID == accountID {
+			orders = append(orders, order)
+		}
+	}
+	return orders
+}
+
 // GetPendingOrdersBySymbol returns pending orders for a symbol
 func (s *OrderService) GetPendingOrdersBySymbol(symbol string) []*PendingOrder {
 	s.mu.RLock()
@@ -300,6 +433,7 @@ func (s *OrderService) GetTPLadder(tradeID string) []TPLadder {
 func (s *OrderService) processLoop() {
 	ticker := time.NewTicker(100 * time.Millisecond) // Check every 100ms
 	for range ticker.C {
+		s.expireOrders(time.Now())
 		s.checkPendingOrders()
 		s.checkTPLadders()
 	}
@@ -313,88 +447,132 @@ func (s *OrderService) checkPendingOrders() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for id, order := range s.pendingOrders {
+	for _, order := range s.pendingOrders {
 		if order.Status != StatusPending {
 			continue
 		}
 
-		// Check expiry
-		if order.Expiry != nil && time.Now().After(*order.Expiry) {
-			order.Status = StatusExpired
-			delete(s.pendingOrders, id)
-			log.Printf("[OrderService] Order expired: %s", id)
+		bid, ask, ok := s.priceCallback(order.Symbol)
+		if !ok {
 			continue
 		}
 
-		bid, ask, ok := s.priceCallback(order.Symbol)
-		if !ok {
+		s.evaluateUnlocked(order, bid, ask)
+	}
+}
+
+// OnTick checks a symbol's pending orders against a new quote: limit orders
+// fill once price reaches the limit, stop orders once it reaches the trigger,
+// and a stop-limit order becomes a limit order at its trigger
+func (s *OrderService) OnTick(symbol string, bid, ask float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, order := range s.pendingOrders {
+		if order.Symbol != symbol || order.Status != StatusPending {
 			continue
 		}
+		s.evaluateUnlocked(order, bid, ask)
+	}
+}
+
+// evaluateUnlocked triggers a pending order if the quote reaches its price
+// (caller must hold lock)
+func (s *OrderService) evaluateUnlocked(order *PendingOrder, bid, ask float64) {
+	if s.markCallback != nil && order.AccountID != 0 {
+		bid, ask = s.markCallback(order.AccountID, order.Symbol, bid, ask)
+	}
+
+	// Stop-limit: the trigger turns it into a limit order, which may fill on the same quote
+	if order.Type == OrderTypeStopLimit {
+		crossed := (order.Side == OrderSideBuy && ask >= order.TriggerPrice) ||
+			(order.Side == OrderSideSell && bid <= order.TriggerPrice)
+		if !crossed || s.deferStaleTriggerUnlocked(order) {
+			return
+		}
+		order.Type = OrderTypeLimit
+		order.EntryPrice = order.LimitPrice
+		order.Subtype = SubtypeBuyLimit
+		if order.Side == OrderSideSell {
+			order.Subtype = SubtypeSellLimit
+		}
+		log.Printf("[OrderService] Stop-Limit triggered, now Limit @ %.5f", order.LimitPrice)
+	}
+
+	triggered := false
+
+	switch order.Type {
+	case OrderTypeLimit:
+		// Buy limit triggers when ask <= entry price
+		// Sell limit triggers when bid >= entry price
+		if order.Side == OrderSideBuy && ask <= order.EntryPrice {
+			triggered = true
+		} else if order.Side == OrderSideSell && bid >= order.EntryPrice {
+			triggered = true
+		}
 
-		triggered := false
-
-		switch order.Type {
-		case OrderTypeLimit:
-			// Buy limit triggers when ask <= entry price
-			// Sell limit triggers when bid >= entry price
-			if order.Side == OrderSideBuy && ask <= order.EntryPrice {
-				triggered = true
-			} else if order.Side == OrderSideSell && bid >= order.EntryPrice {
-				triggered = true
-			}
-
-		case OrderTypeStop:
-			// Buy stop triggers when ask >= trigger price
-			// Sell stop triggers when bid <= trigger price
-			if order.Side == OrderSideBuy && ask >= order.TriggerPrice {
-				triggered = true
-			} else if order.Side == OrderSideSell && bid <= order.TriggerPrice {
-				triggered = true
-			}
-
-		case OrderTypeStopLimit:
-			// First check trigger, then it becomes a limit order
-			if order.Side == OrderSideBuy && ask >= order.TriggerPrice {
-				order.Type = OrderTypeLimit
-				order.EntryPrice = order.LimitPrice
-				log.Printf("[OrderService] Stop-Limit triggered, now Limit @ %.5f", order.LimitPrice)
-			} else if order.Side == OrderSideSell && bid <= order.TriggerPrice {
-				order.Type = OrderTypeLimit
-				order.EntryPrice = order.LimitPrice
-				log.Printf("[OrderService] Stop-Limit triggered, now Limit @ %.5f", order.LimitPrice)
-			}
+	case OrderTypeStop:
+		// Buy stop triggers when ask >= trigger price
+		// Sell stop triggers when bid <= trigger price
+		if order.Side == OrderSideBuy && ask >= order.TriggerPrice {
+			triggered = true
+		} else if order.Side == OrderSideSell && bid <= order.TriggerPrice {
+			triggered = true
 		}
+	}
+
+	// Never fill on a lagged feed: wait for a fresh quote to re-confirm the trigger
+	if !triggered || s.deferStaleTriggerUnlocked(order) {
+		return
+	}
+
+	now := time.Now()
+	order.TriggeredAt = &now
+	order.Status = StatusTriggered
 
-		if triggered {
-			now := time.Now()
-			order.TriggeredAt = &now
-			order.Status = StatusTriggered
-
-			// Execute the order
-			if s.execCallback != nil {
-				go func(o *PendingOrder) {
-					if err := s.execCallback(o); err != nil {
-						log.Printf("[OrderService] Execution failed: %v", err)
-						o.Status = StatusRejected
-					} else {
-						o.Status = StatusFilled
-					}
-				}(order)
-			}
-
-			// Cancel OCO pair
-			if order.OCOPairID != "" {
-				if pairOrder, exists := s.pendingOrders[order.OCOPairID]; exists {
-					pairOrder.Status = StatusCancelled
-					delete(s.pendingOrders, order.OCOPairID)
-					log.Printf("[OrderService] OCO pair cancelled: %s", order.OCOPairID)
-				}
-			}
-
-			delete(s.pendingOrders, id)
-			log.Printf("[OrderService] Order triggered: %s %s %s @ %.5f",
-				order.Side, order.Symbol, order.Type, bid)
+	// Cancel OCO pair
+	var pair *PendingOrder
+	if order.OCOPairID != "" {
+		if pairOrder, exists := s.pendingOrders[order.OCOPairID]; exists {
+			pairOrder.Status = StatusCancelled
+			delete(s.pendingOrders, order.OCOPairID)
+			pair = pairOrder
+			log.Printf("[OrderService] OCO pair cancelled: %s", order.OCOPairID)
+		}
+	}
+
+	delete(s.pendingOrders, order.ID)
+	delete(s.staleDeferred, order.ID)
+	log.Printf("[OrderService] Order triggered: %s %s %s @ %.5f",
+		order.Side, order.Symbol, order.Type, bid)
+
+	// Execute the order
+	if s.execCallback != nil {
+		go s.execute(order, pair)
+	}
+}
+
+// execute fills a triggered order. A fill refused with ErrBeyondLimit puts the
+// order, and the OCO pair its trigger cancelled, back as pending.
+func (s *OrderService) execute(order, pair *PendingOrder) {
+	err := s.execCallback(order)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case err == nil:
+		order.Status = StatusFilled
+	case errors.Is(err, ErrBeyondLimit):
+		log.Printf("[OrderService] Order %s kept pending: %v", order.ID, err)
+		order.Status, order.TriggeredAt = StatusPending, nil
+		s.pendingOrders[order.ID] = order
+		if pair != nil {
+			pair.Status = StatusPending
+			s.pendingOrders[pair.ID] = pair
 		}
+	default:
+		log.Printf("[OrderService] Execution failed: %v", err)
+		order.Status = StatusRejected
 	}
 }
 
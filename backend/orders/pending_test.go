@@ -0,0 +1,227 @@
+package orders
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newPendingTestService() (*OrderService, chan *PendingOrder) {
+	s := &OrderService{
+		pendingOrders: make(map[string]*PendingOrder),
+		tpLadders:     make(map[string][]TPLadder),
+		staleDeferred: make(map[string]bool),
+	}
+	executed := make(chan *PendingOrder, 4)
+	s.SetExecutionCallback(func(order *PendingOrder) error {
+		executed <- order
+		return nil
+	})
+	return s, executed
+}
+
+func expectExecuted(t *testing.T, executed chan *PendingOrder, order *PendingOrder) {
+	t.Helper()
+	select {
+	case got := <-executed:
+		if got.ID != order.ID {
+			t.Errorf("executed order %s, want %s", got.ID, order.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("order %s not executed", order.ID)
+	}
+}
+
+func expectNotExecuted(t *testing.T, executed chan *PendingOrder) {
+	t.Helper()
+	select {
+	case got := <-executed:
+		t.Fatalf("order %s executed, want it pending", got.ID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestLimitOrderTriggersAtLimit tests that a buy limit fills once the ask reaches the limit
+func TestLimitOrderTriggersAtLimit(t *testing.T) {
+	s, executed := newPendingTestService()
+	order, err := s.PlaceLimitOrder("EURUSD", OrderSideBuy, 1.0, 1.0995, 0, 0, PlaceOptions{})
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder() error = %v", err)
+	}
+
+	s.OnTick("EURUSD", 1.0996, 1.0998)
+	expectNotExecuted(t, executed)
+
+	s.OnTick("EURUSD", 1.0993, 1.0995)
+	expectExecuted(t, executed, order)
+	if len(s.GetPendingOrders()) != 0 {
+		t.Error("limit order still pending after triggering")
+	}
+}
+
+// TestStopOrderTriggersAtTrigger tests that a sell stop fills once the bid falls to the trigger
+func TestStopOrderTriggersAtTrigger(t *testing.T) {
+	s, executed := newPendingTestService()
+	order, err := s.PlaceStopOrder("EURUSD", OrderSideSell, 1.0, 1.0950, 0, 0, PlaceOptions{})
+	if err != nil {
+		t.Fatalf("PlaceStopOrder() error = %v", err)
+	}
+
+	s.OnTick("EURUSD", 1.0960, 1.0962)
+	expectNotExecuted(t, executed)
+
+	s.OnTick("EURUSD", 1.0948, 1.0950)
+	expectExecuted(t, executed, order)
+	if order.TriggeredAt == nil {
+		t.Error("TriggeredAt not set")
+	}
+}
+
+// TestStopLimitOrderTriggersThroughBothPhases tests that a buy stop-limit
+// becomes a limit order at its trigger and fills once the ask is back at the limit
+func TestStopLimitOrderTriggersThroughBothPhases(t *testing.T) {
+	s, executed := newPendingTestService()
+	order, err := s.PlaceStopLimitOrder("EURUSD", OrderSideBuy, 1.0, 1.1010, 1.1015, 0, 0, PlaceOptions{})
+	if err != nil {
+		t.Fatalf("PlaceStopLimitOrder() error = %v", err)
+	}
+
+	// Gaps through the trigger beyond the limit: now a limit order, not filled
+	s.OnTick("EURUSD", 1.1018, 1.1020)
+	expectNotExecuted(t, executed)
+	if order.Type != OrderTypeLimit || order.EntryPrice != 1.1015 {
+		t.Fatalf("after trigger type = %s entry = %.5f, want LIMIT @ 1.10150", order.Type, order.EntryPrice)
+	}
+	if len(s.GetPendingOrders()) != 1 {
+		t.Fatal("stop-limit order no longer pending after its trigger")
+	}
+
+	s.OnTick("EURUSD", 1.1012, 1.1014)
+	expectExecuted(t, executed, order)
+	if len(s.GetPendingOrders()) != 0 {
+		t.Error("stop-limit order still pending after filling")
+	}
+}
+
+// TestPendingOrderNeverTriggers tests that an order stays pending while price
+// stays away from it, including on other symbols' ticks
+func TestPendingOrderNeverTriggers(t *testing.T) {
+	s, executed := newPendingTestService()
+	order, err := s.PlaceLimitOrder("EURUSD", OrderSideSell, 1.0, 1.1100, 0, 0, PlaceOptions{})
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder() error = %v", err)
+	}
+
+	for _, bid := range []float64{1.1000, 1.1050, 1.1099} {
+		s.OnTick("EURUSD", bid, bid+0.0002)
+	}
+	s.OnTick("GBPUSD", 1.1200, 1.1202)
+	expectNotExecuted(t, executed)
+
+	if order.Status != StatusPending || len(s.GetPendingOrders()) != 1 {
+		t.Errorf("order status = %s, want PENDING", order.Status)
+	}
+}
+
+// TestLimitOrderTriggersOnMarkedQuote tests that a limit order triggers on its
+// account's marked-up quote, not the raw one
+func TestLimitOrderTriggersOnMarkedQuote(t *testing.T) {
+	s, executed := newPendingTestService()
+	s.SetQuoteMarkCallback(func(accountID int64, symbol string, bid, ask float64) (float64, float64) {
+		return bid - 0.0002, ask + 0.0002
+	})
+	order, err := s.PlaceLimitOrder("EURUSD", OrderSideBuy, 1.0, 1.0995, 0, 0, PlaceOptions{AccountID: 7})
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder() error = %v", err)
+	}
+
+	// Raw ask at the limit, marked ask above it
+	s.OnTick("EURUSD", 1.0993, 1.0995)
+	expectNotExecuted(t, executed)
+
+	s.OnTick("EURUSD", 1.0991, 1.0993)
+	expectExecuted(t, executed, order)
+}
+
+// TestLimitFillBeyondLimitStaysPending tests that a fill refused for slipping
+// past the limit puts the order and its OCO pair back as pending
+func TestLimitFillBeyondLimitStaysPending(t *testing.T) {
+	s, _ := newPendingTestService()
+	attempts := make(chan *PendingOrder, 4)
+	refused := false
+	s.SetExecutionCallback(func(order *PendingOrder) error {
+		defer func() { attempts <- order }()
+		if !refused {
+			refused = true
+			return fmt.Errorf("%w: requoted", ErrBeyondLimit)
+		}
+		return nil
+	})
+	order, err := s.PlaceLimitOrder("EURUSD", OrderSideBuy, 1.0, 1.0995, 0, 0, PlaceOptions{AccountID: 7})
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder() error = %v", err)
+	}
+	pair, err := s.PlaceStopOrder("EURUSD", OrderSideSell, 1.0, 1.0900, 0, 0, PlaceOptions{AccountID: 7})
+	if err != nil {
+		t.Fatalf("PlaceStopOrder() error = %v", err)
+	}
+	if err := s.PlaceOCO(order, pair); err != nil {
+		t.Fatalf("PlaceOCO() error = %v", err)
+	}
+
+	s.OnTick("EURUSD", 1.0993, 1.0995)
+	expectExecuted(t, attempts, order)
+	waitFor(t, func() bool { return len(s.GetPendingOrders()) == 2 })
+	if order.Status != StatusPending || pair.Status != StatusPending {
+		t.Fatalf("after refused fill status = %s/%s, want both PENDING", order.Status, pair.Status)
+	}
+
+	s.OnTick("EURUSD", 1.0993, 1.0995)
+	expectExecuted(t, attempts, order)
+	waitFor(t, func() bool {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return order.Status == StatusFilled
+	})
+	if len(s.GetPendingOrders()) != 0 {
+		t.Error("orders still pending after the fill")
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within a second")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestAccountOrdersAreScoped tests that an account only lists and cancels its
+// own pending orders
+func TestAccountOrdersAreScoped(t *testing.T) {
+	s, _ := newPendingTestService()
+	mine, err := s.PlaceLimitOrder("EURUSD", OrderSideBuy, 1.0, 1.0995, 0, 0, PlaceOptions{AccountID: 7})
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder() error = %v", err)
+	}
+	theirs, err := s.PlaceLimitOrder("EURUSD", OrderSideBuy, 1.0, 1.0995, 0, 0, PlaceOptions{AccountID: 8})
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder() error = %v", err)
+	}
+
+	if got := s.GetAccountPendingOrders(7); len(got) != 1 || got[0].ID != mine.ID {
+		t.Errorf("GetAccountPendingOrders(7) = %v, want only its own order", got)
+	}
+	if err := s.CancelAccountOrder(theirs.ID, 7); err == nil {
+		t.Error("CancelAccountOrder() cancelled another account's order")
+	}
+	if err := s.CancelAccountOrder(mine.ID, 7); err != nil {
+		t.Errorf("CancelAccountOrder() error = %v", err)
+	}
+	if got := s.GetPendingOrders(); len(got) != 1 || got[0].ID != theirs.ID {
+		t.Errorf("pending after cancel = %v, want the other account's order", got)
+	}
+}
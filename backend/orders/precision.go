@@ -0,0 +1,56 @@
+package orders
+
+import (
+	"fmt"
+	"math"
+)
+
+// RoundPrice rounds a price to the given number of decimal digits
+func RoundPrice(price float64, digits int) float64 {
+	if digits < 0 {
+		return price
+	}
+	scale := math.Pow(10, float64(digits))
+	return math.Round(price*scale) / scale
+}
+
+// SetPrecisionCallback sets the lookup of a symbol's quoted price digits
+func (s *OrderService) SetPrecisionCallback(fn func(symbol string) (digits int, ok bool)) {
+	s.precisionCallback = fn
+}
+
+// SetStrictPrecision rejects pending orders priced more precisely than the
+// symbol quotes instead of rounding them
+func (s *OrderService) SetStrictPrecision(strict bool) {
+	s.strictPrecision = strict
+}
+
+// normalizePrices rounds each non-zero price to the symbol's digits so pending
+// levels can be reached by quoted prices. In strict mode an over-precise price
+// is rejected instead.
+func (s *OrderService) normalizePrices(symbol string, prices ...*float64) error {
+	if s.precisionCallback == nil {
+		return nil
+	}
+	digits, ok := s.precisionCallback(symbol)
+	if !ok {
+		return nil
+	}
+
+	for _, p := range prices {
+		if *p == 0 {
+			continue
+		}
+		rounded := RoundPrice(*p, digits)
+		// Tolerate float noise from parsing, e.g. 1.1 stored as 1.1000000000000001
+		if math.Abs(rounded-*p) <= 1e-9*math.Max(1, math.Abs(*p)) {
+			*p = rounded
+			continue
+		}
+		if s.strictPrecision {
+			return fmt.Errorf("price %v exceeds %s precision of %d digits", *p, symbol, digits)
+		}
+		*p = rounded
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package orders
+
+import (
+	"testing"
+)
+
+func newPrecisionTestService(strict bool) *OrderService {
+	s := &OrderService{
+		pendingOrders: make(map[string]*PendingOrder),
+		tpLadders:     make(map[string][]TPLadder),
+	}
+	s.SetPrecisionCallback(func(symbol string) (int, bool) {
+		if symbol == "EURUSD" {
+			return 5, true
+		}
+		return 0, false
+	})
+	s.SetStrictPrecision(strict)
+	return s
+}
+
+// TestOverPreciseLimitPriceRounded tests that limit prices are rounded to the symbol's digits
+func TestOverPreciseLimitPriceRounded(t *testing.T) {
+	s := newPrecisionTestService(false)
+
+	order, err := s.PlaceLimitOrder("EURUSD", OrderSideBuy, 1.0, 1.0987654, 1.0950001, 0, PlaceOptions{})
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder() error = %v", err)
+	}
+	if order.EntryPrice != 1.09877 {
+		t.Errorf("entry price = %v, want 1.09877", order.EntryPrice)
+	}
+	if order.SL != 1.095 {
+		t.Errorf("SL = %v, want 1.095", order.SL)
+	}
+
+	stop, err := s.PlaceStopOrder("EURUSD", OrderSideSell, 1.0, 1.0912345, 0, 0, PlaceOptions{})
+	if err != nil {
+		t.Fatalf("PlaceStopOrder() error = %v", err)
+	}
+	if stop.TriggerPrice != 1.09123 {
+		t.Errorf("trigger price = %v, want 1.09123", stop.TriggerPrice)
+	}
+}
+
+// TestOverPreciseLimitPriceRejectedInStrictMode tests that strict mode refuses over-precise prices
+func TestOverPreciseLimitPriceRejectedInStrictMode(t *testing.T) {
+	s := newPrecisionTestService(true)
+
+	if _, err := s.PlaceLimitOrder("EURUSD", OrderSideBuy, 1.0, 1.0987654, 0, 0, PlaceOptions{}); err == nil {
+		t.Fatal("PlaceLimitOrder() accepted a 7-digit EURUSD price in strict mode")
+	}
+	if len(s.GetPendingOrders()) != 0 {
+		t.Errorf("rejected order was stored")
+	}
+}
+
+// TestCorrectlyPreciseLimitPriceUnchanged tests that prices within the symbol's digits are kept as given
+func TestCorrectlyPreciseLimitPriceUnchanged(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		s := newPrecisionTestService(strict)
+
+		order, err := s.PlaceLimitOrder("EURUSD", OrderSideBuy, 1.0, 1.09876, 1.0950, 1.1050, PlaceOptions{})
+		if err != nil {
+			t.Fatalf("strict=%v PlaceLimitOrder() error = %v", strict, err)
+		}
+		if order.EntryPrice != 1.09876 || order.SL != 1.0950 || order.TP != 1.1050 {
+			t.Errorf("strict=%v prices = %v/%v/%v, want 1.09876/1.095/1.105", strict, order.EntryPrice, order.SL, order.TP)
+		}
+
+		// Symbols without a known precision are not touched
+		other, err := s.PlaceLimitOrder("UNKNOWN", OrderSideBuy, 1.0, 1.0987654, 0, 0, PlaceOptions{})
+		if err != nil || other.EntryPrice != 1.0987654 {
+			t.Errorf("strict=%v unknown symbol = %v, %v; want price kept", strict, other, err)
+		}
+	}
+}
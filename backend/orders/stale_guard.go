@@ -0,0 +1,43 @@
+package orders
+
+import (
+	"log"
+	"time"
+)
+
+// SetQuoteAgeCallback sets the lookup of how old a symbol's latest live quote
+// is; ok false means no live quote has been received
+func (s *OrderService) SetQuoteAgeCallback(fn func(symbol string) (age time.Duration, ok bool)) {
+	s.quoteAgeCallback = fn
+}
+
+// SetMaxQuoteAge sets the oldest quote a pending order may trigger on (0 disables the guard)
+func (s *OrderService) SetMaxQuoteAge(maxAge time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxQuoteAge = maxAge
+}
+
+// deferStaleTriggerUnlocked reports whether an order's trigger must wait for a
+// fresher quote, logging the first deferral (caller must hold lock)
+func (s *OrderService) deferStaleTriggerUnlocked(order *PendingOrder) bool {
+	if s.maxQuoteAge <= 0 || s.quoteAgeCallback == nil {
+		return false
+	}
+
+	age, ok := s.quoteAgeCallback(order.Symbol)
+	if ok && age <= s.maxQuoteAge {
+		delete(s.staleDeferred, order.ID)
+		return false
+	}
+
+	if !s.staleDeferred[order.ID] {
+		s.staleDeferred[order.ID] = true
+		if ok {
+			log.Printf("[OrderService] Trigger deferred for %s: %s quote is %v old (max %v)", order.ID, order.Symbol, age.Round(time.Millisecond), s.maxQuoteAge)
+		} else {
+			log.Printf("[OrderService] Trigger deferred for %s: no live %s quote", order.ID, order.Symbol)
+		}
+	}
+	return true
+}
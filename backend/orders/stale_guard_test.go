@@ -0,0 +1,64 @@
+package orders
+
+import (
+	"testing"
+	"time"
+)
+
+// TestStaleQuoteDefersTrigger tests that a trigger on a stale quote waits and fires once a fresh quote arrives
+func TestStaleQuoteDefersTrigger(t *testing.T) {
+	s := &OrderService{
+		pendingOrders: make(map[string]*PendingOrder),
+		tpLadders:     make(map[string][]TPLadder),
+		staleDeferred: make(map[string]bool),
+	}
+	s.SetPriceCallback(func(symbol string) (float64, float64, bool) {
+		return 1.0990, 1.0992, true // Through the buy limit at 1.0995
+	})
+	quoteAge := 30 * time.Second
+	s.SetQuoteAgeCallback(func(symbol string) (time.Duration, bool) {
+		return quoteAge, true
+	})
+	s.SetMaxQuoteAge(2 * time.Second)
+
+	executed := make(chan *PendingOrder, 1)
+	s.SetExecutionCallback(func(order *PendingOrder) error {
+		executed <- order
+		return nil
+	})
+
+	order, err := s.PlaceLimitOrder("EURUSD", OrderSideBuy, 1.0, 1.0995, 0, 0, PlaceOptions{})
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder() error = %v", err)
+	}
+
+	// Stale quote: the order stays pending, marked as deferred across checks
+	s.checkPendingOrders()
+	s.checkPendingOrders()
+	if order.Status != StatusPending || len(s.GetPendingOrders()) != 1 {
+		t.Fatalf("order status = %s, want PENDING while the quote is stale", order.Status)
+	}
+	if !s.staleDeferred[order.ID] {
+		t.Errorf("order not marked as deferred")
+	}
+	select {
+	case <-executed:
+		t.Fatal("order executed on a stale quote")
+	default:
+	}
+
+	// A fresh quote re-confirms the trigger
+	quoteAge = 100 * time.Millisecond
+	s.checkPendingOrders()
+	select {
+	case got := <-executed:
+		if got.ID != order.ID {
+			t.Errorf("executed order %s, want %s", got.ID, order.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("order not executed after a fresh quote")
+	}
+	if len(s.GetPendingOrders()) != 0 {
+		t.Errorf("order still pending after triggering")
+	}
+}
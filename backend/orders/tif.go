@@ -0,0 +1,78 @@
+package orders
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Time-in-force of a pending order
+const (
+	TIFGTC = "GTC" // Good till cancelled
+	TIFDay = "DAY" // Cancelled at the end of the trading day
+	TIFGTD = "GTD" // Good till the order's expiry
+)
+
+// defaultDayEndHour is the end of the trading day (UTC) without a session
+// callback, the default daily rollover
+const defaultDayEndHour = 22
+
+// ValidateTimeInForce normalises a pending order's time-in-force, "" being
+// GTC. A GTD order needs an expiry after now.
+func ValidateTimeInForce(tif string, expiry *time.Time, now time.Time) (string, error) {
+	tif = strings.ToUpper(strings.TrimSpace(tif))
+	switch tif {
+	case "", TIFGTC:
+		return TIFGTC, nil
+	case TIFDay:
+		return TIFDay, nil
+	case TIFGTD:
+		if expiry == nil {
+			return "", fmt.Errorf("GTD orders require an expiry")
+		}
+		if !expiry.After(now) {
+			return "", fmt.Errorf("expiry %s is in the past", expiry.UTC().Format(time.RFC3339))
+		}
+		return TIFGTD, nil
+	}
+	return "", fmt.Errorf("time in force %q is not supported for pending orders, want GTC, DAY or GTD", tif)
+}
+
+// SetDayEndCallback sets the lookup of when the trading day after now ends,
+// at which DAY orders are cancelled
+func (s *OrderService) SetDayEndCallback(fn func(now time.Time) time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dayEndCallback = fn
+}
+
+// dayEndUnlocked returns the end of the trading day after now (caller must hold lock)
+func (s *OrderService) dayEndUnlocked(now time.Time) time.Time {
+	if s.dayEndCallback != nil {
+		return s.dayEndCallback(now)
+	}
+	now = now.UTC()
+	end := time.Date(now.Year(), now.Month(), now.Day(), defaultDayEndHour, 0, 0, 0, time.UTC)
+	if !end.After(now) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// expireOrders cancels DAY orders past the end of their trading day and GTD
+// orders past their expiry
+func (s *OrderService) expireOrders(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, order := range s.pendingOrders {
+		if order.Status != StatusPending || order.Expiry == nil || now.Before(*order.Expiry) {
+			continue
+		}
+		order.Status = StatusExpired
+		delete(s.pendingOrders, id)
+		delete(s.staleDeferred, id)
+		log.Printf("[OrderService] %s order expired: %s", order.TimeInForce, id)
+	}
+}
@@ -0,0 +1,62 @@
+package orders
+
+import (
+	"testing"
+	"time"
+)
+
+// TestDayOrderExpiresAtRollover tests that a DAY order is cancelled once the session rolls over
+func TestDayOrderExpiresAtRollover(t *testing.T) {
+	s, _ := newPendingTestService()
+	rollover := time.Now().Add(time.Hour)
+	s.SetDayEndCallback(func(now time.Time) time.Time { return rollover })
+
+	order, err := s.PlaceLimitOrder("EURUSD", OrderSideBuy, 1.0, 1.0950, 0, 0, PlaceOptions{AccountID: 7, TimeInForce: "day"})
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder() error = %v", err)
+	}
+	if order.AccountID != 7 {
+		t.Errorf("order account = %d, want 7", order.AccountID)
+	}
+	if order.TimeInForce != TIFDay || order.Expiry == nil || !order.Expiry.Equal(rollover) {
+		t.Fatalf("order TIF = %s expiry = %v, want DAY at %v", order.TimeInForce, order.Expiry, rollover)
+	}
+
+	s.expireOrders(rollover.Add(-time.Second))
+	if len(s.GetPendingOrders()) != 1 {
+		t.Fatal("DAY order cancelled before the rollover")
+	}
+
+	s.expireOrders(rollover.Add(time.Second))
+	if order.Status != StatusExpired || len(s.GetPendingOrders()) != 0 {
+		t.Errorf("order status = %s after the rollover, want EXPIRED", order.Status)
+	}
+}
+
+// TestGTDOrderExpiresAtTimestamp tests that a GTD order is cancelled at its
+// expiry while a GTC order is kept
+func TestGTDOrderExpiresAtTimestamp(t *testing.T) {
+	s, _ := newPendingTestService()
+	gtc, _ := s.PlaceStopOrder("EURUSD", OrderSideBuy, 1.0, 1.1050, 0, 0, PlaceOptions{})
+	if gtc.TimeInForce != TIFGTC {
+		t.Errorf("default TIF = %s, want GTC", gtc.TimeInForce)
+	}
+
+	if _, err := s.PlaceStopOrder("EURUSD", OrderSideSell, 1.0, 1.0950, 0, 0, PlaceOptions{TimeInForce: TIFGTD}); err == nil {
+		t.Error("GTD without an expiry accepted")
+	}
+	expiry := time.Now().Add(30 * time.Minute)
+	gtd, err := s.PlaceStopOrder("EURUSD", OrderSideSell, 1.0, 1.0950, 0, 0, PlaceOptions{TimeInForce: TIFGTD, Expiry: &expiry})
+	if err != nil {
+		t.Fatalf("PlaceStopOrder() error = %v", err)
+	}
+
+	s.expireOrders(expiry.Add(time.Millisecond))
+	pending := s.GetPendingOrders()
+	if len(pending) != 1 || pending[0].ID != gtc.ID {
+		t.Fatalf("pending orders = %d after the GTD expiry, want only the GTC order", len(pending))
+	}
+	if gtd.Status != StatusExpired {
+		t.Errorf("GTD order status = %s, want EXPIRED", gtd.Status)
+	}
+}
@@ -19,9 +19,10 @@ const (
 // TrailingStop represents an active trailing stop
 type TrailingStop struct {
 	TradeID      string           `json:"tradeId"`
+	AccountID    int64            `json:"accountId"` // Owner of the position
 	Symbol       string           `json:"symbol"`
-	Side         string           `json:"side"` // BUY or SELL
-	Distance     float64          `json:"distance"` // In pips or ATR multiplier
+	Side         string           `json:"side"`               // BUY or SELL
+	Distance     float64          `json:"distance"`           // In pips or ATR multiplier
 	StepSize     float64          `json:"stepSize,omitempty"` // For stepped trailing
 	Type         TrailingStopType `json:"type"`
 	CurrentSL    float64          `json:"currentSL"`
@@ -30,23 +31,37 @@ type TrailingStop struct {
 	Active       bool             `json:"active"`
 }
 
-// TrailingStopService manages trailing stops
+// ATR trailing: period of the average and how long a computed value is reused
+const (
+	trailingATRPeriod   = 14
+	trailingATRCacheTTL = time.Minute
+)
+
+// TrailingStopService manages trailing stops, moving and triggering them on
+// each tick of their symbol
 type TrailingStopService struct {
-	mu            sync.RWMutex
-	trailingStops map[string]*TrailingStop
-	priceCallback func(symbol string) (bid, ask float64, ok bool)
+	mu               sync.RWMutex
+	trailingStops    map[string]*TrailingStop
+	priceCallback    func(symbol string) (bid, ask float64, ok bool)
 	modifySLCallback func(tradeID string, newSL float64) error
-	atrCallback   func(symbol string, period int) float64
+	closeCallback    func(ts TrailingStop, price float64) error
+	atrCallback      func(symbol string, period int) float64
+	atrCache         map[string]cachedATR
+}
+
+type cachedATR struct {
+	value float64
+	at    time.Time
 }
 
-// NewTrailingStopService creates a new trailing stop service
+// NewTrailingStopService creates a new trailing stop service. Stops move only
+// on ticks delivered to OnTick.
 func NewTrailingStopService() *TrailingStopService {
 	svc := &TrailingStopService{
 		trailingStops: make(map[string]*TrailingStop),
+		atrCache:      make(map[string]cachedATR),
 	}
 
-	go svc.processLoop()
-	
 	log.Println("[TrailingStopService] Initialized")
 	return svc
 }
@@ -61,13 +76,19 @@ func (s *TrailingStopService) SetModifySLCallback(fn func(tradeID string, newSL
 	s.modifySLCallback = fn
 }
 
-// SetATRCallback sets the ATR calculator
+// SetCloseCallback sets the function that closes a position once price
+// crosses its trailing stop
+func (s *TrailingStopService) SetCloseCallback(fn func(ts TrailingStop, price float64) error) {
+	s.closeCallback = fn
+}
+
+// SetATRCallback sets the ATR calculator, returning the ATR in price units
 func (s *TrailingStopService) SetATRCallback(fn func(symbol string, period int) float64) {
 	s.atrCallback = fn
 }
 
-// SetTrailingStop adds or updates a trailing stop
-func (s *TrailingStopService) SetTrailingStop(tradeID, symbol, side string, tsType TrailingStopType, distance float64, stepSize float64) error {
+// SetTrailingStop adds or updates a trailing stop on accountID's trade
+func (s *TrailingStopService) SetTrailingStop(tradeID string, accountID int64, symbol, side string, tsType TrailingStopType, distance float64, stepSize float64) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -86,6 +107,7 @@ func (s *TrailingStopService) SetTrailingStop(tradeID, symbol, side string, tsTy
 
 	ts := &TrailingStop{
 		TradeID:      tradeID,
+		AccountID:    accountID,
 		Symbol:       symbol,
 		Side:         side,
 		Type:         tsType,
@@ -96,12 +118,11 @@ func (s *TrailingStopService) SetTrailingStop(tradeID, symbol, side string, tsTy
 		Active:       true,
 	}
 
-	// Calculate initial SL
-	pipValue := getPipValue(symbol)
-	if side == "BUY" {
-		ts.CurrentSL = initialPrice - (distance * pipValue)
-	} else {
-		ts.CurrentSL = initialPrice + (distance * pipValue)
+	// Calculate initial SL, or leave it to the first tick without a price
+	if initialPrice > 0 {
+		if offset, ok := s.stopOffsetLocked(ts); ok {
+			ts.CurrentSL = stepStop(ts, trailLevel(ts.Side, initialPrice, offset))
+		}
 	}
 
 	s.trailingStops[tradeID] = ts
@@ -136,85 +157,151 @@ func (s *TrailingStopService) GetAllTrailingStops() []*TrailingStop {
 	return stops
 }
 
-func (s *TrailingStopService) processLoop() {
-	ticker := time.NewTicker(200 * time.Millisecond)
-	for range ticker.C {
-		s.processTrailingStops()
-	}
-}
-
-func (s *TrailingStopService) processTrailingStops() {
-	if s.priceCallback == nil {
-		return
-	}
-
+// OnTick moves the symbol's trailing stops with a new quote and closes the
+// positions whose stop the price has crossed. A stop whose close fails is
+// re-armed, so the next tick beyond it retries.
+func (s *TrailingStopService) OnTick(symbol string, bid, ask float64) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, ts := range s.trailingStops {
-		if !ts.Active {
+	for tradeID, ts := range s.trailingStops {
+		if !ts.Active || ts.Symbol != symbol {
 			continue
 		}
 
-		bid, ask, ok := s.priceCallback(ts.Symbol)
-		if !ok {
+		// Longs close at the bid, shorts at the ask
+		price := bid
+		if ts.Side != "BUY" {
+			price = ask
+		}
+		if price <= 0 {
 			continue
 		}
 
-		pipValue := getPipValue(ts.Symbol)
-		distance := ts.Distance
-
-		// For ATR-based, recalculate distance
-		if ts.Type == TrailingATR && s.atrCallback != nil {
-			atr := s.atrCallback(ts.Symbol, 14)
-			distance = atr * ts.Distance // Distance is ATR multiplier
+		if ts.CurrentSL > 0 && crossed(ts.Side, price, ts.CurrentSL) {
+			ts.Active = false
+			delete(s.trailingStops, tradeID)
+			log.Printf("[TrailingStop] Triggered %s: %s @ %.5f crossed SL %.5f", tradeID, ts.Symbol, price, ts.CurrentSL)
+			if s.closeCallback != nil {
+				go func(ts TrailingStop) {
+					if err := s.closeCallback(ts, price); err != nil {
+						log.Printf("[TrailingStop] Failed to close %s, re-arming: %v", ts.TradeID, err)
+						s.rearm(ts)
+					}
+				}(*ts)
+			}
+			continue
 		}
 
-		var newSL float64
-		shouldUpdate := false
-
-		if ts.Side == "BUY" {
-			// For longs: trail below price
-			if bid > ts.HighestPrice {
-				ts.HighestPrice = bid
-				newSL = bid - (distance * pipValue)
-				
-				if ts.Type == TrailingStep {
-					// Step trailing: only update in steps
-					stepPips := ts.StepSize * pipValue
-					newSL = math.Floor(newSL/stepPips) * stepPips
-				}
-				
-				if newSL > ts.CurrentSL {
-					shouldUpdate = true
-				}
-			}
-		} else {
-			// For shorts: trail above price
-			if ask < ts.LowestPrice || ts.LowestPrice == 0 {
-				ts.LowestPrice = ask
-				newSL = ask + (distance * pipValue)
-				
-				if ts.Type == TrailingStep {
-					stepPips := ts.StepSize * pipValue
-					newSL = math.Ceil(newSL/stepPips) * stepPips
-				}
-				
-				if newSL < ts.CurrentSL || ts.CurrentSL == 0 {
-					shouldUpdate = true
-				}
-			}
+		s.trailLocked(ts, price)
+	}
+}
+
+// rearm restores a triggered stop whose close failed, unless a new stop was
+// set on the trade meanwhile
+func (s *TrailingStopService) rearm(ts TrailingStop) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.trailingStops[ts.TradeID]; exists {
+		return
+	}
+	ts.Active = true
+	s.trailingStops[ts.TradeID] = &ts
+}
+
+// trailLocked ratchets a stop toward a favourable price. It never loosens:
+// a long's stop only rises and a short's only falls (caller must hold lock).
+func (s *TrailingStopService) trailLocked(ts *TrailingStop, price float64) {
+	if ts.Side == "BUY" {
+		if price <= ts.HighestPrice && ts.CurrentSL > 0 {
+			return
 		}
+		if price > ts.HighestPrice {
+			ts.HighestPrice = price
+		}
+	} else {
+		if price >= ts.LowestPrice && ts.LowestPrice > 0 && ts.CurrentSL > 0 {
+			return
+		}
+		if price < ts.LowestPrice || ts.LowestPrice == 0 {
+			ts.LowestPrice = price
+		}
+	}
 
-		if shouldUpdate && newSL > 0 {
-			ts.CurrentSL = newSL
-			log.Printf("[TrailingStop] Updated %s: new SL = %.5f", ts.TradeID, newSL)
-			
-			if s.modifySLCallback != nil {
-				go s.modifySLCallback(ts.TradeID, newSL)
-			}
+	offset, ok := s.stopOffsetLocked(ts)
+	if !ok {
+		return
+	}
+	extreme := ts.HighestPrice
+	if ts.Side != "BUY" {
+		extreme = ts.LowestPrice
+	}
+	newSL := stepStop(ts, trailLevel(ts.Side, extreme, offset))
+
+	tighter := ts.CurrentSL == 0 ||
+		(ts.Side == "BUY" && newSL > ts.CurrentSL) ||
+		(ts.Side != "BUY" && newSL < ts.CurrentSL)
+	if !tighter || newSL <= 0 {
+		return
+	}
+
+	ts.CurrentSL = newSL
+	log.Printf("[TrailingStop] Updated %s: new SL = %.5f", ts.TradeID, newSL)
+
+	if s.modifySLCallback != nil {
+		go s.modifySLCallback(ts.TradeID, newSL)
+	}
+}
+
+// stopOffsetLocked returns the stop's distance from price in price units: the
+// pip distance, or for ATR stops the distance times the symbol's ATR. False
+// while no ATR is available (caller must hold lock).
+func (s *TrailingStopService) stopOffsetLocked(ts *TrailingStop) (float64, bool) {
+	if ts.Type != TrailingATR {
+		return ts.Distance * getPipValue(ts.Symbol), true
+	}
+	if s.atrCallback == nil {
+		return 0, false
+	}
+
+	cached, ok := s.atrCache[ts.Symbol]
+	if !ok || time.Since(cached.at) > trailingATRCacheTTL {
+		cached = cachedATR{value: s.atrCallback(ts.Symbol, trailingATRPeriod), at: time.Now()}
+		if cached.value <= 0 {
+			return 0, false // Not enough bars yet, ask again next tick
 		}
+		s.atrCache[ts.Symbol] = cached
+	}
+	return cached.value * ts.Distance, true // Distance is the ATR multiplier
+}
+
+// trailLevel returns the stop offset behind price on the losing side
+func trailLevel(side string, price, offset float64) float64 {
+	if side == "BUY" {
+		return price - offset
+	}
+	return price + offset
+}
+
+// stepStop moves a STEP stop back onto its step grid, away from price, so it
+// only advances in whole steps
+func stepStop(ts *TrailingStop, level float64) float64 {
+	if ts.Type != TrailingStep || ts.StepSize <= 0 {
+		return level
+	}
+	step := ts.StepSize * getPipValue(ts.Symbol)
+	if ts.Side == "BUY" {
+		return math.Floor(level/step+1e-9) * step
+	}
+	return math.Ceil(level/step-1e-9) * step
+}
+
+// crossed reports whether price has reached a stop
+func crossed(side string, price, stop float64) bool {
+	if side == "BUY" {
+		return price <= stop
 	}
+	return price >= stop
 }
 
 // getPipValue returns the pip value for a symbol
@@ -0,0 +1,147 @@
+package orders
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// newTrailingTestService returns a service quoting EURUSD at bid/ask 1.1000
+// and a channel receiving the price of each stop-triggered close
+func newTrailingTestService() (*TrailingStopService, chan float64) {
+	svc := NewTrailingStopService()
+	svc.SetPriceCallback(func(symbol string) (float64, float64, bool) {
+		return 1.1000, 1.1000, true
+	})
+	closed := make(chan float64, 1)
+	svc.SetCloseCallback(func(ts TrailingStop, price float64) error {
+		closed <- price
+		return nil
+	})
+	return svc, closed
+}
+
+// TestTrailingStopRatchets tests that long and short FIXED and STEP stops
+// follow a favourable price, never loosen when it retraces, and close the
+// position once price crosses them
+func TestTrailingStopRatchets(t *testing.T) {
+	tests := []struct {
+		name     string
+		side     string
+		tsType   TrailingStopType
+		stepSize float64
+		ticks    []float64 // Closing-side price of each tick
+		wantSL   []float64 // Stop after each tick
+		trigger  float64   // Price that crosses the final stop
+	}{
+		{"long fixed", "BUY", TrailingFixed, 0,
+			[]float64{1.1010, 1.1005, 1.1025}, []float64{1.0990, 1.0990, 1.1005}, 1.1004},
+		{"short fixed", "SELL", TrailingFixed, 0,
+			[]float64{1.0990, 1.0995, 1.0970}, []float64{1.1010, 1.1010, 1.0990}, 1.0991},
+		{"long step", "BUY", TrailingStep, 10,
+			[]float64{1.10015, 1.1010, 1.1008, 1.10195}, []float64{1.0980, 1.0990, 1.0990, 1.0990}, 1.0990},
+		{"short step", "SELL", TrailingStep, 10,
+			[]float64{1.09985, 1.0990, 1.0992, 1.09805}, []float64{1.1020, 1.1010, 1.1010, 1.1010}, 1.1010},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, closed := newTrailingTestService()
+			svc.SetTrailingStop("1", 1, "EURUSD", tt.side, tt.tsType, 20, tt.stepSize)
+			ts, _ := svc.GetTrailingStop("1")
+			want := 1.1000 - 0.0020
+			if tt.side == "SELL" {
+				want = 1.1000 + 0.0020
+			}
+			if math.Abs(ts.CurrentSL-want) > 1e-9 {
+				t.Fatalf("initial SL = %.5f, want %.5f", ts.CurrentSL, want)
+			}
+
+			for i, price := range tt.ticks {
+				svc.OnTick("EURUSD", price, price)
+				if math.Abs(ts.CurrentSL-tt.wantSL[i]) > 1e-9 {
+					t.Fatalf("after tick %.5f SL = %.5f, want %.5f", price, ts.CurrentSL, tt.wantSL[i])
+				}
+			}
+			select {
+			case <-closed:
+				t.Fatal("position closed before price crossed the stop")
+			default:
+			}
+
+			svc.OnTick("EURUSD", tt.trigger, tt.trigger)
+			select {
+			case price := <-closed:
+				if price != tt.trigger {
+					t.Errorf("closed at %.5f, want %.5f", price, tt.trigger)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("price crossed the stop without a close")
+			}
+			if _, ok := svc.GetTrailingStop("1"); ok {
+				t.Error("triggered stop still registered")
+			}
+		})
+	}
+}
+
+// TestTrailingStopATR tests that an ATR stop trails at the ATR multiple and
+// waits for an ATR before being placed
+func TestTrailingStopATR(t *testing.T) {
+	svc, _ := newTrailingTestService()
+	atr := 0.0
+	svc.SetATRCallback(func(symbol string, period int) float64 { return atr })
+
+	svc.SetTrailingStop("1", 1, "EURUSD", "BUY", TrailingATR, 2, 0)
+	ts, _ := svc.GetTrailingStop("1")
+	if ts.CurrentSL != 0 {
+		t.Fatalf("SL = %.5f without an ATR, want none", ts.CurrentSL)
+	}
+
+	atr = 0.0015
+	svc.OnTick("EURUSD", 1.1010, 1.1012)
+	if math.Abs(ts.CurrentSL-(1.1010-0.0030)) > 1e-9 {
+		t.Errorf("SL = %.5f, want 2 x ATR below 1.1010 = 1.0980", ts.CurrentSL)
+	}
+}
+
+// TestTrailingStopRearmsOnFailedClose tests that a stop whose close fails is
+// restored and triggers again on the next tick beyond it
+func TestTrailingStopRearmsOnFailedClose(t *testing.T) {
+	svc, _ := newTrailingTestService()
+	attempts := make(chan error, 2)
+	failures := 1
+	svc.SetCloseCallback(func(ts TrailingStop, price float64) error {
+		var err error
+		if failures > 0 {
+			failures--
+			err = errors.New("LP rejected the close")
+		}
+		attempts <- err
+		return err
+	})
+	svc.SetTrailingStop("1", 1, "EURUSD", "BUY", TrailingFixed, 20, 0)
+
+	for i, wantErr := range []bool{true, false} {
+		svc.OnTick("EURUSD", 1.0970, 1.0972)
+		select {
+		case err := <-attempts:
+			if (err != nil) != wantErr {
+				t.Fatalf("close %d error = %v, want error %v", i+1, err, wantErr)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("close %d was not attempted", i+1)
+		}
+		// The failed close re-arms from its goroutine
+		deadline := time.Now().Add(time.Second)
+		for wantErr {
+			if _, ok := svc.GetTrailingStop("1"); ok || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if _, ok := svc.GetTrailingStop("1"); ok != wantErr {
+			t.Fatalf("after close %d stop registered = %v, want %v", i+1, ok, wantErr)
+		}
+	}
+}
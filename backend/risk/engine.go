@@ -65,6 +65,7 @@ type Engine struct {
 	orderHistory          []OrderRecord      // Order history for analytics
 	creditUsage           map[string]float64 // clientID -> used credit
 	correlationMatrix     map[string]map[string]float64 // symbol1 -> symbol2 -> correlation
+	lpSessions            map[string]*LPSessionStatus   // symbol -> LP session state ("" = venue-wide)
 
 	mu                    sync.RWMutex
 }
@@ -95,6 +96,7 @@ func NewEngine() *Engine {
 		orderHistory:      make([]OrderRecord, 0),
 		creditUsage:       make(map[string]float64),
 		correlationMatrix: make(map[string]map[string]float64),
+		lpSessions:        make(map[string]*LPSessionStatus),
 	}
 	engine.circuitBreakerManager = NewCircuitBreakerManager(engine)
 	return engine
@@ -463,9 +465,15 @@ func (e *Engine) StoreLiquidationEvent(event LiquidationEvent) {
 	e.alerts = append(e.alerts, alert)
 }
 
-// IsMarketOpen checks if the market is currently open for a symbol
+// IsMarketOpen checks if the market is currently open for a symbol.
+// LP-reported session states take precedence over the static calendar.
 func (e *Engine) IsMarketOpen(symbol string) bool {
-	now := time.Now().UTC()
+	return e.sessionStateAt(symbol, time.Now()).Open
+}
+
+// calendarOpenAt checks the configured trading calendar for a symbol
+func calendarOpenAt(symbol string, now time.Time) bool {
+	now = now.UTC()
 	weekday := now.Weekday()
 	hour := now.Hour()
 
@@ -0,0 +1,142 @@
+package risk
+
+import (
+	"log"
+	"strings"
+	"time"
+)
+
+// Session states as reported by liquidity providers
+const (
+	SessionOpen     = "OPEN"
+	SessionPreClose = "PRE_CLOSE"
+	SessionPreOpen  = "PRE_OPEN"
+	SessionClosed   = "CLOSED"
+	SessionHalted   = "HALTED"
+)
+
+// Session state sources
+const (
+	SessionSourceCalendar = "CALENDAR"
+	SessionSourceLP       = "LP"
+)
+
+// MaxLPSessionAge bounds how long an LP-reported state overrides the calendar
+// without a fresh report
+const MaxLPSessionAge = 24 * time.Hour
+
+// LPSessionStatus is a trading session state reported by a liquidity provider
+type LPSessionStatus struct {
+	Symbol           string    `json:"symbol,omitempty"` // Empty for venue-wide status
+	TradingSessionID string    `json:"tradingSessionId,omitempty"`
+	State            string    `json:"state"`
+	CloseTime        time.Time `json:"closeTime,omitempty"` // Reported session close, if any
+	ReportedAt       time.Time `json:"reportedAt"`
+}
+
+// SessionState is the effective trading session state of a symbol
+type SessionState struct {
+	Symbol       string     `json:"symbol"`
+	Open         bool       `json:"open"`
+	State        string     `json:"state"`
+	Source       string     `json:"source"` // CALENDAR or LP
+	CalendarOpen bool       `json:"calendarOpen"`
+	ReportedAt   *time.Time `json:"reportedAt,omitempty"`
+}
+
+// UpdateSessionStatus records an LP-reported session state. Statuses without a
+// symbol apply to every symbol without its own report.
+func (e *Engine) UpdateSessionStatus(status LPSessionStatus) {
+	status.State = strings.ToUpper(status.State)
+	switch status.State {
+	case SessionOpen, SessionPreClose, SessionPreOpen, SessionClosed, SessionHalted:
+	default:
+		// Unknown or rejected requests carry no usable state
+		return
+	}
+	if status.ReportedAt.IsZero() {
+		status.ReportedAt = time.Now()
+	}
+
+	e.mu.Lock()
+	e.lpSessions[status.Symbol] = &status
+	e.mu.Unlock()
+
+	// Reconcile against the configured calendar so mismatches are visible
+	if status.Symbol == "" {
+		log.Printf("[Risk] LP venue-wide session state (%s): %s", status.TradingSessionID, status.State)
+		return
+	}
+	if calendarOpen := calendarOpenAt(status.Symbol, status.ReportedAt); calendarOpen != sessionTradeable(status.State) {
+		log.Printf("[Risk] LP session state for %s is %s but calendar says open=%v, using LP state",
+			status.Symbol, status.State, calendarOpen)
+		return
+	}
+	log.Printf("[Risk] LP session state for %s: %s", status.Symbol, status.State)
+}
+
+// ClearSessionStatus drops LP-reported states, reverting to the calendar
+func (e *Engine) ClearSessionStatus() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lpSessions = make(map[string]*LPSessionStatus)
+}
+
+// GetSessionState returns the effective session state of a symbol
+func (e *Engine) GetSessionState(symbol string) SessionState {
+	return e.sessionStateAt(symbol, time.Now())
+}
+
+// sessionStateAt resolves the session state at now: a fresh symbol-level LP
+// report wins, then a venue-wide report, then the static calendar
+func (e *Engine) sessionStateAt(symbol string, now time.Time) SessionState {
+	calendarOpen := calendarOpenAt(symbol, now)
+	state := SessionState{
+		Symbol:       symbol,
+		Open:         calendarOpen,
+		State:        SessionClosed,
+		Source:       SessionSourceCalendar,
+		CalendarOpen: calendarOpen,
+	}
+	if calendarOpen {
+		state.State = SessionOpen
+	}
+
+	e.mu.RLock()
+	status := e.lpSessions[symbol]
+	if !lpSessionCurrent(status, now) {
+		status = e.lpSessions[""]
+	}
+	e.mu.RUnlock()
+
+	if !lpSessionCurrent(status, now) {
+		return state
+	}
+
+	reportedAt := status.ReportedAt
+	state.Open = sessionTradeable(status.State)
+	state.State = status.State
+	state.Source = SessionSourceLP
+	state.ReportedAt = &reportedAt
+	return state
+}
+
+// lpSessionCurrent reports whether an LP status still applies at now
+func lpSessionCurrent(status *LPSessionStatus, now time.Time) bool {
+	if status == nil {
+		return false
+	}
+	if now.Sub(status.ReportedAt) > MaxLPSessionAge {
+		return false
+	}
+	// An open session past its reported close falls back to the calendar
+	if sessionTradeable(status.State) && !status.CloseTime.IsZero() && now.After(status.CloseTime) {
+		return false
+	}
+	return true
+}
+
+// sessionTradeable reports whether new orders are accepted in a session state
+func sessionTradeable(state string) bool {
+	return state == SessionOpen || state == SessionPreClose
+}
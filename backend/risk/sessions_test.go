@@ -0,0 +1,60 @@
+package risk
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLPSessionStatusOverridesCalendar tests that LP-reported session states drive market-open checks
+func TestLPSessionStatusOverridesCalendar(t *testing.T) {
+	engine := NewEngine()
+	wednesday := time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC) // Forex calendar open
+	saturday := time.Date(2024, 1, 6, 12, 0, 0, 0, time.UTC)  // Forex calendar closed
+
+	if state := engine.sessionStateAt("EURUSD", wednesday); !state.Open || state.Source != SessionSourceCalendar {
+		t.Fatalf("without LP status state = %+v, want open from calendar", state)
+	}
+
+	engine.UpdateSessionStatus(LPSessionStatus{Symbol: "EURUSD", State: "halted", ReportedAt: wednesday})
+	state := engine.sessionStateAt("EURUSD", wednesday.Add(time.Minute))
+	if state.Open || state.State != SessionHalted || state.Source != SessionSourceLP || !state.CalendarOpen {
+		t.Errorf("halted state = %+v, want closed HALTED from LP with calendar open", state)
+	}
+
+	// An open session past its reported close falls back to the calendar
+	engine.UpdateSessionStatus(LPSessionStatus{Symbol: "USDJPY", State: SessionOpen, CloseTime: wednesday.Add(time.Hour), ReportedAt: wednesday})
+	if state := engine.sessionStateAt("USDJPY", wednesday.Add(2*time.Hour)); state.Source != SessionSourceCalendar {
+		t.Errorf("after close state source = %s, want %s", state.Source, SessionSourceCalendar)
+	}
+
+	// Venue-wide status applies to symbols without their own report
+	engine.UpdateSessionStatus(LPSessionStatus{TradingSessionID: "FX", State: SessionOpen, ReportedAt: saturday})
+	if state := engine.sessionStateAt("GBPUSD", saturday); !state.Open || state.Source != SessionSourceLP {
+		t.Errorf("venue-wide state = %+v, want open from LP", state)
+	}
+
+	// Stale reports fall back to the calendar
+	if state := engine.sessionStateAt("GBPUSD", saturday.Add(MaxLPSessionAge+time.Hour)); state.Source != SessionSourceCalendar {
+		t.Errorf("stale state source = %s, want %s", state.Source, SessionSourceCalendar)
+	}
+
+	// Unusable states are ignored
+	engine.UpdateSessionStatus(LPSessionStatus{Symbol: "AUDUSD", State: "REQUEST_REJECTED", ReportedAt: wednesday})
+	if _, ok := engine.lpSessions["AUDUSD"]; ok {
+		t.Error("rejected status should not be recorded")
+	}
+}
+
+// TestIsMarketOpenFollowsLPSession tests that the market-open gate reflects the latest LP report
+func TestIsMarketOpenFollowsLPSession(t *testing.T) {
+	engine := NewEngine()
+	engine.UpdateSessionStatus(LPSessionStatus{Symbol: "EURUSD", State: SessionHalted})
+	if engine.IsMarketOpen("EURUSD") {
+		t.Error("IsMarketOpen() = true after LP halt, want false")
+	}
+
+	engine.UpdateSessionStatus(LPSessionStatus{Symbol: "EURUSD", State: SessionOpen})
+	if !engine.IsMarketOpen("EURUSD") {
+		t.Error("IsMarketOpen() = false after LP open, want true")
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strconv"
 	"testing"
 	"time"
 
@@ -33,11 +35,14 @@ type TestContext struct {
 }
 
 // SetupTest initializes test environment
-func SetupTest(t *testing.T) *TestContext {
+func SetupTest(t testing.TB) *TestContext {
 	t.Helper()
 
 	// Initialize B-Book engine
 	bbEngine := core.NewEngine()
+	for _, symbol := range []string{"EURUSD", "GBPUSD", "USDJPY", "AUDUSD", "USDCAD"} {
+		bbEngine.UpdateSymbol(core.GenerateSymbolSpec(symbol))
+	}
 	pnlEngine := core.NewPnLEngine(bbEngine)
 	authService := auth.NewService(bbEngine, "", "test-jwt-secret")
 
@@ -48,8 +53,10 @@ func SetupTest(t *testing.T) *TestContext {
 
 	// Initialize components
 	tickStore := tickstore.NewTickStore("test", 10000)
-	lpManager := lpmanager.NewManager("../data/lp_config.json")
+	lpManager := lpmanager.NewManager(filepath.Join(t.TempDir(), "lp_config.json"))
+	lpManager.LoadConfig()
 	apiHandler := handlers.NewAPIHandler(bbEngine, pnlEngine)
+	apiHandler.SetAuthService(authService)
 
 	// Create server
 	server := api.NewServer(authService, apiHandler, lpManager)
@@ -93,7 +100,7 @@ func SetupTest(t *testing.T) *TestContext {
 }
 
 // Login performs authentication and returns token
-func (tc *TestContext) Login(t *testing.T) string {
+func (tc *TestContext) Login(t testing.TB) string {
 	t.Helper()
 
 	reqBody := map[string]string{
@@ -121,8 +128,14 @@ func (tc *TestContext) Login(t *testing.T) string {
 	return resp.Token
 }
 
-// InjectPrice injects test market data
+// InjectPrice injects test market data and waits for it to propagate
 func (tc *TestContext) InjectPrice(symbol string, bid, ask float64) {
+	tc.BroadcastPrice(symbol, bid, ask)
+	time.Sleep(50 * time.Millisecond)
+}
+
+// BroadcastPrice hands a tick to the hub without waiting for it to propagate
+func (tc *TestContext) BroadcastPrice(symbol string, bid, ask float64) {
 	tick := &ws.MarketTick{
 		Type:      "tick",
 		Symbol:    symbol,
@@ -133,7 +146,38 @@ func (tc *TestContext) InjectPrice(symbol string, bid, ask float64) {
 		LP:        "TEST",
 	}
 	tc.Hub.BroadcastTick(tick)
-	time.Sleep(50 * time.Millisecond)
+}
+
+// ServeAsTrader runs next behind the trader role check with the test token,
+// as main.go routes it
+func (tc *TestContext) ServeAsTrader(next http.HandlerFunc, w http.ResponseWriter, req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+tc.Token)
+	tc.AuthService.RequireRole(auth.RoleTrader, next)(w, req)
+}
+
+// PlaceMarketOrder places a B-Book market order and returns the position it opened
+func (tc *TestContext) PlaceMarketOrder(t *testing.T, symbol, side string, volume float64) *core.Position {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"symbol": symbol,
+		"side":   side,
+		"volume": volume,
+	})
+	req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	tc.ServeAsTrader(tc.APIHandler.HandlePlaceMarketOrder, w, req)
+
+	var resp struct {
+		Position *core.Position `json:"position"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if w.Code != http.StatusOK || resp.Position == nil {
+		t.Fatalf("Market order failed: %d", w.Code)
+	}
+	return resp.Position
 }
 
 // MakeRequest makes authenticated HTTP request
@@ -254,7 +298,6 @@ func TestConfig_GetConfig(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Content-Type", "application/json")
 		config := map[string]interface{}{
 			"brokerName":        "RTX Trading",
@@ -290,15 +333,14 @@ func TestOrder_PlaceMarketOrder_Buy(t *testing.T) {
 		"symbol": "EURUSD",
 		"side":   "BUY",
 		"volume": 0.1,
-		"type":   "MARKET",
 	}
 	body, _ := json.Marshal(reqBody)
 
-	req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	tc.Server.HandlePlaceOrder(w, req)
+	tc.ServeAsTrader(tc.APIHandler.HandlePlaceMarketOrder, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected 200, got %d - %s", w.Code, w.Body.String())
@@ -320,15 +362,14 @@ func TestOrder_PlaceMarketOrder_Sell(t *testing.T) {
 		"symbol": "EURUSD",
 		"side":   "SELL",
 		"volume": 0.1,
-		"type":   "MARKET",
 	}
 	body, _ := json.Marshal(reqBody)
 
-	req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	tc.Server.HandlePlaceOrder(w, req)
+	tc.ServeAsTrader(tc.APIHandler.HandlePlaceMarketOrder, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected 200, got %d - %s", w.Code, w.Body.String())
@@ -351,7 +392,7 @@ func TestOrder_PlaceLimitOrder(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	tc.Server.HandlePlaceLimitOrder(w, req)
+	tc.ServeAsTrader(tc.Server.HandlePlaceLimitOrder, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected 200, got %d - %s", w.Code, w.Body.String())
@@ -374,7 +415,7 @@ func TestOrder_PlaceStopOrder(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	tc.Server.HandlePlaceStopOrder(w, req)
+	tc.ServeAsTrader(tc.Server.HandlePlaceStopOrder, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected 200, got %d - %s", w.Code, w.Body.String())
@@ -398,7 +439,7 @@ func TestOrder_PlaceStopLimitOrder(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	tc.Server.HandlePlaceStopLimitOrder(w, req)
+	tc.ServeAsTrader(tc.Server.HandlePlaceStopLimitOrder, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected 200, got %d - %s", w.Code, w.Body.String())
@@ -421,12 +462,12 @@ func TestOrder_GetPendingOrders(t *testing.T) {
 	req := httptest.NewRequest("POST", "/order/limit", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	tc.Server.HandlePlaceLimitOrder(w, req)
+	tc.ServeAsTrader(tc.Server.HandlePlaceLimitOrder, w, req)
 
 	// Get pending orders
 	req = httptest.NewRequest("GET", "/orders/pending", nil)
 	w = httptest.NewRecorder()
-	tc.Server.HandleGetPendingOrders(w, req)
+	tc.ServeAsTrader(tc.Server.HandleGetPendingOrders, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected 200, got %d", w.Code)
@@ -456,7 +497,7 @@ func TestOrder_CancelOrder(t *testing.T) {
 	req := httptest.NewRequest("POST", "/order/limit", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	tc.Server.HandlePlaceLimitOrder(w, req)
+	tc.ServeAsTrader(tc.Server.HandlePlaceLimitOrder, w, req)
 
 	var orderResp map[string]interface{}
 	json.NewDecoder(w.Body).Decode(&orderResp)
@@ -471,7 +512,7 @@ func TestOrder_CancelOrder(t *testing.T) {
 	req = httptest.NewRequest("POST", "/order/cancel", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w = httptest.NewRecorder()
-	tc.Server.HandleCancelOrder(w, req)
+	tc.ServeAsTrader(tc.Server.HandleCancelOrder, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected 200, got %d - %s", w.Code, w.Body.String())
@@ -496,15 +537,14 @@ func TestOrder_InvalidVolume(t *testing.T) {
 				"symbol": "EURUSD",
 				"side":   "BUY",
 				"volume": tt.volume,
-				"type":   "MARKET",
 			}
 			body, _ := json.Marshal(reqBody)
 
-			req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+			req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
-			tc.Server.HandlePlaceOrder(w, req)
+			tc.ServeAsTrader(tc.APIHandler.HandlePlaceMarketOrder, w, req)
 
 			// Should return bad request for invalid volume
 			t.Logf("%s: status=%d", tt.name, w.Code)
@@ -537,6 +577,8 @@ func TestPosition_ModifySLTP(t *testing.T) {
 
 func TestPosition_SetTrailingStop(t *testing.T) {
 	tc := SetupTest(t)
+	tc.InjectPrice("EURUSD", 1.10000, 1.10020)
+	position := tc.PlaceMarketOrder(t, "EURUSD", "BUY", 0.1)
 
 	tests := []struct {
 		name     string
@@ -551,9 +593,7 @@ func TestPosition_SetTrailingStop(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			reqBody := map[string]interface{}{
-				"tradeId":  "test-trade-123",
-				"symbol":   "EURUSD",
-				"side":     "BUY",
+				"tradeId":  strconv.FormatInt(position.ID, 10),
 				"type":     tt.tsType,
 				"distance": tt.distance,
 			}
@@ -563,7 +603,7 @@ func TestPosition_SetTrailingStop(t *testing.T) {
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
-			tc.Server.HandleSetTrailingStop(w, req)
+			tc.ServeAsTrader(tc.Server.HandleSetTrailingStop, w, req)
 
 			if w.Code != http.StatusOK {
 				t.Errorf("Expected 200, got %d - %s", w.Code, w.Body.String())
@@ -662,8 +702,8 @@ func TestRisk_CalculateLot(t *testing.T) {
 			var result map[string]interface{}
 			json.NewDecoder(w.Body).Decode(&result)
 
-			if result["recommendedLotSize"] == nil {
-				t.Error("Expected recommendedLotSize in response")
+			if result["lotSize"] == nil {
+				t.Error("Expected lotSize in response")
 			}
 		})
 	}
@@ -732,7 +772,6 @@ func TestAdmin_ExecutionModeToggle(t *testing.T) {
 			w := httptest.NewRecorder()
 
 			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				w.Header().Set("Access-Control-Allow-Origin", "*")
 				w.Header().Set("Content-Type", "application/json")
 
 				var reqData map[string]string
@@ -773,15 +812,14 @@ func TestConcurrent_PlaceOrders(t *testing.T) {
 				"symbol": "EURUSD",
 				"side":   "BUY",
 				"volume": 0.01,
-				"type":   "MARKET",
 			}
 			body, _ := json.Marshal(reqBody)
 
-			req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+			req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
-			tc.Server.HandlePlaceOrder(w, req)
+			tc.ServeAsTrader(tc.APIHandler.HandlePlaceMarketOrder, w, req)
 
 			if w.Code != http.StatusOK {
 				errors <- fmt.Errorf("order %d failed: %d - %s", orderNum, w.Code, w.Body.String())
@@ -808,25 +846,24 @@ func TestConcurrent_PlaceOrders(t *testing.T) {
 // ==================== BENCHMARK TESTS ====================
 
 func BenchmarkPlaceMarketOrder(b *testing.B) {
-	tc := SetupTest(&testing.T{})
+	tc := SetupTest(b)
 	tc.InjectPrice("EURUSD", 1.10000, 1.10020)
 
 	reqBody := map[string]interface{}{
 		"symbol": "EURUSD",
 		"side":   "BUY",
 		"volume": 0.1,
-		"type":   "MARKET",
 	}
 	body, _ := json.Marshal(reqBody)
 
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+		req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
 
-		tc.Server.HandlePlaceOrder(w, req)
+		tc.ServeAsTrader(tc.APIHandler.HandlePlaceMarketOrder, w, req)
 
 		if w.Code != http.StatusOK {
 			b.Fatalf("Order failed: %d", w.Code)
@@ -835,7 +872,7 @@ func BenchmarkPlaceMarketOrder(b *testing.B) {
 }
 
 func BenchmarkGetTicks(b *testing.B) {
-	tc := SetupTest(&testing.T{})
+	tc := SetupTest(b)
 	tc.InjectPrice("EURUSD", 1.10000, 1.10020)
 
 	b.ResetTimer()
@@ -853,7 +890,7 @@ func BenchmarkGetTicks(b *testing.B) {
 }
 
 func BenchmarkCalculateLot(b *testing.B) {
-	tc := SetupTest(&testing.T{})
+	tc := SetupTest(b)
 
 	b.ResetTimer()
 
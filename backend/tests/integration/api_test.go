@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -27,15 +30,19 @@ type TestServer struct {
 	hub         *ws.Hub
 	tickStore   *tickstore.TickStore
 	lpManager   *lpmanager.Manager
+	apiHandler  *handlers.APIHandler
 	httpServer  *httptest.Server
 }
 
 // SetupTestServer initializes a test server with all dependencies
-func SetupTestServer(t *testing.T) *TestServer {
+func SetupTestServer(t testing.TB) *TestServer {
 	t.Helper()
 
 	// Initialize B-Book engine
 	bbookEngine := core.NewEngine()
+	for _, symbol := range []string{"EURUSD", "GBPUSD", "USDJPY"} {
+		bbookEngine.UpdateSymbol(core.GenerateSymbolSpec(symbol))
+	}
 
 	// Initialize P/L engine
 	pnlEngine := core.NewPnLEngine(bbookEngine)
@@ -52,10 +59,12 @@ func SetupTestServer(t *testing.T) *TestServer {
 	tickStore := tickstore.NewTickStore("test", 1000)
 
 	// Initialize LP Manager with test config
-	lpManager := lpmanager.NewManager("../../data/lp_config.json")
+	lpManager := lpmanager.NewManager(filepath.Join(t.TempDir(), "lp_config.json"))
+	lpManager.LoadConfig()
 
 	// Create API handlers
 	apiHandler := handlers.NewAPIHandler(bbookEngine, pnlEngine)
+	apiHandler.SetAuthService(authService)
 
 	// Create server
 	server := api.NewServer(authService, apiHandler, lpManager)
@@ -64,6 +73,7 @@ func SetupTestServer(t *testing.T) *TestServer {
 	hub := ws.NewHub()
 	hub.SetTickStore(tickStore)
 	hub.SetBBookEngine(bbookEngine)
+	hub.SetAuthService(authService)
 
 	// Wire dependencies
 	server.SetHub(hub)
@@ -90,6 +100,7 @@ func SetupTestServer(t *testing.T) *TestServer {
 		hub:         hub,
 		tickStore:   tickStore,
 		lpManager:   lpManager,
+		apiHandler:  apiHandler,
 	}
 }
 
@@ -101,7 +112,7 @@ func (ts *TestServer) Cleanup() {
 }
 
 // Login performs login and returns JWT token
-func (ts *TestServer) Login(t *testing.T, username, password string) string {
+func (ts *TestServer) Login(t testing.TB, username, password string) string {
 	t.Helper()
 
 	reqBody := map[string]string{
@@ -129,6 +140,32 @@ func (ts *TestServer) Login(t *testing.T, username, password string) string {
 	return resp.Token
 }
 
+// TraderToken logs in as the test user and returns its trader token
+func (ts *TestServer) TraderToken(t testing.TB) string {
+	t.Helper()
+	return ts.Login(t, "test-user", "password123")
+}
+
+// ServeAsTrader runs next behind the trader role check, as main.go routes it
+func (ts *TestServer) ServeAsTrader(token string, next http.HandlerFunc, w http.ResponseWriter, req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+token)
+	ts.authService.RequireRole(auth.RoleTrader, next)(w, req)
+}
+
+// positionTradeID returns the position a B-Book market order opened, as the
+// tradeId the position routes take
+func positionTradeID(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var resp struct {
+		Position *core.Position `json:"position"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || resp.Position == nil {
+		t.Fatalf("Expected a position in the order response: %v", err)
+	}
+	return strconv.FormatInt(resp.Position.ID, 10)
+}
+
 // InjectPrice injects a test price into the market
 func (ts *TestServer) InjectPrice(symbol string, bid, ask float64) {
 	tick := &ws.MarketTick{
@@ -236,10 +273,11 @@ func TestLoginEndpoint(t *testing.T) {
 	}
 }
 
-// TestPlaceMarketOrder tests market order placement
+// TestPlaceMarketOrder tests B-Book market order placement
 func TestPlaceMarketOrder(t *testing.T) {
 	ts := SetupTestServer(t)
 	defer ts.Cleanup()
+	token := ts.TraderToken(t)
 
 	// Inject test price
 	ts.InjectPrice("EURUSD", 1.10000, 1.10020)
@@ -248,25 +286,56 @@ func TestPlaceMarketOrder(t *testing.T) {
 		"symbol": "EURUSD",
 		"side":   "BUY",
 		"volume": 0.1,
-		"type":   "MARKET",
 	}
 	body, _ := json.Marshal(reqBody)
 
-	req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	ts.server.HandlePlaceOrder(w, req)
+	ts.ServeAsTrader(token, ts.apiHandler.HandlePlaceMarketOrder, w, req)
 
 	if w.Code != http.StatusOK {
-		t.Errorf("Expected status 200, got %d - %s", w.Code, w.Body.String())
+		t.Fatalf("Expected status 200, got %d - %s", w.Code, w.Body.String())
 	}
 
 	var resp map[string]interface{}
 	json.NewDecoder(w.Body).Decode(&resp)
 
-	if !resp["success"].(bool) {
-		t.Error("Expected success to be true")
+	if resp["success"] != true {
+		t.Errorf("Expected success to be true, got %v", resp)
+	}
+}
+
+// TestPlaceLPMarketOrder tests that an A-Book market order for a known risk
+// account passes the pre-trade checks and stops at routing, since the test
+// server has no LP quotes
+func TestPlaceLPMarketOrder(t *testing.T) {
+	ts := SetupTestServer(t)
+	defer ts.Cleanup()
+
+	account := ts.server.GetRiskEngine().CreateAccount("test-user", 10000.0, 100)
+
+	reqBody := map[string]interface{}{
+		"accountId": fmt.Sprintf("%d", account.ID),
+		"symbol":    "EURUSD",
+		"side":      "BUY",
+		"volume":    0.1,
+		"type":      "MARKET",
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	ts.server.HandlePlaceOrder(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d - %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "no recent quote for EURUSD") {
+		t.Errorf("Expected the order to stop at LP routing, got %q", w.Body.String())
 	}
 }
 
@@ -274,6 +343,7 @@ func TestPlaceMarketOrder(t *testing.T) {
 func TestPlaceLimitOrder(t *testing.T) {
 	ts := SetupTestServer(t)
 	defer ts.Cleanup()
+	token := ts.TraderToken(t)
 
 	// Inject test price
 	ts.InjectPrice("EURUSD", 1.10000, 1.10020)
@@ -290,7 +360,7 @@ func TestPlaceLimitOrder(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	ts.server.HandlePlaceLimitOrder(w, req)
+	ts.ServeAsTrader(token, ts.server.HandlePlaceLimitOrder, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status 200, got %d - %s", w.Code, w.Body.String())
@@ -301,6 +371,7 @@ func TestPlaceLimitOrder(t *testing.T) {
 func TestGetPendingOrders(t *testing.T) {
 	ts := SetupTestServer(t)
 	defer ts.Cleanup()
+	token := ts.TraderToken(t)
 
 	// Place a limit order first
 	ts.InjectPrice("EURUSD", 1.10000, 1.10020)
@@ -316,13 +387,13 @@ func TestGetPendingOrders(t *testing.T) {
 	req := httptest.NewRequest("POST", "/order/limit", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	ts.server.HandlePlaceLimitOrder(w, req)
+	ts.ServeAsTrader(token, ts.server.HandlePlaceLimitOrder, w, req)
 
 	// Get pending orders
 	req = httptest.NewRequest("GET", "/orders/pending", nil)
 	w = httptest.NewRecorder()
 
-	ts.server.HandleGetPendingOrders(w, req)
+	ts.ServeAsTrader(token, ts.server.HandleGetPendingOrders, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status 200, got %d", w.Code)
@@ -340,6 +411,7 @@ func TestGetPendingOrders(t *testing.T) {
 func TestCancelOrder(t *testing.T) {
 	ts := SetupTestServer(t)
 	defer ts.Cleanup()
+	token := ts.TraderToken(t)
 
 	// Place a limit order
 	ts.InjectPrice("EURUSD", 1.10000, 1.10020)
@@ -355,7 +427,7 @@ func TestCancelOrder(t *testing.T) {
 	req := httptest.NewRequest("POST", "/order/limit", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	ts.server.HandlePlaceLimitOrder(w, req)
+	ts.ServeAsTrader(token, ts.server.HandlePlaceLimitOrder, w, req)
 
 	var orderResp map[string]interface{}
 	json.NewDecoder(w.Body).Decode(&orderResp)
@@ -371,7 +443,7 @@ func TestCancelOrder(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w = httptest.NewRecorder()
 
-	ts.server.HandleCancelOrder(w, req)
+	ts.ServeAsTrader(token, ts.server.HandleCancelOrder, w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status 200, got %d - %s", w.Code, w.Body.String())
@@ -459,8 +531,8 @@ func TestRiskCalculator(t *testing.T) {
 	var result map[string]interface{}
 	json.NewDecoder(w.Body).Decode(&result)
 
-	if result["recommendedLotSize"] == nil {
-		t.Error("Expected recommendedLotSize in response")
+	if result["lotSize"] == nil {
+		t.Error("Expected lotSize in response")
 	}
 }
 
@@ -496,7 +568,6 @@ func TestConfigEndpoint(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Content-Type", "application/json")
 		config := map[string]interface{}{
 			"brokerName":        "RTX Trading",
@@ -560,7 +631,6 @@ func TestExecutionModeToggle(t *testing.T) {
 			w := httptest.NewRecorder()
 
 			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				w.Header().Set("Access-Control-Allow-Origin", "*")
 				w.Header().Set("Content-Type", "application/json")
 
 				var reqData map[string]string
@@ -584,10 +654,11 @@ func TestExecutionModeToggle(t *testing.T) {
 	}
 }
 
-// TestConcurrentOrders tests placing multiple orders concurrently
+// TestConcurrentOrders tests placing multiple B-Book market orders concurrently
 func TestConcurrentOrders(t *testing.T) {
 	ts := SetupTestServer(t)
 	defer ts.Cleanup()
+	token := ts.TraderToken(t)
 
 	// Inject test price
 	ts.InjectPrice("EURUSD", 1.10000, 1.10020)
@@ -601,15 +672,14 @@ func TestConcurrentOrders(t *testing.T) {
 				"symbol": "EURUSD",
 				"side":   "BUY",
 				"volume": 0.1,
-				"type":   "MARKET",
 			}
 			body, _ := json.Marshal(reqBody)
 
-			req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+			req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
-			ts.server.HandlePlaceOrder(w, req)
+			ts.ServeAsTrader(token, ts.apiHandler.HandlePlaceMarketOrder, w, req)
 
 			if w.Code != http.StatusOK {
 				t.Errorf("Order %d failed: %d - %s", orderNum, w.Code, w.Body.String())
@@ -699,10 +769,11 @@ func TestInvalidRequests(t *testing.T) {
 	}
 }
 
-// BenchmarkPlaceOrder benchmarks order placement performance
+// BenchmarkPlaceOrder benchmarks B-Book market order placement performance
 func BenchmarkPlaceOrder(b *testing.B) {
-	ts := SetupTestServer(&testing.T{})
+	ts := SetupTestServer(b)
 	defer ts.Cleanup()
+	token := ts.TraderToken(b)
 
 	// Inject test price
 	ts.InjectPrice("EURUSD", 1.10000, 1.10020)
@@ -711,18 +782,17 @@ func BenchmarkPlaceOrder(b *testing.B) {
 		"symbol": "EURUSD",
 		"side":   "BUY",
 		"volume": 0.1,
-		"type":   "MARKET",
 	}
 	body, _ := json.Marshal(reqBody)
 
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+		req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
 
-		ts.server.HandlePlaceOrder(w, req)
+		ts.ServeAsTrader(token, ts.apiHandler.HandlePlaceMarketOrder, w, req)
 
 		if w.Code != http.StatusOK {
 			b.Fatalf("Order placement failed: %d", w.Code)
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -51,7 +52,8 @@ func SetupEndpointTestServer(t *testing.T) *EndpointTestServer {
 
 	// Initialize other components
 	tickStore := tickstore.NewTickStore("test", 1000)
-	lpManager := lpmanager.NewManager("../../data/lp_config.json")
+	lpManager := lpmanager.NewManager(filepath.Join(t.TempDir(), "lp_config.json"))
+	lpManager.LoadConfig()
 
 	// Create API handler
 	apiHandler := handlers.NewAPIHandler(bbookEngine, pnlEngine)
@@ -12,6 +12,7 @@ import (
 func TestCompleteOrderFlow(t *testing.T) {
 	ts := SetupTestServer(t)
 	defer ts.Cleanup()
+	token := ts.TraderToken(t)
 
 	// Step 1: Inject market prices
 	ts.InjectPrice("EURUSD", 1.10000, 1.10020)
@@ -21,15 +22,14 @@ func TestCompleteOrderFlow(t *testing.T) {
 		"symbol": "EURUSD",
 		"side":   "BUY",
 		"volume": 0.5,
-		"type":   "MARKET",
 	}
 	body, _ := json.Marshal(orderReq)
 
-	req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	ts.server.HandlePlaceOrder(w, req)
+	ts.ServeAsTrader(token, ts.apiHandler.HandlePlaceMarketOrder, w, req)
 
 	if w.Code != 200 {
 		t.Fatalf("Order placement failed: %d - %s", w.Code, w.Body.String())
@@ -56,7 +56,6 @@ func TestCompleteOrderFlow(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 
 	// Step 6: Close position (if trade ID available)
-	// Note: In A-Book mode, position management may differ
 	t.Log("Order flow test completed successfully")
 }
 
@@ -64,6 +63,7 @@ func TestCompleteOrderFlow(t *testing.T) {
 func TestLimitOrderActivation(t *testing.T) {
 	ts := SetupTestServer(t)
 	defer ts.Cleanup()
+	token := ts.TraderToken(t)
 
 	// Set initial price
 	ts.InjectPrice("EURUSD", 1.10000, 1.10020)
@@ -81,7 +81,7 @@ func TestLimitOrderActivation(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	ts.server.HandlePlaceLimitOrder(w, req)
+	ts.ServeAsTrader(token, ts.server.HandlePlaceLimitOrder, w, req)
 
 	if w.Code != 200 {
 		t.Fatalf("Limit order placement failed: %d", w.Code)
@@ -97,7 +97,7 @@ func TestLimitOrderActivation(t *testing.T) {
 	req = httptest.NewRequest("GET", "/orders/pending", nil)
 	w = httptest.NewRecorder()
 
-	ts.server.HandleGetPendingOrders(w, req)
+	ts.ServeAsTrader(token, ts.server.HandleGetPendingOrders, w, req)
 
 	var pendingOrders []map[string]interface{}
 	json.NewDecoder(w.Body).Decode(&pendingOrders)
@@ -118,6 +118,7 @@ func TestLimitOrderActivation(t *testing.T) {
 func TestStopOrderActivation(t *testing.T) {
 	ts := SetupTestServer(t)
 	defer ts.Cleanup()
+	token := ts.TraderToken(t)
 
 	// Set initial price
 	ts.InjectPrice("GBPUSD", 1.25000, 1.25020)
@@ -135,7 +136,7 @@ func TestStopOrderActivation(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	ts.server.HandlePlaceStopOrder(w, req)
+	ts.ServeAsTrader(token, ts.server.HandlePlaceStopOrder, w, req)
 
 	if w.Code != 200 {
 		t.Fatalf("Stop order placement failed: %d", w.Code)
@@ -157,6 +158,7 @@ func TestStopOrderActivation(t *testing.T) {
 func TestOrderModification(t *testing.T) {
 	ts := SetupTestServer(t)
 	defer ts.Cleanup()
+	token := ts.TraderToken(t)
 
 	// Place an order first
 	ts.InjectPrice("EURUSD", 1.10000, 1.10020)
@@ -165,26 +167,22 @@ func TestOrderModification(t *testing.T) {
 		"symbol": "EURUSD",
 		"side":   "BUY",
 		"volume": 0.1,
-		"type":   "MARKET",
 		"sl":     1.09500,
 		"tp":     1.10500,
 	}
 	body, _ := json.Marshal(orderReq)
 
-	req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	ts.server.HandlePlaceOrder(w, req)
+	ts.ServeAsTrader(token, ts.apiHandler.HandlePlaceMarketOrder, w, req)
 
 	if w.Code != 200 {
 		t.Fatalf("Order placement failed: %d", w.Code)
 	}
 
-	var orderResp map[string]interface{}
-	json.NewDecoder(w.Body).Decode(&orderResp)
-	order := orderResp["order"].(map[string]interface{})
-	tradeID := order["ClientOrderID"].(string)
+	tradeID := positionTradeID(t, w)
 
 	time.Sleep(100 * time.Millisecond)
 
@@ -220,6 +218,7 @@ func TestOrderModification(t *testing.T) {
 func TestBreakevenScenario(t *testing.T) {
 	ts := SetupTestServer(t)
 	defer ts.Cleanup()
+	token := ts.TraderToken(t)
 
 	// Place order
 	ts.InjectPrice("USDJPY", 110.000, 110.020)
@@ -227,25 +226,21 @@ func TestBreakevenScenario(t *testing.T) {
 	orderReq := map[string]interface{}{
 		"symbol": "USDJPY",
 		"side":   "BUY",
-		"volume": 0.3,
-		"type":   "MARKET",
+		"volume": 0.05,
 	}
 	body, _ := json.Marshal(orderReq)
 
-	req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	ts.server.HandlePlaceOrder(w, req)
+	ts.ServeAsTrader(token, ts.apiHandler.HandlePlaceMarketOrder, w, req)
 
 	if w.Code != 200 {
-		t.Fatalf("Order failed: %d", w.Code)
+		t.Fatalf("Order failed: %d - %s", w.Code, w.Body.String())
 	}
 
-	var orderResp map[string]interface{}
-	json.NewDecoder(w.Body).Decode(&orderResp)
-	order := orderResp["order"].(map[string]interface{})
-	tradeID := order["ClientOrderID"].(string)
+	tradeID := positionTradeID(t, w)
 
 	time.Sleep(100 * time.Millisecond)
 
@@ -272,6 +267,7 @@ func TestBreakevenScenario(t *testing.T) {
 func TestTrailingStop(t *testing.T) {
 	ts := SetupTestServer(t)
 	defer ts.Cleanup()
+	token := ts.TraderToken(t)
 
 	// Place order
 	ts.InjectPrice("EURUSD", 1.10000, 1.10020)
@@ -280,24 +276,20 @@ func TestTrailingStop(t *testing.T) {
 		"symbol": "EURUSD",
 		"side":   "BUY",
 		"volume": 0.2,
-		"type":   "MARKET",
 	}
 	body, _ := json.Marshal(orderReq)
 
-	req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	ts.server.HandlePlaceOrder(w, req)
+	ts.ServeAsTrader(token, ts.apiHandler.HandlePlaceMarketOrder, w, req)
 
 	if w.Code != 200 {
-		t.Fatalf("Order failed: %d", w.Code)
+		t.Fatalf("Order failed: %d - %s", w.Code, w.Body.String())
 	}
 
-	var orderResp map[string]interface{}
-	json.NewDecoder(w.Body).Decode(&orderResp)
-	order := orderResp["order"].(map[string]interface{})
-	tradeID := order["ClientOrderID"].(string)
+	tradeID := positionTradeID(t, w)
 
 	time.Sleep(100 * time.Millisecond)
 
@@ -315,7 +307,7 @@ func TestTrailingStop(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w = httptest.NewRecorder()
 
-	ts.server.HandleSetTrailingStop(w, req)
+	ts.ServeAsTrader(token, ts.server.HandleSetTrailingStop, w, req)
 
 	if w.Code != 200 {
 		t.Errorf("Trailing stop failed: %d - %s", w.Code, w.Body.String())
@@ -342,6 +334,7 @@ func TestTrailingStop(t *testing.T) {
 func TestMultiplePositionsSameSymbol(t *testing.T) {
 	ts := SetupTestServer(t)
 	defer ts.Cleanup()
+	token := ts.TraderToken(t)
 
 	ts.InjectPrice("EURUSD", 1.10000, 1.10020)
 
@@ -350,15 +343,14 @@ func TestMultiplePositionsSameSymbol(t *testing.T) {
 		"symbol": "EURUSD",
 		"side":   "BUY",
 		"volume": 0.1,
-		"type":   "MARKET",
 	}
 	body, _ := json.Marshal(order1)
 
-	req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	ts.server.HandlePlaceOrder(w, req)
+	ts.ServeAsTrader(token, ts.apiHandler.HandlePlaceMarketOrder, w, req)
 
 	if w.Code != 200 {
 		t.Fatalf("First order failed: %d", w.Code)
@@ -371,15 +363,14 @@ func TestMultiplePositionsSameSymbol(t *testing.T) {
 		"symbol": "EURUSD",
 		"side":   "BUY",
 		"volume": 0.2,
-		"type":   "MARKET",
 	}
 	body, _ = json.Marshal(order2)
 
-	req = httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+	req = httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w = httptest.NewRecorder()
 
-	ts.server.HandlePlaceOrder(w, req)
+	ts.ServeAsTrader(token, ts.apiHandler.HandlePlaceMarketOrder, w, req)
 
 	if w.Code != 200 {
 		t.Fatalf("Second order failed: %d", w.Code)
@@ -392,15 +383,14 @@ func TestMultiplePositionsSameSymbol(t *testing.T) {
 		"symbol": "EURUSD",
 		"side":   "SELL",
 		"volume": 0.15,
-		"type":   "MARKET",
 	}
 	body, _ = json.Marshal(order3)
 
-	req = httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+	req = httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w = httptest.NewRecorder()
 
-	ts.server.HandlePlaceOrder(w, req)
+	ts.ServeAsTrader(token, ts.apiHandler.HandlePlaceMarketOrder, w, req)
 
 	if w.Code != 200 {
 		t.Fatalf("Third order failed: %d", w.Code)
@@ -421,6 +411,7 @@ func TestMultiplePositionsSameSymbol(t *testing.T) {
 func TestPartialClose(t *testing.T) {
 	ts := SetupTestServer(t)
 	defer ts.Cleanup()
+	token := ts.TraderToken(t)
 
 	// Place order
 	ts.InjectPrice("GBPUSD", 1.25000, 1.25020)
@@ -429,24 +420,20 @@ func TestPartialClose(t *testing.T) {
 		"symbol": "GBPUSD",
 		"side":   "BUY",
 		"volume": 1.0,
-		"type":   "MARKET",
 	}
 	body, _ := json.Marshal(orderReq)
 
-	req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	ts.server.HandlePlaceOrder(w, req)
+	ts.ServeAsTrader(token, ts.apiHandler.HandlePlaceMarketOrder, w, req)
 
 	if w.Code != 200 {
-		t.Fatalf("Order failed: %d", w.Code)
+		t.Fatalf("Order failed: %d - %s", w.Code, w.Body.String())
 	}
 
-	var orderResp map[string]interface{}
-	json.NewDecoder(w.Body).Decode(&orderResp)
-	order := orderResp["order"].(map[string]interface{})
-	tradeID := order["ClientOrderID"].(string)
+	tradeID := positionTradeID(t, w)
 
 	time.Sleep(100 * time.Millisecond)
 
@@ -470,6 +457,7 @@ func TestPartialClose(t *testing.T) {
 func TestOrderRejection(t *testing.T) {
 	ts := SetupTestServer(t)
 	defer ts.Cleanup()
+	token := ts.TraderToken(t)
 
 	tests := []struct {
 		name  string
@@ -481,7 +469,6 @@ func TestOrderRejection(t *testing.T) {
 				"symbol": "EURUSD",
 				"side":   "BUY",
 				"volume": 100.0, // Too large
-				"type":   "MARKET",
 			},
 		},
 		{
@@ -490,7 +477,6 @@ func TestOrderRejection(t *testing.T) {
 				"symbol": "INVALID",
 				"side":   "BUY",
 				"volume": 0.1,
-				"type":   "MARKET",
 			},
 		},
 	}
@@ -499,11 +485,11 @@ func TestOrderRejection(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			body, _ := json.Marshal(tt.order)
 
-			req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+			req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
-			ts.server.HandlePlaceOrder(w, req)
+			ts.ServeAsTrader(token, ts.apiHandler.HandlePlaceMarketOrder, w, req)
 
 			t.Logf("%s: Status %d - %s", tt.name, w.Code, w.Body.String())
 		})
@@ -514,6 +500,7 @@ func TestOrderRejection(t *testing.T) {
 func TestBidAskSpread(t *testing.T) {
 	ts := SetupTestServer(t)
 	defer ts.Cleanup()
+	token := ts.TraderToken(t)
 
 	// Test normal spread
 	ts.InjectPrice("EURUSD", 1.10000, 1.10002)
@@ -522,15 +509,14 @@ func TestBidAskSpread(t *testing.T) {
 		"symbol": "EURUSD",
 		"side":   "BUY",
 		"volume": 0.1,
-		"type":   "MARKET",
 	}
 	body, _ := json.Marshal(orderReq)
 
-	req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	ts.server.HandlePlaceOrder(w, req)
+	ts.ServeAsTrader(token, ts.apiHandler.HandlePlaceMarketOrder, w, req)
 
 	if w.Code != 200 {
 		t.Fatalf("Order with normal spread failed: %d", w.Code)
@@ -545,15 +531,14 @@ func TestBidAskSpread(t *testing.T) {
 		"symbol": "EURUSD",
 		"side":   "SELL",
 		"volume": 0.1,
-		"type":   "MARKET",
 	}
 	body, _ = json.Marshal(orderReq2)
 
-	req = httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+	req = httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w = httptest.NewRecorder()
 
-	ts.server.HandlePlaceOrder(w, req)
+	ts.ServeAsTrader(token, ts.apiHandler.HandlePlaceMarketOrder, w, req)
 
 	t.Logf("Wide spread order: %d - %s", w.Code, w.Body.String())
 }
@@ -562,6 +547,7 @@ func TestBidAskSpread(t *testing.T) {
 func TestOrderFlowWithPriceGap(t *testing.T) {
 	ts := SetupTestServer(t)
 	defer ts.Cleanup()
+	token := ts.TraderToken(t)
 
 	// Initial price
 	ts.InjectPrice("EURUSD", 1.10000, 1.10020)
@@ -579,7 +565,7 @@ func TestOrderFlowWithPriceGap(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	ts.server.HandlePlaceLimitOrder(w, req)
+	ts.ServeAsTrader(token, ts.server.HandlePlaceLimitOrder, w, req)
 
 	if w.Code != 200 {
 		t.Fatalf("Limit order failed: %d", w.Code)
@@ -11,6 +11,12 @@ import (
 	"github.com/gorilla/websocket"
 )
 
+// WebSocketURL returns the ws:// URL of server carrying the test user's token
+func (ts *TestServer) WebSocketURL(t testing.TB, server *httptest.Server) string {
+	t.Helper()
+	return "ws" + server.URL[4:] + "/ws?token=" + ts.TraderToken(t)
+}
+
 // TestWebSocketConnection tests basic WebSocket connection
 func TestWebSocketConnection(t *testing.T) {
 	ts := SetupTestServer(t)
@@ -23,7 +29,7 @@ func TestWebSocketConnection(t *testing.T) {
 	defer server.Close()
 
 	// Convert http:// to ws://
-	wsURL := "ws" + server.URL[4:] + "/ws"
+	wsURL := ts.WebSocketURL(t, server)
 
 	// Connect WebSocket client
 	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
@@ -47,7 +53,7 @@ func TestWebSocketTickStream(t *testing.T) {
 	}))
 	defer server.Close()
 
-	wsURL := "ws" + server.URL[4:] + "/ws"
+	wsURL := ts.WebSocketURL(t, server)
 
 	// Connect WebSocket client
 	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
@@ -102,7 +108,7 @@ func TestWebSocketSubscription(t *testing.T) {
 	}))
 	defer server.Close()
 
-	wsURL := "ws" + server.URL[4:] + "/ws"
+	wsURL := ts.WebSocketURL(t, server)
 
 	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
 	if err != nil {
@@ -167,7 +173,7 @@ func TestWebSocketMultipleClients(t *testing.T) {
 	}))
 	defer server.Close()
 
-	wsURL := "ws" + server.URL[4:] + "/ws"
+	wsURL := ts.WebSocketURL(t, server)
 
 	numClients := 5
 	connections := make([]*websocket.Conn, numClients)
@@ -240,7 +246,7 @@ func TestWebSocketReconnection(t *testing.T) {
 	}))
 	defer server.Close()
 
-	wsURL := "ws" + server.URL[4:] + "/ws"
+	wsURL := ts.WebSocketURL(t, server)
 
 	// First connection
 	conn1, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
@@ -292,7 +298,7 @@ func TestWebSocketBinaryMessages(t *testing.T) {
 	}))
 	defer server.Close()
 
-	wsURL := "ws" + server.URL[4:] + "/ws"
+	wsURL := ts.WebSocketURL(t, server)
 
 	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
 	if err != nil {
@@ -321,7 +327,7 @@ func TestWebSocketPingPong(t *testing.T) {
 	}))
 	defer server.Close()
 
-	wsURL := "ws" + server.URL[4:] + "/ws"
+	wsURL := ts.WebSocketURL(t, server)
 
 	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
 	if err != nil {
@@ -369,7 +375,7 @@ func TestWebSocketMessageOrdering(t *testing.T) {
 	}))
 	defer server.Close()
 
-	wsURL := "ws" + server.URL[4:] + "/ws"
+	wsURL := ts.WebSocketURL(t, server)
 
 	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
 	if err != nil {
@@ -434,7 +440,7 @@ func TestWebSocketErrorHandling(t *testing.T) {
 	}))
 	defer server.Close()
 
-	wsURL := "ws" + server.URL[4:] + "/ws"
+	wsURL := ts.WebSocketURL(t, server)
 
 	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
 	if err != nil {
@@ -481,7 +487,7 @@ func TestWebSocketErrorHandling(t *testing.T) {
 
 // BenchmarkWebSocketThroughput benchmarks message throughput
 func BenchmarkWebSocketThroughput(b *testing.B) {
-	ts := SetupTestServer(&testing.T{})
+	ts := SetupTestServer(b)
 	defer ts.Cleanup()
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -489,7 +495,7 @@ func BenchmarkWebSocketThroughput(b *testing.B) {
 	}))
 	defer server.Close()
 
-	wsURL := "ws" + server.URL[4:] + "/ws"
+	wsURL := ts.WebSocketURL(b, server)
 
 	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -44,15 +45,14 @@ func TestWorkflow_CompleteTrading(t *testing.T) {
 			"symbol": order.symbol,
 			"side":   order.side,
 			"volume": order.volume,
-			"type":   "MARKET",
 		}
 		body, _ := json.Marshal(reqBody)
 
-		req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+		req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
 
-		tc.Server.HandlePlaceOrder(w, req)
+		tc.ServeAsTrader(tc.APIHandler.HandlePlaceMarketOrder, w, req)
 
 		if w.Code != 200 {
 			t.Fatalf("Failed to place %s %s order: %d", order.side, order.symbol, w.Code)
@@ -104,9 +104,9 @@ func TestWorkflow_CompleteTrading(t *testing.T) {
 		w := httptest.NewRecorder()
 
 		if order.orderType == "LIMIT" {
-			tc.Server.HandlePlaceLimitOrder(w, req)
+			tc.ServeAsTrader(tc.Server.HandlePlaceLimitOrder, w, req)
 		} else {
-			tc.Server.HandlePlaceStopOrder(w, req)
+			tc.ServeAsTrader(tc.Server.HandlePlaceStopOrder, w, req)
 		}
 
 		if w.Code != 200 {
@@ -119,7 +119,7 @@ func TestWorkflow_CompleteTrading(t *testing.T) {
 	t.Log("Step 6: Get pending orders")
 	req := httptest.NewRequest("GET", "/orders/pending", nil)
 	w := httptest.NewRecorder()
-	tc.Server.HandleGetPendingOrders(w, req)
+	tc.ServeAsTrader(tc.Server.HandleGetPendingOrders, w, req)
 
 	var pending []interface{}
 	json.NewDecoder(w.Body).Decode(&pending)
@@ -149,7 +149,7 @@ func TestWorkflow_CompleteTrading(t *testing.T) {
 	req = httptest.NewRequest("POST", "/position/trailing-stop", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w = httptest.NewRecorder()
-	tc.Server.HandleSetTrailingStop(w, req)
+	tc.ServeAsTrader(tc.Server.HandleSetTrailingStop, w, req)
 
 	t.Log("Step 9: Update market prices")
 	tc.InjectPrice("EURUSD", 1.10050, 1.10070)
@@ -178,15 +178,14 @@ func TestWorkflow_PositionLifecycle(t *testing.T) {
 		"symbol": "EURUSD",
 		"side":   "BUY",
 		"volume": 1.0,
-		"type":   "MARKET",
 		"sl":     1.09000,
 		"tp":     1.11000,
 	}
 	body, _ := json.Marshal(openReq)
-	req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	tc.Server.HandlePlaceOrder(w, req)
+	tc.ServeAsTrader(tc.APIHandler.HandlePlaceMarketOrder, w, req)
 
 	if w.Code != 200 {
 		t.Fatalf("Failed to open position: %d - %s", w.Code, w.Body.String())
@@ -226,7 +225,7 @@ func TestWorkflow_PositionLifecycle(t *testing.T) {
 	req = httptest.NewRequest("POST", "/position/trailing-stop", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w = httptest.NewRecorder()
-	tc.Server.HandleSetTrailingStop(w, req)
+	tc.ServeAsTrader(tc.Server.HandleSetTrailingStop, w, req)
 	t.Log("Trailing stop enabled")
 
 	t.Log("Phase 5: Price movement")
@@ -279,15 +278,14 @@ func TestWorkflow_MultiSymbolTrading(t *testing.T) {
 			"symbol": s.symbol,
 			"side":   side,
 			"volume": 0.1,
-			"type":   "MARKET",
 		}
 		body, _ := json.Marshal(reqBody)
 
-		req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+		req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
 
-		tc.Server.HandlePlaceOrder(w, req)
+		tc.ServeAsTrader(tc.APIHandler.HandlePlaceMarketOrder, w, req)
 
 		if w.Code != 200 {
 			t.Fatalf("Failed to place order for %s: %d", s.symbol, w.Code)
@@ -353,7 +351,7 @@ func TestWorkflow_OrderManagement(t *testing.T) {
 		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
 
-		tc.Server.HandlePlaceLimitOrder(w, req)
+		tc.ServeAsTrader(tc.Server.HandlePlaceLimitOrder, w, req)
 
 		if w.Code != 200 {
 			t.Fatalf("Failed to place limit order @ %.5f: %d", order.price, w.Code)
@@ -372,7 +370,7 @@ func TestWorkflow_OrderManagement(t *testing.T) {
 	t.Log("Step 2: List pending orders")
 	req := httptest.NewRequest("GET", "/orders/pending", nil)
 	w := httptest.NewRecorder()
-	tc.Server.HandleGetPendingOrders(w, req)
+	tc.ServeAsTrader(tc.Server.HandleGetPendingOrders, w, req)
 
 	var pending []interface{}
 	json.NewDecoder(w.Body).Decode(&pending)
@@ -390,7 +388,7 @@ func TestWorkflow_OrderManagement(t *testing.T) {
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
-			tc.Server.HandleCancelOrder(w, req)
+			tc.ServeAsTrader(tc.Server.HandleCancelOrder, w, req)
 
 			if w.Code == 200 {
 				t.Logf("Cancelled order: %s", orderID)
@@ -401,7 +399,7 @@ func TestWorkflow_OrderManagement(t *testing.T) {
 	t.Log("Step 4: Verify remaining orders")
 	req = httptest.NewRequest("GET", "/orders/pending", nil)
 	w = httptest.NewRecorder()
-	tc.Server.HandleGetPendingOrders(w, req)
+	tc.ServeAsTrader(tc.Server.HandleGetPendingOrders, w, req)
 
 	json.NewDecoder(w.Body).Decode(&pending)
 	t.Logf("Remaining pending orders: %d", len(pending))
@@ -417,7 +415,7 @@ func TestWorkflow_RiskManagement(t *testing.T) {
 	riskLevels := []float64{1.0, 2.0, 3.0, 5.0}
 
 	for _, risk := range riskLevels {
-		req := httptest.NewRequest("GET", "/risk/calculate-lot?symbol=EURUSD&riskPercent="+string(rune(risk))+"&slPips=20", nil)
+		req := httptest.NewRequest("GET", "/risk/calculate-lot?symbol=EURUSD&riskPercent="+strconv.FormatFloat(risk, 'f', -1, 64)+"&slPips=20", nil)
 		w := httptest.NewRecorder()
 		tc.Server.HandleCalculateLot(w, req)
 
@@ -428,14 +426,14 @@ func TestWorkflow_RiskManagement(t *testing.T) {
 
 		var result map[string]interface{}
 		json.NewDecoder(w.Body).Decode(&result)
-		t.Logf("Risk %.1f%%: Lot size = %v", risk, result["recommendedLotSize"])
+		t.Logf("Risk %.1f%%: Lot size = %v", risk, result["lotSize"])
 	}
 
 	t.Log("Phase 2: Preview margin for different volumes")
 	volumes := []float64{0.1, 0.5, 1.0, 2.0, 5.0}
 
 	for _, vol := range volumes {
-		req := httptest.NewRequest("GET", "/risk/margin-preview?symbol=EURUSD&volume="+string(rune(vol))+"&side=BUY", nil)
+		req := httptest.NewRequest("GET", "/risk/margin-preview?symbol=EURUSD&volume="+strconv.FormatFloat(vol, 'f', -1, 64)+"&side=BUY", nil)
 		w := httptest.NewRecorder()
 		tc.Server.HandleMarginPreview(w, req)
 
@@ -151,15 +151,14 @@ func TestLoad_PlaceOrders_100Concurrent(t *testing.T) {
 					"symbol": "EURUSD",
 					"side":   "BUY",
 					"volume": 0.01,
-					"type":   "MARKET",
 				}
 				body, _ := json.Marshal(reqBody)
 
-				req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+				req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 				req.Header.Set("Content-Type", "application/json")
 				w := httptest.NewRecorder()
 
-				tc.Server.HandlePlaceOrder(w, req)
+				tc.ServeAsTrader(tc.APIHandler.HandlePlaceMarketOrder, w, req)
 
 				latency := time.Since(reqStart)
 				success := w.Code == 200
@@ -218,15 +217,14 @@ func TestLoad_PlaceOrders_1000Concurrent(t *testing.T) {
 					"symbol": "EURUSD",
 					"side":   "BUY",
 					"volume": 0.01,
-					"type":   "MARKET",
 				}
 				body, _ := json.Marshal(reqBody)
 
-				req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+				req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 				req.Header.Set("Content-Type", "application/json")
 				w := httptest.NewRecorder()
 
-				tc.Server.HandlePlaceOrder(w, req)
+				tc.ServeAsTrader(tc.APIHandler.HandlePlaceMarketOrder, w, req)
 
 				latency := time.Since(reqStart)
 				success := w.Code == 200
@@ -271,7 +269,7 @@ func TestLoad_MarketDataStream(t *testing.T) {
 			bid := 1.10000 + float64(i)*0.00001
 			ask := bid + 0.00020
 
-			tc.InjectPrice(symbol, bid, ask)
+			tc.BroadcastPrice(symbol, bid, ask)
 
 			latency := time.Since(tickStart)
 			metrics.RecordRequest(latency, true)
@@ -317,13 +315,12 @@ func TestLoad_MixedOperations(t *testing.T) {
 						"symbol": "EURUSD",
 						"side":   "BUY",
 						"volume": 0.01,
-						"type":   "MARKET",
 					}
 					body, _ := json.Marshal(reqBody)
-					req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+					req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 					req.Header.Set("Content-Type", "application/json")
 					w := httptest.NewRecorder()
-					tc.Server.HandlePlaceOrder(w, req)
+					tc.ServeAsTrader(tc.APIHandler.HandlePlaceMarketOrder, w, req)
 					metrics.RecordRequest(time.Since(reqStart), w.Code == 200)
 				},
 				// Get ticks
@@ -347,7 +344,7 @@ func TestLoad_MixedOperations(t *testing.T) {
 					reqStart := time.Now()
 					req := httptest.NewRequest("GET", "/orders/pending", nil)
 					w := httptest.NewRecorder()
-					tc.Server.HandleGetPendingOrders(w, req)
+					tc.ServeAsTrader(tc.Server.HandleGetPendingOrders, w, req)
 					metrics.RecordRequest(time.Since(reqStart), w.Code == 200)
 				},
 			}
@@ -388,15 +385,14 @@ func TestLoad_DatabaseLoad(t *testing.T) {
 			"symbol": "EURUSD",
 			"side":   "BUY",
 			"volume": 0.01,
-			"type":   "MARKET",
 		}
 		body, _ := json.Marshal(reqBody)
 
-		req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+		req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
 
-		tc.Server.HandlePlaceOrder(w, req)
+		tc.ServeAsTrader(tc.APIHandler.HandlePlaceMarketOrder, w, req)
 
 		latency := time.Since(reqStart)
 		metrics.RecordRequest(latency, w.Code == 200)
@@ -436,7 +432,7 @@ func TestLoad_MemoryUsage(t *testing.T) {
 			bid := 1.0 + float64(j)*0.0001
 			ask := bid + 0.0002
 
-			tc.InjectPrice(symbol, bid, ask)
+			tc.BroadcastPrice(symbol, bid, ask)
 		}
 	}
 
@@ -481,6 +477,24 @@ func TestLoad_StressTest(t *testing.T) {
 	var wg sync.WaitGroup
 	stop := make(chan bool)
 
+	// Keep quotes fresh so orders aren't rejected on stale prices
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				for _, symbol := range symbols {
+					tc.BroadcastPrice(symbol, 1.10000, 1.10020)
+				}
+			}
+		}
+	}()
+
 	startTime := time.Now()
 
 	// Start concurrent users
@@ -506,13 +520,12 @@ func TestLoad_StressTest(t *testing.T) {
 							"symbol": symbol,
 							"side":   "BUY",
 							"volume": 0.01,
-							"type":   "MARKET",
 						}
 						body, _ := json.Marshal(reqBody)
-						req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+						req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 						req.Header.Set("Content-Type", "application/json")
 						w := httptest.NewRecorder()
-						tc.Server.HandlePlaceOrder(w, req)
+						tc.ServeAsTrader(tc.APIHandler.HandlePlaceMarketOrder, w, req)
 						metrics.RecordRequest(time.Since(reqStart), w.Code == 200)
 
 					case 1: // Get ticks
@@ -564,14 +577,13 @@ func TestLoad_StressTest(t *testing.T) {
 // ==================== BENCHMARK TESTS ====================
 
 func BenchmarkLoad_PlaceOrder(b *testing.B) {
-	tc := SetupTest(&testing.T{})
+	tc := SetupTest(b)
 	tc.InjectPrice("EURUSD", 1.10000, 1.10020)
 
 	reqBody := map[string]interface{}{
 		"symbol": "EURUSD",
 		"side":   "BUY",
 		"volume": 0.01,
-		"type":   "MARKET",
 	}
 	body, _ := json.Marshal(reqBody)
 
@@ -580,16 +592,16 @@ func BenchmarkLoad_PlaceOrder(b *testing.B) {
 
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
-			req := httptest.NewRequest("POST", "/order", bytes.NewReader(body))
+			req := httptest.NewRequest("POST", "/api/orders/market", bytes.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
-			tc.Server.HandlePlaceOrder(w, req)
+			tc.ServeAsTrader(tc.APIHandler.HandlePlaceMarketOrder, w, req)
 		}
 	})
 }
 
 func BenchmarkLoad_GetTicks(b *testing.B) {
-	tc := SetupTest(&testing.T{})
+	tc := SetupTest(b)
 	tc.InjectPrice("EURUSD", 1.10000, 1.10020)
 
 	b.ResetTimer()
@@ -605,7 +617,7 @@ func BenchmarkLoad_GetTicks(b *testing.B) {
 }
 
 func BenchmarkLoad_CalculateRisk(b *testing.B) {
-	tc := SetupTest(&testing.T{})
+	tc := SetupTest(b)
 
 	b.ResetTimer()
 	b.ReportAllocs()
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/epic1st/rtx/backend/auth"
 	"github.com/epic1st/rtx/backend/internal/core"
 	"github.com/epic1st/rtx/backend/tickstore"
 	"github.com/epic1st/rtx/backend/ws"
@@ -22,6 +23,7 @@ type WebSocketTestContext struct {
 	Hub       *ws.Hub
 	BBEngine  *core.Engine
 	TickStore *tickstore.TickStore
+	Token     string
 }
 
 // SetupWebSocketTest initializes WebSocket test environment
@@ -35,6 +37,15 @@ func SetupWebSocketTest(t *testing.T) *WebSocketTestContext {
 	hub.SetTickStore(tickStore)
 	hub.SetBBookEngine(bbEngine)
 
+	// The hub only upgrades authenticated connections
+	authService := auth.NewService(bbEngine, "", "test-jwt-secret")
+	hub.SetAuthService(authService)
+	bbEngine.CreateAccount("ws-user", "WS User", "ws123", true)
+	token, _, err := authService.Login("ws-user", "ws123")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
 	// Start hub
 	go hub.Run()
 
@@ -48,6 +59,7 @@ func SetupWebSocketTest(t *testing.T) *WebSocketTestContext {
 		Hub:       hub,
 		BBEngine:  bbEngine,
 		TickStore: tickStore,
+		Token:     token,
 	}
 }
 
@@ -62,7 +74,7 @@ func (wc *WebSocketTestContext) Cleanup() {
 func (wc *WebSocketTestContext) ConnectWebSocket(t *testing.T) *websocket.Conn {
 	t.Helper()
 
-	url := "ws" + strings.TrimPrefix(wc.Server.URL, "http")
+	url := "ws" + strings.TrimPrefix(wc.Server.URL, "http") + "?token=" + wc.Token
 	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
 	if err != nil {
 		t.Fatalf("Failed to connect WebSocket: %v", err)
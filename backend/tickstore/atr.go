@@ -0,0 +1,20 @@
+package tickstore
+
+import "math"
+
+// AverageTrueRange returns the simple average of the true ranges of the last
+// period bars (oldest first), or 0 with fewer than period+1 bars
+func AverageTrueRange(bars []OHLC, period int) float64 {
+	n := len(bars)
+	if period <= 0 || n < period+1 {
+		return 0
+	}
+
+	sum := 0.0
+	for i := n - period; i < n; i++ {
+		prevClose := bars[i-1].Close
+		sum += math.Max(bars[i].High-bars[i].Low,
+			math.Max(math.Abs(bars[i].High-prevClose), math.Abs(bars[i].Low-prevClose)))
+	}
+	return sum / float64(period)
+}
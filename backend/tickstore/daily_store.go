@@ -1,8 +1,10 @@
 package tickstore
 
 import (
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -20,6 +22,7 @@ type DailyStore struct {
 	currentDay  string
 	todayTicks  map[string][]Tick // symbol -> today's ticks
 	maxDaysKeep int               // Number of days to keep
+	index       *tickIndex        // Per symbol-day counts and time bounds
 }
 
 // NewDailyStore creates a new daily tick store
@@ -34,6 +37,7 @@ func NewDailyStore(brokerID string, maxDaysKeep int) *DailyStore {
 
 	// Ensure base directory exists
 	os.MkdirAll(ds.basePath, 0755)
+	ds.index = loadTickIndex(filepath.Join(ds.basePath, "index.json"))
 
 	// Load today's ticks for all symbols
 	ds.loadToday()
@@ -59,6 +63,7 @@ func (ds *DailyStore) StoreTick(symbol string, tick Tick) {
 	}
 
 	ds.todayTicks[symbol] = append(ds.todayTicks[symbol], tick)
+	ds.index.add(symbol, ds.currentDay, tick.Timestamp)
 }
 
 // GetHistory returns historical ticks for a symbol across multiple days
@@ -131,6 +136,7 @@ func (ds *DailyStore) loadToday() {
 		ticks := ds.loadDayForSymbol(symbol, ds.currentDay)
 		if len(ticks) > 0 {
 			ds.todayTicks[symbol] = ticks
+			ds.index.set(symbol, ds.currentDay, summarizeDay(ticks))
 			totalLoaded += len(ticks)
 		}
 	}
@@ -142,9 +148,14 @@ func (ds *DailyStore) loadToday() {
 
 // loadDayForSymbol loads ticks for a specific symbol and day
 func (ds *DailyStore) loadDayForSymbol(symbol, date string) []Tick {
-	filePath := filepath.Join(ds.basePath, symbol, date+".json")
+	return loadDailyFile(ds.basePath, symbol, date)
+}
+
+// loadDailyFile loads a symbol's ticks for one day from under basePath
+func loadDailyFile(basePath, symbol, date string) []Tick {
+	filePath := filepath.Join(basePath, symbol, date+".json")
 
-	data, err := os.ReadFile(filePath)
+	data, err := readDailyFile(filePath)
 	if err != nil {
 		return nil
 	}
@@ -211,6 +222,8 @@ func (ds *DailyStore) persistToday() {
 		totalPersisted += len(ticks)
 	}
 
+	ds.index.save()
+
 	if totalPersisted > 0 {
 		log.Printf("[DailyStore] Persisted %d ticks across %d symbols", totalPersisted, len(ds.todayTicks))
 	}
@@ -241,6 +254,7 @@ func (ds *DailyStore) persistTodayLocked() {
 			os.Rename(tempPath, filePath)
 		}
 	}
+	ds.index.save()
 }
 
 // rotateDaily checks at midnight for day change and cleans old files
@@ -253,41 +267,28 @@ func (ds *DailyStore) rotateDaily() {
 	}
 }
 
-// cleanOldFiles removes files older than maxDaysKeep
+// cleanOldFiles removes files, archived or not, older than maxDaysKeep
 func (ds *DailyStore) cleanOldFiles() {
 	if ds.maxDaysKeep <= 0 {
 		return
 	}
 
-	cutoff := time.Now().AddDate(0, 0, -ds.maxDaysKeep)
-	cutoffStr := cutoff.Format("2006-01-02")
-
+	cutoff := time.Now().AddDate(0, 0, -ds.maxDaysKeep).Format("2006-01-02")
 	dirs, _ := os.ReadDir(ds.basePath)
 	for _, d := range dirs {
 		if !d.IsDir() {
 			continue
 		}
-
 		symbolDir := filepath.Join(ds.basePath, d.Name())
 		files, _ := os.ReadDir(symbolDir)
-
 		for _, f := range files {
-			if f.IsDir() {
+			date, ok := dailyFileDate(f.Name())
+			if !ok || date >= cutoff {
 				continue
 			}
-
-			// Extract date from filename (e.g., "2026-01-01.json")
-			name := f.Name()
-			if !strings.HasSuffix(name, ".json") {
-				continue
-			}
-
-			dateStr := strings.TrimSuffix(name, ".json")
-			if dateStr < cutoffStr {
-				filePath := filepath.Join(symbolDir, name)
-				if err := os.Remove(filePath); err == nil {
-					log.Printf("[DailyStore] Cleaned old file: %s", filePath)
-				}
+			filePath := filepath.Join(symbolDir, f.Name())
+			if err := os.Remove(filePath); err == nil {
+				log.Printf("[DailyStore] Cleaned old file: %s", filePath)
 			}
 		}
 	}
@@ -302,30 +303,83 @@ func (ds *DailyStore) GetTodayTickCount(symbol string) int {
 
 // GetAvailableDates returns available dates for a symbol
 func (ds *DailyStore) GetAvailableDates(symbol string) []string {
-	symbolDir := filepath.Join(ds.basePath, symbol)
-	files, err := os.ReadDir(symbolDir)
+	files, err := os.ReadDir(filepath.Join(ds.basePath, symbol))
 	if err != nil {
 		return nil
 	}
 
 	var dates []string
+	seen := make(map[string]bool)
 	for _, f := range files {
-		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+		date, ok := dailyFileDate(f.Name())
+		if !ok || f.IsDir() || seen[date] {
 			continue
 		}
-		date := strings.TrimSuffix(f.Name(), ".json")
+		seen[date] = true
 		dates = append(dates, date)
 	}
-
 	sort.Strings(dates)
 	return dates
 }
 
+// dailyFileDate returns the date of a daily tick file name, plain
+// (YYYY-MM-DD.json) or archived by the compressor (YYYY-MM-DD.json.gz)
+func dailyFileDate(name string) (string, bool) {
+	var date string
+	switch {
+	case strings.HasSuffix(name, ".json.gz"):
+		date = strings.TrimSuffix(name, ".json.gz")
+	case strings.HasSuffix(name, ".json"):
+		date = strings.TrimSuffix(name, ".json")
+	default:
+		return "", false
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return "", false
+	}
+	return date, true
+}
+
+// readDailyFile reads a daily file, decompressing it if archived
+func readDailyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil || !os.IsNotExist(err) {
+		return data, err
+	}
+
+	f, err := os.Open(path + ".gz")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
 // MergeHistoricalData merges ticks from an external source (e.g., imported data)
 func (ds *DailyStore) MergeHistoricalData(symbol string, ticks []Tick) error {
 	ds.mu.Lock()
 	defer ds.mu.Unlock()
+	defer ds.index.save()
+
+	return mergeDailyFiles(ds.basePath, symbol, ticks, func(date string, merged []Tick) {
+		ds.index.set(symbol, date, summarizeDay(merged))
+	})
+}
 
+// MergeDailyFiles merges ticks into symbol's daily files under basePath, for
+// backfills when the running store keeps no DailyStore
+func MergeDailyFiles(basePath, symbol string, ticks []Tick) error {
+	return mergeDailyFiles(basePath, symbol, ticks, nil)
+}
+
+// mergeDailyFiles merges ticks into the daily files they fall on, calling
+// merged with each day's result once saved
+func mergeDailyFiles(basePath, symbol string, ticks []Tick, merged func(date string, ticks []Tick)) error {
 	// Group ticks by date
 	ticksByDate := make(map[string][]Tick)
 	for _, tick := range ticks {
@@ -336,18 +390,18 @@ func (ds *DailyStore) MergeHistoricalData(symbol string, ticks []Tick) error {
 	// Merge each date
 	for date, dateTicks := range ticksByDate {
 		// Load existing
-		existing := ds.loadDayForSymbol(symbol, date)
+		existing := loadDailyFile(basePath, symbol, date)
 
 		// Merge (existing + new, sorted by timestamp)
-		merged := append(existing, dateTicks...)
-		sort.Slice(merged, func(i, j int) bool {
-			return merged[i].Timestamp.Before(merged[j].Timestamp)
+		all := append(existing, dateTicks...)
+		sort.Slice(all, func(i, j int) bool {
+			return all[i].Timestamp.Before(all[j].Timestamp)
 		})
 
 		// Remove duplicates (same timestamp)
-		deduped := make([]Tick, 0, len(merged))
+		deduped := make([]Tick, 0, len(all))
 		seen := make(map[int64]bool)
-		for _, t := range merged {
+		for _, t := range all {
 			ts := t.Timestamp.UnixNano()
 			if !seen[ts] {
 				seen[ts] = true
@@ -356,7 +410,7 @@ func (ds *DailyStore) MergeHistoricalData(symbol string, ticks []Tick) error {
 		}
 
 		// Save
-		symbolDir := filepath.Join(ds.basePath, symbol)
+		symbolDir := filepath.Join(basePath, symbol)
 		os.MkdirAll(symbolDir, 0755)
 
 		filePath := filepath.Join(symbolDir, date+".json")
@@ -365,6 +419,11 @@ func (ds *DailyStore) MergeHistoricalData(symbol string, ticks []Tick) error {
 		if err := os.WriteFile(filePath, data, 0644); err != nil {
 			return fmt.Errorf("failed to save merged data for %s/%s: %w", symbol, date, err)
 		}
+		// The merge read any archive of the day; the new file replaces it
+		os.Remove(filePath + ".gz")
+		if merged != nil {
+			merged(date, deduped)
+		}
 
 		log.Printf("[DailyStore] Merged %d ticks for %s on %s", len(deduped), symbol, date)
 	}
@@ -0,0 +1,179 @@
+package tickstore
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// DayIndex summarises one symbol-day of stored ticks
+type DayIndex struct {
+	First time.Time `json:"first"`
+	Last  time.Time `json:"last"`
+	Count int64     `json:"count"`
+}
+
+// add counts a tick at ts
+func (d *DayIndex) add(ts time.Time) {
+	if d.Count == 0 || ts.Before(d.First) {
+		d.First = ts
+	}
+	if d.Count == 0 || ts.After(d.Last) {
+		d.Last = ts
+	}
+	d.Count++
+}
+
+// summarizeDay builds the index entry of a day's ticks
+func summarizeDay(ticks []Tick) DayIndex {
+	var d DayIndex
+	for _, tick := range ticks {
+		d.add(tick.Timestamp)
+	}
+	return d
+}
+
+// SymbolIndex summarises every stored day of a symbol
+type SymbolIndex struct {
+	Symbol     string    `json:"symbol"`
+	Earliest   time.Time `json:"earliest"`
+	Latest     time.Time `json:"latest"`
+	TotalTicks int64     `json:"totalTicks"`
+	Days       int       `json:"days"`
+}
+
+// tickIndex keeps per symbol-day tick counts and time bounds so metadata
+// queries never read the tick files. It is saved next to them.
+type tickIndex struct {
+	mu    sync.Mutex
+	path  string
+	days  map[string]map[string]DayIndex // symbol -> date -> summary
+	dirty bool
+}
+
+// loadTickIndex loads the index saved at path, starting empty if there is none
+func loadTickIndex(path string) *tickIndex {
+	idx := &tickIndex{path: path, days: make(map[string]map[string]DayIndex)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return idx
+	}
+	if err := json.Unmarshal(data, &idx.days); err != nil {
+		log.Printf("[DailyStore] Ignoring unreadable tick index %s: %v", path, err)
+		idx.days = make(map[string]map[string]DayIndex)
+	}
+	return idx
+}
+
+// add counts a tick stored for symbol on date
+func (idx *tickIndex) add(symbol, date string, ts time.Time) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	days := idx.symbolDaysLocked(symbol)
+	d := days[date]
+	d.add(ts)
+	days[date] = d
+	idx.dirty = true
+}
+
+// set replaces the entry of a symbol-day, after the day's file was rewritten
+func (idx *tickIndex) set(symbol, date string, d DayIndex) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.symbolDaysLocked(symbol)[date] = d
+	idx.dirty = true
+}
+
+// symbolDaysLocked returns a symbol's day map, creating it (caller must hold lock)
+func (idx *tickIndex) symbolDaysLocked(symbol string) map[string]DayIndex {
+	days, ok := idx.days[symbol]
+	if !ok {
+		days = make(map[string]DayIndex)
+		idx.days[symbol] = days
+	}
+	return days
+}
+
+// save writes the index if it changed since the last save
+func (idx *tickIndex) save() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if !idx.dirty || idx.path == "" {
+		return
+	}
+	data, err := json.Marshal(idx.days)
+	if err != nil {
+		log.Printf("[DailyStore] Marshal error for tick index: %v", err)
+		return
+	}
+	tempPath := idx.path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		log.Printf("[DailyStore] Write error for tick index: %v", err)
+		return
+	}
+	if err := os.Rename(tempPath, idx.path); err != nil {
+		log.Printf("[DailyStore] Rename error for tick index: %v", err)
+		os.Remove(tempPath)
+		return
+	}
+	idx.dirty = false
+}
+
+// GetSymbolIndex returns a symbol's earliest and latest tick, tick count and
+// stored days from the index. Days rotated off disk are dropped and days
+// stored before the index existed are indexed once.
+func (ds *DailyStore) GetSymbolIndex(symbol string) SymbolIndex {
+	stored := make(map[string]bool)
+	for _, date := range ds.GetAvailableDates(symbol) {
+		stored[date] = true
+	}
+	ds.mu.RLock()
+	if len(ds.todayTicks[symbol]) > 0 {
+		stored[ds.currentDay] = true
+	}
+	ds.mu.RUnlock()
+
+	ds.index.mu.Lock()
+	days := ds.index.symbolDaysLocked(symbol)
+	for date := range days {
+		if !stored[date] {
+			delete(days, date)
+			ds.index.dirty = true
+		}
+	}
+	var missing []string
+	for date := range stored {
+		if _, ok := days[date]; !ok {
+			missing = append(missing, date)
+		}
+	}
+	ds.index.mu.Unlock()
+
+	for _, date := range missing {
+		ds.index.set(symbol, date, summarizeDay(ds.loadDayForSymbol(symbol, date)))
+	}
+
+	ds.index.mu.Lock()
+	defer ds.index.mu.Unlock()
+
+	summary := SymbolIndex{Symbol: symbol}
+	for _, d := range ds.index.days[symbol] {
+		if d.Count == 0 {
+			continue
+		}
+		if summary.Days == 0 || d.First.Before(summary.Earliest) {
+			summary.Earliest = d.First
+		}
+		if summary.Days == 0 || d.Last.After(summary.Latest) {
+			summary.Latest = d.Last
+		}
+		summary.TotalTicks += d.Count
+		summary.Days++
+	}
+	return summary
+}
@@ -0,0 +1,102 @@
+package tickstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestDailyStore returns a daily store over base without its background jobs
+func newTestDailyStore(base string) *DailyStore {
+	return &DailyStore{
+		basePath:   base,
+		currentDay: time.Now().Format("2006-01-02"),
+		todayTicks: make(map[string][]Tick),
+		index:      loadTickIndex(filepath.Join(base, "index.json")),
+	}
+}
+
+// TestSymbolIndex tests that the index tracks merged days and today's writes,
+// survives a reload and drops days removed from disk
+func TestSymbolIndex(t *testing.T) {
+	base := t.TempDir()
+	ds := newTestDailyStore(base)
+
+	day := time.Date(2025, 6, 2, 9, 0, 0, 0, time.Local)
+	if err := ds.MergeHistoricalData("EURUSD", []Tick{
+		{Timestamp: day}, {Timestamp: day.Add(time.Hour)}, {Timestamp: day.AddDate(0, 0, 1)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	ds.StoreTick("EURUSD", Tick{Timestamp: now})
+
+	idx := ds.GetSymbolIndex("EURUSD")
+	if idx.TotalTicks != 4 || idx.Days != 3 || !idx.Earliest.Equal(day) || !idx.Latest.Equal(now) {
+		t.Fatalf("GetSymbolIndex() = %+v, want 4 ticks over 3 days from %v to %v", idx, day, now)
+	}
+
+	// A reloaded index answers without the in-memory writes; today is gone
+	// from memory, and the rotated day from disk
+	ds.persistTodayLocked()
+	if err := os.Remove(filepath.Join(base, "EURUSD", "2025-06-03.json")); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := newTestDailyStore(base)
+	if idx := reloaded.GetSymbolIndex("EURUSD"); idx.TotalTicks != 3 || idx.Days != 2 || !idx.Earliest.Equal(day) {
+		t.Errorf("reloaded GetSymbolIndex() = %+v, want 3 ticks over 2 days", idx)
+	}
+}
+
+// TestSymbolIndexBackfillsUnindexedDays tests that days written before the
+// index existed are indexed from their file once
+func TestSymbolIndexBackfillsUnindexedDays(t *testing.T) {
+	base := t.TempDir()
+	ds := newTestDailyStore(base)
+	day := time.Date(2025, 6, 2, 9, 0, 0, 0, time.Local)
+	if err := ds.MergeHistoricalData("GBPUSD", []Tick{{Timestamp: day}, {Timestamp: day.Add(time.Minute)}}); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(filepath.Join(base, "index.json"))
+
+	if idx := newTestDailyStore(base).GetSymbolIndex("GBPUSD"); idx.TotalTicks != 2 || idx.Days != 1 || !idx.Latest.Equal(day.Add(time.Minute)) {
+		t.Errorf("GetSymbolIndex() = %+v, want 2 ticks on 1 day", idx)
+	}
+}
+
+// TestArchivedDaysStayReadable tests that a day gzipped by the compressor is
+// still listed and loaded
+func TestArchivedDaysStayReadable(t *testing.T) {
+	base := t.TempDir()
+	ds := newTestDailyStore(base)
+	if err := ds.MergeHistoricalData("EURUSD", []Tick{
+		{Symbol: "EURUSD", Bid: 1.1, Ask: 1.1002, Timestamp: time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local)},
+		{Symbol: "EURUSD", Bid: 1.1001, Ask: 1.1003, Timestamp: time.Date(2026, 3, 1, 9, 0, 1, 0, time.Local)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(base, "EURUSD", "2026-03-01.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var archive bytes.Buffer
+	zw := gzip.NewWriter(&archive)
+	zw.Write(data)
+	zw.Close()
+	if err := os.WriteFile(path+".gz", archive.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(path)
+
+	if dates := ds.GetAvailableDates("EURUSD"); len(dates) != 1 || dates[0] != "2026-03-01" {
+		t.Errorf("dates = %v, want the archived 2026-03-01", dates)
+	}
+	if ticks := ds.loadDayForSymbol("EURUSD", "2026-03-01"); len(ticks) != 2 || ticks[1].Bid != 1.1001 {
+		t.Errorf("archived ticks = %+v, want both ticks", ticks)
+	}
+}
@@ -42,6 +42,17 @@ func TimeframeSeconds(tf Timeframe) int64 {
 	}
 }
 
+// TimeframeForSeconds returns the timeframe lasting secs, and false if no
+// named timeframe has that length
+func TimeframeForSeconds(secs int64) (Timeframe, bool) {
+	for _, tf := range []Timeframe{TF_M1, TF_M5, TF_M15, TF_H1, TF_H4, TF_D1} {
+		if TimeframeSeconds(tf) == secs {
+			return tf, true
+		}
+	}
+	return "", false
+}
+
 // OHLCCache manages pre-computed OHLC bars
 type OHLCCache struct {
 	mu          sync.RWMutex
@@ -49,19 +60,27 @@ type OHLCCache struct {
 	bars        map[string]map[Timeframe][]OHLC // symbol -> timeframe -> bars
 	currentBars map[string]map[Timeframe]*OHLC  // symbol -> timeframe -> current incomplete bar
 	timeframes  []Timeframe
+	barClosed   chan struct{} // Signals the persister that a bar rolled over
+	barsClosed  int64         // Bars completed since startup, across symbols and timeframes
 }
 
 // NewOHLCCache creates a new OHLC cache
 func NewOHLCCache(timeframes []Timeframe) *OHLCCache {
+	return NewOHLCCacheAt("data/ohlc", timeframes)
+}
+
+// NewOHLCCacheAt creates an OHLC cache that persists bars under basePath
+func NewOHLCCacheAt(basePath string, timeframes []Timeframe) *OHLCCache {
 	if len(timeframes) == 0 {
 		timeframes = []Timeframe{TF_M1, TF_M5, TF_H1, TF_D1}
 	}
 
 	cache := &OHLCCache{
-		basePath:    "data/ohlc",
+		basePath:    basePath,
 		bars:        make(map[string]map[Timeframe][]OHLC),
 		currentBars: make(map[string]map[Timeframe]*OHLC),
 		timeframes:  timeframes,
+		barClosed:   make(chan struct{}, 1),
 	}
 
 	os.MkdirAll(cache.basePath, 0755)
@@ -97,9 +116,14 @@ func (c *OHLCCache) UpdateFromTick(symbol string, bid, ask float64, timestamp ti
 		currentBar := c.currentBars[symbol][tf]
 
 		if currentBar == nil || currentBar.Time != candleTime {
-			// Finalize previous bar if exists
+			// Finalize previous bar if exists and have it persisted
 			if currentBar != nil {
 				c.bars[symbol][tf] = append(c.bars[symbol][tf], *currentBar)
+				c.barsClosed++
+				select {
+				case c.barClosed <- struct{}{}:
+				default: // A persist is already pending
+				}
 			}
 
 			// Start new bar
@@ -125,7 +149,25 @@ func (c *OHLCCache) UpdateFromTick(symbol string, bid, ask float64, timestamp ti
 	}
 }
 
-// GetBars returns OHLC bars for a symbol and timeframe
+// BarsGenerated returns the number of bars completed since startup
+func (c *OHLCCache) BarsGenerated() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.barsClosed
+}
+
+// HasTimeframe reports whether tf is aggregated live by the cache
+func (c *OHLCCache) HasTimeframe(tf Timeframe) bool {
+	for _, t := range c.timeframes {
+		if t == tf {
+			return true
+		}
+	}
+	return false
+}
+
+// GetBars returns OHLC bars for a symbol and timeframe, ending with the
+// in-progress bar flagged Partial
 func (c *OHLCCache) GetBars(symbol string, tf Timeframe, limit int) []OHLC {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -135,21 +177,83 @@ func (c *OHLCCache) GetBars(symbol string, tf Timeframe, limit int) []OHLC {
 		return []OHLC{}
 	}
 
-	bars := symbolBars[tf]
-	if bars == nil {
-		bars = []OHLC{}
-	}
+	// Copy so appending the current bar never writes into the cache
+	bars := make([]OHLC, len(symbolBars[tf]), len(symbolBars[tf])+1)
+	copy(bars, symbolBars[tf])
 
 	// Include current bar if exists
 	if c.currentBars[symbol] != nil && c.currentBars[symbol][tf] != nil {
-		bars = append(bars, *c.currentBars[symbol][tf])
+		current := *c.currentBars[symbol][tf]
+		current.Partial = true
+		bars = append(bars, current)
 	}
 
-	// Apply limit
+	return lastBars(bars, limit)
+}
+
+// lastBars returns the newest limit bars; limit <= 0 returns all of them
+func lastBars(bars []OHLC, limit int) []OHLC {
 	if limit > 0 && len(bars) > limit {
-		bars = bars[len(bars)-limit:]
+		return bars[len(bars)-limit:]
+	}
+	return bars
+}
+
+// AggregateOHLC builds tfSecs bars from ticks ordered oldest first. The last
+// bar is flagged Partial if its window is still open at now.
+func AggregateOHLC(ticks []Tick, tfSecs int64, now time.Time) []OHLC {
+	if tfSecs <= 0 {
+		return []OHLC{}
 	}
 
+	bars := aggregateTicks(ticks, tfSecs)
+	if n := len(bars); n > 0 && now.Unix() < bars[n-1].Time+tfSecs {
+		bars[n-1].Partial = true
+	}
+	return bars
+}
+
+// aggregateTicks groups ticks into bars by mid price, oldest first
+func aggregateTicks(ticks []Tick, tfSecs int64) []OHLC {
+	barMap := make(map[int64]*OHLC)
+	var candleTimes []int64
+
+	for _, tick := range ticks {
+		price := (tick.Bid + tick.Ask) / 2
+		ts := tick.Timestamp.Unix()
+		candleTime := (ts / tfSecs) * tfSecs
+
+		if bar, exists := barMap[candleTime]; exists {
+			if price > bar.High {
+				bar.High = price
+			}
+			if price < bar.Low {
+				bar.Low = price
+			}
+			bar.Close = price
+			bar.Volume++
+		} else {
+			barMap[candleTime] = &OHLC{
+				Time:   candleTime,
+				Open:   price,
+				High:   price,
+				Low:    price,
+				Close:  price,
+				Volume: 1,
+			}
+			candleTimes = append(candleTimes, candleTime)
+		}
+	}
+
+	// Convert to sorted slice
+	sort.Slice(candleTimes, func(i, j int) bool {
+		return candleTimes[i] < candleTimes[j]
+	})
+
+	bars := make([]OHLC, 0, len(candleTimes))
+	for _, t := range candleTimes {
+		bars = append(bars, *barMap[t])
+	}
 	return bars
 }
 
@@ -166,12 +270,17 @@ func (c *OHLCCache) GetSymbols() []string {
 	return symbols
 }
 
-// persistPeriodically saves cache to disk every 60 seconds
+// persistPeriodically saves cache to disk whenever a bar closes, and every
+// 60 seconds so the in-progress bars survive a restart
 func (c *OHLCCache) persistPeriodically() {
 	ticker := time.NewTicker(60 * time.Second)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.barClosed:
+		}
 		c.PersistAll()
 	}
 }
@@ -326,48 +435,7 @@ func (c *OHLCCache) RebuildFromTicks(symbol string, ticks []Tick) {
 
 	// Build bars for each timeframe
 	for _, tf := range c.timeframes {
-		tfSecs := TimeframeSeconds(tf)
-		barMap := make(map[int64]*OHLC)
-		var candleTimes []int64
-
-		for _, tick := range ticks {
-			price := (tick.Bid + tick.Ask) / 2
-			ts := tick.Timestamp.Unix()
-			candleTime := (ts / tfSecs) * tfSecs
-
-			if bar, exists := barMap[candleTime]; exists {
-				if price > bar.High {
-					bar.High = price
-				}
-				if price < bar.Low {
-					bar.Low = price
-				}
-				bar.Close = price
-				bar.Volume++
-			} else {
-				barMap[candleTime] = &OHLC{
-					Time:   candleTime,
-					Open:   price,
-					High:   price,
-					Low:    price,
-					Close:  price,
-					Volume: 1,
-				}
-				candleTimes = append(candleTimes, candleTime)
-			}
-		}
-
-		// Convert to sorted slice
-		sort.Slice(candleTimes, func(i, j int) bool {
-			return candleTimes[i] < candleTimes[j]
-		})
-
-		bars := make([]OHLC, 0, len(candleTimes))
-		for _, t := range candleTimes {
-			bars = append(bars, *barMap[t])
-		}
-
-		c.bars[symbol][tf] = bars
+		c.bars[symbol][tf] = aggregateTicks(ticks, TimeframeSeconds(tf))
 	}
 
 	log.Printf("[OHLCCache] Rebuilt cache for %s from %d ticks", symbol, len(ticks))
@@ -0,0 +1,93 @@
+package tickstore
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestOHLCCacheRollover tests that each live timeframe closes its bar when the
+// window rolls over and reports the in-progress bar as partial
+func TestOHLCCacheRollover(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewOHLCCacheAt(dir, []Timeframe{TF_M1, TF_M5})
+	start := time.Unix(1700000000/300*300, 0)
+
+	cache.UpdateFromTick("EURUSD", 1.1000, 1.1002, start)
+	cache.UpdateFromTick("EURUSD", 1.1010, 1.1012, start.Add(30*time.Second))
+	cache.UpdateFromTick("EURUSD", 1.0990, 1.0992, start.Add(70*time.Second))
+
+	m1 := cache.GetBars("EURUSD", TF_M1, 0)
+	if len(m1) != 2 {
+		t.Fatalf("M1 bars = %d, want 2", len(m1))
+	}
+	if m1[0].Partial || m1[0].Open != 1.1001 || m1[0].High != 1.1011 || m1[0].Volume != 2 {
+		t.Errorf("closed M1 bar = %+v, want open 1.1001 high 1.1011 volume 2", m1[0])
+	}
+	if !m1[1].Partial {
+		t.Error("current M1 bar not flagged partial")
+	}
+
+	m5 := cache.GetBars("EURUSD", TF_M5, 0)
+	if len(m5) != 1 || !m5[0].Partial || m5[0].Volume != 3 {
+		t.Errorf("M5 bars = %+v, want one partial bar of 3 ticks", m5)
+	}
+
+	// The M1 rollover asks the persister to save the closed bar
+	select {
+	case <-cache.barClosed:
+	default:
+		t.Error("closing a bar did not signal persistence")
+	}
+	cache.PersistAll()
+	if _, err := os.Stat(filepath.Join(dir, "EURUSD", "M1.json")); err != nil {
+		t.Errorf("M1 bars not persisted: %v", err)
+	}
+}
+
+// TestGetOHLCFallsBackToTicks tests that a timeframe without live bars is
+// aggregated from the ring buffer
+func TestGetOHLCFallsBackToTicks(t *testing.T) {
+	ts := newTestStore(t)
+	start := time.Now().Truncate(2 * time.Minute).Add(-2 * time.Minute) // Last window is open
+
+	for i, price := range []float64{1.1000, 1.1050, 1.1100, 1.1150} {
+		ts.StoreTick("EURUSD", price, price+0.0002, 0.0002, "TEST", start.Add(time.Duration(i)*time.Minute))
+	}
+
+	bars := ts.GetOHLC("EURUSD", 120, 0)
+	if len(bars) != 2 {
+		t.Fatalf("2m bars = %+v, want 2", bars)
+	}
+	if bars[0].Partial || bars[0].Open != 1.1001 || bars[0].Close != 1.1051 {
+		t.Errorf("first 2m bar = %+v, want closed 1.1001 -> 1.1051", bars[0])
+	}
+	if !bars[1].Partial {
+		t.Errorf("last 2m bar = %+v, want partial", bars[1])
+	}
+
+	if got := ts.GetOHLC("EURUSD", 60, 2); len(got) != 2 || !got[1].Partial {
+		t.Errorf("M1 bars = %+v, want the last 2 from the live cache", got)
+	}
+	if got := ts.GetOHLC("GBPUSD", 120, 0); len(got) != 0 {
+		t.Errorf("bars for unknown symbol = %+v, want none", got)
+	}
+}
+
+// TestAverageTrueRange tests the true range of gapping bars and the minimum
+// bar count
+func TestAverageTrueRange(t *testing.T) {
+	bars := []OHLC{
+		{High: 1.1010, Low: 1.1000, Close: 1.1005},
+		{High: 1.1020, Low: 1.1010, Close: 1.1015}, // Gap up: 1.1020 - 1.1005
+		{High: 1.1018, Low: 1.1008, Close: 1.1010}, // Range 0.0010
+	}
+	if got := AverageTrueRange(bars, 2); math.Abs(got-0.00125) > 1e-9 {
+		t.Errorf("AverageTrueRange() = %.5f, want 0.00125", got)
+	}
+	if got := AverageTrueRange(bars, 3); got != 0 {
+		t.Errorf("AverageTrueRange() with too few bars = %v, want 0", got)
+	}
+}
@@ -30,9 +30,11 @@ type OptimizedTickStore struct {
 	// Ring buffers for bounded memory (per-symbol)
 	rings      map[string]*TickRingBuffer
 
-	// Last prices for throttling (skip unchanged quotes)
-	lastPrices map[string]float64
-	throttleMu sync.RWMutex
+	// Last stored quotes for throttling (skip unchanged quotes)
+	lastQuotes         map[string]lastQuote
+	throttleThresholds map[string]float64 // Symbol -> min change in percent
+	defaultThrottlePct float64
+	throttleMu         sync.RWMutex
 
 	// Async batch writer (for JSON backend)
 	writeQueue chan *Tick
@@ -128,6 +130,8 @@ type TickStoreConfig struct {
 	Backend          StorageBackend
 	SQLiteBasePath   string // Path for SQLite databases
 	EnableJSONLegacy bool
+	OHLCTimeframes   []Timeframe // Timeframes aggregated live as ticks arrive
+	OHLCBasePath     string      // Path for persisted OHLC bars
 }
 
 // NewOptimizedTickStore creates a high-performance tick store
@@ -149,19 +153,27 @@ func NewOptimizedTickStoreWithConfig(cfg TickStoreConfig) *OptimizedTickStore {
 	if cfg.Backend == "" {
 		cfg.Backend = BackendJSON
 	}
+	if len(cfg.OHLCTimeframes) == 0 {
+		cfg.OHLCTimeframes = []Timeframe{TF_M1, TF_M5, TF_M15, TF_H1, TF_H4, TF_D1}
+	}
+	if cfg.OHLCBasePath == "" {
+		cfg.OHLCBasePath = "data/ohlc"
+	}
 
 	ts := &OptimizedTickStore{
-		brokerID:      cfg.BrokerID,
-		maxTicks:      cfg.MaxTicksPerSymbol,
-		rings:         make(map[string]*TickRingBuffer),
-		lastPrices:    make(map[string]float64),
-		backend:       cfg.Backend,
-		useJSONLegacy: cfg.EnableJSONLegacy,
-		writeQueue:    make(chan *Tick, 10000), // Buffered async queue
-		writeBatch:    make([]Tick, 0, 1000),
-		batchSize:     500, // Flush every 500 ticks
-		ohlcCache:     NewOHLCCache([]Timeframe{TF_M1, TF_M5, TF_M15, TF_H1, TF_H4, TF_D1}),
-		stopChan:      make(chan struct{}),
+		brokerID:           cfg.BrokerID,
+		maxTicks:           cfg.MaxTicksPerSymbol,
+		rings:              make(map[string]*TickRingBuffer),
+		lastQuotes:         make(map[string]lastQuote),
+		throttleThresholds: make(map[string]float64),
+		defaultThrottlePct: DefaultThrottleThresholdPct,
+		backend:            cfg.Backend,
+		useJSONLegacy:      cfg.EnableJSONLegacy,
+		writeQueue:         make(chan *Tick, 10000), // Buffered async queue
+		writeBatch:         make([]Tick, 0, 1000),
+		batchSize:          500, // Flush every 500 ticks
+		ohlcCache:          NewOHLCCacheAt(cfg.OHLCBasePath, cfg.OHLCTimeframes),
+		stopChan:           make(chan struct{}),
 	}
 
 	// Initialize SQLite store if needed
@@ -196,27 +208,12 @@ func NewOptimizedTickStoreWithConfig(cfg TickStoreConfig) *OptimizedTickStore {
 func (ts *OptimizedTickStore) StoreTick(symbol string, bid, ask, spread float64, lp string, timestamp time.Time) {
 	atomic.AddInt64(&ts.ticksReceived, 1)
 
-	// THROTTLING: Skip if price hasn't changed significantly (< 0.00001 = 0.001%)
-	ts.throttleMu.RLock()
-	lastPrice, exists := ts.lastPrices[symbol]
-	ts.throttleMu.RUnlock()
-
-	if exists {
-		priceChange := (bid - lastPrice) / lastPrice
-		if priceChange < 0 {
-			priceChange = -priceChange
-		}
-		if priceChange < 0.00001 { // Skip if < 0.001% change
-			atomic.AddInt64(&ts.ticksThrottled, 1)
-			return
-		}
+	// THROTTLING: Skip if neither side moved by the symbol's threshold (default 0.001%)
+	if ts.throttleTick(symbol, bid, ask) {
+		atomic.AddInt64(&ts.ticksThrottled, 1)
+		return
 	}
 
-	// Update last price
-	ts.throttleMu.Lock()
-	ts.lastPrices[symbol] = bid
-	ts.throttleMu.Unlock()
-
 	tick := Tick{
 		BrokerID:  ts.brokerID,
 		Symbol:    symbol,
@@ -411,26 +408,23 @@ func (ts *OptimizedTickStore) GetHistory(symbol string, limit int) []Tick {
 	return ring.GetRecent(limit)
 }
 
-// GetOHLC returns OHLC bars
+// GetOHLC returns OHLC bars, the last one flagged Partial while its window is
+// open. Pre-aggregated timeframes are read from the OHLC cache; others are
+// aggregated on the fly from the symbol's ring buffer.
 func (ts *OptimizedTickStore) GetOHLC(symbol string, timeframeSecs int64, limit int) []OHLC {
-	var tf Timeframe
-	switch timeframeSecs {
-	case 60:
-		tf = TF_M1
-	case 300:
-		tf = TF_M5
-	case 900:
-		tf = TF_M15
-	case 3600:
-		tf = TF_H1
-	case 14400:
-		tf = TF_H4
-	case 86400:
-		tf = TF_D1
-	default:
-		tf = TF_M1
+	if tf, ok := TimeframeForSeconds(timeframeSecs); ok && ts.ohlcCache.HasTimeframe(tf) {
+		return ts.ohlcCache.GetBars(symbol, tf, limit)
+	}
+
+	ts.mu.RLock()
+	ring, ok := ts.rings[symbol]
+	ts.mu.RUnlock()
+	if !ok {
+		return []OHLC{}
 	}
-	return ts.ohlcCache.GetBars(symbol, tf, limit)
+
+	bars := AggregateOHLC(ring.GetRecent(ts.maxTicks), timeframeSecs, time.Now())
+	return lastBars(bars, limit)
 }
 
 // GetSymbols returns all symbols
@@ -457,6 +451,35 @@ func (ts *OptimizedTickStore) GetTickCount(symbol string) int {
 	return ring.Count()
 }
 
+// GetTicksInRange returns ticks for a symbol within [from, to], oldest first;
+// a limit truncates the end of the range.
+// Reads from SQLite when available, otherwise from the in-memory ring buffer.
+func (ts *OptimizedTickStore) GetTicksInRange(symbol string, from, to time.Time, limit int) ([]Tick, error) {
+	if ts.sqliteStore != nil {
+		return ts.sqliteStore.GetTicksInRange(symbol, from.UnixMilli(), to.UnixMilli(), limit)
+	}
+
+	ts.mu.RLock()
+	ring, ok := ts.rings[symbol]
+	ts.mu.RUnlock()
+
+	if !ok {
+		return nil, nil
+	}
+
+	var ticks []Tick
+	for _, tick := range ring.GetRecent(ts.maxTicks) {
+		if tick.Timestamp.Before(from) || tick.Timestamp.After(to) {
+			continue
+		}
+		ticks = append(ticks, tick)
+		if limit > 0 && len(ticks) >= limit {
+			break
+		}
+	}
+	return ticks, nil
+}
+
 // Stop gracefully stops the store
 func (ts *OptimizedTickStore) Stop() {
 	log.Printf("[OptimizedTickStore] Stopping...")
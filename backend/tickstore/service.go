@@ -37,6 +37,8 @@ type OHLC struct {
 	Low    float64 `json:"low"`
 	Close  float64 `json:"close"`
 	Volume int     `json:"volume"`
+	// Partial marks the in-progress bar whose window has not closed yet
+	Partial bool `json:"partial,omitempty"`
 }
 
 // TickStore provides unified tick storage with file persistence
@@ -125,28 +127,18 @@ func (ts *TickStore) GetHistory(symbol string, limit int) []Tick {
 	return ts.dailyStore.GetHistory(symbol, limit, 7) // Look back 7 days
 }
 
-// GetOHLC retrieves OHLC bars for a symbol and timeframe
+// GetOHLC retrieves OHLC bars for a symbol and timeframe. Pre-aggregated
+// timeframes come from the OHLC cache; any other timeframe is built from the
+// ticks held in memory.
 func (ts *TickStore) GetOHLC(symbol string, timeframeSecs int64, limit int) []OHLC {
-	// Map timeframe seconds to enum
-	var tf Timeframe
-	switch timeframeSecs {
-	case 60:
-		tf = TF_M1
-	case 300:
-		tf = TF_M5
-	case 900:
-		tf = TF_M15
-	case 3600:
-		tf = TF_H1
-	case 14400:
-		tf = TF_H4
-	case 86400:
-		tf = TF_D1
-	default:
-		tf = TF_M1
-	}
-
-	return ts.ohlcCache.GetBars(symbol, tf, limit)
+	if tf, ok := TimeframeForSeconds(timeframeSecs); ok && ts.ohlcCache.HasTimeframe(tf) {
+		return ts.ohlcCache.GetBars(symbol, tf, limit)
+	}
+
+	ts.mu.RLock()
+	bars := AggregateOHLC(ts.ticks[symbol], timeframeSecs, time.Now())
+	ts.mu.RUnlock()
+	return lastBars(bars, limit)
 }
 
 // GetSymbols returns all symbols with stored ticks
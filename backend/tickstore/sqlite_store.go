@@ -519,7 +519,8 @@ func (s *SQLiteStore) GetRecentTicks(symbol string, limit int) ([]Tick, error) {
 	return ticks, rows.Err()
 }
 
-// GetTicksInRange retrieves ticks for a symbol within a time range
+// GetTicksInRange retrieves ticks for a symbol within a time range, oldest
+// first, so a limit keeps the start of the range
 func (s *SQLiteStore) GetTicksInRange(symbol string, startTime, endTime int64, limit int) ([]Tick, error) {
 	s.mu.RLock()
 	db := s.db
@@ -535,7 +536,7 @@ func (s *SQLiteStore) GetTicksInRange(symbol string, startTime, endTime int64, l
 		WHERE symbol = ?
 		  AND timestamp >= ?
 		  AND timestamp <= ?
-		ORDER BY timestamp DESC
+		ORDER BY timestamp ASC
 		LIMIT ?
 	`, symbol, startTime, endTime, limit)
 	if err != nil {
@@ -547,12 +548,14 @@ func (s *SQLiteStore) GetTicksInRange(symbol string, startTime, endTime int64, l
 	for rows.Next() {
 		var tick Tick
 		var lp sql.NullString
+		var timestampMs int64
 
-		err := rows.Scan(&tick.Timestamp, &tick.Bid, &tick.Ask, &tick.Spread, &lp)
+		err := rows.Scan(&timestampMs, &tick.Bid, &tick.Ask, &tick.Spread, &lp)
 		if err != nil {
 			return nil, err
 		}
 
+		tick.Timestamp = time.UnixMilli(timestampMs)
 		tick.Symbol = symbol
 		if lp.Valid {
 			tick.LP = lp.String
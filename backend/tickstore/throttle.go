@@ -0,0 +1,103 @@
+package tickstore
+
+import "math"
+
+// DefaultThrottleThresholdPct is the smallest price change, in percent, that a
+// tick must show to be stored when no per-symbol threshold is set
+const DefaultThrottleThresholdPct = 0.001
+
+// lastQuote is the last stored bid/ask of a symbol, the throttle's reference
+type lastQuote struct {
+	bid float64
+	ask float64
+}
+
+// SetThrottleThreshold sets the smallest change in percent that a tick of
+// symbol must show against the last stored tick. 0 stores every tick.
+func (ts *OptimizedTickStore) SetThrottleThreshold(symbol string, minChangePct float64) {
+	ts.throttleMu.Lock()
+	defer ts.throttleMu.Unlock()
+	ts.throttleThresholds[symbol] = minChangePct
+}
+
+// ClearThrottleThreshold returns symbol to the default threshold
+func (ts *OptimizedTickStore) ClearThrottleThreshold(symbol string) {
+	ts.throttleMu.Lock()
+	defer ts.throttleMu.Unlock()
+	delete(ts.throttleThresholds, symbol)
+}
+
+// SetDefaultThrottleThreshold sets the threshold of symbols without their own
+func (ts *OptimizedTickStore) SetDefaultThrottleThreshold(minChangePct float64) {
+	ts.throttleMu.Lock()
+	defer ts.throttleMu.Unlock()
+	ts.defaultThrottlePct = minChangePct
+}
+
+// ThrottleThreshold returns the threshold in percent applied to symbol
+func (ts *OptimizedTickStore) ThrottleThreshold(symbol string) float64 {
+	ts.throttleMu.RLock()
+	defer ts.throttleMu.RUnlock()
+	return ts.throttleThresholdUnlocked(symbol)
+}
+
+// SymbolThrottleThreshold returns symbol's own threshold, if one is set
+func (ts *OptimizedTickStore) SymbolThrottleThreshold(symbol string) (float64, bool) {
+	ts.throttleMu.RLock()
+	defer ts.throttleMu.RUnlock()
+	pct, ok := ts.throttleThresholds[symbol]
+	return pct, ok
+}
+
+// ThrottleThresholds returns the default threshold and the per-symbol overrides
+func (ts *OptimizedTickStore) ThrottleThresholds() (float64, map[string]float64) {
+	ts.throttleMu.RLock()
+	defer ts.throttleMu.RUnlock()
+
+	overrides := make(map[string]float64, len(ts.throttleThresholds))
+	for symbol, pct := range ts.throttleThresholds {
+		overrides[symbol] = pct
+	}
+	return ts.defaultThrottlePct, overrides
+}
+
+// throttleThresholdUnlocked returns symbol's threshold (caller must hold throttleMu)
+func (ts *OptimizedTickStore) throttleThresholdUnlocked(symbol string) float64 {
+	if pct, ok := ts.throttleThresholds[symbol]; ok {
+		return pct
+	}
+	return ts.defaultThrottlePct
+}
+
+// QuoteMoved reports whether bid/ask differ from the last quote by at least
+// minChangePct percent on either side, or in spread relative to the mid, so a
+// spread that widens around an unchanged mid is not throttled
+func QuoteMoved(lastBid, lastAsk, bid, ask, minChangePct float64) bool {
+	threshold := minChangePct / 100
+	relative := func(change, base float64) float64 {
+		if base == 0 {
+			return math.Inf(1)
+		}
+		return math.Abs(change / base)
+	}
+
+	mid := (lastBid + lastAsk) / 2
+	return relative(bid-lastBid, lastBid) >= threshold ||
+		relative(ask-lastAsk, lastAsk) >= threshold ||
+		relative((ask-bid)-(lastAsk-lastBid), mid) >= threshold
+}
+
+// throttleTick reports whether a tick should be skipped because neither side
+// moved enough since the last stored tick. The first tick of a symbol always
+// passes; a passing tick becomes the new reference.
+func (ts *OptimizedTickStore) throttleTick(symbol string, bid, ask float64) bool {
+	ts.throttleMu.Lock()
+	defer ts.throttleMu.Unlock()
+
+	last, exists := ts.lastQuotes[symbol]
+	if exists && !QuoteMoved(last.bid, last.ask, bid, ask, ts.throttleThresholdUnlocked(symbol)) {
+		return true
+	}
+	ts.lastQuotes[symbol] = lastQuote{bid: bid, ask: ask}
+	return false
+}
@@ -0,0 +1,67 @@
+package tickstore
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestStore returns a store that keeps ticks in memory and OHLC bars under a temp dir
+func newTestStore(t *testing.T) *OptimizedTickStore {
+	t.Helper()
+	return NewOptimizedTickStoreWithConfig(TickStoreConfig{
+		BrokerID:          "test",
+		MaxTicksPerSymbol: 100,
+		OHLCBasePath:      t.TempDir(),
+	})
+}
+
+// TestThrottleFirstTickAndDefault tests that the first tick always passes and
+// later ticks must move by the default threshold
+func TestThrottleFirstTickAndDefault(t *testing.T) {
+	ts := newTestStore(t)
+	now := time.Now()
+
+	ts.StoreTick("EURUSD", 1.10000, 1.10010, 0.0001, "TEST", now)
+	ts.StoreTick("EURUSD", 1.10000, 1.10010, 0.0001, "TEST", now) // Unchanged
+	ts.StoreTick("EURUSD", 1.10020, 1.10030, 0.0001, "TEST", now) // ~0.018%
+	if got := ts.GetTickCount("EURUSD"); got != 2 {
+		t.Errorf("stored ticks = %d, want 2", got)
+	}
+}
+
+// TestThrottlePerSymbolThreshold tests that an override applies only to its symbol
+func TestThrottlePerSymbolThreshold(t *testing.T) {
+	ts := newTestStore(t)
+	ts.SetThrottleThreshold("XAUUSD", 0.05)
+	now := time.Now()
+
+	// A 0.02% move clears the default but not the 0.05% gold threshold
+	for _, tick := range [][2]float64{{2000.00, 2000.40}, {2000.40, 2000.80}, {2002.00, 2002.40}} {
+		ts.StoreTick("XAUUSD", tick[0], tick[1], 0.40, "TEST", now)
+	}
+	if got := ts.GetTickCount("XAUUSD"); got != 2 {
+		t.Errorf("XAUUSD stored ticks = %d, want 2", got)
+	}
+	if got := ts.ThrottleThreshold("EURUSD"); got != DefaultThrottleThresholdPct {
+		t.Errorf("EURUSD threshold = %v, want default %v", got, DefaultThrottleThresholdPct)
+	}
+
+	ts.ClearThrottleThreshold("XAUUSD")
+	if got := ts.ThrottleThreshold("XAUUSD"); got != DefaultThrottleThresholdPct {
+		t.Errorf("cleared threshold = %v, want default %v", got, DefaultThrottleThresholdPct)
+	}
+}
+
+// TestThrottleSpreadChangePasses tests that a spread widening around an
+// unchanged mid is not throttled even when each side moves little
+func TestThrottleSpreadChangePasses(t *testing.T) {
+	if !QuoteMoved(1.10000, 1.10010, 1.09999, 1.10011, 0.001) {
+		t.Error("QuoteMoved() = false for a doubled spread")
+	}
+	if QuoteMoved(1.10000, 1.10010, 1.10000, 1.10010, 0.001) {
+		t.Error("QuoteMoved() = true for an unchanged quote")
+	}
+	if !QuoteMoved(1.10000, 1.10010, 1.10000, 1.10010, 0) {
+		t.Error("QuoteMoved() = false with a zero threshold")
+	}
+}
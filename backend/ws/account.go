@@ -0,0 +1,120 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/epic1st/rtx/backend/auth"
+	"github.com/epic1st/rtx/backend/internal/core"
+	"github.com/epic1st/rtx/backend/logging"
+	"github.com/gorilla/websocket"
+)
+
+// AccountMessage is a frame on /ws/account: the account snapshot on connect,
+// then an update whenever balance, margin, positions or equity change
+type AccountMessage struct {
+	Type string `json:"type"` // Always "account_update"
+	core.AccountUpdate
+}
+
+// ServeAccountWs handles /ws/account. The connection is bound to the token's
+// trading account (admin tokens pick one with ?accountId=) and receives that
+// account's updates from the P/L engine instead of market data.
+func ServeAccountWs(hub *Hub, pnl *core.PnLEngine, w http.ResponseWriter, r *http.Request) {
+	claims, err := extractClaims(hub, r)
+	if errors.Is(err, auth.ErrTokenExpired) {
+		logger.Info("Expired token on account stream", logging.String("remote", r.RemoteAddr))
+		rejectExpiredToken(w, r)
+		return
+	}
+	if err != nil {
+		logger.Warn("Account stream authentication failed", logging.String("remote", r.RemoteAddr), logging.Err(err))
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	accountRef := claims.UserID
+	if claims.Role == auth.RoleAdmin {
+		accountRef = r.URL.Query().Get("accountId")
+	}
+	accountID, err := strconv.ParseInt(accountRef, 10, 64)
+	if err != nil || accountID <= 0 {
+		http.Error(w, "No trading account for token", http.StatusBadRequest)
+		return
+	}
+	if hub.bbookEngine != nil {
+		if _, ok := hub.bbookEngine.GetAccount(accountID); !ok {
+			http.Error(w, "Account not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Warn("Account stream upgrade failed", logging.String("remote", r.RemoteAddr), logging.Err(err))
+		return
+	}
+	logger.Info("Account stream opened", logging.AccountID(accountRef), logging.UserID(claims.UserID))
+	hub.serveAccount(conn, pnl, accountID)
+}
+
+// serveAccount streams one account's updates to an upgraded connection until
+// either side closes it
+func (h *Hub) serveAccount(conn *websocket.Conn, pnl *core.PnLEngine, accountID int64) {
+	updates := make(chan core.AccountUpdate, 16)
+	pnl.Subscribe(accountID, updates)
+	done := make(chan struct{})
+	userID := strconv.FormatInt(accountID, 10)
+
+	if !h.trackPump() {
+		pnl.Unsubscribe(accountID, updates)
+		sendGoingAway(conn)
+		conn.Close()
+		return
+	}
+	pings, stopPings := h.startHeartbeat(conn)
+
+	// Write pump
+	go func() {
+		defer h.pumps.Done()
+		defer conn.Close()
+		defer stopPings()
+		defer pnl.Unsubscribe(accountID, updates)
+		for {
+			select {
+			case <-done:
+				return
+			case <-h.quit:
+				sendGoingAway(conn)
+				return
+			case update := <-updates:
+				data, err := json.Marshal(AccountMessage{Type: "account_update", AccountUpdate: update})
+				if err != nil {
+					continue
+				}
+				if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+					logger.Warn("Account stream write error", logging.AccountID(userID), logging.Err(err))
+					return
+				}
+			case <-pings:
+				if err := sendPing(conn); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	// Read pump: clients send nothing, reading only detects close and pongs
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				h.noteReadError(userID, err)
+				logger.Info("Account stream closed", logging.AccountID(userID))
+				return
+			}
+		}
+	}()
+}
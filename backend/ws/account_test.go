@@ -0,0 +1,72 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/auth"
+	"github.com/epic1st/rtx/backend/internal/core"
+	"github.com/gorilla/websocket"
+)
+
+// TestAccountWsStreamsSnapshotThenUpdates tests that /ws/account sends the
+// token's account snapshot and then its position changes, never ticks
+func TestAccountWsStreamsSnapshotThenUpdates(t *testing.T) {
+	const secret = "test-jwt-secret-for-testing-only"
+	engine := core.NewEngine()
+	engine.SetPriceCallback(func(symbol string) (float64, float64, bool) {
+		return 1.1000, 1.1002, symbol == "EURUSD"
+	})
+	account := engine.CreateAccount("user1", "trader", "password", true)
+	account.Balance = 10000
+	pnl := core.NewPnLEngine(engine)
+	defer pnl.Stop()
+
+	hub := NewHub()
+	hub.SetAuthService(auth.NewService(engine, "", secret))
+	hub.SetBBookEngine(engine)
+	go hub.Run()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ServeAccountWs(hub, pnl, w, r)
+	}))
+	defer server.Close()
+
+	token, _ := auth.GenerateJWTWithSecret(&auth.User{ID: strconv.FormatInt(account.ID, 10), Role: auth.RoleTrader}, []byte(secret))
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"?token="+token, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+
+	read := func() AccountMessage {
+		t.Helper()
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("ReadMessage() error = %v", err)
+		}
+		var msg AccountMessage
+		if err := json.Unmarshal(data, &msg); err != nil || msg.Type != "account_update" {
+			t.Fatalf("frame = %s, want an account_update", data)
+		}
+		return msg
+	}
+
+	if msg := read(); msg.Reason != core.AccountReasonSnapshot || msg.AccountID != account.ID {
+		t.Fatalf("first frame = %+v, want account %d snapshot", msg, account.ID)
+	}
+
+	hub.BroadcastTick(quote("EURUSD", 1.1000, 1.1002))
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0); err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	if msg := read(); msg.Reason != core.AccountReasonPositionOpened || len(msg.Positions) != 1 {
+		t.Errorf("frame after order = %+v, want the opened position", msg)
+	}
+}
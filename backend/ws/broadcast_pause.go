@@ -0,0 +1,111 @@
+package ws
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+// FeedNotice tells clients that price delivery was paused or resumed
+type FeedNotice struct {
+	Type      string `json:"type"` // feed_paused or feed_resumed
+	Reason    string `json:"reason,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// BroadcastStatus describes whether prices are currently delivered to clients
+type BroadcastStatus struct {
+	Paused   bool       `json:"paused"`
+	Reason   string     `json:"reason,omitempty"`
+	PausedAt *time.Time `json:"pausedAt,omitempty"`
+}
+
+// PauseBroadcast stops delivering ticks to clients. Ticks keep flowing into the
+// tick store, the B-Book engine and the latest price cache while paused.
+// Returns false if the broadcast was already paused.
+func (h *Hub) PauseBroadcast(reason string) bool {
+	h.mu.Lock()
+	if h.broadcastPaused {
+		h.mu.Unlock()
+		return false
+	}
+	h.broadcastPaused = true
+	h.pauseReason = reason
+	h.pausedAt = time.Now()
+	h.mu.Unlock()
+
+	logger.Warn("Market data broadcast paused", logging.String("reason", reason))
+	h.sendFeedNotice("feed_paused", reason)
+	return true
+}
+
+// ResumeBroadcast restarts tick delivery and sends clients the latest price of
+// every enabled symbol so they catch up on what they missed.
+// Returns false if the broadcast was not paused.
+func (h *Hub) ResumeBroadcast() bool {
+	h.mu.Lock()
+	if !h.broadcastPaused {
+		h.mu.Unlock()
+		return false
+	}
+	pausedFor := time.Since(h.pausedAt)
+	h.broadcastPaused = false
+	h.pauseReason = ""
+	h.pausedAt = time.Time{}
+
+	var ticks []*MarketTick
+	for _, tick := range h.latestPrices {
+		if !h.disabledSymbols[tick.Symbol] {
+			ticks = append(ticks, tick)
+		}
+	}
+	h.mu.Unlock()
+
+	var latest []outboundMessage
+	for _, tick := range ticks {
+		if message, err := h.tickMessage(tick); err == nil {
+			latest = append(latest, message)
+		}
+	}
+
+	// Throttling compares against pre-pause prices, start fresh
+	h.throttleMu.Lock()
+	h.lastBroadcast = make(map[string]*MarketTick)
+	h.throttleMu.Unlock()
+
+	logger.Info("Market data broadcast resumed", logging.Duration(float64(pausedFor.Milliseconds())))
+	h.sendFeedNotice("feed_resumed", "")
+	for _, message := range latest {
+		h.enqueue(message)
+	}
+	return true
+}
+
+// GetBroadcastStatus returns the current broadcast pause state
+func (h *Hub) GetBroadcastStatus() BroadcastStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	status := BroadcastStatus{Paused: h.broadcastPaused, Reason: h.pauseReason}
+	if h.broadcastPaused {
+		pausedAt := h.pausedAt
+		status.PausedAt = &pausedAt
+	}
+	return status
+}
+
+// IsBroadcastPaused reports whether tick delivery to clients is paused
+func (h *Hub) IsBroadcastPaused() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.broadcastPaused
+}
+
+func (h *Hub) sendFeedNotice(noticeType, reason string) {
+	data, err := json.Marshal(FeedNotice{Type: noticeType, Reason: reason, Timestamp: time.Now().Unix()})
+	if err != nil {
+		return
+	}
+	h.BroadcastMessage(data)
+}
@@ -0,0 +1,84 @@
+package ws
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// drainMessageTypes collects the type field of every message queued for a client
+func drainMessageTypes(t *testing.T, client *Client) []string {
+	t.Helper()
+	time.Sleep(100 * time.Millisecond)
+
+	var types []string
+	for {
+		select {
+		case data := <-client.send:
+			var msg struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Fatalf("client received invalid JSON: %s", data)
+			}
+			types = append(types, msg.Type)
+		default:
+			return types
+		}
+	}
+}
+
+// TestPausedBroadcastStillRecordsTicks verifies that no ticks reach clients while the
+// broadcast is paused but the tick store and quote cache keep updating
+func TestPausedBroadcastStillRecordsTicks(t *testing.T) {
+	hub := NewHub()
+	mockStore := &MockTickStore{}
+	hub.SetTickStore(mockStore)
+	go hub.Run()
+
+	client := &Client{send: make(chan []byte, 64), symbols: make(map[string]bool)}
+	hub.register <- client
+
+	if !hub.PauseBroadcast("maintenance") {
+		t.Fatal("PauseBroadcast() = false, want true")
+	}
+	if hub.PauseBroadcast("again") {
+		t.Error("PauseBroadcast() on a paused hub = true, want false")
+	}
+
+	for i := 0; i < 5; i++ {
+		hub.BroadcastTick(quote("EURUSD", 1.1000+float64(i)*0.0010, 1.1002+float64(i)*0.0010))
+	}
+
+	types := drainMessageTypes(t, client)
+	if len(types) != 1 || types[0] != "feed_paused" {
+		t.Fatalf("client messages while paused = %v, want [feed_paused]", types)
+	}
+	if stored := atomic.LoadInt64(&mockStore.storedTicks); stored != 5 {
+		t.Errorf("stored ticks while paused = %d, want 5", stored)
+	}
+	if tick := hub.GetLatestPrice("EURUSD"); tick == nil || tick.Bid != 1.1040 {
+		t.Errorf("latest price while paused = %+v, want bid 1.10400", tick)
+	}
+
+	// A client connecting during the pause gets the notice instead of prices
+	late := &Client{send: make(chan []byte, 64), symbols: make(map[string]bool)}
+	hub.register <- late
+	if types := drainMessageTypes(t, late); len(types) != 1 || types[0] != "feed_paused" {
+		t.Errorf("late client messages while paused = %v, want [feed_paused]", types)
+	}
+
+	if !hub.ResumeBroadcast() {
+		t.Fatal("ResumeBroadcast() = false, want true")
+	}
+	types = drainMessageTypes(t, client)
+	if len(types) != 2 || types[0] != "feed_resumed" || types[1] != "tick" {
+		t.Fatalf("client messages after resume = %v, want [feed_resumed tick]", types)
+	}
+
+	hub.BroadcastTick(quote("EURUSD", 1.1100, 1.1102))
+	if types := drainMessageTypes(t, client); len(types) != 1 || types[0] != "tick" {
+		t.Errorf("client messages for live tick = %v, want [tick]", types)
+	}
+}
@@ -0,0 +1,115 @@
+package ws
+
+import (
+	"time"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+// FeedHealthStatus describes whether any market data source is delivering quotes
+type FeedHealthStatus struct {
+	Healthy    bool       `json:"healthy"`
+	LastTickAt *time.Time `json:"lastTickAt,omitempty"`
+	SilentFor  float64    `json:"silentForSeconds"`
+	Threshold  float64    `json:"thresholdSeconds"` // 0 = gate disabled
+	OutageFrom *time.Time `json:"outageFrom,omitempty"`
+}
+
+// SetFeedOutageThreshold sets how long the hub may go without a live tick from
+// any source before the feed counts as down. 0 disables the gate.
+func (h *Hub) SetFeedOutageThreshold(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.feedOutageThreshold = d
+}
+
+// SetFeedHealthCallback sets the function notified when the feed goes down or
+// comes back. silentFor is how long no tick had arrived.
+func (h *Hub) SetFeedHealthCallback(fn func(healthy bool, silentFor time.Duration)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.feedHealthCallback = fn
+}
+
+// IsFeedHealthy reports whether a live tick has arrived from any source within
+// the outage threshold. Always true when the gate is disabled.
+func (h *Hub) IsFeedHealthy() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.feedHealthyUnlocked(time.Now())
+}
+
+// GetFeedHealth returns the current feed health
+func (h *Hub) GetFeedHealth() FeedHealthStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	now := time.Now()
+	status := FeedHealthStatus{
+		Healthy:   h.feedHealthyUnlocked(now),
+		SilentFor: now.Sub(h.lastLiveTick).Seconds(),
+		Threshold: h.feedOutageThreshold.Seconds(),
+	}
+	if h.lastTickSeen {
+		last := h.lastLiveTick
+		status.LastTickAt = &last
+	}
+	if !h.feedOutageFrom.IsZero() {
+		from := h.feedOutageFrom
+		status.OutageFrom = &from
+	}
+	return status
+}
+
+// feedHealthyUnlocked evaluates the gate at now (caller must hold lock)
+func (h *Hub) feedHealthyUnlocked(now time.Time) bool {
+	return h.feedOutageThreshold <= 0 || now.Sub(h.lastLiveTick) <= h.feedOutageThreshold
+}
+
+// recordLiveTickUnlocked marks that a live tick arrived, ending any outage.
+// Returns the callback to notify of the recovery, if any (caller must hold lock).
+func (h *Hub) recordLiveTickUnlocked(now time.Time) func() {
+	silentFor := now.Sub(h.lastLiveTick)
+	h.lastLiveTick = now
+	h.lastTickSeen = true
+
+	if h.feedOutageFrom.IsZero() {
+		return nil
+	}
+	h.feedOutageFrom = time.Time{}
+	logger.Info("Market data resumed", logging.Duration(float64(silentFor.Milliseconds())))
+
+	callback := h.feedHealthCallback
+	if callback == nil {
+		return nil
+	}
+	return func() { callback(true, silentFor) }
+}
+
+// checkFeedHealth raises an outage once the feed has been silent past the threshold
+func (h *Hub) checkFeedHealth(now time.Time) {
+	h.mu.Lock()
+	if h.feedHealthyUnlocked(now) || !h.feedOutageFrom.IsZero() {
+		h.mu.Unlock()
+		return
+	}
+	h.feedOutageFrom = now
+	silentFor := now.Sub(h.lastLiveTick)
+	callback := h.feedHealthCallback
+	h.mu.Unlock()
+
+	logger.Error("No market data from any source, rejecting market orders", nil, logging.Duration(float64(silentFor.Milliseconds())))
+	if callback != nil {
+		callback(false, silentFor)
+	}
+}
+
+// runFeedHealthCheck checks for a total feed outage every second
+func (h *Hub) runFeedHealthCheck() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		h.checkFeedHealth(now)
+	}
+}
@@ -0,0 +1,76 @@
+package ws
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+// TestFeedOutageBlocksMarketOrders tests that market orders are rejected with
+// NO_MARKET_DATA during a total feed outage and accepted again once a fresh
+// tick arrives
+func TestFeedOutageBlocksMarketOrders(t *testing.T) {
+	engine := core.NewEngine()
+	account := engine.CreateAccount("user1", "trader", "password", true)
+	account.Balance = 10000
+
+	hub := NewHub()
+	wireEngine(engine, hub)
+	engine.SetFeedHealthCallback(hub.IsFeedHealthy)
+	hub.SetFeedOutageThreshold(50 * time.Millisecond)
+
+	var events []bool
+	hub.SetFeedHealthCallback(func(healthy bool, silentFor time.Duration) {
+		events = append(events, healthy)
+	})
+
+	hub.BroadcastTick(quote("EURUSD", 1.1000, 1.1002))
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0); err != nil {
+		t.Fatalf("ExecuteMarketOrder() with a live feed error = %v", err)
+	}
+
+	// Every source goes quiet past the threshold
+	time.Sleep(80 * time.Millisecond)
+	hub.checkFeedHealth(time.Now())
+	if hub.IsFeedHealthy() {
+		t.Fatal("feed still healthy after outage threshold")
+	}
+	_, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0)
+	if !errors.Is(err, core.ErrNoMarketData) {
+		t.Fatalf("ExecuteMarketOrder() during outage error = %v, want ErrNoMarketData", err)
+	}
+	if status := hub.GetFeedHealth(); status.Healthy || status.OutageFrom == nil {
+		t.Errorf("GetFeedHealth() = %+v, want unhealthy with outage start", status)
+	}
+
+	// The outage is reported once, not on every check
+	hub.checkFeedHealth(time.Now())
+	if len(events) != 1 || events[0] {
+		t.Fatalf("health events = %v, want [false]", events)
+	}
+
+	// A fresh tick on any symbol ends the outage
+	hub.BroadcastTick(quote("GBPUSD", 1.2500, 1.2502))
+	if !hub.IsFeedHealthy() {
+		t.Fatal("feed not healthy after fresh tick")
+	}
+	if len(events) != 2 || !events[1] {
+		t.Errorf("health events = %v, want [false true]", events)
+	}
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 0.1, 0, 0); err != nil {
+		t.Errorf("ExecuteMarketOrder() after recovery error = %v", err)
+	}
+}
+
+// TestFeedOutageGateDisabled tests that a zero threshold never blocks orders
+func TestFeedOutageGateDisabled(t *testing.T) {
+	hub := NewHub()
+	hub.SetFeedOutageThreshold(0)
+
+	hub.checkFeedHealth(time.Now().Add(time.Hour))
+	if !hub.IsFeedHealthy() {
+		t.Error("disabled gate reported an outage")
+	}
+}
@@ -0,0 +1,108 @@
+package ws
+
+import (
+	"errors"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+const (
+	// DefaultPingInterval is how often each connection is sent a ping frame
+	DefaultPingInterval = 20 * time.Second
+	// DefaultPongTimeout is how long after a ping a pong must arrive
+	DefaultPongTimeout = 10 * time.Second
+
+	// pingWriteWait bounds how long writing a ping may block
+	pingWriteWait = 5 * time.Second
+)
+
+// HubStats reports connection and tick counters for monitoring
+type HubStats struct {
+	ClientsConnected int   `json:"clients_connected"`
+	ClientsEvicted   int64 `json:"clients_evicted"` // Dropped for not answering pings
+	TicksReceived    int64 `json:"ticks_received"`
+	TicksBroadcast   int64 `json:"ticks_broadcast"`
+	TicksThrottled   int64 `json:"ticks_throttled"`
+	TicksDropped     int64 `json:"ticks_dropped"` // Broadcast buffer full
+	// Mean time from a tick arriving to it being queued for clients
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+}
+
+// SetPingInterval sets how often connections are pinged and how long they have
+// to answer before being evicted. An interval of 0 disables heartbeats.
+// Applies to connections opened afterwards.
+func (h *Hub) SetPingInterval(interval, pongTimeout time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pingInterval = interval
+	h.pongTimeout = pongTimeout
+}
+
+// heartbeat returns the ping interval and pong timeout for a new connection
+func (h *Hub) heartbeat() (interval, pongTimeout time.Duration) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.pingInterval, h.pongTimeout
+}
+
+// ClientCount returns the number of registered WebSocket connections
+func (h *Hub) ClientCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// GetStats returns the hub's connection and tick counters
+func (h *Hub) GetStats() HubStats {
+	stats := HubStats{
+		ClientsConnected: h.ClientCount(),
+		ClientsEvicted:   atomic.LoadInt64(&h.clientsEvicted),
+		TicksReceived:    atomic.LoadInt64(&h.ticksReceived),
+		TicksBroadcast:   atomic.LoadInt64(&h.ticksBroadcast),
+		TicksThrottled:   atomic.LoadInt64(&h.ticksThrottled),
+		TicksDropped:     atomic.LoadInt64(&h.ticksDropped),
+	}
+	if stats.TicksBroadcast > 0 {
+		stats.AvgLatencyMs = float64(atomic.LoadInt64(&h.tickLatencyNs)) / float64(stats.TicksBroadcast) / 1e6
+	}
+	return stats
+}
+
+// startHeartbeat arms the connection's read deadline and extends it on every
+// pong, so a client that stops answering pings fails its next read. Returns
+// the ping ticker channel for the write pump, nil when heartbeats are disabled.
+func (h *Hub) startHeartbeat(conn *websocket.Conn) (pings <-chan time.Time, stop func()) {
+	interval, pongTimeout := h.heartbeat()
+	if interval <= 0 {
+		return nil, func() {}
+	}
+
+	deadline := interval + pongTimeout
+	conn.SetReadDeadline(time.Now().Add(deadline))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(deadline))
+	})
+
+	ticker := time.NewTicker(interval)
+	return ticker.C, ticker.Stop
+}
+
+// sendPing writes a ping control frame
+func sendPing(conn *websocket.Conn) error {
+	return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(pingWriteWait))
+}
+
+// noteReadError counts and logs a connection evicted because its read
+// deadline passed without a pong
+func (h *Hub) noteReadError(userID string, err error) {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		atomic.AddInt64(&h.clientsEvicted, 1)
+		logger.Warn("Evicting client: no pong before the heartbeat deadline", logging.UserID(userID))
+	}
+}
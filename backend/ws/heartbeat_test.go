@@ -0,0 +1,65 @@
+package ws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// TestHeartbeatEvictsSilentClient tests that a client answering pings stays
+// connected while one that never reads, and so never pongs, is evicted
+func TestHeartbeatEvictsSilentClient(t *testing.T) {
+	hub := NewHub()
+	hub.SetPingInterval(50*time.Millisecond, 50*time.Millisecond)
+	go hub.Run()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Upgrade() error = %v", err)
+			return
+		}
+		hub.serveClient(conn, r.URL.Query().Get("user"), "")
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	alive, _, err := websocket.DefaultDialer.Dial(url+"?user=alive", nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer alive.Close()
+	go func() {
+		// Reading lets the default ping handler answer with a pong
+		for {
+			if _, _, err := alive.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	silent, _, err := websocket.DefaultDialer.Dial(url+"?user=silent", nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer silent.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && atomic.LoadInt64(&hub.clientsEvicted) == 0 {
+		time.Sleep(20 * time.Millisecond)
+	}
+	time.Sleep(200 * time.Millisecond) // Several more ping rounds for the live client
+
+	stats := hub.GetStats()
+	if stats.ClientsEvicted != 1 {
+		t.Errorf("evicted clients = %d, want 1", stats.ClientsEvicted)
+	}
+	if stats.ClientsConnected != 1 {
+		t.Errorf("connected clients = %d, want 1", stats.ClientsConnected)
+	}
+}
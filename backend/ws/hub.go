@@ -2,8 +2,8 @@ package ws
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
 	"strings"
@@ -13,14 +13,24 @@ import (
 
 	"github.com/epic1st/rtx/backend/auth"
 	"github.com/epic1st/rtx/backend/internal/core"
+	"github.com/epic1st/rtx/backend/logging"
+	"github.com/epic1st/rtx/backend/tickstore"
 	"github.com/gorilla/websocket"
 )
 
+// DefaultBroadcastThrottlePct is the smallest change in percent a quote must
+// show to be broadcast when its symbol has no threshold of its own
+const DefaultBroadcastThrottlePct = 0.0001
+
 // TickStorer interface for tick storage (allows both old TickStore and OptimizedTickStore)
 type TickStorer interface {
 	StoreTick(symbol string, bid, ask, spread float64, lp string, timestamp time.Time)
 }
 
+// CloseTokenExpired is the close code sent when a client connects with an
+// expired token, so it knows to refresh rather than retry
+const CloseTokenExpired = 4001
+
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		return true
@@ -31,16 +41,20 @@ var upgrader = websocket.Upgrader{
 type Client struct {
 	conn      *websocket.Conn
 	send      chan []byte
-	symbols   map[string]bool
-	userID    string // JWT user ID
-	accountID string // Associated account ID
-	mu        sync.Mutex
+	symbols   map[string]bool // Subscribed symbols, empty = all
+	userID    string          // JWT user ID
+	accountID string          // Associated account ID
+	mu        sync.Mutex      // Guards symbols
+
+	// Group markups resolved per symbol, only touched by the hub's Run loop
+	markups   map[string]QuoteMarkup
+	markupGen uint64
 }
 
 // Hub maintains the set of active clients and broadcasts messages
 type Hub struct {
 	clients     map[*Client]bool
-	broadcast   chan []byte
+	broadcast   chan outboundMessage
 	register    chan *Client
 	unregister  chan *Client
 	tickStore   TickStorer // Interface to support both TickStore and OptimizedTickStore
@@ -49,11 +63,18 @@ type Hub struct {
 
 	mu              sync.RWMutex
 	latestPrices    map[string]*MarketTick
+	priceUpdatedAt  map[string]time.Time // When each symbol last received a live tick
 	disabledSymbols map[string]bool
 
-	// Throttling: Track last broadcast price per symbol to reduce CPU load
-	lastBroadcast map[string]float64
-	throttleMu    sync.RWMutex
+	// Broadcast pause: ticks are still recorded but not delivered to clients
+	broadcastPaused bool
+	pauseReason     string
+	pausedAt        time.Time
+
+	// Throttling: Track last broadcast quote per symbol to reduce CPU load
+	lastBroadcast     map[string]*MarketTick
+	throttleThreshold func(symbol string) (float64, bool) // Per-symbol min change in percent
+	throttleMu        sync.RWMutex
 
 	// MT5 Compatibility Mode: When enabled, broadcast ALL ticks without throttling
 	// This is required for professional trading terminals (MT5, cTrader, etc.) that
@@ -66,6 +87,43 @@ type Hub struct {
 	ticksReceived  int64
 	ticksThrottled int64
 	ticksBroadcast int64
+	ticksDropped   int64 // Broadcast buffer full
+	clientsEvicted int64
+	tickLatencyNs  int64 // Summed receive to broadcast time of broadcast ticks
+
+	// Heartbeat: connections are pinged every pingInterval and evicted when no
+	// pong arrives within pongTimeout, 0 interval disables
+	pingInterval time.Duration
+	pongTimeout  time.Duration
+
+	// Per-symbol tick counters, resettable for measurement windows
+	tickMetrics *TickMetrics
+
+	// Per-symbol cap on persisted ticks per second, separate from the client throttle
+	recordSampler *recordSampler
+
+	// Global feed health: outage once no live tick arrives from any source for the threshold
+	lastLiveTick        time.Time
+	lastTickSeen        bool
+	feedOutageThreshold time.Duration
+	feedOutageFrom      time.Time
+	feedHealthCallback  func(healthy bool, silentFor time.Duration)
+
+	// Per-symbol sanity band that drops quotes far from recent prices
+	priceBand         *priceBandGuard
+	priceBandCallback func(reject PriceBandReject)
+
+	// Per-group spread markup applied to each client's ticks, nil = raw quotes
+	markupResolver MarkupResolver
+	markupGen      uint64 // Bumped (atomically) to re-resolve every client's markups
+
+	// Called with every accepted tick after the B-Book engine, e.g. to move trailing stops
+	tickCallback func(symbol string, bid, ask float64)
+
+	// Shutdown: quit is closed by Close, pumps counts connection write pumps
+	quit      chan struct{}
+	closeOnce sync.Once
+	pumps     sync.WaitGroup
 }
 
 // MarketTick represents a price update for clients
@@ -76,7 +134,9 @@ type MarketTick struct {
 	Ask       float64 `json:"ask"`
 	Spread    float64 `json:"spread"`
 	Timestamp int64   `json:"timestamp"`
-	LP        string  `json:"lp"` // Liquidity Provider source
+	LP        string  `json:"lp"`               // Liquidity Provider source
+	Stale     bool    `json:"stale,omitempty"`  // Recovered from a snapshot, not yet refreshed
+	Markup    float64 `json:"markup,omitempty"` // Group markup in pips included in Bid/Ask/Spread
 }
 
 func NewHub() *Hub {
@@ -85,47 +145,59 @@ func NewHub() *Hub {
 
 	h := &Hub{
 		clients:         make(map[*Client]bool),
-		broadcast:       make(chan []byte, 4096), // Larger buffer to handle bursts
+		broadcast:       make(chan outboundMessage, 4096), // Larger buffer to handle bursts
 		register:        make(chan *Client),
 		unregister:      make(chan *Client),
 		latestPrices:    make(map[string]*MarketTick),
+		priceUpdatedAt:  make(map[string]time.Time),
 		disabledSymbols: make(map[string]bool),
-		lastBroadcast:   make(map[string]float64),
+		lastBroadcast:   make(map[string]*MarketTick),
 		mt5Mode:         mt5Mode,
+		tickMetrics:     NewTickMetrics(),
+		recordSampler:   newRecordSampler(),
+		priceBand:       newPriceBandGuard(),
+		lastLiveTick:    time.Now(), // Startup counts as the last sign of life
+		pingInterval:    DefaultPingInterval,
+		pongTimeout:     DefaultPongTimeout,
+		quit:            make(chan struct{}),
 	}
 
 	// Log MT5 mode status on startup
 	if mt5Mode {
-		log.Printf("[Hub] MT5 COMPATIBILITY MODE ENABLED - Broadcasting ALL ticks (no throttling)")
-		log.Printf("[Hub] WARNING: This will increase CPU/network usage by 60-80%%")
+		logger.Warn("MT5 compatibility mode enabled: broadcasting all ticks without throttling, CPU/network usage up by 60-80%")
 	} else {
-		log.Printf("[Hub] Standard mode - Throttling enabled (broadcasts reduced by 60-80%%)")
-		log.Printf("[Hub] To enable MT5 mode, set environment variable: MT5_MODE=true")
+		logger.Info("Standard mode: throttling enabled, set MT5_MODE=true to broadcast all ticks")
 	}
 
 	// Start stats logging
 	go h.logStats()
 
+	// Persist ticks held back by per-symbol record sampling
+	go h.runSampleFlush()
+
+	// Detect a total market data outage
+	go h.runFeedHealthCheck()
+
 	return h
 }
 
+// logger writes the hub's structured log entries, component "ws"
+var logger = logging.With(logging.Component("ws"))
+
 // logStats logs hub performance metrics every 60 seconds
 func (h *Hub) logStats() {
 	ticker := time.NewTicker(60 * time.Second)
 	defer ticker.Stop()
 
 	for range ticker.C {
-		received := atomic.LoadInt64(&h.ticksReceived)
-		throttled := atomic.LoadInt64(&h.ticksThrottled)
-		broadcast := atomic.LoadInt64(&h.ticksBroadcast)
-
-		if received > 0 {
-			throttleRate := float64(throttled) / float64(received) * 100
-			h.mu.RLock()
-			clientCount := len(h.clients)
-			h.mu.RUnlock()
-			log.Printf("[Hub] Stats: received=%d, broadcast=%d, throttled=%d (%.1f%% reduction), clients=%d",
-				received, broadcast, throttled, throttleRate, clientCount)
+		stats := h.GetStats()
+
+		if stats.TicksReceived > 0 {
+			throttleRate := float64(stats.TicksThrottled) / float64(stats.TicksReceived) * 100
+			logger.Info("Hub stats", logging.Int64("received", stats.TicksReceived), logging.Int64("broadcast", stats.TicksBroadcast),
+				logging.Int64("throttled", stats.TicksThrottled), logging.Float64("reduction_pct", throttleRate),
+				logging.Int64("dropped", stats.TicksDropped), logging.Float64("avg_latency_ms", stats.AvgLatencyMs),
+				logging.Int("clients", stats.ClientsConnected), logging.Int64("evicted", stats.ClientsEvicted))
 		}
 	}
 }
@@ -155,12 +227,22 @@ func (h *Hub) UpdateSymbol(spec *core.SymbolSpec) {
 		// Keep the disabled state
 		h.disabledSymbols[spec.Symbol] = true
 	}
+
+	h.recordSampler.setRate(spec.Symbol, spec.MaxRecordRate)
 }
 
 // BroadcastTick broadcasts a market tick to all clients with THROTTLING
 // Throttling reduces CPU load by 60-80% by skipping tiny price changes
 func (h *Hub) BroadcastTick(tick *MarketTick) {
+	received := time.Now()
 	atomic.AddInt64(&h.ticksReceived, 1)
+	h.tickMetrics.Record(tick, time.Now())
+
+	// Drop fat-finger quotes far outside the symbol's recent prices before they
+	// reach storage, the engine or clients
+	if !h.checkPriceBand(tick, time.Now()) {
+		return
+	}
 
 	// ============================================
 	// CRITICAL FIX: ALWAYS PERSIST TICKS FIRST
@@ -169,29 +251,39 @@ func (h *Hub) BroadcastTick(tick *MarketTick) {
 	// - WebSocket client connections
 	// - Symbol enabled/disabled status
 	// - Price change throttling
+	// Symbols with a max record rate are sampled down before storage.
 	// ============================================
-	if h.tickStore != nil {
-		h.tickStore.StoreTick(tick.Symbol, tick.Bid, tick.Ask, tick.Spread, tick.LP, time.Now())
-	}
+	h.persistTick(tick, time.Now())
 
 	// Update B-Book engine (needs all prices for accurate execution)
 	if h.bbookEngine != nil {
 		h.bbookEngine.UpdatePrice(tick.Symbol, tick.Bid, tick.Ask)
 	}
+	if h.tickCallback != nil {
+		h.tickCallback(tick.Symbol, tick.Bid, tick.Ask)
+	}
 
 	// Update latest price (always - needed for queries)
 	h.mu.Lock()
+	now := time.Now()
 	h.latestPrices[tick.Symbol] = tick
+	h.priceUpdatedAt[tick.Symbol] = now
+	feedResumed := h.recordLiveTickUnlocked(now)
+
+	// Skip broadcast if symbol is disabled or delivery is paused (but tick is already stored above)
+	skip := h.disabledSymbols[tick.Symbol] || h.broadcastPaused
+	h.mu.Unlock()
 
-	// Skip broadcast if symbol is disabled (but tick is already stored above)
-	if h.disabledSymbols[tick.Symbol] {
-		h.mu.Unlock()
+	if feedResumed != nil {
+		feedResumed()
+	}
+	if skip {
 		return
 	}
-	h.mu.Unlock()
 
 	// ============================================
-	// THROTTLING: Skip broadcast if price change < 0.0001% (1/100th of a pip)
+	// THROTTLING: Skip broadcast if neither side nor the spread moved by the
+	// symbol's threshold (default 0.0001%, 1/100th of a pip)
 	// This reduces broadcast volume by 60-80% and prevents CPU overload
 	// NOTE: Tick is already stored above, throttling only affects broadcast
 	//
@@ -202,17 +294,20 @@ func (h *Hub) BroadcastTick(tick *MarketTick) {
 	if !h.mt5Mode {
 		// Standard mode: Apply throttling to reduce CPU/network load
 		h.throttleMu.RLock()
-		lastPrice, exists := h.lastBroadcast[tick.Symbol]
+		last, exists := h.lastBroadcast[tick.Symbol]
+		thresholdFn := h.throttleThreshold
 		h.throttleMu.RUnlock()
 
-		if exists && lastPrice > 0 {
-			priceChange := (tick.Bid - lastPrice) / lastPrice
-			if priceChange < 0 {
-				priceChange = -priceChange
+		if exists {
+			threshold := DefaultBroadcastThrottlePct
+			if thresholdFn != nil {
+				if pct, ok := thresholdFn(tick.Symbol); ok {
+					threshold = pct
+				}
 			}
 
-			// Skip broadcast if change < 0.0001% (tick already stored above)
-			if priceChange < 0.000001 {
+			// Skip broadcast below the threshold (tick already stored above)
+			if !tickstore.QuoteMoved(last.Bid, last.Ask, tick.Bid, tick.Ask, threshold) {
 				atomic.AddInt64(&h.ticksThrottled, 1)
 				return
 			}
@@ -220,22 +315,24 @@ func (h *Hub) BroadcastTick(tick *MarketTick) {
 	}
 	// MT5 mode: Skip throttling check entirely - broadcast ALL ticks
 
-	// Update last broadcast price
+	// Update last broadcast quote
 	h.throttleMu.Lock()
-	h.lastBroadcast[tick.Symbol] = tick.Bid
+	h.lastBroadcast[tick.Symbol] = tick
 	h.throttleMu.Unlock()
 
-	data, err := json.Marshal(tick)
+	message, err := h.tickMessage(tick)
 	if err != nil {
 		return
 	}
 
 	// NON-BLOCKING SEND: If buffer full, drop tick to keep engine running
 	select {
-	case h.broadcast <- data:
+	case h.broadcast <- message:
 		atomic.AddInt64(&h.ticksBroadcast, 1)
+		atomic.AddInt64(&h.tickLatencyNs, int64(time.Since(received)))
 	default:
 		// Buffer full - drop to prevent blocking (data still stored for history)
+		atomic.AddInt64(&h.ticksDropped, 1)
 	}
 }
 
@@ -246,6 +343,40 @@ func (h *Hub) GetLatestPrice(symbol string) *MarketTick {
 	return h.latestPrices[symbol]
 }
 
+// QuoteAge returns how long ago a symbol last received a live tick. ok is
+// false when no live tick has arrived yet, e.g. only a snapshot quote is held.
+func (h *Hub) QuoteAge(symbol string) (age time.Duration, ok bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	updatedAt, ok := h.priceUpdatedAt[symbol]
+	if !ok {
+		return 0, false
+	}
+	return time.Since(updatedAt), true
+}
+
+// QuoteAges returns how long ago each symbol last received a live tick
+func (h *Hub) QuoteAges() map[string]time.Duration {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ages := make(map[string]time.Duration, len(h.priceUpdatedAt))
+	for symbol, updatedAt := range h.priceUpdatedAt {
+		ages[symbol] = time.Since(updatedAt)
+	}
+	return ages
+}
+
+// SetThrottleThresholdFunc sets where per-symbol broadcast throttle thresholds
+// (min change in percent) come from, typically the tick store. Symbols fn
+// reports no threshold for use DefaultBroadcastThrottlePct.
+func (h *Hub) SetThrottleThresholdFunc(fn func(symbol string) (float64, bool)) {
+	h.throttleMu.Lock()
+	defer h.throttleMu.Unlock()
+	h.throttleThreshold = fn
+}
+
 // SetTickStore sets the tick store for persisting market data
 // Accepts any TickStorer interface (works with both TickStore and OptimizedTickStore)
 func (h *Hub) SetTickStore(ts TickStorer) {
@@ -255,6 +386,17 @@ func (h *Hub) SetTickStore(ts TickStorer) {
 // SetBBookEngine sets the B-Book engine for symbol synchronization
 func (h *Hub) SetBBookEngine(engine *core.Engine) {
 	h.bbookEngine = engine
+
+	// Pick up per-symbol record rates from the symbol registry
+	for _, spec := range engine.GetSymbols() {
+		h.recordSampler.setRate(spec.Symbol, spec.MaxRecordRate)
+	}
+}
+
+// SetTickCallback sets the function called with every tick that passes the
+// price band, whether or not it is broadcast
+func (h *Hub) SetTickCallback(fn func(symbol string, bid, ask float64)) {
+	h.tickCallback = fn
 }
 
 // SetAuthService sets the authentication service for validating tokens
@@ -267,21 +409,56 @@ func (h *Hub) Run() {
 		select {
 		case client := <-h.register:
 			h.mu.Lock()
+			if h.closing() {
+				// Close already disconnected the others
+				close(client.send)
+				h.mu.Unlock()
+				continue
+			}
 			h.clients[client] = true
 			clientCount := len(h.clients)
 			h.mu.Unlock()
-			log.Printf("[Hub] Client connected. Total clients: %d", clientCount)
+			logger.Info("Client connected", logging.UserID(client.userID), logging.Int("clients", clientCount))
 
 			// Send latest prices for all symbols upon connection
 			h.mu.RLock()
+			if h.broadcastPaused {
+				notice := FeedNotice{Type: "feed_paused", Reason: h.pauseReason, Timestamp: time.Now().Unix()}
+				if data, err := json.Marshal(notice); err == nil {
+					select {
+					case client.send <- data:
+					default:
+					}
+				}
+				h.mu.RUnlock()
+				continue
+			}
+			var snapshot []*MarketTick
 			for _, tick := range h.latestPrices {
-				if !h.disabledSymbols[tick.Symbol] {
-					if data, err := json.Marshal(tick); err == nil {
-						// Try non-blocking send to client on init
-						select {
-						case client.send <- data:
-						default:
-						}
+				if !h.disabledSymbols[tick.Symbol] && client.wantsSymbol(tick.Symbol) {
+					snapshot = append(snapshot, tick)
+				}
+			}
+			resolve := h.markupResolver
+			h.mu.RUnlock()
+
+			// Symbol pricing and markups come from outside the hub, so frames are built without the lock
+			var frames [][]byte
+			for _, tick := range snapshot {
+				message, err := h.tickMessage(tick)
+				if err != nil {
+					continue
+				}
+				frames = append(frames, h.tickForClient(client, message, resolve, make(map[QuoteMarkup][]byte)))
+			}
+
+			h.mu.RLock()
+			if h.clients[client] {
+				for _, data := range frames {
+					// Try non-blocking send to client on init
+					select {
+					case client.send <- data:
+					default:
 					}
 				}
 			}
@@ -292,7 +469,7 @@ func (h *Hub) Run() {
 			if _, ok := h.clients[client]; ok {
 				delete(h.clients, client)
 				close(client.send)
-				log.Printf("[Hub] Client disconnected. Total clients: %d", len(h.clients))
+				logger.Info("Client disconnected", logging.UserID(client.userID), logging.Int("clients", len(h.clients)))
 			}
 			h.mu.Unlock()
 
@@ -306,9 +483,30 @@ func (h *Hub) Run() {
 			}
 
 			h.mu.RLock()
+			var targets []*Client
 			for client := range h.clients {
+				// Clients that subscribed only receive ticks for their symbols
+				if client.wantsSymbol(message.symbol) {
+					targets = append(targets, client)
+				}
+			}
+			resolve := h.markupResolver
+			h.mu.RUnlock()
+
+			// Frames are picked outside the lock: a markup may need resolving
+			marked := make(map[QuoteMarkup][]byte) // Tick frames per group markup
+			frames := make([][]byte, len(targets))
+			for i, client := range targets {
+				frames[i] = h.tickForClient(client, message, resolve, marked)
+			}
+
+			h.mu.RLock()
+			for i, client := range targets {
+				if !h.clients[client] {
+					continue // Disconnected meanwhile
+				}
 				select {
-				case client.send <- message:
+				case client.send <- frames[i]:
 				default:
 					// Client buffer full - just drop the message instead of disconnecting
 					// The client will get the next update
@@ -321,12 +519,17 @@ func (h *Hub) Run() {
 
 // ServeWs handles websocket requests from the peer with JWT authentication.
 func ServeWs(hub *Hub, w http.ResponseWriter, r *http.Request) {
-	log.Printf("[WS] Upgrade request from %s", r.RemoteAddr)
+	logger.Debug("Upgrade request", logging.String("remote", r.RemoteAddr))
 
 	// Extract and validate JWT token from query parameters or headers
 	userID, accountID, err := extractAndValidateToken(hub, r)
+	if errors.Is(err, auth.ErrTokenExpired) {
+		logger.Info("Expired token", logging.String("remote", r.RemoteAddr))
+		rejectExpiredToken(w, r)
+		return
+	}
 	if err != nil {
-		log.Printf("[WS] Authentication FAILED for %s: %v", r.RemoteAddr, err)
+		logger.Warn("Authentication failed", logging.String("remote", r.RemoteAddr), logging.Err(err))
 		// Return 401 Unauthorized
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
@@ -334,12 +537,16 @@ func ServeWs(hub *Hub, w http.ResponseWriter, r *http.Request) {
 
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Printf("[WS] Upgrade FAILED for %s: %v", r.RemoteAddr, err)
+		logger.Warn("Upgrade failed", logging.String("remote", r.RemoteAddr), logging.Err(err))
 		return
 	}
 
-	log.Printf("[WS] Upgrade SUCCESS for user %s (account %s) from %s", userID, accountID, r.RemoteAddr)
+	logger.Info("Upgrade succeeded", logging.UserID(userID), logging.AccountID(accountID), logging.String("remote", r.RemoteAddr))
+	hub.serveClient(conn, userID, accountID)
+}
 
+// serveClient registers an upgraded connection and starts its pumps
+func (h *Hub) serveClient(conn *websocket.Conn, userID, accountID string) {
 	client := &Client{
 		conn:      conn,
 		send:      make(chan []byte, 1024), // BUFFERED: Handle bursts
@@ -347,16 +554,38 @@ func ServeWs(hub *Hub, w http.ResponseWriter, r *http.Request) {
 		userID:    userID,
 		accountID: accountID,
 	}
-	hub.register <- client
+	if !h.trackPump() {
+		sendGoingAway(conn)
+		conn.Close()
+		return
+	}
+	h.register <- client
+
+	// Heartbeat: pings go out from the write pump, pongs extend the read deadline
+	pings, stopPings := h.startHeartbeat(conn)
 
 	// Write pump
 	go func() {
+		defer h.pumps.Done()
 		defer conn.Close()
-		for message := range client.send {
-			err := conn.WriteMessage(websocket.TextMessage, message)
-			if err != nil {
-				log.Printf("[WS] Write error for user %s: %v", userID, err)
-				break
+		defer stopPings()
+		for {
+			select {
+			case message, ok := <-client.send:
+				if !ok {
+					// Unregistered, or the hub is closing
+					sendGoingAway(conn)
+					return
+				}
+				if err := conn.WriteMessage(websocket.TextMessage, message); err != nil {
+					logger.Warn("Write error", logging.UserID(userID), logging.Err(err))
+					return
+				}
+			case <-pings:
+				if err := sendPing(conn); err != nil {
+					logger.Warn("Ping failed", logging.UserID(userID), logging.Err(err))
+					return
+				}
 			}
 		}
 	}()
@@ -364,24 +593,53 @@ func ServeWs(hub *Hub, w http.ResponseWriter, r *http.Request) {
 	// Read pump (handle subscriptions, etc.)
 	go func() {
 		defer func() {
-			hub.unregister <- client
+			h.unregister <- client
 			conn.Close()
-			log.Printf("[WS] Connection closed for user %s", userID)
+			logger.Info("Connection closed", logging.UserID(userID))
 		}()
 		for {
-			_, _, err := conn.ReadMessage()
+			_, data, err := conn.ReadMessage()
 			if err != nil {
+				h.noteReadError(userID, err)
 				break
 			}
+			h.handleClientMessage(client, data)
 		}
 	}()
 }
 
+// rejectExpiredToken completes the handshake only to close it with
+// CloseTokenExpired; a plain 401 is indistinguishable from a bad token
+func rejectExpiredToken(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(CloseTokenExpired, "token expired"),
+		time.Now().Add(pingWriteWait))
+}
+
 // extractAndValidateToken extracts the JWT token from query params or Authorization header
 // and validates it using the auth service. Returns (userID, accountID, error).
 func extractAndValidateToken(hub *Hub, r *http.Request) (string, string, error) {
+	claims, err := extractClaims(hub, r)
+	if err != nil {
+		return "", "", err
+	}
+
+	userID := claims.UserID
+	accountID := userID // For now, use userID as accountID (same value for admin/trader accounts)
+
+	return userID, accountID, nil
+}
+
+// extractClaims extracts the JWT token from query params or Authorization
+// header and returns its validated claims
+func extractClaims(hub *Hub, r *http.Request) (*auth.Claims, error) {
 	if hub.authService == nil {
-		return "", "", fmt.Errorf("auth service not configured")
+		return nil, fmt.Errorf("auth service not configured")
 	}
 
 	// Try query parameter first (ws://localhost/ws?token=xyz)
@@ -399,26 +657,27 @@ func extractAndValidateToken(hub *Hub, r *http.Request) (string, string, error)
 	}
 
 	if token == "" {
-		return "", "", fmt.Errorf("no token provided")
+		return nil, fmt.Errorf("no token provided")
 	}
 
 	// Validate token using the auth service's secret (same one used to generate tokens)
 	claims, err := hub.authService.ValidateToken(token)
 	if err != nil {
-		return "", "", fmt.Errorf("invalid token: %v", err)
+		return nil, fmt.Errorf("invalid token: %w", err)
 	}
-
-	userID := claims.UserID
-	accountID := userID // For now, use userID as accountID (same value for admin/trader accounts)
-
-	return userID, accountID, nil
+	return claims, nil
 }
 
 // BroadcastMessage sends a generic message to all connected clients
 func (h *Hub) BroadcastMessage(message []byte) {
+	h.enqueue(outboundMessage{data: message})
+}
+
+// enqueue queues a message for the client loop without blocking
+func (h *Hub) enqueue(message outboundMessage) {
 	select {
 	case h.broadcast <- message:
 	default:
-		log.Println("[Hub] Broadcast buffer full, message dropped")
+		logger.Warn("Broadcast buffer full, message dropped", logging.Symbol(message.symbol))
 	}
 }
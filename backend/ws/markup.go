@@ -0,0 +1,126 @@
+package ws
+
+import (
+	"encoding/json"
+	"math"
+	"sync/atomic"
+
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+// QuoteMarkup widens a quote for a client's group: BidPips come off the bid,
+// AskPips go on the ask
+type QuoteMarkup struct {
+	BidPips float64
+	AskPips float64
+}
+
+// IsZero reports whether the markup leaves the quote unchanged
+func (m QuoteMarkup) IsZero() bool {
+	return m.BidPips == 0 && m.AskPips == 0
+}
+
+// SymmetricMarkup splits a markup in pips evenly between bid and ask
+func SymmetricMarkup(pips float64) QuoteMarkup {
+	return QuoteMarkup{BidPips: pips / 2, AskPips: pips / 2}
+}
+
+// MarkupResolver returns the markup of a client's account for a symbol
+type MarkupResolver func(accountID, symbol string) QuoteMarkup
+
+// ApplyMarkup returns a copy of tick widened by markup and rounded to the
+// symbol's digits. Spread is the effective spread the client sees.
+func ApplyMarkup(tick *MarketTick, markup QuoteMarkup, pipSize float64, digits int) *MarketTick {
+	marked := *tick
+	scale := math.Pow(10, float64(digits))
+	round := func(price float64) float64 { return math.Round(price*scale) / scale }
+
+	marked.Bid = round(tick.Bid - markup.BidPips*pipSize)
+	marked.Ask = round(tick.Ask + markup.AskPips*pipSize)
+	marked.Spread = round(marked.Ask - marked.Bid)
+	marked.Markup = markup.BidPips + markup.AskPips
+	return &marked
+}
+
+// SetMarkupResolver sets the lookup of each client's group markup. Until set,
+// every client receives the raw quote. A client's markup for a symbol is
+// resolved once, and again only after InvalidateMarkups.
+func (h *Hub) SetMarkupResolver(fn MarkupResolver) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.markupResolver = fn
+	atomic.AddUint64(&h.markupGen, 1)
+}
+
+// InvalidateMarkups makes every client's markups resolve again on their next
+// tick, to be called when a group's markup or an account's group changes
+func (h *Hub) InvalidateMarkups() {
+	atomic.AddUint64(&h.markupGen, 1)
+}
+
+// clientMarkup returns the markup of a client for a symbol, resolving it on
+// first use or after InvalidateMarkups. Only the Run loop calls it.
+func (h *Hub) clientMarkup(client *Client, symbol string, resolve MarkupResolver) QuoteMarkup {
+	if gen := atomic.LoadUint64(&h.markupGen); client.markups == nil || client.markupGen != gen {
+		client.markups = make(map[string]QuoteMarkup)
+		client.markupGen = gen
+	}
+	markup, ok := client.markups[symbol]
+	if !ok {
+		markup = resolve(client.accountID, symbol)
+		client.markups[symbol] = markup
+	}
+	return markup
+}
+
+// symbolPricing returns the pip size and quoted digits of a symbol, from the
+// engine's spec or generated from the symbol name
+func (h *Hub) symbolPricing(symbol string) (pipSize float64, digits int) {
+	var spec *core.SymbolSpec
+	if h.bbookEngine != nil {
+		spec, _ = h.bbookEngine.GetSymbol(symbol)
+	}
+	if spec == nil {
+		spec = core.GenerateSymbolSpec(symbol)
+	}
+
+	pipSize, digits = spec.PipSize, spec.Digits
+	if digits <= 0 && pipSize > 0 {
+		// Prices quote one digit past the pip: 0.0001 -> 5 digits, 0.01 -> 3
+		digits = int(math.Round(-math.Log10(pipSize))) + 1
+	}
+	return pipSize, digits
+}
+
+// tickForClient returns the frame of a tick as the client's group sees it.
+// Marked-up frames are cached per markup so each group is encoded once.
+// Called from the Run loop without h.mu held.
+func (h *Hub) tickForClient(client *Client, message outboundMessage, resolve MarkupResolver, cache map[QuoteMarkup][]byte) []byte {
+	if message.tick == nil || resolve == nil || message.pipSize <= 0 {
+		return message.data
+	}
+	markup := h.clientMarkup(client, message.symbol, resolve)
+	if markup.IsZero() {
+		return message.data
+	}
+	if data, ok := cache[markup]; ok {
+		return data
+	}
+	data, err := json.Marshal(ApplyMarkup(message.tick, markup, message.pipSize, message.digits))
+	if err != nil {
+		return message.data
+	}
+	cache[markup] = data
+	return data
+}
+
+// tickMessage builds the outbound frame of a tick with the pricing markups need
+func (h *Hub) tickMessage(tick *MarketTick) (outboundMessage, error) {
+	data, err := json.Marshal(tick)
+	if err != nil {
+		return outboundMessage{}, err
+	}
+	message := outboundMessage{symbol: tick.Symbol, data: data, tick: tick}
+	message.pipSize, message.digits = h.symbolPricing(tick.Symbol)
+	return message, nil
+}
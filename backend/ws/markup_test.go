@@ -0,0 +1,122 @@
+package ws
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+// TestApplyMarkupDigits tests markup math on a 5-digit and a 3-digit symbol,
+// symmetric and asymmetric
+func TestApplyMarkupDigits(t *testing.T) {
+	hub := NewHub()
+	tests := []struct {
+		symbol         string
+		bid, ask       float64
+		markup         QuoteMarkup
+		wantBid        float64
+		wantAsk        float64
+		wantSpread     float64
+		wantPip        float64
+		wantDigitCount int
+	}{
+		{"EURUSD", 1.10000, 1.10012, SymmetricMarkup(1.0), 1.09995, 1.10017, 0.00022, 0.0001, 5},
+		{"EURUSD", 1.10000, 1.10012, QuoteMarkup{BidPips: 0.3, AskPips: 0.9}, 1.09997, 1.10021, 0.00024, 0.0001, 5},
+		{"USDJPY", 150.000, 150.012, SymmetricMarkup(1.0), 149.995, 150.017, 0.022, 0.01, 3},
+		{"USDJPY", 150.000, 150.012, QuoteMarkup{AskPips: 2}, 150.000, 150.032, 0.032, 0.01, 3},
+	}
+	for _, tt := range tests {
+		pipSize, digits := hub.symbolPricing(tt.symbol)
+		if pipSize != tt.wantPip || digits != tt.wantDigitCount {
+			t.Fatalf("symbolPricing(%s) = %v, %d; want %v, %d", tt.symbol, pipSize, digits, tt.wantPip, tt.wantDigitCount)
+		}
+		got := ApplyMarkup(quote(tt.symbol, tt.bid, tt.ask), tt.markup, pipSize, digits)
+		if got.Bid != tt.wantBid || got.Ask != tt.wantAsk || math.Abs(got.Spread-tt.wantSpread) > 1e-12 {
+			t.Errorf("ApplyMarkup(%s, %+v) = %v/%v spread %v; want %v/%v spread %v",
+				tt.symbol, tt.markup, got.Bid, got.Ask, got.Spread, tt.wantBid, tt.wantAsk, tt.wantSpread)
+		}
+		if got.Markup != tt.markup.BidPips+tt.markup.AskPips {
+			t.Errorf("markup = %v pips, want %v", got.Markup, tt.markup.BidPips+tt.markup.AskPips)
+		}
+	}
+}
+
+// TestGroupsSeeDifferentSpreads tests that accounts in different groups receive
+// the same underlying quote at their own group's spread
+func TestGroupsSeeDifferentSpreads(t *testing.T) {
+	hub := NewHub()
+	hub.SetMarkupResolver(func(accountID, symbol string) QuoteMarkup {
+		if accountID == "2" {
+			return SymmetricMarkup(2.0) // e.g. a standard group
+		}
+		return QuoteMarkup{} // Raw spread group
+	})
+	go hub.Run()
+
+	raw := &Client{send: make(chan []byte, 64), symbols: make(map[string]bool), accountID: "1"}
+	marked := &Client{send: make(chan []byte, 64), symbols: make(map[string]bool), accountID: "2"}
+	hub.register <- raw
+	hub.register <- marked
+
+	hub.BroadcastTick(quote("EURUSD", 1.10000, 1.10010))
+	time.Sleep(100 * time.Millisecond)
+
+	receive := func(client *Client) MarketTick {
+		t.Helper()
+		select {
+		case data := <-client.send:
+			var tick MarketTick
+			if err := json.Unmarshal(data, &tick); err != nil {
+				t.Fatalf("invalid tick JSON: %s", data)
+			}
+			return tick
+		default:
+			t.Fatal("no tick delivered")
+			return MarketTick{}
+		}
+	}
+
+	if tick := receive(raw); tick.Bid != 1.10000 || tick.Ask != 1.10010 || tick.Markup != 0 {
+		t.Errorf("raw group tick = %+v, want the LP quote", tick)
+	}
+	tick := receive(marked)
+	if tick.Bid != 1.09990 || tick.Ask != 1.10020 || math.Abs(tick.Spread-0.0003) > 1e-12 || tick.Markup != 2 {
+		t.Errorf("marked-up group tick = %+v, want 1.09990/1.10020 with a 3 pip spread", tick)
+	}
+
+	// The hub keeps the raw quote for pricing and storage
+	if latest := hub.GetLatestPrice("EURUSD"); latest.Bid != 1.10000 || latest.Ask != 1.10010 {
+		t.Errorf("latest price = %+v, want the raw quote", latest)
+	}
+}
+
+// TestClientMarkupResolvedOnce tests that a client's markup is resolved once
+// per symbol and again only after InvalidateMarkups
+func TestClientMarkupResolvedOnce(t *testing.T) {
+	hub := NewHub()
+	resolved := 0
+	resolve := func(accountID, symbol string) QuoteMarkup {
+		resolved++
+		return SymmetricMarkup(1.0)
+	}
+	hub.SetMarkupResolver(resolve)
+	client := &Client{send: make(chan []byte, 1), symbols: make(map[string]bool), accountID: "2"}
+
+	message, err := hub.tickMessage(quote("EURUSD", 1.10000, 1.10010))
+	if err != nil {
+		t.Fatalf("tickMessage() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		hub.tickForClient(client, message, resolve, make(map[QuoteMarkup][]byte))
+	}
+	if resolved != 1 {
+		t.Fatalf("markup resolved %d times over 3 ticks, want once", resolved)
+	}
+
+	hub.InvalidateMarkups()
+	hub.tickForClient(client, message, resolve, make(map[QuoteMarkup][]byte))
+	if resolved != 2 {
+		t.Errorf("markup resolved %d times after invalidation, want twice", resolved)
+	}
+}
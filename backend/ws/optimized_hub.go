@@ -2,12 +2,12 @@ package ws
 
 import (
 	"encoding/json"
-	"log"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/epic1st/rtx/backend/internal/core"
+	"github.com/epic1st/rtx/backend/logging"
 )
 
 // OptimizedHub is a high-performance WebSocket hub with quote throttling
@@ -145,7 +145,7 @@ func (h *OptimizedHub) RunOptimized() {
 			h.clients[client] = true
 			clientCount := len(h.clients)
 			h.mu.Unlock()
-			log.Printf("[OptimizedHub] Client connected. Total: %d", clientCount)
+			logger.Info("Client connected", logging.String("hub", "optimized"), logging.Int("clients", clientCount))
 
 			// Send latest prices to new client
 			h.sendLatestPrices(client)
@@ -155,7 +155,7 @@ func (h *OptimizedHub) RunOptimized() {
 			if _, ok := h.clients[client]; ok {
 				delete(h.clients, client)
 				close(client.send)
-				log.Printf("[OptimizedHub] Client disconnected. Total: %d", len(h.clients))
+				logger.Info("Client disconnected", logging.String("hub", "optimized"), logging.Int("clients", len(h.clients)))
 			}
 			h.mu.Unlock()
 
@@ -207,8 +207,8 @@ func (h *OptimizedHub) logStats() {
 
 		if received > 0 {
 			throttleRate := float64(throttled) / float64(received) * 100
-			log.Printf("[OptimizedHub] Stats: received=%d, broadcast=%d, throttled=%d (%.1f%% reduction)",
-				received, broadcast, throttled, throttleRate)
+			logger.Info("Hub stats", logging.String("hub", "optimized"), logging.Int64("received", received),
+				logging.Int64("broadcast", broadcast), logging.Int64("throttled", throttled), logging.Float64("reduction_pct", throttleRate))
 		}
 	}
 }
@@ -0,0 +1,240 @@
+package ws
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+// minBandSamples is how many accepted mids a symbol needs before its band is enforced
+const minBandSamples = 3
+
+// PriceBandConfig bounds how far a quote's mid may stray from the median of the
+// symbol's recent accepted mids. The band follows the market as accepted ticks
+// roll through the window.
+type PriceBandConfig struct {
+	MaxDeviationPct  float64 `json:"maxDeviationPct"`  // 0 disables the band
+	Window           int     `json:"window"`           // Accepted mids in the rolling median
+	RecalibrateAfter int     `json:"recalibrateAfter"` // Consecutive agreeing rejects that re-anchor the band at a new level, 0 never
+}
+
+// PriceBandReject describes a quote dropped by the sanity band
+type PriceBandReject struct {
+	Symbol       string    `json:"symbol"`
+	LP           string    `json:"lp"`
+	Mid          float64   `json:"mid"`
+	Reference    float64   `json:"reference"` // Median of the recent accepted mids
+	DeviationPct float64   `json:"deviationPct"`
+	Consecutive  int       `json:"consecutive"` // Rejects in a row for the symbol, including this one
+	At           time.Time `json:"at"`
+}
+
+// PriceBandStats counts quotes rejected by the sanity band
+type PriceBandStats struct {
+	Default    PriceBandConfig            `json:"default"`
+	Overrides  map[string]PriceBandConfig `json:"overrides"`
+	Rejected   int64                      `json:"rejected"`
+	BySymbol   map[string]int64           `json:"bySymbol"`
+	LastReject map[string]PriceBandReject `json:"lastReject"`
+}
+
+// priceBandGuard keeps the recent accepted mids of every symbol and decides
+// whether an incoming quote is plausible
+type priceBandGuard struct {
+	mu         sync.Mutex
+	defaults   PriceBandConfig
+	overrides  map[string]PriceBandConfig
+	mids       map[string][]float64 // Symbol -> recent accepted mids, oldest first
+	streaks    map[string][]float64 // Symbol -> latest mids rejected in a row, up to RecalibrateAfter
+	runs       map[string]int       // Symbol -> number of rejects in a row
+	rejected   map[string]int64
+	lastReject map[string]PriceBandReject
+}
+
+func newPriceBandGuard() *priceBandGuard {
+	return &priceBandGuard{
+		overrides:  make(map[string]PriceBandConfig),
+		mids:       make(map[string][]float64),
+		streaks:    make(map[string][]float64),
+		runs:       make(map[string]int),
+		rejected:   make(map[string]int64),
+		lastReject: make(map[string]PriceBandReject),
+	}
+}
+
+// configFor returns the band of a symbol (caller must hold lock)
+func (g *priceBandGuard) configFor(symbol string) PriceBandConfig {
+	if cfg, ok := g.overrides[symbol]; ok {
+		return cfg
+	}
+	return g.defaults
+}
+
+// check records a quote and returns the rejection when its mid falls outside
+// the symbol's band, or nil when the quote may pass
+func (g *priceBandGuard) check(tick *MarketTick, at time.Time) *PriceBandReject {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	cfg := g.configFor(tick.Symbol)
+	if cfg.MaxDeviationPct <= 0 || cfg.Window <= 0 {
+		return nil
+	}
+
+	mid := (tick.Bid + tick.Ask) / 2
+	history := g.mids[tick.Symbol]
+	if len(history) < minBandSamples {
+		g.acceptUnlocked(tick.Symbol, mid, cfg.Window)
+		return nil
+	}
+
+	reference := median(history)
+	deviation := math.Abs(mid-reference) / reference * 100
+	if deviation <= cfg.MaxDeviationPct {
+		g.acceptUnlocked(tick.Symbol, mid, cfg.Window)
+		return nil
+	}
+
+	streak := append(g.streaks[tick.Symbol], mid)
+	if keep := max(cfg.RecalibrateAfter, 1); len(streak) > keep {
+		streak = streak[len(streak)-keep:]
+	}
+	if cfg.RecalibrateAfter > 0 && len(streak) == cfg.RecalibrateAfter && withinBand(streak, cfg.MaxDeviationPct) {
+		// The market has genuinely moved: restart the band from the new level
+		logger.Info("Price band recalibrated", logging.Symbol(tick.Symbol), logging.Float64("from", reference),
+			logging.Float64("to", median(streak)), logging.Int("consistent_quotes", len(streak)))
+		if len(streak) > cfg.Window {
+			streak = streak[len(streak)-cfg.Window:]
+		}
+		g.mids[tick.Symbol] = streak
+		delete(g.streaks, tick.Symbol)
+		delete(g.runs, tick.Symbol)
+		return nil
+	}
+	g.streaks[tick.Symbol] = streak
+	g.runs[tick.Symbol]++
+	g.rejected[tick.Symbol]++
+
+	reject := PriceBandReject{
+		Symbol:       tick.Symbol,
+		LP:           tick.LP,
+		Mid:          mid,
+		Reference:    reference,
+		DeviationPct: deviation,
+		Consecutive:  g.runs[tick.Symbol],
+		At:           at,
+	}
+	g.lastReject[tick.Symbol] = reject
+	return &reject
+}
+
+// acceptUnlocked adds a mid to the symbol's window and ends any reject streak
+// (caller must hold lock)
+func (g *priceBandGuard) acceptUnlocked(symbol string, mid float64, window int) {
+	history := append(g.mids[symbol], mid)
+	if len(history) > window {
+		history = history[len(history)-window:]
+	}
+	g.mids[symbol] = history
+	delete(g.streaks, symbol)
+	delete(g.runs, symbol)
+}
+
+// median returns the median of values without reordering them
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// withinBand reports whether every value lies within maxPct of their median
+func withinBand(values []float64, maxPct float64) bool {
+	m := median(values)
+	for _, v := range values {
+		if math.Abs(v-m)/m*100 > maxPct {
+			return false
+		}
+	}
+	return true
+}
+
+// SetDefaultPriceBand sets the sanity band applied to symbols without an override
+func (h *Hub) SetDefaultPriceBand(cfg PriceBandConfig) {
+	h.priceBand.mu.Lock()
+	defer h.priceBand.mu.Unlock()
+	h.priceBand.defaults = cfg
+}
+
+// SetPriceBand overrides the sanity band of one symbol. A zero MaxDeviationPct
+// turns the band off for the symbol.
+func (h *Hub) SetPriceBand(symbol string, cfg PriceBandConfig) {
+	h.priceBand.mu.Lock()
+	defer h.priceBand.mu.Unlock()
+	h.priceBand.overrides[symbol] = cfg
+}
+
+// ClearPriceBand removes a symbol's override so it uses the default band again
+func (h *Hub) ClearPriceBand(symbol string) {
+	h.priceBand.mu.Lock()
+	defer h.priceBand.mu.Unlock()
+	delete(h.priceBand.overrides, symbol)
+}
+
+// SetPriceBandCallback sets the function notified of every quote rejected by the band
+func (h *Hub) SetPriceBandCallback(fn func(reject PriceBandReject)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.priceBandCallback = fn
+}
+
+// GetPriceBandStats returns the band configuration and rejection counters
+func (h *Hub) GetPriceBandStats() PriceBandStats {
+	g := h.priceBand
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	stats := PriceBandStats{
+		Default:    g.defaults,
+		Overrides:  make(map[string]PriceBandConfig, len(g.overrides)),
+		BySymbol:   make(map[string]int64, len(g.rejected)),
+		LastReject: make(map[string]PriceBandReject, len(g.lastReject)),
+	}
+	for symbol, cfg := range g.overrides {
+		stats.Overrides[symbol] = cfg
+	}
+	for symbol, count := range g.rejected {
+		stats.BySymbol[symbol] = count
+		stats.Rejected += count
+	}
+	for symbol, reject := range g.lastReject {
+		stats.LastReject[symbol] = reject
+	}
+	return stats
+}
+
+// checkPriceBand reports whether a tick passes the sanity band, notifying the
+// callback when it does not
+func (h *Hub) checkPriceBand(tick *MarketTick, at time.Time) bool {
+	reject := h.priceBand.check(tick, at)
+	if reject == nil {
+		return true
+	}
+
+	logger.Warn("Rejected quote outside the price band", logging.Symbol(reject.Symbol), logging.String("lp", reject.LP),
+		logging.Float64("mid", reject.Mid), logging.Float64("deviation_pct", reject.DeviationPct), logging.Float64("median", reject.Reference))
+
+	h.mu.RLock()
+	callback := h.priceBandCallback
+	h.mu.RUnlock()
+	if callback != nil {
+		callback(*reject)
+	}
+	return false
+}
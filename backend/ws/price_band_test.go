@@ -0,0 +1,100 @@
+package ws
+
+import (
+	"testing"
+)
+
+// TestPriceBandRejectsOutlier tests that a fat-finger tick is dropped before it
+// reaches the quote cache and is counted, while normal ticks pass
+func TestPriceBandRejectsOutlier(t *testing.T) {
+	hub := NewHub()
+	hub.SetDefaultPriceBand(PriceBandConfig{MaxDeviationPct: 1, Window: 20})
+
+	var rejects []PriceBandReject
+	hub.SetPriceBandCallback(func(reject PriceBandReject) {
+		rejects = append(rejects, reject)
+	})
+
+	for i := 0; i < 10; i++ {
+		hub.BroadcastTick(quote("EURUSD", 1.1000, 1.1002))
+	}
+	hub.BroadcastTick(quote("EURUSD", 1.2100, 1.2102))
+
+	if tick := hub.GetLatestPrice("EURUSD"); tick == nil || tick.Bid != 1.1000 {
+		t.Fatalf("latest price = %+v, want the outlier dropped", tick)
+	}
+	if len(rejects) != 1 || rejects[0].Symbol != "EURUSD" || rejects[0].Consecutive != 1 {
+		t.Fatalf("rejects = %+v, want one EURUSD reject", rejects)
+	}
+	if rejects[0].DeviationPct < 9 {
+		t.Errorf("deviation = %.2f%%, want about 10%%", rejects[0].DeviationPct)
+	}
+
+	hub.BroadcastTick(quote("EURUSD", 1.1001, 1.1003))
+	if tick := hub.GetLatestPrice("EURUSD"); tick.Bid != 1.1001 {
+		t.Errorf("latest bid = %v after a normal tick, want 1.1001", tick.Bid)
+	}
+
+	stats := hub.GetPriceBandStats()
+	if stats.Rejected != 1 || stats.BySymbol["EURUSD"] != 1 {
+		t.Errorf("stats = %+v, want 1 EURUSD reject", stats)
+	}
+}
+
+// TestPriceBandFollowsGradualTrend tests that a market trending far beyond the
+// band in small steps keeps being accepted as the band recalibrates
+func TestPriceBandFollowsGradualTrend(t *testing.T) {
+	hub := NewHub()
+	hub.SetDefaultPriceBand(PriceBandConfig{MaxDeviationPct: 1, Window: 20})
+
+	bid := 1.1000
+	for i := 0; i < 300; i++ {
+		bid *= 1.0005 // 0.05% per tick, about 16% in total
+		hub.BroadcastTick(quote("EURUSD", bid, bid+0.0002))
+	}
+
+	if stats := hub.GetPriceBandStats(); stats.Rejected != 0 {
+		t.Fatalf("rejected %d ticks of a gradual trend, want 0", stats.Rejected)
+	}
+	if tick := hub.GetLatestPrice("EURUSD"); tick.Bid != bid {
+		t.Errorf("latest bid = %v, want %v", tick.Bid, bid)
+	}
+}
+
+// TestPriceBandRecalibratesAfterGap tests that a level that persists for
+// RecalibrateAfter ticks becomes the new reference
+func TestPriceBandRecalibratesAfterGap(t *testing.T) {
+	hub := NewHub()
+	hub.SetDefaultPriceBand(PriceBandConfig{MaxDeviationPct: 1, Window: 20, RecalibrateAfter: 5})
+
+	for i := 0; i < 10; i++ {
+		hub.BroadcastTick(quote("XAUUSD", 2000.00, 2000.50))
+	}
+	for i := 0; i < 5; i++ {
+		hub.BroadcastTick(quote("XAUUSD", 2100.00, 2100.50))
+	}
+
+	if stats := hub.GetPriceBandStats(); stats.BySymbol["XAUUSD"] != 4 {
+		t.Errorf("rejected %d ticks, want 4 before the band recalibrates", stats.BySymbol["XAUUSD"])
+	}
+	hub.BroadcastTick(quote("XAUUSD", 2100.10, 2100.60))
+	if tick := hub.GetLatestPrice("XAUUSD"); tick.Bid != 2100.10 {
+		t.Errorf("latest bid = %v, want ticks at the new level accepted", tick.Bid)
+	}
+}
+
+// TestPriceBandSymbolOverride tests that a per-symbol band replaces the default
+func TestPriceBandSymbolOverride(t *testing.T) {
+	hub := NewHub()
+	hub.SetDefaultPriceBand(PriceBandConfig{MaxDeviationPct: 1, Window: 20})
+	hub.SetPriceBand("BTCUSD", PriceBandConfig{MaxDeviationPct: 20, Window: 20})
+
+	for i := 0; i < 5; i++ {
+		hub.BroadcastTick(quote("BTCUSD", 60000, 60010))
+	}
+	hub.BroadcastTick(quote("BTCUSD", 65000, 65010))
+
+	if tick := hub.GetLatestPrice("BTCUSD"); tick.Bid != 65000 {
+		t.Errorf("latest bid = %v, want an 8%% move accepted under the 20%% override", tick.Bid)
+	}
+}
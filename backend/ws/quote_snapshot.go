@@ -0,0 +1,113 @@
+package ws
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/epic1st/rtx/backend/logging"
+)
+
+// quoteSnapshot is the on-disk format of the latest quote per symbol
+type quoteSnapshot struct {
+	SavedAt time.Time    `json:"savedAt"`
+	Quotes  []MarketTick `json:"quotes"`
+}
+
+// SaveQuoteSnapshot writes the latest quote per symbol to path.
+// The file is replaced atomically so a crash mid-write keeps the previous snapshot.
+func (h *Hub) SaveQuoteSnapshot(path string) error {
+	h.mu.RLock()
+	snapshot := quoteSnapshot{
+		SavedAt: time.Now(),
+		Quotes:  make([]MarketTick, 0, len(h.latestPrices)),
+	}
+	for _, tick := range h.latestPrices {
+		snapshot.Quotes = append(snapshot.Quotes, *tick)
+	}
+	h.mu.RUnlock()
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadQuoteSnapshot seeds the latest prices from a snapshot written by
+// SaveQuoteSnapshot. Loaded quotes are marked stale until a live tick replaces
+// them; symbols that already have a live quote are left untouched.
+func (h *Hub) LoadQuoteSnapshot(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	var snapshot quoteSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return 0, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	loaded := 0
+	for i := range snapshot.Quotes {
+		tick := snapshot.Quotes[i]
+		if tick.Symbol == "" || tick.Bid <= 0 || tick.Ask <= 0 {
+			continue
+		}
+		if _, exists := h.latestPrices[tick.Symbol]; exists {
+			continue
+		}
+		tick.Stale = true
+		h.latestPrices[tick.Symbol] = &tick
+		loaded++
+	}
+
+	logger.Info("Loaded stale quotes from snapshot", logging.Int("quotes", loaded), logging.String("saved_at", snapshot.SavedAt.Format(time.RFC3339)))
+	return loaded, nil
+}
+
+// IsQuoteStale reports whether the latest price for symbol was recovered from a
+// snapshot and has not yet been refreshed by a live tick
+func (h *Hub) IsQuoteStale(symbol string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	tick, ok := h.latestPrices[symbol]
+	return ok && tick.Stale
+}
+
+// StartQuoteSnapshots persists the latest quotes to path every interval
+func (h *Hub) StartQuoteSnapshots(path string, interval time.Duration) {
+	if interval <= 0 {
+		logger.Warn("Quote snapshots disabled: interval must be positive", logging.String("interval", interval.String()))
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := h.SaveQuoteSnapshot(path); err != nil {
+				logger.Error("Failed to save quote snapshot", err)
+			}
+		}
+	}()
+
+	logger.Info("Saving quote snapshots", logging.String("path", path), logging.String("interval", interval.String()))
+}
@@ -0,0 +1,80 @@
+package ws
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/internal/core"
+)
+
+// wireEngine points the engine's price feed at the hub, as the server does
+func wireEngine(engine *core.Engine, hub *Hub) {
+	engine.SetPriceCallback(func(symbol string) (float64, float64, bool) {
+		if tick := hub.GetLatestPrice(symbol); tick != nil {
+			return tick.Bid, tick.Ask, true
+		}
+		return 0, 0, false
+	})
+	engine.SetStaleQuoteCallback(hub.IsQuoteStale)
+}
+
+func quote(symbol string, bid, ask float64) *MarketTick {
+	return &MarketTick{Type: "tick", Symbol: symbol, Bid: bid, Ask: ask, Spread: ask - bid, Timestamp: time.Now().Unix(), LP: "TEST"}
+}
+
+// TestQuoteSnapshotRecoveredAfterRestart tests that persisted quotes value positions after a
+// restart, are marked stale until a live tick arrives, and never fill orders while stale
+func TestQuoteSnapshotRecoveredAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quotes.json")
+	engine := core.NewEngine()
+	account := engine.CreateAccount("user1", "trader", "password", true)
+	account.Balance = 10000
+
+	// Before the restart: live quotes, an open position and a saved snapshot
+	before := NewHub()
+	wireEngine(engine, before)
+	before.BroadcastTick(quote("EURUSD", 1.1000, 1.1002))
+	pos, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 1.0, 0, 0)
+	if err != nil {
+		t.Fatalf("ExecuteMarketOrder() error = %v", err)
+	}
+	if err := before.SaveQuoteSnapshot(path); err != nil {
+		t.Fatalf("SaveQuoteSnapshot() error = %v", err)
+	}
+
+	// After the restart the new hub has no prices until the snapshot is loaded
+	after := NewHub()
+	wireEngine(engine, after)
+	loaded, err := after.LoadQuoteSnapshot(path)
+	if err != nil || loaded != 1 {
+		t.Fatalf("LoadQuoteSnapshot() = %d, %v; want 1, nil", loaded, err)
+	}
+	if tick := after.GetLatestPrice("EURUSD"); tick == nil || !tick.Stale {
+		t.Fatalf("recovered quote = %+v, want stale EURUSD quote", tick)
+	}
+
+	engine.UpdatePositionPrices()
+	if pos.CurrentPrice != 1.1000 || math.Abs(pos.UnrealizedPnL-(-20)) > 1e-6 {
+		t.Errorf("stale valuation = %.5f / %.2f, want 1.10000 / -20.00", pos.CurrentPrice, pos.UnrealizedPnL)
+	}
+	if _, err := engine.ExecuteMarketOrder(account.ID, "EURUSD", "BUY", 1.0, 0, 0); err == nil {
+		t.Error("ExecuteMarketOrder() should refuse to fill at a stale price")
+	}
+
+	// A live tick replaces the recovered quote
+	after.BroadcastTick(quote("EURUSD", 1.1010, 1.1012))
+	if after.IsQuoteStale("EURUSD") {
+		t.Error("quote still stale after live tick")
+	}
+	engine.UpdatePositionPrices()
+	if pos.CurrentPrice != 1.1010 || math.Abs(pos.UnrealizedPnL-80) > 1e-6 {
+		t.Errorf("live valuation = %.5f / %.2f, want 1.10100 / 80.00", pos.CurrentPrice, pos.UnrealizedPnL)
+	}
+
+	// Live quotes are not overwritten by an older snapshot
+	if loaded, _ := after.LoadQuoteSnapshot(path); loaded != 0 {
+		t.Errorf("LoadQuoteSnapshot() over live quotes loaded %d, want 0", loaded)
+	}
+}
@@ -0,0 +1,131 @@
+package ws
+
+import (
+	"sync"
+	"time"
+)
+
+// sampledTick is a tick waiting for its sampling interval to end
+type sampledTick struct {
+	tick *MarketTick
+	at   time.Time
+	slot int64
+}
+
+// recordSampler bounds how many ticks per second are persisted for a symbol,
+// keeping the last tick of each interval. It only affects storage; the live
+// feed still sees every tick.
+type recordSampler struct {
+	mu      sync.Mutex
+	rates   map[string]int // Symbol -> max recorded ticks per second
+	pending map[string]*sampledTick
+}
+
+func newRecordSampler() *recordSampler {
+	return &recordSampler{
+		rates:   make(map[string]int),
+		pending: make(map[string]*sampledTick),
+	}
+}
+
+// setRate sets the maximum recorded ticks per second of a symbol, 0 records every tick
+func (s *recordSampler) setRate(symbol string, rate int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rate <= 0 {
+		delete(s.rates, symbol)
+		return
+	}
+	s.rates[symbol] = rate
+}
+
+// offer takes a tick received at the given time and returns the ticks that are
+// ready to persist: the tick itself when the symbol is not sampled, otherwise
+// the held tick of an interval that has just ended
+func (s *recordSampler) offer(tick *MarketTick, at time.Time) []sampledTick {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	held := s.pending[tick.Symbol]
+	rate, sampled := s.rates[tick.Symbol]
+	if !sampled {
+		if held == nil {
+			return []sampledTick{{tick: tick, at: at}}
+		}
+		// Sampling was switched off, release the held tick first
+		delete(s.pending, tick.Symbol)
+		return []sampledTick{*held, {tick: tick, at: at}}
+	}
+
+	slot := sampleSlot(at, rate)
+	var ready []sampledTick
+	if held != nil && held.slot != slot {
+		ready = append(ready, *held)
+	}
+	s.pending[tick.Symbol] = &sampledTick{tick: tick, at: at, slot: slot}
+	return ready
+}
+
+// flush returns and forgets the held ticks whose interval has ended by now
+func (s *recordSampler) flush(now time.Time) []sampledTick {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ready []sampledTick
+	for symbol, held := range s.pending {
+		rate, sampled := s.rates[symbol]
+		if sampled && sampleSlot(now, rate) == held.slot {
+			continue
+		}
+		ready = append(ready, *held)
+		delete(s.pending, symbol)
+	}
+	return ready
+}
+
+// sampleSlot returns the index of the sampling interval containing at
+func sampleSlot(at time.Time, rate int) int64 {
+	interval := int64(time.Second) / int64(rate)
+	if interval <= 0 {
+		interval = 1
+	}
+	return at.UnixNano() / interval
+}
+
+// persistTick stores a tick through the record sampler
+func (h *Hub) persistTick(tick *MarketTick, at time.Time) {
+	if h.tickStore == nil {
+		return
+	}
+	for _, st := range h.recordSampler.offer(tick, at) {
+		h.tickStore.StoreTick(st.tick.Symbol, st.tick.Bid, st.tick.Ask, st.tick.Spread, st.tick.LP, st.at)
+	}
+}
+
+// flushSampledTicks persists the held tick of every sampling interval that has
+// ended, so quiet symbols still record their last price
+func (h *Hub) flushSampledTicks(now time.Time) {
+	if h.tickStore == nil {
+		return
+	}
+	for _, st := range h.recordSampler.flush(now) {
+		h.tickStore.StoreTick(st.tick.Symbol, st.tick.Bid, st.tick.Ask, st.tick.Spread, st.tick.LP, st.at)
+	}
+}
+
+// runSampleFlush periodically persists ticks held by the record sampler
+func (h *Hub) runSampleFlush() {
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		h.flushSampledTicks(now)
+	}
+}
+
+// SetRecordRate limits how many ticks per second of a symbol are persisted,
+// keeping the last tick of each interval. 0 records every tick.
+func (h *Hub) SetRecordRate(symbol string, rate int) {
+	h.recordSampler.setRate(symbol, rate)
+}
@@ -0,0 +1,87 @@
+package ws
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingTickStore keeps the bid of every stored tick
+type recordingTickStore struct {
+	mu   sync.Mutex
+	bids []float64
+}
+
+func (r *recordingTickStore) StoreTick(symbol string, bid, ask, spread float64, lp string, timestamp time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bids = append(r.bids, bid)
+}
+
+func (r *recordingTickStore) stored() []float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]float64(nil), r.bids...)
+}
+
+// TestRecordSamplingDownsamplesStorage tests that a 1000 ticks/sec stream is stored at the
+// configured rate, keeping the last tick of each interval
+func TestRecordSamplingDownsamplesStorage(t *testing.T) {
+	hub := NewHub()
+	store := &recordingTickStore{}
+	hub.SetTickStore(store)
+	hub.SetRecordRate("EURUSD", 10)
+
+	base := time.Unix(1700000000, 0)
+	for i := 0; i < 1000; i++ {
+		tick := &MarketTick{Type: "tick", Symbol: "EURUSD", Bid: float64(i), Ask: float64(i) + 1}
+		hub.persistTick(tick, base.Add(time.Duration(i)*time.Millisecond))
+	}
+	hub.flushSampledTicks(base.Add(2 * time.Second))
+
+	bids := store.stored()
+	if len(bids) != 10 {
+		t.Fatalf("stored %d ticks, want 10", len(bids))
+	}
+	for i, bid := range bids {
+		if want := float64(i*100 + 99); bid != want {
+			t.Errorf("stored tick %d bid = %.0f, want last of interval %.0f", i, bid, want)
+		}
+	}
+
+	// Symbols without a record rate store every tick
+	hub.persistTick(&MarketTick{Symbol: "GBPUSD", Bid: 1}, base)
+	hub.persistTick(&MarketTick{Symbol: "GBPUSD", Bid: 2}, base)
+	if got := len(store.stored()); got != 12 {
+		t.Errorf("stored %d ticks after unsampled symbol, want 12", got)
+	}
+}
+
+// TestRecordSamplingLeavesLiveFeed tests that sampling storage does not drop ticks from the live feed
+func TestRecordSamplingLeavesLiveFeed(t *testing.T) {
+	hub := NewHub()
+	store := &recordingTickStore{}
+	hub.SetTickStore(store)
+	hub.SetRecordRate("EURUSD", 10)
+
+	var last *MarketTick
+	start := time.Now()
+	for i := 0; i < 1000; i++ {
+		last = &MarketTick{Type: "tick", Symbol: "EURUSD", Bid: 1.1 + float64(i)*0.0001, Ask: 1.1002 + float64(i)*0.0001}
+		hub.BroadcastTick(last)
+	}
+	elapsed := time.Since(start)
+
+	if metrics := hub.GetTickMetrics(); metrics.TotalTicks != 1000 {
+		t.Errorf("live feed received %d ticks, want 1000", metrics.TotalTicks)
+	}
+	if latest := hub.GetLatestPrice("EURUSD"); latest != last {
+		t.Errorf("latest price = %+v, want the last tick", latest)
+	}
+
+	// At most one stored tick per elapsed 100ms interval, plus the partial first one
+	maxStored := int(elapsed/(100*time.Millisecond)) + 1
+	if got := len(store.stored()); got > maxStored {
+		t.Errorf("stored %d ticks in %v, want at most %d", got, elapsed, maxStored)
+	}
+}
@@ -0,0 +1,66 @@
+package ws
+
+import (
+	"context"
+	"time"
+
+	"github.com/epic1st/rtx/backend/logging"
+	"github.com/gorilla/websocket"
+)
+
+// Close disconnects every client with a going-away close frame and waits for
+// their write pumps to finish, or for ctx. Upgrades arriving afterwards are
+// closed straight away. Safe to call more than once.
+func (h *Hub) Close(ctx context.Context) error {
+	h.closeOnce.Do(func() { close(h.quit) })
+
+	h.mu.Lock()
+	disconnected := len(h.clients)
+	for client := range h.clients {
+		delete(h.clients, client)
+		close(client.send)
+	}
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.pumps.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		logger.Info("Hub closed", logging.Int("clients_disconnected", disconnected))
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// closing reports whether Close has been called
+func (h *Hub) closing() bool {
+	select {
+	case <-h.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+// trackPump counts a new connection's write pump, or returns false once Close
+// has begun. Checked under h.mu so Close's wait never races a new Add.
+func (h *Hub) trackPump() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closing() {
+		return false
+	}
+	h.pumps.Add(1)
+	return true
+}
+
+// sendGoingAway tells the client the server is going away before the
+// connection is closed
+func sendGoingAway(conn *websocket.Conn) {
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+}
@@ -0,0 +1,114 @@
+package ws
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// SubscriptionRequest is a client control frame choosing which symbols' ticks
+// the connection receives, e.g. {"action":"subscribe","symbols":["EURUSD"]}
+type SubscriptionRequest struct {
+	Action  string   `json:"action"` // subscribe or unsubscribe
+	Symbols []string `json:"symbols"`
+}
+
+// SubscriptionAck answers a control frame with the connection's symbol set
+// after the change. An empty set receives every symbol.
+type SubscriptionAck struct {
+	Type    string   `json:"type"` // Always "subscriptions"
+	Symbols []string `json:"symbols"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// outboundMessage is a frame queued for delivery. Ticks carry their symbol so
+// subscribed clients can be filtered; other messages go to everyone.
+type outboundMessage struct {
+	symbol string
+	data   []byte
+
+	// Ticks keep the quote and its pricing to derive group marked-up frames
+	tick    *MarketTick
+	pipSize float64
+	digits  int
+}
+
+// wantsSymbol reports whether the client receives ticks for symbol. Clients
+// that never subscribed, and messages without a symbol, are not filtered.
+func (c *Client) wantsSymbol(symbol string) bool {
+	if symbol == "" {
+		return true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.symbols) == 0 || c.symbols[symbol]
+}
+
+// subscribe adds symbols to the client's set and returns the new set
+func (c *Client) subscribe(symbols []string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, symbol := range symbols {
+		if symbol = normalizeSymbol(symbol); symbol != "" {
+			c.symbols[symbol] = true
+		}
+	}
+	return c.subscribedSymbolsUnlocked()
+}
+
+// unsubscribe removes symbols from the client's set and returns the new set.
+// No symbols clears the set, returning the client to every symbol.
+func (c *Client) unsubscribe(symbols []string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(symbols) == 0 {
+		c.symbols = make(map[string]bool)
+	}
+	for _, symbol := range symbols {
+		delete(c.symbols, normalizeSymbol(symbol))
+	}
+	return c.subscribedSymbolsUnlocked()
+}
+
+// subscribedSymbolsUnlocked returns the sorted symbol set (caller must hold lock)
+func (c *Client) subscribedSymbolsUnlocked() []string {
+	symbols := make([]string, 0, len(c.symbols))
+	for symbol := range c.symbols {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+func normalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+// handleClientMessage applies a control frame read from the client. Frames
+// without an action are ignored; anything else is acknowledged.
+func (h *Hub) handleClientMessage(client *Client, data []byte) {
+	var req SubscriptionRequest
+	if err := json.Unmarshal(data, &req); err != nil || req.Action == "" {
+		return
+	}
+
+	ack := SubscriptionAck{Type: "subscriptions"}
+	switch strings.ToLower(req.Action) {
+	case "subscribe":
+		ack.Symbols = client.subscribe(req.Symbols)
+	case "unsubscribe":
+		ack.Symbols = client.unsubscribe(req.Symbols)
+	default:
+		client.mu.Lock()
+		ack.Symbols = client.subscribedSymbolsUnlocked()
+		client.mu.Unlock()
+		ack.Error = "unknown action " + req.Action
+	}
+
+	if reply, err := json.Marshal(ack); err == nil {
+		select {
+		case client.send <- reply:
+		default:
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// drainTickSymbols collects the symbol of every tick queued for a client and
+// the last subscription ack
+func drainTickSymbols(t *testing.T, client *Client) ([]string, *SubscriptionAck) {
+	t.Helper()
+	time.Sleep(100 * time.Millisecond)
+
+	var symbols []string
+	var ack *SubscriptionAck
+	for {
+		select {
+		case data := <-client.send:
+			var msg struct {
+				Type    string   `json:"type"`
+				Symbol  string   `json:"symbol"`
+				Symbols []string `json:"symbols"`
+				Error   string   `json:"error"`
+			}
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Fatalf("client received invalid JSON: %s", data)
+			}
+			switch msg.Type {
+			case "tick":
+				symbols = append(symbols, msg.Symbol)
+			case "subscriptions":
+				ack = &SubscriptionAck{Type: msg.Type, Symbols: msg.Symbols, Error: msg.Error}
+			}
+		default:
+			return symbols, ack
+		}
+	}
+}
+
+// TestSubscriptionFiltersTicks tests that a subscribed client only receives its
+// symbols while an unsubscribed client still receives everything
+func TestSubscriptionFiltersTicks(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	selective := &Client{send: make(chan []byte, 64), symbols: make(map[string]bool)}
+	firehose := &Client{send: make(chan []byte, 64), symbols: make(map[string]bool)}
+	hub.register <- selective
+	hub.register <- firehose
+
+	hub.handleClientMessage(selective, []byte(`{"action":"subscribe","symbols":["eurusd","XAUUSD"]}`))
+	if _, ack := drainTickSymbols(t, selective); ack == nil || len(ack.Symbols) != 2 || ack.Symbols[0] != "EURUSD" {
+		t.Fatalf("subscribe ack = %+v, want [EURUSD XAUUSD]", ack)
+	}
+
+	hub.BroadcastTick(quote("EURUSD", 1.1000, 1.1002))
+	hub.BroadcastTick(quote("GBPUSD", 1.2500, 1.2502))
+	hub.BroadcastTick(quote("XAUUSD", 2000.00, 2000.40))
+
+	if got, _ := drainTickSymbols(t, selective); len(got) != 2 || got[0] != "EURUSD" || got[1] != "XAUUSD" {
+		t.Errorf("subscribed client ticks = %v, want [EURUSD XAUUSD]", got)
+	}
+	if got, _ := drainTickSymbols(t, firehose); len(got) != 3 {
+		t.Errorf("unsubscribed client ticks = %v, want all 3", got)
+	}
+
+	hub.handleClientMessage(selective, []byte(`{"action":"unsubscribe","symbols":["XAUUSD"]}`))
+	hub.BroadcastTick(quote("XAUUSD", 2010.00, 2010.40))
+	hub.BroadcastTick(quote("EURUSD", 1.1010, 1.1012))
+	got, ack := drainTickSymbols(t, selective)
+	if ack == nil || len(ack.Symbols) != 1 || ack.Symbols[0] != "EURUSD" {
+		t.Errorf("unsubscribe ack = %+v, want [EURUSD]", ack)
+	}
+	if len(got) != 1 || got[0] != "EURUSD" {
+		t.Errorf("ticks after unsubscribe = %v, want [EURUSD]", got)
+	}
+}
+
+// TestSubscriptionControlFrames tests clearing the set and rejecting unknown actions
+func TestSubscriptionControlFrames(t *testing.T) {
+	hub := NewHub()
+	client := &Client{send: make(chan []byte, 64), symbols: make(map[string]bool)}
+
+	hub.handleClientMessage(client, []byte(`{"action":"subscribe","symbols":["EURUSD"]}`))
+	hub.handleClientMessage(client, []byte(`{"action":"unsubscribe"}`))
+	if !client.wantsSymbol("GBPUSD") {
+		t.Error("client with a cleared set is still filtered")
+	}
+
+	hub.handleClientMessage(client, []byte(`not json`))
+	hub.handleClientMessage(client, []byte(`{"action":"watch","symbols":["EURUSD"]}`))
+	if _, ack := drainTickSymbols(t, client); ack == nil || ack.Error == "" {
+		t.Errorf("ack for unknown action = %+v, want an error", ack)
+	}
+}
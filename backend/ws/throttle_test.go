@@ -0,0 +1,33 @@
+package ws
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+// TestBroadcastThrottleKeepsDefault tests that symbols without a threshold of
+// their own are throttled at DefaultBroadcastThrottlePct, while a symbol's own
+// threshold from the source replaces it
+func TestBroadcastThrottleKeepsDefault(t *testing.T) {
+	hub := NewHub()
+	hub.SetTickStore(&MockTickStore{})
+	hub.SetThrottleThresholdFunc(func(symbol string) (float64, bool) {
+		if symbol == "XAUUSD" {
+			return 0.01, true
+		}
+		return 0, false
+	})
+
+	// 0.0005% moves: above the 0.0001% default, below XAUUSD's 0.01%
+	hub.BroadcastTick(quote("EURUSD", 1.100000, 1.100200))
+	hub.BroadcastTick(quote("EURUSD", 1.100006, 1.100206))
+	if throttled := atomic.LoadInt64(&hub.ticksThrottled); throttled != 0 {
+		t.Errorf("EURUSD throttled = %d, want 0 at the default threshold", throttled)
+	}
+
+	hub.BroadcastTick(quote("XAUUSD", 2000.00, 2000.50))
+	hub.BroadcastTick(quote("XAUUSD", 2000.01, 2000.51))
+	if throttled := atomic.LoadInt64(&hub.ticksThrottled); throttled != 1 {
+		t.Errorf("XAUUSD throttled = %d, want 1 at its own threshold", throttled)
+	}
+}
@@ -0,0 +1,117 @@
+package ws
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// SymbolTickMetrics holds the tick counters of one symbol
+type SymbolTickMetrics struct {
+	Symbol     string    `json:"symbol"`
+	Count      int64     `json:"count"`
+	LastTickAt time.Time `json:"lastTickAt"`
+	LastBid    float64   `json:"lastBid"`
+	LastAsk    float64   `json:"lastAsk"`
+	LastLP     string    `json:"lastLp"`
+}
+
+// TickMetricsSnapshot is a point-in-time view of the tick counters since the
+// last reset
+type TickMetricsSnapshot struct {
+	TotalTicks    int64               `json:"totalTicks"`
+	SymbolCount   int                 `json:"symbolCount"`
+	WindowStart   time.Time           `json:"windowStart"`
+	WindowSeconds float64             `json:"windowSeconds"`
+	TicksPerSec   float64             `json:"ticksPerSec"`
+	LastTickAt    *time.Time          `json:"lastTickAt,omitempty"`
+	Symbols       []SymbolTickMetrics `json:"symbols"`
+}
+
+// TickMetrics counts received ticks per symbol and globally. Counters can be
+// reset to start a new measurement window.
+type TickMetrics struct {
+	mu          sync.RWMutex
+	total       int64
+	symbols     map[string]*SymbolTickMetrics
+	windowStart time.Time
+}
+
+// NewTickMetrics creates an empty tick metrics store
+func NewTickMetrics() *TickMetrics {
+	return &TickMetrics{
+		symbols:     make(map[string]*SymbolTickMetrics),
+		windowStart: time.Now(),
+	}
+}
+
+// Record counts a tick received at the given time
+func (m *TickMetrics) Record(tick *MarketTick, at time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metrics, ok := m.symbols[tick.Symbol]
+	if !ok {
+		metrics = &SymbolTickMetrics{Symbol: tick.Symbol}
+		m.symbols[tick.Symbol] = metrics
+	}
+	metrics.Count++
+	metrics.LastTickAt = at
+	metrics.LastBid = tick.Bid
+	metrics.LastAsk = tick.Ask
+	metrics.LastLP = tick.LP
+	m.total++
+}
+
+// Snapshot returns the counters of the current measurement window
+func (m *TickMetrics) Snapshot() TickMetricsSnapshot {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	window := time.Since(m.windowStart).Seconds()
+	snap := TickMetricsSnapshot{
+		TotalTicks:    m.total,
+		SymbolCount:   len(m.symbols),
+		WindowStart:   m.windowStart,
+		WindowSeconds: window,
+		Symbols:       make([]SymbolTickMetrics, 0, len(m.symbols)),
+	}
+	if window > 0 {
+		snap.TicksPerSec = float64(m.total) / window
+	}
+
+	var last time.Time
+	for _, metrics := range m.symbols {
+		snap.Symbols = append(snap.Symbols, *metrics)
+		if metrics.LastTickAt.After(last) {
+			last = metrics.LastTickAt
+		}
+	}
+	if !last.IsZero() {
+		snap.LastTickAt = &last
+	}
+	sort.Slice(snap.Symbols, func(i, j int) bool {
+		return snap.Symbols[i].Symbol < snap.Symbols[j].Symbol
+	})
+	return snap
+}
+
+// Reset clears all counters and starts a new measurement window
+func (m *TickMetrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.total = 0
+	m.symbols = make(map[string]*SymbolTickMetrics)
+	m.windowStart = time.Now()
+}
+
+// GetTickMetrics returns the tick counters of the current measurement window
+func (h *Hub) GetTickMetrics() TickMetricsSnapshot {
+	return h.tickMetrics.Snapshot()
+}
+
+// ResetTickMetrics clears the tick counters and starts a new measurement window
+func (h *Hub) ResetTickMetrics() {
+	h.tickMetrics.Reset()
+}
@@ -0,0 +1,39 @@
+package ws
+
+import "testing"
+
+// TestTickMetricsCountAndReset tests that broadcast ticks are counted per symbol and reset clears them
+func TestTickMetricsCountAndReset(t *testing.T) {
+	hub := NewHub()
+
+	hub.BroadcastTick(&MarketTick{Type: "tick", Symbol: "EURUSD", Bid: 1.1000, Ask: 1.1002, LP: "YOFX"})
+	hub.BroadcastTick(&MarketTick{Type: "tick", Symbol: "EURUSD", Bid: 1.1001, Ask: 1.1003, LP: "YOFX"})
+	hub.BroadcastTick(&MarketTick{Type: "tick", Symbol: "GBPUSD", Bid: 1.2500, Ask: 1.2502, LP: "OANDA"})
+
+	snap := hub.GetTickMetrics()
+	if snap.TotalTicks != 3 || snap.SymbolCount != 2 {
+		t.Fatalf("total = %d, symbols = %d; want 3 / 2", snap.TotalTicks, snap.SymbolCount)
+	}
+	eurusd := snap.Symbols[0]
+	if eurusd.Symbol != "EURUSD" || eurusd.Count != 2 || eurusd.LastBid != 1.1001 || eurusd.LastTickAt.IsZero() {
+		t.Errorf("EURUSD metrics = %+v, want 2 ticks with last bid 1.1001", eurusd)
+	}
+	if gbpusd := snap.Symbols[1]; gbpusd.Symbol != "GBPUSD" || gbpusd.Count != 1 || gbpusd.LastLP != "OANDA" {
+		t.Errorf("GBPUSD metrics = %+v, want 1 tick from OANDA", gbpusd)
+	}
+	if snap.LastTickAt == nil {
+		t.Error("LastTickAt = nil, want the time of the last tick")
+	}
+
+	hub.ResetTickMetrics()
+	snap = hub.GetTickMetrics()
+	if snap.TotalTicks != 0 || snap.SymbolCount != 0 || len(snap.Symbols) != 0 || snap.LastTickAt != nil {
+		t.Errorf("after reset = %+v, want empty counters", snap)
+	}
+
+	// Counting resumes in the new window
+	hub.BroadcastTick(&MarketTick{Type: "tick", Symbol: "EURUSD", Bid: 1.1002, Ask: 1.1004})
+	if snap = hub.GetTickMetrics(); snap.TotalTicks != 1 || snap.Symbols[0].Count != 1 {
+		t.Errorf("after new tick = %+v, want 1 EURUSD tick", snap)
+	}
+}
@@ -0,0 +1,48 @@
+package ws
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/epic1st/rtx/backend/auth"
+	"github.com/epic1st/rtx/backend/internal/core"
+	"github.com/gorilla/websocket"
+)
+
+// TestExpiredTokenClosesWithCode tests that connecting with an expired token
+// completes the handshake and is closed with CloseTokenExpired
+func TestExpiredTokenClosesWithCode(t *testing.T) {
+	const secret = "test-jwt-secret-for-testing-only"
+	hub := NewHub()
+	hub.SetAuthService(auth.NewService(core.NewEngine(), "", secret))
+	go hub.Run()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ServeWs(hub, w, r)
+	}))
+	defer server.Close()
+
+	token, err := auth.GenerateJWTWithTTL(&auth.User{ID: "1", Role: "TRADER"}, []byte(secret), -time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateJWTWithTTL() error = %v", err)
+	}
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"?token="+token, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err = conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != CloseTokenExpired {
+		t.Errorf("ReadMessage() error = %v, want close code %d", err, CloseTokenExpired)
+	}
+	if got := hub.ClientCount(); got != 0 {
+		t.Errorf("registered clients = %d, want 0", got)
+	}
+}
@@ -2,7 +2,7 @@
 
 # Market Data Pipeline Health Check Script
 # Purpose: Verify all components of the pipeline are working correctly
-# Usage: ./pipeline_health_check.sh
+# Usage: ADMIN_TOKEN=<admin JWT> ./pipeline_health_check.sh
 
 set -e
 
@@ -62,7 +62,7 @@ if [ $BACKEND_OK -eq 1 ]; then
 
   # Check pipeline stats
   echo -e "${BLUE}4. Pipeline Statistics:${NC}"
-  STATS=$(curl -s http://localhost:8080/api/admin/pipeline-stats 2>/dev/null)
+  STATS=$(curl -sf -H "Authorization: Bearer ${ADMIN_TOKEN}" http://localhost:8080/api/admin/pipeline-stats 2>/dev/null || true)
 
   if [ -n "$STATS" ]; then
     TICKS=$(echo "$STATS" | jq -r '.data.ticks_received // 0' 2>/dev/null)
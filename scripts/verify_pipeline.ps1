@@ -1,11 +1,12 @@
 # Market Data Pipeline Verification Script for Windows
 # Purpose: Verify pipeline health and data flow
-# Usage: ./verify_pipeline.ps1
+# Usage: ./verify_pipeline.ps1 -AdminToken <admin JWT>
 
 param(
     [string]$BackendURL = "http://localhost:8080",
     [string]$RedisPort = "6379",
-    [string]$Duration = "30"  # seconds
+    [string]$Duration = "30",  # seconds
+    [string]$AdminToken = ""   # Admin JWT for /api/admin/pipeline-stats
 )
 
 $ErrorActionPreference = "Continue"
@@ -104,7 +105,7 @@ if ($BackendOK) {
     Write-Host "4. Pipeline Statistics:" -ForegroundColor Cyan
 
     try {
-        $PipelineStats = Invoke-RestMethod -Uri "$BackendURL/api/admin/pipeline-stats" -Method Get -TimeoutSec 5 -ErrorAction Stop
+        $PipelineStats = Invoke-RestMethod -Uri "$BackendURL/api/admin/pipeline-stats" -Headers @{ Authorization = "Bearer $AdminToken" } -Method Get -TimeoutSec 5 -ErrorAction Stop
 
         $TicksReceived = $PipelineStats.data.ticks_received
         $TicksProcessed = $PipelineStats.data.ticks_processed